@@ -3,11 +3,13 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/PancyStudios/PancyBotGo/internal/commands"
 	"github.com/PancyStudios/PancyBotGo/internal/events"
@@ -18,12 +20,15 @@ import (
 	utilsMsgCommands "github.com/PancyStudios/PancyBotGo/internal/messagecommands/utils"
 	helpMsgCommands "github.com/PancyStudios/PancyBotGo/internal/messagecommands/help"
 	iaMsgCommands "github.com/PancyStudios/PancyBotGo/internal/messagecommands/ia"
+	invitesMsgCommands "github.com/PancyStudios/PancyBotGo/internal/messagecommands/invites"
 	levelsMsgCommands "github.com/PancyStudios/PancyBotGo/internal/messagecommands/levels"
 	devMsgCommands "github.com/PancyStudios/PancyBotGo/internal/messagecommands/dev"
 	embedsMsgCommands "github.com/PancyStudios/PancyBotGo/internal/messagecommands/embeds"
 	musicMsgCommands "github.com/PancyStudios/PancyBotGo/internal/messagecommands/music"
 	premiumMsgCommands "github.com/PancyStudios/PancyBotGo/internal/messagecommands/premium"
+	privacyMsgCommands "github.com/PancyStudios/PancyBotGo/internal/messagecommands/privacy"
 	reactionMsgCommands "github.com/PancyStudios/PancyBotGo/internal/messagecommands/reaction"
+	seasonsMsgCommands "github.com/PancyStudios/PancyBotGo/internal/messagecommands/seasons"
 	securityMsgCommands "github.com/PancyStudios/PancyBotGo/internal/messagecommands/security"
 	"github.com/PancyStudios/PancyBotGo/pkg/api"
 	"github.com/PancyStudios/PancyBotGo/pkg/cli"
@@ -35,6 +40,7 @@ import (
 	"github.com/PancyStudios/PancyBotGo/pkg/logger"
 	"github.com/PancyStudios/PancyBotGo/pkg/mqtt"
 	"github.com/PancyStudios/PancyBotGo/pkg/scheduler"
+	"github.com/PancyStudios/PancyBotGo/pkg/tracing"
 	"github.com/PancyStudios/PancyBotGo/pkg/web"
 )
 
@@ -53,6 +59,31 @@ func main() {
 	// Initialize logger
 	log := logger.Init(cfg.ErrorWebhook, cfg.LogsWebhook)
 	defer log.Close()
+	if err := log.Apply(logger.Settings{
+		Format:       cfg.LogFormat,
+		Level:        cfg.LogLevel,
+		PrefixLevels: cfg.LogLevels,
+		Rotation: logger.Rotation{
+			MaxSize:    int64(cfg.LogMaxSizeMB) << 20,
+			Interval:   24 * time.Hour,
+			MaxBackups: cfg.LogMaxFiles,
+			MaxAge:     time.Duration(cfg.LogMaxAgeDays) * 24 * time.Hour,
+		},
+	}); err != nil {
+		logger.Warn(fmt.Sprintf("Configuración de logs inválida: %v", err), "Main")
+	}
+
+	// Initialize tracing
+	if err := tracing.Init(tracing.Config{
+		Exporter:    cfg.TracingExporter,
+		Endpoint:    cfg.TracingEndpoint,
+		Headers:     cfg.TracingHeaders,
+		ServiceName: "pancybot-" + cfg.Environment,
+		SampleRatio: cfg.TracingSampleRatio,
+	}); err != nil {
+		logger.Warn(fmt.Sprintf("Configuración de tracing inválida: %v", err), "Main")
+	}
+	defer tracing.Shutdown()
 
 	logger.System("Iniciando PancyBot Go...", "Main")
 	logger.Info(fmt.Sprintf("Directorio de trabajo: %s", getCurrentDir()), "Main")
@@ -73,11 +104,19 @@ func main() {
 	})
 
 	// Initialize database
-	db, err := database.Init(cfg.MongoDBURL, cfg.DBName)
-	if err != nil {
-		logger.Error(fmt.Sprintf("Error connecting to database: %v", err), "Main")
-		logger.Debug(fmt.Sprintf("Error connecting to database: %v", cfg.MongoDBURL), "Main")
-		// Continue without database- it will attempt to reconnect
+	var db *database.Database
+	if cfg.DBBackend == "memory" {
+		db = database.InitMemory()
+	} else {
+		db, err = database.Init(cfg.MongoDBURL, cfg.DBName, database.Options{
+			WriteQueuePath: cfg.WriteQueuePath,
+			WriteQueueMax:  cfg.WriteQueueMax,
+		})
+		if err != nil {
+			logger.Error(fmt.Sprintf("Error connecting to database: %v", err), "Main")
+			logger.Debug(fmt.Sprintf("Error connecting to database: %v", cfg.MongoDBURL), "Main")
+			// Continue without database- it will attempt to reconnect
+		}
 	}
 	defer func() {
 		if db != nil {
@@ -99,6 +138,17 @@ func main() {
 
 		// Start automatic blacklist cache refresh every 5 minutes
 		database.StartBlacklistCacheRefresh()
+
+		// Upgrade old documents and create the missing indexes
+		if db.Connected() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			if _, err := database.Migrate(ctx, db, database.MigrateOptions{Instance: cfg.Environment}); err != nil {
+				logger.Error(fmt.Sprintf("Error applying database migrations: %v", err), "Main")
+			}
+			cancel()
+		} else {
+			logger.Warn("DB offline: las migraciones se aplicarán en el próximo arranque", "Main")
+		}
 	}
 
 	// Initialize MQTT
@@ -116,6 +166,13 @@ func main() {
 	)
 	defer mqttClient.Destroy()
 
+	// Keep the caches of every instance coherent: writes here evict the
+	// document on the other instances, and theirs evict it here
+	if err := database.StartInvalidationBus(database.MQTTInvalidationBus(mqttClient)); err != nil {
+		logger.Warn(fmt.Sprintf("Error iniciando la invalidación de caché entre instancias: %v", err), "Main")
+	}
+	defer database.StopInvalidationBus()
+
 	// ──────────────────────────────────────────
 	// Publish logs to MQTT
 	// ──────────────────────────────────────────
@@ -159,13 +216,16 @@ func main() {
 	utilsMsgCommands.Register()
 	helpMsgCommands.RegisterAll()
 	iaMsgCommands.RegisterAll()
+	invitesMsgCommands.RegisterAll()
 	levelsMsgCommands.RegisterAll()
 	devMsgCommands.RegisterAll()
 	embedsMsgCommands.RegisterAll()
 	musicMsgCommands.RegisterAll()
 	premiumMsgCommands.RegisterAll()
+	privacyMsgCommands.RegisterAll()
 	reactionMsgCommands.RegisterAll()
 	securityMsgCommands.RegisterAll()
+	seasonsMsgCommands.RegisterAll()
 
 	logger.Info("Initialising Discord Bot...", "App")
 
@@ -187,6 +247,24 @@ func main() {
 	// Start Tempban scheduler
 	scheduler.StartTempBanScheduler(discordClient)
 
+	// Start verification timeout scheduler
+	scheduler.StartVerificationScheduler(discordClient)
+
+	// Start marketplace settlement scheduler
+	scheduler.StartMarketScheduler(discordClient)
+
+	// Start the refund of casino stakes left open by a restart
+	scheduler.StartCasinoScheduler(discordClient)
+
+	// Start timed item roles scheduler
+	scheduler.StartTimedRoleScheduler(discordClient)
+
+	// Start seasons rollover scheduler
+	scheduler.StartSeasonScheduler(discordClient)
+
+	// Start the purge of the data of servers the bot left
+	scheduler.StartGuildPurgeScheduler(discordClient)
+
 	// Initialize Lavalink after Discord is connected
 	lavalinkClient = lavalink.Init(discordClient.Session, []lavalink.NodeConfig{
 		{
@@ -208,6 +286,13 @@ func main() {
 	lavalink.RegisterMusicHandlers(mqttClient, lavalinkClient)
 	api.RegisterAPIHandlers(mqttClient, discordClient)
 	api.RegisterDevHandlers(mqttClient, discordClient)
+	api.RegisterGuildConfigHandlers(mqttClient, discordClient)
+	if cfg.MQTTAuthSecret == "" {
+		logger.Warn("MQTT_AuthSecret no está configurado: los tópicos del dashboard rechazarán todas las peticiones", "Main")
+	}
+	if err := mqttClient.PublishSchema(); err != nil {
+		logger.Warn(fmt.Sprintf("Error publicando el esquema MQTT: %v", err), "Main")
+	}
 
 	logger.Success("PancyBot Go iniciado correctamente!", "Main")
 
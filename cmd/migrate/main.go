@@ -0,0 +1,102 @@
+// Package main applies the database migrations and creates the missing indexes.
+// The bot does the same at startup; this tool allows checking them first.
+//
+// Usage:
+//
+//	go run cmd/migrate/main.go [options]
+//
+// Options:
+//
+//	-dry-run        Show the pending migrations and missing indexes without writing
+//	-status         List the migrations and whether they were applied
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/config"
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "Show what would change without writing")
+	status := flag.Bool("status", false, "List the migrations and their state")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("Error loading configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	log := logger.Init(cfg.ErrorWebhook, cfg.LogsWebhook)
+	defer log.Close()
+
+	db := database.NewDatabase()
+	if err := db.Connect(cfg.MongoDBURL, cfg.DBName); err != nil {
+		logger.Critical(fmt.Sprintf("Error connecting to database: %v", err), "Migrate")
+		os.Exit(1)
+	}
+	defer db.Disconnect()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	if *status {
+		showStatus(ctx, db)
+		return
+	}
+
+	report, err := database.Migrate(ctx, db, database.MigrateOptions{DryRun: *dryRun, Instance: "migrate-cli"})
+	if report != nil {
+		printReport(report, *dryRun)
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error applying migrations: %v", err), "Migrate")
+		os.Exit(1)
+	}
+	logger.Success("Operación completada exitosamente", "Migrate")
+}
+
+// showStatus lists every migration with its state
+func showStatus(ctx context.Context, db *database.Database) {
+	records, err := database.Migrations(ctx, db)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading migrations: %v", err), "Migrate")
+		return
+	}
+	for _, record := range records {
+		line := fmt.Sprintf("  %3d. [%s] %s", record.Version, record.State, record.Description)
+		if record.State == "done" {
+			line += fmt.Sprintf(" (%d documentos, %s)", record.Documents, record.AppliedAt.Format(time.RFC3339))
+		}
+		logger.Info(line, "Migrate")
+	}
+}
+
+// printReport shows what was applied, or what would be on a dry run
+func printReport(report *database.MigrateReport, dryRun bool) {
+	verb := "Aplicada"
+	if dryRun {
+		verb = "Pendiente"
+	}
+	if len(report.Migrations) == 0 {
+		logger.Info("No hay migraciones pendientes", "Migrate")
+	}
+	for _, m := range report.Migrations {
+		logger.Info(fmt.Sprintf("%s %d: %s (%d documentos)", verb, m.Version, m.Description, m.Documents), "Migrate")
+	}
+
+	verb = "Índice creado"
+	if dryRun {
+		verb = "Índice faltante"
+	}
+	for _, idx := range report.Indexes {
+		logger.Info(fmt.Sprintf("%s: %s", verb, idx), "Migrate")
+	}
+}
@@ -0,0 +1,182 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/cards"
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/imaging"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func cardSubcommand() *discordgo.ApplicationCommandOption {
+	backgroundChoices := make([]*discordgo.ApplicationCommandOptionChoice, 0)
+	for _, name := range imaging.Backgrounds() {
+		backgroundChoices = append(backgroundChoices, &discordgo.ApplicationCommandOptionChoice{Name: name, Value: name})
+	}
+	fontChoices := make([]*discordgo.ApplicationCommandOptionChoice, 0)
+	for _, name := range imaging.Fonts() {
+		fontChoices = append(fontChoices, &discordgo.ApplicationCommandOptionChoice{Name: name, Value: name})
+	}
+
+	return &discordgo.ApplicationCommandOption{
+		Name:        "card",
+		Description: "🖼️ | Configura las tarjetas de imagen de bienvenida, despedida y rango",
+		Type:        discordgo.ApplicationCommandOptionSubCommand,
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "welcome",
+				Description: "🖼️ | Adjuntar la tarjeta en las bienvenidas",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "farewell",
+				Description: "🖼️ | Adjuntar la tarjeta en las despedidas",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "background",
+				Description: "🎨 | Fondo predefinido de la tarjeta",
+				Required:    false,
+				Choices:     backgroundChoices,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "font",
+				Description: "🔤 | Estilo de la fuente",
+				Required:    false,
+				Choices:     fontChoices,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "accent",
+				Description: "🎨 | Color de acento en hexadecimal (ej: #5865F2)",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "custom_background",
+				Description: "💎 | URL de una imagen de fondo (Premium). Escribe 'none' para quitarla",
+				Required:    false,
+			},
+		},
+	}
+}
+
+func handleCard(ctx *discord.CommandContext, options []*discordgo.ApplicationCommandInteractionDataOption) error {
+	guildID := ctx.Interaction.GuildID
+	if guildID == "" {
+		return ctx.ReplyEphemeral("❌ Este comando solo puede usarse en un servidor.")
+	}
+
+	guildDoc, err := database.GlobalGuildDM.WithContext(ctx.Context()).Get(bson.M{"id": guildID})
+	if err != nil {
+		return ctx.ReplyEphemeral(fmt.Sprintf("❌ Error obteniendo configuración: %v", err))
+	}
+	if guildDoc == nil {
+		guildDoc = models.NewDefaultGuildDocument(guildID)
+	}
+
+	card := &guildDoc.Greetings.Card
+	for _, opt := range options {
+		switch opt.Name {
+		case "welcome":
+			guildDoc.Greetings.Welcome.UseCard = opt.BoolValue()
+		case "farewell":
+			guildDoc.Greetings.Farewell.UseCard = opt.BoolValue()
+		case "background":
+			card.Background = opt.StringValue()
+		case "font":
+			card.Font = opt.StringValue()
+		case "accent":
+			accent, err := imaging.ParseHexColor(opt.StringValue())
+			if err != nil {
+				return ctx.ReplyEphemeral("❌ Color inválido. Usa un valor hexadecimal como `#5865F2`.")
+			}
+			card.AccentColor = accent
+		case "custom_background":
+			value := strings.TrimSpace(opt.StringValue())
+			if strings.EqualFold(value, "none") {
+				card.CustomBackground = ""
+				continue
+			}
+			if !strings.HasPrefix(value, "https://") {
+				return ctx.ReplyEphemeral("❌ La URL del fondo debe empezar por `https://`.")
+			}
+			premium, _, err := database.IsGuildPremium(guildID)
+			if err != nil || !premium {
+				return ctx.ReplyEphemeral("💎 Los fondos personalizados son exclusivos de servidores **Premium**.")
+			}
+			card.CustomBackground = value
+		}
+	}
+
+	_, err = database.GlobalGuildDM.WithContext(ctx.Context()).Set(bson.M{"id": guildID}, guildDoc)
+	if err != nil {
+		return ctx.ReplyEphemeral(fmt.Sprintf("❌ Error guardando configuración: %v", err))
+	}
+	cards.ForgetBackground(guildID)
+
+	if err := ctx.Defer(); err != nil {
+		return err
+	}
+
+	embed := cardSummaryEmbed(guildDoc)
+	edit := &discordgo.WebhookEdit{Embeds: &[]*discordgo.MessageEmbed{embed}}
+
+	// Preview using the member that ran the command
+	if guild := ctx.Guild(); guild != nil {
+		if file, err := cards.Welcome(ctx.User(), guild, guildDoc); err == nil {
+			embed.Image = &discordgo.MessageEmbedImage{URL: "attachment://" + file.Name}
+			edit.Files = []*discordgo.File{file}
+		}
+	}
+
+	_, err = ctx.Session.InteractionResponseEdit(ctx.Interaction.Interaction, edit)
+	return err
+}
+
+// cardSummaryEmbed describes the current card settings of the guild
+func cardSummaryEmbed(guildDoc *models.GuildDocument) *discordgo.MessageEmbed {
+	card := guildDoc.Greetings.Card
+	onOff := func(v bool) string {
+		if v {
+			return "✅ Activada"
+		}
+		return "❌ Desactivada"
+	}
+
+	custom := "Ninguno"
+	if card.CustomBackground != "" {
+		custom = card.CustomBackground
+	}
+	accent := card.AccentColor
+	if accent == 0 {
+		accent = imaging.DefaultAccentColor
+	}
+
+	return discord.NewEmbed().
+		SetTitle("🖼️ Tarjetas actualizadas").
+		SetColor(accent).
+		AddField("Bienvenidas", onOff(guildDoc.Greetings.Welcome.UseCard), true).
+		AddField("Despedidas", onOff(guildDoc.Greetings.Farewell.UseCard), true).
+		AddField("Fondo", valueOr(card.Background, imaging.DefaultBackground), true).
+		AddField("Fuente", valueOr(card.Font, imaging.DefaultFont), true).
+		AddField("Acento", fmt.Sprintf("#%06X", accent), true).
+		AddField("Fondo personalizado", custom, false).
+		Build()
+}
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -40,7 +40,7 @@ func handleLogs(ctx *discord.CommandContext, options []*discordgo.ApplicationCom
 		return ctx.ReplyEphemeral("❌ Este comando solo puede usarse en un servidor.")
 	}
 
-	guildDoc, err := database.GlobalGuildDM.Get(bson.M{"id": guildID})
+	guildDoc, err := database.GlobalGuildDM.WithContext(ctx.Context()).Get(bson.M{"id": guildID})
 	if err != nil {
 		return ctx.ReplyEphemeral(fmt.Sprintf("❌ Error obteniendo configuración: %v", err))
 	}
@@ -51,7 +51,7 @@ func handleLogs(ctx *discord.CommandContext, options []*discordgo.ApplicationCom
 
 	guildDoc.Configuration.LogsChannel = channelID
 
-	_, err = database.GlobalGuildDM.Set(bson.M{"id": guildID}, guildDoc)
+	_, err = database.GlobalGuildDM.WithContext(ctx.Context()).Set(bson.M{"id": guildID}, guildDoc)
 	if err != nil {
 		return ctx.ReplyEphemeral(fmt.Sprintf("❌ Error guardando configuración: %v", err))
 	}
@@ -87,7 +87,7 @@ func handlePojCommand(ctx *discord.CommandContext) error {
 		return ctx.Reply(fmt.Sprintf("✅ Configuración de PoJ removida del canal <#%s>.", channelID))
 
 	case "list":
-		doc, err := database.GlobalGuildDM.Get(bson.M{"id": ctx.Interaction.GuildID})
+		doc, err := database.GlobalGuildDM.WithContext(ctx.Context()).Get(bson.M{"id": ctx.Interaction.GuildID})
 		if err != nil || len(doc.PingOnJoin) == 0 {
 			return ctx.Reply("ℹ️ No hay configuraciones de Ping On Join activas.")
 		}
@@ -27,6 +27,8 @@ func Register(client *discord.ExtendedClient) {
 	configCmd.Options = append(configCmd.Options, farewellSubcommand())
 	configCmd.Options = append(configCmd.Options, autoroleSubcommand())
 	configCmd.Options = append(configCmd.Options, logsSubcommand())
+	configCmd.Options = append(configCmd.Options, cardSubcommand())
+	configCmd.Options = append(configCmd.Options, templateSubcommand())
 
 	// Register the command with the client
 	client.CommandHandler.RegisterCommand(configCmd)
@@ -63,6 +65,10 @@ func HandleSubcommand(ctx *discord.CommandContext) error {
 		return handleAutorole(ctx, options[0].Options)
 	case "logs":
 		return handleLogs(ctx, options[0].Options)
+	case "card":
+		return handleCard(ctx, options[0].Options)
+	case "template":
+		return handleTemplate(ctx, options[0].Options)
 	default:
 		return ctx.ReplyEphemeral("❌ Subcomando no encontrado.")
 	}
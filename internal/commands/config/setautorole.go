@@ -68,7 +68,7 @@ func handleAutorole(ctx *discord.CommandContext, options []*discordgo.Applicatio
 		return ctx.ReplyEphemeral("❌ Este comando solo puede usarse en un servidor.")
 	}
 
-	guildDoc, err := database.GlobalGuildDM.Get(bson.M{"id": guildID})
+	guildDoc, err := database.GlobalGuildDM.WithContext(ctx.Context()).Get(bson.M{"id": guildID})
 	if err != nil {
 		return ctx.ReplyEphemeral(fmt.Sprintf("❌ Error obteniendo configuración: %v", err))
 	}
@@ -95,7 +95,7 @@ func handleAutorole(ctx *discord.CommandContext, options []*discordgo.Applicatio
 		guildDoc.Greetings.Autorole.Delay = delay
 	}
 
-	_, err = database.GlobalGuildDM.Set(bson.M{"id": guildID}, guildDoc)
+	_, err = database.GlobalGuildDM.WithContext(ctx.Context()).Set(bson.M{"id": guildID}, guildDoc)
 	if err != nil {
 		return ctx.ReplyEphemeral(fmt.Sprintf("❌ Error guardando configuración: %v", err))
 	}
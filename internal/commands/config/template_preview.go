@@ -0,0 +1,103 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/templates"
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func templateSubcommand() *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Name:        "template",
+		Description: "🧩 | Previsualiza una plantilla de mensaje usando tus datos",
+		Type:        discordgo.ApplicationCommandOptionSubCommand,
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "text",
+				Description: "🧩 | Texto de la plantilla (vacío para ver las variables disponibles)",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "embed",
+				Description: "🧩 | ID de un embed guardado para previsualizarlo",
+				Required:    false,
+			},
+		},
+	}
+}
+
+func handleTemplate(ctx *discord.CommandContext, options []*discordgo.ApplicationCommandInteractionDataOption) error {
+	var text, embedID string
+	for _, opt := range options {
+		switch opt.Name {
+		case "text":
+			text = opt.StringValue()
+		case "embed":
+			embedID = opt.StringValue()
+		}
+	}
+
+	guildID := ctx.Interaction.GuildID
+	if guildID == "" {
+		return ctx.ReplyEphemeral("❌ Este comando solo puede usarse en un servidor.")
+	}
+
+	if text == "" && embedID == "" {
+		return ctx.ReplyEphemeralEmbed(discord.NewEmbed().
+			SetTitle("🧩 Variables disponibles").
+			SetDescription(templates.Help()).
+			SetColor(discord.ColorInfo).
+			Build())
+	}
+
+	vars := templates.NewVars(ctx.User(), ctx.Guild())
+	if profile, err := database.GetLocalLevelProfile(guildID, ctx.User().ID); err == nil && profile != nil {
+		vars.WithLevel(profile.Level, profile.XP)
+	}
+
+	embeds := make([]*discordgo.MessageEmbed, 0, 2)
+
+	if text != "" {
+		preview := discord.NewEmbed().
+			SetTitle("🧩 Vista previa").
+			SetDescription(templates.Render(text, vars)).
+			SetColor(discord.ColorSuccess)
+		if err := templates.Validate(text); err != nil {
+			preview.SetColor(discord.ColorWarning).AddField("⚠️ Problemas", templates.Explain(err), false)
+		}
+		embeds = append(embeds, preview.Build())
+	}
+
+	if embedID != "" {
+		guildDoc, err := database.GlobalGuildDM.WithContext(ctx.Context()).Get(bson.M{"id": guildID})
+		if err != nil {
+			return ctx.ReplyEphemeral(fmt.Sprintf("❌ Error obteniendo configuración: %v", err))
+		}
+		ce := templates.FindEmbed(guildDoc, embedID)
+		if ce == nil {
+			return ctx.ReplyEphemeral(fmt.Sprintf("❌ No existe ningún embed con el ID `%s`.", embedID))
+		}
+		embeds = append(embeds, templates.RenderEmbed(*ce, vars))
+		if err := templates.ValidateEmbed(*ce); err != nil {
+			embeds = append(embeds, discord.NewEmbed().
+				SetTitle("⚠️ Problemas en el embed").
+				SetDescription(templates.Explain(err)).
+				SetColor(discord.ColorWarning).
+				Build())
+		}
+	}
+
+	return ctx.Session.InteractionRespond(ctx.Interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: embeds,
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/PancyStudios/PancyBotGo/pkg/database"
 	"github.com/PancyStudios/PancyBotGo/pkg/discord"
 	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/PancyStudios/PancyBotGo/pkg/templates"
 	"github.com/bwmarrin/discordgo"
 	"go.mongodb.org/mongo-driver/bson"
 )
@@ -32,7 +33,7 @@ func welcomeSubcommand() *discordgo.ApplicationCommandOption {
 			{
 				Type:        discordgo.ApplicationCommandOptionString,
 				Name:        "message",
-				Description: "⚙️ | Mensaje de bienvenida (usa {user} para mencionar al usuario)",
+				Description: "⚙️ | Mensaje de bienvenida (admite variables como {user} o {server.members})",
 				Required:    false,
 			},
 			{
@@ -69,7 +70,11 @@ func handleWelcome(ctx *discord.CommandContext, options []*discordgo.Application
 		return ctx.ReplyEphemeral("❌ Este comando solo puede usarse en un servidor.")
 	}
 
-	guildDoc, err := database.GlobalGuildDM.Get(bson.M{"id": guildID})
+	if err := templates.Validate(message); err != nil {
+		return ctx.ReplyEphemeral("❌ El mensaje tiene errores:\n" + templates.Explain(err))
+	}
+
+	guildDoc, err := database.GlobalGuildDM.WithContext(ctx.Context()).Get(bson.M{"id": guildID})
 	if err != nil {
 		return ctx.ReplyEphemeral(fmt.Sprintf("❌ Error obteniendo configuración: %v", err))
 	}
@@ -101,7 +106,7 @@ func handleWelcome(ctx *discord.CommandContext, options []*discordgo.Application
 		guildDoc.Greetings.Welcome.IsDM = isDM
 	}
 
-	_, err = database.GlobalGuildDM.Set(bson.M{"id": guildID}, guildDoc)
+	_, err = database.GlobalGuildDM.WithContext(ctx.Context()).Set(bson.M{"id": guildID}, guildDoc)
 	if err != nil {
 		return ctx.ReplyEphemeral(fmt.Sprintf("❌ Error guardando configuración: %v", err))
 	}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/PancyStudios/PancyBotGo/pkg/database"
 	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/items"
 	"github.com/PancyStudios/PancyBotGo/pkg/models"
 	"github.com/bwmarrin/discordgo"
 	"github.com/google/uuid"
@@ -51,18 +52,14 @@ func createAdminShopCommand() *discord.Command {
 				{
 					Type:        discordgo.ApplicationCommandOptionString,
 					Name:        "efecto",
-					Description: "✨ | Efecto (NONE, EXPAND_BANK, GIVE_ROLE)",
+					Description: "✨ | Qué hace el objeto al usarlo o tenerlo",
 					Required:    false,
-					Choices: []*discordgo.ApplicationCommandOptionChoice{
-						{Name: "Ninguno", Value: "NONE"},
-						{Name: "Expandir Banco", Value: "EXPAND_BANK"},
-						{Name: "Otorgar Rol", Value: "GIVE_ROLE"},
-					},
+					Choices:     effectChoices(),
 				},
 				{
 					Type:        discordgo.ApplicationCommandOptionNumber,
 					Name:        "valor_efecto",
-					Description: "✨ | Valor numérico del efecto (ej. 1000 para capacidad de banco)",
+					Description: "✨ | Cantidad, multiplicador (ej. 1.5) o porcentaje del efecto",
 					Required:    false,
 				},
 				{
@@ -71,6 +68,26 @@ func createAdminShopCommand() *discord.Command {
 					Description: "✨ | El rol a otorgar (solo si el efecto es GIVE_ROLE)",
 					Required:    false,
 				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "duracion",
+					Description: "⏳ | Minutos que dura el efecto o el rol (obligatorio en multiplicadores y escudos)",
+					Required:    false,
+					MinValue:    func() *float64 { v := 1.0; return &v }(),
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "stock",
+					Description: "📦 | Unidades a la venta (sin indicar = ilimitado)",
+					Required:    false,
+					MinValue:    func() *float64 { v := 0.0; return &v }(),
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "obligatorio",
+					Description: "🛠️ | Herramientas: el comando no se puede usar sin una",
+					Required:    false,
+				},
 				{
 					Type:        discordgo.ApplicationCommandOptionString,
 					Name:        "id",
@@ -108,6 +125,9 @@ func adminShopHandler(ctx *discord.CommandContext) error {
 		effectValue := float64(0)
 		roleID := ""
 		customID := ""
+		duration := 0
+		stock := -1
+		required := false
 
 		for _, opt := range subcommand.Options {
 			switch opt.Name {
@@ -127,6 +147,12 @@ func adminShopHandler(ctx *discord.CommandContext) error {
 				roleID = opt.RoleValue(ctx.Session, ctx.Interaction.GuildID).ID
 			case "id":
 				customID = opt.StringValue()
+			case "duracion":
+				duration = int(opt.IntValue())
+			case "stock":
+				stock = int(opt.IntValue())
+			case "obligatorio":
+				required = opt.BoolValue()
 			}
 		}
 
@@ -135,13 +161,6 @@ func adminShopHandler(ctx *discord.CommandContext) error {
 			return nil
 		}
 
-		itemType := models.ItemTypeCollectible
-		if effect == "GIVE_ROLE" {
-			itemType = models.ItemTypeRole
-		} else if effect != "NONE" {
-			itemType = models.ItemTypeConsumable
-		}
-
 		if customID == "" {
 			customID = "EC-" + uuid.New().String()[:8]
 		} else if len(customID) < 3 || customID[:3] != "EC-" {
@@ -156,13 +175,20 @@ func adminShopHandler(ctx *discord.CommandContext) error {
 			Description: desc,
 			Price:       price,
 			SellPrice:   price / 2,
-			Type:        itemType,
 			Emoji:       emoji,
-			Stock:       -1,
+			Stock:       stock,
 			Effect:      effect,
 			EffectValue: effectValue,
 			RoleID:      roleID,
+			Duration:    duration,
+			Required:    required,
+		}
+
+		if err := items.Validate(item); err != nil {
+			ctx.Reply("❌ El efecto no es válido: " + items.Explain(err))
+			return nil
 		}
+		item.Type = items.Of(item).Type
 
 		err := database.SaveItem(item)
 		if err != nil {
@@ -205,3 +231,15 @@ func adminShopHandler(ctx *discord.CommandContext) error {
 
 	return nil
 }
+
+// effectChoices lists the registered item effects for the admin command
+func effectChoices() []*discordgo.ApplicationCommandOptionChoice {
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, e := range items.All() {
+		if e.Scope == items.ScopeGlobal {
+			continue // The local shop can't sell global only effects
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: e.Name, Value: e.ID})
+	}
+	return choices
+}
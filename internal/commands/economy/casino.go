@@ -0,0 +1,161 @@
+package economy
+
+import (
+	"github.com/PancyStudios/PancyBotGo/pkg/casino"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/ecoconfig"
+	"github.com/bwmarrin/discordgo"
+)
+
+func betOption() *discordgo.ApplicationCommandOption {
+	one := 1.0
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionInteger,
+		Name:        "apuesta",
+		Description: "🎲 | Cantidad que quieres apostar",
+		Required:    true,
+		MinValue:    &one,
+	}
+}
+
+func createBlackjackCommand(isGlobal bool) *discord.Command {
+	return discord.NewCommand(
+		"blackjack",
+		"🃏 | Juega una mano de blackjack contra el crupier",
+		"economy",
+		func(ctx *discord.CommandContext) error {
+			return blackjackHandler(ctx, isGlobal)
+		},
+	).WithOptions(betOption())
+}
+
+func blackjackHandler(ctx *discord.CommandContext, isGlobal bool) error {
+	guildID := ctx.Interaction.GuildID
+	_, err := casino.OpenBlackjack(ctx.Session, guildID, ctx.Interaction.ChannelID, ctx.Interaction.Member.User.ID, isGlobal, ctx.GetIntOption("apuesta"))
+	if err != nil {
+		return ctx.ReplyEphemeral(casino.ErrorText(err, ecoconfig.CasinoFor(guildID, isGlobal)))
+	}
+	return ctx.ReplyEphemeral("🃏 ¡Mesa abierta! Juega tu mano con los botones.")
+}
+
+func createSlotsCommand(isGlobal bool) *discord.Command {
+	return discord.NewCommand(
+		"slots",
+		"🎰 | Prueba suerte en las tragaperras",
+		"economy",
+		func(ctx *discord.CommandContext) error {
+			return slotsHandler(ctx, isGlobal)
+		},
+	).WithOptions(betOption())
+}
+
+func slotsHandler(ctx *discord.CommandContext, isGlobal bool) error {
+	guildID := ctx.Interaction.GuildID
+	result, err := casino.Slots(guildID, ctx.Interaction.Member.User.ID, isGlobal, ctx.GetIntOption("apuesta"))
+	return replyCasinoResult(ctx, isGlobal, "🎰 Tragaperras", result.Wager.Rules.SlotsTable(), result, err)
+}
+
+func createCoinflipCommand(isGlobal bool) *discord.Command {
+	return discord.NewCommand(
+		"coinflip",
+		"🪙 | Apuesta a cara o cruz",
+		"economy",
+		func(ctx *discord.CommandContext) error {
+			return coinflipHandler(ctx, isGlobal)
+		},
+	).WithOptions(
+		betOption(),
+		&discordgo.ApplicationCommandOption{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "lado",
+			Description: "🪙 | Lado de la moneda",
+			Required:    true,
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{Name: "Cara", Value: casino.Heads},
+				{Name: "Cruz", Value: casino.Tails},
+			},
+		},
+	)
+}
+
+func coinflipHandler(ctx *discord.CommandContext, isGlobal bool) error {
+	guildID := ctx.Interaction.GuildID
+	result, err := casino.Coinflip(guildID, ctx.Interaction.Member.User.ID, isGlobal, ctx.GetIntOption("apuesta"), ctx.GetStringOption("lado"))
+	return replyCasinoResult(ctx, isGlobal, "🪙 Cara o cruz", "", result, err)
+}
+
+func createRouletteCommand(isGlobal bool) *discord.Command {
+	return discord.NewCommand(
+		"roulette",
+		"🎡 | Apuesta en la ruleta a un color, paridad, mitad o número",
+		"economy",
+		func(ctx *discord.CommandContext) error {
+			return rouletteHandler(ctx, isGlobal)
+		},
+	).WithOptions(
+		betOption(),
+		&discordgo.ApplicationCommandOption{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "a",
+			Description: "🎡 | rojo, negro, par, impar, bajo (1-18), alto (19-36) o un número del 0 al 36",
+			Required:    true,
+			MaxLength:   6,
+		},
+	)
+}
+
+func rouletteHandler(ctx *discord.CommandContext, isGlobal bool) error {
+	guildID := ctx.Interaction.GuildID
+	result, err := casino.Roulette(guildID, ctx.Interaction.Member.User.ID, isGlobal, ctx.GetIntOption("apuesta"), ctx.GetStringOption("a"))
+	return replyCasinoResult(ctx, isGlobal, "🎡 Ruleta", casino.RouletteTable, result, err)
+}
+
+func createDuelCommand(isGlobal bool) *discord.Command {
+	return discord.NewCommand(
+		"duel",
+		"⚔️ | Reta a otro usuario: ambos apostáis y el ganador se lleva el bote",
+		"economy",
+		func(ctx *discord.CommandContext) error {
+			return duelHandler(ctx, isGlobal)
+		},
+	).WithOptions(
+		&discordgo.ApplicationCommandOption{
+			Type:        discordgo.ApplicationCommandOptionUser,
+			Name:        "usuario",
+			Description: "⚔️ | Usuario al que quieres retar",
+			Required:    true,
+		},
+		betOption(),
+	)
+}
+
+func duelHandler(ctx *discord.CommandContext, isGlobal bool) error {
+	opponent := ctx.GetUserOption("usuario")
+	user := ctx.Interaction.Member.User
+	guildID := ctx.Interaction.GuildID
+
+	if opponent == nil || opponent.ID == user.ID {
+		return ctx.ReplyEphemeral("❌ No puedes retarte a ti mismo.")
+	}
+	if opponent.Bot {
+		return ctx.ReplyEphemeral("❌ Los bots no tienen economía.")
+	}
+
+	_, err := casino.OpenDuel(ctx.Session, guildID, ctx.Interaction.ChannelID, user.ID, opponent.ID, isGlobal, ctx.GetIntOption("apuesta"))
+	if err != nil {
+		return ctx.ReplyEphemeral(casino.ErrorText(err, ecoconfig.CasinoFor(guildID, isGlobal)))
+	}
+	return ctx.ReplyEphemeral("⚔️ Reto enviado. Tu apuesta queda retenida hasta que responda.")
+}
+
+// replyCasinoResult shows the outcome of an instant game, or why it was not played
+func replyCasinoResult(ctx *discord.CommandContext, isGlobal bool, title, footer string, result casino.Result, err error) error {
+	if result.Wager.Amount == 0 {
+		return ctx.ReplyEphemeral(casino.ErrorText(err, ecoconfig.CasinoFor(ctx.Interaction.GuildID, isGlobal)))
+	}
+	if replyErr := ctx.ReplyEmbed(casino.ResultEmbed(title, result, footer)); replyErr != nil {
+		return replyErr
+	}
+	// A prize that could not be paid
+	return err
+}
@@ -0,0 +1,393 @@
+package economy
+
+import (
+	"fmt"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/ecoconfig"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func economyCommandChoices() []*discordgo.ApplicationCommandOptionChoice {
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(ecoconfig.Commands))
+	for _, command := range ecoconfig.Commands {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: command, Value: command})
+	}
+	return choices
+}
+
+func createEconomyConfigCommand() *discord.Command {
+	zero := func() *float64 { v := 0.0; return &v }
+	return &discord.Command{
+		Name:            "config",
+		Description:     "⚙️ | Configura la economía local del servidor",
+		UserPermissions: discordgo.PermissionManageGuild,
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "view",
+				Description: "📋 | Muestra la configuración actual",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "general",
+				Description: "💵 | Moneda, saldo inicial y capacidad del banco",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "moneda",
+						Description: "🏷️ | Nombre de la moneda (ej. monedas)",
+						Required:    false,
+						MaxLength:   32,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "emoji",
+						Description: "😀 | Emoji de la moneda",
+						Required:    false,
+						MaxLength:   64,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "saldo_inicial",
+						Description: "💰 | Monedas con las que empieza cada usuario",
+						Required:    false,
+						MinValue:    zero(),
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "banco",
+						Description: "🏦 | Capacidad del banco de los nuevos usuarios (0 = por defecto)",
+						Required:    false,
+						MinValue:    zero(),
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "command",
+				Description: "🎛️ | Ajusta pagos, probabilidad, multas y cooldown de un comando (0 = por defecto)",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "comando",
+						Description: "🎛️ | Comando a configurar",
+						Required:    true,
+						Choices:     economyCommandChoices(),
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "activar",
+						Description: "⚙️ | Activar o desactivar el comando",
+						Required:    false,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "pago_min",
+						Description: "💰 | Pago mínimo (en rob, % de la cartera de la víctima)",
+						Required:    false,
+						MinValue:    zero(),
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "pago_max",
+						Description: "💰 | Pago máximo (en rob, % de la cartera de la víctima)",
+						Required:    false,
+						MinValue:    zero(),
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "probabilidad",
+						Description: "🎲 | Probabilidad de éxito en %",
+						Required:    false,
+						MinValue:    zero(),
+						MaxValue:    100,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "multa_min",
+						Description: "🚔 | Multa mínima (en rob, % de tu cartera)",
+						Required:    false,
+						MinValue:    zero(),
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "multa_max",
+						Description: "🚔 | Multa máxima (en rob, % de tu cartera)",
+						Required:    false,
+						MinValue:    zero(),
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "cooldown",
+						Description: "⏱️ | Cooldown en minutos",
+						Required:    false,
+						MinValue:    zero(),
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "messages",
+				Description: "💬 | Agrega respuestas personalizadas, o bórralas si no indicas mensaje",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "comando",
+						Description: "🎛️ | Comando de las respuestas",
+						Required:    true,
+						Choices:     economyCommandChoices(),
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "tipo",
+						Description: "💬 | Respuesta de éxito o de fracaso",
+						Required:    true,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{Name: "Éxito", Value: "success"},
+							{Name: "Fracaso", Value: "fail"},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "mensaje",
+						Description: "✏️ | Texto de la respuesta, usa {amount} y {currency}",
+						Required:    false,
+						MaxLength:   300,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "casino",
+				Description: "🎰 | Límites de apuesta, ventaja de la casa y premios de las tragaperras (0 = por defecto)",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "activar",
+						Description: "⚙️ | Activar o desactivar el casino",
+						Required:    false,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "apuesta_min",
+						Description: "🎲 | Apuesta mínima",
+						Required:    false,
+						MinValue:    zero(),
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "apuesta_max",
+						Description: "🎲 | Apuesta máxima",
+						Required:    false,
+						MinValue:    zero(),
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "ventaja",
+						Description: "🏦 | % de las ganancias que se queda la casa",
+						Required:    false,
+						MinValue:    zero(),
+						MaxValue:    50,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "simbolo",
+						Description: "🎰 | Premio de las tragaperras a cambiar",
+						Required:    false,
+						Choices:     slotsChoices(),
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionNumber,
+						Name:        "multiplicador",
+						Description: "🎰 | Multiplicador de la apuesta para ese premio",
+						Required:    false,
+						MinValue:    zero(),
+						MaxValue:    1000,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "reset",
+				Description: "♻️ | Vuelve a los valores por defecto",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "comando",
+						Description: "🎛️ | Comando a restablecer (vacío = toda la economía)",
+						Required:    false,
+						Choices:     append(economyCommandChoices(), &discordgo.ApplicationCommandOptionChoice{Name: "casino", Value: "casino"}),
+					},
+				},
+			},
+		},
+		Run: economyConfigHandler,
+	}
+}
+
+func economyConfigHandler(ctx *discord.CommandContext) error {
+	guildID := ctx.Interaction.GuildID
+	if guildID == "" {
+		return ctx.ReplyEphemeral("❌ Este comando solo se puede usar en un servidor.")
+	}
+
+	guildData, err := database.GlobalGuildDM.WithContext(ctx.Context()).Get(bson.M{"id": guildID})
+	if err != nil {
+		return ctx.ReplyEphemeral(fmt.Sprintf("❌ Error obteniendo configuración: %v", err))
+	}
+	if guildData == nil {
+		guildData = models.NewDefaultGuildDocument(guildID)
+	}
+
+	cfg := guildData.Economy
+	if cfg.Commands == nil {
+		cfg.Commands = make(map[string]models.EconomyCommandConfig)
+	}
+
+	switch {
+	case ctx.HasOption("view"):
+		return ctx.ReplyEmbed(economyConfigEmbed(cfg))
+
+	case ctx.HasOption("general"):
+		if ctx.HasOption("moneda") {
+			cfg.CurrencyName = ctx.GetStringOption("moneda")
+		}
+		if ctx.HasOption("emoji") {
+			cfg.CurrencyEmoji = ctx.GetStringOption("emoji")
+		}
+		if ctx.HasOption("saldo_inicial") {
+			cfg.StartingBalance = ctx.GetIntOption("saldo_inicial")
+		}
+		if ctx.HasOption("banco") {
+			cfg.BankCapacity = ctx.GetIntOption("banco")
+		}
+
+	case ctx.HasOption("command"):
+		command := ctx.GetStringOption("comando")
+		custom := cfg.Commands[command]
+		if ctx.HasOption("activar") {
+			custom.Disabled = !ctx.GetBoolOption("activar")
+		}
+		if ctx.HasOption("pago_min") {
+			custom.MinPayout = ctx.GetIntOption("pago_min")
+		}
+		if ctx.HasOption("pago_max") {
+			custom.MaxPayout = ctx.GetIntOption("pago_max")
+		}
+		if ctx.HasOption("probabilidad") {
+			custom.SuccessRate = int(ctx.GetIntOption("probabilidad"))
+		}
+		if ctx.HasOption("multa_min") {
+			custom.MinFine = ctx.GetIntOption("multa_min")
+		}
+		if ctx.HasOption("multa_max") {
+			custom.MaxFine = ctx.GetIntOption("multa_max")
+		}
+		if ctx.HasOption("cooldown") {
+			custom.CooldownMinutes = int(ctx.GetIntOption("cooldown"))
+		}
+		cfg.Commands[command] = custom
+
+	case ctx.HasOption("messages"):
+		command := ctx.GetStringOption("comando")
+		custom := cfg.Commands[command]
+		messages := &custom.Messages
+		if ctx.GetStringOption("tipo") == "fail" {
+			messages = &custom.FailMessages
+		}
+		if message := ctx.GetStringOption("mensaje"); message != "" {
+			*messages = append(*messages, message)
+		} else {
+			*messages = nil
+		}
+		cfg.Commands[command] = custom
+
+	case ctx.HasOption("casino"):
+		if ctx.HasOption("activar") {
+			cfg.Casino.Disabled = !ctx.GetBoolOption("activar")
+		}
+		if ctx.HasOption("apuesta_min") {
+			cfg.Casino.MinBet = ctx.GetIntOption("apuesta_min")
+		}
+		if ctx.HasOption("apuesta_max") {
+			cfg.Casino.MaxBet = ctx.GetIntOption("apuesta_max")
+		}
+		if ctx.HasOption("ventaja") {
+			cfg.Casino.HouseEdge = int(ctx.GetIntOption("ventaja"))
+		}
+		if symbol := ctx.GetStringOption("simbolo"); symbol != "" && ctx.HasOption("multiplicador") {
+			setSlotsPayout(&cfg.Casino, symbol, ctx.GetFloatOption("multiplicador"))
+		}
+
+	case ctx.HasOption("reset"):
+		if command := ctx.GetStringOption("comando"); command == "casino" {
+			cfg.Casino = models.CasinoConfig{}
+		} else if command != "" {
+			delete(cfg.Commands, command)
+		} else {
+			cfg = models.EconomyConfig{Commands: make(map[string]models.EconomyCommandConfig)}
+		}
+	}
+
+	if err := ecoconfig.Validate(cfg); err != nil {
+		return ctx.ReplyEphemeral("❌ Configuración inválida: " + ecoconfig.Explain(err))
+	}
+
+	guildData.Economy = cfg
+	if _, err = database.GlobalGuildDM.WithContext(ctx.Context()).Set(bson.M{"id": guildID}, guildData); err != nil {
+		return ctx.ReplyEphemeral(fmt.Sprintf("❌ Error guardando configuración: %v", err))
+	}
+
+	return ctx.ReplyEmbed(economyConfigEmbed(cfg))
+}
+
+func economyConfigEmbed(cfg models.EconomyConfig) *discordgo.MessageEmbed {
+	currency := ecoconfig.LocalCurrency(cfg)
+	bank := cfg.BankCapacity
+	if bank <= 0 {
+		bank = database.DefaultLocalBankCapacity
+	}
+
+	embed := discord.NewEmbed().
+		SetTitle("⚙️ Economía del servidor").
+		SetDescription("Los ajustes solo cambian la economía local. Desactivar un comando lo desactiva también en la global.").
+		SetColor(discord.ColorSuccess).
+		AddField("Moneda", fmt.Sprintf("%s %s", currency.Emoji, currency.Name), true).
+		AddField("Saldo inicial", currency.Format(cfg.StartingBalance), true).
+		AddField("Banco inicial", fmt.Sprintf("%d", bank), true)
+
+	for _, command := range ecoconfig.Commands {
+		embed.AddField(command, ecoconfig.Resolve(cfg, command, false).Summary(), false)
+	}
+	casinoRules := ecoconfig.ResolveCasino(cfg, false)
+	embed.AddField("casino", casinoRules.Summary()+"\n"+casinoRules.SlotsTable(), false)
+	return embed.Build()
+}
+
+func slotsChoices() []*discordgo.ApplicationCommandOptionChoice {
+	choices := []*discordgo.ApplicationCommandOptionChoice{{Name: "Pareja", Value: "pareja"}}
+	for _, symbol := range ecoconfig.SlotSymbols {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: symbol + symbol + symbol, Value: symbol})
+	}
+	return choices
+}
+
+// setSlotsPayout changes a prize of the slot machine; "pareja" is two of a kind
+func setSlotsPayout(cfg *models.CasinoConfig, symbol string, multiplier float64) {
+	if symbol == "pareja" {
+		cfg.SlotsPair = multiplier
+		return
+	}
+	if cfg.SlotsPayouts == nil {
+		cfg.SlotsPayouts = make(map[string]float64)
+	}
+	if multiplier == 0 {
+		delete(cfg.SlotsPayouts, symbol)
+		return
+	}
+	cfg.SlotsPayouts[symbol] = multiplier
+}
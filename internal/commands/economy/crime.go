@@ -6,7 +6,10 @@ import (
 	"time"
 
 	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/items"
 	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/ecoconfig"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
 	"github.com/bwmarrin/discordgo"
 )
 
@@ -37,16 +40,20 @@ func crimeHandler(ctx *discord.CommandContext, isGlobal bool) error {
 	userID := ctx.Interaction.Member.User.ID
 	guildID := ctx.Interaction.GuildID
 
-	cooldownDuration := 10 * time.Minute
+	rules := ecoconfig.For(guildID, ecoconfig.CommandCrime, isGlobal)
+	if !rules.Enabled {
+		ctx.Reply(rules.DisabledText())
+		return nil
+	}
 
 	var isReady bool
 	var remaining time.Duration
 	var err error
 
 	if !isGlobal {
-		isReady, remaining, err = database.CooldownLocal(guildID, userID, "crime", cooldownDuration)
+		isReady, remaining, err = database.CooldownLocal(guildID, userID, "crime", rules.Cooldown)
 	} else {
-		isReady, remaining, err = database.CooldownStars(userID, "crime", cooldownDuration)
+		isReady, remaining, err = database.CooldownStars(userID, "crime", rules.Cooldown)
 	}
 
 	if err != nil {
@@ -59,32 +66,42 @@ func crimeHandler(ctx *discord.CommandContext, isGlobal bool) error {
 		return nil
 	}
 
-	// 40% chance of success
-	success := rand.Float64() < 0.40
+	gear, err := items.LoadGear(guildID, userID, isGlobal, items.EffectCrimeTool)
+	if err != nil {
+		ctx.Reply("❌ Error al revisar tu equipo.")
+		return err
+	}
+	if gear.Missing != nil {
+		ctx.Reply(gear.MissingText("cometer crímenes"))
+		return nil
+	}
+
+	// Tools make it easier, up to a 90% chance
+	success := rand.Float64() < gear.Chance(rules.SuccessRate, 0.90)
 
 	if !isGlobal {
 		_ = database.SetCooldownLocal(guildID, userID, "crime")
 
 		if success {
-			amount := int64(rand.Intn(400) + 200)
-			database.AddLocalBalance(guildID, userID, amount, false)
-			ctx.Reply(fmt.Sprintf("🔪 Robaste una tienda y escapaste con **💵 %d monedas**.", amount))
+			amount := rules.Payout()
+			database.AddLocalBalance(guildID, userID, amount, false, database.TxInfo{Type: models.TransactionEarn, Command: "crime"})
+			ctx.Reply(rules.Success("🔪 Robaste una tienda y escapaste con **{amount}**.", amount) + gear.Note(""))
 		} else {
-			fine := int64(rand.Intn(200) + 100)
-			database.AddLocalBalance(guildID, userID, -fine, false) // Subtract money
-			ctx.Reply(fmt.Sprintf("🚔 Te atraparon intentando robar una ancianita. Pagaste una fianza de **💵 %d monedas**.", fine))
+			fine := rules.Fine()
+			database.AddLocalBalance(guildID, userID, -fine, false, database.TxInfo{Type: models.TransactionPenalty, Command: "crime"}) // Subtract money
+			ctx.Reply(rules.Failure("🚔 Te atraparon intentando robar una ancianita. Pagaste una fianza de **{amount}**.", fine))
 		}
 	} else {
 		_ = database.SetCooldownStars(userID, "crime")
 
 		if success {
-			amount := int64(rand.Intn(300) + 150)
-			database.AddStars(userID, amount, false)
-			ctx.Reply(fmt.Sprintf("🔪 Hackeaste el banco intergaláctico y obtuviste **🌟 %d estrellas**.", amount))
+			amount := rules.Payout()
+			database.AddStars(userID, amount, false, database.TxInfo{Type: models.TransactionEarn, Command: "crime"})
+			ctx.Reply(rules.Success("🔪 Hackeaste el banco intergaláctico y obtuviste **{amount}**.", amount) + gear.Note(""))
 		} else {
-			fine := int64(rand.Intn(150) + 50)
-			database.AddStars(userID, -fine, false)
-			ctx.Reply(fmt.Sprintf("🚔 La patrulla espacial te pilló contrabandeando. Pagaste una multa de **🌟 %d estrellas**.", fine))
+			fine := rules.Fine()
+			database.AddStars(userID, -fine, false, database.TxInfo{Type: models.TransactionPenalty, Command: "crime"})
+			ctx.Reply(rules.Failure("🚔 La patrulla espacial te pilló contrabandeando. Pagaste una multa de **{amount}**.", fine))
 		}
 	}
 	return nil
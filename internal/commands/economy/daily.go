@@ -6,6 +6,8 @@ import (
 
 	"github.com/PancyStudios/PancyBotGo/pkg/database"
 	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/ecoconfig"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
 )
 
 func createDailyCommand(isGlobal bool) *discord.Command {
@@ -21,10 +23,23 @@ func createDailyCommand(isGlobal bool) *discord.Command {
 
 func dailyHandler(ctx *discord.CommandContext, isGlobal bool) error {
 	userID := ctx.Interaction.Member.User.ID
+	guildID := ctx.Interaction.GuildID
 
-	cooldownDuration := 24 * time.Hour
+	rules := ecoconfig.For(guildID, ecoconfig.CommandDaily, isGlobal)
+	if !rules.Enabled {
+		ctx.Reply(rules.DisabledText())
+		return nil
+	}
+
+	var isReady bool
+	var remaining time.Duration
+	var err error
 
-	isReady, remaining, err := database.CooldownStars(userID, "daily", cooldownDuration)
+	if !isGlobal {
+		isReady, remaining, err = database.CooldownLocal(guildID, userID, "daily", rules.Cooldown)
+	} else {
+		isReady, remaining, err = database.CooldownStars(userID, "daily", rules.Cooldown)
+	}
 	if err != nil {
 		ctx.Reply("❌ " + "Error al comprobar el cooldown.")
 		return err
@@ -35,16 +50,25 @@ func dailyHandler(ctx *discord.CommandContext, isGlobal bool) error {
 		return nil
 	}
 
-	amount := int64(1000)
+	amount := rules.Payout()
+	info := database.TxInfo{Type: models.TransactionReward, Command: "daily"}
 
-	_, err = database.AddStars(userID, amount, false)
+	if !isGlobal {
+		_, err = database.AddLocalBalance(guildID, userID, amount, false, info)
+	} else {
+		_, err = database.AddStars(userID, amount, false, info)
+	}
 	if err != nil {
 		ctx.Reply("❌ " + "Error al procesar la recompensa.")
 		return err
 	}
 
-	_ = database.SetCooldownStars(userID, "daily")
+	if !isGlobal {
+		_ = database.SetCooldownLocal(guildID, userID, "daily")
+	} else {
+		_ = database.SetCooldownStars(userID, "daily")
+	}
 
-	ctx.Reply(fmt.Sprintf("¡Felicidades! Has reclamado tu recompensa diaria de **🌟 %d estrellas**.", amount))
+	ctx.Reply(rules.Success("¡Felicidades! Has reclamado tu recompensa diaria de **{amount}**.", amount))
 	return nil
 }
@@ -0,0 +1,192 @@
+package economy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	historyPageSize = 10
+	// auditRapidCount is the number of movements with the same user inside the
+	// audit window that gets flagged as suspicious
+	auditRapidCount = 5
+)
+
+func createHistoryCommand(isGlobal bool) *discord.Command {
+	return discord.NewCommand(
+		"history",
+		"📜 | Revisa tus últimos movimientos de dinero",
+		"economy",
+		func(ctx *discord.CommandContext) error {
+			return historyHandler(ctx, isGlobal)
+		},
+	).WithOptions(
+		&discordgo.ApplicationCommandOption{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "pagina",
+			Description: "📜 | Página del historial",
+			Required:    false,
+			MinValue:    func() *float64 { v := 1.0; return &v }(),
+		},
+	)
+}
+
+func historyHandler(ctx *discord.CommandContext, isGlobal bool) error {
+	userID := ctx.Interaction.Member.User.ID
+	page := ctx.GetIntOption("pagina")
+	if page < 1 {
+		page = 1
+	}
+
+	filter := database.TransactionFilter{Currency: models.CurrencyLocal, GuildID: ctx.Interaction.GuildID, UserID: userID}
+	symbol := "💵"
+	if isGlobal {
+		filter = database.TransactionFilter{Currency: models.CurrencyStars, UserID: userID}
+		symbol = "🌟"
+	}
+
+	txs, err := database.GetTransactions(filter, historyPageSize, (page-1)*historyPageSize)
+	if err != nil {
+		ctx.ReplyEphemeral("❌ No se pudo obtener tu historial en este momento.")
+		return err
+	}
+	if len(txs) == 0 {
+		ctx.ReplyEphemeral("📭 No hay movimientos en esta página.")
+		return nil
+	}
+
+	lines := make([]string, 0, len(txs))
+	for _, tx := range txs {
+		lines = append(lines, transactionLine(tx, symbol))
+	}
+
+	embed := discord.NewEmbed().
+		SetTitle(fmt.Sprintf("📜 Historial de %s", ctx.Interaction.Member.User.Username)).
+		SetColor(discord.ColorInfo).
+		SetDescription(strings.Join(lines, "\n")).
+		SetFooter(fmt.Sprintf("Página %d", page), "").
+		Build()
+
+	ctx.ReplyEphemeralEmbed(embed)
+	return nil
+}
+
+func createAuditCommand() *discord.Command {
+	return discord.NewCommand(
+		"audit",
+		"🔎 | Rastrea los movimientos de dinero de un usuario del servidor",
+		"economy",
+		auditHandler,
+	).WithUserPermissions(discordgo.PermissionManageGuild).WithOptions(
+		&discordgo.ApplicationCommandOption{
+			Type:        discordgo.ApplicationCommandOptionUser,
+			Name:        "usuario",
+			Description: "🔎 | Usuario a auditar",
+			Required:    true,
+		},
+		&discordgo.ApplicationCommandOption{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "horas",
+			Description: "🔎 | Ventana de tiempo a revisar (por defecto 168 horas)",
+			Required:    false,
+			MinValue:    func() *float64 { v := 1.0; return &v }(),
+		},
+	)
+}
+
+func auditHandler(ctx *discord.CommandContext) error {
+	target := ctx.GetUserOption("usuario")
+	if target == nil {
+		ctx.ReplyEphemeral("❌ Debes indicar un usuario.")
+		return nil
+	}
+
+	hours := ctx.GetIntOption("horas")
+	if hours < 1 {
+		hours = 168
+	}
+
+	filter := database.TransactionFilter{
+		Currency: models.CurrencyLocal,
+		GuildID:  ctx.Interaction.GuildID,
+		UserID:   target.ID,
+		Since:    time.Now().Add(-time.Duration(hours) * time.Hour),
+	}
+
+	flows, err := database.GetTransactionFlows(filter, 8)
+	if err != nil {
+		ctx.ReplyEphemeral("❌ No se pudo consultar el registro de transacciones.")
+		return err
+	}
+	// Embed fields are limited to 1024 characters, keep the list short
+	txs, err := database.GetTransactions(filter, 5, 0)
+	if err != nil {
+		ctx.ReplyEphemeral("❌ No se pudo consultar el registro de transacciones.")
+		return err
+	}
+
+	embed := discord.NewEmbed().
+		SetTitle(fmt.Sprintf("🔎 Auditoría de %s", target.Username)).
+		SetColor(discord.ColorWarning).
+		SetThumbnail(target.AvatarURL("")).
+		SetDescription(fmt.Sprintf("Movimientos de las últimas **%d horas** en la economía local.", hours))
+
+	embed.AddField("🔁 Flujos con otros usuarios", auditFlowsText(flows), false)
+
+	recent := "Sin movimientos."
+	if len(txs) > 0 {
+		lines := make([]string, 0, len(txs))
+		for _, tx := range txs {
+			lines = append(lines, transactionLine(tx, "💵"))
+		}
+		recent = strings.Join(lines, "\n")
+	}
+	embed.AddField("📜 Últimos movimientos", recent, false)
+
+	ctx.ReplyEphemeralEmbed(embed.Build())
+	return nil
+}
+
+// auditFlowsText lists the money exchanged with each counterparty and flags the
+// ones with many movements or that account for most of what the user received
+func auditFlowsText(flows []*models.TransactionFlow) string {
+	if len(flows) == 0 {
+		return "Sin transferencias, robos ni multas con otros usuarios."
+	}
+
+	var totalReceived int64
+	for _, flow := range flows {
+		totalReceived += flow.Received
+	}
+
+	lines := make([]string, 0, len(flows))
+	for _, flow := range flows {
+		flag := ""
+		if flow.Count >= auditRapidCount || (len(flows) > 1 && totalReceived > 0 && flow.Received*2 > totalReceived) {
+			flag = "⚠️ "
+		}
+		lines = append(lines, fmt.Sprintf("%s<@%s> · recibido **%d** · enviado **%d** · %d movimientos", flag, flow.CounterpartyID, flow.Received, flow.Sent, flow.Count))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// transactionLine renders a ledger entry as a single line
+func transactionLine(tx *models.Transaction, symbol string) string {
+	line := fmt.Sprintf("<t:%d:R> `%s` **%+d** %s", tx.CreatedAt.Unix(), tx.Type.Label(), tx.Amount, symbol)
+	if tx.WalletDelta != 0 && tx.BankDelta != 0 {
+		line = fmt.Sprintf("<t:%d:R> `%s` cartera **%+d**, banco **%+d** %s", tx.CreatedAt.Unix(), tx.Type.Label(), tx.WalletDelta, tx.BankDelta, symbol)
+	}
+	if tx.CounterpartyID != "" {
+		line += fmt.Sprintf(" · <@%s>", tx.CounterpartyID)
+	}
+	if tx.Note != "" {
+		line += " · " + tx.Note
+	}
+	return line
+}
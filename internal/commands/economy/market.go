@@ -0,0 +1,295 @@
+package economy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/bwmarrin/discordgo"
+)
+
+const marketPageSize = 10
+
+func createMarketListCommand() *discord.Command {
+	return discord.NewCommand(
+		"list",
+		"🏪 | Muestra las publicaciones activas del mercado",
+		"economy",
+		marketListHandler,
+	).WithOptions(
+		&discordgo.ApplicationCommandOption{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "pagina",
+			Description: "🏪 | Página del mercado",
+			Required:    false,
+			MinValue:    func() *float64 { v := 1.0; return &v }(),
+		},
+	)
+}
+
+func createMarketSellCommand() *discord.Command {
+	return discord.NewCommand(
+		"sell",
+		"🏷️ | Publica un objeto en el mercado del servidor",
+		"economy",
+		marketSellHandler,
+	).WithOptions(
+		&discordgo.ApplicationCommandOption{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "id",
+			Description: "🏷️ | ID del objeto de tu inventario",
+			Required:    true,
+		},
+		&discordgo.ApplicationCommandOption{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "cantidad",
+			Description: "🏷️ | Cuántas unidades vender",
+			Required:    true,
+			MinValue:    func() *float64 { v := 1.0; return &v }(),
+		},
+		&discordgo.ApplicationCommandOption{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "precio",
+			Description: "🏷️ | Precio de compra inmediata en monedas locales",
+			Required:    false,
+			MinValue:    func() *float64 { v := 1.0; return &v }(),
+		},
+		&discordgo.ApplicationCommandOption{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "puja_minima",
+			Description: "🏷️ | Puja inicial para subastar el objeto",
+			Required:    false,
+			MinValue:    func() *float64 { v := 1.0; return &v }(),
+		},
+		&discordgo.ApplicationCommandOption{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "horas",
+			Description: "🏷️ | Duración de la publicación (máximo 72 horas)",
+			Required:    false,
+			MinValue:    func() *float64 { v := 1.0; return &v }(),
+			MaxValue:    72,
+		},
+	)
+}
+
+func createMarketBuyCommand() *discord.Command {
+	return discord.NewCommand(
+		"buy",
+		"🛍️ | Compra una publicación al precio de compra inmediata",
+		"economy",
+		marketBuyHandler,
+	).WithOptions(
+		&discordgo.ApplicationCommandOption{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "publicacion",
+			Description: "🛍️ | ID de la publicación",
+			Required:    true,
+		},
+	)
+}
+
+func createMarketBidCommand() *discord.Command {
+	return discord.NewCommand(
+		"bid",
+		"🔨 | Puja por una publicación en subasta",
+		"economy",
+		marketBidHandler,
+	).WithOptions(
+		&discordgo.ApplicationCommandOption{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "publicacion",
+			Description: "🔨 | ID de la publicación",
+			Required:    true,
+		},
+		&discordgo.ApplicationCommandOption{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "cantidad",
+			Description: "🔨 | Monedas que pujas",
+			Required:    true,
+			MinValue:    func() *float64 { v := 1.0; return &v }(),
+		},
+	)
+}
+
+func createMarketCancelCommand() *discord.Command {
+	return discord.NewCommand(
+		"cancel",
+		"✖️ | Retira una publicación tuya sin pujas",
+		"economy",
+		marketCancelHandler,
+	).WithOptions(
+		&discordgo.ApplicationCommandOption{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "publicacion",
+			Description: "✖️ | ID de la publicación",
+			Required:    true,
+		},
+	)
+}
+
+func marketListHandler(ctx *discord.CommandContext) error {
+	page := ctx.GetIntOption("pagina")
+	if page < 1 {
+		page = 1
+	}
+
+	listings, err := database.GetActiveListings(ctx.Interaction.GuildID, marketPageSize, (page-1)*marketPageSize)
+	if err != nil {
+		ctx.ReplyEphemeral("❌ No se pudo cargar el mercado.")
+		return err
+	}
+	if len(listings) == 0 {
+		ctx.Reply("🏪 No hay publicaciones activas en esta página. ¡Publica algo con `/market sell`!")
+		return nil
+	}
+
+	lines := make([]string, 0, len(listings))
+	for _, listing := range listings {
+		lines = append(lines, listingLine(listing))
+	}
+
+	embed := discord.NewEmbed().
+		SetTitle("🏪 Mercado del servidor").
+		SetColor(discord.ColorInfo).
+		SetDescription(strings.Join(lines, "\n\n")).
+		SetFooter(fmt.Sprintf("Página %d · Comisión de publicación: %d%%", page, database.ListingFeePercent), "").
+		Build()
+
+	ctx.ReplyEmbed(embed)
+	return nil
+}
+
+func marketSellHandler(ctx *discord.CommandContext) error {
+	itemID := ctx.GetStringOption("id")
+	qty := int(ctx.GetIntOption("cantidad"))
+	price := ctx.GetIntOption("precio")
+	startingBid := ctx.GetIntOption("puja_minima")
+	hours := ctx.GetIntOption("horas")
+
+	items, err := database.GetItems(ctx.Interaction.GuildID)
+	if err != nil {
+		ctx.ReplyEphemeral("❌ Error al acceder a los objetos.")
+		return err
+	}
+	var selectedItem *models.Item
+	for _, it := range items {
+		if it.ID == itemID {
+			copyIt := it
+			selectedItem = &copyIt
+			break
+		}
+	}
+	if selectedItem == nil {
+		ctx.ReplyEphemeral("❌ No existe un objeto con ese ID.")
+		return nil
+	}
+
+	listing, err := database.CreateListing(ctx.Interaction.GuildID, ctx.Interaction.Member.User.ID, *selectedItem, qty, price, startingBid, time.Duration(hours)*time.Hour)
+	if err != nil {
+		ctx.ReplyEphemeral(marketErrorText(err))
+		if isMarketUserError(err) {
+			return nil
+		}
+		return err
+	}
+
+	ctx.Reply(fmt.Sprintf("🏷️ Publicación `%s` creada: **x%d %s**. Se cobró una comisión de 💵 %d.\n%s",
+		listing.ID, listing.Quantity, listing.ItemName, listing.Fee, listingPrices(listing)))
+	return nil
+}
+
+func marketBuyHandler(ctx *discord.CommandContext) error {
+	listing, err := database.BuyListing(ctx.Interaction.GuildID, ctx.GetStringOption("publicacion"), ctx.Interaction.Member.User.ID)
+	if err != nil {
+		ctx.ReplyEphemeral(marketErrorText(err))
+		if isMarketUserError(err) {
+			return nil
+		}
+		return err
+	}
+
+	ctx.Reply(fmt.Sprintf("🛍️ Compraste **x%d %s** a <@%s> por 💵 %d.", listing.Quantity, listing.ItemName, listing.SellerID, listing.BuyNowPrice))
+	return nil
+}
+
+func marketBidHandler(ctx *discord.CommandContext) error {
+	listing, err := database.PlaceBid(ctx.Interaction.GuildID, ctx.GetStringOption("publicacion"), ctx.Interaction.Member.User.ID, ctx.GetIntOption("cantidad"))
+	if err != nil {
+		ctx.ReplyEphemeral(marketErrorText(err))
+		if isMarketUserError(err) {
+			return nil
+		}
+		return err
+	}
+
+	ctx.Reply(fmt.Sprintf("🔨 Pujaste 💵 %d por **x%d %s**. La subasta termina <t:%d:R>.", listing.CurrentBid, listing.Quantity, listing.ItemName, listing.ExpiresAt.Unix()))
+	return nil
+}
+
+func marketCancelHandler(ctx *discord.CommandContext) error {
+	listing, err := database.CancelListing(ctx.Interaction.GuildID, ctx.GetStringOption("publicacion"), ctx.Interaction.Member.User.ID)
+	if err != nil {
+		ctx.ReplyEphemeral(marketErrorText(err))
+		if isMarketUserError(err) {
+			return nil
+		}
+		return err
+	}
+
+	ctx.ReplyEphemeral(fmt.Sprintf("✖️ Publicación `%s` retirada. Los objetos volvieron a tu inventario (la comisión no se devuelve).", listing.ID))
+	return nil
+}
+
+// listingLine renders a listing for the market list
+func listingLine(listing *models.MarketListing) string {
+	return fmt.Sprintf("`%s` %s **%s** x%d · vendedor <@%s> · termina <t:%d:R>\n%s",
+		listing.ID, listing.ItemEmoji, listing.ItemName, listing.Quantity, listing.SellerID, listing.ExpiresAt.Unix(), listingPrices(listing))
+}
+
+func listingPrices(listing *models.MarketListing) string {
+	var parts []string
+	if listing.BuyNowPrice > 0 {
+		parts = append(parts, fmt.Sprintf("Compra inmediata: 💵 %d", listing.BuyNowPrice))
+	}
+	if listing.AcceptsBids() {
+		if listing.BidderID != "" {
+			parts = append(parts, fmt.Sprintf("Puja actual: 💵 %d (<@%s>) · mínima siguiente 💵 %d", listing.CurrentBid, listing.BidderID, database.MinNextBid(listing)))
+		} else {
+			parts = append(parts, fmt.Sprintf("Puja inicial: 💵 %d", listing.StartingBid))
+		}
+	}
+	return strings.Join(parts, " · ")
+}
+
+// isMarketUserError reports whether the error is caused by the user input
+func isMarketUserError(err error) bool {
+	return marketErrorText(err) != marketErrorText(nil)
+}
+
+// marketErrorText explains a marketplace error to the user
+func marketErrorText(err error) string {
+	switch {
+	case errors.Is(err, database.ErrInsufficientFunds):
+		return "❌ No tienes suficientes monedas locales en la cartera."
+	case errors.Is(err, database.ErrInsufficientItems):
+		return "❌ No tienes suficientes unidades de ese objeto."
+	case errors.Is(err, database.ErrListingNotFound):
+		return "❌ No existe una publicación con ese ID en este servidor."
+	case errors.Is(err, database.ErrListingUnavailable):
+		return "❌ Esa publicación ya no está disponible (vendida, retirada, expirada o sin esa opción)."
+	case errors.Is(err, database.ErrInvalidListing):
+		return "❌ Indica un precio de compra inmediata, una puja inicial o ambos (la puja debe ser menor que el precio)."
+	case errors.Is(err, database.ErrOwnListing):
+		return "❌ No puedes comprar ni pujar por tus propias publicaciones."
+	case errors.Is(err, database.ErrBidTooLow):
+		return "❌ La puja es demasiado baja (o alcanza el precio de compra inmediata: usa `/market buy`)."
+	case errors.Is(err, database.ErrListingHasBids):
+		return "❌ No puedes retirar una publicación que ya tiene pujas."
+	default:
+		return "❌ Ocurrió un error en el mercado. Inténtalo más tarde."
+	}
+}
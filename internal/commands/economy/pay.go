@@ -5,6 +5,7 @@ import (
 
 	"github.com/PancyStudios/PancyBotGo/pkg/database"
 	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
 	"github.com/bwmarrin/discordgo"
 )
 
@@ -69,7 +70,7 @@ func payHandler(ctx *discord.CommandContext, isGlobal bool) error {
 
 	var err error
 	if !isGlobal {
-		err = database.TransferLocalBalance(guildID, userID, targetUser.ID, amount)
+		err = database.TransferLocalBalance(guildID, userID, targetUser.ID, amount, database.TxInfo{Type: models.TransactionTransfer, Command: "pay"})
 		if err != nil {
 			if err == database.ErrInsufficientFunds {
 				ctx.Reply("❌ " + "No tienes suficientes monedas locales en tu cartera.")
@@ -80,7 +81,7 @@ func payHandler(ctx *discord.CommandContext, isGlobal bool) error {
 		}
 		ctx.Reply(fmt.Sprintf("Has transferido **💵 %d** monedas locales a %s.", amount, targetUser.Mention()))
 	} else {
-		err = database.TransferStars(userID, targetUser.ID, amount)
+		err = database.TransferStars(userID, targetUser.ID, amount, database.TxInfo{Type: models.TransactionTransfer, Command: "pay"})
 		if err != nil {
 			if err == database.ErrInsufficientFunds {
 				ctx.Reply("❌ " + "No tienes suficientes estrellas en tu cartera.")
@@ -18,6 +18,12 @@ func Register(client *discord.ExtendedClient) {
 	slutGlobal := createSlutCommand(true)
 	robGlobal := createRobCommand(true)
 	topGlobal := createTopCommand(true)
+	historyGlobal := createHistoryCommand(true)
+	blackjackGlobal := createBlackjackCommand(true)
+	slotsGlobal := createSlotsCommand(true)
+	coinflipGlobal := createCoinflipCommand(true)
+	rouletteGlobal := createRouletteCommand(true)
+	duelGlobal := createDuelCommand(true)
 
 	// Create individual eco subcommands (Local)
 	balanceLocal := createBalanceCommand(false)
@@ -31,6 +37,13 @@ func Register(client *discord.ExtendedClient) {
 	slutLocal := createSlutCommand(false)
 	robLocal := createRobCommand(false)
 	topLocal := createTopCommand(false)
+	historyLocal := createHistoryCommand(false)
+	blackjackLocal := createBlackjackCommand(false)
+	slotsLocal := createSlotsCommand(false)
+	coinflipLocal := createCoinflipCommand(false)
+	rouletteLocal := createRouletteCommand(false)
+	duelLocal := createDuelCommand(false)
+	auditLocal := createAuditCommand()
 
 	// Build the /eco command group
 	ecoGroup := client.CommandHandler.BuildCommandGroup(
@@ -47,6 +60,12 @@ func Register(client *discord.ExtendedClient) {
 		slutGlobal,
 		robGlobal,
 		topGlobal,
+		historyGlobal,
+		blackjackGlobal,
+		slotsGlobal,
+		coinflipGlobal,
+		rouletteGlobal,
+		duelGlobal,
 	)
 
 	// Build the /ecol command group
@@ -64,6 +83,13 @@ func Register(client *discord.ExtendedClient) {
 		slutLocal,
 		robLocal,
 		topLocal,
+		historyLocal,
+		auditLocal,
+		blackjackLocal,
+		slotsLocal,
+		coinflipLocal,
+		rouletteLocal,
+		duelLocal,
 	)
 
 	// Create unified shop subcommands
@@ -72,6 +98,7 @@ func Register(client *discord.ExtendedClient) {
 	shopUse := createUseCommand()
 	shopInv := createInventoryCommand()
 	shopAdmin := createAdminShopCommand()
+	shopTrade := createTradeCommand()
 
 	// Build the /shop command group
 	shopGroup := client.CommandHandler.BuildCommandGroup(
@@ -82,10 +109,31 @@ func Register(client *discord.ExtendedClient) {
 		shopUse,
 		shopInv,
 		shopAdmin,
+		shopTrade,
+	)
+
+	// Build the /market command group
+	marketGroup := client.CommandHandler.BuildCommandGroup(
+		"market",
+		"🏪 Mercado de objetos entre usuarios (Servidor)",
+		createMarketListCommand(),
+		createMarketSellCommand(),
+		createMarketBuyCommand(),
+		createMarketBidCommand(),
+		createMarketCancelCommand(),
+	)
+
+	// Build the /economy command group
+	economyGroup := client.CommandHandler.BuildCommandGroup(
+		"economy",
+		"⚙️ Configuración de la economía del servidor",
+		createEconomyConfigCommand(),
 	)
 
 	// Register global groups
 	client.CommandHandler.AddGlobalCommand(ecoGroup)
 	client.CommandHandler.AddGlobalCommand(ecolGroup)
 	client.CommandHandler.AddGlobalCommand(shopGroup)
+	client.CommandHandler.AddGlobalCommand(marketGroup)
+	client.CommandHandler.AddGlobalCommand(economyGroup)
 }
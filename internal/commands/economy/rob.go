@@ -2,11 +2,13 @@ package economy
 
 import (
 	"fmt"
-	"math/rand"
 	"time"
 
 	"github.com/PancyStudios/PancyBotGo/pkg/database"
 	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/ecoconfig"
+	"github.com/PancyStudios/PancyBotGo/pkg/items"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
 	"github.com/bwmarrin/discordgo"
 )
 
@@ -49,16 +51,20 @@ func robHandler(ctx *discord.CommandContext, isGlobal bool) error {
 		return nil
 	}
 
-	cooldownDuration := 30 * time.Minute
+	rules := ecoconfig.For(guildID, ecoconfig.CommandRob, isGlobal)
+	if !rules.Enabled {
+		ctx.Reply(rules.DisabledText())
+		return nil
+	}
 
 	var isReady bool
 	var remaining time.Duration
 	var err error
 
 	if !isGlobal {
-		isReady, remaining, err = database.CooldownLocal(guildID, userID, "rob", cooldownDuration)
+		isReady, remaining, err = database.CooldownLocal(guildID, userID, "rob", rules.Cooldown)
 	} else {
-		isReady, remaining, err = database.CooldownStars(userID, "rob", cooldownDuration)
+		isReady, remaining, err = database.CooldownStars(userID, "rob", rules.Cooldown)
 	}
 
 	if err != nil {
@@ -72,7 +78,7 @@ func robHandler(ctx *discord.CommandContext, isGlobal bool) error {
 	}
 
 	// Calculate robbery logic
-	success := rand.Float64() < 0.40 // 40% base success rate
+	success := rules.Succeeds()
 
 	if !isGlobal {
 		targetProfile, err := database.GetLocalProfile(guildID, targetUser.ID)
@@ -80,30 +86,34 @@ func robHandler(ctx *discord.CommandContext, isGlobal bool) error {
 			ctx.Reply("❌ Ese usuario no tiene dinero que valga la pena robar (Mínimo 100).")
 			return nil
 		}
+		if items.Shielded(targetProfile.Buffs) {
+			ctx.Reply(fmt.Sprintf("🛡️ %s tiene un escudo anti-robo activo. Mejor busca otra víctima.", targetUser.Mention()))
+			return nil
+		}
 		myProfile, _ := database.GetLocalProfile(guildID, userID)
 		if myProfile.Wallet < 100 {
-			ctx.Reply("❌ Necesitas al menos 100 monedas locales en tu cartera para cubrir posibles fianzas.")
+			ctx.Reply(fmt.Sprintf("❌ Necesitas al menos 100 %s en tu cartera para cubrir posibles fianzas.", rules.Currency.Name))
 			return nil
 		}
 
 		_ = database.SetCooldownLocal(guildID, userID, "rob")
 
 		if success {
-			// Steal between 10% to 40% of their wallet
-			percent := (rand.Float64() * 0.3) + 0.1
-			stolen := int64(float64(targetProfile.Wallet) * percent)
+			// Steal a percentage of their wallet
+			stolen := targetProfile.Wallet * rules.Payout() / 100
 
-			database.AddLocalBalance(guildID, targetUser.ID, -stolen, false)
-			database.AddLocalBalance(guildID, userID, stolen, false)
-			ctx.Reply(fmt.Sprintf("🦹 ¡Éxito! Le robaste **💵 %d monedas** a %s.", stolen, targetUser.Mention()))
+			if err := database.TransferLocalBalance(guildID, targetUser.ID, userID, stolen, database.TxInfo{Type: models.TransactionRob, Command: "rob"}); err != nil {
+				ctx.Reply("❌ Tu víctima ya no tenía ese dinero en la cartera. El golpe salió mal.")
+				return nil
+			}
+			ctx.Reply(fmt.Sprintf("🦹 ¡Éxito! Le robaste **%s** a %s.", rules.Currency.Format(stolen), targetUser.Mention()))
 		} else {
-			fine := int64(float64(myProfile.Wallet) * 0.25) // Pay 25% of your wallet
+			fine := myProfile.Wallet * rules.Fine() / 100 // Pay a percentage of your wallet
 			if fine < 10 {
 				fine = 10
 			}
-			database.AddLocalBalance(guildID, userID, -fine, false)
-			database.AddLocalBalance(guildID, targetUser.ID, fine, false) // Give it to the victim as compensation
-			ctx.Reply(fmt.Sprintf("🚔 ¡Te atraparon intentando robarle a %s! Tuviste que pagarle **💵 %d monedas** como multa.", targetUser.Mention(), fine))
+			_ = database.TransferLocalBalance(guildID, userID, targetUser.ID, fine, database.TxInfo{Type: models.TransactionFine, Command: "rob"}) // Give it to the victim as compensation
+			ctx.Reply(fmt.Sprintf("🚔 ¡Te atraparon intentando robarle a %s! Tuviste que pagarle **%s** como multa.", targetUser.Mention(), rules.Currency.Format(fine)))
 		}
 	} else {
 		targetProfile, err := database.GetGlobalProfile(targetUser.ID)
@@ -111,6 +121,10 @@ func robHandler(ctx *discord.CommandContext, isGlobal bool) error {
 			ctx.Reply("❌ Ese usuario no tiene estrellas suficientes en la cartera (Mínimo 100).")
 			return nil
 		}
+		if items.Shielded(targetProfile.Buffs) {
+			ctx.Reply(fmt.Sprintf("🛡️ %s tiene un escudo anti-robo activo. Mejor busca otra víctima.", targetUser.Mention()))
+			return nil
+		}
 		myProfile, _ := database.GetGlobalProfile(userID)
 		if myProfile.StarsWallet < 100 {
 			ctx.Reply("❌ Necesitas al menos 100 estrellas en tu cartera para cubrir posibles fianzas.")
@@ -120,19 +134,19 @@ func robHandler(ctx *discord.CommandContext, isGlobal bool) error {
 		_ = database.SetCooldownStars(userID, "rob")
 
 		if success {
-			percent := (rand.Float64() * 0.3) + 0.1
-			stolen := int64(float64(targetProfile.StarsWallet) * percent)
+			stolen := targetProfile.StarsWallet * rules.Payout() / 100
 
-			database.AddStars(targetUser.ID, -stolen, false)
-			database.AddStars(userID, stolen, false)
+			if err := database.TransferStars(targetUser.ID, userID, stolen, database.TxInfo{Type: models.TransactionRob, Command: "rob"}); err != nil {
+				ctx.Reply("❌ Tu víctima ya no tenía esas estrellas en la cartera. El golpe salió mal.")
+				return nil
+			}
 			ctx.Reply(fmt.Sprintf("🦹 ¡Éxito! Le robaste **🌟 %d estrellas** a %s.", stolen, targetUser.Mention()))
 		} else {
-			fine := int64(float64(myProfile.StarsWallet) * 0.25)
+			fine := myProfile.StarsWallet * rules.Fine() / 100
 			if fine < 10 {
 				fine = 10
 			}
-			database.AddStars(userID, -fine, false)
-			database.AddStars(targetUser.ID, fine, false)
+			_ = database.TransferStars(userID, targetUser.ID, fine, database.TxInfo{Type: models.TransactionFine, Command: "rob"})
 			ctx.Reply(fmt.Sprintf("🚔 ¡Te atraparon robando estrellas de %s! Fuiste multado por **🌟 %d estrellas**, las cuales se le entregaron a tu víctima.", targetUser.Mention(), fine))
 		}
 	}
@@ -132,7 +132,11 @@ func RenderShopPage(guildID, shopType string, page int) (*discordgo.MessageEmbed
 		} else {
 			currency = "🪙"
 		}
-		list += fmt.Sprintf("### %s %s\n> %s\n> **Precio:** `%d %s` | **ID:** `%s`\n\n", item.Emoji, item.Name, item.Description, item.Price, currency, item.ID)
+		stock := ""
+		if item.Stock >= 0 {
+			stock = fmt.Sprintf(" | **Stock:** `%d`", item.Stock)
+		}
+		list += fmt.Sprintf("### %s %s\n> %s\n> **Precio:** `%d %s` | **ID:** `%s`%s\n\n", item.Emoji, item.Name, item.Description, item.Price, currency, item.ID, stock)
 	}
 
 	var title string
@@ -2,11 +2,12 @@ package economy
 
 import (
 	"fmt"
-	"math/rand"
 	"time"
 
 	"github.com/PancyStudios/PancyBotGo/pkg/database"
 	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/ecoconfig"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
 	"github.com/bwmarrin/discordgo"
 )
 
@@ -33,16 +34,20 @@ func slutHandler(ctx *discord.CommandContext, isGlobal bool) error {
 	userID := ctx.Interaction.Member.User.ID
 	guildID := ctx.Interaction.GuildID
 
-	cooldownDuration := 15 * time.Minute
+	rules := ecoconfig.For(guildID, ecoconfig.CommandSlut, isGlobal)
+	if !rules.Enabled {
+		ctx.Reply(rules.DisabledText())
+		return nil
+	}
 
 	var isReady bool
 	var remaining time.Duration
 	var err error
 
 	if !isGlobal {
-		isReady, remaining, err = database.CooldownLocal(guildID, userID, "slut", cooldownDuration)
+		isReady, remaining, err = database.CooldownLocal(guildID, userID, "slut", rules.Cooldown)
 	} else {
-		isReady, remaining, err = database.CooldownStars(userID, "slut", cooldownDuration)
+		isReady, remaining, err = database.CooldownStars(userID, "slut", rules.Cooldown)
 	}
 
 	if err != nil {
@@ -55,32 +60,31 @@ func slutHandler(ctx *discord.CommandContext, isGlobal bool) error {
 		return nil
 	}
 
-	// 60% chance of success
-	success := rand.Float64() < 0.60
+	success := rules.Succeeds()
 
 	if !isGlobal {
 		_ = database.SetCooldownLocal(guildID, userID, "slut")
 
 		if success {
-			amount := int64(rand.Intn(500) + 100)
-			database.AddLocalBalance(guildID, userID, amount, false)
-			ctx.Reply(fmt.Sprintf("💋 Te fue excelente en la esquina y te pagaron **💵 %d monedas**.", amount))
+			amount := rules.Payout()
+			database.AddLocalBalance(guildID, userID, amount, false, database.TxInfo{Type: models.TransactionEarn, Command: "slut"})
+			ctx.Reply(rules.Success("💋 Te fue excelente en la esquina y te pagaron **{amount}**.", amount))
 		} else {
-			fine := int64(rand.Intn(100) + 50)
-			database.AddLocalBalance(guildID, userID, -fine, false) // Subtract money
-			ctx.Reply(fmt.Sprintf("🚔 Te asaltaron en el callejón. Perdiste **💵 %d monedas**.", fine))
+			fine := rules.Fine()
+			database.AddLocalBalance(guildID, userID, -fine, false, database.TxInfo{Type: models.TransactionPenalty, Command: "slut"}) // Subtract money
+			ctx.Reply(rules.Failure("🚔 Te asaltaron en el callejón. Perdiste **{amount}**.", fine))
 		}
 	} else {
 		_ = database.SetCooldownStars(userID, "slut")
 
 		if success {
-			amount := int64(rand.Intn(400) + 100)
-			database.AddStars(userID, amount, false)
-			ctx.Reply(fmt.Sprintf("💋 Conseguiste un Sugar Alien que te donó **🌟 %d estrellas**.", amount))
+			amount := rules.Payout()
+			database.AddStars(userID, amount, false, database.TxInfo{Type: models.TransactionEarn, Command: "slut"})
+			ctx.Reply(rules.Success("💋 Conseguiste un Sugar Alien que te donó **{amount}**.", amount))
 		} else {
-			fine := int64(rand.Intn(100) + 20)
-			database.AddStars(userID, -fine, false)
-			ctx.Reply(fmt.Sprintf("🚔 Te arrestó la patrulla del espacio por exhibicionismo. Pagaste **🌟 %d estrellas** de multa.", fine))
+			fine := rules.Fine()
+			database.AddStars(userID, -fine, false, database.TxInfo{Type: models.TransactionPenalty, Command: "slut"})
+			ctx.Reply(rules.Failure("🚔 Te arrestó la patrulla del espacio por exhibicionismo. Pagaste **{amount}** de multa.", fine))
 		}
 	}
 	return nil
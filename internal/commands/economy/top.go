@@ -2,12 +2,11 @@ package economy
 
 import (
 	"fmt"
-	"sort"
 
-	"github.com/PancyStudios/PancyBotGo/pkg/database"
 	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/leaderboard"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
 	"github.com/bwmarrin/discordgo"
-	"go.mongodb.org/mongo-driver/bson"
 )
 
 func createTopCommand(isGlobal bool) *discord.Command {
@@ -25,68 +24,39 @@ func createTopCommand(isGlobal bool) *discord.Command {
 			Description: "💰 | Elige economía Local o Global",
 			Required:    true,
 		},
+		&discordgo.ApplicationCommandOption{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "pagina",
+			Description: "📄 | Página de la clasificación",
+			Required:    false,
+			MinValue:    func() *float64 { v := 1.0; return &v }(),
+		},
 	)
 }
 
 func topHandler(ctx *discord.CommandContext, isGlobal bool) error {
-
-	var leaderboardStr string
-	var embedTitle string
-	var embedColor int
-
-	if !isGlobal {
-		embedTitle = "🏆 Tabla de Clasificación Local"
-		embedColor = 0x2ECC71
-
-		profiles, err := database.LocalEconomyDM.GetAll(bson.M{"guild_id": ctx.Interaction.GuildID})
-		if err != nil {
-			ctx.Reply("❌ Hubo un error al obtener la tabla de clasificación local.")
-			return err
-		}
-
-		sort.Slice(profiles, func(i, j int) bool {
-			return (profiles[i].Wallet + profiles[i].Bank) > (profiles[j].Wallet + profiles[j].Bank)
-		})
-
-		for i, profile := range profiles {
-			if i >= 10 {
-				break
-			}
-			leaderboardStr += fmt.Sprintf("**%d.** <@%s> - 💵 %d (Cartera: %d, Banco: %d)\n", i+1, profile.UserID, profile.Wallet+profile.Bank, profile.Wallet, profile.Bank)
-		}
-
-	} else {
-		embedTitle = "🏆 Tabla de Clasificación Global"
-		embedColor = 0xF1C40F
-
-		profiles, err := database.GlobalEconomyDM.GetAll(bson.M{})
-		if err != nil {
-			ctx.Reply("❌ Hubo un error al obtener la tabla de clasificación global.")
-			return err
-		}
-
-		sort.Slice(profiles, func(i, j int) bool {
-			return (profiles[i].StarsWallet + profiles[i].StarsBank) > (profiles[j].StarsWallet + profiles[j].StarsBank)
-		})
-
-		for i, profile := range profiles {
-			if i >= 10 {
-				break
-			}
-			leaderboardStr += fmt.Sprintf("**%d.** <@%s> - 🌟 %d (Cartera: %d, Banco: %d)\n", i+1, profile.UserID, profile.StarsWallet+profile.StarsBank, profile.StarsWallet, profile.StarsBank)
-		}
+	board := leaderboard.BoardLocal
+	if isGlobal {
+		board = leaderboard.BoardGlobal
 	}
 
-	if leaderboardStr == "" {
-		leaderboardStr = "No hay datos para mostrar en la tabla de clasificación."
+	page := 0
+	if ctx.HasOption("pagina") {
+		page = int(ctx.GetIntOption("pagina")) - 1
 	}
 
-	embed := discord.NewEmbed().
-		SetTitle(embedTitle).
-		SetColor(embedColor).
-		SetDescription(leaderboardStr).
-		Build()
+	embed, components, err := leaderboard.Render(board, ctx.Interaction.GuildID, ctx.Interaction.Member.User.ID, page)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error obteniendo la clasificación %s: %v", board, err), "Economy")
+		ctx.Reply("❌ Hubo un error al obtener la tabla de clasificación.")
+		return err
+	}
 
-	ctx.ReplyEmbed(embed)
-	return nil
+	return ctx.Session.InteractionRespond(ctx.Interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+		},
+	})
 }
@@ -0,0 +1,52 @@
+package economy
+
+import (
+	"errors"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/trade"
+	"github.com/bwmarrin/discordgo"
+)
+
+func createTradeCommand() *discord.Command {
+	return discord.NewCommand(
+		"trade",
+		"🤝 | Intercambia objetos, monedas y estrellas con otro usuario",
+		"economy",
+		tradeHandler,
+	).WithOptions(
+		&discordgo.ApplicationCommandOption{
+			Type:        discordgo.ApplicationCommandOptionUser,
+			Name:        "usuario",
+			Description: "🤝 | Usuario con el que quieres intercambiar",
+			Required:    true,
+		},
+	)
+}
+
+func tradeHandler(ctx *discord.CommandContext) error {
+	partner := ctx.GetUserOption("usuario")
+	user := ctx.Interaction.Member.User
+
+	if partner == nil || partner.ID == user.ID {
+		ctx.ReplyEphemeral("❌ No puedes intercambiar contigo mismo.")
+		return nil
+	}
+	if partner.Bot {
+		ctx.ReplyEphemeral("❌ Los bots no tienen inventario.")
+		return nil
+	}
+
+	_, err := trade.Open(ctx.Session, ctx.Interaction.GuildID, ctx.Interaction.ChannelID, user, partner)
+	if errors.Is(err, trade.ErrAlreadyTrading) {
+		ctx.ReplyEphemeral("❌ Tú o ese usuario ya tenéis un intercambio abierto.")
+		return nil
+	}
+	if err != nil {
+		ctx.ReplyEphemeral("❌ No se pudo abrir el intercambio.")
+		return err
+	}
+
+	ctx.ReplyEphemeral("🤝 Ventana de intercambio abierta. Tenéis 5 minutos para cerrar el trato.")
+	return nil
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/PancyStudios/PancyBotGo/pkg/database"
 	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/ecoconfig"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
 )
 
 func createWeeklyCommand(isGlobal bool) *discord.Command {
@@ -21,10 +23,23 @@ func createWeeklyCommand(isGlobal bool) *discord.Command {
 
 func weeklyHandler(ctx *discord.CommandContext, isGlobal bool) error {
 	userID := ctx.Interaction.Member.User.ID
+	guildID := ctx.Interaction.GuildID
 
-	cooldownDuration := 7 * 24 * time.Hour
+	rules := ecoconfig.For(guildID, ecoconfig.CommandWeekly, isGlobal)
+	if !rules.Enabled {
+		ctx.Reply(rules.DisabledText())
+		return nil
+	}
+
+	var isReady bool
+	var remaining time.Duration
+	var err error
 
-	isReady, remaining, err := database.CooldownStars(userID, "weekly", cooldownDuration)
+	if !isGlobal {
+		isReady, remaining, err = database.CooldownLocal(guildID, userID, "weekly", rules.Cooldown)
+	} else {
+		isReady, remaining, err = database.CooldownStars(userID, "weekly", rules.Cooldown)
+	}
 	if err != nil {
 		ctx.Reply("❌ Error al comprobar el cooldown.")
 		return err
@@ -35,16 +50,25 @@ func weeklyHandler(ctx *discord.CommandContext, isGlobal bool) error {
 		return nil
 	}
 
-	amount := int64(10000) // Weekly gives 10k stars
+	amount := rules.Payout()
+	info := database.TxInfo{Type: models.TransactionReward, Command: "weekly"}
 
-	_, err = database.AddStars(userID, amount, false)
+	if !isGlobal {
+		_, err = database.AddLocalBalance(guildID, userID, amount, false, info)
+	} else {
+		_, err = database.AddStars(userID, amount, false, info)
+	}
 	if err != nil {
 		ctx.Reply("❌ Error al procesar la recompensa.")
 		return err
 	}
 
-	_ = database.SetCooldownStars(userID, "weekly")
+	if !isGlobal {
+		_ = database.SetCooldownLocal(guildID, userID, "weekly")
+	} else {
+		_ = database.SetCooldownStars(userID, "weekly")
+	}
 
-	ctx.Reply(fmt.Sprintf("¡Increíble! Has reclamado tu jugosa recompensa semanal de **🌟 %d estrellas**.", amount))
+	ctx.Reply(rules.Success("¡Increíble! Has reclamado tu jugosa recompensa semanal de **{amount}**.", amount))
 	return nil
 }
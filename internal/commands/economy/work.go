@@ -2,11 +2,13 @@ package economy
 
 import (
 	"fmt"
-	"math/rand"
 	"time"
 
 	"github.com/PancyStudios/PancyBotGo/pkg/database"
 	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/ecoconfig"
+	"github.com/PancyStudios/PancyBotGo/pkg/items"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
 	"github.com/bwmarrin/discordgo"
 )
 
@@ -33,16 +35,20 @@ func workHandler(ctx *discord.CommandContext, isGlobal bool) error {
 	userID := ctx.Interaction.Member.User.ID
 	guildID := ctx.Interaction.GuildID
 
-	cooldownDuration := 5 * time.Minute
+	rules := ecoconfig.For(guildID, ecoconfig.CommandWork, isGlobal)
+	if !rules.Enabled {
+		ctx.Reply(rules.DisabledText())
+		return nil
+	}
 
 	var isReady bool
 	var remaining time.Duration
 	var err error
 
 	if !isGlobal {
-		isReady, remaining, err = database.CooldownLocal(guildID, userID, "work", cooldownDuration)
+		isReady, remaining, err = database.CooldownLocal(guildID, userID, "work", rules.Cooldown)
 	} else {
-		isReady, remaining, err = database.CooldownStars(userID, "work", cooldownDuration)
+		isReady, remaining, err = database.CooldownStars(userID, "work", rules.Cooldown)
 	}
 
 	if err != nil {
@@ -55,26 +61,36 @@ func workHandler(ctx *discord.CommandContext, isGlobal bool) error {
 		return nil
 	}
 
-	amount := int64(rand.Intn(200) + 50)
+	gear, err := items.LoadGear(guildID, userID, isGlobal, items.EffectWorkTool)
+	if err != nil {
+		ctx.Reply("❌ " + "Error al revisar tu equipo.")
+		return err
+	}
+	if gear.Missing != nil {
+		ctx.Reply(gear.MissingText("trabajar"))
+		return nil
+	}
+
+	amount := gear.Boost(rules.Payout(), items.EffectWorkBoost)
 
 	if !isGlobal {
-		_, err = database.AddLocalBalance(guildID, userID, amount, false)
+		_, err = database.AddLocalBalance(guildID, userID, amount, false, database.TxInfo{Type: models.TransactionEarn, Command: "work"})
 		if err != nil {
 			ctx.Reply("❌ " + "Error al procesar el pago local.")
 			return err
 		}
 		_ = database.SetCooldownLocal(guildID, userID, "work")
 
-		ctx.Reply(fmt.Sprintf("Has trabajado duro y ganaste **💵 %d monedas locales**.", amount))
+		ctx.Reply(rules.Success("Has trabajado duro y ganaste **{amount}**.", amount) + gear.Note(items.EffectWorkBoost))
 	} else {
-		_, err = database.AddStars(userID, amount, false)
+		_, err = database.AddStars(userID, amount, false, database.TxInfo{Type: models.TransactionEarn, Command: "work"})
 		if err != nil {
 			ctx.Reply("❌ " + "Error al procesar el pago global.")
 			return err
 		}
 		_ = database.SetCooldownStars(userID, "work")
 
-		ctx.Reply(fmt.Sprintf("Hiciste un viaje espacial y minaste **🌟 %d estrellas**.", amount))
+		ctx.Reply(rules.Success("Hiciste un viaje espacial y minaste **{amount}**.", amount) + gear.Note(items.EffectWorkBoost))
 	}
 	return nil
 }
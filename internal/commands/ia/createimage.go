@@ -59,7 +59,7 @@ func createImageHandler(ctx *discord.CommandContext) error {
 	}
 	jsonData, _ := json.Marshal(reqBody)
 
-	req, err := http.NewRequest("POST", modelUrl, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx.Context(), "POST", modelUrl, bytes.NewBuffer(jsonData))
 	if err != nil {
 		sendErrorEdit(ctx, fmt.Sprintf("Error interno: %v", err))
 		return nil
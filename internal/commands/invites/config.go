@@ -0,0 +1,102 @@
+package invites
+
+import (
+	"fmt"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/invites"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var configCommand = &discord.Command{
+	Name:            "config",
+	Description:     "⚙️ | Configura el seguimiento de invitaciones",
+	UserPermissions: discordgo.PermissionManageGuild,
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Name:        "activar",
+			Description: "⚙️ | Activar o desactivar el seguimiento",
+			Required:    true,
+		},
+		{
+			Type:         discordgo.ApplicationCommandOptionChannel,
+			Name:         "canal",
+			Description:  "📝 | Canal donde registrar quién invitó a cada miembro",
+			Required:     false,
+			ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "dias_falsas",
+			Description: "⚠️ | Cuentas más nuevas que estos días cuentan como invitación falsa (por defecto 7)",
+			Required:    false,
+			MinValue:    func() *float64 { v := 0.0; return &v }(),
+		},
+	},
+	Run: func(ctx *discord.CommandContext) error {
+		guildID := ctx.Interaction.GuildID
+		if guildID == "" {
+			return ctx.ReplyEphemeral("❌ Este comando solo se puede usar en un servidor.")
+		}
+
+		guildData, err := database.GlobalGuildDM.WithContext(ctx.Context()).Get(bson.M{"id": guildID})
+		if err != nil {
+			return ctx.ReplyEphemeral(fmt.Sprintf("❌ Error obteniendo configuración: %v", err))
+		}
+		if guildData == nil {
+			guildData = models.NewDefaultGuildDocument(guildID)
+		}
+
+		cfg := &guildData.Invites
+		cfg.Enable = ctx.GetBoolOption("activar")
+		if channel := ctx.GetChannelOption("canal"); channel != nil {
+			cfg.LogChannel = channel.ID
+		}
+		if ctx.HasOption("dias_falsas") {
+			cfg.FakeAccountDays = int(ctx.GetIntOption("dias_falsas"))
+		}
+
+		if _, err = database.GlobalGuildDM.WithContext(ctx.Context()).Set(bson.M{"id": guildID}, guildData); err != nil {
+			return ctx.ReplyEphemeral(fmt.Sprintf("❌ Error guardando configuración: %v", err))
+		}
+
+		warning := ""
+		if cfg.Enable {
+			if err := invites.Load(ctx.Session, guildID); err != nil {
+				logger.Warn(fmt.Sprintf("No se pudieron cargar las invitaciones de %s: %v", guildID, err), "Invites")
+				warning = "\n⚠️ No pude leer las invitaciones del servidor. Necesito el permiso **Gestionar Servidor**."
+			}
+		}
+
+		return ctx.ReplyEmbed(configEmbed(cfg, warning))
+	},
+}
+
+func configEmbed(cfg *models.InvitesConfig, warning string) *discordgo.MessageEmbed {
+	status := "❌ Desactivado"
+	if cfg.Enable {
+		status = "✅ Activado"
+	}
+	channel := "Ninguno"
+	if cfg.LogChannel != "" {
+		channel = fmt.Sprintf("<#%s>", cfg.LogChannel)
+	}
+	days := cfg.FakeAccountDays
+	if days <= 0 {
+		days = invites.DefaultFakeAccountDays
+	}
+
+	return discord.NewEmbed().
+		SetTitle("📨 Seguimiento de invitaciones").
+		SetDescription(fmt.Sprintf("Estado: **%s**%s", status, warning)).
+		SetColor(discord.ColorSuccess).
+		AddField("Canal de registro", channel, true).
+		AddField("Cuentas falsas", fmt.Sprintf("Menos de %d días", days), true).
+		AddField("Recompensas", fmt.Sprintf("%d configuradas", len(cfg.Rewards)), true).
+		Build()
+}
@@ -0,0 +1,78 @@
+package invites
+
+import (
+	"fmt"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var infoCommand = &discord.Command{
+	Name:        "info",
+	Description: "📨 | Muestra las invitaciones de un usuario",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionUser,
+			Name:        "usuario",
+			Description: "👤 | Usuario a consultar (por defecto tú)",
+			Required:    false,
+		},
+	},
+	Run: func(ctx *discord.CommandContext) error {
+		guildID := ctx.Interaction.GuildID
+		if guildID == "" {
+			return ctx.ReplyEphemeral("❌ Este comando solo se puede usar en un servidor.")
+		}
+
+		guildData, err := database.GlobalGuildDM.WithContext(ctx.Context()).Get(bson.M{"id": guildID})
+		if err != nil || guildData == nil || !guildData.Invites.Enable {
+			return ctx.ReplyEphemeral("❌ El seguimiento de invitaciones está desactivado en este servidor.")
+		}
+
+		target := ctx.User()
+		if ctx.HasOption("usuario") {
+			target = ctx.GetUserOption("usuario")
+		}
+
+		stats, err := database.GetInviteStats(guildID, target.ID)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Error obteniendo invitaciones de %s: %v", target.ID, err), "Invites")
+			return ctx.ReplyEphemeral("❌ Ocurrió un error al obtener las invitaciones.")
+		}
+
+		embed := discord.NewEmbed().
+			SetTitle(fmt.Sprintf("📨 Invitaciones de %s", target.Username)).
+			SetThumbnail(target.AvatarURL("128")).
+			SetColor(discord.ColorInfo).
+			SetDescription(fmt.Sprintf("Tiene **%d** invitaciones válidas.", stats.Total())).
+			AddField("✅ Entradas", fmt.Sprintf("%d", stats.Joins), true).
+			AddField("👋 Salidas", fmt.Sprintf("%d", stats.Leaves), true).
+			AddField("⚠️ Falsas", fmt.Sprintf("%d", stats.Fakes), true)
+
+		if next := nextReward(guildData.Invites.Rewards, stats.Total()); next != nil {
+			embed.AddField("🎁 Próxima recompensa", fmt.Sprintf("<@&%s> al llegar a **%d** invitaciones", next.RoleID, next.Invites), false)
+		}
+
+		if join, err := database.GetInviteJoin(guildID, target.ID); err == nil && join != nil && join.InviterID != "" {
+			embed.AddField("🔗 Invitado por", fmt.Sprintf("<@%s>", join.InviterID), false)
+		}
+
+		return ctx.ReplyEmbed(embed.Build())
+	},
+}
+
+// nextReward returns the closest reward above the given total, or nil
+func nextReward(rewards []models.InviteReward, total int64) *models.InviteReward {
+	var next *models.InviteReward
+	for i := range rewards {
+		r := &rewards[i]
+		if r.Invites > total && (next == nil || r.Invites < next.Invites) {
+			next = r
+		}
+	}
+	return next
+}
@@ -0,0 +1,60 @@
+package invites
+
+import (
+	"fmt"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var leaderboardCommand = &discord.Command{
+	Name:        "leaderboard",
+	Description: "🏆 | Muestra los usuarios con más invitaciones del servidor",
+	Run: func(ctx *discord.CommandContext) error {
+		guildID := ctx.Interaction.GuildID
+		if guildID == "" {
+			return ctx.ReplyEphemeral("❌ Este comando solo se puede usar en un servidor.")
+		}
+
+		guildData, err := database.GlobalGuildDM.WithContext(ctx.Context()).Get(bson.M{"id": guildID})
+		if err != nil || guildData == nil || !guildData.Invites.Enable {
+			return ctx.ReplyEphemeral("❌ El seguimiento de invitaciones está desactivado en este servidor.")
+		}
+
+		top, err := database.GetTopInviters(guildID, 10)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Error obteniendo leaderboard de invitaciones para %s: %v", guildID, err), "Invites")
+			return ctx.ReplyEphemeral("❌ Ocurrió un error al obtener la clasificación.")
+		}
+
+		if len(top) == 0 {
+			return ctx.ReplyEphemeral("📉 Aún nadie ha invitado a nadie en este servidor.")
+		}
+
+		description := ""
+		for i, stats := range top {
+			medal := "🏅"
+			switch i {
+			case 0:
+				medal = "🥇"
+			case 1:
+				medal = "🥈"
+			case 2:
+				medal = "🥉"
+			}
+			description += fmt.Sprintf("%s **#%d** <@%s> - %d invitaciones (%d entradas, %d salidas, %d falsas)\n",
+				medal, i+1, stats.UserID, stats.Total(), stats.Joins, stats.Leaves, stats.Fakes)
+		}
+
+		embed := &discordgo.MessageEmbed{
+			Title:       "🏆 Tabla de Clasificación de Invitaciones",
+			Description: description,
+			Color:       0xFFD700, // Gold
+		}
+
+		return ctx.ReplyEmbed(embed)
+	},
+}
@@ -0,0 +1,19 @@
+package invites
+
+import (
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+)
+
+// RegisterCommands registers the invite tracker commands
+func RegisterCommands(client *discord.ExtendedClient) {
+	invitesGroup := client.CommandHandler.BuildCommandGroup(
+		"invites",
+		"📨 | Seguimiento de invitaciones del servidor",
+		infoCommand,
+		leaderboardCommand,
+		rewardsCommand,
+		configCommand,
+	)
+
+	client.CommandHandler.AddGlobalCommand(invitesGroup)
+}
@@ -0,0 +1,140 @@
+package invites
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var rewardsCommand = &discord.Command{
+	Name:            "rewards",
+	Description:     "🎁 | Gestiona los roles que se entregan al alcanzar invitaciones",
+	UserPermissions: discordgo.PermissionAdministrator,
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "add",
+			Description: "➕ | Agrega un rol como recompensa por un número de invitaciones",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "invitaciones",
+					Description: "📨 | Invitaciones necesarias para obtener el rol",
+					Required:    true,
+					MinValue:    func() *float64 { v := 1.0; return &v }(),
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionRole,
+					Name:        "rol",
+					Description: "🎭 | Rol a entregar",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "remove",
+			Description: "➖ | Elimina la recompensa de un número de invitaciones",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "invitaciones",
+					Description: "📨 | Invitaciones de la recompensa a eliminar",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "list",
+			Description: "📋 | Muestra todas las recompensas configuradas",
+		},
+	},
+	Run: func(ctx *discord.CommandContext) error {
+		guildID := ctx.Interaction.GuildID
+		if guildID == "" {
+			return ctx.ReplyEphemeral("❌ Este comando solo se puede usar en un servidor.")
+		}
+
+		guildData, err := database.GlobalGuildDM.WithContext(ctx.Context()).Get(bson.M{"id": guildID})
+		if err != nil {
+			return ctx.ReplyEphemeral(fmt.Sprintf("❌ Error al obtener la configuración del servidor: %v", err))
+		}
+		if guildData == nil {
+			guildData = models.NewDefaultGuildDocument(guildID)
+		}
+
+		switch {
+		case ctx.HasOption("add"):
+			invites := ctx.GetIntOption("invitaciones")
+			role := ctx.GetRoleOption("rol")
+			if role == nil {
+				return ctx.ReplyEphemeral("❌ Rol inválido.")
+			}
+
+			exists := false
+			for i, r := range guildData.Invites.Rewards {
+				if r.Invites == invites {
+					guildData.Invites.Rewards[i].RoleID = role.ID
+					exists = true
+					break
+				}
+			}
+			if !exists {
+				guildData.Invites.Rewards = append(guildData.Invites.Rewards, models.InviteReward{Invites: invites, RoleID: role.ID})
+			}
+
+			if _, err = database.GlobalGuildDM.WithContext(ctx.Context()).Set(bson.M{"id": guildID}, guildData); err != nil {
+				return ctx.ReplyEphemeral("❌ Error al guardar la configuración.")
+			}
+			return ctx.Reply(fmt.Sprintf("✅ Recompensa configurada: Al llegar a **%d** invitaciones, se entregará el rol <@&%s>.", invites, role.ID))
+
+		case ctx.HasOption("remove"):
+			invites := ctx.GetIntOption("invitaciones")
+
+			found := false
+			rewards := make([]models.InviteReward, 0, len(guildData.Invites.Rewards))
+			for _, r := range guildData.Invites.Rewards {
+				if r.Invites == invites {
+					found = true
+				} else {
+					rewards = append(rewards, r)
+				}
+			}
+			if !found {
+				return ctx.ReplyEphemeral(fmt.Sprintf("❌ No hay ninguna recompensa configurada para %d invitaciones.", invites))
+			}
+
+			guildData.Invites.Rewards = rewards
+			if _, err = database.GlobalGuildDM.WithContext(ctx.Context()).Set(bson.M{"id": guildID}, guildData); err != nil {
+				return ctx.ReplyEphemeral("❌ Error al guardar la configuración.")
+			}
+			return ctx.Reply(fmt.Sprintf("✅ Recompensa de **%d** invitaciones eliminada.", invites))
+
+		default:
+			if len(guildData.Invites.Rewards) == 0 {
+				return ctx.ReplyEphemeral("📉 No hay recompensas de invitaciones configuradas en este servidor.")
+			}
+
+			rewards := append([]models.InviteReward(nil), guildData.Invites.Rewards...)
+			sort.Slice(rewards, func(i, j int) bool { return rewards[i].Invites < rewards[j].Invites })
+
+			listDesc := ""
+			for _, r := range rewards {
+				listDesc += fmt.Sprintf("📨 **%d invitaciones**: <@&%s>\n", r.Invites, r.RoleID)
+			}
+
+			embed := discord.NewEmbed().
+				SetTitle("🎁 Recompensas de Invitaciones").
+				SetDescription(listDesc).
+				SetColor(0x22d3ee)
+
+			return ctx.ReplyEmbed(embed.Build())
+		}
+	},
+}
@@ -0,0 +1,222 @@
+package levels
+
+import (
+	"fmt"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/leveladmin"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/bwmarrin/discordgo"
+)
+
+func adminUserOption(description string) *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionUser,
+		Name:        "usuario",
+		Description: description,
+		Required:    true,
+	}
+}
+
+var adminCommand = &discord.Command{
+	Name:            "admin",
+	Description:     "🛠️ | Ajusta, reinicia, exporta o importa la experiencia de los usuarios",
+	UserPermissions: discordgo.PermissionAdministrator,
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "set-xp",
+			Description: "✏️ | Fija la experiencia de un usuario",
+			Options: []*discordgo.ApplicationCommandOption{
+				adminUserOption("👤 | Usuario al que cambiar la experiencia"),
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "xp",
+					Description: "✨ | Experiencia total",
+					Required:    true,
+					MinValue:    func() *float64 { v := 0.0; return &v }(),
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "add-xp",
+			Description: "➕ | Suma experiencia a un usuario (negativa para quitarla)",
+			Options: []*discordgo.ApplicationCommandOption{
+				adminUserOption("👤 | Usuario al que cambiar la experiencia"),
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "xp",
+					Description: "✨ | Experiencia a sumar o, en negativo, a quitar",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "set-level",
+			Description: "⭐ | Lleva a un usuario al inicio de un nivel",
+			Options: []*discordgo.ApplicationCommandOption{
+				adminUserOption("👤 | Usuario al que cambiar el nivel"),
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "nivel",
+					Description: "⭐ | Nivel nuevo",
+					Required:    true,
+					MinValue:    func() *float64 { v := 0.0; return &v }(),
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "reset",
+			Description: "🗑️ | Borra la experiencia de un usuario",
+			Options: []*discordgo.ApplicationCommandOption{
+				adminUserOption("👤 | Usuario a reiniciar"),
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "reset-all",
+			Description: "💣 | Borra la experiencia de todo el servidor",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "export",
+			Description: "📤 | Descarga la experiencia de todos los usuarios",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "formato",
+					Description: "📄 | Formato del archivo",
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "CSV", Value: leveladmin.FormatCSV},
+						{Name: "JSON", Value: leveladmin.FormatJSON},
+					},
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "import",
+			Description: "📥 | Carga experiencia desde un CSV o JSON, por ejemplo de otro bot",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionAttachment,
+					Name:        "archivo",
+					Description: "📎 | Archivo con columnas user_id y xp (o level)",
+					Required:    true,
+				},
+			},
+		},
+	},
+	Run: func(ctx *discord.CommandContext) error {
+		guildID := ctx.Interaction.GuildID
+		if guildID == "" {
+			return ctx.ReplyEphemeral("❌ Este comando solo se puede usar en un servidor.")
+		}
+
+		action := leveladmin.Action{GuildID: guildID, AdminID: ctx.User().ID}
+		switch {
+		case ctx.HasOption("set-xp"):
+			action.Kind, action.Value = leveladmin.ActionSetXP, ctx.GetIntOption("xp")
+		case ctx.HasOption("add-xp"):
+			action.Kind, action.Value = leveladmin.ActionAddXP, ctx.GetIntOption("xp")
+		case ctx.HasOption("set-level"):
+			action.Kind, action.Value = leveladmin.ActionSetLevel, ctx.GetIntOption("nivel")
+		case ctx.HasOption("reset"):
+			action.Kind = leveladmin.ActionReset
+		case ctx.HasOption("reset-all"):
+			action.Kind = leveladmin.ActionResetAll
+		case ctx.HasOption("export"):
+			return adminExport(ctx, guildID)
+		case ctx.HasOption("import"):
+			return adminImport(ctx, action)
+		default:
+			return ctx.ReplyEphemeral("❌ Subcomando no encontrado.")
+		}
+
+		if user := ctx.GetUserOption("usuario"); user != nil {
+			if user.Bot {
+				return ctx.ReplyEphemeral("🤖 Los bots no tienen niveles.")
+			}
+			action.UserID = user.ID
+		}
+
+		action, err := leveladmin.Prepare(action)
+		if err != nil {
+			return ctx.ReplyEphemeral(leveladmin.ErrorText(err))
+		}
+		return ctx.Session.InteractionRespond(ctx.Interaction.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Embeds:     []*discordgo.MessageEmbed{leveladmin.ConfirmEmbed(action)},
+				Components: leveladmin.ConfirmComponents(action),
+				Flags:      discordgo.MessageFlagsEphemeral,
+			},
+		})
+	},
+}
+
+// deferEphemeral acknowledges a slow subcommand with a private "thinking" reply
+func deferEphemeral(ctx *discord.CommandContext) error {
+	return ctx.Session.InteractionRespond(ctx.Interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
+	})
+}
+
+func adminExport(ctx *discord.CommandContext, guildID string) error {
+	if err := deferEphemeral(ctx); err != nil {
+		return err
+	}
+
+	file, total, err := leveladmin.ExportFile(guildID, ctx.GetStringOption("formato"))
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error exportando niveles de %s: %v", guildID, err), "Levels")
+		return ctx.EditReplyText(leveladmin.ErrorText(err))
+	}
+	content := fmt.Sprintf("📤 Experiencia de **%d** usuarios.", total)
+	_, err = ctx.Session.InteractionResponseEdit(ctx.Interaction.Interaction, &discordgo.WebhookEdit{
+		Content: &content,
+		Files:   []*discordgo.File{file},
+	})
+	return err
+}
+
+func adminImport(ctx *discord.CommandContext, action leveladmin.Action) error {
+	var attachment *discordgo.MessageAttachment
+	if resolved := ctx.Interaction.ApplicationCommandData().Resolved; resolved != nil {
+		attachmentID, _ := ctx.GetOption("archivo").Value.(string)
+		attachment = resolved.Attachments[attachmentID]
+	}
+	if attachment == nil {
+		return ctx.ReplyEphemeral("❌ No se encontró el archivo.")
+	}
+	if attachment.Size > leveladmin.MaxImportSize {
+		return ctx.ReplyEphemeral(leveladmin.ErrorText(leveladmin.ErrFileTooBig))
+	}
+	if err := deferEphemeral(ctx); err != nil {
+		return err
+	}
+
+	data, err := leveladmin.Download(attachment.URL)
+	if err == nil {
+		action.Records, err = leveladmin.Decode(data)
+	}
+	if err == nil {
+		action.Kind = leveladmin.ActionImport
+		action, err = leveladmin.Prepare(action)
+	}
+	if err != nil {
+		return ctx.EditReplyText(leveladmin.ErrorText(err))
+	}
+
+	embeds := []*discordgo.MessageEmbed{leveladmin.ConfirmEmbed(action)}
+	components := leveladmin.ConfirmComponents(action)
+	_, err = ctx.Session.InteractionResponseEdit(ctx.Interaction.Interaction, &discordgo.WebhookEdit{
+		Embeds:     &embeds,
+		Components: &components,
+	})
+	return err
+}
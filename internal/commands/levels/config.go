@@ -0,0 +1,215 @@
+package levels
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/leveling"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/bwmarrin/discordgo"
+)
+
+var configCommand = &discord.Command{
+	Name:            "config",
+	Description:     "🛠️ | Configura cómo se gana experiencia en el servidor",
+	UserPermissions: discordgo.PermissionAdministrator,
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "view",
+			Description: "📋 | Muestra la configuración de experiencia",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "xp",
+			Description: "✨ | Cambia el XP por mensaje, el cooldown y la curva de niveles (0 restablece)",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "xp_min",
+					Description: "✨ | XP mínimo por mensaje",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "xp_max",
+					Description: "✨ | XP máximo por mensaje",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "cooldown",
+					Description: "⏱️ | Segundos entre dos ganancias de XP",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "curva",
+					Description: "📈 | Cómo crece el XP necesario por nivel",
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "Cuadrática (base × nivel²)", Value: leveling.CurveQuadratic},
+						{Name: "Lineal (base × nivel)", Value: leveling.CurveLinear},
+						{Name: "Exponencial (+20% por nivel)", Value: leveling.CurveExponential},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "base",
+					Description: "📈 | XP base de la curva",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionNumber,
+					Name:        "premium",
+					Description: "💎 | Multiplicador de XP de los usuarios premium",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "voz_xp",
+					Description: "🎤 | XP por minuto en canales de voz",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "voz",
+					Description: "🎤 | Si el tiempo en voz da experiencia",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "recompensas",
+					Description: "🎁 | Si los roles de recompensa se acumulan o se reemplazan",
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "Acumular", Value: leveling.RewardsStack},
+						{Name: "Reemplazar", Value: leveling.RewardsReplace},
+					},
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "multiplier",
+			Description: "✖️ | Multiplica el XP de un canal o un rol (1 lo quita)",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionNumber,
+					Name:        "multiplicador",
+					Description: "✖️ | Multiplicador, por ejemplo 1.5",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionChannel,
+					Name:        "canal",
+					Description: "💬 | Canal al que se aplica",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionRole,
+					Name:        "rol",
+					Description: "🎭 | Rol al que se aplica",
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "noxp",
+			Description: "🚫 | Activa o quita un canal o un rol sin experiencia",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionChannel,
+					Name:        "canal",
+					Description: "💬 | Canal donde no se gana XP",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionRole,
+					Name:        "rol",
+					Description: "🎭 | Rol que no gana XP",
+				},
+			},
+		},
+	},
+	Run: func(ctx *discord.CommandContext) error {
+		guildID := ctx.Interaction.GuildID
+		if guildID == "" {
+			return ctx.ReplyEphemeral("❌ Este comando solo se puede usar en un servidor.")
+		}
+
+		guildData, err := database.GlobalGuildDM.WithContext(ctx.Context()).Get(bson.M{"id": guildID})
+		if err != nil {
+			return ctx.ReplyEphemeral(fmt.Sprintf("❌ Error al obtener la configuración del servidor: %v", err))
+		}
+
+		cfg := guildData.Levels
+		switch {
+		case ctx.HasOption("view"):
+			return ctx.ReplyEmbed(levelsConfigEmbed(cfg))
+
+		case ctx.HasOption("xp"):
+			if ctx.HasOption("xp_min") {
+				cfg.MinXP = ctx.GetIntOption("xp_min")
+			}
+			if ctx.HasOption("xp_max") {
+				cfg.MaxXP = ctx.GetIntOption("xp_max")
+			}
+			if ctx.HasOption("cooldown") {
+				cfg.CooldownSeconds = int(ctx.GetIntOption("cooldown"))
+			}
+			if ctx.HasOption("curva") {
+				cfg.Curve = ctx.GetStringOption("curva")
+			}
+			if ctx.HasOption("base") {
+				cfg.CurveBase = ctx.GetIntOption("base")
+			}
+			if ctx.HasOption("premium") {
+				cfg.PremiumBoost = ctx.GetFloatOption("premium")
+			}
+			if ctx.HasOption("recompensas") {
+				cfg.RewardMode = ctx.GetStringOption("recompensas")
+			}
+			if ctx.HasOption("voz_xp") {
+				cfg.VoiceXP = ctx.GetIntOption("voz_xp")
+			}
+			if ctx.HasOption("voz") {
+				cfg.VoiceDisabled = !ctx.GetBoolOption("voz")
+			}
+
+		case ctx.HasOption("multiplier"):
+			multiplier := ctx.GetFloatOption("multiplicador")
+			switch {
+			case ctx.HasOption("canal"):
+				cfg.ChannelMultipliers = leveling.SetMultiplier(cfg.ChannelMultipliers, ctx.GetOption("canal").Value.(string), multiplier)
+			case ctx.HasOption("rol"):
+				cfg.RoleMultipliers = leveling.SetMultiplier(cfg.RoleMultipliers, ctx.GetOption("rol").Value.(string), multiplier)
+			default:
+				return ctx.ReplyEphemeral("❌ Indica un canal o un rol.")
+			}
+
+		case ctx.HasOption("noxp"):
+			switch {
+			case ctx.HasOption("canal"):
+				cfg.NoXPChannels = leveling.ToggleID(cfg.NoXPChannels, ctx.GetOption("canal").Value.(string))
+			case ctx.HasOption("rol"):
+				cfg.NoXPRoles = leveling.ToggleID(cfg.NoXPRoles, ctx.GetOption("rol").Value.(string))
+			default:
+				return ctx.ReplyEphemeral("❌ Indica un canal o un rol.")
+			}
+		}
+
+		if err := leveling.Validate(cfg); err != nil {
+			return ctx.ReplyEphemeral("❌ " + leveling.Explain(err))
+		}
+
+		guildData.Levels = cfg
+		if _, err := database.GlobalGuildDM.WithContext(ctx.Context()).Set(bson.M{"id": guildID}, guildData); err != nil {
+			return ctx.ReplyEphemeral("❌ Error al guardar la configuración.")
+		}
+		return ctx.ReplyEmbed(levelsConfigEmbed(cfg))
+	},
+}
+
+func levelsConfigEmbed(cfg models.LevelsConfig) *discordgo.MessageEmbed {
+	multipliers, excluded := leveling.Lists(cfg)
+	return discord.NewEmbed().
+		SetTitle("🛠️ Configuración de Experiencia").
+		SetDescription(leveling.Resolve(cfg).Summary()).
+		SetColor(0x22d3ee).
+		AddField("✖️ Multiplicadores", multipliers, false).
+		AddField("🚫 Sin XP", excluded, false).
+		Build()
+}
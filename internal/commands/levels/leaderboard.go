@@ -6,6 +6,7 @@ import (
 
 	"github.com/PancyStudios/PancyBotGo/pkg/database"
 	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/leaderboard"
 	"github.com/PancyStudios/PancyBotGo/pkg/logger"
 	"github.com/bwmarrin/discordgo"
 )
@@ -13,6 +14,15 @@ import (
 var leaderboardCommand = &discord.Command{
 	Name:        "leaderboard",
 	Description: "🏆 | Muestra los usuarios con más nivel en el servidor",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "pagina",
+			Description: "📄 | Página de la clasificación",
+			Required:    false,
+			MinValue:    func() *float64 { v := 1.0; return &v }(),
+		},
+	},
 	Run: func(ctx *discord.CommandContext) error {
 		guildID := ctx.Interaction.GuildID
 		if guildID == "" {
@@ -20,43 +30,33 @@ var leaderboardCommand = &discord.Command{
 		}
 
 		// Verificar si el sistema está activado
-		guildData, err := database.GlobalGuildDM.Get(bson.M{"id": guildID})
+		guildData, err := database.GlobalGuildDM.WithContext(ctx.Context()).Get(bson.M{"id": guildID})
 		if err != nil || guildData == nil || !guildData.Levels.Enable {
 			return ctx.ReplyEphemeral("❌ El sistema de niveles está desactivado en este servidor.")
 		}
 
-		topProfiles, err := database.GetTopLevels(guildID, 10)
-		if err != nil {
-			logger.Error(fmt.Sprintf("Error obteniendo leaderboard para %s: %v", guildID, err), "Leaderboard")
-			return ctx.ReplyEphemeral("❌ Ocurrió un error al obtener la clasificación.")
-		}
-
-		if len(topProfiles) == 0 {
+		total, err := database.CountLevelsLeaderboard(guildID)
+		if err == nil && total == 0 {
 			return ctx.ReplyEphemeral("📉 Aún no hay usuarios con experiencia en este servidor.")
 		}
 
-		description := "¡Estos son los usuarios más activos del servidor!\n\n"
-
-		for i, profile := range topProfiles {
-			medal := "🏅"
-			switch i {
-			case 0:
-				medal = "🥇"
-			case 1:
-				medal = "🥈"
-			case 2:
-				medal = "🥉"
-			}
-
-			description += fmt.Sprintf("%s **#%d** <@%s> - Nivel %d (%d XP)\n", medal, i+1, profile.UserID, profile.Level, profile.XP)
+		page := 0
+		if ctx.HasOption("pagina") {
+			page = int(ctx.GetIntOption("pagina")) - 1
 		}
 
-		embed := &discordgo.MessageEmbed{
-			Title:       "🏆 Tabla de Clasificación de Niveles",
-			Description: description,
-			Color:       0xFFD700, // Gold
+		embed, components, err := leaderboard.Render(leaderboard.BoardLevels, guildID, ctx.Interaction.Member.User.ID, page)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Error obteniendo leaderboard para %s: %v", guildID, err), "Leaderboard")
+			return ctx.ReplyEphemeral("❌ Ocurrió un error al obtener la clasificación.")
 		}
 
-		return ctx.ReplyEmbed(embed)
+		return ctx.Session.InteractionRespond(ctx.Interaction.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Embeds:     []*discordgo.MessageEmbed{embed},
+				Components: components,
+			},
+		})
 	},
 }
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"go.mongodb.org/mongo-driver/bson"
 
+	"github.com/PancyStudios/PancyBotGo/pkg/cards"
 	"github.com/PancyStudios/PancyBotGo/pkg/database"
 	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/leveling"
 	"github.com/PancyStudios/PancyBotGo/pkg/logger"
 	"github.com/bwmarrin/discordgo"
 )
@@ -28,7 +30,7 @@ var rankCommand = &discord.Command{
 		}
 
 		// Verificar si el sistema está activado
-		guildData, err := database.GlobalGuildDM.Get(bson.M{"id": guildID})
+		guildData, err := database.GlobalGuildDM.WithContext(ctx.Context()).Get(bson.M{"id": guildID})
 		if err != nil || guildData == nil || !guildData.Levels.Enable {
 			return ctx.ReplyEphemeral("❌ El sistema de niveles está desactivado en este servidor.")
 		}
@@ -49,53 +51,20 @@ var rankCommand = &discord.Command{
 			return ctx.ReplyEphemeral("❌ Ocurrió un error al obtener el rango.")
 		}
 
-		// Calcular XP necesaria para el próximo nivel
-		nextLevel := profile.Level + 1
-		requiredXP := nextLevel * nextLevel * 100
-
-		// Calcular XP base del nivel actual
-		currentLevelXP := int64(0)
-		if profile.Level > 0 {
-			currentLevelXP = profile.Level * profile.Level * 100
+		if err := ctx.Defer(); err != nil {
+			return err
 		}
 
-		// Progreso dentro del nivel actual
-		xpInCurrentLevel := profile.XP - currentLevelXP
-		xpNeededForNext := requiredXP - currentLevelXP
-
-		progressPercent := float64(xpInCurrentLevel) / float64(xpNeededForNext) * 100
-
-		progressBar := createProgressBar(progressPercent, 10)
-
-		embed := discord.NewEmbed().
-			SetTitle(fmt.Sprintf("🌟 Rango de %s", targetUser.Username)).
-			SetDescription(fmt.Sprintf("¡Sigue chateando para subir de nivel!\n\n**Nivel Actual:** %d\n**Experiencia:** %d / %d XP\n\n%s (%.1f%%)", profile.Level, profile.XP, requiredXP, progressBar, progressPercent)).
-			SetColor(0x00FFFF). // Cyan
-			SetThumbnail(targetUser.AvatarURL("128")).
-			SetFooter(fmt.Sprintf("Mensajes totales: %d", profile.TotalMessages), "").
-			Build()
+		file, err := cards.Rank(targetUser, profile, guildData)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Error generando tarjeta de rango: %v", err), "RankCommand")
+			return ctx.EditReplyEmbed(discord.NewEmbed().
+				SetTitle(fmt.Sprintf("🌟 Rango de %s", targetUser.Username)).
+				SetDescription(fmt.Sprintf("**Nivel Actual:** %d\n**Experiencia:** %d / %d XP\n**Tiempo en voz:** %s", profile.Level, profile.XP, leveling.Resolve(guildData.Levels).XPForLevel(profile.Level+1), leveling.FormatVoice(profile.VoiceSeconds))).
+				SetColor(0x00FFFF). // Cyan
+				Build())
+		}
 
-		return ctx.ReplyEmbed(embed)
+		return ctx.EditReplyFiles(file)
 	},
 }
-
-func createProgressBar(percent float64, length int) string {
-	filledBlocks := int((percent / 100.0) * float64(length))
-	if filledBlocks > length {
-		filledBlocks = length
-	}
-	if filledBlocks < 0 {
-		filledBlocks = 0
-	}
-
-	emptyBlocks := length - filledBlocks
-
-	bar := ""
-	for i := 0; i < filledBlocks; i++ {
-		bar += "🟦"
-	}
-	for i := 0; i < emptyBlocks; i++ {
-		bar += "⬛"
-	}
-	return bar
-}
@@ -13,7 +13,10 @@ func RegisterCommands(client *discord.ExtendedClient) {
 		leaderboardCommand,
 		toggleCommand,
 		rewardsCommand,
+		configCommand,
+		adminCommand,
 	)
 
 	client.CommandHandler.AddGlobalCommand(levelsGroup)
+	client.CommandHandler.RegisterCommand(voiceTopCommand)
 }
@@ -20,7 +20,7 @@ var toggleCommand = &discord.Command{
 		}
 
 		// Obtener configuración del servidor
-		guildData, err := database.GlobalGuildDM.Get(bson.M{"id": guildID})
+		guildData, err := database.GlobalGuildDM.WithContext(ctx.Context()).Get(bson.M{"id": guildID})
 		if err != nil {
 			return ctx.ReplyEphemeral(fmt.Sprintf("❌ Error al obtener la configuración del servidor: %v", err))
 		}
@@ -30,7 +30,7 @@ var toggleCommand = &discord.Command{
 		guildData.Levels.Enable = newState
 
 		// Guardar configuración
-		_, err = database.GlobalGuildDM.Set(bson.M{"id": guildID}, guildData)
+		_, err = database.GlobalGuildDM.WithContext(ctx.Context()).Set(bson.M{"id": guildID}, guildData)
 		if err != nil {
 			return ctx.ReplyEphemeral(fmt.Sprintf("❌ Error al guardar la configuración: %v", err))
 		}
@@ -0,0 +1,61 @@
+package levels
+
+import (
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/leaderboard"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/bwmarrin/discordgo"
+)
+
+var voiceTopCommand = &discord.Command{
+	Name:        "voicetop",
+	Description: "🎤 | Muestra los usuarios que más tiempo pasan en canales de voz",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "pagina",
+			Description: "📄 | Página de la clasificación",
+			Required:    false,
+			MinValue:    func() *float64 { v := 1.0; return &v }(),
+		},
+	},
+	Run: func(ctx *discord.CommandContext) error {
+		guildID := ctx.Interaction.GuildID
+		if guildID == "" {
+			return ctx.ReplyEphemeral("❌ Este comando solo se puede usar en un servidor.")
+		}
+
+		guildData, err := database.GlobalGuildDM.WithContext(ctx.Context()).Get(bson.M{"id": guildID})
+		if err != nil || guildData == nil || !guildData.Levels.Enable {
+			return ctx.ReplyEphemeral("❌ El sistema de niveles está desactivado en este servidor.")
+		}
+
+		total, err := database.CountVoiceLeaderboard(guildID)
+		if err == nil && total == 0 {
+			return ctx.ReplyEphemeral("📉 Aún nadie ha pasado tiempo en voz en este servidor.")
+		}
+
+		page := 0
+		if ctx.HasOption("pagina") {
+			page = int(ctx.GetIntOption("pagina")) - 1
+		}
+
+		embed, components, err := leaderboard.Render(leaderboard.BoardVoice, guildID, ctx.Interaction.Member.User.ID, page)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Error obteniendo el top de voz para %s: %v", guildID, err), "Leaderboard")
+			return ctx.ReplyEphemeral("❌ Ocurrió un error al obtener la clasificación.")
+		}
+
+		return ctx.Session.InteractionRespond(ctx.Interaction.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Embeds:     []*discordgo.MessageEmbed{embed},
+				Components: components,
+			},
+		})
+	},
+}
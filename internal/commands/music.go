@@ -167,7 +167,7 @@ func playHandler(ctx *discord.CommandContext) error {
 			return
 		}
 
-		result, err := lavalinkClient.Search(query)
+		result, err := lavalinkClient.SearchContext(ctx.Context(), query)
 		if err != nil {
 			err := ctx.EditReply(fmt.Sprintf("❌ Error buscando: %v", err))
 			if err != nil {
@@ -199,7 +199,7 @@ func playHandler(ctx *discord.CommandContext) error {
 			for _, track := range tracks {
 				track.RequesterID = ctx.User().ID
 				track.RequesterName = ctx.User().Username
-				if err := lavalinkClient.Play(ctx.Interaction.GuildID, voiceState.ChannelID, ctx.Interaction.ChannelID, track); err != nil {
+				if err := lavalinkClient.PlayContext(ctx.Context(), ctx.Interaction.GuildID, voiceState.ChannelID, ctx.Interaction.ChannelID, track); err != nil {
 					// Log error but continue
 					continue
 				}
@@ -222,7 +222,7 @@ func playHandler(ctx *discord.CommandContext) error {
 		track.RequesterName = ctx.User().Username
 
 		// Play the track
-		if err := lavalinkClient.Play(ctx.Interaction.GuildID, voiceState.ChannelID, ctx.Interaction.ChannelID, track); err != nil {
+		if err := lavalinkClient.PlayContext(ctx.Context(), ctx.Interaction.GuildID, voiceState.ChannelID, ctx.Interaction.ChannelID, track); err != nil {
 			err := ctx.EditReply(fmt.Sprintf("❌ Error reproduciendo: %v", err))
 			if err != nil {
 				return
@@ -471,7 +471,7 @@ func playAutoComplete(ctx *discord.CommandContext) {
 			return
 		}
 
-		result, err := lavalinkClient.Search(query)
+		result, err := lavalinkClient.SearchContext(ctx.Context(), query)
 		if err != nil {
 			return
 		}
@@ -545,7 +545,7 @@ func radioHandler(ctx *discord.CommandContext) error {
 			}
 		}
 
-		result, err := lavalinkClient.Search(stationURL)
+		result, err := lavalinkClient.SearchContext(ctx.Context(), stationURL)
 		if err != nil {
 			err := ctx.EditReply(fmt.Sprintf("❌ Error buscando la radio: %v", err))
 			if err != nil {
@@ -571,7 +571,7 @@ func radioHandler(ctx *discord.CommandContext) error {
 		}
 
 		// Play the radio track
-		if err := lavalinkClient.Play(ctx.Interaction.GuildID, voiceState.ChannelID, ctx.Interaction.ChannelID, track); err != nil {
+		if err := lavalinkClient.PlayContext(ctx.Context(), ctx.Interaction.GuildID, voiceState.ChannelID, ctx.Interaction.ChannelID, track); err != nil {
 			err := ctx.EditReply(fmt.Sprintf("❌ Error reproduciendo: %v", err))
 			if err != nil {
 				return
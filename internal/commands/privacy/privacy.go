@@ -0,0 +1,73 @@
+package privacy
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/PancyStudios/PancyBotGo/pkg/privacy"
+	"github.com/bwmarrin/discordgo"
+)
+
+var exportCommand = discord.NewCommand(
+	"export",
+	"📦 | Recibe por mensaje directo un archivo con todos tus datos",
+	"privacy",
+	func(ctx *discord.CommandContext) error {
+		// Gathering every collection can take longer than an interaction allows
+		if err := ctx.Session.InteractionRespond(ctx.Interaction.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
+		}); err != nil {
+			return err
+		}
+
+		userID := ctx.User().ID
+		if err := privacy.SendExport(ctx.Session, userID); err != nil {
+			if !errors.Is(err, privacy.ErrTooSoon) && !errors.Is(err, privacy.ErrDMClosed) {
+				logger.Error(fmt.Sprintf("Error exportando los datos de %s: %v", userID, err), "Privacy")
+			}
+			return ctx.EditReplyText(privacy.ErrorText(err))
+		}
+		return ctx.EditReplyText("📬 Te envié tus datos por mensaje directo.")
+	},
+).RequiresDatabase()
+
+var deleteCommand = discord.NewCommand(
+	"delete",
+	"🗑️ | Borra tus datos de todos los servidores",
+	"privacy",
+	func(ctx *discord.CommandContext) error {
+		return confirm(ctx, privacy.Action{Kind: privacy.ActionDeleteUser, UserID: ctx.User().ID})
+	},
+).RequiresDatabase()
+
+var deleteServerCommand = discord.NewCommand(
+	"delete-server",
+	"💣 | Borra todos los datos de este servidor (solo el dueño)",
+	"privacy",
+	func(ctx *discord.CommandContext) error {
+		guildID := ctx.Interaction.GuildID
+		if guildID == "" {
+			return ctx.ReplyEphemeral("❌ Este comando solo se puede usar en un servidor.")
+		}
+		return confirm(ctx, privacy.Action{Kind: privacy.ActionDeleteGuild, UserID: ctx.User().ID, GuildID: guildID})
+	},
+).RequiresDatabase()
+
+// confirm asks the requester to confirm a deletion
+func confirm(ctx *discord.CommandContext, action privacy.Action) error {
+	action, err := privacy.Prepare(ctx.Session, action)
+	if err != nil {
+		return ctx.ReplyEphemeral(privacy.ErrorText(err))
+	}
+	return ctx.Session.InteractionRespond(ctx.Interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{privacy.ConfirmEmbed(action)},
+			Components: privacy.ConfirmComponents(action),
+			Flags:      discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
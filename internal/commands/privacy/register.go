@@ -0,0 +1,19 @@
+package privacy
+
+import (
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+)
+
+// RegisterCommands registers the privacy commands. They also work in DMs, so
+// users can ask for their data without sharing a server with the bot.
+func RegisterCommands(client *discord.ExtendedClient) {
+	privacyGroup := client.CommandHandler.BuildUserCommandGroup(
+		"privacy",
+		"🔒 | Descarga o borra los datos que el bot guarda sobre ti",
+		exportCommand,
+		deleteCommand,
+		deleteServerCommand,
+	)
+
+	client.CommandHandler.AddGlobalCommand(privacyGroup)
+}
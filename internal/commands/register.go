@@ -10,10 +10,13 @@ import (
 	"github.com/PancyStudios/PancyBotGo/internal/commands/fun"
 	"github.com/PancyStudios/PancyBotGo/internal/commands/help"
 	"github.com/PancyStudios/PancyBotGo/internal/commands/ia"
+	"github.com/PancyStudios/PancyBotGo/internal/commands/invites"
 	"github.com/PancyStudios/PancyBotGo/internal/commands/levels"
 	"github.com/PancyStudios/PancyBotGo/internal/commands/mod"
 	"github.com/PancyStudios/PancyBotGo/internal/commands/premium"
+	"github.com/PancyStudios/PancyBotGo/internal/commands/privacy"
 	"github.com/PancyStudios/PancyBotGo/internal/commands/reaction"
+	"github.com/PancyStudios/PancyBotGo/internal/commands/seasons"
 	"github.com/PancyStudios/PancyBotGo/internal/commands/security"
 	"github.com/PancyStudios/PancyBotGo/internal/commands/utils"
 	"github.com/PancyStudios/PancyBotGo/pkg/discord"
@@ -61,6 +64,15 @@ func RegisterAll(client *discord.ExtendedClient) {
 	// Levels commands (/levels rank, /levels leaderboard)
 	levels.RegisterCommands(client)
 
+	// Seasons commands (/season info, /season top)
+	seasons.RegisterCommands(client)
+
+	// Invites commands (/invites info, /invites leaderboard)
+	invites.RegisterCommands(client)
+
+	// Privacy commands (/privacy export, /privacy delete)
+	privacy.RegisterCommands(client)
+
 	// Help commands (/help cmds)
 	help.Register(client)
 }
@@ -0,0 +1,149 @@
+package seasons
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/PancyStudios/PancyBotGo/pkg/seasons"
+	"github.com/bwmarrin/discordgo"
+)
+
+var startCommand = &discord.Command{
+	Name:            "start",
+	Description:     "▶️ | Empieza una nueva temporada",
+	UserPermissions: discordgo.PermissionManageGuild,
+	Run: func(ctx *discord.CommandContext) error {
+		guildID := ctx.Interaction.GuildID
+		if guildID == "" {
+			return ctx.ReplyEphemeral("❌ Este comando solo se puede usar en un servidor.")
+		}
+
+		season, err := seasons.Start(guildID, time.Now())
+		if err != nil {
+			logger.Warn(fmt.Sprintf("No se pudo iniciar la temporada de %s: %v", guildID, err), "Seasons")
+			return ctx.ReplyEphemeral(seasons.ErrorText(err))
+		}
+		return ctx.ReplyEmbed(discord.NewEmbed().
+			SetTitle(fmt.Sprintf("▶️ ¡Empieza la Temporada %d!", season.Number)).
+			SetDescription(fmt.Sprintf("La experiencia y las ganancias de la temporada empiezan desde cero. Termina <t:%d:R>.", season.EndsAt.Unix())).
+			SetColor(0xE67E22).
+			Build())
+	},
+}
+
+var endCommand = &discord.Command{
+	Name:            "end",
+	Description:     "⏹️ | Termina la temporada en curso y reparte los premios",
+	UserPermissions: discordgo.PermissionManageGuild,
+	Run: func(ctx *discord.CommandContext) error {
+		guildID := ctx.Interaction.GuildID
+		if guildID == "" {
+			return ctx.ReplyEphemeral("❌ Este comando solo se puede usar en un servidor.")
+		}
+		if err := ctx.Defer(); err != nil {
+			return err
+		}
+
+		season, next, err := seasons.End(ctx.Session, guildID, time.Now())
+		if err != nil {
+			logger.Warn(fmt.Sprintf("No se pudo cerrar la temporada de %s: %v", guildID, err), "Seasons")
+			return ctx.EditReplyText(seasons.ErrorText(err))
+		}
+
+		message := fmt.Sprintf("✅ La **Temporada %d** terminó.", season.Number)
+		if next != nil {
+			message += fmt.Sprintf(" La **Temporada %d** ya empezó.", next.Number)
+		}
+		return ctx.EditReplyText(message)
+	},
+}
+
+var configCommand = &discord.Command{
+	Name:            "config",
+	Description:     "⚙️ | Configura la duración, los anuncios y los premios de las temporadas",
+	UserPermissions: discordgo.PermissionManageGuild,
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "dias",
+			Description: fmt.Sprintf("📅 | Duración de cada temporada en días (0 restablece %d)", seasons.DefaultLengthDays),
+			MinValue:    func() *float64 { v := 0.0; return &v }(),
+			MaxValue:    seasons.MaxLengthDays,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Name:        "renovar",
+			Description: "🔁 | Empezar la siguiente temporada al terminar una",
+		},
+		{
+			Type:         discordgo.ApplicationCommandOptionChannel,
+			Name:         "canal",
+			Description:  "📢 | Canal donde anunciar los resultados",
+			ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText, discordgo.ChannelTypeGuildNews},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "top",
+			Description: "🎖️ | Cuántos usuarios de cada tabla reciben el rol (0 desactiva)",
+			MinValue:    func() *float64 { v := 0.0; return &v }(),
+			MaxValue:    seasons.MaxRewardTop,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionRole,
+			Name:        "rol_niveles",
+			Description: "🌟 | Rol para los primeros en experiencia",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionRole,
+			Name:        "rol_economia",
+			Description: "💰 | Rol para los primeros en ganancias",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Name:        "quitar_canal",
+			Description: "🗑️ | Dejar de anunciar los resultados",
+		},
+	},
+	Run: func(ctx *discord.CommandContext) error {
+		guildID := ctx.Interaction.GuildID
+		if guildID == "" {
+			return ctx.ReplyEphemeral("❌ Este comando solo se puede usar en un servidor.")
+		}
+
+		cfg, err := seasons.Configure(guildID, func(cfg *models.SeasonsConfig) {
+			if ctx.HasOption("dias") {
+				cfg.LengthDays = int(ctx.GetIntOption("dias"))
+			}
+			if ctx.HasOption("renovar") {
+				cfg.AutoRenew = ctx.GetBoolOption("renovar")
+			}
+			if channel := ctx.GetChannelOption("canal"); channel != nil {
+				cfg.AnnounceChannel = channel.ID
+			}
+			if ctx.GetBoolOption("quitar_canal") {
+				cfg.AnnounceChannel = ""
+			}
+			if ctx.HasOption("top") {
+				cfg.RewardTop = int(ctx.GetIntOption("top"))
+			}
+			if role := ctx.GetRoleOption("rol_niveles"); role != nil {
+				cfg.LevelsRole = role.ID
+			}
+			if role := ctx.GetRoleOption("rol_economia"); role != nil {
+				cfg.EconomyRole = role.ID
+			}
+		})
+		if err != nil {
+			return ctx.ReplyEphemeral(seasons.ErrorText(err))
+		}
+
+		return ctx.ReplyEmbed(discord.NewEmbed().
+			SetTitle("⚙️ Configuración de Temporadas").
+			SetDescription(seasons.Summary(cfg)).
+			SetColor(discord.ColorSuccess).
+			Build())
+	},
+}
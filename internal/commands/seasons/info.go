@@ -0,0 +1,122 @@
+package seasons
+
+import (
+	"fmt"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/leaderboard"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/PancyStudios/PancyBotGo/pkg/seasons"
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var infoCommand = &discord.Command{
+	Name:        "info",
+	Description: "🏁 | Muestra la temporada en curso y quién va primero",
+	Run: func(ctx *discord.CommandContext) error {
+		guildID := ctx.Interaction.GuildID
+		if guildID == "" {
+			return ctx.ReplyEphemeral("❌ Este comando solo se puede usar en un servidor.")
+		}
+
+		guildData, err := database.GlobalGuildDM.WithContext(ctx.Context()).Get(bson.M{"id": guildID})
+		if err != nil || guildData == nil {
+			guildData = models.NewDefaultGuildDocument(guildID)
+		}
+		return ctx.ReplyEmbed(seasons.StatusEmbed(guildData))
+	},
+}
+
+var topCommand = &discord.Command{
+	Name:        "top",
+	Description: "🏆 | Clasificación de la temporada en curso",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "tabla",
+			Description: "📊 | Experiencia o ganancias de la temporada",
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{Name: "Experiencia", Value: leaderboard.BoardSeasonLevels},
+				{Name: "Ganancias", Value: leaderboard.BoardSeasonEconomy},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "pagina",
+			Description: "📄 | Página de la clasificación",
+			MinValue:    func() *float64 { v := 1.0; return &v }(),
+		},
+	},
+	Run: func(ctx *discord.CommandContext) error {
+		guildID := ctx.Interaction.GuildID
+		if guildID == "" {
+			return ctx.ReplyEphemeral("❌ Este comando solo se puede usar en un servidor.")
+		}
+
+		board := leaderboard.BoardSeasonLevels
+		if ctx.GetStringOption("tabla") == leaderboard.BoardSeasonEconomy {
+			board = leaderboard.BoardSeasonEconomy
+		}
+		page := 0
+		if ctx.HasOption("pagina") {
+			page = int(ctx.GetIntOption("pagina")) - 1
+		}
+
+		embed, components, err := leaderboard.Render(board, guildID, ctx.User().ID, page)
+		if err != nil {
+			if err != seasons.ErrNotRunning {
+				logger.Error(fmt.Sprintf("Error obteniendo la clasificación de temporada para %s: %v", guildID, err), "Leaderboard")
+			}
+			return ctx.ReplyEphemeral(seasons.ErrorText(err))
+		}
+
+		return ctx.Session.InteractionRespond(ctx.Interaction.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Embeds:     []*discordgo.MessageEmbed{embed},
+				Components: components,
+			},
+		})
+	},
+}
+
+var historyCommand = &discord.Command{
+	Name:        "history",
+	Description: "📜 | Resultados de las temporadas anteriores",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "numero",
+			Description: "🔢 | Temporada de la que ver la clasificación final",
+			MinValue:    func() *float64 { v := 1.0; return &v }(),
+		},
+	},
+	Run: func(ctx *discord.CommandContext) error {
+		guildID := ctx.Interaction.GuildID
+		if guildID == "" {
+			return ctx.ReplyEphemeral("❌ Este comando solo se puede usar en un servidor.")
+		}
+
+		if !ctx.HasOption("numero") {
+			past, err := database.GetPastSeasons(guildID, 15)
+			if err != nil {
+				return ctx.ReplyEphemeral(seasons.ErrorText(err))
+			}
+			return ctx.ReplyEmbed(seasons.HistoryEmbed(past))
+		}
+
+		number := int(ctx.GetIntOption("numero"))
+		season, err := database.GetSeason(guildID, number)
+		if err != nil || season == nil || season.EndedAt.IsZero() {
+			return ctx.ReplyEphemeral(fmt.Sprintf("❌ La temporada %d no existe o aún no ha terminado.", number))
+		}
+		guildData, err := database.GlobalGuildDM.WithContext(ctx.Context()).Get(bson.M{"id": guildID})
+		if err != nil || guildData == nil {
+			guildData = models.NewDefaultGuildDocument(guildID)
+		}
+		return ctx.ReplyEmbed(seasons.ResultsEmbed(guildData, season, nil))
+	},
+}
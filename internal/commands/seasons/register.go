@@ -0,0 +1,21 @@
+package seasons
+
+import (
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+)
+
+// RegisterCommands registers the season commands
+func RegisterCommands(client *discord.ExtendedClient) {
+	seasonGroup := client.CommandHandler.BuildCommandGroup(
+		"season",
+		"🏁 | Temporadas de niveles y economía",
+		infoCommand,
+		topCommand,
+		historyCommand,
+		startCommand,
+		endCommand,
+		configCommand,
+	)
+
+	client.CommandHandler.AddGlobalCommand(seasonGroup)
+}
@@ -5,6 +5,8 @@ import (
 
 	"github.com/PancyStudios/PancyBotGo/pkg/database"
 	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/PancyStudios/PancyBotGo/pkg/verification"
 	"github.com/bwmarrin/discordgo"
 	"go.mongodb.org/mongo-driver/bson"
 )
@@ -22,6 +24,46 @@ func createVerificationCommand() *discord.Command {
 			Name:        "panel",
 			Description: "Envía el panel interactivo de verificación al canal configurado",
 		},
+		&discordgo.ApplicationCommandOption{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "config",
+			Description: "Configura el tipo de reto, los intentos y el tiempo límite de verificación",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "tipo",
+					Description: "Tipo de verificación",
+					Required:    true,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "Botón", Value: verification.TypeButton},
+						{Name: "Captcha (imagen)", Value: verification.TypeCaptcha},
+						{Name: "Pregunta matemática", Value: verification.TypeMath},
+						{Name: "Web", Value: verification.TypeWeb},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "intentos",
+					Description: "Intentos fallidos permitidos antes de expulsar (por defecto 3)",
+					MinValue:    func() *float64 { v := 1.0; return &v }(),
+					MaxValue:    10,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "tiempo_limite",
+					Description: "Minutos para verificarse antes de ser expulsado (0 para desactivar)",
+					MinValue:    func() *float64 { v := 0.0; return &v }(),
+					MaxValue:    1440,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "edad_minima",
+					Description: "Edad mínima de la cuenta en días para verificarse (0 para desactivar)",
+					MinValue:    func() *float64 { v := 0.0; return &v }(),
+					MaxValue:    365,
+				},
+			},
+		},
 	)
 }
 
@@ -37,7 +79,7 @@ func verificationHandler(ctx *discord.CommandContext) error {
 	}
 
 	// Fetch guild data
-	guildDoc, err := database.GlobalGuildDM.Get(bson.M{"id": ctx.Interaction.GuildID})
+	guildDoc, err := database.GlobalGuildDM.WithContext(ctx.Context()).Get(bson.M{"id": ctx.Interaction.GuildID})
 	if err != nil || guildDoc == nil {
 		return ctx.ReplyEmbed(discord.NewEmbed().
 			SetColor(0xFF0000).
@@ -45,6 +87,10 @@ func verificationHandler(ctx *discord.CommandContext) error {
 			Build())
 	}
 
+	if ctx.HasOption("config") {
+		return verificationConfigHandler(ctx, guildDoc)
+	}
+
 	// Check if verification is enabled and configured
 	if !guildDoc.Protection.Verification.Enable {
 		return ctx.ReplyEmbed(discord.NewEmbed().
@@ -67,13 +113,13 @@ func verificationHandler(ctx *discord.CommandContext) error {
 	// Create embed
 	embed := discord.NewEmbed().
 		SetTitle("🔐 Verificación Requerida").
-		SetDescription("Para acceder al resto del servidor y canales, debes verificarte.\n\nHaz clic en el botón de abajo para confirmar que no eres un bot y aceptar las reglas del servidor.").
+		SetDescription(verification.PanelDescription(guildDoc.Protection.Verification.Type)).
 		SetColor(0x2ecc71).
 		Build()
 
 	// Determine button type
 	var verifyButton discordgo.MessageComponent
-	if guildDoc.Protection.Verification.Type == "web" {
+	if guildDoc.Protection.Verification.Type == verification.TypeWeb {
 		verifyButton = discordgo.Button{
 			Label: "🌐 Verificar en la Web",
 			Style: discordgo.LinkButton,
@@ -83,7 +129,7 @@ func verificationHandler(ctx *discord.CommandContext) error {
 		verifyButton = discordgo.Button{
 			Label:    "✅ Verificarme",
 			Style:    discordgo.SuccessButton,
-			CustomID: "btn_verify_user",
+			CustomID: verification.ButtonCustomID,
 		}
 	}
 
@@ -112,3 +158,52 @@ func verificationHandler(ctx *discord.CommandContext) error {
 		SetDescription(fmt.Sprintf("✅ Panel de verificación enviado correctamente a <#%s>.", channelID)).
 		Build())
 }
+
+// verificationConfigHandler handles /security verification config
+func verificationConfigHandler(ctx *discord.CommandContext, guildDoc *models.GuildDocument) error {
+	cfg := &guildDoc.Protection.Verification
+	cfg.Type = ctx.GetStringOption("tipo")
+	if ctx.HasOption("intentos") {
+		cfg.MaxAttempts = int(ctx.GetIntOption("intentos"))
+	}
+	if ctx.HasOption("tiempo_limite") {
+		cfg.TimeoutMinutes = int(ctx.GetIntOption("tiempo_limite"))
+	}
+	if ctx.HasOption("edad_minima") {
+		cfg.MinAccountAgeDays = int(ctx.GetIntOption("edad_minima"))
+	}
+
+	if _, err := database.GlobalGuildDM.WithContext(ctx.Context()).Set(bson.M{"id": ctx.Interaction.GuildID}, guildDoc); err != nil {
+		return ctx.ReplyEmbed(discord.NewEmbed().
+			SetColor(0xFF0000).
+			SetDescription("❌ Error al guardar la configuración.").
+			Build())
+	}
+
+	return ctx.ReplyEmbed(verificationSummaryEmbed(*cfg))
+}
+
+// verificationSummaryEmbed describes the current verification settings
+func verificationSummaryEmbed(cfg models.VerificationConfig) *discordgo.MessageEmbed {
+	timeout := "Desactivado"
+	if cfg.TimeoutMinutes > 0 {
+		timeout = fmt.Sprintf("%d minutos", cfg.TimeoutMinutes)
+	}
+	minAge := "Desactivada"
+	if cfg.MinAccountAgeDays > 0 {
+		minAge = fmt.Sprintf("%d días", cfg.MinAccountAgeDays)
+	}
+
+	embed := discord.NewEmbed().
+		SetTitle("🔐 Verificación actualizada").
+		SetColor(discord.ColorSuccess).
+		AddField("Tipo", cfg.Type, true).
+		AddField("Intentos", fmt.Sprintf("%d", verification.MaxAttempts(cfg)), true).
+		AddField("Tiempo límite", timeout, true).
+		AddField("Edad mínima", minAge, true)
+
+	if !cfg.Enable {
+		embed.SetDescription("⚠️ El sistema de verificación está desactivado. Habilítalo en el **Dashboard** para que se aplique.")
+	}
+	return embed.Build()
+}
@@ -54,7 +54,7 @@ func createScreenshotCommand() *discord.Command {
 
 			// Prepare request payload
 			payload, _ := json.Marshal(map[string]string{"url": urlParam})
-			req, err := http.NewRequest("POST", urlApi, bytes.NewBuffer(payload))
+			req, err := http.NewRequestWithContext(ctx.Context(), "POST", urlApi, bytes.NewBuffer(payload))
 			if err != nil {
 				return sendError(ctx, fmt.Sprintf("Error interno: %v", err))
 			}
@@ -0,0 +1,126 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/casino"
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/ecoconfig"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/bwmarrin/discordgo"
+)
+
+// handleCasinoInteraction routes the buttons of blackjack tables and duels.
+// Returns true if the interaction was handled by this module
+func handleCasinoInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) bool {
+	if i.Member == nil || i.Type != discordgo.InteractionMessageComponent {
+		return false
+	}
+
+	customID := i.MessageComponentData().CustomID
+	userID := i.Member.User.ID
+	switch {
+	case strings.HasPrefix(customID, casino.HitButtonPrefix):
+		game, err := casino.Hit(strings.TrimPrefix(customID, casino.HitButtonPrefix), userID)
+		updateBlackjackTable(s, i, game, err)
+	case strings.HasPrefix(customID, casino.StandButtonPrefix):
+		game, err := casino.Stand(strings.TrimPrefix(customID, casino.StandButtonPrefix), userID)
+		updateBlackjackTable(s, i, game, err)
+	case strings.HasPrefix(customID, casino.DoubleButtonPrefix):
+		game, err := casino.Double(strings.TrimPrefix(customID, casino.DoubleButtonPrefix), userID)
+		updateBlackjackTable(s, i, game, err)
+	case strings.HasPrefix(customID, casino.AcceptButtonPrefix):
+		handleDuelAccept(s, i, strings.TrimPrefix(customID, casino.AcceptButtonPrefix))
+	case strings.HasPrefix(customID, casino.DeclineButtonPrefix):
+		handleDuelDecline(s, i, strings.TrimPrefix(customID, casino.DeclineButtonPrefix))
+	default:
+		return false
+	}
+	return true
+}
+
+func respondCasinoEphemeral(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error respondiendo interacción del casino: %v", err), "Casino")
+	}
+}
+
+// updateBlackjackTable re-renders the table after an action of the player
+func updateBlackjackTable(s *discordgo.Session, i *discordgo.InteractionCreate, game casino.Blackjack, err error) {
+	switch {
+	case game.ID == "":
+		respondCasinoEphemeral(s, i, casino.ErrorText(err, ecoconfig.CasinoFor(i.GuildID, false)))
+		return
+	case errors.Is(err, casino.ErrCannotDouble):
+		respondCasinoEphemeral(s, i, "❌ Solo puedes doblar con tus dos primeras cartas.")
+		return
+	case errors.Is(err, database.ErrInsufficientFunds):
+		respondCasinoEphemeral(s, i, casino.ErrorText(err, game.Wager.Rules))
+		return
+	case err != nil:
+		logger.Error(fmt.Sprintf("Error pagando la partida de blackjack %s: %v", game.ID, err), "Casino")
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{casino.BlackjackEmbed(game)},
+			Components: casino.BlackjackComponents(game),
+		},
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error actualizando la mesa de blackjack %s: %v", game.ID, err), "Casino")
+	}
+}
+
+func handleDuelAccept(s *discordgo.Session, i *discordgo.InteractionCreate, duelID string) {
+	d, err := casino.Accept(duelID, i.Member.User.ID)
+	switch {
+	case d.ID == "":
+		respondCasinoEphemeral(s, i, casino.ErrorText(err, ecoconfig.CasinoFor(i.GuildID, false)))
+		return
+	case d.Winner == "":
+		// The opponent could not pay the stake
+		respondCasinoEphemeral(s, i, casino.ErrorText(err, d.Challenger.Rules))
+		return
+	case err != nil:
+		logger.Error(fmt.Sprintf("Error pagando el duelo %s: %v", d.ID, err), "Casino")
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{casino.DuelResultEmbed(d)},
+			Components: []discordgo.MessageComponent{},
+		},
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error actualizando el duelo %s: %v", d.ID, err), "Casino")
+	}
+}
+
+func handleDuelDecline(s *discordgo.Session, i *discordgo.InteractionCreate, duelID string) {
+	d, err := casino.Decline(duelID, i.Member.User.ID)
+	if d.ID == "" {
+		respondCasinoEphemeral(s, i, casino.ErrorText(err, ecoconfig.CasinoFor(i.GuildID, false)))
+		return
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error devolviendo la apuesta del duelo %s: %v", d.ID, err), "Casino")
+	}
+
+	d.MessageID = i.Message.ID
+	d.ChannelID = i.ChannelID
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseDeferredMessageUpdate})
+	casino.CloseDuel(s, d, fmt.Sprintf("✖️ <@%s> rechazó el duelo. La apuesta fue devuelta.", i.Member.User.ID), discord.ColorError)
+}
@@ -85,6 +85,11 @@ func onGuildCreate(s *discordgo.Session, g *discordgo.GuildCreate) {
 	// Inicializar configuración por defecto si no existe
 	go func() {
 		defer errors.RecoverMiddleware()()
+		// The bot was added back before the data of the guild was purged
+		if err := database.CancelGuildPurge(g.ID); err != nil {
+			logger.Warn(fmt.Sprintf("Error cancelando el borrado de datos del guild %s: %v", g.ID, err), "Guild")
+		}
+
 		doc, err := database.GlobalGuildDM.Get(bson.M{"id": g.ID})
 		if err != nil || doc == nil {
 			newDoc := models.NewDefaultGuildDocument(g.ID)
@@ -202,5 +207,19 @@ func onGuildCreate(s *discordgo.Session, g *discordgo.GuildCreate) {
 
 // onGuildDelete is called when the bot is removed from a server
 func onGuildDelete(s *discordgo.Session, g *discordgo.GuildDelete) {
+	// An outage makes guilds unavailable without the bot leaving them
+	if g.Unavailable {
+		return
+	}
 	logger.Info(fmt.Sprintf("➖ Bot removido del servidor ID: %s", g.ID), "Guild")
+
+	days := config.Get().GuildDataRetentionDays
+	if days <= 0 {
+		return
+	}
+	if err := database.ScheduleGuildPurge(g.ID, time.Duration(days)*24*time.Hour); err != nil {
+		logger.Error(fmt.Sprintf("Error programando el borrado de datos del guild %s: %v", g.ID, err), "Guild")
+		return
+	}
+	logger.Info(fmt.Sprintf("Los datos del servidor %s se borrarán en %d días si no se vuelve a agregar el bot", g.ID, days), "Guild")
 }
@@ -6,17 +6,14 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/PancyStudios/PancyBotGo/internal/commands/embeds"
 	slashHelpCommands "github.com/PancyStudios/PancyBotGo/internal/commands/help"
 	"github.com/PancyStudios/PancyBotGo/internal/commands/economy"
 	helpMsgCommands "github.com/PancyStudios/PancyBotGo/internal/messagecommands/help"
-	"github.com/PancyStudios/PancyBotGo/pkg/database"
 	"github.com/PancyStudios/PancyBotGo/pkg/discord"
 	"github.com/PancyStudios/PancyBotGo/pkg/logger"
 	"github.com/bwmarrin/discordgo"
-	"go.mongodb.org/mongo-driver/bson"
 )
 
 // RegisterInteractionEvents registers all interaction-related event handlers
@@ -39,6 +36,30 @@ func onInteractionCreate(s *discordgo.Session, i *discordgo.InteractionCreate, c
 			return
 		}
 
+		if handleVerificationInteraction(s, i) {
+			return
+		}
+
+		if handleTradeInteraction(s, i) {
+			return
+		}
+
+		if handleLeaderboardInteraction(s, i) {
+			return
+		}
+
+		if handleCasinoInteraction(s, i) {
+			return
+		}
+
+		if handleLevelAdminInteraction(s, i) {
+			return
+		}
+
+		if handlePrivacyInteraction(s, i) {
+			return
+		}
+
 		if helpMsgCommands.HandleInteraction(s, i) {
 			return
 		}
@@ -55,8 +76,6 @@ func onInteractionCreate(s *discordgo.Session, i *discordgo.InteractionCreate, c
 			handleDenyButton(s, i)
 		case "menu_roles":
 			handleRoleMenu(s, i)
-		case "btn_verify_user":
-			handleVerifyUser(s, i)
 		default:
 			if len(customID) >= 9 && customID[:9] == "shop_nav_" {
 				handleShopNavigation(s, i)
@@ -76,6 +95,14 @@ func onInteractionCreate(s *discordgo.Session, i *discordgo.InteractionCreate, c
 			return
 		}
 
+		if handleVerificationInteraction(s, i) {
+			return
+		}
+
+		if handleTradeInteraction(s, i) {
+			return
+		}
+
 		switch modalID {
 		case "modal_feedback":
 			handleFeedbackModal(s, i)
@@ -224,82 +251,3 @@ func handleFeedbackModal(s *discordgo.Session, i *discordgo.InteractionCreate) {
 
 	logger.Info(fmt.Sprintf("Feedback recibido de %s: %s", i.Member.User.Username, feedback), "Interaction")
 }
-
-func handleVerifyUser(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	guildDoc, err := database.GlobalGuildDM.Get(bson.M{"id": i.GuildID})
-	if err != nil || guildDoc == nil || !guildDoc.Protection.Verification.Enable || guildDoc.Protection.Verification.Role == "" {
-		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "❌ El sistema de verificación no está activo o el rol no está configurado en este servidor.",
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
-		return
-	}
-
-	verifyRoleID := guildDoc.Protection.Verification.Role
-
-	// Check Account Age if configured
-	if guildDoc.Protection.Verification.MinAccountAgeDays > 0 {
-		// Snowflake to Timestamp formula
-		// Timestamp = (Snowflake >> 22) + DiscordEpoch (1420070400000)
-		idInt, _ := strconv.ParseInt(i.Member.User.ID, 10, 64)
-		timestampMs := (idInt >> 22) + 1420070400000
-		createdAt := time.UnixMilli(timestampMs)
-		accountAge := time.Since(createdAt)
-
-		minAgeDuration := time.Duration(guildDoc.Protection.Verification.MinAccountAgeDays) * 24 * time.Hour
-		if accountAge < minAgeDuration {
-			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-				Type: discordgo.InteractionResponseChannelMessageWithSource,
-				Data: &discordgo.InteractionResponseData{
-					Content: fmt.Sprintf("❌ Tu cuenta es demasiado reciente. Debe tener al menos **%d días** de antigüedad para verificarte.", guildDoc.Protection.Verification.MinAccountAgeDays),
-					Flags:   discordgo.MessageFlagsEphemeral,
-				},
-			})
-			return
-		}
-	}
-
-	// Check if user already has the role
-	hasRole := false
-	for _, r := range i.Member.Roles {
-		if r == verifyRoleID {
-			hasRole = true
-			break
-		}
-	}
-
-	if hasRole {
-		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "✅ Ya estás verificado.",
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
-		return
-	}
-
-	err = s.GuildMemberRoleAdd(i.GuildID, i.Member.User.ID, verifyRoleID)
-	if err != nil {
-		logger.Error(fmt.Sprintf("Error añadiendo rol de verificación: %v", err), "Interaction")
-		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "❌ No pude añadirte el rol. Es posible que me falten permisos o el rol esté por encima del mío.",
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
-		return
-	}
-
-	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Content: "🎉 ¡Te has verificado exitosamente!",
-			Flags:   discordgo.MessageFlagsEphemeral,
-		},
-	})
-}
@@ -0,0 +1,52 @@
+package events
+
+import (
+	"fmt"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/errors"
+	"github.com/PancyStudios/PancyBotGo/pkg/invites"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// RegisterInviteEvents registers the handlers that keep the invite cache up to date
+func RegisterInviteEvents(client *discord.ExtendedClient) {
+	client.Session.AddHandler(onInviteGuildCreate)
+	client.Session.AddHandler(onInviteGuildDelete)
+	client.Session.AddHandler(onInviteCreate)
+	client.Session.AddHandler(onInviteDelete)
+}
+
+// onInviteGuildCreate fills the invite cache of guilds with the tracker enabled (on startup and on join)
+func onInviteGuildCreate(s *discordgo.Session, g *discordgo.GuildCreate) {
+	go func() {
+		defer errors.RecoverMiddleware()()
+
+		guildDoc, err := database.GlobalGuildDM.Get(bson.M{"id": g.ID})
+		if err != nil || guildDoc == nil || !guildDoc.Invites.Enable {
+			return
+		}
+		if err := invites.Load(s, g.ID); err != nil {
+			logger.Warn(fmt.Sprintf("No se pudieron cargar las invitaciones de %s: %v", g.ID, err), "Invites")
+		}
+	}()
+}
+
+// onInviteGuildDelete drops the cache of guilds the bot left
+func onInviteGuildDelete(s *discordgo.Session, g *discordgo.GuildDelete) {
+	if g.Unavailable {
+		return
+	}
+	invites.Forget(g.ID)
+}
+
+func onInviteCreate(s *discordgo.Session, i *discordgo.InviteCreate) {
+	invites.Created(i)
+}
+
+func onInviteDelete(s *discordgo.Session, i *discordgo.InviteDelete) {
+	invites.Deleted(i.GuildID, i.Code)
+}
@@ -0,0 +1,124 @@
+package joingate
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/cards"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/PancyStudios/PancyBotGo/pkg/templates"
+	"github.com/bwmarrin/discordgo"
+)
+
+// pingOnJoinDelay is how long the ghost ping stays before being deleted
+var pingOnJoinDelay = 1 * time.Second
+
+// welcomeStage sends the welcome message (or the default embed when the guild has no configuration)
+func welcomeStage(jc *Context) Result {
+	guild := jc.Guild
+
+	// Fallback to default logic if no DB entry exists
+	if jc.GuildDoc == nil {
+		if guild.SystemChannelID != "" {
+			welcomeEmbed := &discordgo.MessageEmbed{
+				Title:       "¡Bienvenido/a! 🎉",
+				Description: fmt.Sprintf("Dale la bienvenida a <@%s>\nAhora somos **%d** miembros.", jc.User.ID, guild.MemberCount),
+				Color:       0x00ff00,
+				Thumbnail: &discordgo.MessageEmbedThumbnail{
+					URL: jc.User.AvatarURL("128"),
+				},
+				Footer: &discordgo.MessageEmbedFooter{
+					Text:    guild.Name,
+					IconURL: guild.IconURL("64"),
+				},
+				Timestamp: time.Now().Format(time.RFC3339),
+			}
+			jc.Session.ChannelMessageSendEmbed(guild.SystemChannelID, welcomeEmbed)
+		}
+		return Continue
+	}
+
+	welcome := jc.GuildDoc.Greetings.Welcome
+	if !welcome.Enable {
+		return Continue
+	}
+
+	vars := templates.NewVars(jc.User, guild).WithInviter(jc.Inviter)
+	messageText := templates.Render(welcome.Message, vars)
+
+	channelID := welcome.Channel
+	if channelID == "" {
+		channelID = guild.SystemChannelID
+	}
+
+	var welcomeEmbed *discordgo.MessageEmbed
+
+	// Revisar si usa Custom Embed
+	if ce := templates.FindEmbed(jc.GuildDoc, welcome.EmbedID); ce != nil {
+		welcomeEmbed = templates.RenderEmbed(*ce, vars)
+	}
+
+	sendData := &discordgo.MessageSend{
+		Content: messageText,
+	}
+
+	// Generated image card, shown inside the embed when there is one
+	if welcome.UseCard {
+		file, err := cards.Welcome(jc.User, guild, jc.GuildDoc)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Error generando tarjeta de bienvenida: %v", err), "Member")
+		} else {
+			sendData.Files = []*discordgo.File{file}
+			if welcomeEmbed != nil {
+				welcomeEmbed.Image = &discordgo.MessageEmbedImage{URL: "attachment://" + file.Name}
+			}
+		}
+	}
+
+	// Fallback si no hay custom embed pero tampoco hay texto, enviamos un embed por defecto
+	if welcomeEmbed == nil && messageText == "" && len(sendData.Files) == 0 {
+		welcomeEmbed = &discordgo.MessageEmbed{
+			Title:       "¡Bienvenido/a! 🎉",
+			Description: fmt.Sprintf("¡Bienvenido/a <@%s> a **%s**!", jc.User.ID, guild.Name),
+			Color:       0x00ff00,
+			Thumbnail:   &discordgo.MessageEmbedThumbnail{URL: jc.User.AvatarURL("128")},
+			Footer:      &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("Ahora somos %d miembros", guild.MemberCount), IconURL: guild.IconURL("64")},
+			Timestamp:   time.Now().Format(time.RFC3339),
+		}
+	}
+
+	if welcomeEmbed != nil {
+		sendData.Embeds = []*discordgo.MessageEmbed{welcomeEmbed}
+	}
+
+	if welcome.IsDM {
+		channel, err := jc.Session.UserChannelCreate(jc.User.ID)
+		if err == nil {
+			jc.Session.ChannelMessageSendComplex(channel.ID, sendData)
+		}
+	} else if channelID != "" {
+		jc.Session.ChannelMessageSendComplex(channelID, sendData)
+	}
+	return Continue
+}
+
+// pingOnJoinStage sends a ghost ping in every configured channel
+func pingOnJoinStage(jc *Context) Result {
+	if jc.GuildDoc == nil {
+		return Continue
+	}
+
+	for _, poj := range jc.GuildDoc.PingOnJoin {
+		if poj.ChannelID == "" {
+			continue
+		}
+		msg, err := jc.Session.ChannelMessageSend(poj.ChannelID, fmt.Sprintf("<@%s>", jc.User.ID))
+		if err == nil {
+			go func(msgID, channelID string) {
+				time.Sleep(pingOnJoinDelay)
+				jc.Session.ChannelMessageDelete(channelID, msgID)
+			}(msg.ID, poj.ChannelID)
+		}
+	}
+	return Continue
+}
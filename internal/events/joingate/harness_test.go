@@ -0,0 +1,174 @@
+package joingate
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/bwmarrin/discordgo"
+)
+
+// fakeAction is a Discord call recorded by fakeSession
+type fakeAction struct {
+	Kind      string // kick, ban, role, send, delete, dm
+	ChannelID string
+	UserID    string
+	RoleID    string
+	Reason    string
+	Content   string
+	Embeds    []*discordgo.MessageEmbed
+}
+
+// fakeSession records every call made by the stages instead of talking to Discord
+type fakeSession struct {
+	mu      sync.Mutex
+	actions []fakeAction
+	nextID  int
+	invites []*discordgo.Invite
+}
+
+func (f *fakeSession) record(a fakeAction) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.actions = append(f.actions, a)
+}
+
+func (f *fakeSession) newMessage(channelID string) *discordgo.Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextID++
+	return &discordgo.Message{ID: strconv.Itoa(f.nextID), ChannelID: channelID}
+}
+
+// Actions returns a copy of the recorded calls filtered by kind (all when empty)
+func (f *fakeSession) Actions(kind string) []fakeAction {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []fakeAction
+	for _, a := range f.actions {
+		if kind == "" || a.Kind == kind {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+func (f *fakeSession) GuildMemberDeleteWithReason(guildID, userID, reason string, _ ...discordgo.RequestOption) error {
+	f.record(fakeAction{Kind: "kick", UserID: userID, Reason: reason})
+	return nil
+}
+
+func (f *fakeSession) GuildBanCreateWithReason(guildID, userID, reason string, days int, _ ...discordgo.RequestOption) error {
+	f.record(fakeAction{Kind: "ban", UserID: userID, Reason: reason})
+	return nil
+}
+
+func (f *fakeSession) GuildMemberRoleAdd(guildID, userID, roleID string, _ ...discordgo.RequestOption) error {
+	f.record(fakeAction{Kind: "role", UserID: userID, RoleID: roleID})
+	return nil
+}
+
+func (f *fakeSession) ChannelMessageSend(channelID string, content string, _ ...discordgo.RequestOption) (*discordgo.Message, error) {
+	f.record(fakeAction{Kind: "send", ChannelID: channelID, Content: content})
+	return f.newMessage(channelID), nil
+}
+
+func (f *fakeSession) ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, _ ...discordgo.RequestOption) (*discordgo.Message, error) {
+	f.record(fakeAction{Kind: "send", ChannelID: channelID, Content: data.Content, Embeds: data.Embeds})
+	return f.newMessage(channelID), nil
+}
+
+func (f *fakeSession) ChannelMessageSendEmbed(channelID string, embed *discordgo.MessageEmbed, _ ...discordgo.RequestOption) (*discordgo.Message, error) {
+	f.record(fakeAction{Kind: "send", ChannelID: channelID, Embeds: []*discordgo.MessageEmbed{embed}})
+	return f.newMessage(channelID), nil
+}
+
+func (f *fakeSession) ChannelMessageDelete(channelID, messageID string, _ ...discordgo.RequestOption) error {
+	f.record(fakeAction{Kind: "delete", ChannelID: channelID})
+	return nil
+}
+
+func (f *fakeSession) UserChannelCreate(recipientID string, _ ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	f.record(fakeAction{Kind: "dm", UserID: recipientID})
+	return &discordgo.Channel{ID: "dm-" + recipientID}, nil
+}
+
+func (f *fakeSession) GuildInvites(guildID string, _ ...discordgo.RequestOption) ([]*discordgo.Invite, error) {
+	f.record(fakeAction{Kind: "invites"})
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make([]*discordgo.Invite, len(f.invites))
+	for i, inv := range f.invites {
+		copied := *inv
+		out[i] = &copied
+	}
+	return out, nil
+}
+
+func (f *fakeSession) RequestWithBucketID(method, urlStr string, data interface{}, bucketID string, _ ...discordgo.RequestOption) ([]byte, error) {
+	return nil, fmt.Errorf("fake session: %s %s not supported", method, urlStr)
+}
+
+// SetInvites replaces the invites returned by GuildInvites
+func (f *fakeSession) SetInvites(invites ...*discordgo.Invite) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.invites = invites
+}
+
+// joinHarness replays synthetic GuildMemberAdd events through a pipeline
+type joinHarness struct {
+	pipeline *Pipeline
+	session  *fakeSession
+	guild    *discordgo.Guild
+	guildDoc *models.GuildDocument
+	saves    int
+}
+
+func newJoinHarness(p *Pipeline, guildDoc *models.GuildDocument) *joinHarness {
+	return &joinHarness{
+		pipeline: p,
+		session:  &fakeSession{},
+		guild: &discordgo.Guild{
+			ID:              "guild-1",
+			Name:            "Test Guild",
+			OwnerID:         "owner-1",
+			SystemChannelID: "system-1",
+			MemberCount:     10,
+		},
+		guildDoc: guildDoc,
+	}
+}
+
+// Join replays a GuildMemberAdd for the user and returns the pipeline context
+func (h *joinHarness) Join(user *discordgo.User) (*Context, Result) {
+	event := &discordgo.GuildMemberAdd{Member: &discordgo.Member{GuildID: h.guild.ID, User: user}}
+	jc := &Context{
+		Session:  h.session,
+		GuildID:  event.GuildID,
+		Member:   event.Member,
+		User:     event.User,
+		Guild:    h.guild,
+		GuildDoc: h.guildDoc,
+		SaveGuild: func(doc *models.GuildDocument) error {
+			h.saves++
+			return nil
+		},
+	}
+	return jc, h.pipeline.Run(jc)
+}
+
+// snowflakeAt builds a user ID whose creation date is the given time
+func snowflakeAt(t time.Time) string {
+	return strconv.FormatInt((t.UnixMilli()-1420070400000)<<22, 10)
+}
+
+// newUser creates a human account created the given number of days ago
+func newUser(n int, ageDays int) *discordgo.User {
+	return &discordgo.User{
+		ID:       snowflakeAt(time.Now().Add(-time.Duration(ageDays)*24*time.Hour - time.Duration(n)*time.Millisecond)),
+		Username: fmt.Sprintf("user%d", n),
+	}
+}
@@ -0,0 +1,181 @@
+// Package joingate implements the staged pipeline that runs when a member joins a guild.
+// Stages are grouped in phases (security → verification → greeting → roles → logging)
+// and any stage before the logging phase can stop the join from going further.
+package joingate
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/bwmarrin/discordgo"
+)
+
+// Session is the subset of *discordgo.Session used by the join stages.
+// Tests provide a fake implementation to replay joins without Discord.
+type Session interface {
+	GuildMemberDeleteWithReason(guildID, userID, reason string, options ...discordgo.RequestOption) error
+	GuildBanCreateWithReason(guildID, userID, reason string, days int, options ...discordgo.RequestOption) error
+	GuildMemberRoleAdd(guildID, userID, roleID string, options ...discordgo.RequestOption) error
+	ChannelMessageSend(channelID string, content string, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageSendEmbed(channelID string, embed *discordgo.MessageEmbed, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageDelete(channelID, messageID string, options ...discordgo.RequestOption) error
+	UserChannelCreate(recipientID string, options ...discordgo.RequestOption) (*discordgo.Channel, error)
+	GuildInvites(guildID string, options ...discordgo.RequestOption) ([]*discordgo.Invite, error)
+	RequestWithBucketID(method, urlStr string, data interface{}, bucketID string, options ...discordgo.RequestOption) ([]byte, error)
+}
+
+// Phase orders stages inside the pipeline
+type Phase int
+
+const (
+	PhaseSecurity Phase = iota
+	PhaseVerification
+	PhaseGreeting
+	PhaseRoles
+	// PhaseLogging stages always run, even when an earlier stage stopped the join
+	PhaseLogging
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PhaseSecurity:
+		return "security"
+	case PhaseVerification:
+		return "verification"
+	case PhaseGreeting:
+		return "greeting"
+	case PhaseRoles:
+		return "roles"
+	case PhaseLogging:
+		return "logging"
+	}
+	return fmt.Sprintf("phase(%d)", int(p))
+}
+
+// Result is returned by every stage; Stop short-circuits the remaining stages
+type Result struct {
+	Stop   bool
+	Reason string
+}
+
+// Continue lets the join move on to the next stage
+var Continue = Result{}
+
+// Stop ends the pipeline for this join with the given reason
+func Stop(reason string) Result {
+	return Result{Stop: true, Reason: reason}
+}
+
+// Context carries the join being processed through the stages
+type Context struct {
+	Session Session
+	GuildID string
+	Member  *discordgo.Member
+	User    *discordgo.User
+	// Guild comes from the state cache; it is never nil while stages run
+	Guild *discordgo.Guild
+	// GuildDoc is nil when the guild has no configuration stored
+	GuildDoc *models.GuildDocument
+
+	// Inviter is the value of the {inviter} placeholder, filled by the invites stage
+	Inviter string
+
+	// Filled in when a stage stops the pipeline
+	StoppedBy  string
+	StopReason string
+
+	// invitesSeen is set once the invites stage attributed the join
+	invitesSeen bool
+
+	// SaveGuild persists changes made to GuildDoc by a stage
+	SaveGuild func(doc *models.GuildDocument) error
+}
+
+// Stage is a single registered step of the join pipeline
+type Stage interface {
+	Name() string
+	Phase() Phase
+	Run(jc *Context) Result
+}
+
+// funcStage adapts a plain function to the Stage interface
+type funcStage struct {
+	name  string
+	phase Phase
+	run   func(jc *Context) Result
+}
+
+func (f *funcStage) Name() string           { return f.name }
+func (f *funcStage) Phase() Phase           { return f.phase }
+func (f *funcStage) Run(jc *Context) Result { return f.run(jc) }
+
+// NewStage creates a stage from a function
+func NewStage(name string, phase Phase, run func(jc *Context) Result) Stage {
+	return &funcStage{name: name, phase: phase, run: run}
+}
+
+// Pipeline runs the registered stages in phase order
+type Pipeline struct {
+	stages []Stage
+}
+
+// New creates an empty pipeline
+func New() *Pipeline {
+	return &Pipeline{}
+}
+
+// Register adds a stage. Stages of the same phase keep their registration order.
+func (p *Pipeline) Register(stages ...Stage) *Pipeline {
+	p.stages = append(p.stages, stages...)
+	sort.SliceStable(p.stages, func(i, j int) bool {
+		return p.stages[i].Phase() < p.stages[j].Phase()
+	})
+	return p
+}
+
+// Stages returns the registered stages in execution order
+func (p *Pipeline) Stages() []Stage {
+	out := make([]Stage, len(p.stages))
+	copy(out, p.stages)
+	return out
+}
+
+// Run processes a join through every stage and returns the result that stopped it (if any)
+func (p *Pipeline) Run(jc *Context) Result {
+	final := Continue
+	for _, stage := range p.stages {
+		if final.Stop && stage.Phase() != PhaseLogging {
+			continue
+		}
+
+		res := stage.Run(jc)
+		if res.Stop && !final.Stop && stage.Phase() != PhaseLogging {
+			final = res
+			jc.StoppedBy = stage.Name()
+			jc.StopReason = res.Reason
+			logger.Debug(fmt.Sprintf("Unión de %s en %s detenida por %s: %s", jc.User.ID, jc.GuildID, stage.Name(), res.Reason), "JoinGate")
+		}
+	}
+	return final
+}
+
+// NewDefault creates the pipeline with all the built-in stages. Invites are
+// attributed after the security gates so a raid doesn't spend the invite rate
+// limit on members that are kicked anyway; a stopped join only refreshes the
+// invite snapshot once.
+func NewDefault() *Pipeline {
+	return New().Register(
+		newAntiRaidStage(),
+		NewStage("antibots", PhaseSecurity, antibotsStage),
+		NewStage("invites", PhaseSecurity, invitesStage),
+		NewStage("verification", PhaseVerification, verificationStage),
+		NewStage("welcome", PhaseGreeting, welcomeStage),
+		NewStage("ping-on-join", PhaseGreeting, pingOnJoinStage),
+		NewStage("autorole", PhaseRoles, autoroleStage),
+		NewStage("invites-sync", PhaseLogging, invitesSyncStage),
+		NewStage("join-log", PhaseLogging, joinLogStage),
+	)
+}
@@ -0,0 +1,288 @@
+package joingate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/bwmarrin/discordgo"
+)
+
+func testGuildDoc() *models.GuildDocument {
+	return models.NewDefaultGuildDocument("guild-1")
+}
+
+func TestRegisterOrdersByPhase(t *testing.T) {
+	noop := func(jc *Context) Result { return Continue }
+	p := New().Register(
+		NewStage("log", PhaseLogging, noop),
+		NewStage("roles", PhaseRoles, noop),
+		NewStage("security-a", PhaseSecurity, noop),
+		NewStage("greeting", PhaseGreeting, noop),
+		NewStage("security-b", PhaseSecurity, noop),
+	)
+
+	var names []string
+	for _, s := range p.Stages() {
+		names = append(names, s.Name())
+	}
+	expected := "security-a,security-b,greeting,roles,log"
+	if got := strings.Join(names, ","); got != expected {
+		t.Errorf("Expected order %s, got %s", expected, got)
+	}
+}
+
+func TestStopSkipsLaterStagesButLogs(t *testing.T) {
+	var ran []string
+	track := func(name string, res Result) func(jc *Context) Result {
+		return func(jc *Context) Result {
+			ran = append(ran, name)
+			return res
+		}
+	}
+	p := New().Register(
+		NewStage("gate", PhaseSecurity, track("gate", Stop("blocked"))),
+		NewStage("welcome", PhaseGreeting, track("welcome", Continue)),
+		NewStage("log", PhaseLogging, track("log", Continue)),
+	)
+
+	h := newJoinHarness(p, testGuildDoc())
+	jc, res := h.Join(newUser(1, 30))
+
+	if !res.Stop || res.Reason != "blocked" {
+		t.Errorf("Expected stop result with reason 'blocked', got %+v", res)
+	}
+	if jc.StoppedBy != "gate" {
+		t.Errorf("Expected StoppedBy 'gate', got '%s'", jc.StoppedBy)
+	}
+	if got := strings.Join(ran, ","); got != "gate,log" {
+		t.Errorf("Expected stages gate,log to run, got %s", got)
+	}
+}
+
+func TestPanicModeKicksAndSkipsWelcome(t *testing.T) {
+	doc := testGuildDoc()
+	doc.Protection.AntiRaid.Enable = true
+	doc.Greetings.Welcome.Enable = true
+	doc.Greetings.Welcome.Channel = "welcome-1"
+	doc.Invites.Enable = true
+
+	h := newJoinHarness(NewDefault(), doc)
+	jc, res := h.Join(newUser(1, 30))
+
+	if !res.Stop || jc.StoppedBy != "antiraid" {
+		t.Fatalf("Expected antiraid to stop the join, got %+v (by %s)", res, jc.StoppedBy)
+	}
+	if kicks := h.session.Actions("kick"); len(kicks) != 1 {
+		t.Errorf("Expected 1 kick, got %d", len(kicks))
+	}
+	if fetches := h.session.Actions("invites"); len(fetches) != 1 {
+		t.Errorf("Expected a single invite refresh for a kicked member, got %d", len(fetches))
+	}
+	if sends := h.session.Actions("send"); len(sends) != 0 {
+		t.Errorf("Expected no messages, got %d", len(sends))
+	}
+}
+
+func TestMinAccountAgeBans(t *testing.T) {
+	doc := testGuildDoc()
+	doc.Protection.AntiRaid.MinAccountAgeDays = 7
+	doc.Protection.AntiRaid.Action = "ban"
+
+	h := newJoinHarness(NewDefault(), doc)
+
+	if _, res := h.Join(newUser(1, 30)); res.Stop {
+		t.Errorf("Expected old account to pass, got %+v", res)
+	}
+	if _, res := h.Join(newUser(2, 1)); !res.Stop {
+		t.Error("Expected new account to be stopped")
+	}
+	if bans := h.session.Actions("ban"); len(bans) != 1 {
+		t.Errorf("Expected 1 ban, got %d", len(bans))
+	}
+}
+
+func TestRaidDetectionEnablesPanicMode(t *testing.T) {
+	doc := testGuildDoc()
+	doc.Protection.AntiRaid.JoinLimit = 3
+	doc.Protection.AntiRaid.TimeWindow = 60
+	doc.Configuration.LogsChannel = "logs-1"
+
+	h := newJoinHarness(NewDefault(), doc)
+	for n := 1; n <= 2; n++ {
+		if _, res := h.Join(newUser(n, 30)); res.Stop {
+			t.Fatalf("Join %d should not trigger the raid detector", n)
+		}
+	}
+
+	jc, res := h.Join(newUser(3, 30))
+	if !res.Stop || jc.StoppedBy != "antiraid" {
+		t.Fatalf("Expected the third join to trigger the raid detector, got %+v", res)
+	}
+	if !doc.Protection.AntiRaid.Enable {
+		t.Error("Expected panic mode to be enabled")
+	}
+	if h.saves != 1 {
+		t.Errorf("Expected guild to be saved once, got %d", h.saves)
+	}
+
+	var alerted, dmOwner bool
+	for _, a := range h.session.Actions("send") {
+		if a.ChannelID == "logs-1" {
+			alerted = true
+		}
+		if a.ChannelID == "dm-owner-1" {
+			dmOwner = true
+		}
+	}
+	if !alerted || !dmOwner {
+		t.Errorf("Expected alert in logs channel (%v) and owner DM (%v)", alerted, dmOwner)
+	}
+
+	// Later joins are blocked by panic mode
+	if _, res := h.Join(newUser(4, 30)); res.Reason != "Anti-Raid: Modo pánico activado" {
+		t.Errorf("Expected panic mode to block the next join, got %+v", res)
+	}
+}
+
+func TestAntibots(t *testing.T) {
+	tests := []struct {
+		mode     string
+		verified bool
+		kicked   bool
+	}{
+		{"all", true, true},
+		{"all", false, true},
+		{"only_nv", false, true},
+		{"only_nv", true, false},
+		{"only_v", true, true},
+		{"only_v", false, false},
+	}
+
+	for _, tt := range tests {
+		doc := testGuildDoc()
+		doc.Protection.Antibots.Enable = true
+		doc.Protection.Antibots.Type = tt.mode
+
+		bot := newUser(1, 30)
+		bot.Bot = true
+		if tt.verified {
+			bot.PublicFlags = discordgo.UserFlagVerifiedBot
+		}
+
+		h := newJoinHarness(NewDefault(), doc)
+		_, res := h.Join(bot)
+		kicked := len(h.session.Actions("kick")) > 0
+		if res.Stop != tt.kicked || kicked != tt.kicked {
+			t.Errorf("mode=%s verified=%v: expected kicked=%v, got %+v", tt.mode, tt.verified, tt.kicked, res)
+		}
+	}
+}
+
+func TestWelcomeAndAutorole(t *testing.T) {
+	doc := testGuildDoc()
+	doc.Greetings.Welcome.Enable = true
+	doc.Greetings.Welcome.Channel = "welcome-1"
+	doc.Greetings.Welcome.Message = "Hola {user}, bienvenido a {server}"
+	doc.Greetings.Autorole.Enable = true
+	doc.Greetings.Autorole.Roles = []string{"role-1", "role-2"}
+
+	h := newJoinHarness(NewDefault(), doc)
+	user := newUser(1, 30)
+	if _, res := h.Join(user); res.Stop {
+		t.Fatalf("Expected join to pass, got %+v", res)
+	}
+
+	sends := h.session.Actions("send")
+	if len(sends) != 1 || sends[0].ChannelID != "welcome-1" {
+		t.Fatalf("Expected one welcome message in welcome-1, got %+v", sends)
+	}
+	expected := "Hola <@" + user.ID + ">, bienvenido a Test Guild"
+	if sends[0].Content != expected {
+		t.Errorf("Expected welcome '%s', got '%s'", expected, sends[0].Content)
+	}
+	if roles := h.session.Actions("role"); len(roles) != 2 {
+		t.Errorf("Expected 2 autoroles, got %d", len(roles))
+	}
+}
+
+func TestDefaultWelcomeWithoutConfiguration(t *testing.T) {
+	h := newJoinHarness(NewDefault(), nil)
+	if _, res := h.Join(newUser(1, 30)); res.Stop {
+		t.Fatalf("Expected join to pass, got %+v", res)
+	}
+
+	sends := h.session.Actions("send")
+	if len(sends) != 1 || sends[0].ChannelID != "system-1" || len(sends[0].Embeds) != 1 {
+		t.Fatalf("Expected default welcome embed in the system channel, got %+v", sends)
+	}
+	if !strings.Contains(sends[0].Embeds[0].Description, "**10** miembros") {
+		t.Errorf("Expected member count from the cached guild, got '%s'", sends[0].Embeds[0].Description)
+	}
+}
+
+func TestInviterAttribution(t *testing.T) {
+	doc := testGuildDoc()
+	doc.Invites.Enable = true
+	doc.Greetings.Welcome.Enable = true
+	doc.Greetings.Welcome.Channel = "welcome-1"
+	doc.Greetings.Welcome.Message = "{if:inviter}Invitado por {inviter}{else}Sin invitación{/if}"
+
+	alice := &discordgo.User{ID: "alice"}
+	bob := &discordgo.User{ID: "bob"}
+	h := newJoinHarness(NewDefault(), doc)
+	h.session.SetInvites(
+		&discordgo.Invite{Code: "abc", Inviter: alice, Uses: 1},
+		&discordgo.Invite{Code: "xyz", Inviter: bob, Uses: 3},
+	)
+
+	welcomes := func() []string {
+		var out []string
+		for _, a := range h.session.Actions("send") {
+			if a.ChannelID == "welcome-1" {
+				out = append(out, a.Content)
+			}
+		}
+		return out
+	}
+
+	// The first join only fills the cache
+	h.Join(newUser(1, 30))
+
+	h.session.SetInvites(
+		&discordgo.Invite{Code: "abc", Inviter: alice, Uses: 2},
+		&discordgo.Invite{Code: "xyz", Inviter: bob, Uses: 3},
+	)
+	h.Join(newUser(2, 30))
+
+	// Both invites moved, the join cannot be attributed
+	h.session.SetInvites(
+		&discordgo.Invite{Code: "abc", Inviter: alice, Uses: 3},
+		&discordgo.Invite{Code: "xyz", Inviter: bob, Uses: 4},
+	)
+	h.Join(newUser(3, 30))
+
+	// A single-use invite that Discord deleted after the join
+	h.session.SetInvites(
+		&discordgo.Invite{Code: "abc", Inviter: alice, Uses: 3},
+		&discordgo.Invite{Code: "xyz", Inviter: bob, Uses: 4},
+		&discordgo.Invite{Code: "once", Inviter: bob, Uses: 0, MaxUses: 1},
+	)
+	h.Join(newUser(4, 30))
+	h.session.SetInvites(
+		&discordgo.Invite{Code: "abc", Inviter: alice, Uses: 3},
+		&discordgo.Invite{Code: "xyz", Inviter: bob, Uses: 4},
+	)
+	h.Join(newUser(5, 30))
+
+	expected := []string{"Sin invitación", "Invitado por <@alice>", "Sin invitación", "Sin invitación", "Invitado por <@bob>"}
+	got := welcomes()
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d welcomes, got %v", len(expected), got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("Join %d: expected '%s', got '%s'", i+1, expected[i], got[i])
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package joingate
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/bwmarrin/discordgo"
+)
+
+// antiRaidStage applies the minimum account age, panic mode and join-rate detection
+type antiRaidStage struct {
+	mu    sync.Mutex
+	joins map[string][]time.Time
+	nowFn func() time.Time
+}
+
+func newAntiRaidStage() *antiRaidStage {
+	return &antiRaidStage{
+		joins: make(map[string][]time.Time),
+		nowFn: time.Now,
+	}
+}
+
+func (a *antiRaidStage) Name() string { return "antiraid" }
+func (a *antiRaidStage) Phase() Phase { return PhaseSecurity }
+
+func (a *antiRaidStage) Run(jc *Context) Result {
+	if jc.GuildDoc == nil {
+		return Continue
+	}
+	antiRaid := &jc.GuildDoc.Protection.AntiRaid
+
+	// 1. Min Account Age
+	if antiRaid.MinAccountAgeDays > 0 {
+		createdAt, err := discordgo.SnowflakeTimestamp(jc.User.ID)
+		if err == nil {
+			ageDays := int(a.nowFn().Sub(createdAt).Hours() / 24)
+			if ageDays < antiRaid.MinAccountAgeDays {
+				reason := fmt.Sprintf("Anti-Raid: Cuenta muy reciente (%d días < %d días)", ageDays, antiRaid.MinAccountAgeDays)
+				punish(jc, antiRaid.Action, reason)
+				logger.Info(fmt.Sprintf("🛡️ %s expulsado/baneado por Anti-Raid (Edad de cuenta: %d días)", jc.User.Username, ageDays), "AntiRaid")
+				return Stop(reason)
+			}
+		}
+	}
+
+	// 2. Active Panic Mode
+	if antiRaid.Enable {
+		reason := "Anti-Raid: Modo pánico activado"
+		punish(jc, antiRaid.Action, reason)
+		logger.Info(fmt.Sprintf("🛡️ %s expulsado/baneado por Modo Pánico Anti-Raid", jc.User.Username), "AntiRaid")
+		return Stop(reason)
+	}
+
+	// 3. Raid Detection
+	if antiRaid.JoinLimit > 0 && antiRaid.TimeWindow > 0 {
+		if a.recordJoin(jc.GuildID, time.Duration(antiRaid.TimeWindow)*time.Second) < antiRaid.JoinLimit {
+			return Continue
+		}
+
+		// Trigger Panic Mode!
+		antiRaid.Enable = true
+		if jc.SaveGuild != nil {
+			if err := jc.SaveGuild(jc.GuildDoc); err != nil {
+				logger.Error(fmt.Sprintf("Error guardando el modo pánico de %s: %v", jc.GuildID, err), "AntiRaid")
+			}
+		}
+		logger.Warn(fmt.Sprintf("🚨 POSIBLE RAID DETECTADO en %s! Modo Pánico activado automáticamente.", jc.GuildID), "AntiRaid")
+
+		reason := "Anti-Raid: Límite de uniones superado (Modo Pánico Auto-Activado)"
+		punish(jc, antiRaid.Action, reason)
+		alertRaid(jc)
+		return Stop(reason)
+	}
+
+	return Continue
+}
+
+// recordJoin stores a join for the guild and returns how many joins happened inside the window
+func (a *antiRaidStage) recordJoin(guildID string, window time.Duration) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.nowFn()
+	var validJoins []time.Time
+	for _, j := range a.joins[guildID] {
+		if now.Sub(j) <= window {
+			validJoins = append(validJoins, j)
+		}
+	}
+	validJoins = append(validJoins, now)
+	a.joins[guildID] = validJoins
+	return len(validJoins)
+}
+
+// alertRaid notifies the logs (or system) channel and the owner that panic mode was enabled
+func alertRaid(jc *Context) {
+	embedAlert := discord.NewEmbed().
+		SetColor(0xFF0000). // Red
+		SetTitle("🚨 ¡ALERTA DE RAID MASIVO!").
+		SetDescription("Se detectó un pico inusual de nuevas cuentas uniéndose al servidor.\n\nEl **Modo Pánico Anti-Raid** ha sido activado automáticamente y todas las nuevas uniones serán bloqueadas.\n\n*Un administrador debe desactivarlo con `/security antiraid toggle` cuando sea seguro.*").
+		Build()
+
+	alertChannel := jc.GuildDoc.Configuration.LogsChannel
+	if alertChannel == "" {
+		alertChannel = jc.Guild.SystemChannelID
+	}
+	if alertChannel != "" {
+		jc.Session.ChannelMessageSendEmbed(alertChannel, embedAlert)
+	}
+
+	// Alert the owner via DM
+	if jc.Guild.OwnerID != "" {
+		dmChannel, err := jc.Session.UserChannelCreate(jc.Guild.OwnerID)
+		if err == nil {
+			jc.Session.ChannelMessageSendEmbed(dmChannel.ID, embedAlert)
+		}
+	}
+}
+
+// punish kicks or bans the joining member depending on the configured action
+func punish(jc *Context, action, reason string) {
+	var err error
+	if action == "ban" {
+		err = jc.Session.GuildBanCreateWithReason(jc.GuildID, jc.User.ID, reason, 0)
+	} else {
+		err = jc.Session.GuildMemberDeleteWithReason(jc.GuildID, jc.User.ID, reason)
+	}
+	if err != nil {
+		logger.Warn(fmt.Sprintf("No se pudo sancionar a %s en %s: %v", jc.User.ID, jc.GuildID, err), "AntiRaid")
+	}
+}
+
+// antibotsStage removes bots according to the Anti-Bots mode
+func antibotsStage(jc *Context) Result {
+	if jc.GuildDoc == nil || !jc.User.Bot || !jc.GuildDoc.Protection.Antibots.Enable {
+		return Continue
+	}
+
+	verified := jc.User.PublicFlags&discordgo.UserFlagVerifiedBot != 0
+	var reason string
+	switch jc.GuildDoc.Protection.Antibots.Type {
+	case "all":
+		reason = "Anti-Bots: Todos los bots están bloqueados"
+	case "only_nv":
+		if !verified {
+			reason = "Anti-Bots: Bot no verificado"
+		}
+	case "only_v":
+		if verified {
+			reason = "Anti-Bots: Bot verificado no permitido"
+		}
+	}
+	if reason == "" {
+		return Continue
+	}
+
+	if err := jc.Session.GuildMemberDeleteWithReason(jc.GuildID, jc.User.ID, reason); err != nil {
+		logger.Warn(fmt.Sprintf("No se pudo expulsar al bot %s: %v", jc.User.ID, err), "Member")
+	}
+	logger.Info(fmt.Sprintf("🤖 Bot %s expulsado por Anti-Bots (%s)", jc.User.Username, jc.GuildDoc.Protection.Antibots.Type), "Member")
+	return Stop(reason)
+}
@@ -0,0 +1,96 @@
+package joingate
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/invites"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/PancyStudios/PancyBotGo/pkg/verification"
+)
+
+// tracksInvites reports whether the join counts towards the invite tracker
+func tracksInvites(jc *Context) bool {
+	return jc.GuildDoc != nil && jc.GuildDoc.Invites.Enable && !jc.User.Bot
+}
+
+// invitesStage attributes the join to an invite. It runs after the security gates;
+// joins they stop are handled by invitesSyncStage.
+func invitesStage(jc *Context) Result {
+	if !tracksInvites(jc) {
+		return Continue
+	}
+
+	jc.invitesSeen = true
+	att, err := invites.Attribute(jc.Session, jc.GuildID)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("No se pudieron leer las invitaciones de %s: %v", jc.GuildID, err), "Invites")
+	}
+	jc.Inviter = invites.InviterText(att)
+	invites.RecordJoin(jc.Session, jc.GuildDoc, jc.User, att)
+	return Continue
+}
+
+// invitesSyncStage refreshes the invite snapshot when a security stage stopped the
+// join before it was attributed, so the use it consumed isn't credited to the next join.
+func invitesSyncStage(jc *Context) Result {
+	if jc.StoppedBy == "" || jc.invitesSeen || !tracksInvites(jc) {
+		return Continue
+	}
+
+	if err := invites.Load(jc.Session, jc.GuildID); err != nil {
+		logger.Warn(fmt.Sprintf("No se pudieron leer las invitaciones de %s: %v", jc.GuildID, err), "Invites")
+	}
+	return Continue
+}
+
+// verificationStage starts the verification timeout for human members
+func verificationStage(jc *Context) Result {
+	if jc.GuildDoc == nil || jc.User.Bot {
+		return Continue
+	}
+	verification.RegisterJoin(jc.GuildDoc, jc.User.ID)
+	return Continue
+}
+
+// autoroleStage gives the configured autoroles, optionally after a delay
+func autoroleStage(jc *Context) Result {
+	if jc.GuildDoc == nil {
+		return Continue
+	}
+	autorole := jc.GuildDoc.Greetings.Autorole
+	if !autorole.Enable || len(autorole.Roles) == 0 {
+		return Continue
+	}
+
+	applyRole := func() {
+		for _, roleID := range autorole.Roles {
+			err := jc.Session.GuildMemberRoleAdd(jc.GuildID, jc.User.ID, roleID)
+			if err != nil {
+				logger.Error(fmt.Sprintf("Error asignando autorol %s a %s: %v", roleID, jc.User.ID, err), "Member")
+			} else {
+				logger.Debug(fmt.Sprintf("✅ Autorol %s asignado a %s", roleID, jc.User.ID), "Member")
+			}
+		}
+	}
+
+	if autorole.Delay > 0 {
+		go func() {
+			time.Sleep(time.Duration(autorole.Delay) * time.Millisecond)
+			applyRole()
+		}()
+	} else {
+		applyRole()
+	}
+	return Continue
+}
+
+// joinLogStage records how the join ended
+func joinLogStage(jc *Context) Result {
+	if jc.StoppedBy != "" {
+		logger.Info(fmt.Sprintf("🚪 Unión de %s en %s bloqueada por %s: %s", jc.User.Username, jc.GuildID, jc.StoppedBy, jc.StopReason), "Member")
+		return Continue
+	}
+	logger.Debug(fmt.Sprintf("✅ Unión de %s en %s procesada", jc.User.Username, jc.GuildID), "Member")
+	return Continue
+}
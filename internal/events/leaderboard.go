@@ -0,0 +1,53 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/leaderboard"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/bwmarrin/discordgo"
+)
+
+// handleLeaderboardInteraction turns the pages of the economy and levels
+// leaderboards. Returns true if the interaction was handled by this module
+func handleLeaderboardInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) bool {
+	if i.Type != discordgo.InteractionMessageComponent || i.Member == nil {
+		return false
+	}
+	customID := i.MessageComponentData().CustomID
+	if !strings.HasPrefix(customID, leaderboard.ButtonPrefix) {
+		return false
+	}
+
+	board, page, ok := leaderboard.ParseButton(customID)
+	if !ok {
+		logger.Error(fmt.Sprintf("Invalid customID format: %s", customID), "Interaction")
+		return true
+	}
+
+	embed, components, err := leaderboard.Render(board, i.GuildID, i.Member.User.ID, page)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error obteniendo la clasificación %s: %v", board, err), "Interaction")
+		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ Ocurrió un error al cargar la clasificación.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return true
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+		},
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error actualizando la clasificación: %v", err), "Interaction")
+	}
+	return true
+}
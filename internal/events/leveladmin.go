@@ -0,0 +1,92 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/leveladmin"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/bwmarrin/discordgo"
+)
+
+// handleLevelAdminInteraction routes the confirmation buttons of level admin
+// actions. Returns true if the interaction was handled by this module
+func handleLevelAdminInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) bool {
+	if i.Member == nil || i.Type != discordgo.InteractionMessageComponent {
+		return false
+	}
+
+	customID := i.MessageComponentData().CustomID
+	userID := i.Member.User.ID
+	switch {
+	case strings.HasPrefix(customID, leveladmin.ConfirmButtonPrefix):
+		id := strings.TrimPrefix(customID, leveladmin.ConfirmButtonPrefix)
+		// Imports and resets can take longer than an interaction allows
+		if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseDeferredMessageUpdate,
+		}); err != nil {
+			logger.Error(fmt.Sprintf("Error respondiendo interacción de niveles: %v", err), "Levels")
+			return true
+		}
+
+		description, err := leveladmin.Confirm(s, id, userID)
+		color := discord.ColorSuccess
+		if err != nil {
+			if !errors.Is(err, leveladmin.ErrNotFound) && !errors.Is(err, leveladmin.ErrNotAdmin) {
+				logger.Error(fmt.Sprintf("Error aplicando la acción de niveles %s: %v", id, err), "Levels")
+			}
+			if errors.Is(err, leveladmin.ErrNotAdmin) {
+				// Somebody else clicked: leave the confirmation as it is
+				_, _ = s.FollowupMessageCreate(i.Interaction, false, &discordgo.WebhookParams{
+					Content: leveladmin.ErrorText(err),
+					Flags:   discordgo.MessageFlagsEphemeral,
+				})
+				return true
+			}
+			description, color = leveladmin.ErrorText(err), discord.ColorError
+		}
+		closeLevelAdmin(s, i, description, color)
+
+	case strings.HasPrefix(customID, leveladmin.CancelButtonPrefix):
+		err := leveladmin.Cancel(strings.TrimPrefix(customID, leveladmin.CancelButtonPrefix), userID)
+		if errors.Is(err, leveladmin.ErrNotAdmin) {
+			_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: leveladmin.ErrorText(err),
+					Flags:   discordgo.MessageFlagsEphemeral,
+				},
+			})
+			return true
+		}
+		if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseDeferredMessageUpdate,
+		}); err != nil {
+			logger.Error(fmt.Sprintf("Error respondiendo interacción de niveles: %v", err), "Levels")
+			return true
+		}
+		closeLevelAdmin(s, i, "✖️ Cambio cancelado.", discord.ColorWarning)
+
+	default:
+		return false
+	}
+	return true
+}
+
+// closeLevelAdmin replaces a confirmation with its result and removes the buttons
+func closeLevelAdmin(s *discordgo.Session, i *discordgo.InteractionCreate, description string, color int) {
+	embeds := []*discordgo.MessageEmbed{discord.NewEmbed().
+		SetTitle("🛠️ Administración de Niveles").
+		SetDescription(description).
+		SetColor(color).
+		Build()}
+	components := []discordgo.MessageComponent{}
+	if _, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds:     &embeds,
+		Components: &components,
+	}); err != nil {
+		logger.Error(fmt.Sprintf("Error actualizando la confirmación de niveles: %v", err), "Levels")
+	}
+}
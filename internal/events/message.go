@@ -9,7 +9,12 @@ import (
 	"github.com/PancyStudios/PancyBotGo/internal/messagecommands"
 	"github.com/PancyStudios/PancyBotGo/pkg/database"
 	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/items"
+	"github.com/PancyStudios/PancyBotGo/pkg/leveling"
 	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/PancyStudios/PancyBotGo/pkg/seasons"
+	"github.com/PancyStudios/PancyBotGo/pkg/templates"
 	"github.com/bwmarrin/discordgo"
 	"go.mongodb.org/mongo-driver/bson"
 )
@@ -215,6 +220,15 @@ func handleUserLeveling(s *discordgo.Session, m *discordgo.MessageCreate) {
 		return
 	}
 
+	rules := leveling.Resolve(guildData.Levels)
+	var roles []string
+	if m.Member != nil {
+		roles = m.Member.Roles
+	}
+	if rules.Excluded(m.ChannelID, roles) {
+		return
+	}
+
 	profile, err := database.GetLocalLevelProfile(m.GuildID, m.Author.ID)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Error obteniendo perfil de nivel para %s: %v", m.Author.ID, err), "Levels")
@@ -224,20 +238,20 @@ func handleUserLeveling(s *discordgo.Session, m *discordgo.MessageCreate) {
 	now := time.Now()
 
 	// Comprobar si está en enfriamiento
-	if now.Before(profile.CooldownUntil) {
+	if now.Before(profile.CooldownUntil) || now.Sub(profile.LastMessageTime) < rules.Cooldown {
 		return
 	}
 
-	// Comprobar ventana de spam (4 mensajes en 3 segundos)
-	if now.Sub(profile.SpamWindowStart) > 3*time.Second {
+	// Comprobar ventana de spam
+	if now.Sub(profile.SpamWindowStart) > rules.SpamWindow {
 		// Resetear la ventana
 		profile.SpamWindowStart = now
 		profile.SpamCount = 1
 	} else {
 		profile.SpamCount++
-		if profile.SpamCount >= 4 {
-			// Activar cooldown de 5 segundos
-			profile.CooldownUntil = now.Add(5 * time.Second)
+		if profile.SpamCount >= rules.SpamMessages {
+			// Activar cooldown
+			profile.CooldownUntil = now.Add(rules.SpamCooldown)
 			profile.SpamCount = 0 // Resetear cuenta para después del cooldown
 
 			// Guardar el perfil para que el cooldown haga efecto, y no dar XP
@@ -249,21 +263,17 @@ func handleUserLeveling(s *discordgo.Session, m *discordgo.MessageCreate) {
 		}
 	}
 
-	// Añadir XP aleatorio (1 a 15)
-	addedXP := int64(1 + (now.UnixNano() % 15)) // simple pseudo-random
+	// Añadir XP aleatorio con los multiplicadores de roles, canal, premium y objetos
+	addedXP := int64(float64(rules.Roll()) * levelMultiplier(rules, m.GuildID, m.Author.ID, m.ChannelID, roles))
 	profile.XP += addedXP
+	seasons.AddXP(profile, guildData.Seasons, addedXP)
 	profile.TotalMessages += 1
 	profile.LastMessageTime = now
 
-	// Verificar si subió de nivel
-	nextLevel := profile.Level + 1
-	requiredXP := nextLevel * nextLevel * 100
-
-	levelUp := false
-	if profile.XP >= requiredXP {
-		profile.Level = nextLevel
-		levelUp = true
-	}
+	// Verificar si subió de nivel, aunque sea varios niveles de golpe
+	previousLevel := profile.Level
+	profile.Level = rules.LevelFor(profile.XP)
+	levelUp := profile.Level > previousLevel
 
 	_, err = database.LocalLevelsDM.Set(bson.M{"_id": profile.ID}, profile)
 	if err != nil {
@@ -273,35 +283,45 @@ func handleUserLeveling(s *discordgo.Session, m *discordgo.MessageCreate) {
 
 	// Enviar mensaje de Level Up si es necesario
 	if levelUp {
-		// Asignar roles de recompensa
-		for _, reward := range guildData.Levels.Rewards {
-			if reward.Level == profile.Level {
-				err := s.GuildMemberRoleAdd(m.GuildID, m.Author.ID, reward.RoleID)
-				if err != nil {
-					logger.Error(fmt.Sprintf("No se pudo asignar rol de nivel %d a %s: %v", profile.Level, m.Author.ID, err), "Levels")
-				} else {
-					logger.Info(fmt.Sprintf("Rol %s asignado a %s por alcanzar el nivel %d", reward.RoleID, m.Author.ID, profile.Level), "Levels")
-				}
-			}
-		}
+		announceLevelUp(s, guildData, rules, m.Author, roles, m.ChannelID, profile)
+	}
+}
 
-		chID := m.ChannelID
-		if guildData.Levels.LevelUpChannel != "" {
-			chID = guildData.Levels.LevelUpChannel
-		}
+// levelMultiplier returns the XP multiplier of a member in a channel: roles,
+// channel and premium from the server rules, and XP boost items
+func levelMultiplier(rules leveling.Rules, guildID, userID, channelID string, roles []string) float64 {
+	premium := false
+	if rules.PremiumBoost != 1 {
+		userPremium, _, _ := database.IsUserPremium(userID)
+		guildPremium, _, _ := database.IsGuildPremium(guildID)
+		premium = userPremium || guildPremium
+	}
+	multiplier := rules.Multiplier(channelID, roles, premium)
+	return multiplier * items.BestMultiplier(guildID, userID, items.EffectXPBoost)
+}
 
-		msgContent := guildData.Levels.LevelUpMessage
-		if msgContent == "" {
-			msgContent = "¡Felicidades {user}, has avanzado al **Nivel {level}**! 🎉"
-		}
+// announceLevelUp gives the reward roles of the new level and sends the level up
+// message to the configured channel, or to channelID when there is none
+func announceLevelUp(s *discordgo.Session, guildData *models.GuildDocument, rules leveling.Rules, user *discordgo.User, roles []string, channelID string, profile *models.UserLevelProfile) {
+	// Asignar roles de recompensa
+	add, remove := rules.RewardChanges(guildData.Levels.Rewards, profile.Level)
+	leveling.ApplyRewards(s, profile.GuildID, user.ID, roles, add, remove)
+
+	if guildData.Levels.LevelUpChannel != "" {
+		channelID = guildData.Levels.LevelUpChannel
+	}
 
-		msgContent = strings.ReplaceAll(msgContent, "{user}", fmt.Sprintf("<@%s>", m.Author.ID))
-		msgContent = strings.ReplaceAll(msgContent, "{level}", fmt.Sprintf("%d", profile.Level))
+	msgContent := guildData.Levels.LevelUpMessage
+	if msgContent == "" {
+		msgContent = "¡Felicidades {user}, has avanzado al **Nivel {level}**! 🎉"
+	}
 
-		_, err = s.ChannelMessageSend(chID, msgContent)
-		if err != nil {
-			logger.Error(fmt.Sprintf("No se pudo enviar mensaje de level up a %s: %v", chID, err), "Levels")
-		}
+	guild, _ := stateGuild(s, profile.GuildID)
+	vars := templates.NewVars(user, guild).WithLevel(profile.Level, profile.XP)
+	msgContent = templates.Render(msgContent, vars)
+
+	if _, err := s.ChannelMessageSend(channelID, msgContent); err != nil {
+		logger.Error(fmt.Sprintf("No se pudo enviar mensaje de level up a %s: %v", channelID, err), "Levels")
 	}
 }
 
@@ -0,0 +1,101 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/PancyStudios/PancyBotGo/pkg/privacy"
+	"github.com/bwmarrin/discordgo"
+)
+
+// handlePrivacyInteraction routes the confirmation buttons of data deletions,
+// which may be clicked in a DM. Returns true if the interaction was handled by
+// this module
+func handlePrivacyInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) bool {
+	if i.Type != discordgo.InteractionMessageComponent {
+		return false
+	}
+	user := i.User
+	if i.Member != nil {
+		user = i.Member.User
+	}
+	if user == nil {
+		return false
+	}
+
+	customID := i.MessageComponentData().CustomID
+	switch {
+	case strings.HasPrefix(customID, privacy.ConfirmButtonPrefix):
+		id := strings.TrimPrefix(customID, privacy.ConfirmButtonPrefix)
+		// Deleting a whole server can take longer than an interaction allows
+		if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseDeferredMessageUpdate,
+		}); err != nil {
+			logger.Error(fmt.Sprintf("Error respondiendo interacción de privacidad: %v", err), "Privacy")
+			return true
+		}
+
+		description, err := privacy.Confirm(id, user.ID)
+		color := discord.ColorSuccess
+		if err != nil {
+			if errors.Is(err, privacy.ErrNotRequester) {
+				// Somebody else clicked: leave the confirmation as it is
+				_, _ = s.FollowupMessageCreate(i.Interaction, false, &discordgo.WebhookParams{
+					Content: privacy.ErrorText(err),
+					Flags:   discordgo.MessageFlagsEphemeral,
+				})
+				return true
+			}
+			if !errors.Is(err, privacy.ErrNotFound) {
+				logger.Error(fmt.Sprintf("Error borrando datos (%s): %v", id, err), "Privacy")
+			}
+			description, color = privacy.ErrorText(err), discord.ColorError
+		} else {
+			logger.Info(fmt.Sprintf("Datos borrados a petición de %s (%s)", user.ID, id), "Privacy")
+		}
+		closePrivacy(s, i, description, color)
+
+	case strings.HasPrefix(customID, privacy.CancelButtonPrefix):
+		err := privacy.Cancel(strings.TrimPrefix(customID, privacy.CancelButtonPrefix), user.ID)
+		if errors.Is(err, privacy.ErrNotRequester) {
+			_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: privacy.ErrorText(err),
+					Flags:   discordgo.MessageFlagsEphemeral,
+				},
+			})
+			return true
+		}
+		if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseDeferredMessageUpdate,
+		}); err != nil {
+			logger.Error(fmt.Sprintf("Error respondiendo interacción de privacidad: %v", err), "Privacy")
+			return true
+		}
+		closePrivacy(s, i, "✖️ Borrado cancelado.", discord.ColorWarning)
+
+	default:
+		return false
+	}
+	return true
+}
+
+// closePrivacy replaces a confirmation with its result and removes the buttons
+func closePrivacy(s *discordgo.Session, i *discordgo.InteractionCreate, description string, color int) {
+	embeds := []*discordgo.MessageEmbed{discord.NewEmbed().
+		SetTitle("🔒 Privacidad").
+		SetDescription(description).
+		SetColor(color).
+		Build()}
+	components := []discordgo.MessageComponent{}
+	if _, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds:     &embeds,
+		Components: &components,
+	}); err != nil {
+		logger.Error(fmt.Sprintf("Error actualizando la confirmación de privacidad: %v", err), "Privacy")
+	}
+}
@@ -21,6 +21,9 @@ func RegisterAll(client *discord.ExtendedClient) {
 	// Member events (join/leave/update)
 	RegisterMemberEvents(client)
 
+	// Invite events (invite tracker cache)
+	RegisterInviteEvents(client)
+
 	// Message events (create/update/delete)
 	RegisterMessageEvents(client)
 
@@ -0,0 +1,225 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/PancyStudios/PancyBotGo/pkg/trade"
+	"github.com/bwmarrin/discordgo"
+)
+
+// handleTradeInteraction routes the buttons and the offer modal of trade windows.
+// Returns true if the interaction was handled by this module
+func handleTradeInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) bool {
+	if i.Member == nil {
+		return false
+	}
+
+	switch i.Type {
+	case discordgo.InteractionMessageComponent:
+		customID := i.MessageComponentData().CustomID
+		switch {
+		case strings.HasPrefix(customID, trade.OfferButtonPrefix):
+			handleTradeOfferButton(s, i, strings.TrimPrefix(customID, trade.OfferButtonPrefix))
+			return true
+		case strings.HasPrefix(customID, trade.ConfirmButtonPrefix):
+			handleTradeConfirm(s, i, strings.TrimPrefix(customID, trade.ConfirmButtonPrefix))
+			return true
+		case strings.HasPrefix(customID, trade.CancelButtonPrefix):
+			handleTradeCancel(s, i, strings.TrimPrefix(customID, trade.CancelButtonPrefix))
+			return true
+		}
+	case discordgo.InteractionModalSubmit:
+		customID := i.ModalSubmitData().CustomID
+		if strings.HasPrefix(customID, trade.OfferModalPrefix) {
+			handleTradeOfferModal(s, i, strings.TrimPrefix(customID, trade.OfferModalPrefix))
+			return true
+		}
+	}
+	return false
+}
+
+func respondTradeEphemeral(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error respondiendo interacción de intercambio: %v", err), "Trade")
+	}
+}
+
+// tradeErrorText explains a trade error to the user
+func tradeErrorText(err error) string {
+	switch {
+	case errors.Is(err, trade.ErrNotFound):
+		return "⌛ Este intercambio ya no está activo."
+	case errors.Is(err, trade.ErrNotParticipant):
+		return "❌ No formas parte de este intercambio."
+	case errors.Is(err, trade.ErrEmptyTrade):
+		return "❌ Ninguno de los dos ha ofrecido nada todavía."
+	case errors.Is(err, trade.ErrUnknownItem):
+		return "❌ Uno de los objetos no existe en esta tienda. Revisa los IDs en la tienda."
+	case errors.Is(err, trade.ErrInvalidOffer):
+		return "❌ La oferta no es válida. Usa `id x cantidad` separados por comas y números positivos."
+	default:
+		return "❌ Ocurrió un error con el intercambio."
+	}
+}
+
+// updateTradeWindow re-renders the window the interaction came from
+func updateTradeWindow(s *discordgo.Session, i *discordgo.InteractionCreate, t trade.Trade) {
+	catalog, err := trade.Catalog(t.GuildID)
+	if err != nil {
+		respondTradeEphemeral(s, i, "❌ No se pudo cargar el catálogo de objetos.")
+		return
+	}
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{trade.Embed(t, catalog)},
+			Components: trade.Components(t.ID, false),
+		},
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error actualizando la ventana del intercambio %s: %v", t.ID, err), "Trade")
+	}
+}
+
+func handleTradeOfferButton(s *discordgo.Session, i *discordgo.InteractionCreate, tradeID string) {
+	t, err := trade.Get(tradeID)
+	if err != nil {
+		respondTradeEphemeral(s, i, tradeErrorText(err))
+		return
+	}
+
+	side := -1
+	for n, u := range t.Users {
+		if u == i.Member.User.ID {
+			side = n
+		}
+	}
+	if side < 0 {
+		respondTradeEphemeral(s, i, tradeErrorText(trade.ErrNotParticipant))
+		return
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: trade.OfferModal(tradeID, t.Offers[side]),
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error abriendo el formulario del intercambio %s: %v", tradeID, err), "Trade")
+	}
+}
+
+func handleTradeOfferModal(s *discordgo.Session, i *discordgo.InteractionCreate, tradeID string) {
+	t, err := trade.Get(tradeID)
+	if err != nil {
+		respondTradeEphemeral(s, i, tradeErrorText(err))
+		return
+	}
+	catalog, err := trade.Catalog(t.GuildID)
+	if err != nil {
+		respondTradeEphemeral(s, i, "❌ No se pudo cargar el catálogo de objetos.")
+		return
+	}
+
+	values := make(map[string]string)
+	for _, component := range i.ModalSubmitData().Components {
+		if row, ok := component.(*discordgo.ActionsRow); ok {
+			for _, c := range row.Components {
+				if input, ok := c.(*discordgo.TextInput); ok {
+					values[input.CustomID] = strings.TrimSpace(input.Value)
+				}
+			}
+		}
+	}
+
+	offer := trade.Offer{}
+	if offer.Items, err = trade.ParseItems(values[trade.ItemsInputID], catalog); err != nil {
+		respondTradeEphemeral(s, i, tradeErrorText(err))
+		return
+	}
+	for id, target := range map[string]*int64{trade.CoinsInputID: &offer.Coins, trade.StarsInputID: &offer.Stars} {
+		if values[id] == "" {
+			continue
+		}
+		if *target, err = strconv.ParseInt(values[id], 10, 64); err != nil || *target < 0 {
+			respondTradeEphemeral(s, i, tradeErrorText(trade.ErrInvalidOffer))
+			return
+		}
+	}
+	if len(offer.Items) == 0 {
+		offer.Items = nil
+	}
+
+	t, err = trade.SetOffer(tradeID, i.Member.User.ID, offer)
+	if err != nil {
+		respondTradeEphemeral(s, i, tradeErrorText(err))
+		return
+	}
+	updateTradeWindow(s, i, t)
+}
+
+func handleTradeConfirm(s *discordgo.Session, i *discordgo.InteractionCreate, tradeID string) {
+	t, ready, err := trade.Confirm(tradeID, i.Member.User.ID)
+	if err != nil {
+		respondTradeEphemeral(s, i, tradeErrorText(err))
+		return
+	}
+	if !ready {
+		updateTradeWindow(s, i, t)
+		return
+	}
+
+	// Executing touches several profiles, acknowledge the click first
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseDeferredMessageUpdate})
+
+	catalog, err := trade.Catalog(t.GuildID)
+	if err == nil {
+		err = trade.Execute(t, catalog)
+	}
+
+	description := fmt.Sprintf("✅ ¡Intercambio completado!\n\n**%s** entregó:\n%s\n\n**%s** entregó:\n%s",
+		t.Names[0], trade.DescribeOffer(t.Offers[0], catalog), t.Names[1], trade.DescribeOffer(t.Offers[1], catalog))
+	color := discord.ColorSuccess
+	if err != nil {
+		color = discord.ColorError
+		switch {
+		case errors.Is(err, database.ErrInsufficientFunds):
+			description = "❌ El intercambio se canceló: uno de los dos ya no tiene el dinero ofrecido."
+		case errors.Is(err, database.ErrInsufficientItems):
+			description = "❌ El intercambio se canceló: uno de los dos ya no tiene los objetos ofrecidos."
+		default:
+			description = "❌ El intercambio se canceló por un error. No se movió nada."
+			logger.Error(fmt.Sprintf("Error ejecutando el intercambio %s: %v", t.ID, err), "Trade")
+		}
+	}
+
+	// The window message is the one the button belongs to
+	t.MessageID = i.Message.ID
+	t.ChannelID = i.ChannelID
+	trade.Close(s, t, description, color)
+}
+
+func handleTradeCancel(s *discordgo.Session, i *discordgo.InteractionCreate, tradeID string) {
+	t, err := trade.Cancel(tradeID, i.Member.User.ID)
+	if err != nil {
+		respondTradeEphemeral(s, i, tradeErrorText(err))
+		return
+	}
+
+	t.MessageID = i.Message.ID
+	t.ChannelID = i.ChannelID
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseDeferredMessageUpdate})
+	trade.Close(s, t, fmt.Sprintf("✖️ <@%s> canceló el intercambio.", i.Member.User.ID), discord.ColorError)
+}
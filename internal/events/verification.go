@@ -0,0 +1,243 @@
+package events
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/PancyStudios/PancyBotGo/pkg/verification"
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Custom IDs used by the verification components and modals
+const (
+	verifyButtonID        = verification.ButtonCustomID
+	verifyCaptchaAnswerID = "verify_captcha_answer"
+	verifyCaptchaModalID  = "verify_modal_captcha"
+	verifyMathModalID     = "verify_modal_math"
+	verifyAnswerInputID   = "verify_answer"
+)
+
+// handleVerificationInteraction routes verification buttons and modals.
+// Returns true if the interaction was handled by this module
+func handleVerificationInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) bool {
+	switch i.Type {
+	case discordgo.InteractionMessageComponent:
+		switch i.MessageComponentData().CustomID {
+		case verifyButtonID:
+			handleVerifyUser(s, i)
+			return true
+		case verifyCaptchaAnswerID:
+			handleCaptchaAnswerButton(s, i)
+			return true
+		}
+	case discordgo.InteractionModalSubmit:
+		switch i.ModalSubmitData().CustomID {
+		case verifyCaptchaModalID, verifyMathModalID:
+			handleVerificationModal(s, i)
+			return true
+		}
+	}
+	return false
+}
+
+// respondVerifyEphemeral sends an ephemeral text reply for the verification flow
+func respondVerifyEphemeral(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error respondiendo interacción de verificación: %v", err), "Verification")
+	}
+}
+
+// loadVerificationConfig fetches the guild document and checks that verification is usable
+func loadVerificationConfig(s *discordgo.Session, i *discordgo.InteractionCreate) *models.GuildDocument {
+	if i.Member == nil {
+		return nil
+	}
+	guildDoc, err := database.GlobalGuildDM.Get(bson.M{"id": i.GuildID})
+	if err != nil || guildDoc == nil || !guildDoc.Protection.Verification.Enable || guildDoc.Protection.Verification.Role == "" {
+		respondVerifyEphemeral(s, i, "❌ El sistema de verificación no está activo o el rol no está configurado en este servidor.")
+		return nil
+	}
+	return guildDoc
+}
+
+func handleVerifyUser(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	guildDoc := loadVerificationConfig(s, i)
+	if guildDoc == nil {
+		return
+	}
+	cfg := guildDoc.Protection.Verification
+	userID := i.Member.User.ID
+
+	for _, r := range i.Member.Roles {
+		if r == cfg.Role {
+			respondVerifyEphemeral(s, i, "✅ Ya estás verificado.")
+			return
+		}
+	}
+
+	if !verification.MeetsMinimumAge(cfg, userID) {
+		verification.LogOutcome(s, guildDoc, userID, verification.OutcomeTooYoung,
+			fmt.Sprintf("Antigüedad de la cuenta: %d días (mínimo %d)", int(verification.AccountAge(userID).Hours()/24), cfg.MinAccountAgeDays))
+		respondVerifyEphemeral(s, i, fmt.Sprintf("❌ Tu cuenta es demasiado reciente. Debe tener al menos **%d días** de antigüedad para verificarte.", cfg.MinAccountAgeDays))
+		return
+	}
+
+	switch cfg.Type {
+	case verification.TypeCaptcha:
+		sendCaptchaChallenge(s, i)
+	case verification.TypeMath:
+		question := verification.NewMathChallenge(i.GuildID, userID)
+		openVerificationModal(s, i, verifyMathModalID, "🧮 Verificación", question, "Escribe solo el número")
+	case verification.TypeWeb:
+		err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "🌐 Este servidor usa verificación web. Completa el proceso en el siguiente enlace:",
+				Flags:   discordgo.MessageFlagsEphemeral,
+				Components: []discordgo.MessageComponent{
+					discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+						discordgo.Button{
+							Label: "🌐 Verificar en la Web",
+							Style: discordgo.LinkButton,
+							URL:   fmt.Sprintf("https://pancybot.miau.media/verify/%s", guildDoc.ID),
+						},
+					}},
+				},
+			},
+		})
+		if err != nil {
+			logger.Error(fmt.Sprintf("Error respondiendo interacción: %v", err), "Verification")
+		}
+	default:
+		grantVerification(s, i, guildDoc, verification.TypeButton)
+	}
+}
+
+// sendCaptchaChallenge replies with a fresh captcha image and the button to answer it
+func sendCaptchaChallenge(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	png, err := verification.NewCaptchaChallenge(i.GuildID, i.Member.User.ID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error generando captcha: %v", err), "Verification")
+		respondVerifyEphemeral(s, i, "❌ No se pudo generar el captcha. Inténtalo de nuevo.")
+		return
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+			Embeds: []*discordgo.MessageEmbed{{
+				Title:       "🖼️ Captcha de verificación",
+				Description: "Escribe el código que aparece en la imagen pulsando **Responder**.\nEl código tiene 6 caracteres y no distingue mayúsculas.",
+				Color:       0x2ecc71,
+				Image:       &discordgo.MessageEmbedImage{URL: "attachment://captcha.png"},
+			}},
+			Files: []*discordgo.File{{
+				Name:        "captcha.png",
+				ContentType: "image/png",
+				Reader:      bytes.NewReader(png),
+			}},
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+					discordgo.Button{Label: "✍️ Responder", Style: discordgo.PrimaryButton, CustomID: verifyCaptchaAnswerID},
+					discordgo.Button{Label: "🔄 Otro captcha", Style: discordgo.SecondaryButton, CustomID: verifyButtonID},
+				}},
+			},
+		},
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error enviando captcha: %v", err), "Verification")
+	}
+}
+
+func handleCaptchaAnswerButton(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i.Member == nil {
+		return
+	}
+	openVerificationModal(s, i, verifyCaptchaModalID, "🖼️ Captcha", "Código del captcha", "Ejemplo: A3KX9P")
+}
+
+// openVerificationModal shows a modal with a single answer field
+func openVerificationModal(s *discordgo.Session, i *discordgo.InteractionCreate, modalID, title, label, placeholder string) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: modalID,
+			Title:    title,
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+					discordgo.TextInput{
+						CustomID:    verifyAnswerInputID,
+						Label:       label,
+						Style:       discordgo.TextInputShort,
+						Placeholder: placeholder,
+						Required:    true,
+						MaxLength:   16,
+					},
+				}},
+			},
+		},
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error abriendo modal de verificación: %v", err), "Verification")
+	}
+}
+
+func handleVerificationModal(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	guildDoc := loadVerificationConfig(s, i)
+	if guildDoc == nil {
+		return
+	}
+	userID := i.Member.User.ID
+	maxAttempts := verification.MaxAttempts(guildDoc.Protection.Verification)
+
+	answer := ""
+	for _, component := range i.ModalSubmitData().Components {
+		if row, ok := component.(*discordgo.ActionsRow); ok {
+			for _, c := range row.Components {
+				if input, ok := c.(*discordgo.TextInput); ok && input.CustomID == verifyAnswerInputID {
+					answer = input.Value
+				}
+			}
+		}
+	}
+
+	result, remaining := verification.CheckAnswer(i.GuildID, userID, answer, maxAttempts)
+	switch result {
+	case verification.ResultPassed:
+		method := verification.TypeCaptcha
+		if i.ModalSubmitData().CustomID == verifyMathModalID {
+			method = verification.TypeMath
+		}
+		grantVerification(s, i, guildDoc, method)
+	case verification.ResultWrong:
+		verification.LogOutcome(s, guildDoc, userID, verification.OutcomeFailed, fmt.Sprintf("Intentos restantes: %d", remaining))
+		respondVerifyEphemeral(s, i, fmt.Sprintf("❌ Respuesta incorrecta. Te quedan **%d** intento(s). Pulsa de nuevo el botón de verificación para obtener otro reto.", remaining))
+	case verification.ResultExhausted:
+		respondVerifyEphemeral(s, i, "⛔ Has agotado todos tus intentos de verificación.")
+		verification.Kick(s, guildDoc, userID, verification.OutcomeExhausted, fmt.Sprintf("Falló la verificación %d veces", maxAttempts))
+	default:
+		respondVerifyEphemeral(s, i, "⌛ Tu reto de verificación ha expirado. Pulsa de nuevo el botón de verificación.")
+	}
+}
+
+// grantVerification gives the role and answers the interaction
+func grantVerification(s *discordgo.Session, i *discordgo.InteractionCreate, guildDoc *models.GuildDocument, method string) {
+	if err := verification.Grant(s, guildDoc, i.Member.User.ID, method); err != nil {
+		logger.Error(fmt.Sprintf("Error añadiendo rol de verificación: %v", err), "Verification")
+		respondVerifyEphemeral(s, i, "❌ No pude añadirte el rol. Es posible que me falten permisos o el rol esté por encima del mío.")
+		return
+	}
+	respondVerifyEphemeral(s, i, "🎉 ¡Te has verificado exitosamente!")
+}
@@ -12,6 +12,8 @@ import (
 // RegisterVoiceEvents registers all voice-related event handlers
 func RegisterVoiceEvents(client *discord.ExtendedClient) {
 	client.Session.AddHandler(onVoiceStateUpdate)
+	client.Session.AddHandler(onVoiceActivity)
+	startVoiceXP(client.Session)
 }
 
 // onVoiceStateUpdate is called when a user's voice state changes
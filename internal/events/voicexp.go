@@ -0,0 +1,155 @@
+package events
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/leveling"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/PancyStudios/PancyBotGo/pkg/seasons"
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// voiceTracker holds the voice sessions that are earning XP
+var voiceTracker = leveling.NewVoiceTracker()
+
+// onVoiceActivity starts and ends the voice sessions of the guild where a voice
+// state changed. Everybody in the old and new channel is checked again, since
+// somebody joining or leaving changes who is talking alone.
+func onVoiceActivity(s *discordgo.Session, v *discordgo.VoiceStateUpdate) {
+	if v.GuildID == "" {
+		return
+	}
+	creditVoiceTime(s, voiceTracker.Sync(v.GuildID, earningVoice(s, v.GuildID), time.Now()))
+}
+
+// startVoiceXP credits open voice sessions every minute. It also catches sessions
+// missed while the bot was disconnected.
+func startVoiceXP(s *discordgo.Session) {
+	go func() {
+		for {
+			time.Sleep(1 * time.Minute)
+
+			db := database.Get()
+			if db == nil || !db.Connected() || s.State == nil {
+				continue
+			}
+
+			now := time.Now()
+			s.State.RLock()
+			guildIDs := make([]string, 0, len(s.State.Guilds))
+			for _, guild := range s.State.Guilds {
+				guildIDs = append(guildIDs, guild.ID)
+			}
+			s.State.RUnlock()
+
+			for _, guildID := range guildIDs {
+				creditVoiceTime(s, voiceTracker.Sync(guildID, earningVoice(s, guildID), now))
+			}
+			creditVoiceTime(s, voiceTracker.Flush(now))
+		}
+	}()
+}
+
+// earningVoice returns the members of a guild that earn voice XP right now
+// (UserID -> ChannelID): not in the AFK channel, not deafened, not a bot and with
+// at least another person in the channel
+func earningVoice(s *discordgo.Session, guildID string) map[string]string {
+	if s.State == nil {
+		return nil
+	}
+	guild, err := s.State.Guild(guildID)
+	if err != nil {
+		return nil
+	}
+
+	s.State.RLock()
+	afkChannelID := guild.AfkChannelID
+	states := make([]discordgo.VoiceState, 0, len(guild.VoiceStates))
+	for _, vs := range guild.VoiceStates {
+		states = append(states, *vs)
+	}
+	s.State.RUnlock()
+
+	humans := make(map[string]int)
+	var candidates []discordgo.VoiceState
+	for _, vs := range states {
+		if vs.ChannelID == "" || vs.ChannelID == afkChannelID || isBotMember(s, guildID, vs) {
+			continue
+		}
+		humans[vs.ChannelID]++
+		if !vs.Deaf && !vs.SelfDeaf {
+			candidates = append(candidates, vs)
+		}
+	}
+
+	earning := make(map[string]string, len(candidates))
+	for _, vs := range candidates {
+		if humans[vs.ChannelID] > 1 {
+			earning[vs.UserID] = vs.ChannelID
+		}
+	}
+	return earning
+}
+
+func isBotMember(s *discordgo.Session, guildID string, vs discordgo.VoiceState) bool {
+	if vs.Member != nil && vs.Member.User != nil {
+		return vs.Member.User.Bot
+	}
+	if member, err := s.State.Member(guildID, vs.UserID); err == nil && member.User != nil {
+		return member.User.Bot
+	}
+	return false
+}
+
+// creditVoiceTime adds voice time to the level profiles and gives the XP of every
+// whole minute completed
+func creditVoiceTime(s *discordgo.Session, times []leveling.VoiceTime) {
+	for _, vt := range times {
+		seconds := int64(vt.Duration / time.Second)
+		if seconds <= 0 {
+			continue
+		}
+
+		guildData, err := database.GlobalGuildDM.Get(bson.M{"id": vt.GuildID})
+		if err != nil || guildData == nil || !guildData.Levels.Enable {
+			continue
+		}
+		rules := leveling.Resolve(guildData.Levels)
+
+		profile, err := database.GetLocalLevelProfile(vt.GuildID, vt.UserID)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Error obteniendo perfil de nivel para %s: %v", vt.UserID, err), "Levels")
+			continue
+		}
+
+		var roles []string
+		var user *discordgo.User
+		if member, err := s.State.Member(vt.GuildID, vt.UserID); err == nil {
+			roles, user = member.Roles, member.User
+		}
+
+		minutes := leveling.VoiceMinutes(profile.VoiceSeconds, seconds)
+		profile.VoiceSeconds += seconds
+		if rules.VoiceXP > 0 && minutes > 0 && !rules.Excluded(vt.ChannelID, roles) {
+			gained := int64(float64(minutes*rules.VoiceXP) * levelMultiplier(rules, vt.GuildID, vt.UserID, vt.ChannelID, roles))
+			profile.XP += gained
+			seasons.AddXP(profile, guildData.Seasons, gained)
+		}
+
+		previousLevel := profile.Level
+		profile.Level = rules.LevelFor(profile.XP)
+
+		if _, err := database.LocalLevelsDM.Set(bson.M{"_id": profile.ID}, profile); err != nil {
+			logger.Error(fmt.Sprintf("Error guardando el tiempo en voz de %s: %v", vt.UserID, err), "Levels")
+			continue
+		}
+
+		if profile.Level > previousLevel && user != nil {
+			// Voice channels have their own text chat
+			announceLevelUp(s, guildData, rules, user, roles, vt.ChannelID, profile)
+		}
+	}
+}
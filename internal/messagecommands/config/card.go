@@ -0,0 +1,116 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PancyStudios/PancyBotGo/internal/messagecommands"
+	"github.com/PancyStudios/PancyBotGo/pkg/cards"
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/imaging"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const cardUsage = "Uso: `pan!card <welcome|farewell> <on/off>`, `pan!card background <nombre>`, `pan!card font <nombre>`, `pan!card accent <#hex>` o `pan!card custom <url|none>`"
+
+func cardCommand(ctx *messagecommands.MessageContext) error {
+	if !ctx.HasPermission(discordgo.PermissionAdministrator) {
+		_, err := ctx.ReplyError("Acceso Denegado", "No tienes permiso de Administrador para configurar las tarjetas.")
+		return err
+	}
+
+	if len(ctx.Args) < 2 {
+		_, err := ctx.ReplyError("Uso Incorrecto", cardUsage)
+		return err
+	}
+
+	guildDoc, err := database.GlobalGuildDM.Get(bson.M{"id": ctx.Message.GuildID})
+	if err != nil {
+		_, err = ctx.ReplyError("Error", fmt.Sprintf("❌ Error obteniendo configuración: %v", err))
+		return err
+	}
+	if guildDoc == nil {
+		guildDoc = models.NewDefaultGuildDocument(ctx.Message.GuildID)
+	}
+
+	card := &guildDoc.Greetings.Card
+	setting := strings.ToLower(ctx.Args[0])
+	value := ctx.Args[1]
+	var msg string
+
+	switch setting {
+	case "welcome", "farewell":
+		enable := strings.ToLower(value) == "on" || strings.ToLower(value) == "enable" || strings.ToLower(value) == "true"
+		if setting == "welcome" {
+			guildDoc.Greetings.Welcome.UseCard = enable
+		} else {
+			guildDoc.Greetings.Farewell.UseCard = enable
+		}
+		status := "desactivada"
+		if enable {
+			status = "activada"
+		}
+		msg = fmt.Sprintf("✅ Tarjeta de %s **%s**.", setting, status)
+
+	case "background":
+		name := strings.ToLower(value)
+		if !imaging.IsValidBackground(name) {
+			_, err = ctx.ReplyError("Fondo Inválido", fmt.Sprintf("Fondos disponibles: `%s`", strings.Join(imaging.Backgrounds(), "`, `")))
+			return err
+		}
+		card.Background = name
+		msg = fmt.Sprintf("✅ Fondo establecido en **%s**.", name)
+
+	case "font":
+		name := strings.ToLower(value)
+		if !imaging.IsValidFont(name) {
+			_, err = ctx.ReplyError("Fuente Inválida", fmt.Sprintf("Fuentes disponibles: `%s`", strings.Join(imaging.Fonts(), "`, `")))
+			return err
+		}
+		card.Font = name
+		msg = fmt.Sprintf("✅ Fuente establecida en **%s**.", name)
+
+	case "accent":
+		accent, err := imaging.ParseHexColor(value)
+		if err != nil {
+			_, err = ctx.ReplyError("Color Inválido", "Usa un valor hexadecimal como `#5865F2`.")
+			return err
+		}
+		card.AccentColor = accent
+		msg = fmt.Sprintf("✅ Color de acento establecido en **#%06X**.", accent)
+
+	case "custom":
+		if strings.EqualFold(value, "none") {
+			card.CustomBackground = ""
+			msg = "✅ Fondo personalizado eliminado."
+			break
+		}
+		if !strings.HasPrefix(value, "https://") {
+			_, err = ctx.ReplyError("URL Inválida", "La URL del fondo debe empezar por `https://`.")
+			return err
+		}
+		premium, _, err := database.IsGuildPremium(ctx.Message.GuildID)
+		if err != nil || !premium {
+			_, err = ctx.ReplyError("Premium Requerido", "💎 Los fondos personalizados son exclusivos de servidores **Premium**.")
+			return err
+		}
+		card.CustomBackground = value
+		msg = "✅ Fondo personalizado establecido."
+
+	default:
+		_, err = ctx.ReplyError("Uso Incorrecto", cardUsage)
+		return err
+	}
+
+	_, err = database.GlobalGuildDM.Set(bson.M{"id": ctx.Message.GuildID}, guildDoc)
+	if err != nil {
+		_, err = ctx.ReplyError("Error", fmt.Sprintf("❌ Error guardando configuración: %v", err))
+		return err
+	}
+	cards.ForgetBackground(ctx.Message.GuildID)
+
+	_, err = ctx.ReplySuccess("Tarjetas", msg)
+	return err
+}
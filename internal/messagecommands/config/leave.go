@@ -7,6 +7,7 @@ import (
 	"github.com/PancyStudios/PancyBotGo/internal/messagecommands"
 	"github.com/PancyStudios/PancyBotGo/pkg/database"
 	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/PancyStudios/PancyBotGo/pkg/templates"
 	"github.com/bwmarrin/discordgo"
 	"go.mongodb.org/mongo-driver/bson"
 )
@@ -57,6 +58,11 @@ func leaveCommand(ctx *messagecommands.MessageContext) error {
 		}
 	}
 
+	if err := templates.Validate(guildDoc.Greetings.Farewell.Message); err != nil {
+		_, err = ctx.ReplyError("Mensaje Inválido", templates.Explain(err))
+		return err
+	}
+
 	_, err = database.GlobalGuildDM.Set(bson.M{"id": ctx.Message.GuildID}, guildDoc)
 	if err != nil {
 		_, err = ctx.ReplyError("Error", fmt.Sprintf("❌ Error guardando configuración: %v", err))
@@ -12,4 +12,6 @@ func Register() {
 	messagecommands.RegisterCommand("leave", "Comando leave", "pan!leave", "Config", leaveCommand)
 	messagecommands.RegisterCommand("channels", "Comando channels", "pan!channels", "Config", channelsCommand)
 	messagecommands.RegisterCommand("logs", "Comando logs", "pan!logs", "Config", logsCommand)
+	messagecommands.RegisterCommand("card", "Comando card", "pan!card <ajuste> <valor>", "Config", cardCommand)
+	messagecommands.RegisterCommand("template", "Previsualiza una plantilla de mensaje", "pan!template [texto]", "Config", templateCommand)
 }
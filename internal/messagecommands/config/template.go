@@ -0,0 +1,49 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/PancyStudios/PancyBotGo/internal/messagecommands"
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/templates"
+	"github.com/bwmarrin/discordgo"
+)
+
+func templateCommand(ctx *messagecommands.MessageContext) error {
+	if !ctx.HasPermission(discordgo.PermissionManageGuild) {
+		_, err := ctx.ReplyError("Acceso Denegado", "Necesitas el permiso de Gestionar Servidor para usar este comando.")
+		return err
+	}
+
+	if len(ctx.Args) == 0 {
+		_, err := ctx.ReplyEmbed(discord.NewEmbed().
+			SetTitle("🧩 Variables disponibles").
+			SetDescription(templates.Help()).
+			SetColor(discord.ColorInfo).
+			Build())
+		return err
+	}
+
+	text := strings.Join(ctx.Args, " ")
+
+	var guild *discordgo.Guild
+	if ctx.Session.State != nil {
+		guild, _ = ctx.Session.State.Guild(ctx.Message.GuildID)
+	}
+	vars := templates.NewVars(ctx.Message.Author, guild)
+	if profile, err := database.GetLocalLevelProfile(ctx.Message.GuildID, ctx.Message.Author.ID); err == nil && profile != nil {
+		vars.WithLevel(profile.Level, profile.XP)
+	}
+
+	preview := discord.NewEmbed().
+		SetTitle("🧩 Vista previa").
+		SetDescription(templates.Render(text, vars)).
+		SetColor(discord.ColorSuccess)
+	if err := templates.Validate(text); err != nil {
+		preview.SetColor(discord.ColorWarning).AddField("⚠️ Problemas", templates.Explain(err), false)
+	}
+
+	_, err := ctx.ReplyEmbed(preview.Build())
+	return err
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/PancyStudios/PancyBotGo/internal/messagecommands"
 	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/items"
 	"github.com/PancyStudios/PancyBotGo/pkg/models"
 	"github.com/bwmarrin/discordgo"
 	"github.com/google/uuid"
@@ -19,7 +20,7 @@ func adminShopCommand(ctx *messagecommands.MessageContext) error {
 	}
 
 	if len(ctx.Args) == 0 {
-		_, err := ctx.ReplyError("Uso Incorrecto", "Uso: `pan!adminshop add <nombre> | <desc> | <precio> | [emoji] | [efecto: NONE/EXPAND_BANK/GIVE_ROLE] | [valor_efecto] | [rol]`\nO `pan!adminshop delete <id>`")
+		_, err := ctx.ReplyError("Uso Incorrecto", "Uso: `pan!adminshop add <nombre> | <desc> | <precio> | [emoji] | [efecto] | [valor_efecto] | [rol] | [duración_min] | [stock] | [obligatorio: si/no]`\nO `pan!adminshop delete <id>`\nEfectos: "+effectIDs())
 		return err
 	}
 
@@ -52,12 +53,9 @@ func adminShopCommand(ctx *messagecommands.MessageContext) error {
 			emoji = parts[3]
 		}
 
-		effect := "NONE"
+		effect := items.EffectNone
 		if len(parts) > 4 && parts[4] != "" {
-			e := strings.ToUpper(parts[4])
-			if e == "NONE" || e == "EXPAND_BANK" || e == "GIVE_ROLE" {
-				effect = e
-			}
+			effect = strings.ToUpper(parts[4])
 		}
 
 		effectValue := float64(0)
@@ -76,13 +74,26 @@ func adminShopCommand(ctx *messagecommands.MessageContext) error {
 			roleID = r
 		}
 
-		itemType := models.ItemTypeCollectible
-		if effect == "GIVE_ROLE" {
-			itemType = models.ItemTypeRole
-		} else if effect != "NONE" {
-			itemType = models.ItemTypeConsumable
+		duration := 0
+		if len(parts) > 7 && parts[7] != "" {
+			duration, err = strconv.Atoi(parts[7])
+			if err != nil || duration <= 0 {
+				_, err = ctx.ReplyError("Error", "❌ La duración debe ser un número de minutos mayor a 0.")
+				return err
+			}
 		}
 
+		stock := -1
+		if len(parts) > 8 && parts[8] != "" {
+			stock, err = strconv.Atoi(parts[8])
+			if err != nil || stock < 0 {
+				_, err = ctx.ReplyError("Error", "❌ El stock debe ser un número igual o mayor a 0.")
+				return err
+			}
+		}
+
+		required := len(parts) > 9 && (strings.EqualFold(parts[9], "si") || strings.EqualFold(parts[9], "sí"))
+
 		item := models.Item{
 			ID:          uuid.New().String()[:8],
 			GuildID:     ctx.Message.GuildID,
@@ -90,14 +101,21 @@ func adminShopCommand(ctx *messagecommands.MessageContext) error {
 			Description: desc,
 			Price:       price,
 			SellPrice:   price / 2,
-			Type:        itemType,
 			Emoji:       emoji,
-			Stock:       -1,
+			Stock:       stock,
 			Effect:      effect,
 			EffectValue: effectValue,
 			RoleID:      roleID,
+			Duration:    duration,
+			Required:    required,
 		}
 
+		if err := items.Validate(item); err != nil {
+			_, err = ctx.ReplyError("Efecto Inválido", "❌ "+items.Explain(err))
+			return err
+		}
+		item.Type = items.Of(item).Type
+
 		err = database.SaveItem(item)
 		if err != nil {
 			_, err = ctx.ReplyError("Error", "❌ Hubo un error al guardar el objeto en la tienda local.")
@@ -147,3 +165,14 @@ func adminShopCommand(ctx *messagecommands.MessageContext) error {
 	_, err := ctx.ReplyError("Uso Incorrecto", "Acción no reconocida. Usa `add` o `delete`.")
 	return err
 }
+
+// effectIDs lists the effects the local shop can sell
+func effectIDs() string {
+	var ids []string
+	for _, e := range items.All() {
+		if e.Scope != items.ScopeGlobal {
+			ids = append(ids, "`"+e.ID+"`")
+		}
+	}
+	return strings.Join(ids, ", ")
+}
@@ -2,10 +2,12 @@ package economy
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/PancyStudios/PancyBotGo/internal/messagecommands"
 	"github.com/PancyStudios/PancyBotGo/pkg/database"
 	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/items"
 )
 
 func balanceCommand(ctx *messagecommands.MessageContext, isGlobal bool) error {
@@ -32,14 +34,30 @@ func balanceCommand(ctx *messagecommands.MessageContext, isGlobal bool) error {
 		return err
 	}
 
-	embed := discord.NewEmbed().
+	builder := discord.NewEmbed().
 		SetTitle(fmt.Sprintf("Balance de %s", targetUser.Username)).
 		SetColor(discord.ColorWarning).
 		SetThumbnail(targetUser.AvatarURL("")).
 		SetDescription("💰 | Aquí tienes el resumen de tu economía.").
 		AddField("🌐 Economía Global (Estrellas)", fmt.Sprintf("**Cartera:** 🌟 %d\n**Banco:** 🏦 %d / %d", globalProfile.StarsWallet, globalProfile.StarsBank, globalProfile.BankCapacity), false).
-		AddField("🏠 Economía Local (Servidor)", fmt.Sprintf("**Cartera:** 💵 %d\n**Banco:** 🏦 %d / %d", localProfile.Wallet, localProfile.Bank, localProfile.BankCapacity), false).
-		Build()
+		AddField("🏠 Economía Local (Servidor)", fmt.Sprintf("**Cartera:** 💵 %d\n**Banco:** 🏦 %d / %d", localProfile.Wallet, localProfile.Bank, localProfile.BankCapacity), false)
+
+	// Badges are shown while owned, from either inventory
+	if catalog, err := database.GetItems(ctx.Message.GuildID); err == nil {
+		var badges []string
+		for _, badge := range append(items.Badges(catalog, globalProfile.Inventory), items.Badges(catalog, localProfile.Inventory)...) {
+			badges = append(badges, fmt.Sprintf("%s %s", badge.Emoji, badge.Name))
+		}
+		if len(badges) > 0 {
+			builder.AddField("🏅 Emblemas", strings.Join(badges, " · "), false)
+		}
+	}
+
+	if buffs := strings.TrimSpace(items.DescribeBuffs(globalProfile.Buffs) + "\n" + items.DescribeBuffs(localProfile.Buffs)); buffs != "" {
+		builder.AddField("⏳ Efectos Activos", buffs, false)
+	}
+
+	embed := builder.Build()
 
 	_, err = ctx.ReplyEmbed(embed)
 	return err
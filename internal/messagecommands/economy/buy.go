@@ -1,13 +1,13 @@
 package economy
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 
 	"github.com/PancyStudios/PancyBotGo/internal/messagecommands"
 	"github.com/PancyStudios/PancyBotGo/pkg/database"
 	"github.com/PancyStudios/PancyBotGo/pkg/models"
-	"go.mongodb.org/mongo-driver/bson"
 )
 
 func buyCommand(ctx *messagecommands.MessageContext) error {
@@ -51,32 +51,26 @@ func buyCommand(ctx *messagecommands.MessageContext) error {
 		return err
 	}
 
-	totalCost := selectedItem.Price * qty
+	totalCost, err := database.PurchaseItem(ctx.Message.GuildID, ctx.Message.Author.ID, *selectedItem, int(qty))
+	if errors.Is(err, database.ErrOutOfStock) {
+		_, err = ctx.ReplyError("Error", "❌ No quedan suficientes unidades de este objeto en la tienda.")
+		return err
+	}
 
 	if selectedItem.IsGlobal {
-		_, err = database.AddStars(ctx.Message.Author.ID, -totalCost, false)
 		if err != nil {
 			_, err = ctx.ReplyError("Error", "❌ No tienes suficientes estrellas para comprar esto.")
 			return err
 		}
 
-		profile, _ := database.GetGlobalProfile(ctx.Message.Author.ID)
-		profile.Inventory[selectedItem.ID] += int(qty)
-		database.GlobalEconomyDM.Set(bson.M{"_id": profile.UserID}, profile)
-
 		_, err = ctx.ReplySuccess("Compra Exitosa", fmt.Sprintf("Has comprado **x%d %s** por 🌟 %d estrellas.", qty, selectedItem.Name, totalCost))
 		return err
 	} else {
-		_, err = database.AddLocalBalance(ctx.Message.GuildID, ctx.Message.Author.ID, -totalCost, false)
 		if err != nil {
 			_, err = ctx.ReplyError("Error", "❌ No tienes suficientes monedas locales para comprar esto.")
 			return err
 		}
 
-		profile, _ := database.GetLocalProfile(ctx.Message.GuildID, ctx.Message.Author.ID)
-		profile.Inventory[selectedItem.ID] += int(qty)
-		database.LocalEconomyDM.Set(bson.M{"_id": profile.ID}, profile)
-
 		_, err = ctx.ReplySuccess("Compra Exitosa", fmt.Sprintf("Has comprado **x%d %s** por 💵 %d monedas locales.", qty, selectedItem.Name, totalCost))
 		return err
 	}
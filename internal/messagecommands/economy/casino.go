@@ -0,0 +1,120 @@
+package economy
+
+import (
+	"strconv"
+
+	"github.com/PancyStudios/PancyBotGo/internal/messagecommands"
+	"github.com/PancyStudios/PancyBotGo/pkg/casino"
+	"github.com/PancyStudios/PancyBotGo/pkg/ecoconfig"
+)
+
+// parseBet reads the bet at an argument, replying with the usage when it is missing
+func parseBet(ctx *messagecommands.MessageContext, index int, usage string) (int64, bool) {
+	if len(ctx.Args) <= index {
+		_, _ = ctx.ReplyError("Uso Incorrecto", usage)
+		return 0, false
+	}
+	amount, err := strconv.ParseInt(ctx.Args[index], 10, 64)
+	if err != nil || amount <= 0 {
+		_, _ = ctx.ReplyError("Error", "❌ La apuesta debe ser un número mayor a 0.")
+		return 0, false
+	}
+	return amount, true
+}
+
+func blackjackCommand(ctx *messagecommands.MessageContext, isGlobal bool) error {
+	amount, ok := parseBet(ctx, 0, "Uso: `pan!eco blackjack <apuesta>`")
+	if !ok {
+		return nil
+	}
+
+	guildID := ctx.Message.GuildID
+	_, err := casino.OpenBlackjack(ctx.Session, guildID, ctx.Message.ChannelID, ctx.Message.Author.ID, isGlobal, amount)
+	if err != nil {
+		_, err = ctx.ReplyError("Casino", casino.ErrorText(err, ecoconfig.CasinoFor(guildID, isGlobal)))
+	}
+	return err
+}
+
+func slotsCommand(ctx *messagecommands.MessageContext, isGlobal bool) error {
+	amount, ok := parseBet(ctx, 0, "Uso: `pan!eco slots <apuesta>`")
+	if !ok {
+		return nil
+	}
+
+	result, err := casino.Slots(ctx.Message.GuildID, ctx.Message.Author.ID, isGlobal, amount)
+	return replyCasinoResult(ctx, isGlobal, "🎰 Tragaperras", result.Wager.Rules.SlotsTable(), result, err)
+}
+
+func coinflipCommand(ctx *messagecommands.MessageContext, isGlobal bool) error {
+	usage := "Uso: `pan!eco coinflip <apuesta> <cara|cruz>`"
+	amount, ok := parseBet(ctx, 0, usage)
+	if !ok {
+		return nil
+	}
+	if len(ctx.Args) < 2 {
+		_, err := ctx.ReplyError("Uso Incorrecto", usage)
+		return err
+	}
+
+	result, err := casino.Coinflip(ctx.Message.GuildID, ctx.Message.Author.ID, isGlobal, amount, ctx.Args[1])
+	return replyCasinoResult(ctx, isGlobal, "🪙 Cara o cruz", "", result, err)
+}
+
+func rouletteCommand(ctx *messagecommands.MessageContext, isGlobal bool) error {
+	usage := "Uso: `pan!eco roulette <apuesta> <rojo|negro|par|impar|bajo|alto|0-36>`"
+	amount, ok := parseBet(ctx, 0, usage)
+	if !ok {
+		return nil
+	}
+	if len(ctx.Args) < 2 {
+		_, err := ctx.ReplyError("Uso Incorrecto", usage)
+		return err
+	}
+
+	result, err := casino.Roulette(ctx.Message.GuildID, ctx.Message.Author.ID, isGlobal, amount, ctx.Args[1])
+	return replyCasinoResult(ctx, isGlobal, "🎡 Ruleta", casino.RouletteTable, result, err)
+}
+
+func duelCommand(ctx *messagecommands.MessageContext, isGlobal bool) error {
+	usage := "Uso: `pan!eco duel @usuario <apuesta>`"
+	opponentID := ctx.ParseUser(0)
+	if opponentID == "" {
+		_, err := ctx.ReplyError("Uso Incorrecto", usage)
+		return err
+	}
+	amount, ok := parseBet(ctx, 1, usage)
+	if !ok {
+		return nil
+	}
+
+	userID := ctx.Message.Author.ID
+	guildID := ctx.Message.GuildID
+	if opponentID == userID {
+		_, err := ctx.ReplyError("Error", "❌ No puedes retarte a ti mismo.")
+		return err
+	}
+	if member, err := ctx.Session.GuildMember(guildID, opponentID); err == nil && member.User.Bot {
+		_, err := ctx.ReplyError("Error", "❌ Los bots no tienen economía.")
+		return err
+	}
+
+	_, err := casino.OpenDuel(ctx.Session, guildID, ctx.Message.ChannelID, userID, opponentID, isGlobal, amount)
+	if err != nil {
+		_, err = ctx.ReplyError("Casino", casino.ErrorText(err, ecoconfig.CasinoFor(guildID, isGlobal)))
+	}
+	return err
+}
+
+// replyCasinoResult shows the outcome of an instant game, or why it was not played
+func replyCasinoResult(ctx *messagecommands.MessageContext, isGlobal bool, title, footer string, result casino.Result, err error) error {
+	if result.Wager.Amount == 0 {
+		_, err = ctx.ReplyError("Casino", casino.ErrorText(err, ecoconfig.CasinoFor(ctx.Message.GuildID, isGlobal)))
+		return err
+	}
+	if _, replyErr := ctx.ReplyEmbed(casino.ResultEmbed(title, result, footer)); replyErr != nil {
+		return replyErr
+	}
+	// A prize that could not be paid
+	return err
+}
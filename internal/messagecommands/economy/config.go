@@ -0,0 +1,259 @@
+package economy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/PancyStudios/PancyBotGo/internal/messagecommands"
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/ecoconfig"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const economyConfigUsage = "Uso:\n" +
+	"`pan!economy view`\n" +
+	"`pan!economy currency <nombre> [emoji]`\n" +
+	"`pan!economy start <cantidad>` · `pan!economy bank <capacidad>`\n" +
+	"`pan!economy enable|disable <comando>`\n" +
+	"`pan!economy set <comando> <pago_min|pago_max|probabilidad|multa_min|multa_max|cooldown> <valor>`\n" +
+	"`pan!economy message <comando> <success|fail> [texto]` (sin texto borra las respuestas)\n" +
+	"`pan!economy casino <on|off|min|max|ventaja> [valor]` · `pan!economy slots <símbolo|pareja> <multiplicador>`\n" +
+	"`pan!economy reset [comando|casino]`"
+
+func economyConfigCommand(ctx *messagecommands.MessageContext) error {
+	if !ctx.HasPermission(discordgo.PermissionManageGuild) {
+		_, err := ctx.ReplyError("Acceso Denegado", "Necesitas el permiso de Gestionar Servidor.")
+		return err
+	}
+
+	if len(ctx.Args) == 0 {
+		_, err := ctx.ReplyError("Uso Incorrecto", economyConfigUsage)
+		return err
+	}
+
+	guildID := ctx.Message.GuildID
+	guildData, err := database.GlobalGuildDM.Get(bson.M{"id": guildID})
+	if err != nil {
+		_, err = ctx.ReplyError("Error", fmt.Sprintf("❌ Error obteniendo configuración: %v", err))
+		return err
+	}
+	if guildData == nil {
+		guildData = models.NewDefaultGuildDocument(guildID)
+	}
+
+	cfg := guildData.Economy
+	if cfg.Commands == nil {
+		cfg.Commands = make(map[string]models.EconomyCommandConfig)
+	}
+
+	action := strings.ToLower(ctx.Args[0])
+	args := ctx.Args[1:]
+
+	// Every action but view and currency names a command or a number first
+	command := ""
+	if len(args) > 0 {
+		command = strings.ToLower(args[0])
+	}
+	needsCommand := action == "enable" || action == "disable" || action == "set" || action == "message"
+	if needsCommand && !ecoconfig.Known(command) {
+		_, err = ctx.ReplyError("Comando Inválido", "Comandos configurables: `"+strings.Join(ecoconfig.Commands, "`, `")+"`")
+		return err
+	}
+
+	switch action {
+	case "view":
+		_, err = ctx.ReplyEmbed(economyConfigEmbed(cfg))
+		return err
+
+	case "currency":
+		if len(args) == 0 {
+			_, err = ctx.ReplyError("Uso Incorrecto", economyConfigUsage)
+			return err
+		}
+		cfg.CurrencyName = args[0]
+		if len(args) > 1 {
+			cfg.CurrencyEmoji = args[1]
+		}
+
+	case "start", "bank":
+		if len(args) == 0 {
+			_, err = ctx.ReplyError("Uso Incorrecto", economyConfigUsage)
+			return err
+		}
+		value, parseErr := strconv.ParseInt(args[0], 10, 64)
+		if parseErr != nil {
+			_, err = ctx.ReplyError("Valor Inválido", "Debes indicar un número entero.")
+			return err
+		}
+		if action == "start" {
+			cfg.StartingBalance = value
+		} else {
+			cfg.BankCapacity = value
+		}
+
+	case "enable", "disable":
+		custom := cfg.Commands[command]
+		custom.Disabled = action == "disable"
+		cfg.Commands[command] = custom
+
+	case "set":
+		if len(args) < 3 {
+			_, err = ctx.ReplyError("Uso Incorrecto", economyConfigUsage)
+			return err
+		}
+		value, parseErr := strconv.ParseInt(args[2], 10, 64)
+		if parseErr != nil {
+			_, err = ctx.ReplyError("Valor Inválido", "Debes indicar un número entero (0 = por defecto).")
+			return err
+		}
+
+		custom := cfg.Commands[command]
+		switch strings.ToLower(args[1]) {
+		case "pago_min":
+			custom.MinPayout = value
+		case "pago_max":
+			custom.MaxPayout = value
+		case "probabilidad":
+			custom.SuccessRate = int(value)
+		case "multa_min":
+			custom.MinFine = value
+		case "multa_max":
+			custom.MaxFine = value
+		case "cooldown":
+			custom.CooldownMinutes = int(value)
+		default:
+			_, err = ctx.ReplyError("Campo Inválido", economyConfigUsage)
+			return err
+		}
+		cfg.Commands[command] = custom
+
+	case "message":
+		if len(args) < 2 {
+			_, err = ctx.ReplyError("Uso Incorrecto", economyConfigUsage)
+			return err
+		}
+		custom := cfg.Commands[command]
+		messages := &custom.Messages
+		if strings.ToLower(args[1]) == "fail" {
+			messages = &custom.FailMessages
+		}
+		if text := strings.Join(args[2:], " "); text != "" {
+			*messages = append(*messages, text)
+		} else {
+			*messages = nil
+		}
+		cfg.Commands[command] = custom
+
+	case "casino":
+		if len(args) == 0 {
+			_, err = ctx.ReplyError("Uso Incorrecto", economyConfigUsage)
+			return err
+		}
+		if command == "on" || command == "off" {
+			cfg.Casino.Disabled = command == "off"
+			break
+		}
+		if len(args) < 2 {
+			_, err = ctx.ReplyError("Uso Incorrecto", economyConfigUsage)
+			return err
+		}
+		value, parseErr := strconv.ParseInt(args[1], 10, 64)
+		if parseErr != nil {
+			_, err = ctx.ReplyError("Valor Inválido", "Debes indicar un número entero (0 = por defecto).")
+			return err
+		}
+		switch command {
+		case "min":
+			cfg.Casino.MinBet = value
+		case "max":
+			cfg.Casino.MaxBet = value
+		case "ventaja":
+			cfg.Casino.HouseEdge = int(value)
+		default:
+			_, err = ctx.ReplyError("Campo Inválido", economyConfigUsage)
+			return err
+		}
+
+	case "slots":
+		if len(args) < 2 {
+			_, err = ctx.ReplyError("Uso Incorrecto", economyConfigUsage)
+			return err
+		}
+		multiplier, parseErr := strconv.ParseFloat(args[1], 64)
+		if parseErr != nil {
+			_, err = ctx.ReplyError("Valor Inválido", "Debes indicar un multiplicador, por ejemplo `2.5` (0 = por defecto).")
+			return err
+		}
+		setSlotsPayout(&cfg.Casino, command, multiplier)
+
+	case "reset":
+		if command == "casino" {
+			cfg.Casino = models.CasinoConfig{}
+		} else if command != "" {
+			delete(cfg.Commands, command)
+		} else {
+			cfg = models.EconomyConfig{Commands: make(map[string]models.EconomyCommandConfig)}
+		}
+
+	default:
+		_, err = ctx.ReplyError("Uso Incorrecto", economyConfigUsage)
+		return err
+	}
+
+	if err := ecoconfig.Validate(cfg); err != nil {
+		_, err = ctx.ReplyError("Configuración Inválida", "❌ "+ecoconfig.Explain(err))
+		return err
+	}
+
+	guildData.Economy = cfg
+	if _, err = database.GlobalGuildDM.Set(bson.M{"id": guildID}, guildData); err != nil {
+		_, err = ctx.ReplyError("Error", fmt.Sprintf("❌ Error guardando configuración: %v", err))
+		return err
+	}
+
+	_, err = ctx.ReplyEmbed(economyConfigEmbed(cfg))
+	return err
+}
+
+func economyConfigEmbed(cfg models.EconomyConfig) *discordgo.MessageEmbed {
+	currency := ecoconfig.LocalCurrency(cfg)
+	bank := cfg.BankCapacity
+	if bank <= 0 {
+		bank = database.DefaultLocalBankCapacity
+	}
+
+	embed := discord.NewEmbed().
+		SetTitle("⚙️ Economía del servidor").
+		SetDescription("Los ajustes solo cambian la economía local. Desactivar un comando lo desactiva también en la global.").
+		SetColor(discord.ColorSuccess).
+		AddField("Moneda", fmt.Sprintf("%s %s", currency.Emoji, currency.Name), true).
+		AddField("Saldo inicial", currency.Format(cfg.StartingBalance), true).
+		AddField("Banco inicial", fmt.Sprintf("%d", bank), true)
+
+	for _, command := range ecoconfig.Commands {
+		embed.AddField(command, ecoconfig.Resolve(cfg, command, false).Summary(), false)
+	}
+	casinoRules := ecoconfig.ResolveCasino(cfg, false)
+	embed.AddField("casino", casinoRules.Summary()+"\n"+casinoRules.SlotsTable(), false)
+	return embed.Build()
+}
+
+// setSlotsPayout changes a prize of the slot machine; "pareja" is two of a kind
+func setSlotsPayout(cfg *models.CasinoConfig, symbol string, multiplier float64) {
+	if symbol == "pareja" {
+		cfg.SlotsPair = multiplier
+		return
+	}
+	if cfg.SlotsPayouts == nil {
+		cfg.SlotsPayouts = make(map[string]float64)
+	}
+	if multiplier == 0 {
+		delete(cfg.SlotsPayouts, symbol)
+		return
+	}
+	cfg.SlotsPayouts[symbol] = multiplier
+}
@@ -7,6 +7,9 @@ import (
 
 	"github.com/PancyStudios/PancyBotGo/internal/messagecommands"
 	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/ecoconfig"
+	"github.com/PancyStudios/PancyBotGo/pkg/items"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
 )
 
 func crimeCommand(ctx *messagecommands.MessageContext, isGlobal bool) error {
@@ -14,16 +17,20 @@ func crimeCommand(ctx *messagecommands.MessageContext, isGlobal bool) error {
 	userID := ctx.Message.Author.ID
 	guildID := ctx.Message.GuildID
 
-	cooldownDuration := 10 * time.Minute
+	rules := ecoconfig.For(guildID, ecoconfig.CommandCrime, isGlobal)
+	if !rules.Enabled {
+		_, err := ctx.ReplyError("Comando Desactivado", rules.DisabledText())
+		return err
+	}
 
 	var isReady bool
 	var remaining time.Duration
 	var err error
 
 	if !isGlobal {
-		isReady, remaining, err = database.CooldownLocal(guildID, userID, "crime", cooldownDuration)
+		isReady, remaining, err = database.CooldownLocal(guildID, userID, "crime", rules.Cooldown)
 	} else {
-		isReady, remaining, err = database.CooldownStars(userID, "crime", cooldownDuration)
+		isReady, remaining, err = database.CooldownStars(userID, "crime", rules.Cooldown)
 	}
 
 	if err != nil {
@@ -36,34 +43,44 @@ func crimeCommand(ctx *messagecommands.MessageContext, isGlobal bool) error {
 		return err
 	}
 
-	success := rand.Float64() < 0.40
+	gear, err := items.LoadGear(guildID, userID, isGlobal, items.EffectCrimeTool)
+	if err != nil {
+		_, _ = ctx.ReplyError("Error", "❌ Error al revisar tu equipo.")
+		return err
+	}
+	if gear.Missing != nil {
+		_, err = ctx.ReplyError("Herramienta Necesaria", gear.MissingText("cometer crímenes"))
+		return err
+	}
+
+	success := rand.Float64() < gear.Chance(rules.SuccessRate, 0.90)
 
 	if !isGlobal {
 		_ = database.SetCooldownLocal(guildID, userID, "crime")
 
 		if success {
-			amount := int64(rand.Intn(400) + 200)
-			database.AddLocalBalance(guildID, userID, amount, false)
-			_, err = ctx.ReplySuccess("Crimen Exitoso", fmt.Sprintf("🔪 Robaste una tienda y escapaste con **💵 %d monedas**.", amount))
+			amount := rules.Payout()
+			database.AddLocalBalance(guildID, userID, amount, false, database.TxInfo{Type: models.TransactionEarn, Command: "crime"})
+			_, err = ctx.ReplySuccess("Crimen Exitoso", rules.Success("🔪 Robaste una tienda y escapaste con **{amount}**.", amount)+gear.Note(""))
 			return err
 		} else {
-			fine := int64(rand.Intn(200) + 100)
-			database.AddLocalBalance(guildID, userID, -fine, false)
-			_, err = ctx.ReplyError("Atrapado", fmt.Sprintf("🚔 Te atraparon intentando robar una ancianita. Pagaste una fianza de **💵 %d monedas**.", fine))
+			fine := rules.Fine()
+			database.AddLocalBalance(guildID, userID, -fine, false, database.TxInfo{Type: models.TransactionPenalty, Command: "crime"})
+			_, err = ctx.ReplyError("Atrapado", rules.Failure("🚔 Te atraparon intentando robar una ancianita. Pagaste una fianza de **{amount}**.", fine))
 			return err
 		}
 	} else {
 		_ = database.SetCooldownStars(userID, "crime")
 
 		if success {
-			amount := int64(rand.Intn(300) + 150)
-			database.AddStars(userID, amount, false)
-			_, err = ctx.ReplySuccess("Crimen Exitoso", fmt.Sprintf("🔪 Hackeaste el banco intergaláctico y obtuviste **🌟 %d estrellas**.", amount))
+			amount := rules.Payout()
+			database.AddStars(userID, amount, false, database.TxInfo{Type: models.TransactionEarn, Command: "crime"})
+			_, err = ctx.ReplySuccess("Crimen Exitoso", rules.Success("🔪 Hackeaste el banco intergaláctico y obtuviste **{amount}**.", amount)+gear.Note(""))
 			return err
 		} else {
-			fine := int64(rand.Intn(150) + 50)
-			database.AddStars(userID, -fine, false)
-			_, err = ctx.ReplyError("Atrapado", fmt.Sprintf("🚔 La patrulla espacial te pilló contrabandeando. Pagaste una multa de **🌟 %d estrellas**.", fine))
+			fine := rules.Fine()
+			database.AddStars(userID, -fine, false, database.TxInfo{Type: models.TransactionPenalty, Command: "crime"})
+			_, err = ctx.ReplyError("Atrapado", rules.Failure("🚔 La patrulla espacial te pilló contrabandeando. Pagaste una multa de **{amount}**.", fine))
 			return err
 		}
 	}
@@ -6,14 +6,29 @@ import (
 
 	"github.com/PancyStudios/PancyBotGo/internal/messagecommands"
 	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/ecoconfig"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
 )
 
 func dailyCommand(ctx *messagecommands.MessageContext, isGlobal bool) error {
 	userID := ctx.Message.Author.ID
+	guildID := ctx.Message.GuildID
 
-	cooldownDuration := 24 * time.Hour
+	rules := ecoconfig.For(guildID, ecoconfig.CommandDaily, isGlobal)
+	if !rules.Enabled {
+		_, err := ctx.ReplyError("Comando Desactivado", rules.DisabledText())
+		return err
+	}
+
+	var isReady bool
+	var remaining time.Duration
+	var err error
 
-	isReady, remaining, err := database.CooldownStars(userID, "daily", cooldownDuration)
+	if !isGlobal {
+		isReady, remaining, err = database.CooldownLocal(guildID, userID, "daily", rules.Cooldown)
+	} else {
+		isReady, remaining, err = database.CooldownStars(userID, "daily", rules.Cooldown)
+	}
 	if err != nil {
 		_, err = ctx.ReplyError("Error", "❌ Error al comprobar el cooldown.")
 		return err
@@ -24,16 +39,25 @@ func dailyCommand(ctx *messagecommands.MessageContext, isGlobal bool) error {
 		return err
 	}
 
-	amount := int64(1000)
+	amount := rules.Payout()
+	info := database.TxInfo{Type: models.TransactionReward, Command: "daily"}
 
-	_, err = database.AddStars(userID, amount, false)
+	if !isGlobal {
+		_, err = database.AddLocalBalance(guildID, userID, amount, false, info)
+	} else {
+		_, err = database.AddStars(userID, amount, false, info)
+	}
 	if err != nil {
 		_, err = ctx.ReplyError("Error", "❌ Error al procesar la recompensa.")
 		return err
 	}
 
-	_ = database.SetCooldownStars(userID, "daily")
+	if !isGlobal {
+		_ = database.SetCooldownLocal(guildID, userID, "daily")
+	} else {
+		_ = database.SetCooldownStars(userID, "daily")
+	}
 
-	_, err = ctx.ReplySuccess("Recompensa Diaria", fmt.Sprintf("¡Felicidades! Has reclamado tu recompensa diaria de **🌟 %d estrellas**.", amount))
+	_, err = ctx.ReplySuccess("Recompensa Diaria", rules.Success("¡Felicidades! Has reclamado tu recompensa diaria de **{amount}**.", amount))
 	return err
 }
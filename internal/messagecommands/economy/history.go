@@ -0,0 +1,158 @@
+package economy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/internal/messagecommands"
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	historyPageSize = 10
+	auditRapidCount = 5
+)
+
+func historyCommand(ctx *messagecommands.MessageContext, isGlobal bool) error {
+	page := int64(1)
+	if len(ctx.Args) > 0 {
+		if p, err := strconv.ParseInt(ctx.Args[0], 10, 64); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	userID := ctx.Message.Author.ID
+	filter := database.TransactionFilter{Currency: models.CurrencyLocal, GuildID: ctx.Message.GuildID, UserID: userID}
+	symbol := "💵"
+	if isGlobal {
+		filter = database.TransactionFilter{Currency: models.CurrencyStars, UserID: userID}
+		symbol = "🌟"
+	}
+
+	txs, err := database.GetTransactions(filter, historyPageSize, (page-1)*historyPageSize)
+	if err != nil {
+		_, _ = ctx.ReplyError("Error", "❌ No se pudo obtener tu historial en este momento.")
+		return err
+	}
+	if len(txs) == 0 {
+		_, err = ctx.Reply("📭 No hay movimientos en esta página.")
+		return err
+	}
+
+	lines := make([]string, 0, len(txs))
+	for _, tx := range txs {
+		lines = append(lines, transactionLine(tx, symbol))
+	}
+
+	embed := discord.NewEmbed().
+		SetTitle(fmt.Sprintf("📜 Historial de %s", ctx.Message.Author.Username)).
+		SetColor(discord.ColorInfo).
+		SetDescription(strings.Join(lines, "\n")).
+		SetFooter(fmt.Sprintf("Página %d", page), "").
+		Build()
+
+	_, err = ctx.ReplyEmbed(embed)
+	return err
+}
+
+func auditCommand(ctx *messagecommands.MessageContext) error {
+	if !ctx.HasPermission(discordgo.PermissionManageGuild) {
+		_, err := ctx.ReplyError("Acceso Denegado", "Necesitas el permiso de gestionar el servidor para auditar la economía.")
+		return err
+	}
+
+	targetID := ctx.ParseUser(0)
+	if targetID == "" {
+		_, err := ctx.ReplyError("Uso Incorrecto", "Uso: `pan!ecol audit @usuario [horas]`")
+		return err
+	}
+
+	hours := int64(168)
+	if len(ctx.Args) > 1 {
+		if h, err := strconv.ParseInt(ctx.Args[1], 10, 64); err == nil && h > 0 {
+			hours = h
+		}
+	}
+
+	filter := database.TransactionFilter{
+		Currency: models.CurrencyLocal,
+		GuildID:  ctx.Message.GuildID,
+		UserID:   targetID,
+		Since:    time.Now().Add(-time.Duration(hours) * time.Hour),
+	}
+
+	flows, err := database.GetTransactionFlows(filter, 8)
+	if err != nil {
+		_, _ = ctx.ReplyError("Error", "❌ No se pudo consultar el registro de transacciones.")
+		return err
+	}
+	// Embed fields are limited to 1024 characters, keep the list short
+	txs, err := database.GetTransactions(filter, 5, 0)
+	if err != nil {
+		_, _ = ctx.ReplyError("Error", "❌ No se pudo consultar el registro de transacciones.")
+		return err
+	}
+
+	recent := "Sin movimientos."
+	if len(txs) > 0 {
+		lines := make([]string, 0, len(txs))
+		for _, tx := range txs {
+			lines = append(lines, transactionLine(tx, "💵"))
+		}
+		recent = strings.Join(lines, "\n")
+	}
+
+	embed := discord.NewEmbed().
+		SetTitle("🔎 Auditoría de economía").
+		SetColor(discord.ColorWarning).
+		SetDescription(fmt.Sprintf("Movimientos de <@%s> en las últimas **%d horas** en la economía local.", targetID, hours)).
+		AddField("🔁 Flujos con otros usuarios", auditFlowsText(flows), false).
+		AddField("📜 Últimos movimientos", recent, false).
+		Build()
+
+	_, err = ctx.ReplyEmbed(embed)
+	return err
+}
+
+// auditFlowsText lists the money exchanged with each counterparty and flags the
+// ones with many movements or that account for most of what the user received
+func auditFlowsText(flows []*models.TransactionFlow) string {
+	if len(flows) == 0 {
+		return "Sin transferencias, robos ni multas con otros usuarios."
+	}
+
+	var totalReceived int64
+	for _, flow := range flows {
+		totalReceived += flow.Received
+	}
+
+	lines := make([]string, 0, len(flows))
+	for _, flow := range flows {
+		flag := ""
+		if flow.Count >= auditRapidCount || (len(flows) > 1 && totalReceived > 0 && flow.Received*2 > totalReceived) {
+			flag = "⚠️ "
+		}
+		lines = append(lines, fmt.Sprintf("%s<@%s> · recibido **%d** · enviado **%d** · %d movimientos", flag, flow.CounterpartyID, flow.Received, flow.Sent, flow.Count))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// transactionLine renders a ledger entry as a single line
+func transactionLine(tx *models.Transaction, symbol string) string {
+	line := fmt.Sprintf("<t:%d:R> `%s` **%+d** %s", tx.CreatedAt.Unix(), tx.Type.Label(), tx.Amount, symbol)
+	if tx.WalletDelta != 0 && tx.BankDelta != 0 {
+		line = fmt.Sprintf("<t:%d:R> `%s` cartera **%+d**, banco **%+d** %s", tx.CreatedAt.Unix(), tx.Type.Label(), tx.WalletDelta, tx.BankDelta, symbol)
+	}
+	if tx.CounterpartyID != "" {
+		line += fmt.Sprintf(" · <@%s>", tx.CounterpartyID)
+	}
+	if tx.Note != "" {
+		line += " · " + tx.Note
+	}
+	return line
+}
@@ -0,0 +1,232 @@
+package economy
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/internal/messagecommands"
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+)
+
+const marketUsage = "Uso:\n`pan!market list [página]`\n`pan!market sell <id_objeto> <cantidad> <precio|-> [puja_mínima] [horas]`\n`pan!market buy <publicación>`\n`pan!market bid <publicación> <cantidad>`\n`pan!market cancel <publicación>`"
+
+func marketRouter(ctx *messagecommands.MessageContext) error {
+	if len(ctx.Args) == 0 {
+		return marketListCommand(ctx)
+	}
+
+	cmd := strings.ToLower(ctx.Args[0])
+	ctx.Args = ctx.Args[1:]
+
+	switch cmd {
+	case "list":
+		return marketListCommand(ctx)
+	case "sell":
+		return marketSellCommand(ctx)
+	case "buy":
+		return marketBuyCommand(ctx)
+	case "bid":
+		return marketBidCommand(ctx)
+	case "cancel":
+		return marketCancelCommand(ctx)
+	default:
+		_, err := ctx.ReplyError("Uso Incorrecto", marketUsage)
+		return err
+	}
+}
+
+func marketListCommand(ctx *messagecommands.MessageContext) error {
+	page := int64(1)
+	if len(ctx.Args) > 0 {
+		if p, err := strconv.ParseInt(ctx.Args[0], 10, 64); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	listings, err := database.GetActiveListings(ctx.Message.GuildID, 10, (page-1)*10)
+	if err != nil {
+		_, _ = ctx.ReplyError("Error", "❌ No se pudo cargar el mercado.")
+		return err
+	}
+	if len(listings) == 0 {
+		_, err := ctx.Reply("🏪 No hay publicaciones activas en esta página. ¡Publica algo con `pan!market sell`!")
+		return err
+	}
+
+	lines := make([]string, 0, len(listings))
+	for _, listing := range listings {
+		lines = append(lines, marketListingLine(listing))
+	}
+
+	embed := discord.NewEmbed().
+		SetTitle("🏪 Mercado del servidor").
+		SetColor(discord.ColorInfo).
+		SetDescription(strings.Join(lines, "\n\n")).
+		SetFooter(fmt.Sprintf("Página %d · Comisión de publicación: %d%%", page, database.ListingFeePercent), "").
+		Build()
+
+	_, err = ctx.ReplyEmbed(embed)
+	return err
+}
+
+func marketSellCommand(ctx *messagecommands.MessageContext) error {
+	if len(ctx.Args) < 3 {
+		_, err := ctx.ReplyError("Uso Incorrecto", marketUsage)
+		return err
+	}
+
+	qty, err := strconv.Atoi(ctx.Args[1])
+	if err != nil || qty <= 0 {
+		_, err := ctx.ReplyError("Error", "❌ La cantidad debe ser un número mayor a 0.")
+		return err
+	}
+
+	// "-" skips the buy now price for auction only listings
+	var price, startingBid, hours int64
+	numbers := []*int64{&price, &startingBid, &hours}
+	for n, arg := range ctx.Args[2:] {
+		if n >= len(numbers) {
+			break
+		}
+		if arg == "-" {
+			continue
+		}
+		v, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil || v <= 0 {
+			_, err := ctx.ReplyError("Uso Incorrecto", marketUsage)
+			return err
+		}
+		*numbers[n] = v
+	}
+
+	items, err := database.GetItems(ctx.Message.GuildID)
+	if err != nil {
+		_, _ = ctx.ReplyError("Error", "❌ Error al acceder a los objetos.")
+		return err
+	}
+	var selectedItem *models.Item
+	for _, it := range items {
+		if it.ID == ctx.Args[0] {
+			copyIt := it
+			selectedItem = &copyIt
+			break
+		}
+	}
+	if selectedItem == nil {
+		_, err := ctx.ReplyError("Error", "❌ No existe un objeto con ese ID.")
+		return err
+	}
+
+	listing, err := database.CreateListing(ctx.Message.GuildID, ctx.Message.Author.ID, *selectedItem, qty, price, startingBid, time.Duration(hours)*time.Hour)
+	if err != nil {
+		return replyMarketError(ctx, err)
+	}
+
+	_, err = ctx.ReplySuccess("Publicación creada", fmt.Sprintf("Publicación `%s`: **x%d %s**. Se cobró una comisión de 💵 %d.\n%s",
+		listing.ID, listing.Quantity, listing.ItemName, listing.Fee, marketListingPrices(listing)))
+	return err
+}
+
+func marketBuyCommand(ctx *messagecommands.MessageContext) error {
+	if len(ctx.Args) < 1 {
+		_, err := ctx.ReplyError("Uso Incorrecto", marketUsage)
+		return err
+	}
+
+	listing, err := database.BuyListing(ctx.Message.GuildID, ctx.Args[0], ctx.Message.Author.ID)
+	if err != nil {
+		return replyMarketError(ctx, err)
+	}
+
+	_, err = ctx.ReplySuccess("Compra Exitosa", fmt.Sprintf("Compraste **x%d %s** a <@%s> por 💵 %d.", listing.Quantity, listing.ItemName, listing.SellerID, listing.BuyNowPrice))
+	return err
+}
+
+func marketBidCommand(ctx *messagecommands.MessageContext) error {
+	if len(ctx.Args) < 2 {
+		_, err := ctx.ReplyError("Uso Incorrecto", marketUsage)
+		return err
+	}
+
+	amount, err := strconv.ParseInt(ctx.Args[1], 10, 64)
+	if err != nil || amount <= 0 {
+		_, err := ctx.ReplyError("Error", "❌ La cantidad debe ser un número mayor a 0.")
+		return err
+	}
+
+	listing, err := database.PlaceBid(ctx.Message.GuildID, ctx.Args[0], ctx.Message.Author.ID, amount)
+	if err != nil {
+		return replyMarketError(ctx, err)
+	}
+
+	_, err = ctx.ReplySuccess("Puja registrada", fmt.Sprintf("Pujaste 💵 %d por **x%d %s**. La subasta termina <t:%d:R>.", listing.CurrentBid, listing.Quantity, listing.ItemName, listing.ExpiresAt.Unix()))
+	return err
+}
+
+func marketCancelCommand(ctx *messagecommands.MessageContext) error {
+	if len(ctx.Args) < 1 {
+		_, err := ctx.ReplyError("Uso Incorrecto", marketUsage)
+		return err
+	}
+
+	listing, err := database.CancelListing(ctx.Message.GuildID, ctx.Args[0], ctx.Message.Author.ID)
+	if err != nil {
+		return replyMarketError(ctx, err)
+	}
+
+	_, err = ctx.ReplySuccess("Publicación retirada", fmt.Sprintf("La publicación `%s` se retiró y los objetos volvieron a tu inventario (la comisión no se devuelve).", listing.ID))
+	return err
+}
+
+func marketListingLine(listing *models.MarketListing) string {
+	return fmt.Sprintf("`%s` %s **%s** x%d · vendedor <@%s> · termina <t:%d:R>\n%s",
+		listing.ID, listing.ItemEmoji, listing.ItemName, listing.Quantity, listing.SellerID, listing.ExpiresAt.Unix(), marketListingPrices(listing))
+}
+
+func marketListingPrices(listing *models.MarketListing) string {
+	var parts []string
+	if listing.BuyNowPrice > 0 {
+		parts = append(parts, fmt.Sprintf("Compra inmediata: 💵 %d", listing.BuyNowPrice))
+	}
+	if listing.AcceptsBids() {
+		if listing.BidderID != "" {
+			parts = append(parts, fmt.Sprintf("Puja actual: 💵 %d (<@%s>) · mínima siguiente 💵 %d", listing.CurrentBid, listing.BidderID, database.MinNextBid(listing)))
+		} else {
+			parts = append(parts, fmt.Sprintf("Puja inicial: 💵 %d", listing.StartingBid))
+		}
+	}
+	return strings.Join(parts, " · ")
+}
+
+// replyMarketError explains a marketplace error; unexpected errors are returned
+func replyMarketError(ctx *messagecommands.MessageContext, err error) error {
+	var text string
+	switch {
+	case errors.Is(err, database.ErrInsufficientFunds):
+		text = "❌ No tienes suficientes monedas locales en la cartera."
+	case errors.Is(err, database.ErrInsufficientItems):
+		text = "❌ No tienes suficientes unidades de ese objeto."
+	case errors.Is(err, database.ErrListingNotFound):
+		text = "❌ No existe una publicación con ese ID en este servidor."
+	case errors.Is(err, database.ErrListingUnavailable):
+		text = "❌ Esa publicación ya no está disponible (vendida, retirada, expirada o sin esa opción)."
+	case errors.Is(err, database.ErrInvalidListing):
+		text = "❌ Indica un precio de compra inmediata, una puja inicial o ambos (la puja debe ser menor que el precio)."
+	case errors.Is(err, database.ErrOwnListing):
+		text = "❌ No puedes comprar ni pujar por tus propias publicaciones."
+	case errors.Is(err, database.ErrBidTooLow):
+		text = "❌ La puja es demasiado baja (o alcanza el precio de compra inmediata: usa `pan!market buy`)."
+	case errors.Is(err, database.ErrListingHasBids):
+		text = "❌ No puedes retirar una publicación que ya tiene pujas."
+	default:
+		_, _ = ctx.ReplyError("Error", "❌ Ocurrió un error en el mercado. Inténtalo más tarde.")
+		return err
+	}
+	_, err = ctx.ReplyError("Error", text)
+	return err
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/PancyStudios/PancyBotGo/internal/messagecommands"
 	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
 )
 
 func payCommand(ctx *messagecommands.MessageContext, isGlobal bool) error {
@@ -48,7 +49,7 @@ func payCommand(ctx *messagecommands.MessageContext, isGlobal bool) error {
 	}
 
 	if !isGlobal {
-		err = database.TransferLocalBalance(guildID, userID, targetUserID, amount)
+		err = database.TransferLocalBalance(guildID, userID, targetUserID, amount, database.TxInfo{Type: models.TransactionTransfer, Command: "pay"})
 		if err != nil {
 			if err == database.ErrInsufficientFunds {
 				_, err = ctx.ReplyError("Error", "❌ No tienes suficientes monedas locales en tu cartera.")
@@ -60,7 +61,7 @@ func payCommand(ctx *messagecommands.MessageContext, isGlobal bool) error {
 		_, err = ctx.ReplySuccess("Transferencia Exitosa", fmt.Sprintf("Has transferido **💵 %d** monedas locales a <@%s>.", amount, targetUserID))
 		return err
 	} else {
-		err = database.TransferStars(userID, targetUserID, amount)
+		err = database.TransferStars(userID, targetUserID, amount, database.TxInfo{Type: models.TransactionTransfer, Command: "pay"})
 		if err != nil {
 			if err == database.ErrInsufficientFunds {
 				_, err = ctx.ReplyError("Error", "❌ No tienes suficientes estrellas en tu cartera.")
@@ -12,11 +12,13 @@ func Register() {
 	messagecommands.RegisterCommand("ecol", "Comandos de economía local", "pan!ecol <comando>", "Economy", func(ctx *messagecommands.MessageContext) error { return ecoRouter(ctx, false) })
 
 	messagecommands.RegisterCommand("shop", "Tienda de objetos", "pan!shop <comando>", "Economy", func(ctx *messagecommands.MessageContext) error { return shopRouter(ctx) })
+	messagecommands.RegisterCommand("market", "Mercado de objetos entre usuarios", "pan!market <list|sell|buy|bid|cancel>", "Economy", func(ctx *messagecommands.MessageContext) error { return marketRouter(ctx) })
+	messagecommands.RegisterCommand("economy", "Configura la economía local del servidor", "pan!economy <view|currency|start|bank|enable|disable|set|message|casino|slots|reset>", "Economy", economyConfigCommand)
 }
 
 func ecoRouter(ctx *messagecommands.MessageContext, isGlobal bool) error {
 	if len(ctx.Args) == 0 {
-		_, err := ctx.ReplyError("Uso Incorrecto", "Debes especificar un comando. Ejemplo: `work`, `balance`, `rob`, `deposit`, `withdraw`, `pay`, `daily`, `weekly`, `crime`, `slut`, `top`")
+		_, err := ctx.ReplyError("Uso Incorrecto", "Debes especificar un comando. Ejemplo: `work`, `balance`, `rob`, `deposit`, `withdraw`, `pay`, `daily`, `weekly`, `crime`, `slut`, `top`, `history`, `audit`, `blackjack`, `slots`, `coinflip`, `roulette`, `duel`")
 		return err
 	}
 
@@ -46,6 +48,24 @@ func ecoRouter(ctx *messagecommands.MessageContext, isGlobal bool) error {
 		return slutCommand(ctx, isGlobal)
 	case "top":
 		return topCommand(ctx, isGlobal)
+	case "history":
+		return historyCommand(ctx, isGlobal)
+	case "blackjack":
+		return blackjackCommand(ctx, isGlobal)
+	case "slots":
+		return slotsCommand(ctx, isGlobal)
+	case "coinflip":
+		return coinflipCommand(ctx, isGlobal)
+	case "roulette":
+		return rouletteCommand(ctx, isGlobal)
+	case "duel":
+		return duelCommand(ctx, isGlobal)
+	case "audit":
+		if isGlobal {
+			_, err := ctx.ReplyError("Comando no disponible", "La auditoría solo está disponible para la economía local: `pan!ecol audit @usuario [horas]`")
+			return err
+		}
+		return auditCommand(ctx)
 	default:
 		_, err := ctx.ReplyError("Comando no encontrado", "Ese comando de economía no existe.")
 		return err
@@ -72,6 +92,9 @@ func shopRouter(ctx *messagecommands.MessageContext) error {
 	case "admin":
 		ctx.Args = ctx.Args[1:]
 		return adminShopCommand(ctx)
+	case "trade":
+		ctx.Args = ctx.Args[1:]
+		return tradeCommand(ctx)
 	default:
 		// view shop with page
 		return shopCommand(ctx)
@@ -2,12 +2,14 @@ package economy
 
 import (
 	"fmt"
-	"math/rand"
 	"strings"
 	"time"
 
 	"github.com/PancyStudios/PancyBotGo/internal/messagecommands"
 	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/ecoconfig"
+	"github.com/PancyStudios/PancyBotGo/pkg/items"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
 )
 
 func robCommand(ctx *messagecommands.MessageContext, isGlobal bool) error {
@@ -42,15 +44,19 @@ func robCommand(ctx *messagecommands.MessageContext, isGlobal bool) error {
 		return err
 	}
 
-	cooldownDuration := 30 * time.Minute
+	rules := ecoconfig.For(guildID, ecoconfig.CommandRob, isGlobal)
+	if !rules.Enabled {
+		_, err := ctx.ReplyError("Comando Desactivado", rules.DisabledText())
+		return err
+	}
 
 	var isReady bool
 	var remaining time.Duration
 
 	if !isGlobal {
-		isReady, remaining, err = database.CooldownLocal(guildID, userID, "rob", cooldownDuration)
+		isReady, remaining, err = database.CooldownLocal(guildID, userID, "rob", rules.Cooldown)
 	} else {
-		isReady, remaining, err = database.CooldownStars(userID, "rob", cooldownDuration)
+		isReady, remaining, err = database.CooldownStars(userID, "rob", rules.Cooldown)
 	}
 
 	if err != nil {
@@ -63,7 +69,7 @@ func robCommand(ctx *messagecommands.MessageContext, isGlobal bool) error {
 		return err
 	}
 
-	success := rand.Float64() < 0.40 // 40% base success rate
+	success := rules.Succeeds()
 
 	if !isGlobal {
 		targetProfile, err := database.GetLocalProfile(guildID, targetUserID)
@@ -71,30 +77,34 @@ func robCommand(ctx *messagecommands.MessageContext, isGlobal bool) error {
 			_, err = ctx.ReplyError("Error", "❌ Ese usuario no tiene dinero que valga la pena robar (Mínimo 100).")
 			return err
 		}
+		if items.Shielded(targetProfile.Buffs) {
+			_, err = ctx.ReplyError("Víctima Protegida", fmt.Sprintf("🛡️ <@%s> tiene un escudo anti-robo activo. Mejor busca otra víctima.", targetUserID))
+			return err
+		}
 		myProfile, _ := database.GetLocalProfile(guildID, userID)
 		if myProfile.Wallet < 100 {
-			_, err = ctx.ReplyError("Error", "❌ Necesitas al menos 100 monedas locales en tu cartera para cubrir posibles fianzas.")
+			_, err = ctx.ReplyError("Error", fmt.Sprintf("❌ Necesitas al menos 100 %s en tu cartera para cubrir posibles fianzas.", rules.Currency.Name))
 			return err
 		}
 
 		_ = database.SetCooldownLocal(guildID, userID, "rob")
 
 		if success {
-			percent := (rand.Float64() * 0.3) + 0.1
-			stolen := int64(float64(targetProfile.Wallet) * percent)
+			stolen := targetProfile.Wallet * rules.Payout() / 100
 
-			database.AddLocalBalance(guildID, targetUserID, -stolen, false)
-			database.AddLocalBalance(guildID, userID, stolen, false)
-			_, err = ctx.ReplySuccess("¡Robo Exitoso!", fmt.Sprintf("🦹 ¡Éxito! Le robaste **💵 %d monedas** a <@%s>.", stolen, targetUserID))
+			if err := database.TransferLocalBalance(guildID, targetUserID, userID, stolen, database.TxInfo{Type: models.TransactionRob, Command: "rob"}); err != nil {
+				_, err = ctx.ReplyError("Error", "❌ Tu víctima ya no tenía ese dinero en la cartera. El golpe salió mal.")
+				return err
+			}
+			_, err = ctx.ReplySuccess("¡Robo Exitoso!", fmt.Sprintf("🦹 ¡Éxito! Le robaste **%s** a <@%s>.", rules.Currency.Format(stolen), targetUserID))
 			return err
 		} else {
-			fine := int64(float64(myProfile.Wallet) * 0.25)
+			fine := myProfile.Wallet * rules.Fine() / 100
 			if fine < 10 {
 				fine = 10
 			}
-			database.AddLocalBalance(guildID, userID, -fine, false)
-			database.AddLocalBalance(guildID, targetUserID, fine, false)
-			_, err = ctx.ReplyError("¡Atrapado!", fmt.Sprintf("🚔 ¡Te atraparon intentando robarle a <@%s>! Tuviste que pagarle **💵 %d monedas** como multa.", targetUserID, fine))
+			_ = database.TransferLocalBalance(guildID, userID, targetUserID, fine, database.TxInfo{Type: models.TransactionFine, Command: "rob"})
+			_, err = ctx.ReplyError("¡Atrapado!", fmt.Sprintf("🚔 ¡Te atraparon intentando robarle a <@%s>! Tuviste que pagarle **%s** como multa.", targetUserID, rules.Currency.Format(fine)))
 			return err
 		}
 	} else {
@@ -103,6 +113,10 @@ func robCommand(ctx *messagecommands.MessageContext, isGlobal bool) error {
 			_, err = ctx.ReplyError("Error", "❌ Ese usuario no tiene estrellas suficientes en la cartera (Mínimo 100).")
 			return err
 		}
+		if items.Shielded(targetProfile.Buffs) {
+			_, err = ctx.ReplyError("Víctima Protegida", fmt.Sprintf("🛡️ <@%s> tiene un escudo anti-robo activo. Mejor busca otra víctima.", targetUserID))
+			return err
+		}
 		myProfile, _ := database.GetGlobalProfile(userID)
 		if myProfile.StarsWallet < 100 {
 			_, err = ctx.ReplyError("Error", "❌ Necesitas al menos 100 estrellas en tu cartera para cubrir posibles fianzas.")
@@ -112,20 +126,20 @@ func robCommand(ctx *messagecommands.MessageContext, isGlobal bool) error {
 		_ = database.SetCooldownStars(userID, "rob")
 
 		if success {
-			percent := (rand.Float64() * 0.3) + 0.1
-			stolen := int64(float64(targetProfile.StarsWallet) * percent)
+			stolen := targetProfile.StarsWallet * rules.Payout() / 100
 
-			database.AddStars(targetUserID, -stolen, false)
-			database.AddStars(userID, stolen, false)
+			if err := database.TransferStars(targetUserID, userID, stolen, database.TxInfo{Type: models.TransactionRob, Command: "rob"}); err != nil {
+				_, err = ctx.ReplyError("Error", "❌ Tu víctima ya no tenía esas estrellas en la cartera. El golpe salió mal.")
+				return err
+			}
 			_, err = ctx.ReplySuccess("¡Robo Exitoso!", fmt.Sprintf("🦹 ¡Éxito! Le robaste **🌟 %d estrellas** a <@%s>.", stolen, targetUserID))
 			return err
 		} else {
-			fine := int64(float64(myProfile.StarsWallet) * 0.25)
+			fine := myProfile.StarsWallet * rules.Fine() / 100
 			if fine < 10 {
 				fine = 10
 			}
-			database.AddStars(userID, -fine, false)
-			database.AddStars(targetUserID, fine, false)
+			_ = database.TransferStars(userID, targetUserID, fine, database.TxInfo{Type: models.TransactionFine, Command: "rob"})
 			_, err = ctx.ReplyError("¡Atrapado!", fmt.Sprintf("🚔 ¡Te atraparon robando estrellas de <@%s>! Fuiste multado por **🌟 %d estrellas**, las cuales se le entregaron a tu víctima.", targetUserID, fine))
 			return err
 		}
@@ -2,11 +2,12 @@ package economy
 
 import (
 	"fmt"
-	"math/rand"
 	"time"
 
 	"github.com/PancyStudios/PancyBotGo/internal/messagecommands"
 	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/ecoconfig"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
 )
 
 func slutCommand(ctx *messagecommands.MessageContext, isGlobal bool) error {
@@ -14,16 +15,20 @@ func slutCommand(ctx *messagecommands.MessageContext, isGlobal bool) error {
 	userID := ctx.Message.Author.ID
 	guildID := ctx.Message.GuildID
 
-	cooldownDuration := 15 * time.Minute
+	rules := ecoconfig.For(guildID, ecoconfig.CommandSlut, isGlobal)
+	if !rules.Enabled {
+		_, err := ctx.ReplyError("Comando Desactivado", rules.DisabledText())
+		return err
+	}
 
 	var isReady bool
 	var remaining time.Duration
 	var err error
 
 	if !isGlobal {
-		isReady, remaining, err = database.CooldownLocal(guildID, userID, "slut", cooldownDuration)
+		isReady, remaining, err = database.CooldownLocal(guildID, userID, "slut", rules.Cooldown)
 	} else {
-		isReady, remaining, err = database.CooldownStars(userID, "slut", cooldownDuration)
+		isReady, remaining, err = database.CooldownStars(userID, "slut", rules.Cooldown)
 	}
 
 	if err != nil {
@@ -36,34 +41,34 @@ func slutCommand(ctx *messagecommands.MessageContext, isGlobal bool) error {
 		return err
 	}
 
-	success := rand.Float64() < 0.60
+	success := rules.Succeeds()
 
 	if !isGlobal {
 		_ = database.SetCooldownLocal(guildID, userID, "slut")
 
 		if success {
-			amount := int64(rand.Intn(500) + 100)
-			database.AddLocalBalance(guildID, userID, amount, false)
-			_, err = ctx.ReplySuccess("Trabajo Terminado", fmt.Sprintf("💋 Te fue excelente en la esquina y te pagaron **💵 %d monedas**.", amount))
+			amount := rules.Payout()
+			database.AddLocalBalance(guildID, userID, amount, false, database.TxInfo{Type: models.TransactionEarn, Command: "slut"})
+			_, err = ctx.ReplySuccess("Trabajo Terminado", rules.Success("💋 Te fue excelente en la esquina y te pagaron **{amount}**.", amount))
 			return err
 		} else {
-			fine := int64(rand.Intn(100) + 50)
-			database.AddLocalBalance(guildID, userID, -fine, false)
-			_, err = ctx.ReplyError("Atrapado", fmt.Sprintf("🚔 Te asaltaron en el callejón. Perdiste **💵 %d monedas**.", fine))
+			fine := rules.Fine()
+			database.AddLocalBalance(guildID, userID, -fine, false, database.TxInfo{Type: models.TransactionPenalty, Command: "slut"})
+			_, err = ctx.ReplyError("Atrapado", rules.Failure("🚔 Te asaltaron en el callejón. Perdiste **{amount}**.", fine))
 			return err
 		}
 	} else {
 		_ = database.SetCooldownStars(userID, "slut")
 
 		if success {
-			amount := int64(rand.Intn(400) + 100)
-			database.AddStars(userID, amount, false)
-			_, err = ctx.ReplySuccess("Trabajo Terminado", fmt.Sprintf("💋 Conseguiste un Sugar Alien que te donó **🌟 %d estrellas**.", amount))
+			amount := rules.Payout()
+			database.AddStars(userID, amount, false, database.TxInfo{Type: models.TransactionEarn, Command: "slut"})
+			_, err = ctx.ReplySuccess("Trabajo Terminado", rules.Success("💋 Conseguiste un Sugar Alien que te donó **{amount}**.", amount))
 			return err
 		} else {
-			fine := int64(rand.Intn(100) + 20)
-			database.AddStars(userID, -fine, false)
-			_, err = ctx.ReplyError("Atrapado", fmt.Sprintf("🚔 Te arrestó la patrulla del espacio por exhibicionismo. Pagaste **🌟 %d estrellas** de multa.", fine))
+			fine := rules.Fine()
+			database.AddStars(userID, -fine, false, database.TxInfo{Type: models.TransactionPenalty, Command: "slut"})
+			_, err = ctx.ReplyError("Atrapado", rules.Failure("🚔 Te arrestó la patrulla del espacio por exhibicionismo. Pagaste **{amount}** de multa.", fine))
 			return err
 		}
 	}
@@ -0,0 +1,41 @@
+package economy
+
+import (
+	"errors"
+
+	"github.com/PancyStudios/PancyBotGo/internal/messagecommands"
+	"github.com/PancyStudios/PancyBotGo/pkg/trade"
+)
+
+func tradeCommand(ctx *messagecommands.MessageContext) error {
+	partnerID := ctx.ParseUser(0)
+	if partnerID == "" {
+		_, err := ctx.ReplyError("Uso Incorrecto", "Debes especificar un usuario.\nUso: `pan!shop trade @usuario`")
+		return err
+	}
+	if partnerID == ctx.Message.Author.ID {
+		_, err := ctx.ReplyError("Error", "❌ No puedes intercambiar contigo mismo.")
+		return err
+	}
+
+	partner, err := ctx.Session.User(partnerID)
+	if err != nil {
+		_, err = ctx.ReplyError("Error", "❌ No se encontró a ese usuario.")
+		return err
+	}
+	if partner.Bot {
+		_, err := ctx.ReplyError("Error", "❌ Los bots no tienen inventario.")
+		return err
+	}
+
+	_, err = trade.Open(ctx.Session, ctx.Message.GuildID, ctx.Message.ChannelID, ctx.Message.Author, partner)
+	if errors.Is(err, trade.ErrAlreadyTrading) {
+		_, err = ctx.ReplyError("Error", "❌ Tú o ese usuario ya tenéis un intercambio abierto.")
+		return err
+	}
+	if err != nil {
+		_, _ = ctx.ReplyError("Error", "❌ No se pudo abrir el intercambio.")
+		return err
+	}
+	return nil
+}
@@ -1,12 +1,13 @@
 package economy
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/PancyStudios/PancyBotGo/internal/messagecommands"
 	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/items"
 	"github.com/PancyStudios/PancyBotGo/pkg/models"
-	"go.mongodb.org/mongo-driver/bson"
 )
 
 func useCommand(ctx *messagecommands.MessageContext) error {
@@ -19,14 +20,14 @@ func useCommand(ctx *messagecommands.MessageContext) error {
 	userID := ctx.Message.Author.ID
 	guildID := ctx.Message.GuildID
 
-	items, err := database.GetItems(guildID)
+	catalog, err := database.GetItems(guildID)
 	if err != nil {
 		_, err = ctx.ReplyError("Error", "❌ Error al cargar los objetos.")
 		return err
 	}
 
 	var selectedItem *models.Item
-	for _, it := range items {
+	for _, it := range catalog {
 		if it.ID == itemID {
 			copyIt := it
 			selectedItem = &copyIt
@@ -39,65 +40,25 @@ func useCommand(ctx *messagecommands.MessageContext) error {
 		return err
 	}
 
-	if selectedItem.IsGlobal {
-		profile, _ := database.GetGlobalProfile(userID)
-		qty := profile.Inventory[selectedItem.ID]
-		if qty <= 0 {
-			_, err = ctx.ReplyError("Error", "❌ No tienes ese objeto en tu inventario global.")
-			return err
-		}
-
-		profile.Inventory[selectedItem.ID] -= 1
-		if profile.Inventory[selectedItem.ID] == 0 {
-			delete(profile.Inventory, selectedItem.ID)
-		}
-
-		efectoAplicado := false
-		if selectedItem.Effect == "EXPAND_BANK" {
-			profile.BankCapacity += int64(selectedItem.EffectValue)
-			efectoAplicado = true
-		} else if selectedItem.Effect == "STAR_TICKET" {
-			profile.StarsWallet += int64(selectedItem.EffectValue)
-			efectoAplicado = true
-		}
-
-		database.GlobalEconomyDM.Set(bson.M{"_id": profile.UserID}, profile)
-
-		if efectoAplicado {
-			_, err = ctx.ReplySuccess("Objeto Usado", fmt.Sprintf("✨ Has usado **%s**. ¡Tu capacidad estelar o balance ha mejorado!", selectedItem.Name))
-			return err
-		}
-
-	} else {
-		profile, _ := database.GetLocalProfile(guildID, userID)
-		qty := profile.Inventory[selectedItem.ID]
-		if qty <= 0 {
-			_, err = ctx.ReplyError("Error", "❌ No tienes ese objeto en tu inventario local.")
-			return err
-		}
-
-		profile.Inventory[selectedItem.ID] -= 1
-		if profile.Inventory[selectedItem.ID] == 0 {
-			delete(profile.Inventory, selectedItem.ID)
-		}
-
-		efectoAplicado := false
-		if selectedItem.Effect == "EXPAND_BANK" {
-			profile.BankCapacity += int64(selectedItem.EffectValue)
-			efectoAplicado = true
-		} else if selectedItem.Effect == "LOCAL_TICKET" {
-			profile.Wallet += int64(selectedItem.EffectValue)
-			efectoAplicado = true
-		}
-
-		database.LocalEconomyDM.Set(bson.M{"_id": profile.ID}, profile)
-
-		if efectoAplicado {
-			_, err = ctx.ReplySuccess("Objeto Usado", fmt.Sprintf("✨ Has usado **%s**. ¡Tu capacidad del banco local o cartera ha mejorado!", selectedItem.Name))
-			return err
+	// The item is consumed together with its effect in a single ledger entry
+	use := &items.Use{Session: ctx.Session, GuildID: guildID, UserID: userID, Item: *selectedItem}
+	text, err := use.Apply()
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrInsufficientItems):
+			_, err = ctx.ReplyError("Error", "❌ No tienes ese objeto en tu inventario.")
+		case errors.Is(err, items.ErrPassive):
+			_, err = ctx.ReplyError("Objeto Pasivo", fmt.Sprintf("ℹ️ **%s** no se usa: funciona solo mientras lo tengas en tu inventario.", selectedItem.Name))
+		case errors.Is(err, items.ErrRoleGrant):
+			_, err = ctx.ReplyError("Error", "❌ No pude darte el rol asociado a este objeto. Revisa mis permisos.")
+		case errors.Is(err, items.ErrWrongScope):
+			_, err = ctx.ReplyError("Error", "❌ Este objeto no se puede usar aquí.")
+		default:
+			_, _ = ctx.ReplyError("Error", "❌ Error al usar el objeto.")
 		}
+		return err
 	}
 
-	_, err = ctx.ReplySuccess("Objeto Usado", fmt.Sprintf("✨ Has usado **%s**, pero no tiene un efecto especial programado.", selectedItem.Name))
+	_, err = ctx.ReplySuccess("Objeto Usado", text)
 	return err
 }
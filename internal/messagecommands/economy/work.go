@@ -2,11 +2,13 @@ package economy
 
 import (
 	"fmt"
-	"math/rand"
 	"time"
 
 	"github.com/PancyStudios/PancyBotGo/internal/messagecommands"
 	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/ecoconfig"
+	"github.com/PancyStudios/PancyBotGo/pkg/items"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
 )
 
 func workCommand(ctx *messagecommands.MessageContext, isGlobal bool) error {
@@ -14,16 +16,20 @@ func workCommand(ctx *messagecommands.MessageContext, isGlobal bool) error {
 	userID := ctx.Message.Author.ID
 	guildID := ctx.Message.GuildID
 
-	cooldownDuration := 5 * time.Minute
+	rules := ecoconfig.For(guildID, ecoconfig.CommandWork, isGlobal)
+	if !rules.Enabled {
+		_, err := ctx.ReplyError("Comando Desactivado", rules.DisabledText())
+		return err
+	}
 
 	var isReady bool
 	var remaining time.Duration
 	var err error
 
 	if !isGlobal {
-		isReady, remaining, err = database.CooldownLocal(guildID, userID, "work", cooldownDuration)
+		isReady, remaining, err = database.CooldownLocal(guildID, userID, "work", rules.Cooldown)
 	} else {
-		isReady, remaining, err = database.CooldownStars(userID, "work", cooldownDuration)
+		isReady, remaining, err = database.CooldownStars(userID, "work", rules.Cooldown)
 	}
 
 	if err != nil {
@@ -36,27 +42,37 @@ func workCommand(ctx *messagecommands.MessageContext, isGlobal bool) error {
 		return err
 	}
 
-	amount := int64(rand.Intn(200) + 50)
+	gear, err := items.LoadGear(guildID, userID, isGlobal, items.EffectWorkTool)
+	if err != nil {
+		_, _ = ctx.ReplyError("Error", "❌ Error al revisar tu equipo.")
+		return err
+	}
+	if gear.Missing != nil {
+		_, err = ctx.ReplyError("Herramienta Necesaria", gear.MissingText("trabajar"))
+		return err
+	}
+
+	amount := gear.Boost(rules.Payout(), items.EffectWorkBoost)
 
 	if !isGlobal {
-		_, err = database.AddLocalBalance(guildID, userID, amount, false)
+		_, err = database.AddLocalBalance(guildID, userID, amount, false, database.TxInfo{Type: models.TransactionEarn, Command: "work"})
 		if err != nil {
 			_, err = ctx.ReplyError("Error", "❌ Error al procesar el pago local.")
 			return err
 		}
 		_ = database.SetCooldownLocal(guildID, userID, "work")
 
-		_, err = ctx.ReplySuccess("Trabajo Terminado", fmt.Sprintf("Has trabajado duro y ganaste **💵 %d monedas locales**.", amount))
+		_, err = ctx.ReplySuccess("Trabajo Terminado", rules.Success("Has trabajado duro y ganaste **{amount}**.", amount)+gear.Note(items.EffectWorkBoost))
 		return err
 	} else {
-		_, err = database.AddStars(userID, amount, false)
+		_, err = database.AddStars(userID, amount, false, database.TxInfo{Type: models.TransactionEarn, Command: "work"})
 		if err != nil {
 			_, err = ctx.ReplyError("Error", "❌ Error al procesar el pago global.")
 			return err
 		}
 		_ = database.SetCooldownStars(userID, "work")
 
-		_, err = ctx.ReplySuccess("Trabajo Terminado", fmt.Sprintf("Hiciste un viaje espacial y minaste **🌟 %d estrellas**.", amount))
+		_, err = ctx.ReplySuccess("Trabajo Terminado", rules.Success("Hiciste un viaje espacial y minaste **{amount}**.", amount)+gear.Note(items.EffectWorkBoost))
 		return err
 	}
 }
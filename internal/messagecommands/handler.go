@@ -1,10 +1,13 @@
 package messagecommands
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/tracing"
 	"github.com/bwmarrin/discordgo"
 )
 
@@ -13,16 +16,25 @@ type MessageContext struct {
 	Session *discordgo.Session
 	Message *discordgo.MessageCreate
 	Args    []string
+	ctx     context.Context
+}
+
+// Context returns the context of the command run, carrying its tracing span
+func (ctx *MessageContext) Context() context.Context {
+	if ctx.ctx == nil {
+		return context.Background()
+	}
+	return ctx.ctx
 }
 
 // Reply sends a simple text response
 func (ctx *MessageContext) Reply(content string) (*discordgo.Message, error) {
-	return ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, content)
+	return ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, content, discordgo.WithContext(ctx.Context()))
 }
 
 // ReplyEmbed sends an embed response
 func (ctx *MessageContext) ReplyEmbed(embed *discordgo.MessageEmbed) (*discordgo.Message, error) {
-	return ctx.Session.ChannelMessageSendEmbed(ctx.Message.ChannelID, embed)
+	return ctx.Session.ChannelMessageSendEmbed(ctx.Message.ChannelID, embed, discordgo.WithContext(ctx.Context()))
 }
 
 // ReplyError sends an error embed response
@@ -125,13 +137,25 @@ func Handle(s *discordgo.Session, m *discordgo.MessageCreate, commandName string
 		return nil
 	}
 
+	traceCtx, span := tracing.Start(context.Background(), "command "+cmd.Name, tracing.KindServer,
+		tracing.String("command.name", cmd.Name),
+		tracing.String("command.kind", discord.CommandKindPrefix),
+		tracing.String("discord.guild_id", m.GuildID),
+		tracing.String("discord.user_id", m.Author.ID),
+	)
+	defer span.End()
+
 	ctx := &MessageContext{
 		Session: s,
 		Message: m,
 		Args:    args,
+		ctx:     traceCtx,
 	}
 
+	start := time.Now()
 	err := cmd.Run(ctx)
+	discord.ObserveCommand(cmd.Name, discord.CommandKindPrefix, start, err)
+	span.RecordError(err)
 	if err != nil {
 		ctx.ReplyError("Error al ejecutar", fmt.Sprintf("Hubo un error interno:\n```\n%v\n```", err))
 	}
@@ -40,7 +40,7 @@ func createImageCommand(ctx *messagecommands.MessageContext) error {
 	}
 	jsonData, _ := json.Marshal(reqBody)
 
-	req, err := http.NewRequest("POST", modelUrl, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx.Context(), "POST", modelUrl, bytes.NewBuffer(jsonData))
 	if err != nil {
 		ctx.Session.ChannelMessageDelete(loadingMsg.ChannelID, loadingMsg.ID)
 		_, err = ctx.ReplyError("Error", fmt.Sprintf("Error interno: %v", err))
@@ -0,0 +1,185 @@
+package invites
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/PancyStudios/PancyBotGo/internal/messagecommands"
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/invites"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func configCommand(ctx *messagecommands.MessageContext) error {
+	if !ctx.HasPermission(discordgo.PermissionManageGuild) {
+		_, err := ctx.ReplyError("Acceso Denegado", "Necesitas el permiso de Gestionar Servidor.")
+		return err
+	}
+
+	if len(ctx.Args) == 0 {
+		_, err := ctx.ReplyError("Uso Incorrecto", "Uso: `pan!inviteconfig <on/off> [#canal] [dias]`")
+		return err
+	}
+
+	guildID := ctx.Message.GuildID
+	guildData, err := database.GlobalGuildDM.Get(bson.M{"id": guildID})
+	if err != nil {
+		_, err = ctx.ReplyError("Error", fmt.Sprintf("❌ Error obteniendo configuración: %v", err))
+		return err
+	}
+	if guildData == nil {
+		guildData = models.NewDefaultGuildDocument(guildID)
+	}
+
+	cfg := &guildData.Invites
+	state := strings.ToLower(ctx.Args[0])
+	cfg.Enable = state == "on" || state == "enable" || state == "true"
+
+	for i := 1; i < len(ctx.Args); i++ {
+		if channelID := ctx.ParseChannel(i); channelID != "" {
+			cfg.LogChannel = channelID
+		} else if days, err := strconv.Atoi(ctx.Args[i]); err == nil && days >= 0 {
+			cfg.FakeAccountDays = days
+		}
+	}
+
+	if _, err = database.GlobalGuildDM.Set(bson.M{"id": guildID}, guildData); err != nil {
+		_, err = ctx.ReplyError("Error", fmt.Sprintf("❌ Error guardando configuración: %v", err))
+		return err
+	}
+
+	status := "desactivado"
+	if cfg.Enable {
+		status = "activado"
+		if err := invites.Load(ctx.Session, guildID); err != nil {
+			logger.Warn(fmt.Sprintf("No se pudieron cargar las invitaciones de %s: %v", guildID, err), "Invites")
+			status += ". ⚠️ No pude leer las invitaciones, necesito el permiso **Gestionar Servidor**"
+		}
+	}
+
+	_, err = ctx.ReplySuccess("Invitaciones", fmt.Sprintf("✅ Seguimiento de invitaciones **%s**.", status))
+	return err
+}
+
+func rewardsCommand(ctx *messagecommands.MessageContext) error {
+	if !ctx.HasPermission(discordgo.PermissionAdministrator) {
+		_, err := ctx.ReplyError("Acceso Denegado", "No tienes permiso de Administrador.")
+		return err
+	}
+
+	if len(ctx.Args) == 0 {
+		_, err := ctx.ReplyError("Uso Incorrecto", "Uso: `pan!invitereward <add/remove/list> [invitaciones] [@rol]`")
+		return err
+	}
+
+	guildID := ctx.Message.GuildID
+	guildData, err := database.GlobalGuildDM.Get(bson.M{"id": guildID})
+	if err != nil {
+		_, err = ctx.ReplyError("Error", fmt.Sprintf("❌ Error al obtener la configuración del servidor: %v", err))
+		return err
+	}
+	if guildData == nil {
+		guildData = models.NewDefaultGuildDocument(guildID)
+	}
+
+	switch strings.ToLower(ctx.Args[0]) {
+	case "add":
+		if len(ctx.Args) < 3 {
+			_, err = ctx.ReplyError("Uso Incorrecto", "Uso: `pan!invitereward add <invitaciones> <@rol>`")
+			return err
+		}
+		count, err := strconv.ParseInt(ctx.Args[1], 10, 64)
+		if err != nil || count < 1 {
+			_, err = ctx.ReplyError("Error", "❌ El número de invitaciones debe ser un número mayor que 0.")
+			return err
+		}
+		roleID := ctx.ParseRole(2)
+		if roleID == "" {
+			_, err = ctx.ReplyError("Error", "❌ Debes especificar un rol válido.")
+			return err
+		}
+
+		exists := false
+		for i, r := range guildData.Invites.Rewards {
+			if r.Invites == count {
+				guildData.Invites.Rewards[i].RoleID = roleID
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			guildData.Invites.Rewards = append(guildData.Invites.Rewards, models.InviteReward{Invites: count, RoleID: roleID})
+		}
+
+		if _, err = database.GlobalGuildDM.Set(bson.M{"id": guildID}, guildData); err != nil {
+			_, err = ctx.ReplyError("Error", "❌ Error al guardar la configuración.")
+			return err
+		}
+		_, err = ctx.ReplySuccess("Recompensa Configurada", fmt.Sprintf("Al llegar a **%d** invitaciones, se entregará el rol <@&%s>.", count, roleID))
+		return err
+
+	case "remove":
+		if len(ctx.Args) < 2 {
+			_, err = ctx.ReplyError("Uso Incorrecto", "Uso: `pan!invitereward remove <invitaciones>`")
+			return err
+		}
+		count, err := strconv.ParseInt(ctx.Args[1], 10, 64)
+		if err != nil {
+			_, err = ctx.ReplyError("Error", "❌ El número de invitaciones debe ser un número válido.")
+			return err
+		}
+
+		found := false
+		rewards := make([]models.InviteReward, 0, len(guildData.Invites.Rewards))
+		for _, r := range guildData.Invites.Rewards {
+			if r.Invites == count {
+				found = true
+			} else {
+				rewards = append(rewards, r)
+			}
+		}
+		if !found {
+			_, err = ctx.ReplyError("Error", fmt.Sprintf("❌ No hay ninguna recompensa configurada para %d invitaciones.", count))
+			return err
+		}
+
+		guildData.Invites.Rewards = rewards
+		if _, err = database.GlobalGuildDM.Set(bson.M{"id": guildID}, guildData); err != nil {
+			_, err = ctx.ReplyError("Error", "❌ Error al guardar la configuración.")
+			return err
+		}
+		_, err = ctx.ReplySuccess("Recompensa Eliminada", fmt.Sprintf("Recompensa de **%d** invitaciones eliminada.", count))
+		return err
+
+	case "list":
+		if len(guildData.Invites.Rewards) == 0 {
+			_, err = ctx.Reply("📉 No hay recompensas de invitaciones configuradas en este servidor.")
+			return err
+		}
+
+		rewards := append([]models.InviteReward(nil), guildData.Invites.Rewards...)
+		sort.Slice(rewards, func(i, j int) bool { return rewards[i].Invites < rewards[j].Invites })
+
+		listDesc := ""
+		for _, r := range rewards {
+			listDesc += fmt.Sprintf("📨 **%d invitaciones**: <@&%s>\n", r.Invites, r.RoleID)
+		}
+
+		_, err = ctx.ReplyEmbed(discord.NewEmbed().
+			SetTitle("🎁 Recompensas de Invitaciones").
+			SetDescription(listDesc).
+			SetColor(0x22d3ee).
+			Build())
+		return err
+
+	default:
+		_, err = ctx.ReplyError("Uso Incorrecto", "Uso: `pan!invitereward <add/remove/list> [invitaciones] [@rol]`")
+		return err
+	}
+}
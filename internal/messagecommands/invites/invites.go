@@ -0,0 +1,98 @@
+package invites
+
+import (
+	"fmt"
+
+	"github.com/PancyStudios/PancyBotGo/internal/messagecommands"
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func invitesCommand(ctx *messagecommands.MessageContext) error {
+	guildID := ctx.Message.GuildID
+
+	guildData, err := database.GlobalGuildDM.Get(bson.M{"id": guildID})
+	if err != nil || guildData == nil || !guildData.Invites.Enable {
+		_, err = ctx.ReplyError("Error", "❌ El seguimiento de invitaciones está desactivado en este servidor.")
+		return err
+	}
+
+	target := ctx.Message.Author
+	if len(ctx.Args) > 0 {
+		if userID := ctx.ParseUser(0); userID != "" {
+			if member, err := ctx.Session.GuildMember(guildID, userID); err == nil {
+				target = member.User
+			}
+		}
+	}
+
+	stats, err := database.GetInviteStats(guildID, target.ID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error obteniendo invitaciones de %s: %v", target.ID, err), "Invites")
+		_, err = ctx.ReplyError("Error", "❌ Ocurrió un error al obtener las invitaciones.")
+		return err
+	}
+
+	embed := discord.NewEmbed().
+		SetTitle(fmt.Sprintf("📨 Invitaciones de %s", target.Username)).
+		SetThumbnail(target.AvatarURL("128")).
+		SetColor(discord.ColorInfo).
+		SetDescription(fmt.Sprintf("Tiene **%d** invitaciones válidas.", stats.Total())).
+		AddField("✅ Entradas", fmt.Sprintf("%d", stats.Joins), true).
+		AddField("👋 Salidas", fmt.Sprintf("%d", stats.Leaves), true).
+		AddField("⚠️ Falsas", fmt.Sprintf("%d", stats.Fakes), true)
+
+	if join, err := database.GetInviteJoin(guildID, target.ID); err == nil && join != nil && join.InviterID != "" {
+		embed.AddField("🔗 Invitado por", fmt.Sprintf("<@%s>", join.InviterID), false)
+	}
+
+	_, err = ctx.ReplyEmbed(embed.Build())
+	return err
+}
+
+func leaderboardCommand(ctx *messagecommands.MessageContext) error {
+	guildID := ctx.Message.GuildID
+
+	guildData, err := database.GlobalGuildDM.Get(bson.M{"id": guildID})
+	if err != nil || guildData == nil || !guildData.Invites.Enable {
+		_, err = ctx.ReplyError("Error", "❌ El seguimiento de invitaciones está desactivado en este servidor.")
+		return err
+	}
+
+	top, err := database.GetTopInviters(guildID, 10)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error obteniendo leaderboard de invitaciones para %s: %v", guildID, err), "Invites")
+		_, err = ctx.ReplyError("Error", "❌ Ocurrió un error al obtener la clasificación.")
+		return err
+	}
+
+	if len(top) == 0 {
+		_, err = ctx.Reply("📉 Aún nadie ha invitado a nadie en este servidor.")
+		return err
+	}
+
+	description := ""
+	for i, stats := range top {
+		medal := "🏅"
+		switch i {
+		case 0:
+			medal = "🥇"
+		case 1:
+			medal = "🥈"
+		case 2:
+			medal = "🥉"
+		}
+		description += fmt.Sprintf("%s **#%d** <@%s> - %d invitaciones (%d entradas, %d salidas, %d falsas)\n",
+			medal, i+1, stats.UserID, stats.Total(), stats.Joins, stats.Leaves, stats.Fakes)
+	}
+
+	_, err = ctx.ReplyEmbed(&discordgo.MessageEmbed{
+		Title:       "🏆 Tabla de Clasificación de Invitaciones",
+		Description: description,
+		Color:       0xFFD700, // Gold
+	})
+	return err
+}
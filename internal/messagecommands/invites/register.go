@@ -0,0 +1,12 @@
+package invites
+
+import (
+	"github.com/PancyStudios/PancyBotGo/internal/messagecommands"
+)
+
+func RegisterAll() {
+	messagecommands.RegisterCommand("invites", "Muestra las invitaciones de un usuario", "pan!invites [@usuario]", "Invites", invitesCommand)
+	messagecommands.RegisterCommand("invitestop", "Clasificación de invitaciones", "pan!invitestop", "Invites", leaderboardCommand)
+	messagecommands.RegisterCommand("invitereward", "Recompensas por invitaciones", "pan!invitereward <add/remove/list> [invitaciones] [@rol]", "Invites", rewardsCommand)
+	messagecommands.RegisterCommand("inviteconfig", "Configura el seguimiento de invitaciones", "pan!inviteconfig <on/off> [#canal] [dias]", "Invites", configCommand)
+}
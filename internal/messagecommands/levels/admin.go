@@ -0,0 +1,106 @@
+package levels
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/PancyStudios/PancyBotGo/internal/messagecommands"
+	"github.com/PancyStudios/PancyBotGo/pkg/leveladmin"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/bwmarrin/discordgo"
+)
+
+const xpAdminUsage = "Uso: `pan!xpadmin <set-xp|add-xp|set-level> <@usuario> <valor>`, `pan!xpadmin reset <@usuario>`, `pan!xpadmin reset-all`, `pan!xpadmin export [csv|json]` o `pan!xpadmin import` adjuntando un CSV o JSON"
+
+func xpAdminCommand(ctx *messagecommands.MessageContext) error {
+	if !ctx.HasPermission(discordgo.PermissionAdministrator) {
+		_, err := ctx.ReplyError("Acceso Denegado", "No tienes permiso de Administrador.")
+		return err
+	}
+	if len(ctx.Args) == 0 {
+		_, err := ctx.ReplyError("Uso Incorrecto", xpAdminUsage)
+		return err
+	}
+
+	guildID := ctx.Message.GuildID
+	action := leveladmin.Action{GuildID: guildID, AdminID: ctx.Message.Author.ID}
+	switch strings.ToLower(ctx.Args[0]) {
+	case leveladmin.ActionSetXP, leveladmin.ActionAddXP, leveladmin.ActionSetLevel:
+		if len(ctx.Args) < 3 {
+			_, err := ctx.ReplyError("Uso Incorrecto", xpAdminUsage)
+			return err
+		}
+		value, err := strconv.ParseInt(ctx.Args[2], 10, 64)
+		if err != nil {
+			_, err = ctx.ReplyError("Error", "❌ El valor debe ser un número entero.")
+			return err
+		}
+		action.Kind, action.UserID, action.Value = strings.ToLower(ctx.Args[0]), ctx.ParseUser(1), value
+
+	case leveladmin.ActionReset:
+		action.Kind, action.UserID = leveladmin.ActionReset, ctx.ParseUser(1)
+
+	case leveladmin.ActionResetAll:
+		action.Kind = leveladmin.ActionResetAll
+
+	case "export":
+		format := leveladmin.FormatCSV
+		if len(ctx.Args) > 1 {
+			format = strings.ToLower(ctx.Args[1])
+		}
+		file, total, err := leveladmin.ExportFile(guildID, format)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Error exportando niveles de %s: %v", guildID, err), "Levels")
+			_, err = ctx.ReplyError("Error", leveladmin.ErrorText(err))
+			return err
+		}
+		_, err = ctx.Session.ChannelMessageSendComplex(ctx.Message.ChannelID, &discordgo.MessageSend{
+			Content:   fmt.Sprintf("📤 Experiencia de **%d** usuarios.", total),
+			Files:     []*discordgo.File{file},
+			Reference: ctx.Message.Reference(),
+		})
+		return err
+
+	case leveladmin.ActionImport:
+		if len(ctx.Message.Attachments) == 0 {
+			_, err := ctx.ReplyError("Uso Incorrecto", "Adjunta un archivo CSV o JSON con columnas `user_id` y `xp` (o `level`).")
+			return err
+		}
+		attachment := ctx.Message.Attachments[0]
+		if attachment.Size > leveladmin.MaxImportSize {
+			_, err := ctx.ReplyError("Error", leveladmin.ErrorText(leveladmin.ErrFileTooBig))
+			return err
+		}
+		data, err := leveladmin.Download(attachment.URL)
+		if err == nil {
+			action.Records, err = leveladmin.Decode(data)
+		}
+		if err != nil {
+			_, err = ctx.ReplyError("Error", leveladmin.ErrorText(err))
+			return err
+		}
+		action.Kind = leveladmin.ActionImport
+
+	default:
+		_, err := ctx.ReplyError("Uso Incorrecto", xpAdminUsage)
+		return err
+	}
+
+	if action.Kind != leveladmin.ActionResetAll && action.Kind != leveladmin.ActionImport && action.UserID == "" {
+		_, err := ctx.ReplyError("Error", "❌ Debes mencionar a un usuario válido.")
+		return err
+	}
+
+	action, err := leveladmin.Prepare(action)
+	if err != nil {
+		_, err = ctx.ReplyError("Error", leveladmin.ErrorText(err))
+		return err
+	}
+	_, err = ctx.Session.ChannelMessageSendComplex(ctx.Message.ChannelID, &discordgo.MessageSend{
+		Embeds:     []*discordgo.MessageEmbed{leveladmin.ConfirmEmbed(action)},
+		Components: leveladmin.ConfirmComponents(action),
+		Reference:  ctx.Message.Reference(),
+	})
+	return err
+}
@@ -0,0 +1,144 @@
+package levels
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/PancyStudios/PancyBotGo/internal/messagecommands"
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/leveling"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/bwmarrin/discordgo"
+)
+
+const xpConfigUsage = "Uso: `pan!xpconfig view`, `pan!xpconfig <min|max|cooldown|curva|base|premium|recompensas|vozxp> <valor>`, `pan!xpconfig voz <on|off>`, `pan!xpconfig multiplier <#canal|@rol> <x>` o `pan!xpconfig noxp <#canal|@rol>`"
+
+func xpConfigCommand(ctx *messagecommands.MessageContext) error {
+	if !ctx.HasPermission(discordgo.PermissionAdministrator) {
+		_, err := ctx.ReplyError("Acceso Denegado", "No tienes permiso de Administrador.")
+		return err
+	}
+
+	guildID := ctx.Message.GuildID
+	guildData, err := database.GlobalGuildDM.Get(bson.M{"id": guildID})
+	if err != nil {
+		_, err = ctx.ReplyError("Error", fmt.Sprintf("❌ Error al obtener la configuración del servidor: %v", err))
+		return err
+	}
+
+	cfg := guildData.Levels
+	if len(ctx.Args) == 0 || strings.ToLower(ctx.Args[0]) == "view" {
+		_, err = ctx.ReplyEmbed(xpConfigEmbed(cfg))
+		return err
+	}
+	if len(ctx.Args) < 2 {
+		_, err = ctx.ReplyError("Uso Incorrecto", xpConfigUsage)
+		return err
+	}
+
+	field := strings.ToLower(ctx.Args[0])
+	value := strings.ToLower(ctx.Args[1])
+	switch field {
+	case "min", "max", "cooldown", "base", "vozxp":
+		n, parseErr := strconv.ParseInt(value, 10, 64)
+		if parseErr != nil {
+			_, err = ctx.ReplyError("Error", "❌ El valor debe ser un número entero.")
+			return err
+		}
+		switch field {
+		case "min":
+			cfg.MinXP = n
+		case "max":
+			cfg.MaxXP = n
+		case "cooldown":
+			cfg.CooldownSeconds = int(n)
+		case "base":
+			cfg.CurveBase = n
+		case "vozxp":
+			cfg.VoiceXP = n
+		}
+
+	case "voz":
+		cfg.VoiceDisabled = value == "off"
+
+	case "curva":
+		cfg.Curve = value
+
+	case "recompensas":
+		cfg.RewardMode = value
+
+	case "premium":
+		x, parseErr := strconv.ParseFloat(value, 64)
+		if parseErr != nil {
+			_, err = ctx.ReplyError("Error", "❌ El multiplicador debe ser un número, por ejemplo 1.5.")
+			return err
+		}
+		cfg.PremiumBoost = x
+
+	case "multiplier":
+		if len(ctx.Args) < 3 {
+			_, err = ctx.ReplyError("Uso Incorrecto", xpConfigUsage)
+			return err
+		}
+		x, parseErr := strconv.ParseFloat(ctx.Args[2], 64)
+		if parseErr != nil {
+			_, err = ctx.ReplyError("Error", "❌ El multiplicador debe ser un número, por ejemplo 1.5.")
+			return err
+		}
+		if isChannelArg(ctx, 1) {
+			cfg.ChannelMultipliers = leveling.SetMultiplier(cfg.ChannelMultipliers, ctx.ParseChannel(1), x)
+		} else {
+			cfg.RoleMultipliers = leveling.SetMultiplier(cfg.RoleMultipliers, ctx.ParseRole(1), x)
+		}
+
+	case "noxp":
+		if isChannelArg(ctx, 1) {
+			cfg.NoXPChannels = leveling.ToggleID(cfg.NoXPChannels, ctx.ParseChannel(1))
+		} else {
+			cfg.NoXPRoles = leveling.ToggleID(cfg.NoXPRoles, ctx.ParseRole(1))
+		}
+
+	default:
+		_, err = ctx.ReplyError("Uso Incorrecto", xpConfigUsage)
+		return err
+	}
+
+	if err := leveling.Validate(cfg); err != nil {
+		_, err = ctx.ReplyError("Configuración Inválida", "❌ "+leveling.Explain(err))
+		return err
+	}
+
+	guildData.Levels = cfg
+	if _, err := database.GlobalGuildDM.Set(bson.M{"id": guildID}, guildData); err != nil {
+		_, err = ctx.ReplyError("Error", "❌ Error al guardar la configuración.")
+		return err
+	}
+	_, err = ctx.ReplyEmbed(xpConfigEmbed(cfg))
+	return err
+}
+
+// isChannelArg reports whether the argument is a channel mention or the ID of a
+// channel of the server; anything else is treated as a role
+func isChannelArg(ctx *messagecommands.MessageContext, index int) bool {
+	if strings.HasPrefix(ctx.Args[index], "<#") {
+		return true
+	}
+	channel, err := ctx.Session.State.Channel(ctx.ParseChannel(index))
+	return err == nil && channel.GuildID == ctx.Message.GuildID
+}
+
+func xpConfigEmbed(cfg models.LevelsConfig) *discordgo.MessageEmbed {
+	multipliers, excluded := leveling.Lists(cfg)
+	return &discordgo.MessageEmbed{
+		Title:       "🛠️ Configuración de Experiencia",
+		Description: leveling.Resolve(cfg).Summary(),
+		Color:       0x22d3ee,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "✖️ Multiplicadores", Value: multipliers},
+			{Name: "🚫 Sin XP", Value: excluded},
+		},
+	}
+}
@@ -2,10 +2,13 @@ package levels
 
 import (
 	"fmt"
+	"strconv"
+
 	"go.mongodb.org/mongo-driver/bson"
 
 	"github.com/PancyStudios/PancyBotGo/internal/messagecommands"
 	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/leaderboard"
 	"github.com/PancyStudios/PancyBotGo/pkg/logger"
 	"github.com/bwmarrin/discordgo"
 )
@@ -19,40 +22,29 @@ func leaderboardCommand(ctx *messagecommands.MessageContext) error {
 		return err
 	}
 
-	topProfiles, err := database.GetTopLevels(guildID, 10)
-	if err != nil {
-		logger.Error(fmt.Sprintf("Error obteniendo leaderboard para %s: %v", guildID, err), "Leaderboard")
-		_, err = ctx.ReplyError("Error", "❌ Ocurrió un error al obtener la clasificación.")
-		return err
-	}
-
-	if len(topProfiles) == 0 {
+	total, err := database.CountLevelsLeaderboard(guildID)
+	if err == nil && total == 0 {
 		_, err = ctx.ReplySuccess("Clasificación", "📉 Aún no hay usuarios con experiencia en este servidor.")
 		return err
 	}
 
-	description := "¡Estos son los usuarios más activos del servidor!\n\n"
-
-	for i, profile := range topProfiles {
-		medal := "🏅"
-		switch i {
-		case 0:
-			medal = "🥇"
-		case 1:
-			medal = "🥈"
-		case 2:
-			medal = "🥉"
+	page := 0
+	if len(ctx.Args) > 0 {
+		if n, err := strconv.Atoi(ctx.Args[0]); err == nil {
+			page = n - 1
 		}
-
-		description += fmt.Sprintf("%s **#%d** <@%s> - Nivel %d (%d XP)\n", medal, i+1, profile.UserID, profile.Level, profile.XP)
 	}
 
-	embed := &discordgo.MessageEmbed{
-		Title:       "🏆 Tabla de Clasificación de Niveles",
-		Description: description,
-		Color:       0xFFD700,
+	embed, components, err := leaderboard.Render(leaderboard.BoardLevels, guildID, ctx.Message.Author.ID, page)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error obteniendo leaderboard para %s: %v", guildID, err), "Leaderboard")
+		_, err = ctx.ReplyError("Error", "❌ Ocurrió un error al obtener la clasificación.")
+		return err
 	}
 
-	_, err = ctx.ReplyEmbed(embed)
+	_, err = ctx.Session.ChannelMessageSendComplex(ctx.Message.ChannelID, &discordgo.MessageSend{
+		Embeds:     []*discordgo.MessageEmbed{embed},
+		Components: components,
+	})
 	return err
 }
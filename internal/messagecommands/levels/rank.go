@@ -5,7 +5,9 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 
 	"github.com/PancyStudios/PancyBotGo/internal/messagecommands"
+	"github.com/PancyStudios/PancyBotGo/pkg/cards"
 	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/leveling"
 	"github.com/PancyStudios/PancyBotGo/pkg/logger"
 	"github.com/bwmarrin/discordgo"
 )
@@ -42,54 +44,20 @@ func rankCommand(ctx *messagecommands.MessageContext) error {
 		return err
 	}
 
-	nextLevel := profile.Level + 1
-	requiredXP := nextLevel * nextLevel * 100
-
-	currentLevelXP := int64(0)
-	if profile.Level > 0 {
-		currentLevelXP = profile.Level * profile.Level * 100
-	}
-
-	xpInCurrentLevel := profile.XP - currentLevelXP
-	xpNeededForNext := requiredXP - currentLevelXP
-
-	progressPercent := float64(xpInCurrentLevel) / float64(xpNeededForNext) * 100
-
-	progressBar := createProgressBar(progressPercent, 10)
-
-	embed := &discordgo.MessageEmbed{
-		Title:       fmt.Sprintf("🌟 Rango de %s", targetUser.Username),
-		Description: fmt.Sprintf("¡Sigue chateando para subir de nivel!\n\n**Nivel Actual:** %d\n**Experiencia:** %d / %d XP\n\n%s (%.1f%%)", profile.Level, profile.XP, requiredXP, progressBar, progressPercent),
-		Color:       0x00FFFF,
-		Thumbnail: &discordgo.MessageEmbedThumbnail{
-			URL: targetUser.AvatarURL("128"),
-		},
-		Footer: &discordgo.MessageEmbedFooter{
-			Text: fmt.Sprintf("Mensajes totales: %d", profile.TotalMessages),
-		},
+	file, err := cards.Rank(targetUser, profile, guildData)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error generando tarjeta de rango: %v", err), "RankCommand")
+		_, err = ctx.ReplyEmbed(&discordgo.MessageEmbed{
+			Title:       fmt.Sprintf("🌟 Rango de %s", targetUser.Username),
+			Description: fmt.Sprintf("**Nivel Actual:** %d\n**Experiencia:** %d / %d XP\n**Tiempo en voz:** %s", profile.Level, profile.XP, leveling.Resolve(guildData.Levels).XPForLevel(profile.Level+1), leveling.FormatVoice(profile.VoiceSeconds)),
+			Color:       0x00FFFF,
+		})
+		return err
 	}
 
-	_, err = ctx.ReplyEmbed(embed)
+	_, err = ctx.Session.ChannelMessageSendComplex(ctx.Message.ChannelID, &discordgo.MessageSend{
+		Files:     []*discordgo.File{file},
+		Reference: ctx.Message.Reference(),
+	})
 	return err
 }
-
-func createProgressBar(percent float64, length int) string {
-	filledBlocks := int((percent / 100.0) * float64(length))
-	if filledBlocks > length {
-		filledBlocks = length
-	}
-	if filledBlocks < 0 {
-		filledBlocks = 0
-	}
-
-	emptyBlocks := length - filledBlocks
-
-	bar := ""
-	for i := 0; i < filledBlocks; i++ {
-		bar += "🟦"
-	}
-	for i := 0; i < emptyBlocks; i++ {
-		bar += "⬛"
-	}
-	return bar
-}
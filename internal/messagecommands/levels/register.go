@@ -7,6 +7,9 @@ import (
 func RegisterAll() {
 	messagecommands.RegisterCommand("leaderboard", "Comando leaderboard", "pan!leaderboard", "Levels", leaderboardCommand)
 	messagecommands.RegisterCommand("rank", "Comando rank", "pan!rank", "Levels", rankCommand)
+	messagecommands.RegisterCommand("voicetop", "Comando voicetop", "pan!voicetop [página]", "Levels", voiceTopCommand)
 	messagecommands.RegisterCommand("rewards", "Comando rewards", "pan!rewards", "Levels", rewardsCommand)
+	messagecommands.RegisterCommand("xpconfig", "Comando xpconfig", "pan!xpconfig <view|campo|multiplier|noxp> [valor]", "Levels", xpConfigCommand)
+	messagecommands.RegisterCommand("xpadmin", "Comando xpadmin", "pan!xpadmin <set-xp|add-xp|set-level|reset|reset-all|export|import> [@usuario] [valor]", "Levels", xpAdminCommand)
 	messagecommands.RegisterCommand("togglelevels", "Comando togglelevels", "pan!togglelevels", "Levels", toggleCommand)
 }
@@ -0,0 +1,50 @@
+package levels
+
+import (
+	"fmt"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/PancyStudios/PancyBotGo/internal/messagecommands"
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/leaderboard"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/bwmarrin/discordgo"
+)
+
+func voiceTopCommand(ctx *messagecommands.MessageContext) error {
+	guildID := ctx.Message.GuildID
+
+	guildData, err := database.GlobalGuildDM.Get(bson.M{"id": guildID})
+	if err != nil || guildData == nil || !guildData.Levels.Enable {
+		_, err = ctx.ReplyError("Error", "❌ El sistema de niveles está desactivado en este servidor.")
+		return err
+	}
+
+	total, err := database.CountVoiceLeaderboard(guildID)
+	if err == nil && total == 0 {
+		_, err = ctx.ReplySuccess("Clasificación de Voz", "📉 Aún nadie ha pasado tiempo en voz en este servidor.")
+		return err
+	}
+
+	page := 0
+	if len(ctx.Args) > 0 {
+		if n, err := strconv.Atoi(ctx.Args[0]); err == nil {
+			page = n - 1
+		}
+	}
+
+	embed, components, err := leaderboard.Render(leaderboard.BoardVoice, guildID, ctx.Message.Author.ID, page)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error obteniendo el top de voz para %s: %v", guildID, err), "Leaderboard")
+		_, err = ctx.ReplyError("Error", "❌ Ocurrió un error al obtener la clasificación.")
+		return err
+	}
+
+	_, err = ctx.Session.ChannelMessageSendComplex(ctx.Message.ChannelID, &discordgo.MessageSend{
+		Embeds:     []*discordgo.MessageEmbed{embed},
+		Components: components,
+	})
+	return err
+}
@@ -75,7 +75,7 @@ func playHandler(ctx *messagecommands.MessageContext) error {
 			return
 		}
 
-		result, err := lavalinkClient.Search(query)
+		result, err := lavalinkClient.SearchContext(ctx.Context(), query)
 		if err != nil {
 			_, err := ctx.Reply(fmt.Sprintf("❌ Error buscando: %v", err))
 			if err != nil {
@@ -107,7 +107,7 @@ func playHandler(ctx *messagecommands.MessageContext) error {
 			for _, track := range tracks {
 				track.RequesterID = ctx.Message.Author.ID
 				track.RequesterName = ctx.Message.Author.Username
-				if err := lavalinkClient.Play(ctx.Message.GuildID, voiceState.ChannelID, ctx.Message.ChannelID, track); err != nil {
+				if err := lavalinkClient.PlayContext(ctx.Context(), ctx.Message.GuildID, voiceState.ChannelID, ctx.Message.ChannelID, track); err != nil {
 					// Log error but continue
 					continue
 				}
@@ -130,7 +130,7 @@ func playHandler(ctx *messagecommands.MessageContext) error {
 		track.RequesterName = ctx.Message.Author.Username
 
 		// Play the track
-		if err := lavalinkClient.Play(ctx.Message.GuildID, voiceState.ChannelID, ctx.Message.ChannelID, track); err != nil {
+		if err := lavalinkClient.PlayContext(ctx.Context(), ctx.Message.GuildID, voiceState.ChannelID, ctx.Message.ChannelID, track); err != nil {
 			_, err := ctx.Reply(fmt.Sprintf("❌ Error reproduciendo: %v", err))
 			if err != nil {
 				return
@@ -410,7 +410,7 @@ func radioHandler(ctx *messagecommands.MessageContext) error {
 			}
 		}
 
-		result, err := lavalinkClient.Search(stationURL)
+		result, err := lavalinkClient.SearchContext(ctx.Context(), stationURL)
 		if err != nil {
 			_, err := ctx.Reply(fmt.Sprintf("❌ Error buscando la radio: %v", err))
 			if err != nil {
@@ -436,7 +436,7 @@ func radioHandler(ctx *messagecommands.MessageContext) error {
 		}
 
 		// Play the radio track
-		if err := lavalinkClient.Play(ctx.Message.GuildID, voiceState.ChannelID, ctx.Message.ChannelID, track); err != nil {
+		if err := lavalinkClient.PlayContext(ctx.Context(), ctx.Message.GuildID, voiceState.ChannelID, ctx.Message.ChannelID, track); err != nil {
 			_, err := ctx.Reply(fmt.Sprintf("❌ Error reproduciendo: %v", err))
 			if err != nil {
 				return
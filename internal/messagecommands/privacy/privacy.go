@@ -0,0 +1,62 @@
+package privacy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/PancyStudios/PancyBotGo/internal/messagecommands"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/PancyStudios/PancyBotGo/pkg/privacy"
+	"github.com/bwmarrin/discordgo"
+)
+
+const privacyUsage = "Uso: `pan!privacy export` para recibir tus datos por DM, `pan!privacy delete` para borrarlos o `pan!privacy delete-server` para borrar los del servidor (solo el dueño)"
+
+func privacyCommand(ctx *messagecommands.MessageContext) error {
+	if len(ctx.Args) == 0 {
+		_, err := ctx.ReplyError("Uso Incorrecto", privacyUsage)
+		return err
+	}
+
+	userID := ctx.Message.Author.ID
+	action := privacy.Action{UserID: userID}
+	switch strings.ToLower(ctx.Args[0]) {
+	case "export":
+		if err := privacy.SendExport(ctx.Session, userID); err != nil {
+			if !errors.Is(err, privacy.ErrTooSoon) && !errors.Is(err, privacy.ErrDMClosed) {
+				logger.Error(fmt.Sprintf("Error exportando los datos de %s: %v", userID, err), "Privacy")
+			}
+			_, err = ctx.ReplyError("Error", privacy.ErrorText(err))
+			return err
+		}
+		_, err := ctx.Reply("📬 Te envié tus datos por mensaje directo.")
+		return err
+
+	case privacy.ActionDeleteUser:
+		action.Kind = privacy.ActionDeleteUser
+
+	case privacy.ActionDeleteGuild:
+		if ctx.Message.GuildID == "" {
+			_, err := ctx.ReplyError("Error", "❌ Este comando solo se puede usar en un servidor.")
+			return err
+		}
+		action.Kind, action.GuildID = privacy.ActionDeleteGuild, ctx.Message.GuildID
+
+	default:
+		_, err := ctx.ReplyError("Uso Incorrecto", privacyUsage)
+		return err
+	}
+
+	action, err := privacy.Prepare(ctx.Session, action)
+	if err != nil {
+		_, err = ctx.ReplyError("Error", privacy.ErrorText(err))
+		return err
+	}
+	_, err = ctx.Session.ChannelMessageSendComplex(ctx.Message.ChannelID, &discordgo.MessageSend{
+		Embeds:     []*discordgo.MessageEmbed{privacy.ConfirmEmbed(action)},
+		Components: privacy.ConfirmComponents(action),
+		Reference:  ctx.Message.Reference(),
+	})
+	return err
+}
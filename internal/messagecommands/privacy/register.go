@@ -0,0 +1,9 @@
+package privacy
+
+import (
+	"github.com/PancyStudios/PancyBotGo/internal/messagecommands"
+)
+
+func RegisterAll() {
+	messagecommands.RegisterCommand("privacy", "Comando privacy", "pan!privacy <export|delete|delete-server>", "Privacy", privacyCommand)
+}
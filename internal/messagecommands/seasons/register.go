@@ -0,0 +1,9 @@
+package seasons
+
+import (
+	"github.com/PancyStudios/PancyBotGo/internal/messagecommands"
+)
+
+func RegisterAll() {
+	messagecommands.RegisterCommand("season", "Temporadas de niveles y economía", "pan!season [info|top [xp|eco] [página]|history [número]|start|end|config <campo> <valor>]", "Seasons", seasonCommand)
+}
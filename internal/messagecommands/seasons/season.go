@@ -0,0 +1,202 @@
+package seasons
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/internal/messagecommands"
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/leaderboard"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/PancyStudios/PancyBotGo/pkg/seasons"
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const seasonConfigUsage = "Uso: `pan!season config <campo> <valor>`\nCampos: `dias <n>`, `renovar on|off`, `canal #canal|off`, `top <n>`, `rolniveles @rol|off`, `roleconomia @rol|off`"
+
+func seasonCommand(ctx *messagecommands.MessageContext) error {
+	sub := "info"
+	if len(ctx.Args) > 0 {
+		sub = strings.ToLower(ctx.Args[0])
+	}
+
+	switch sub {
+	case "info":
+		return seasonInfo(ctx)
+	case "top":
+		return seasonTop(ctx)
+	case "history", "historial":
+		return seasonHistory(ctx)
+	case "start", "end", "config":
+		if !ctx.HasPermission(discordgo.PermissionManageGuild) {
+			_, err := ctx.ReplyError("Acceso Denegado", "Necesitas el permiso de Gestionar Servidor.")
+			return err
+		}
+	default:
+		_, err := ctx.ReplyError("Uso Incorrecto", "Uso: `pan!season [info|top|history|start|end|config]`")
+		return err
+	}
+
+	guildID := ctx.Message.GuildID
+	switch sub {
+	case "start":
+		season, err := seasons.Start(guildID, time.Now())
+		if err != nil {
+			_, err = ctx.ReplyError("Error", seasons.ErrorText(err))
+			return err
+		}
+		_, err = ctx.ReplySuccess(fmt.Sprintf("▶️ ¡Empieza la Temporada %d!", season.Number),
+			fmt.Sprintf("La experiencia y las ganancias de la temporada empiezan desde cero. Termina <t:%d:R>.", season.EndsAt.Unix()))
+		return err
+
+	case "end":
+		season, next, err := seasons.End(ctx.Session, guildID, time.Now())
+		if err != nil {
+			_, err = ctx.ReplyError("Error", seasons.ErrorText(err))
+			return err
+		}
+		message := fmt.Sprintf("✅ La **Temporada %d** terminó.", season.Number)
+		if next != nil {
+			message += fmt.Sprintf(" La **Temporada %d** ya empezó.", next.Number)
+		}
+		_, err = ctx.ReplySuccess("Temporada terminada", message)
+		return err
+	}
+	return seasonConfig(ctx)
+}
+
+func seasonInfo(ctx *messagecommands.MessageContext) error {
+	guildData, err := database.GlobalGuildDM.Get(bson.M{"id": ctx.Message.GuildID})
+	if err != nil || guildData == nil {
+		guildData = models.NewDefaultGuildDocument(ctx.Message.GuildID)
+	}
+	_, err = ctx.ReplyEmbed(seasons.StatusEmbed(guildData))
+	return err
+}
+
+func seasonTop(ctx *messagecommands.MessageContext) error {
+	board, page := leaderboard.BoardSeasonLevels, 0
+	for _, arg := range ctx.Args[1:] {
+		switch strings.ToLower(arg) {
+		case "eco", "economia", "economía", "ganancias":
+			board = leaderboard.BoardSeasonEconomy
+		case "xp", "niveles":
+			board = leaderboard.BoardSeasonLevels
+		default:
+			if n, err := strconv.Atoi(arg); err == nil {
+				page = n - 1
+			}
+		}
+	}
+
+	embed, components, err := leaderboard.Render(board, ctx.Message.GuildID, ctx.Message.Author.ID, page)
+	if err != nil {
+		_, err = ctx.ReplyError("Error", seasons.ErrorText(err))
+		return err
+	}
+	_, err = ctx.Session.ChannelMessageSendComplex(ctx.Message.ChannelID, &discordgo.MessageSend{
+		Embeds:     []*discordgo.MessageEmbed{embed},
+		Components: components,
+	})
+	return err
+}
+
+func seasonHistory(ctx *messagecommands.MessageContext) error {
+	guildID := ctx.Message.GuildID
+	if len(ctx.Args) < 2 {
+		past, err := database.GetPastSeasons(guildID, 15)
+		if err != nil {
+			_, err = ctx.ReplyError("Error", seasons.ErrorText(err))
+			return err
+		}
+		_, err = ctx.ReplyEmbed(seasons.HistoryEmbed(past))
+		return err
+	}
+
+	number, err := strconv.Atoi(ctx.Args[1])
+	if err != nil {
+		_, err = ctx.ReplyError("Error", "❌ El número de temporada no es válido.")
+		return err
+	}
+	season, err := database.GetSeason(guildID, number)
+	if err != nil || season == nil || season.EndedAt.IsZero() {
+		_, err = ctx.ReplyError("Error", fmt.Sprintf("❌ La temporada %d no existe o aún no ha terminado.", number))
+		return err
+	}
+	guildData, err := database.GlobalGuildDM.Get(bson.M{"id": guildID})
+	if err != nil || guildData == nil {
+		guildData = models.NewDefaultGuildDocument(guildID)
+	}
+	_, err = ctx.ReplyEmbed(seasons.ResultsEmbed(guildData, season, nil))
+	return err
+}
+
+func seasonConfig(ctx *messagecommands.MessageContext) error {
+	if len(ctx.Args) < 3 {
+		_, err := ctx.ReplyError("Uso Incorrecto", seasonConfigUsage)
+		return err
+	}
+	change := parseSeasonConfig(ctx, strings.ToLower(ctx.Args[1]), strings.ToLower(ctx.Args[2]))
+	if change == nil {
+		_, err := ctx.ReplyError("Uso Incorrecto", seasonConfigUsage)
+		return err
+	}
+
+	cfg, err := seasons.Configure(ctx.Message.GuildID, change)
+	if err != nil {
+		_, err = ctx.ReplyError("Error", seasons.ErrorText(err))
+		return err
+	}
+	_, err = ctx.ReplySuccess("⚙️ Configuración de Temporadas", seasons.Summary(cfg))
+	return err
+}
+
+// parseSeasonConfig turns a field and a value into a change, or nil if they are
+// not valid
+func parseSeasonConfig(ctx *messagecommands.MessageContext, field, value string) func(cfg *models.SeasonsConfig) {
+	off := value == "off" || value == "no" || value == "ninguno"
+
+	switch field {
+	case "dias", "días", "top":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil
+		}
+		if field == "top" {
+			return func(cfg *models.SeasonsConfig) { cfg.RewardTop = n }
+		}
+		return func(cfg *models.SeasonsConfig) { cfg.LengthDays = n }
+
+	case "renovar":
+		on := value == "on" || value == "si" || value == "sí"
+		if !on && !off {
+			return nil
+		}
+		return func(cfg *models.SeasonsConfig) { cfg.AutoRenew = on }
+
+	case "canal":
+		channelID := ""
+		if !off {
+			if channelID = ctx.ParseChannel(2); channelID == "" {
+				return nil
+			}
+		}
+		return func(cfg *models.SeasonsConfig) { cfg.AnnounceChannel = channelID }
+
+	case "rolniveles", "roleconomia", "roleconomía":
+		roleID := ""
+		if !off {
+			if roleID = ctx.ParseRole(2); roleID == "" {
+				return nil
+			}
+		}
+		if field == "rolniveles" {
+			return func(cfg *models.SeasonsConfig) { cfg.LevelsRole = roleID }
+		}
+		return func(cfg *models.SeasonsConfig) { cfg.EconomyRole = roleID }
+	}
+	return nil
+}
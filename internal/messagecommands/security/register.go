@@ -7,5 +7,5 @@ import (
 func RegisterAll() {
 	messagecommands.RegisterCommand("antibots", "Comando antibots", "pan!antibots", "Security", antibotsCommand)
 	messagecommands.RegisterCommand("antiraid", "Comando antiraid", "pan!antiraid", "Security", antiraidCommand)
-	messagecommands.RegisterCommand("verification", "Comando verification", "pan!verification", "Security", verificationCommand)
+	messagecommands.RegisterCommand("verification", "Comando verification", "pan!verification <panel|config>", "Security", verificationCommand)
 }
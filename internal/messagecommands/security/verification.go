@@ -2,22 +2,33 @@ package security
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/PancyStudios/PancyBotGo/internal/messagecommands"
 	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/PancyStudios/PancyBotGo/pkg/verification"
 	"github.com/bwmarrin/discordgo"
 	"go.mongodb.org/mongo-driver/bson"
 )
 
+const verificationUsage = "Uso: `pan!verification panel` o `pan!verification config <button|captcha|math|web> [intentos] [minutos] [edad_minima]`"
+
 func verificationCommand(ctx *messagecommands.MessageContext) error {
 	if !ctx.HasPermission(discordgo.PermissionAdministrator) {
 		_, err := ctx.ReplyError("Acceso Denegado", "Necesitas permisos de Administrador para usar este comando.")
 		return err
 	}
 
-	if len(ctx.Args) == 0 || strings.ToLower(ctx.Args[0]) != "panel" {
-		_, err := ctx.ReplyError("Uso Incorrecto", "Uso: `pan!verification panel`")
+	if len(ctx.Args) == 0 {
+		_, err := ctx.ReplyError("Uso Incorrecto", verificationUsage)
+		return err
+	}
+
+	sub := strings.ToLower(ctx.Args[0])
+	if sub != "panel" && sub != "config" {
+		_, err := ctx.ReplyError("Uso Incorrecto", verificationUsage)
 		return err
 	}
 
@@ -27,6 +38,10 @@ func verificationCommand(ctx *messagecommands.MessageContext) error {
 		return err
 	}
 
+	if sub == "config" {
+		return verificationConfigCommand(ctx, guildDoc)
+	}
+
 	if !guildDoc.Protection.Verification.Enable {
 		_, err = ctx.ReplyError("Sistema Inactivo", "El sistema de verificación está desactivado. Habilítalo en el **Dashboard**.")
 		return err
@@ -41,12 +56,12 @@ func verificationCommand(ctx *messagecommands.MessageContext) error {
 
 	embed := &discordgo.MessageEmbed{
 		Title:       "🔐 Verificación Requerida",
-		Description: "Para acceder al resto del servidor y canales, debes verificarte.\n\nHaz clic en el botón de abajo para confirmar que no eres un bot y aceptar las reglas del servidor.",
+		Description: verification.PanelDescription(guildDoc.Protection.Verification.Type),
 		Color:       0x2ecc71,
 	}
 
 	var verifyButton discordgo.MessageComponent
-	if guildDoc.Protection.Verification.Type == "web" {
+	if guildDoc.Protection.Verification.Type == verification.TypeWeb {
 		verifyButton = discordgo.Button{
 			Label: "🌐 Verificar en la Web",
 			Style: discordgo.LinkButton,
@@ -56,7 +71,7 @@ func verificationCommand(ctx *messagecommands.MessageContext) error {
 		verifyButton = discordgo.Button{
 			Label:    "✅ Verificarme",
 			Style:    discordgo.SuccessButton,
-			CustomID: "btn_verify_user",
+			CustomID: verification.ButtonCustomID,
 		}
 	}
 
@@ -79,3 +94,63 @@ func verificationCommand(ctx *messagecommands.MessageContext) error {
 	_, err = ctx.ReplySuccess("Panel Enviado", fmt.Sprintf("✅ Panel de verificación enviado correctamente a <#%s>.", channelID))
 	return err
 }
+
+// verificationConfigCommand handles pan!verification config <tipo> [intentos] [minutos] [edad_minima]
+func verificationConfigCommand(ctx *messagecommands.MessageContext, guildDoc *models.GuildDocument) error {
+	if len(ctx.Args) < 2 || !verification.IsValidType(strings.ToLower(ctx.Args[1])) {
+		_, err := ctx.ReplyError("Uso Incorrecto", verificationUsage)
+		return err
+	}
+
+	// Optional numeric arguments in order, with their allowed ranges
+	limits := []struct {
+		name     string
+		min, max int
+	}{
+		{"intentos", 1, 10},
+		{"minutos", 0, 1440},
+		{"edad_minima", 0, 365},
+	}
+	values := make([]int, 0, len(limits))
+	for idx, arg := range ctx.Args[2:] {
+		if idx >= len(limits) {
+			break
+		}
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < limits[idx].min || n > limits[idx].max {
+			_, err = ctx.ReplyError("Valor Inválido", fmt.Sprintf("`%s` debe ser un número entre %d y %d.", limits[idx].name, limits[idx].min, limits[idx].max))
+			return err
+		}
+		values = append(values, n)
+	}
+
+	cfg := &guildDoc.Protection.Verification
+	cfg.Type = strings.ToLower(ctx.Args[1])
+	if len(values) > 0 {
+		cfg.MaxAttempts = values[0]
+	}
+	if len(values) > 1 {
+		cfg.TimeoutMinutes = values[1]
+	}
+	if len(values) > 2 {
+		cfg.MinAccountAgeDays = values[2]
+	}
+
+	if _, err := database.GlobalGuildDM.Set(bson.M{"id": ctx.Message.GuildID}, guildDoc); err != nil {
+		_, err = ctx.ReplyError("Error", "❌ Error al guardar la configuración.")
+		return err
+	}
+
+	timeout := "desactivado"
+	if cfg.TimeoutMinutes > 0 {
+		timeout = fmt.Sprintf("%d minutos", cfg.TimeoutMinutes)
+	}
+	content := fmt.Sprintf("**Tipo:** %s\n**Intentos:** %d\n**Tiempo límite:** %s\n**Edad mínima:** %d días",
+		cfg.Type, verification.MaxAttempts(*cfg), timeout, cfg.MinAccountAgeDays)
+	if !cfg.Enable {
+		content += "\n\n⚠️ El sistema está desactivado. Habilítalo en el **Dashboard** para que se aplique."
+	}
+
+	_, err := ctx.ReplySuccess("Verificación Actualizada", content)
+	return err
+}
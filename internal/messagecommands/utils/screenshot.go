@@ -38,7 +38,7 @@ func screenshotCommand(ctx *messagecommands.MessageContext) error {
 	}
 
 	payload, _ := json.Marshal(map[string]string{"url": urlParam})
-	req, err := http.NewRequest("POST", urlApi, bytes.NewBuffer(payload))
+	req, err := http.NewRequestWithContext(ctx.Context(), "POST", urlApi, bytes.NewBuffer(payload))
 	if err != nil {
 		_, err = ctx.ReplyError("Error", fmt.Sprintf("Error interno: %v", err))
 		return err
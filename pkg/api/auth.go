@@ -0,0 +1,14 @@
+package api
+
+import (
+	"github.com/PancyStudios/PancyBotGo/pkg/config"
+	"github.com/PancyStudios/PancyBotGo/pkg/mqtt"
+)
+
+// dashboardAuth protects the topics only the dashboard backend may call. It
+// accepts the shared secret itself or a token signed with it; without a secret
+// those topics reject every request.
+func dashboardAuth() mqtt.Authorizer {
+	secret := config.Get().MQTTAuthSecret
+	return mqtt.AnyOf(mqtt.SharedSecret(secret), mqtt.SignedTokens(secret))
+}
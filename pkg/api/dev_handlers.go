@@ -3,6 +3,7 @@ package api
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/PancyStudios/PancyBotGo/pkg/database"
 	"github.com/PancyStudios/PancyBotGo/pkg/discord"
@@ -10,34 +11,78 @@ import (
 	"github.com/PancyStudios/PancyBotGo/pkg/models"
 	"github.com/PancyStudios/PancyBotGo/pkg/mqtt"
 	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/bson"
 )
 
+// guildSummary is a server in the developer panel list
+type guildSummary struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Icon        string `json:"icon"`
+	MemberCount int    `json:"memberCount"`
+}
+
+type devGuildResponse struct {
+	Success bool   `json:"success"`
+	GuildID string `json:"guildId"`
+	Note    string `json:"note,omitempty"`
+}
+
+type blacklistGuildRequest struct {
+	GuildID string `json:"guildId" validate:"required"`
+	Reason  string `json:"reason"`
+}
+
+type sendMessageRequest struct {
+	GuildID   string `json:"guildId" validate:"required"`
+	ChannelID string `json:"channelId" validate:"required"`
+	Content   string `json:"content" validate:"required"`
+}
+
+type sendMessageResponse struct {
+	Success   bool   `json:"success"`
+	MessageID string `json:"messageId"`
+	ChannelID string `json:"channelId"`
+	GuildID   string `json:"guildId"`
+}
+
+// pendingWrite is a write waiting in the offline queue of the database
+type pendingWrite struct {
+	ID         string    `json:"id"`
+	Collection string    `json:"collection"`
+	Operation  string    `json:"operation"`
+	Query      bson.M    `json:"query"`
+	Guarded    bool      `json:"guarded"` // Dropped on replay if the document changed meanwhile
+	QueuedAt   time.Time `json:"queuedAt"`
+}
+
+type writeQueueResponse struct {
+	Connected bool           `json:"connected"`
+	Count     int            `json:"count"`
+	Writes    []pendingWrite `json:"writes"`
+}
+
 // RegisterDevHandlers registra los handlers MQTT exclusivos para el panel de developer.
-// Todos los tópicos usan el prefijo "dev-" para distinguirlos de los handlers normales.
+// Todos los tópicos usan el prefijo "dev-" para distinguirlos de los handlers normales
+// y solo aceptan peticiones con el secreto o un token firmado del dashboard.
 func RegisterDevHandlers(mc *mqtt.MqttCommunicator, discordClient *discord.ExtendedClient) {
+	auth := mqtt.WithAuth(dashboardAuth())
 
 	// ─────────────────────────────────────────────────────────────────────────
 	// get-all-bot-guilds
 	// Devuelve la lista completa de servidores donde está el bot (sin filtros).
 	// ─────────────────────────────────────────────────────────────────────────
-	mc.On("get-all-bot-guilds", func(payload map[string]interface{}) (interface{}, error) {
+	mqtt.On(mc, "get-all-bot-guilds", func(call *mqtt.Call, req mqtt.Empty) ([]guildSummary, error) {
 		if discordClient == nil || discordClient.Session == nil || discordClient.Session.State == nil {
-			return []interface{}{}, nil
+			return []guildSummary{}, nil
 		}
 
 		discordClient.Session.State.RLock()
 		defer discordClient.Session.State.RUnlock()
 
-		type GuildSummary struct {
-			ID          string `json:"id"`
-			Name        string `json:"name"`
-			Icon        string `json:"icon"`
-			MemberCount int    `json:"memberCount"`
-		}
-
-		result := make([]GuildSummary, 0, len(discordClient.Session.State.Guilds))
+		result := make([]guildSummary, 0, len(discordClient.Session.State.Guilds))
 		for _, g := range discordClient.Session.State.Guilds {
-			result = append(result, GuildSummary{
+			result = append(result, guildSummary{
 				ID:          g.ID,
 				Name:        g.Name,
 				Icon:        g.Icon,
@@ -47,50 +92,42 @@ func RegisterDevHandlers(mc *mqtt.MqttCommunicator, discordClient *discord.Exten
 
 		logger.Info(fmt.Sprintf("[Dev] get-all-bot-guilds: devolviendo %d servidores", len(result)), "DevMQTT")
 		return result, nil
-	})
+	}, mqtt.Describe("Todos los servidores donde está el bot"), auth)
 
 	// ─────────────────────────────────────────────────────────────────────────
 	// dev-leave-guild
 	// Hace que el bot abandone un servidor específico.
 	// Payload: { "guildId": "..." }
 	// ─────────────────────────────────────────────────────────────────────────
-	mc.On("dev-leave-guild", func(payload map[string]interface{}) (interface{}, error) {
+	mqtt.On(mc, "dev-leave-guild", func(call *mqtt.Call, req guildRequest) (*devGuildResponse, error) {
 		if discordClient == nil || discordClient.Session == nil {
 			return nil, fmt.Errorf("discord client not ready")
 		}
 
-		guildID, ok := payload["guildId"].(string)
-		if !ok || guildID == "" {
-			return nil, fmt.Errorf("missing or invalid guildId")
-		}
-
-		if err := discordClient.Session.GuildLeave(guildID); err != nil {
+		guildID := req.GuildID
+		if err := discordClient.Session.GuildLeave(guildID, discordgo.WithContext(call.Context())); err != nil {
 			logger.Error(fmt.Sprintf("[Dev] Error saliendo del servidor %s: %v", guildID, err), "DevMQTT")
 			return nil, fmt.Errorf("error al salir del servidor: %w", err)
 		}
 
-		logger.Info(fmt.Sprintf("[Dev] Bot salió del servidor %s por orden del developer", guildID), "DevMQTT")
-		return map[string]interface{}{"success": true, "guildId": guildID}, nil
-	})
+		logger.Info(fmt.Sprintf("[Dev] Bot salió del servidor %s por orden de %s", guildID, callerName(call)), "DevMQTT")
+		return &devGuildResponse{Success: true, GuildID: guildID}, nil
+	}, mqtt.Describe("Hace que el bot abandone un servidor"), auth)
 
 	// ─────────────────────────────────────────────────────────────────────────
 	// dev-blacklist-guild
 	// Añade un servidor a la blacklist y hace que el bot lo abandone.
 	// Payload: { "guildId": "...", "reason": "..." }
 	// ─────────────────────────────────────────────────────────────────────────
-	mc.On("dev-blacklist-guild", func(payload map[string]interface{}) (interface{}, error) {
+	mqtt.On(mc, "dev-blacklist-guild", func(call *mqtt.Call, req blacklistGuildRequest) (*devGuildResponse, error) {
 		if discordClient == nil || discordClient.Session == nil {
 			return nil, fmt.Errorf("discord client not ready")
 		}
 
-		guildID, ok := payload["guildId"].(string)
-		if !ok || guildID == "" {
-			return nil, fmt.Errorf("missing or invalid guildId")
-		}
-
+		guildID := req.GuildID
 		reason := "Sin razón especificada."
-		if r, ok := payload["reason"].(string); ok && r != "" {
-			reason = r
+		if req.Reason != "" {
+			reason = req.Reason
 		}
 
 		// Añadir a la blacklist del bot (database + cache)
@@ -118,67 +155,50 @@ func RegisterDevHandlers(mc *mqtt.MqttCommunicator, discordClient *discord.Exten
 		}
 
 		// Salir del servidor
-		if leaveErr := discordClient.Session.GuildLeave(guildID); leaveErr != nil {
+		if leaveErr := discordClient.Session.GuildLeave(guildID, discordgo.WithContext(call.Context())); leaveErr != nil {
 			logger.Warn(fmt.Sprintf("[Dev] No se pudo salir del servidor blacklisted %s: %v", guildID, leaveErr), "DevMQTT")
 			// No retornamos error — el blacklist ya fue guardado
 		}
 
-		logger.Info(fmt.Sprintf("[Dev] Servidor %s blacklisted y abandonado. Razón: %s", guildID, reason), "DevMQTT")
-		return map[string]interface{}{"success": true, "guildId": guildID}, nil
-	})
+		logger.Info(fmt.Sprintf("[Dev] Servidor %s blacklisted y abandonado por %s. Razón: %s", guildID, callerName(call), reason), "DevMQTT")
+		return &devGuildResponse{Success: true, GuildID: guildID}, nil
+	}, mqtt.Describe("Añade un servidor a la blacklist y lo abandona"), auth)
 
 	// ─────────────────────────────────────────────────────────────────────────
 	// dev-unblacklist-guild
 	// Remueve un servidor de la blacklist.
 	// Payload: { "guildId": "..." }
 	// ─────────────────────────────────────────────────────────────────────────
-	mc.On("dev-unblacklist-guild", func(payload map[string]interface{}) (interface{}, error) {
-		guildID, ok := payload["guildId"].(string)
-		if !ok || guildID == "" {
-			return nil, fmt.Errorf("missing or invalid guildId")
-		}
-
+	mqtt.On(mc, "dev-unblacklist-guild", func(call *mqtt.Call, req guildRequest) (*devGuildResponse, error) {
+		guildID := req.GuildID
 		if err := database.RemoveFromBlacklist(guildID); err != nil {
 			if errors.Is(err, database.ErrBlacklistNotFound) {
 				// No está en la blacklist del bot — OK, puede haber sido solo en la DB del API
 				logger.Info(fmt.Sprintf("[Dev] Servidor %s no estaba en la blacklist del bot, ignorando.", guildID), "DevMQTT")
-				return map[string]interface{}{"success": true, "guildId": guildID, "note": "not in bot blacklist"}, nil
+				return &devGuildResponse{Success: true, GuildID: guildID, Note: "not in bot blacklist"}, nil
 			}
 			logger.Error(fmt.Sprintf("[Dev] Error removiendo %s de blacklist: %v", guildID, err), "DevMQTT")
 			return nil, fmt.Errorf("error al remover de blacklist: %w", err)
 		}
 
 		logger.Info(fmt.Sprintf("[Dev] Servidor %s removido de la blacklist del bot", guildID), "DevMQTT")
-		return map[string]interface{}{"success": true, "guildId": guildID}, nil
-	})
+		return &devGuildResponse{Success: true, GuildID: guildID}, nil
+	}, mqtt.Describe("Quita un servidor de la blacklist"), auth)
 
 	// ─────────────────────────────────────────────────────────────────────────
 	// dev-send-message
 	// Envía un mensaje de texto a un canal específico de un servidor.
 	// Payload: { "guildId": "...", "channelId": "...", "content": "..." }
 	// ─────────────────────────────────────────────────────────────────────────
-	mc.On("dev-send-message", func(payload map[string]interface{}) (interface{}, error) {
+	mqtt.On(mc, "dev-send-message", func(call *mqtt.Call, req sendMessageRequest) (*sendMessageResponse, error) {
 		if discordClient == nil || discordClient.Session == nil {
 			return nil, fmt.Errorf("discord client not ready")
 		}
 
-		guildID, ok := payload["guildId"].(string)
-		if !ok || guildID == "" {
-			return nil, fmt.Errorf("missing or invalid guildId")
-		}
-
-		channelID, ok := payload["channelId"].(string)
-		if !ok || channelID == "" {
-			return nil, fmt.Errorf("missing or invalid channelId")
-		}
-
-		content, ok := payload["content"].(string)
-		if !ok || content == "" {
-			return nil, fmt.Errorf("missing or invalid content")
-		}
+		guildID, channelID := req.GuildID, req.ChannelID
 
 		// Verificar que el canal pertenece al servidor esperado (seguridad básica)
-		channel, err := discordClient.Session.Channel(channelID)
+		channel, err := discordClient.Session.Channel(channelID, discordgo.WithContext(call.Context()))
 		if err != nil {
 			return nil, fmt.Errorf("canal no encontrado: %w", err)
 		}
@@ -186,18 +206,51 @@ func RegisterDevHandlers(mc *mqtt.MqttCommunicator, discordClient *discord.Exten
 			return nil, fmt.Errorf("el canal no pertenece al servidor indicado")
 		}
 
-		msg, err := discordClient.Session.ChannelMessageSend(channelID, content)
+		msg, err := discordClient.Session.ChannelMessageSend(channelID, req.Content, discordgo.WithContext(call.Context()))
 		if err != nil {
 			logger.Error(fmt.Sprintf("[Dev] Error enviando mensaje al canal %s: %v", channelID, err), "DevMQTT")
 			return nil, fmt.Errorf("error al enviar mensaje: %w", err)
 		}
 
 		logger.Info(fmt.Sprintf("[Dev] Mensaje enviado al canal %s del servidor %s (msgID: %s)", channelID, guildID, msg.ID), "DevMQTT")
-		return map[string]interface{}{
-			"success":   true,
-			"messageId": msg.ID,
-			"channelId": channelID,
-			"guildId":   guildID,
+		return &sendMessageResponse{
+			Success:   true,
+			MessageID: msg.ID,
+			ChannelID: channelID,
+			GuildID:   guildID,
 		}, nil
-	})
+	}, mqtt.Describe("Envía un mensaje a un canal de un servidor"), auth)
+
+	// ─────────────────────────────────────────────────────────────────────────
+	// dev-write-queue
+	// Lista las escrituras que esperan en la cola offline a que vuelva la DB.
+	// ─────────────────────────────────────────────────────────────────────────
+	mqtt.On(mc, "dev-write-queue", func(call *mqtt.Call, req mqtt.Empty) (*writeQueueResponse, error) {
+		db := database.Get()
+		if db == nil {
+			return nil, fmt.Errorf("database not initialized")
+		}
+
+		pending := db.PendingWrites()
+		res := &writeQueueResponse{Connected: db.Connected(), Count: len(pending), Writes: make([]pendingWrite, len(pending))}
+		for i, op := range pending {
+			res.Writes[i] = pendingWrite{
+				ID:         op.ID,
+				Collection: op.CollectionName,
+				Operation:  op.Operation,
+				Query:      op.Query,
+				Guarded:    len(op.Guards) > 0,
+				QueuedAt:   op.QueuedAt,
+			}
+		}
+		return res, nil
+	}, mqtt.Describe("Lista las escrituras pendientes de la cola offline de la base de datos"), auth)
+}
+
+// callerName returns who made a dev request, for the logs
+func callerName(call *mqtt.Call) string {
+	if call.Caller != "" {
+		return call.Caller
+	}
+	return "el developer"
 }
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/cards"
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/guildconfig"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/PancyStudios/PancyBotGo/pkg/mqtt"
+	"github.com/bwmarrin/discordgo"
+)
+
+// guildConfigRequest asks for the configuration of a server on behalf of a
+// dashboard user, who must be able to manage it
+type guildConfigRequest struct {
+	GuildID string `json:"guildId" validate:"required"`
+	UserID  string `json:"userId" validate:"required"`
+}
+
+type guildConfigResponse struct {
+	GuildID    string                    `json:"guildId"`
+	Version    int64                     `json:"version"` // Send it back with patches
+	Greetings  models.Greetings          `json:"greetings"`
+	Moderation models.ModeratorData      `json:"moderation"`
+	Protection models.ProtectionConfig   `json:"protection"`
+	Levels     models.LevelsConfig       `json:"levels"`
+	Embeds     []models.CustomEmbed      `json:"embeds"`
+	PingOnJoin []models.PingOnJoinConfig `json:"pingOnJoin"`
+}
+
+// patchGuildConfigRequest changes one section with a JSON merge patch. Version
+// is the one the dashboard read; the patch is rejected if the configuration
+// changed since then.
+type patchGuildConfigRequest struct {
+	GuildID string          `json:"guildId" validate:"required"`
+	UserID  string          `json:"userId" validate:"required"`
+	Section string          `json:"section" validate:"required"`
+	Version int64           `json:"version"`
+	Patch   json.RawMessage `json:"patch" validate:"required"`
+}
+
+func (r patchGuildConfigRequest) Validate() error {
+	if !guildconfig.Known(r.Section) {
+		return fmt.Errorf("unknown section %q, use %s", r.Section, strings.Join(guildconfig.Sections, ", "))
+	}
+	return nil
+}
+
+type patchGuildConfigResponse struct {
+	GuildID string      `json:"guildId"`
+	Section string      `json:"section"`
+	Version int64       `json:"version"`
+	Value   interface{} `json:"value"` // The whole section after the patch
+}
+
+// guildConfigEvent is published on guild-config/<guildId> after every patch
+type guildConfigEvent struct {
+	GuildID string      `json:"guildId"`
+	Section string      `json:"section"`
+	Version int64       `json:"version"`
+	UserID  string      `json:"userId"`
+	Value   interface{} `json:"value"`
+}
+
+// configError is a rejected patch. It answers with the bad_request code and
+// lists the invalid fields in the details of the response.
+type configError struct {
+	*guildconfig.ValidationError
+}
+
+func (e configError) Error() string {
+	return mqtt.ErrBadRequest.Error() + ": " + e.ValidationError.Error()
+}
+
+func (e configError) Unwrap() error        { return mqtt.ErrBadRequest }
+func (e configError) Details() interface{} { return e.Problems }
+
+// RegisterGuildConfigHandlers registers the topics the dashboard uses to read
+// and edit the configuration of a server
+func RegisterGuildConfigHandlers(mc *mqtt.MqttCommunicator, discordClient *discord.ExtendedClient) {
+	auth := mqtt.WithAuth(dashboardAuth())
+
+	// get-guild-config
+	mqtt.On(mc, "get-guild-config", func(call *mqtt.Call, req guildConfigRequest) (*guildConfigResponse, error) {
+		if _, err := managedGuild(call, discordClient, req.GuildID, req.UserID); err != nil {
+			return nil, err
+		}
+		doc, err := database.GetGuildConfig(call.Context(), req.GuildID)
+		if err != nil {
+			return nil, err
+		}
+		return &guildConfigResponse{
+			GuildID:    doc.ID,
+			Version:    doc.Version,
+			Greetings:  doc.Greetings,
+			Moderation: doc.Moderation,
+			Protection: doc.Protection,
+			Levels:     doc.Levels,
+			Embeds:     doc.Embeds,
+			PingOnJoin: doc.PingOnJoin,
+		}, nil
+	}, mqtt.Describe("Configuración editable de un servidor, con su versión"), auth)
+
+	// patch-guild-config
+	mqtt.On(mc, "patch-guild-config", func(call *mqtt.Call, req patchGuildConfigRequest) (*patchGuildConfigResponse, error) {
+		guild, err := managedGuild(call, discordClient, req.GuildID, req.UserID)
+		if err != nil {
+			return nil, err
+		}
+		doc, err := database.GetGuildConfig(call.Context(), req.GuildID)
+		if err != nil {
+			return nil, err
+		}
+		if doc.Version != req.Version {
+			return nil, fmt.Errorf("%w: the configuration is at version %d", mqtt.ErrConflict, doc.Version)
+		}
+
+		premium, _, err := database.IsGuildPremium(req.GuildID)
+		if err != nil {
+			logger.WithFields(logger.Fields{"guild_id": req.GuildID}).WithError(err).Warn("No se pudo comprobar el premium del servidor", "API")
+		}
+		// Patch a copy, the document may be the cached one
+		next := *doc
+		discordClient.Session.State.RLock()
+		value, err := guildconfig.Patch(&next, req.Section, req.Patch, guildconfig.Env{Guild: guild, Premium: premium})
+		discordClient.Session.State.RUnlock()
+		var invalid *guildconfig.ValidationError
+		if errors.As(err, &invalid) {
+			return nil, configError{invalid}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		saved, err := database.SaveGuildSection(call.Context(), req.GuildID, req.Version, req.Section, value)
+		if errors.Is(err, database.ErrVersionConflict) {
+			return nil, fmt.Errorf("%w: the configuration changed while saving, read it again", mqtt.ErrConflict)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if req.Section == guildconfig.SectionGreetings {
+			cards.ForgetBackground(req.GuildID)
+		}
+
+		logger.WithFields(logger.Fields{
+			"guild_id": req.GuildID,
+			"user_id":  req.UserID,
+			"section":  req.Section,
+			"version":  saved.Version,
+		}).Info("Configuración actualizada desde el dashboard", "API")
+
+		event := guildConfigEvent{GuildID: req.GuildID, Section: req.Section, Version: saved.Version, UserID: req.UserID, Value: value}
+		if err := mc.Emit("guild-config/"+req.GuildID, event); err != nil {
+			logger.Warn(fmt.Sprintf("No se pudo publicar el cambio de configuración de %s: %v", req.GuildID, err), "API")
+		}
+
+		return &patchGuildConfigResponse{GuildID: req.GuildID, Section: req.Section, Version: saved.Version, Value: value}, nil
+	}, mqtt.Describe("Cambia una sección de la configuración con un JSON merge patch"), auth)
+}
+
+// managedGuild returns the server if the bot is in it and the user can manage it
+func managedGuild(call *mqtt.Call, discordClient *discord.ExtendedClient, guildID, userID string) (*discordgo.Guild, error) {
+	if discordClient == nil || discordClient.Session == nil || discordClient.Session.State == nil {
+		return nil, fmt.Errorf("discord client not ready")
+	}
+	guild, err := discordClient.Session.State.Guild(guildID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: guild not found", mqtt.ErrNotFound)
+	}
+
+	member, err := discordClient.Session.State.Member(guildID, userID)
+	if err != nil {
+		member, err = discordClient.Session.GuildMember(guildID, userID, discordgo.WithContext(call.Context()))
+	}
+	if err != nil || member == nil {
+		return nil, fmt.Errorf("%w: user is not a member of the guild", mqtt.ErrForbidden)
+	}
+
+	discordClient.Session.State.RLock()
+	allowed := guildconfig.CanManage(guild, userID, member.Roles)
+	discordClient.Session.State.RUnlock()
+	if !allowed {
+		return nil, fmt.Errorf("%w: user cannot manage the guild", mqtt.ErrForbidden)
+	}
+	return guild, nil
+}
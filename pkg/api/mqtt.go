@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"runtime"
 	"strings"
@@ -10,16 +11,131 @@ import (
 	"github.com/PancyStudios/PancyBotGo/pkg/config"
 	"github.com/PancyStudios/PancyBotGo/pkg/database"
 	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/ecoconfig"
+	"github.com/PancyStudios/PancyBotGo/pkg/leveling"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
 	"github.com/PancyStudios/PancyBotGo/pkg/mqtt"
+	"github.com/PancyStudios/PancyBotGo/pkg/verification"
 	"github.com/bwmarrin/discordgo"
 	"go.mongodb.org/mongo-driver/bson"
 )
 
+// guildRequest is the payload of the topics about a single server
+type guildRequest struct {
+	GuildID string `json:"guildId" validate:"required"`
+}
+
+// memberRequest is the payload of the topics about a member of a server
+type memberRequest struct {
+	GuildID string `json:"guildId" validate:"required"`
+	UserID  string `json:"userId" validate:"required"`
+}
+
+type successResponse struct {
+	Success bool `json:"success"`
+}
+
+type guildRole struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Color int    `json:"color"`
+}
+
+type guildChannel struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type guildInfo struct {
+	ID       string         `json:"id"`
+	Name     string         `json:"name"`
+	Icon     string         `json:"icon"`
+	Roles    []guildRole    `json:"roles"`
+	Channels []guildChannel `json:"channels"`
+}
+
+type leaderboardRequest struct {
+	GuildID string `json:"guildId" validate:"required"`
+	Limit   int64  `json:"limit"` // 10 when missing
+	Skip    int64  `json:"skip"`
+}
+
+type leaderboardEntry struct {
+	UserID        string `json:"userId"`
+	Username      string `json:"username"`
+	AvatarURL     string `json:"avatarUrl"`
+	Level         int64  `json:"level"`
+	XP            int64  `json:"xp"`
+	TotalMessages int64  `json:"totalMessages"`
+}
+
+type userLevel struct {
+	UserID        string `json:"userId"`
+	Level         int64  `json:"level"`
+	XP            int64  `json:"xp"`
+	RequiredXP    int64  `json:"requiredXp"`
+	TotalMessages int64  `json:"totalMessages"`
+	VoiceSeconds  int64  `json:"voiceSeconds"`
+}
+
+type broadcastRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Color       string `json:"color"` // Hex, like "#A855F7"
+	ImageURL    string `json:"imageUrl"`
+}
+
+func (r broadcastRequest) Validate() error {
+	if r.Title == "" && r.Description == "" {
+		return fmt.Errorf("missing title or description")
+	}
+	return nil
+}
+
+type configUpdateRequest struct {
+	MaintenanceMode  bool     `json:"maintenanceMode"`
+	DisabledCommands []string `json:"disabledCommands"`
+}
+
+type economyConfigResponse struct {
+	Version  int64                `json:"version"` // Send it back with updates
+	Economy  models.EconomyConfig `json:"economy"`
+	Commands []string             `json:"commands"`
+}
+
+// updateEconomyRequest replaces the economy configuration on behalf of a
+// dashboard user. It is rejected if the guild changed since Version was read.
+type updateEconomyRequest struct {
+	GuildID string               `json:"guildId" validate:"required"`
+	UserID  string               `json:"userId" validate:"required"`
+	Version int64                `json:"version"`
+	Economy models.EconomyConfig `json:"economy"`
+}
+
+func (r *updateEconomyRequest) Validate() error {
+	if r.Economy.Commands == nil {
+		r.Economy.Commands = make(map[string]models.EconomyCommandConfig)
+	}
+	if err := ecoconfig.Validate(r.Economy); err != nil {
+		return fmt.Errorf("invalid economy config: %s", ecoconfig.Explain(err))
+	}
+	return nil
+}
+
+type updateEconomyResponse struct {
+	Success bool                 `json:"success"`
+	Version int64                `json:"version"`
+	Economy models.EconomyConfig `json:"economy"`
+}
+
 // RegisterAPIHandlers registers all MQTT endpoints for the REST API
 func RegisterAPIHandlers(mc *mqtt.MqttCommunicator, discordClient *discord.ExtendedClient) {
+	// Topics that change state or expose the configuration of a server
+	auth := mqtt.WithAuth(dashboardAuth())
 
 	// get-bot-guild-ids
-	mc.On("get-bot-guild-ids", func(payload map[string]interface{}) (interface{}, error) {
+	mqtt.On(mc, "get-bot-guild-ids", func(call *mqtt.Call, req mqtt.Empty) ([]string, error) {
 		if discordClient == nil || discordClient.Session == nil || discordClient.Session.State == nil {
 			return []string{}, nil
 		}
@@ -33,152 +149,120 @@ func RegisterAPIHandlers(mc *mqtt.MqttCommunicator, discordClient *discord.Exten
 		}
 
 		return ids, nil
-	})
+	}, mqtt.Describe("IDs de los servidores donde está el bot"))
 
 	// update-guild-cache
-	mc.On("update-guild-cache", func(payload map[string]interface{}) (interface{}, error) {
-		guildID, _ := payload["guildId"].(string)
-		if guildID != "" {
-			database.GlobalGuildDM.ClearCache()
-		}
-		return map[string]interface{}{"success": true}, nil
-	})
+	mqtt.On(mc, "update-guild-cache", func(call *mqtt.Call, req guildRequest) (successResponse, error) {
+		database.GlobalGuildDM.Invalidate(bson.M{"id": req.GuildID})
+		return successResponse{Success: true}, nil
+	}, mqtt.Describe("Descarta la configuración en caché de un servidor, en todas las instancias, tras un cambio desde el dashboard"), auth)
 
 	// verify-user-web
-	mc.On("verify-user-web", func(payload map[string]interface{}) (interface{}, error) {
-		guildID, _ := payload["guildId"].(string)
-		userID, _ := payload["userId"].(string)
-
-		if guildID == "" || userID == "" {
-			return nil, fmt.Errorf("missing guildId or userId")
-		}
-
+	mqtt.On(mc, "verify-user-web", func(call *mqtt.Call, req memberRequest) (successResponse, error) {
 		// Prevent bots that are not in the guild from responding with an error
-		_, err := discordClient.Session.State.Guild(guildID)
+		_, err := discordClient.Session.State.Guild(req.GuildID)
 		if err != nil {
 			// Silently ignore if this specific bot is not in the guild
-			return nil, fmt.Errorf("bot not in guild")
+			return successResponse{}, fmt.Errorf("bot not in guild")
 		}
 
-		guildDoc, err := database.GlobalGuildDM.Get(bson.M{"id": guildID})
+		guildDoc, err := database.GlobalGuildDM.WithContext(call.Context()).Get(bson.M{"id": req.GuildID})
 		if err != nil || guildDoc == nil || !guildDoc.Protection.Verification.Enable || guildDoc.Protection.Verification.Role == "" {
-			return nil, fmt.Errorf("verification disabled or role not configured")
+			return successResponse{}, fmt.Errorf("verification disabled or role not configured")
+		}
+		// The other methods are answered in Discord and can't be skipped from the web
+		if guildDoc.Protection.Verification.Type != verification.TypeWeb {
+			return successResponse{}, fmt.Errorf("guild does not use web verification")
+		}
+		pending, err := database.GetPendingVerification(req.GuildID, req.UserID)
+		if err != nil {
+			return successResponse{}, err
+		}
+		if pending == nil {
+			return successResponse{}, fmt.Errorf("%w: user has no pending verification", mqtt.ErrNotFound)
 		}
 
-		err = discordClient.Session.GuildMemberRoleAdd(guildID, userID, guildDoc.Protection.Verification.Role)
+		if !verification.MeetsMinimumAge(guildDoc.Protection.Verification, req.UserID) {
+			verification.LogOutcome(discordClient.Session, guildDoc, req.UserID, verification.OutcomeTooYoung, "Verificación web rechazada")
+			return successResponse{}, fmt.Errorf("account too young")
+		}
+
+		err = verification.Grant(discordClient.Session, guildDoc, req.UserID, verification.TypeWeb)
 		if err != nil {
-			return nil, fmt.Errorf("failed to add role: %v", err)
+			return successResponse{}, fmt.Errorf("failed to add role: %v", err)
 		}
 
-		return map[string]interface{}{"success": true}, nil
-	})
+		return successResponse{Success: true}, nil
+	}, mqtt.Describe("Completa la verificación web de un miembro"), auth)
 
 	// get-guild-info
-	mc.On("get-guild-info", func(payload map[string]interface{}) (interface{}, error) {
+	mqtt.On(mc, "get-guild-info", func(call *mqtt.Call, req guildRequest) (*guildInfo, error) {
 		if discordClient == nil || discordClient.Session == nil || discordClient.Session.State == nil {
 			return nil, fmt.Errorf("discord client not ready")
 		}
 
-		guildIDInter, ok := payload["guildId"]
-		if !ok {
-			return nil, fmt.Errorf("missing guildId")
-		}
-
-		guildID, ok := guildIDInter.(string)
-		if !ok {
-			return nil, fmt.Errorf("guildId must be a string")
-		}
-
-		guild, err := discordClient.Session.State.Guild(guildID)
+		guild, err := discordClient.Session.State.Guild(req.GuildID)
 		if err != nil {
 			return nil, fmt.Errorf("guild not found")
 		}
 
-		var roles []map[string]interface{}
+		info := &guildInfo{
+			ID:   guild.ID,
+			Name: guild.Name,
+			Icon: guild.Icon,
+		}
 		for _, r := range guild.Roles {
-			roles = append(roles, map[string]interface{}{
-				"id":    r.ID,
-				"name":  r.Name,
-				"color": r.Color,
-			})
+			info.Roles = append(info.Roles, guildRole{ID: r.ID, Name: r.Name, Color: r.Color})
 		}
-
-		var channels []map[string]interface{}
 		for _, c := range guild.Channels {
 			// Solamente canales de texto (Type 0)
 			if c.Type == 0 {
-				channels = append(channels, map[string]interface{}{
-					"id":   c.ID,
-					"name": c.Name,
-				})
+				info.Channels = append(info.Channels, guildChannel{ID: c.ID, Name: c.Name})
 			}
 		}
 
-		return map[string]interface{}{
-			"id":       guild.ID,
-			"name":     guild.Name,
-			"icon":     guild.Icon,
-			"roles":    roles,
-			"channels": channels,
-		}, nil
-	})
+		return info, nil
+	}, mqtt.Describe("Nombre, icono, roles y canales de texto de un servidor"), auth)
 
 	// get-levels-leaderboard
-	mc.On("get-levels-leaderboard", func(payload map[string]interface{}) (interface{}, error) {
+	mqtt.On(mc, "get-levels-leaderboard", func(call *mqtt.Call, req leaderboardRequest) ([]leaderboardEntry, error) {
 		if discordClient == nil || discordClient.Session == nil {
 			return nil, fmt.Errorf("discord client not ready")
 		}
 
-		guildIDInter, ok := payload["guildId"]
-		if !ok {
-			return nil, fmt.Errorf("missing guildId")
-		}
-
-		guildID, ok := guildIDInter.(string)
-		if !ok {
-			return nil, fmt.Errorf("guildId must be a string")
+		limit := req.Limit
+		if limit == 0 {
+			limit = 10
 		}
-
-		limit := int64(10)
-		if limitInter, ok := payload["limit"]; ok {
-			if l, ok := limitInter.(float64); ok {
-				limit = int64(l)
-			}
+		skip := req.Skip
+		if skip < 0 {
+			skip = 0
 		}
 
-		profiles, err := database.GetTopLevels(guildID, limit)
+		profiles, err := database.GetTopLevels(req.GuildID, limit, skip)
 		if err != nil {
 			return nil, fmt.Errorf("error fetching leaderboard: %w", err)
 		}
 
-		type LeaderboardEntry struct {
-			UserID        string `json:"userId"`
-			Username      string `json:"username"`
-			AvatarURL     string `json:"avatarUrl"`
-			Level         int64  `json:"level"`
-			XP            int64  `json:"xp"`
-			TotalMessages int64  `json:"totalMessages"`
-		}
-
-		var result []LeaderboardEntry
+		var result []leaderboardEntry
 		for _, p := range profiles {
 			username := "Usuario Desconocido"
 			avatar := ""
 
 			// Try to get user info from state, fallback to API
-			member, err := discordClient.Session.State.Member(guildID, p.UserID)
+			member, err := discordClient.Session.State.Member(req.GuildID, p.UserID)
 			if err == nil && member != nil && member.User != nil {
 				username = member.User.Username
 				avatar = member.User.AvatarURL("")
 			} else {
-				user, err := discordClient.Session.User(p.UserID)
+				user, err := discordClient.Session.User(p.UserID, discordgo.WithContext(call.Context()))
 				if err == nil && user != nil {
 					username = user.Username
 					avatar = user.AvatarURL("")
 				}
 			}
 
-			result = append(result, LeaderboardEntry{
+			result = append(result, leaderboardEntry{
 				UserID:        p.UserID,
 				Username:      username,
 				AvatarURL:     avatar,
@@ -189,55 +273,39 @@ func RegisterAPIHandlers(mc *mqtt.MqttCommunicator, discordClient *discord.Exten
 		}
 
 		return result, nil
-	})
+	}, mqtt.Describe("Ranking de niveles de un servidor"))
 
 	// get-user-level
-	mc.On("get-user-level", func(payload map[string]interface{}) (interface{}, error) {
+	mqtt.On(mc, "get-user-level", func(call *mqtt.Call, req memberRequest) (*userLevel, error) {
 		if discordClient == nil || discordClient.Session == nil {
 			return nil, fmt.Errorf("discord client not ready")
 		}
 
-		guildIDInter, ok := payload["guildId"]
-		if !ok {
-			return nil, fmt.Errorf("missing guildId")
-		}
-
-		userIDInter, ok := payload["userId"]
-		if !ok {
-			return nil, fmt.Errorf("missing userId")
-		}
-
-		guildID, ok := guildIDInter.(string)
-		if !ok {
-			return nil, fmt.Errorf("guildId must be a string")
-		}
-
-		userID, ok := userIDInter.(string)
-		if !ok {
-			return nil, fmt.Errorf("userId must be a string")
-		}
-
-		profile, err := database.GetLocalLevelProfile(guildID, userID)
+		profile, err := database.GetLocalLevelProfile(req.GuildID, req.UserID)
 		if err != nil {
 			return nil, fmt.Errorf("error fetching user level: %w", err)
 		}
 
-		nextLevel := profile.Level + 1
-		requiredXP := nextLevel * nextLevel * 100
+		var levels models.LevelsConfig
+		if guildDoc, err := database.GlobalGuildDM.WithContext(call.Context()).Get(bson.M{"id": req.GuildID}); err == nil && guildDoc != nil {
+			levels = guildDoc.Levels
+		}
+		requiredXP := leveling.Resolve(levels).XPForLevel(profile.Level + 1)
 
-		return map[string]interface{}{
-			"userId":        profile.UserID,
-			"level":         profile.Level,
-			"xp":            profile.XP,
-			"requiredXp":    requiredXP,
-			"totalMessages": profile.TotalMessages,
+		return &userLevel{
+			UserID:        profile.UserID,
+			Level:         profile.Level,
+			XP:            profile.XP,
+			RequiredXP:    requiredXP,
+			TotalMessages: profile.TotalMessages,
+			VoiceSeconds:  profile.VoiceSeconds,
 		}, nil
-	})
+	}, mqtt.Describe("Nivel y XP de un miembro"))
 
 	// get-stats
-	mc.On("get-stats", func(payload map[string]interface{}) (interface{}, error) {
+	mqtt.On(mc, "get-stats", func(call *mqtt.Call, req mqtt.Empty) (string, error) {
 		if discordClient == nil || discordClient.Session == nil || discordClient.Session.State == nil {
-			return nil, fmt.Errorf("discord client not ready")
+			return "", fmt.Errorf("discord client not ready")
 		}
 
 		discordClient.Session.State.RLock()
@@ -285,36 +353,30 @@ func RegisterAPIHandlers(mc *mqtt.MqttCommunicator, discordClient *discord.Exten
 
 		bytes, err := json.Marshal(stats)
 		if err != nil {
-			return nil, err
+			return "", err
 		}
 
 		return string(bytes), nil
-	})
+	}, mqtt.Describe("Estadísticas del bot, como JSON dentro de un texto"))
 
 	// broadcast-message
-	mc.On("broadcast-message", func(payload map[string]interface{}) (interface{}, error) {
+	mqtt.On(mc, "broadcast-message", func(call *mqtt.Call, req broadcastRequest) (successResponse, error) {
 		if discordClient == nil || discordClient.Session == nil {
-			return nil, fmt.Errorf("discord client not ready")
+			return successResponse{}, fmt.Errorf("discord client not ready")
 		}
 
-		title, _ := payload["title"].(string)
-		description, _ := payload["description"].(string)
-		colorHex, _ := payload["color"].(string)
-		imageUrl, _ := payload["imageUrl"].(string)
-
 		colorInt := 0xA855F7 // default purple
-		if colorHex != "" {
-			colorHex = strings.TrimPrefix(colorHex, "#")
-			fmt.Sscanf(colorHex, "%x", &colorInt)
+		if req.Color != "" {
+			fmt.Sscanf(strings.TrimPrefix(req.Color, "#"), "%x", &colorInt)
 		}
 
 		embed := &discordgo.MessageEmbed{
-			Title:       title,
-			Description: description,
+			Title:       req.Title,
+			Description: req.Description,
 			Color:       colorInt,
 		}
-		if imageUrl != "" {
-			embed.Image = &discordgo.MessageEmbedImage{URL: imageUrl}
+		if req.ImageURL != "" {
+			embed.Image = &discordgo.MessageEmbedImage{URL: req.ImageURL}
 		}
 
 		go func() {
@@ -338,26 +400,58 @@ func RegisterAPIHandlers(mc *mqtt.MqttCommunicator, discordClient *discord.Exten
 			}
 		}()
 
-		return map[string]interface{}{"success": true}, nil
-	})
+		return successResponse{Success: true}, nil
+	}, mqtt.Describe("Envía un anuncio a todos los servidores"), auth)
 
 	// config-update
-	mc.On("config-update", func(payload map[string]interface{}) (interface{}, error) {
-		maintenanceMode, _ := payload["maintenanceMode"].(bool)
-		
-		disabledCommandsRaw, ok := payload["disabledCommands"].([]interface{})
-		var disabledCommands []string
-		if ok {
-			for _, v := range disabledCommandsRaw {
-				if str, ok := v.(string); ok {
-					disabledCommands = append(disabledCommands, str)
-				}
-			}
+	mqtt.On(mc, "config-update", func(call *mqtt.Call, req configUpdateRequest) (successResponse, error) {
+		botConfig := config.GetBotConfig()
+		botConfig.Update(req.MaintenanceMode, req.DisabledCommands)
+
+		return successResponse{Success: true}, nil
+	}, mqtt.Describe("Cambia el modo mantenimiento y los comandos desactivados"), auth)
+
+	// get-economy-config
+	mqtt.On(mc, "get-economy-config", func(call *mqtt.Call, req guildRequest) (*economyConfigResponse, error) {
+		guildData, err := database.GetGuildConfig(call.Context(), req.GuildID)
+		if err != nil {
+			return nil, err
 		}
 
-		botConfig := config.GetBotConfig()
-		botConfig.Update(maintenanceMode, disabledCommands)
+		return &economyConfigResponse{
+			Version:  guildData.Version,
+			Economy:  guildData.Economy,
+			Commands: ecoconfig.Commands,
+		}, nil
+	}, mqtt.Describe("Configuración de la economía local de un servidor"), auth)
+
+	// update-economy-config
+	mqtt.On(mc, "update-economy-config", func(call *mqtt.Call, req updateEconomyRequest) (*updateEconomyResponse, error) {
+		if _, err := managedGuild(call, discordClient, req.GuildID, req.UserID); err != nil {
+			return nil, err
+		}
+		guildData, err := database.GetGuildConfig(call.Context(), req.GuildID)
+		if err != nil {
+			return nil, err
+		}
+		if guildData.Version != req.Version {
+			return nil, fmt.Errorf("%w: the configuration is at version %d", mqtt.ErrConflict, guildData.Version)
+		}
+
+		saved, err := database.SaveGuildSection(call.Context(), req.GuildID, req.Version, "economy", req.Economy)
+		if errors.Is(err, database.ErrVersionConflict) {
+			return nil, fmt.Errorf("%w: the configuration changed while saving, read it again", mqtt.ErrConflict)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		logger.WithFields(logger.Fields{
+			"guild_id": req.GuildID,
+			"user_id":  req.UserID,
+			"version":  saved.Version,
+		}).Info("Economía actualizada desde el dashboard", "API")
 
-		return map[string]interface{}{"success": true}, nil
-	})
+		return &updateEconomyResponse{Success: true, Version: saved.Version, Economy: req.Economy}, nil
+	}, mqtt.Describe("Guarda la configuración de la economía local de un servidor"), auth)
 }
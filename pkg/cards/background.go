@@ -0,0 +1,68 @@
+package cards
+
+import (
+	"fmt"
+	"image"
+	"sync"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/imaging"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+)
+
+// How long a downloaded custom background is reused, and how long a failed
+// download waits before it is tried again
+const (
+	backgroundTTL   = 30 * time.Minute
+	backgroundRetry = time.Minute
+)
+
+// cachedBackground is the custom background of a guild, already fitted to the
+// card size. img is nil when the download failed.
+type cachedBackground struct {
+	mu      sync.Mutex
+	url     string
+	img     image.Image
+	expires time.Time
+}
+
+var (
+	backgrounds   = make(map[string]*cachedBackground)
+	backgroundsMu sync.Mutex
+)
+
+// customBackground returns the custom background of a guild, downloading it
+// only when it isn't cached or the URL changed. Concurrent cards of the same
+// guild wait for a single download.
+func customBackground(guildID, url string) image.Image {
+	backgroundsMu.Lock()
+	entry, ok := backgrounds[guildID]
+	if !ok {
+		entry = &cachedBackground{}
+		backgrounds[guildID] = entry
+	}
+	backgroundsMu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.url == url && time.Now().Before(entry.expires) {
+		return entry.img
+	}
+
+	entry.url, entry.img, entry.expires = url, nil, time.Now().Add(backgroundRetry)
+	img, err := imaging.FetchImage(url)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("No se pudo cargar el fondo personalizado de %s: %v", guildID, err), "Cards")
+		return nil
+	}
+	entry.img, entry.expires = imaging.FitBackground(img), time.Now().Add(backgroundTTL)
+	return entry.img
+}
+
+// ForgetBackground drops the cached background of a guild, so the next card
+// downloads it again. Call it when the card configuration changes.
+func ForgetBackground(guildID string) {
+	backgroundsMu.Lock()
+	delete(backgrounds, guildID)
+	backgroundsMu.Unlock()
+}
@@ -0,0 +1,137 @@
+// Package cards renders the guild-styled image cards (welcome, farewell and rank)
+// on top of pkg/imaging and returns them ready to attach to a Discord message.
+package cards
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/imaging"
+	"github.com/PancyStudios/PancyBotGo/pkg/leveling"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/bwmarrin/discordgo"
+)
+
+// File names used for the attachments (reference them with attachment://<name>)
+const (
+	WelcomeFileName  = "welcome.png"
+	FarewellFileName = "farewell.png"
+	RankFileName     = "rank.png"
+)
+
+// Style builds the card style of a guild. Custom backgrounds only apply to premium guilds.
+func Style(guildDoc *models.GuildDocument) imaging.CardStyle {
+	style := imaging.CardStyle{
+		Background: imaging.DefaultBackground,
+		Font:       imaging.DefaultFont,
+		Accent:     imaging.DefaultAccentColor,
+	}
+	if guildDoc == nil {
+		return style
+	}
+
+	cfg := guildDoc.Greetings.Card
+	if imaging.IsValidBackground(cfg.Background) {
+		style.Background = cfg.Background
+	}
+	if imaging.IsValidFont(cfg.Font) {
+		style.Font = cfg.Font
+	}
+	if cfg.AccentColor > 0 {
+		style.Accent = cfg.AccentColor
+	}
+
+	if cfg.CustomBackground != "" {
+		if premium, _, err := database.IsGuildPremium(guildDoc.ID); err == nil && premium {
+			if bg := customBackground(guildDoc.ID, cfg.CustomBackground); bg != nil {
+				style.CustomBackground = bg
+			}
+		}
+	}
+	return style
+}
+
+// Welcome renders the welcome card of a new member
+func Welcome(user *discordgo.User, guild *discordgo.Guild, guildDoc *models.GuildDocument) (*discordgo.File, error) {
+	card := imaging.WelcomeCard{
+		Title:    "Bienvenido/a",
+		Username: user.Username,
+		Subtitle: fmt.Sprintf("Eres el miembro #%d", guild.MemberCount),
+		Avatar:   fetchAvatar(user),
+	}
+	data, err := imaging.RenderWelcomeCard(card, Style(guildDoc))
+	if err != nil {
+		return nil, err
+	}
+	return pngFile(WelcomeFileName, data), nil
+}
+
+// Farewell renders the farewell card of a member that left
+func Farewell(user *discordgo.User, guild *discordgo.Guild, guildDoc *models.GuildDocument) (*discordgo.File, error) {
+	card := imaging.WelcomeCard{
+		Title:    "Hasta pronto",
+		Username: user.Username,
+		Subtitle: fmt.Sprintf("Ahora somos %d miembros", guild.MemberCount),
+		Avatar:   fetchAvatar(user),
+	}
+	data, err := imaging.RenderWelcomeCard(card, Style(guildDoc))
+	if err != nil {
+		return nil, err
+	}
+	return pngFile(FarewellFileName, data), nil
+}
+
+// Rank renders the level card of a user
+func Rank(user *discordgo.User, profile *models.UserLevelProfile, guildDoc *models.GuildDocument) (*discordgo.File, error) {
+	rank, err := database.GetLevelRank(profile.GuildID, profile.XP)
+	if err != nil {
+		logger.Debug(fmt.Sprintf("No se pudo calcular la posición de %s: %v", user.ID, err), "Cards")
+		rank = 0
+	}
+
+	var levels models.LevelsConfig
+	if guildDoc != nil {
+		levels = guildDoc.Levels
+	}
+	rules := leveling.Resolve(levels)
+
+	card := imaging.RankCard{
+		Username:      user.Username,
+		Avatar:        fetchAvatar(user),
+		Level:         profile.Level,
+		XP:            profile.XP,
+		LevelXP:       rules.XPForLevel(profile.Level),
+		NextLevelXP:   rules.XPForLevel(profile.Level + 1),
+		Rank:          rank,
+		TotalMessages: profile.TotalMessages,
+	}
+	if profile.VoiceSeconds > 0 {
+		card.VoiceTime = leveling.FormatVoice(profile.VoiceSeconds)
+	}
+	data, err := imaging.RenderRankCard(card, Style(guildDoc))
+	if err != nil {
+		return nil, err
+	}
+	return pngFile(RankFileName, data), nil
+}
+
+// fetchAvatar downloads the avatar; cards fall back to a placeholder circle on error
+func fetchAvatar(user *discordgo.User) image.Image {
+	avatar, err := imaging.FetchImage(user.AvatarURL("256"))
+	if err != nil {
+		logger.Debug(fmt.Sprintf("No se pudo descargar el avatar de %s: %v", user.ID, err), "Cards")
+		return nil
+	}
+	return avatar
+}
+
+func pngFile(name string, data []byte) *discordgo.File {
+	return &discordgo.File{
+		Name:        name,
+		ContentType: "image/png",
+		Reader:      bytes.NewReader(data),
+	}
+}
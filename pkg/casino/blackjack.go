@@ -0,0 +1,332 @@
+package casino
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BlackjackTimeout is how long a blackjack table waits for the player; when it
+// runs out the player stands
+const BlackjackTimeout = 2 * time.Minute
+
+// ErrCannotDouble is returned when doubling after the first two cards
+var ErrCannotDouble = errors.New("can only double on the first two cards")
+
+// Outcomes of a blackjack hand
+const (
+	OutcomeBlackjack = "blackjack"
+	OutcomeWin       = "win"
+	OutcomePush      = "push"
+	OutcomeLose      = "lose"
+)
+
+var outcomeMultipliers = map[string]float64{
+	OutcomeBlackjack: 2.5,
+	OutcomeWin:       2,
+	OutcomePush:      1,
+	OutcomeLose:      0,
+}
+
+// Card is a playing card; Rank goes from 1 (ace) to 13 (king)
+type Card struct {
+	Rank int
+	Suit int
+}
+
+var suits = []string{"♠️", "♥️", "♦️", "♣️"}
+
+func (c Card) String() string {
+	rank := fmt.Sprint(c.Rank)
+	switch c.Rank {
+	case 1:
+		rank = "A"
+	case 11:
+		rank = "J"
+	case 12:
+		rank = "Q"
+	case 13:
+		rank = "K"
+	}
+	return rank + suits[c.Suit]
+}
+
+// HandValue returns the best total of a hand, counting aces as 11 while it does
+// not bust
+func HandValue(hand []Card) int {
+	total, aces := 0, 0
+	for _, card := range hand {
+		switch {
+		case card.Rank == 1:
+			total++
+			aces++
+		case card.Rank > 10:
+			total += 10
+		default:
+			total += card.Rank
+		}
+	}
+	if aces > 0 && total+10 <= 21 {
+		total += 10
+	}
+	return total
+}
+
+// IsBlackjack reports whether a hand is a natural 21
+func IsBlackjack(hand []Card) bool {
+	return len(hand) == 2 && HandValue(hand) == 21
+}
+
+// Blackjack is a hand of blackjack against the dealer
+type Blackjack struct {
+	ID        string
+	ChannelID string
+	MessageID string
+	Wager     Wager // Doubling doubles its amount
+	Player    []Card
+	Dealer    []Card
+	Outcome   string // Empty while the hand is being played
+	Paid      int64
+	ExpiresAt time.Time
+}
+
+// Finished reports whether the hand is over
+func (b Blackjack) Finished() bool {
+	return b.Outcome != ""
+}
+
+// CanDouble reports whether the player can still double
+func (b Blackjack) CanDouble() bool {
+	return !b.Finished() && len(b.Player) == 2
+}
+
+// table is a blackjack hand in play. Its mutex is held during the whole action,
+// ledger writes included, so a click and the timeout never settle it twice.
+type table struct {
+	mu     sync.Mutex
+	userID string
+	game   Blackjack
+	deck   []Card
+}
+
+var (
+	tables   = make(map[string]*table)
+	tablesMu sync.Mutex
+)
+
+// StartBlackjack takes the bet and deals the first cards. A natural blackjack of
+// either side ends the hand at once.
+func StartBlackjack(guildID, channelID, userID string, global bool, amount int64) (Blackjack, error) {
+	tablesMu.Lock()
+	for _, t := range tables {
+		if t.userID == userID {
+			tablesMu.Unlock()
+			return Blackjack{}, ErrAlreadyPlaying
+		}
+	}
+	t := &table{userID: userID, deck: newDeck()}
+	t.game.ID = uuid.New().String()[:8]
+	tables[t.game.ID] = t
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tablesMu.Unlock()
+
+	w, err := Place(guildID, userID, global, GameBlackjack, amount)
+	if err != nil {
+		removeTable(t.game.ID)
+		return Blackjack{}, err
+	}
+
+	t.game.ChannelID = channelID
+	t.game.Wager = w
+	t.game.ExpiresAt = time.Now().Add(BlackjackTimeout)
+	w.track(t.game.ID, t.game.ExpiresAt)
+	t.game.Player = []Card{t.draw(), t.draw()}
+	t.game.Dealer = []Card{t.draw(), t.draw()}
+	if IsBlackjack(t.game.Player) || IsBlackjack(t.game.Dealer) {
+		err = t.settle()
+	}
+	return t.game, err
+}
+
+// SetBlackjackMessage remembers the message that shows the table
+func SetBlackjackMessage(gameID, messageID string) {
+	t, err := lockTable(gameID)
+	if err != nil {
+		return
+	}
+	defer t.mu.Unlock()
+	t.game.MessageID = messageID
+}
+
+// Hit draws a card for the player; busting ends the hand
+func Hit(gameID, userID string) (Blackjack, error) {
+	t, err := playerTable(gameID, userID)
+	if err != nil {
+		return Blackjack{}, err
+	}
+	defer t.mu.Unlock()
+
+	t.game.Player = append(t.game.Player, t.draw())
+	if HandValue(t.game.Player) >= 21 {
+		err = t.settle()
+	}
+	return t.game, err
+}
+
+// Stand ends the turn of the player; the dealer draws and the hand is settled
+func Stand(gameID, userID string) (Blackjack, error) {
+	t, err := playerTable(gameID, userID)
+	if err != nil {
+		return Blackjack{}, err
+	}
+	defer t.mu.Unlock()
+
+	err = t.settle()
+	return t.game, err
+}
+
+// Double doubles the bet, draws exactly one card and stands
+func Double(gameID, userID string) (Blackjack, error) {
+	t, err := playerTable(gameID, userID)
+	if err != nil {
+		return Blackjack{}, err
+	}
+	defer t.mu.Unlock()
+
+	if !t.game.CanDouble() {
+		return t.game, ErrCannotDouble
+	}
+	w := t.game.Wager
+	// The second stake may go over the max bet, it was already accepted once
+	if err := w.apply(-w.Amount, txBet(w, "Doblar en blackjack")); err != nil {
+		return t.game, err
+	}
+	t.game.Wager.Amount *= 2
+	t.game.Wager.track(t.game.ID, t.game.ExpiresAt)
+	t.game.Player = append(t.game.Player, t.draw())
+	err = t.settle()
+	return t.game, err
+}
+
+// ExpireBlackjack stands for a player that stopped playing. It reports false if
+// the hand was already over.
+func ExpireBlackjack(gameID string) (Blackjack, bool, error) {
+	t, err := lockTable(gameID)
+	if err != nil {
+		return Blackjack{}, false, nil
+	}
+	defer t.mu.Unlock()
+	return t.game, true, t.settle()
+}
+
+// settle plays the dealer, pays the hand and closes the table; t.mu must be held
+func (t *table) settle() error {
+	g := &t.game
+	player := HandValue(g.Player)
+
+	// The dealer only plays when the player is still in the hand
+	if player <= 21 && !IsBlackjack(g.Player) {
+		for HandValue(g.Dealer) < 17 {
+			g.Dealer = append(g.Dealer, t.draw())
+		}
+	}
+	dealer := HandValue(g.Dealer)
+
+	switch {
+	case IsBlackjack(g.Player) && IsBlackjack(g.Dealer):
+		g.Outcome = OutcomePush
+	case IsBlackjack(g.Player):
+		g.Outcome = OutcomeBlackjack
+	case player > 21 || IsBlackjack(g.Dealer):
+		g.Outcome = OutcomeLose
+	case dealer > 21 || player > dealer:
+		g.Outcome = OutcomeWin
+	case player == dealer:
+		g.Outcome = OutcomePush
+	default:
+		g.Outcome = OutcomeLose
+	}
+
+	removeTable(g.ID)
+	paid, err := g.Wager.Settle(outcomeMultipliers[g.Outcome], "", fmt.Sprintf("Blackjack: %d contra %d", player, dealer))
+	g.Paid = paid
+	if err == nil {
+		// A hand that could not be paid keeps its stake stored to be refunded
+		untrack(g.ID)
+	}
+	return err
+}
+
+func (t *table) draw() Card {
+	if len(t.deck) == 0 {
+		t.deck = newDeck()
+	}
+	card := t.deck[len(t.deck)-1]
+	t.deck = t.deck[:len(t.deck)-1]
+	return card
+}
+
+func newDeck() []Card {
+	deck := make([]Card, 0, 52)
+	for suit := range suits {
+		for rank := 1; rank <= 13; rank++ {
+			deck = append(deck, Card{Rank: rank, Suit: suit})
+		}
+	}
+	rand.Shuffle(len(deck), func(i, j int) { deck[i], deck[j] = deck[j], deck[i] })
+	return deck
+}
+
+// lockTable returns an open table with its mutex held
+func lockTable(gameID string) (*table, error) {
+	tablesMu.Lock()
+	t, ok := tables[gameID]
+	tablesMu.Unlock()
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	t.mu.Lock()
+	if t.game.Finished() {
+		t.mu.Unlock()
+		return nil, ErrNotFound
+	}
+	return t, nil
+}
+
+// playerTable is lockTable for an action of the player
+func playerTable(gameID, userID string) (*table, error) {
+	t, err := lockTable(gameID)
+	if err != nil {
+		return nil, err
+	}
+	if t.userID != userID {
+		t.mu.Unlock()
+		return nil, ErrNotPlayer
+	}
+	return t, nil
+}
+
+func removeTable(gameID string) {
+	tablesMu.Lock()
+	defer tablesMu.Unlock()
+	delete(tables, gameID)
+}
+
+// DescribeHand renders the cards of a hand and its value
+func DescribeHand(hand []Card, hideHole bool) string {
+	if hideHole {
+		return fmt.Sprintf("%s 🂠 (%d)", hand[0], HandValue(hand[:1]))
+	}
+	cards := make([]string, len(hand))
+	for i, card := range hand {
+		cards[i] = card.String()
+	}
+	return fmt.Sprintf("%s (%d)", strings.Join(cards, " "), HandValue(hand))
+}
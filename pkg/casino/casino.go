@@ -0,0 +1,139 @@
+// Package casino implements the games of chance of the economy. Every bet is taken
+// from the wallet before the game is played and every prize is paid back through
+// the ledger, so the history of a user shows each stake and each win.
+package casino
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/ecoconfig"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+)
+
+// Games, used as the command of the ledger entries
+const (
+	GameBlackjack = "blackjack"
+	GameSlots     = "slots"
+	GameCoinflip  = "coinflip"
+	GameRoulette  = "roulette"
+	GameDuel      = "duel"
+)
+
+var (
+	ErrDisabled       = errors.New("casino disabled")
+	ErrBetTooSmall    = errors.New("bet below the minimum")
+	ErrBetTooLarge    = errors.New("bet above the maximum")
+	ErrNotFound       = errors.New("game not found or finished")
+	ErrNotPlayer      = errors.New("user is not playing this game")
+	ErrAlreadyPlaying = errors.New("user already has an open game")
+	ErrInvalidBet     = errors.New("invalid bet")
+)
+
+// Wager is a stake already taken from the wallet of a player
+type Wager struct {
+	GuildID string
+	UserID  string
+	Global  bool
+	Game    string
+	Amount  int64
+	Rules   ecoconfig.CasinoRules
+}
+
+// Check validates a bet against the casino rules of its economy
+func Check(rules ecoconfig.CasinoRules, amount int64) error {
+	switch {
+	case !rules.Enabled:
+		return ErrDisabled
+	case amount < rules.MinBet:
+		return ErrBetTooSmall
+	case amount > rules.MaxBet:
+		return ErrBetTooLarge
+	}
+	return nil
+}
+
+// Place checks a bet and takes it from the wallet of the player
+func Place(guildID, userID string, global bool, game string, amount int64) (Wager, error) {
+	rules := ecoconfig.CasinoFor(guildID, global)
+	if err := Check(rules, amount); err != nil {
+		return Wager{}, err
+	}
+
+	w := Wager{GuildID: guildID, UserID: userID, Global: global, Game: game, Amount: amount, Rules: rules}
+	if err := w.apply(-amount, txBet(w, fmt.Sprintf("Apuesta en %s", game))); err != nil {
+		return Wager{}, err
+	}
+	return w, nil
+}
+
+// Settle pays the prize of a wager for a multiplier of the stake (2 = double) and
+// returns the amount paid. The house keeps its edge from the profit only, so a
+// returned stake is never taxed.
+func (w Wager) Settle(multiplier float64, counterparty, note string) (int64, error) {
+	paid := Payout(w.Amount, multiplier, w.Rules.HouseEdge)
+	if paid <= 0 {
+		return 0, nil
+	}
+	info := database.TxInfo{Type: models.TransactionPrize, Command: w.Game, Counterparty: counterparty, Note: note}
+	if err := w.apply(paid, info); err != nil {
+		return 0, err
+	}
+	return paid, nil
+}
+
+// Refund returns the stake untouched
+func (w Wager) Refund(note string) error {
+	return w.apply(w.Amount, database.TxInfo{Type: models.TransactionRefund, Command: w.Game, Note: note})
+}
+
+func txBet(w Wager, note string) database.TxInfo {
+	return database.TxInfo{Type: models.TransactionBet, Command: w.Game, Note: note}
+}
+
+func (w Wager) apply(amount int64, info database.TxInfo) error {
+	change := database.BalanceChange{Wallet: amount}
+	if w.Global {
+		_, err := database.ApplyStarsChange(w.UserID, change, info)
+		return err
+	}
+	_, err := database.ApplyLocalChange(w.GuildID, w.UserID, change, info)
+	return err
+}
+
+// Payout returns what a bet pays for a multiplier once the house edge is taken
+// from the profit
+func Payout(bet int64, multiplier, edge float64) int64 {
+	gross := int64(math.Floor(float64(bet) * multiplier))
+	if gross <= bet {
+		return gross
+	}
+	profit := gross - bet
+	return bet + profit - int64(math.Ceil(float64(profit)*edge))
+}
+
+// ErrorText explains a casino error to the player
+func ErrorText(err error, rules ecoconfig.CasinoRules) string {
+	switch {
+	case errors.Is(err, ErrDisabled):
+		return "❌ El casino está desactivado en este servidor."
+	case errors.Is(err, ErrBetTooSmall):
+		return fmt.Sprintf("❌ La apuesta mínima es de %s.", rules.Currency.Format(rules.MinBet))
+	case errors.Is(err, ErrBetTooLarge):
+		return fmt.Sprintf("❌ La apuesta máxima es de %s.", rules.Currency.Format(rules.MaxBet))
+	case errors.Is(err, database.ErrInsufficientFunds):
+		return fmt.Sprintf("❌ No tienes suficientes %s en tu cartera.", rules.Currency.Name)
+	case errors.Is(err, ErrAlreadyPlaying):
+		return "❌ Ya tienes una partida abierta. Termínala antes de empezar otra."
+	case errors.Is(err, ErrNotFound):
+		return "⌛ Esta partida ya terminó."
+	case errors.Is(err, ErrNotPlayer):
+		return "❌ Esta partida no es tuya."
+	case errors.Is(err, ErrInvalidBet):
+		return "❌ Esa apuesta no es válida."
+	default:
+		return "❌ Ocurrió un error con la apuesta."
+	}
+}
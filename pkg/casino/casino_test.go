@@ -0,0 +1,90 @@
+package casino
+
+import (
+	"testing"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/ecoconfig"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+)
+
+func TestHandValue(t *testing.T) {
+	tests := []struct {
+		hand []Card
+		want int
+	}{
+		{[]Card{{Rank: 1}, {Rank: 13}}, 21},
+		{[]Card{{Rank: 1}, {Rank: 1}, {Rank: 9}}, 21},
+		{[]Card{{Rank: 1}, {Rank: 6}, {Rank: 10}}, 17},
+		{[]Card{{Rank: 12}, {Rank: 11}, {Rank: 5}}, 25},
+	}
+	for _, tt := range tests {
+		if got := HandValue(tt.hand); got != tt.want {
+			t.Errorf("HandValue(%v) = %d, want %d", tt.hand, got, tt.want)
+		}
+	}
+	if IsBlackjack([]Card{{Rank: 7}, {Rank: 7}, {Rank: 7}}) {
+		t.Error("three cards are never a natural blackjack")
+	}
+}
+
+func TestPayoutTakesEdgeFromProfit(t *testing.T) {
+	if got := Payout(100, 2, 0.03); got != 197 {
+		t.Errorf("Payout(100, 2, 3%%) = %d, want 197", got)
+	}
+	if got := Payout(100, 1, 0.5); got != 100 {
+		t.Errorf("a push must return the whole stake, got %d", got)
+	}
+	if got := Payout(100, 0, 0.03); got != 0 {
+		t.Errorf("a loss must pay nothing, got %d", got)
+	}
+}
+
+func TestRouletteMultiplier(t *testing.T) {
+	tests := []struct {
+		bet    string
+		number int
+		want   float64
+	}{
+		{"rojo", 1, 2},
+		{"negro", 1, 0},
+		{"par", 0, 0},
+		{"impar", 0, 0},
+		{"alto", 36, 2},
+		{"17", 17, 36},
+		{"17", 18, 0},
+	}
+	for _, tt := range tests {
+		got, err := RouletteMultiplier(tt.bet, tt.number)
+		if err != nil || got != tt.want {
+			t.Errorf("RouletteMultiplier(%q, %d) = %v, %v; want %v", tt.bet, tt.number, got, err, tt.want)
+		}
+	}
+	for _, bet := range []string{"verde", "37", "-1"} {
+		if _, err := RouletteMultiplier(bet, 0); err == nil {
+			t.Errorf("bet %q should be rejected", bet)
+		}
+	}
+}
+
+func TestSlotsUseGuildTable(t *testing.T) {
+	cfg := models.EconomyConfig{Casino: models.CasinoConfig{SlotsPayouts: map[string]float64{"🍒": 7}, SlotsPair: 1.5}}
+	rules := ecoconfig.ResolveCasino(cfg, false)
+
+	if got := SlotsMultiplier(rules, [3]string{"🍒", "🍒", "🍒"}); got != 7 {
+		t.Errorf("three cherries pay x%v, want x7", got)
+	}
+	if got := SlotsMultiplier(rules, [3]string{"💎", "💎", "💎"}); got != 50 {
+		t.Errorf("three diamonds pay x%v, want the default x50", got)
+	}
+	if got := SlotsMultiplier(rules, [3]string{"🔔", "🍋", "🔔"}); got != 1.5 {
+		t.Errorf("a pair pays x%v, want x1.5", got)
+	}
+	if got := SlotsMultiplier(rules, [3]string{"🔔", "🍋", "🍇"}); got != 0 {
+		t.Errorf("no match pays x%v, want nothing", got)
+	}
+
+	// The global economy ignores the server table
+	if got := SlotsMultiplier(ecoconfig.ResolveCasino(cfg, true), [3]string{"🍒", "🍒", "🍒"}); got != 5 {
+		t.Errorf("three cherries in Stars pay x%v, want x5", got)
+	}
+}
@@ -0,0 +1,186 @@
+package casino
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DuelTimeout is how long a challenge waits for the opponent
+const DuelTimeout = 2 * time.Minute
+
+// Duel is a challenge between two players. Both stakes are held by the bot until
+// the duel is decided; the winner takes the pot minus the house edge.
+type Duel struct {
+	ID         string
+	ChannelID  string
+	MessageID  string
+	Challenger Wager // Already taken
+	OpponentID string
+	Winner     string // Empty until the duel is decided
+	Paid       int64
+	ExpiresAt  time.Time
+
+	onExpire func(d Duel, err error) // Set by WatchDuel
+}
+
+var (
+	duels   = make(map[string]*Duel)
+	duelsMu sync.Mutex
+)
+
+// Challenge takes the stake of the challenger and opens a duel. A user can only
+// have one open challenge.
+func Challenge(guildID, channelID, challengerID, opponentID string, global bool, amount int64) (Duel, error) {
+	duelsMu.Lock()
+	for _, d := range duels {
+		if d.Challenger.UserID == challengerID {
+			duelsMu.Unlock()
+			return Duel{}, ErrAlreadyPlaying
+		}
+	}
+	// Hold the place of the challenger while the stake is taken; nobody knows
+	// the ID yet, so the duel cannot be answered before it is ready
+	d := &Duel{
+		ID:         uuid.New().String()[:8],
+		ChannelID:  channelID,
+		Challenger: Wager{UserID: challengerID},
+		OpponentID: opponentID,
+	}
+	duels[d.ID] = d
+	duelsMu.Unlock()
+
+	w, err := Place(guildID, challengerID, global, GameDuel, amount)
+	if err != nil {
+		duelsMu.Lock()
+		delete(duels, d.ID)
+		duelsMu.Unlock()
+		return Duel{}, err
+	}
+
+	duelsMu.Lock()
+	d.Challenger = w
+	d.ExpiresAt = time.Now().Add(DuelTimeout)
+	open := *d
+	duelsMu.Unlock()
+	w.track(open.ID, open.ExpiresAt)
+	return open, nil
+}
+
+// SetDuelMessage remembers the message that shows the challenge
+func SetDuelMessage(duelID, messageID string) {
+	duelsMu.Lock()
+	defer duelsMu.Unlock()
+	if d, ok := duels[duelID]; ok {
+		d.MessageID = messageID
+	}
+}
+
+// WatchDuel closes the duel and returns the stake of the challenger once it
+// times out, then calls onExpire with the closed duel
+func WatchDuel(duelID string, onExpire func(d Duel, err error)) {
+	duelsMu.Lock()
+	d, ok := duels[duelID]
+	if ok {
+		d.onExpire = onExpire
+	}
+	duelsMu.Unlock()
+	if ok {
+		armExpiry(*d)
+	}
+}
+
+// armExpiry closes the duel when its timeout passes. A duel that was answered
+// in the meantime is left alone.
+func armExpiry(d Duel) {
+	time.AfterFunc(time.Until(d.ExpiresAt), func() {
+		expired, ok, err := ExpireDuel(d.ID)
+		if ok && expired.onExpire != nil {
+			expired.onExpire(expired, err)
+		}
+	})
+}
+
+// Accept takes the stake of the opponent and decides the duel with a coin flip.
+// If the opponent cannot pay the challenge stays open.
+func Accept(duelID, userID string) (Duel, error) {
+	d, err := claimDuel(duelID, userID, true)
+	if err != nil {
+		return Duel{}, err
+	}
+
+	opponent := d.Challenger
+	opponent.UserID = userID
+	info := txBet(opponent, fmt.Sprintf("Apuesta del duelo %s", d.ID))
+	info.Counterparty = d.Challenger.UserID
+	if err := opponent.apply(-opponent.Amount, info); err != nil {
+		// The timer may have fired while the duel was claimed, so arm it again;
+		// it closes the duel right away if the timeout already passed
+		duelsMu.Lock()
+		duels[d.ID] = &d
+		duelsMu.Unlock()
+		armExpiry(d)
+		return d, err
+	}
+
+	winner, loser := d.Challenger, opponent
+	if rand.Intn(2) == 1 {
+		winner, loser = opponent, d.Challenger
+	}
+	d.Winner = winner.UserID
+	d.Paid, err = winner.Settle(2, loser.UserID, fmt.Sprintf("Duelo %s ganado", d.ID))
+	if err == nil {
+		untrack(d.ID)
+	}
+	return d, err
+}
+
+// Decline closes a duel and returns the stake of the challenger. Both players can
+// decline it.
+func Decline(duelID, userID string) (Duel, error) {
+	d, err := claimDuel(duelID, userID, false)
+	if err != nil {
+		return Duel{}, err
+	}
+	return d, refundDuel(d, fmt.Sprintf("Duelo %s rechazado", d.ID))
+}
+
+// ExpireDuel closes a duel nobody answered and returns the stake of the
+// challenger. It reports false if the duel was already closed.
+func ExpireDuel(duelID string) (Duel, bool, error) {
+	duelsMu.Lock()
+	d, ok := duels[duelID]
+	delete(duels, duelID)
+	duelsMu.Unlock()
+	if !ok {
+		return Duel{}, false, nil
+	}
+	return *d, true, refundDuel(*d, fmt.Sprintf("Duelo %s expirado", d.ID))
+}
+
+// refundDuel returns the stake of the challenger of a closed duel
+func refundDuel(d Duel, note string) error {
+	if err := d.Challenger.Refund(note); err != nil {
+		return err
+	}
+	untrack(d.ID)
+	return nil
+}
+
+// claimDuel removes an open duel so only one action can close it
+func claimDuel(duelID, userID string, opponentOnly bool) (Duel, error) {
+	duelsMu.Lock()
+	defer duelsMu.Unlock()
+	d, ok := duels[duelID]
+	if !ok {
+		return Duel{}, ErrNotFound
+	}
+	if userID != d.OpponentID && (opponentOnly || userID != d.Challenger.UserID) {
+		return Duel{}, ErrNotPlayer
+	}
+	delete(duels, duelID)
+	return *d, nil
+}
@@ -0,0 +1,185 @@
+package casino
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/ecoconfig"
+)
+
+// Sides of the coin
+const (
+	Heads = "cara"
+	Tails = "cruz"
+)
+
+// Result is the outcome of an instant game (coinflip, roulette, slots)
+type Result struct {
+	Wager  Wager
+	Paid   int64  // Total paid back, stake included
+	Detail string // What came out, e.g. "🔴 17"
+}
+
+// Net returns the profit (or the loss, negative) of the game
+func (r Result) Net() int64 {
+	return r.Paid - r.Wager.Amount
+}
+
+// Coinflip bets on a side of a coin; a hit pays double
+func Coinflip(guildID, userID string, global bool, amount int64, side string) (Result, error) {
+	side = strings.ToLower(side)
+	if side != Heads && side != Tails {
+		return Result{}, ErrInvalidBet
+	}
+
+	w, err := Place(guildID, userID, global, GameCoinflip, amount)
+	if err != nil {
+		return Result{}, err
+	}
+
+	landed := Heads
+	if rand.Intn(2) == 1 {
+		landed = Tails
+	}
+	multiplier := 0.0
+	if landed == side {
+		multiplier = 2
+	}
+	return finish(w, multiplier, fmt.Sprintf("🪙 %s", landed))
+}
+
+// redNumbers are the red pockets of the roulette wheel
+var redNumbers = map[int]bool{
+	1: true, 3: true, 5: true, 7: true, 9: true, 12: true, 14: true, 16: true, 18: true,
+	19: true, 21: true, 23: true, 25: true, 27: true, 30: true, 32: true, 34: true, 36: true,
+}
+
+// RouletteTable describes what the roulette bets pay
+const RouletteTable = "Color, paridad y mitad pagan x2 · Número x36 · El 0 gana a la banca"
+
+// RouletteMultiplier returns what a bet pays when the ball lands on a number, or 0
+func RouletteMultiplier(bet string, number int) (float64, error) {
+	if n, err := strconv.Atoi(bet); err == nil {
+		if n < 0 || n > 36 {
+			return 0, ErrInvalidBet
+		}
+		if n == number {
+			return 36, nil
+		}
+		return 0, nil
+	}
+
+	// Zero loses every outside bet
+	var hit bool
+	switch bet {
+	case "rojo":
+		hit = redNumbers[number]
+	case "negro":
+		hit = number != 0 && !redNumbers[number]
+	case "par":
+		hit = number != 0 && number%2 == 0
+	case "impar":
+		hit = number%2 == 1
+	case "bajo":
+		hit = number >= 1 && number <= 18
+	case "alto":
+		hit = number >= 19
+	default:
+		return 0, ErrInvalidBet
+	}
+	if hit {
+		return 2, nil
+	}
+	return 0, nil
+}
+
+// Roulette spins a single zero wheel
+func Roulette(guildID, userID string, global bool, amount int64, bet string) (Result, error) {
+	bet = strings.ToLower(bet)
+	if _, err := RouletteMultiplier(bet, 0); err != nil {
+		return Result{}, err
+	}
+
+	w, err := Place(guildID, userID, global, GameRoulette, amount)
+	if err != nil {
+		return Result{}, err
+	}
+
+	number := rand.Intn(37)
+	multiplier, _ := RouletteMultiplier(bet, number)
+	color := "🟢"
+	if number != 0 {
+		color = "⚫"
+		if redNumbers[number] {
+			color = "🔴"
+		}
+	}
+	return finish(w, multiplier, fmt.Sprintf("%s %d", color, number))
+}
+
+// SlotsMultiplier returns what a spin pays: three of a kind pays its symbol in the
+// table and any pair pays the pair multiplier
+func SlotsMultiplier(rules ecoconfig.CasinoRules, reels [3]string) float64 {
+	switch {
+	case reels[0] == reels[1] && reels[1] == reels[2]:
+		return rules.SlotsPayouts[reels[0]]
+	case reels[0] == reels[1] || reels[1] == reels[2] || reels[0] == reels[2]:
+		return rules.SlotsPair
+	}
+	return 0
+}
+
+// Slots spins the three reels of the slot machine
+func Slots(guildID, userID string, global bool, amount int64) (Result, error) {
+	w, err := Place(guildID, userID, global, GameSlots, amount)
+	if err != nil {
+		return Result{}, err
+	}
+
+	var reels [3]string
+	for i := range reels {
+		reels[i] = spinReel()
+	}
+	multiplier := SlotsMultiplier(w.Rules, reels)
+	return finish(w, multiplier, strings.Join(reels[:], " | "))
+}
+
+func spinReel() string {
+	n := rand.Intn(100)
+	for _, symbol := range ecoconfig.SlotSymbols {
+		n -= ecoconfig.SlotWeight(symbol)
+		if n < 0 {
+			return symbol
+		}
+	}
+	return ecoconfig.SlotSymbols[0]
+}
+
+// finish pays an instant game
+func finish(w Wager, multiplier float64, detail string) (Result, error) {
+	result := Result{Wager: w, Detail: detail}
+	if multiplier <= 0 {
+		return result, nil
+	}
+	paid, err := w.Settle(multiplier, "", fmt.Sprintf("Premio de %s: %s", w.Game, detail))
+	if err != nil {
+		return result, err
+	}
+	result.Paid = paid
+	return result, nil
+}
+
+// Describe renders the outcome of an instant game for the reply
+func (r Result) Describe() string {
+	currency := r.Wager.Rules.Currency
+	switch {
+	case r.Net() > 0:
+		return fmt.Sprintf("%s\n\n🎉 ¡Ganaste! Recibes %s (%s de beneficio).", r.Detail, currency.Format(r.Paid), currency.Format(r.Net()))
+	case r.Paid > 0:
+		return fmt.Sprintf("%s\n\n😐 Recuperas %s de tu apuesta de %s.", r.Detail, currency.Format(r.Paid), currency.Format(r.Wager.Amount))
+	default:
+		return fmt.Sprintf("%s\n\n💸 Perdiste %s.", r.Detail, currency.Format(r.Wager.Amount))
+	}
+}
@@ -0,0 +1,64 @@
+package casino
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+)
+
+// AbandonGrace is how long after its timeout an open game is considered lost.
+// A running bot settles it at the timeout, so only games that were open when
+// the bot stopped are still stored after it.
+const AbandonGrace = 5 * time.Minute
+
+// track stores the stake of an open game, so it can be refunded if the bot
+// stops before the game is settled
+func (w Wager) track(gameID string, expiresAt time.Time) {
+	err := database.SaveOpenWager(&models.OpenWager{
+		ID:        gameID,
+		GuildID:   w.GuildID,
+		UserID:    w.UserID,
+		Global:    w.Global,
+		Game:      w.Game,
+		Amount:    w.Amount,
+		CreatedAt: time.Now(),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		logger.Warn(fmt.Sprintf("No se pudo guardar la apuesta abierta %s: %v", gameID, err), "Casino")
+	}
+}
+
+// untrack forgets the stake of a game once it has been paid or refunded
+func untrack(gameID string) {
+	if err := database.CloseOpenWager(gameID); err != nil {
+		logger.Warn(fmt.Sprintf("No se pudo cerrar la apuesta abierta %s: %v", gameID, err), "Casino")
+	}
+}
+
+// RefundAbandoned returns the stakes of the games that were open when the bot
+// stopped and returns how many were refunded
+func RefundAbandoned() (int, error) {
+	abandoned, err := database.GetAbandonedWagers(time.Now().Add(-AbandonGrace))
+	if err != nil {
+		return 0, err
+	}
+
+	refunded := 0
+	for _, open := range abandoned {
+		claimed, err := database.ClaimOpenWager(open.ID)
+		if err != nil || !claimed {
+			continue
+		}
+		w := Wager{GuildID: open.GuildID, UserID: open.UserID, Global: open.Global, Game: open.Game, Amount: open.Amount}
+		if err := w.Refund(fmt.Sprintf("Partida %s cerrada por un reinicio", open.ID)); err != nil {
+			logger.Error(fmt.Sprintf("Error devolviendo la apuesta abierta %s de %s: %v", open.ID, open.UserID, err), "Casino")
+			continue
+		}
+		refunded++
+	}
+	return refunded, nil
+}
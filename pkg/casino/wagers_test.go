@@ -0,0 +1,156 @@
+package casino
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+)
+
+var testGuilds atomic.Int64
+
+// useMemoryDatabase gives alice 1000 coins in a new guild on a fresh in-memory
+// database. The cache outlives the database, so each test uses its own guild.
+func useMemoryDatabase(t *testing.T) string {
+	t.Helper()
+	database.InitGlobalDataManagers(database.NewMemoryDatabase())
+	guildID := fmt.Sprintf("g%d", testGuilds.Add(1))
+	if _, err := database.AddLocalBalance(guildID, "alice", 1000, false, database.TxInfo{Type: models.TransactionReward}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		duelsMu.Lock()
+		duels = make(map[string]*Duel)
+		duelsMu.Unlock()
+	})
+	return guildID
+}
+
+func wallet(t *testing.T, guildID, userID string) int64 {
+	t.Helper()
+	profile, err := database.GetLocalProfile(guildID, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return profile.Wallet
+}
+
+func TestChallengeTakesOneStake(t *testing.T) {
+	guildID := useMemoryDatabase(t)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	opened := 0
+	for n := 0; n < 10; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := Challenge(guildID, "c1", "alice", "bob", false, 100); err == nil {
+				mu.Lock()
+				opened++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if opened != 1 {
+		t.Errorf("Expected a single open challenge, got %d", opened)
+	}
+	if got := wallet(t, guildID, "alice"); got != 900 {
+		t.Errorf("Expected one stake taken, wallet = %d", got)
+	}
+}
+
+func TestAbandonedWagersAreRefunded(t *testing.T) {
+	guildID := useMemoryDatabase(t)
+
+	d, err := Challenge(guildID, "c1", "alice", "bob", false, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A live duel is left alone
+	if n, err := RefundAbandoned(); err != nil || n != 0 {
+		t.Fatalf("RefundAbandoned() = %d, %v for a live duel", n, err)
+	}
+
+	// The bot restarts: the duel is lost and its timeout passed long ago
+	duelsMu.Lock()
+	duels = make(map[string]*Duel)
+	duelsMu.Unlock()
+	if err := database.SaveOpenWager(&models.OpenWager{
+		ID:        d.ID,
+		GuildID:   guildID,
+		UserID:    "alice",
+		Game:      GameDuel,
+		Amount:    100,
+		ExpiresAt: time.Now().Add(-AbandonGrace - time.Minute),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := RefundAbandoned(); err != nil || n != 1 {
+		t.Fatalf("RefundAbandoned() = %d, %v, want 1", n, err)
+	}
+	if n, _ := RefundAbandoned(); n != 0 {
+		t.Errorf("Expected the stake to be refunded once, got %d more", n)
+	}
+	if got := wallet(t, guildID, "alice"); got != 1000 {
+		t.Errorf("Expected the stake back, wallet = %d", got)
+	}
+}
+
+func TestSettledDuelIsForgotten(t *testing.T) {
+	guildID := useMemoryDatabase(t)
+
+	d, err := Challenge(guildID, "c1", "alice", "bob", false, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Decline(d.ID, "bob"); err != nil {
+		t.Fatal(err)
+	}
+	open, err := database.GetAbandonedWagers(time.Now().Add(time.Hour))
+	if err != nil || len(open) != 0 {
+		t.Errorf("Expected no open wagers after declining, got %v, %v", open, err)
+	}
+	if got := wallet(t, guildID, "alice"); got != 1000 {
+		t.Errorf("Expected the stake back, wallet = %d", got)
+	}
+}
+
+func TestFailedAcceptKeepsTheTimeout(t *testing.T) {
+	guildID := useMemoryDatabase(t)
+
+	d, err := Challenge(guildID, "c1", "alice", "bob", false, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired := make(chan Duel, 1)
+	WatchDuel(d.ID, func(d Duel, err error) { expired <- d })
+
+	// The timeout passes while bob, who has no coins, tries to accept
+	duelsMu.Lock()
+	duels[d.ID].ExpiresAt = time.Now()
+	duelsMu.Unlock()
+	if _, err := Accept(d.ID, "bob"); err == nil {
+		t.Fatal("Expected bob to be unable to pay the stake")
+	}
+
+	select {
+	case got := <-expired:
+		if got.ID != d.ID {
+			t.Errorf("Expected duel %s to expire, got %s", d.ID, got.ID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the duel to expire after the failed accept")
+	}
+	if got := wallet(t, guildID, "alice"); got != 1000 {
+		t.Errorf("Expected the stake back, wallet = %d", got)
+	}
+}
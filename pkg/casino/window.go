@@ -0,0 +1,223 @@
+package casino
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/bwmarrin/discordgo"
+)
+
+// Custom ID prefixes of the casino buttons, followed by the game or duel ID
+const (
+	HitButtonPrefix     = "bj_hit_"
+	StandButtonPrefix   = "bj_stand_"
+	DoubleButtonPrefix  = "bj_double_"
+	AcceptButtonPrefix  = "duel_accept_"
+	DeclineButtonPrefix = "duel_decline_"
+)
+
+// ResultEmbed renders the outcome of an instant game
+func ResultEmbed(title string, result Result, footer string) *discordgo.MessageEmbed {
+	color := discord.ColorError
+	if result.Net() > 0 {
+		color = discord.ColorSuccess
+	} else if result.Paid > 0 {
+		color = discord.ColorWarning
+	}
+
+	embed := discord.NewEmbed().
+		SetTitle(title).
+		SetDescription(result.Describe()).
+		SetColor(color)
+	if footer != "" {
+		embed.SetFooter(footer, "")
+	}
+	return embed.Build()
+}
+
+// OpenBlackjack deals a hand and posts its table in the channel. A player that
+// stops playing stands automatically when the table times out.
+func OpenBlackjack(s *discordgo.Session, guildID, channelID, userID string, global bool, amount int64) (Blackjack, error) {
+	game, err := StartBlackjack(guildID, channelID, userID, global, amount)
+	if err != nil && game.ID == "" {
+		return game, err
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error pagando la partida de blackjack %s: %v", game.ID, err), "Casino")
+	}
+
+	msg, err := s.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+		Content:    fmt.Sprintf("<@%s>", userID),
+		Embeds:     []*discordgo.MessageEmbed{BlackjackEmbed(game)},
+		Components: BlackjackComponents(game),
+	})
+	if err != nil {
+		// Without a table to click the hand is played as a stand
+		_, _, _ = ExpireBlackjack(game.ID)
+		return game, err
+	}
+	if game.Finished() {
+		return game, nil
+	}
+	SetBlackjackMessage(game.ID, msg.ID)
+	game.MessageID = msg.ID
+
+	time.AfterFunc(BlackjackTimeout, func() {
+		expired, ok, err := ExpireBlackjack(game.ID)
+		if !ok {
+			return
+		}
+		if err != nil {
+			logger.Error(fmt.Sprintf("Error pagando la partida de blackjack %s: %v", expired.ID, err), "Casino")
+		}
+		embeds := []*discordgo.MessageEmbed{BlackjackEmbed(expired)}
+		components := []discordgo.MessageComponent{}
+		if _, err := s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+			ID:         expired.MessageID,
+			Channel:    expired.ChannelID,
+			Embeds:     &embeds,
+			Components: &components,
+		}); err != nil {
+			logger.Debug(fmt.Sprintf("No se pudo cerrar la mesa de blackjack %s: %v", expired.ID, err), "Casino")
+		}
+	})
+
+	return game, nil
+}
+
+// BlackjackEmbed renders a blackjack table; the hole card of the dealer stays
+// hidden while the hand is being played
+func BlackjackEmbed(game Blackjack) *discordgo.MessageEmbed {
+	currency := game.Wager.Rules.Currency
+	description := fmt.Sprintf("Apuesta: %s\nPide carta, plántate o dobla tu apuesta. Te plantas solo <t:%d:R>.", currency.Format(game.Wager.Amount), game.ExpiresAt.Unix())
+	color := discord.ColorInfo
+
+	switch game.Outcome {
+	case OutcomeBlackjack:
+		description = fmt.Sprintf("🃏 ¡Blackjack! Recibes %s.", currency.Format(game.Paid))
+		color = discord.ColorSuccess
+	case OutcomeWin:
+		description = fmt.Sprintf("🎉 ¡Ganaste! Recibes %s.", currency.Format(game.Paid))
+		color = discord.ColorSuccess
+	case OutcomePush:
+		description = fmt.Sprintf("🤝 Empate. Recuperas %s.", currency.Format(game.Paid))
+		color = discord.ColorWarning
+	case OutcomeLose:
+		description = fmt.Sprintf("💸 Perdiste %s.", currency.Format(game.Wager.Amount))
+		color = discord.ColorError
+	}
+
+	return discord.NewEmbed().
+		SetTitle("🃏 Blackjack").
+		SetDescription(description).
+		SetColor(color).
+		AddField("Tu mano", DescribeHand(game.Player, false), true).
+		AddField("Crupier", DescribeHand(game.Dealer, !game.Finished()), true).
+		SetFooter("ID: "+game.ID, "").
+		Build()
+}
+
+// BlackjackComponents returns the buttons of a table, none once the hand is over
+func BlackjackComponents(game Blackjack) []discordgo.MessageComponent {
+	if game.Finished() {
+		return []discordgo.MessageComponent{}
+	}
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{Label: "Pedir", Emoji: &discordgo.ComponentEmoji{Name: "➕"}, Style: discordgo.PrimaryButton, CustomID: HitButtonPrefix + game.ID},
+			discordgo.Button{Label: "Plantarse", Emoji: &discordgo.ComponentEmoji{Name: "✋"}, Style: discordgo.SecondaryButton, CustomID: StandButtonPrefix + game.ID},
+			discordgo.Button{Label: "Doblar", Emoji: &discordgo.ComponentEmoji{Name: "💰"}, Style: discordgo.SuccessButton, CustomID: DoubleButtonPrefix + game.ID, Disabled: !game.CanDouble()},
+		}},
+	}
+}
+
+// OpenDuel takes the stake of the challenger and posts the challenge in the
+// channel. The stake is returned if nobody answers in time.
+func OpenDuel(s *discordgo.Session, guildID, channelID, challengerID, opponentID string, global bool, amount int64) (Duel, error) {
+	d, err := Challenge(guildID, channelID, challengerID, opponentID, global, amount)
+	if err != nil {
+		return Duel{}, err
+	}
+
+	msg, err := s.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+		Content:    fmt.Sprintf("<@%s>", opponentID),
+		Embeds:     []*discordgo.MessageEmbed{DuelEmbed(d)},
+		Components: DuelComponents(d.ID, false),
+	})
+	if err != nil {
+		_, _ = Decline(d.ID, challengerID)
+		return Duel{}, err
+	}
+	SetDuelMessage(d.ID, msg.ID)
+	d.MessageID = msg.ID
+
+	WatchDuel(d.ID, func(expired Duel, err error) {
+		if err != nil {
+			logger.Error(fmt.Sprintf("Error devolviendo la apuesta del duelo %s: %v", expired.ID, err), "Casino")
+		}
+		CloseDuel(s, expired, "⌛ Nadie aceptó el duelo. La apuesta fue devuelta.", discord.ColorWarning)
+	})
+
+	return d, nil
+}
+
+// DuelEmbed renders an open challenge
+func DuelEmbed(d Duel) *discordgo.MessageEmbed {
+	currency := d.Challenger.Rules.Currency
+	return discord.NewEmbed().
+		SetTitle("⚔️ Duelo").
+		SetDescription(fmt.Sprintf("<@%s> reta a <@%s> a un duelo por %s.\nEl ganador se lleva el bote. Expira <t:%d:R>.",
+			d.Challenger.UserID, d.OpponentID, currency.Format(d.Challenger.Amount), d.ExpiresAt.Unix())).
+		SetColor(discord.ColorInfo).
+		SetFooter("ID: "+d.ID, "").
+		Build()
+}
+
+// DuelComponents returns the buttons of a challenge
+func DuelComponents(duelID string, disabled bool) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{Label: "Aceptar", Emoji: &discordgo.ComponentEmoji{Name: "⚔️"}, Style: discordgo.SuccessButton, CustomID: AcceptButtonPrefix + duelID, Disabled: disabled},
+			discordgo.Button{Label: "Rechazar", Emoji: &discordgo.ComponentEmoji{Name: "✖️"}, Style: discordgo.DangerButton, CustomID: DeclineButtonPrefix + duelID, Disabled: disabled},
+		}},
+	}
+}
+
+// DuelResultEmbed renders the outcome of a duel
+func DuelResultEmbed(d Duel) *discordgo.MessageEmbed {
+	loser := d.OpponentID
+	if d.Winner == d.OpponentID {
+		loser = d.Challenger.UserID
+	}
+	return discord.NewEmbed().
+		SetTitle("⚔️ Duelo").
+		SetDescription(fmt.Sprintf("🏆 <@%s> derrotó a <@%s> y se lleva %s.", d.Winner, loser, d.Challenger.Rules.Currency.Format(d.Paid))).
+		SetColor(discord.ColorSuccess).
+		SetFooter("ID: "+d.ID, "").
+		Build()
+}
+
+// CloseDuel replaces a challenge with a final message and removes its buttons
+func CloseDuel(s *discordgo.Session, d Duel, description string, color int) {
+	if d.MessageID == "" {
+		return
+	}
+	embed := discord.NewEmbed().
+		SetTitle("⚔️ Duelo").
+		SetDescription(description).
+		SetColor(color).
+		Build()
+	embeds := []*discordgo.MessageEmbed{embed}
+	components := []discordgo.MessageComponent{}
+	_, err := s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		ID:         d.MessageID,
+		Channel:    d.ChannelID,
+		Embeds:     &embeds,
+		Components: &components,
+	})
+	if err != nil {
+		logger.Debug(fmt.Sprintf("No se pudo cerrar el duelo %s: %v", d.ID, err), "Casino")
+	}
+}
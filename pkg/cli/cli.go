@@ -6,14 +6,17 @@ import (
 	"os"
 	"os/exec"
 	"runtime"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
+	"github.com/PancyStudios/PancyBotGo/pkg/config"
 	"github.com/PancyStudios/PancyBotGo/pkg/database"
 	"github.com/PancyStudios/PancyBotGo/pkg/discord"
 	"github.com/PancyStudios/PancyBotGo/pkg/logger"
 	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/PancyStudios/PancyBotGo/pkg/mqtt"
 	"go.mongodb.org/mongo-driver/bson"
 )
 
@@ -73,6 +76,12 @@ func ExecuteCommand(input string) bool {
 		handleGuildInfo(args[1:])
 	case "msg":
 		handleMsg(args[1:])
+	case "log":
+		handleLog(args[1:])
+	case "mqtt":
+		handleMqtt(args[1:])
+	case "db":
+		handleDB(args[1:])
 	case "ping":
 		if discordClient != nil && discordClient.Session != nil {
 			logger.System(fmt.Sprintf("Discord API Latency: %v", discordClient.Session.HeartbeatLatency()), "CLI")
@@ -118,6 +127,14 @@ func showHelp() {
   ping                       - Muestra la latencia con Discord
   cache reload               - Limpia la memoria caché de la DB
   msg <canal_id> <texto>     - Envía un mensaje como el bot
+  log level <nivel> [prefijo] - Cambia el nivel de logs (global o de un prefijo)
+  log reset <prefijo>        - Quita el nivel propio de un prefijo
+  log format <text|json>     - Cambia el formato de la consola y los archivos
+  log status                 - Muestra los niveles y el formato actuales
+  mqtt token <sujeto> <horas> <tópicos...> - Firma un token para los tópicos del dashboard
+  mqtt schema                - Publica de nuevo el esquema de los tópicos MQTT
+  db queue                   - Lista las escrituras pendientes de la cola offline
+  db sync                    - Reintenta ahora las escrituras pendientes
 =============================`
 	logger.System(msg, "CLI")
 }
@@ -433,3 +450,142 @@ func handleMsg(args []string) {
 		logger.System(fmt.Sprintf("Mensaje enviado al canal %s", channelID), "CLI")
 	}
 }
+
+func handleLog(args []string) {
+	if len(args) == 0 {
+		logger.System("Uso: log <level|reset|format|status> [args]", "CLI")
+		return
+	}
+
+	log := logger.Get()
+	switch strings.ToLower(args[0]) {
+	case "level":
+		if len(args) < 2 {
+			logger.System("Uso: log level <critical|error|warn|info|debug> [prefijo]", "CLI")
+			return
+		}
+		level, err := logger.ParseLevel(args[1])
+		if err != nil {
+			logger.System(fmt.Sprintf("Nivel inválido: %s", args[1]), "CLI")
+			return
+		}
+		if len(args) > 2 {
+			log.SetPrefixLevel(args[2], level)
+			logger.System(fmt.Sprintf("Nivel de '%s' cambiado a %s", args[2], level), "CLI")
+		} else {
+			log.SetLevel(level)
+			logger.System(fmt.Sprintf("Nivel global cambiado a %s", level), "CLI")
+		}
+	case "reset":
+		if len(args) < 2 {
+			logger.System("Uso: log reset <prefijo>", "CLI")
+			return
+		}
+		log.ResetPrefixLevel(args[1])
+		logger.System(fmt.Sprintf("'%s' vuelve a usar el nivel global", args[1]), "CLI")
+	case "format":
+		if len(args) < 2 {
+			logger.System("Uso: log format <text|json>", "CLI")
+			return
+		}
+		format, ok := logger.ParseFormat(args[1])
+		if !ok {
+			logger.System(fmt.Sprintf("Formato inválido: %s", args[1]), "CLI")
+			return
+		}
+		log.SetFormat(format)
+		logger.System(fmt.Sprintf("Formato de logs cambiado a %s", format), "CLI")
+	case "status":
+		prefixes := strings.Join(log.PrefixLevels(), ", ")
+		if prefixes == "" {
+			prefixes = "Ninguno"
+		}
+		logger.System(fmt.Sprintf("=== Logs ===\n  Nivel global : %s\n  Prefijos     : %s\n  Formato      : %s", log.Level(), prefixes, log.Format()), "CLI")
+	default:
+		logger.System("Uso: log <level|reset|format|status> [args]", "CLI")
+	}
+}
+
+func handleMqtt(args []string) {
+	if len(args) == 0 {
+		logger.System("Uso: mqtt <token|schema> [args]", "CLI")
+		return
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "token":
+		if len(args) < 4 {
+			logger.System("Uso: mqtt token <sujeto> <horas> <tópicos...>", "CLI")
+			return
+		}
+		hours, err := strconv.Atoi(args[2])
+		if err != nil || hours <= 0 {
+			logger.System(fmt.Sprintf("Horas inválidas: %s", args[2]), "CLI")
+			return
+		}
+		token, err := mqtt.SignToken(config.Get().MQTTAuthSecret, mqtt.Claims{
+			Subject:   args[1],
+			Topics:    args[3:],
+			ExpiresAt: time.Now().Add(time.Duration(hours) * time.Hour).Unix(),
+		})
+		if err != nil {
+			logger.System(fmt.Sprintf("No se pudo firmar el token: %v", err), "CLI")
+			return
+		}
+		// Straight to the console: the logs also go to webhooks and MQTT
+		fmt.Printf("Token para '%s' (%d h): %s\n", args[1], hours, token)
+	case "schema":
+		mc := mqtt.Get()
+		if mc == nil {
+			logger.System("El cliente MQTT no está listo.", "CLI")
+			return
+		}
+		if err := mc.PublishSchema(); err != nil {
+			logger.System(fmt.Sprintf("Error publicando el esquema: %v", err), "CLI")
+			return
+		}
+		logger.System(fmt.Sprintf("Esquema publicado con %d tópicos", len(mc.Schema().Topics)), "CLI")
+	default:
+		logger.System("Uso: mqtt <token|schema> [args]", "CLI")
+	}
+}
+
+func handleDB(args []string) {
+	db := database.Get()
+	if db == nil {
+		logger.System("La base de datos no está inicializada.", "CLI")
+		return
+	}
+	if len(args) == 0 {
+		logger.System("Uso: db <queue|sync>", "CLI")
+		return
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "queue":
+		pending := db.PendingWrites()
+		if len(pending) == 0 {
+			logger.System("No hay escrituras pendientes.", "CLI")
+			return
+		}
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("=== Cola offline (%d) ===\n", len(pending)))
+		for _, op := range pending {
+			sb.WriteString(fmt.Sprintf("  %s  %-6s %-20s %v  (hace %s)\n",
+				op.ID, op.Operation, op.CollectionName, op.Query, time.Since(op.QueuedAt).Round(time.Second)))
+		}
+		logger.System(strings.TrimSuffix(sb.String(), "\n"), "CLI")
+	case "sync":
+		if !db.Connected() {
+			logger.System("La base de datos sigue desconectada.", "CLI")
+			return
+		}
+		result, err := db.SyncOfflineWrites()
+		if err != nil {
+			logger.System(fmt.Sprintf("Sincronización incompleta: %v", err), "CLI")
+		}
+		logger.System(fmt.Sprintf("%d aplicadas, %d conflictos, %d pendientes", result.Applied, result.Conflicts, result.Pending), "CLI")
+	default:
+		logger.System("Uso: db <queue|sync>", "CLI")
+	}
+}
@@ -4,6 +4,7 @@ package config
 
 import (
 	"os"
+	"strconv"
 	"sync"
 
 	"github.com/joho/godotenv"
@@ -16,17 +17,23 @@ type Config struct {
 	DevGuildID string
 
 	// MongoDB
-	MongoDBURL string
-	DBName     string
+	DBBackend              string // "mongodb", or "memory" to run without a database
+	MongoDBURL             string
+	DBName                 string
+	WriteQueuePath         string // Journal of the writes made while MongoDB is down
+	WriteQueueMax          int
+	GuildDataRetentionDays int // Days the data of a server the bot left is kept, 0 keeps it forever
 
 	// MQTT
-	MQTTHost     string
-	MQTTPort     string
-	MQTTUser     string
-	MQTTPassword string
+	MQTTHost       string
+	MQTTPort       string
+	MQTTUser       string
+	MQTTPassword   string
+	MQTTAuthSecret string // Shared secret and token key of the dashboard-only topics
 
 	// Web Server
-	Port string
+	Port         string
+	MetricsToken string // Bearer token required by /metrics, empty leaves it open
 
 	// Environment
 	Environment string
@@ -37,6 +44,20 @@ type Config struct {
 	LogsWebServerHook string
 	GuildsWebhook     string
 
+	// Logging
+	LogFormat     string // text or json
+	LogLevel      string // Level of the prefixes without their own
+	LogLevels     string // Per-prefix levels, like "Levels=debug,Scheduler=warn"
+	LogMaxSizeMB  int
+	LogMaxFiles   int
+	LogMaxAgeDays int
+
+	// Tracing
+	TracingExporter    string // none, stdout or otlp
+	TracingEndpoint    string // OTLP/HTTP collector, like http://localhost:4318
+	TracingHeaders     string // Extra collector headers, like "api-key=abc"
+	TracingSampleRatio float64
+
 	// Lavalink
 	LinkServer   string
 	LinkPassword string
@@ -74,17 +95,23 @@ func loadConfig() {
 		DevGuildID: getEnv("devGuildId", ""),
 
 		// MongoDB
-		MongoDBURL: getEnv("mongodbUrl", "mongodb://localhost:27017"),
-		DBName:     getEnv("dbName", "PancyBot"),
+		DBBackend:              getEnv("dbBackend", "mongodb"),
+		MongoDBURL:             getEnv("mongodbUrl", "mongodb://localhost:27017"),
+		DBName:                 getEnv("dbName", "PancyBot"),
+		WriteQueuePath:         getEnv("writeQueuePath", "data/write-queue.jsonl"),
+		WriteQueueMax:          getEnvInt("writeQueueMax", 10000),
+		GuildDataRetentionDays: getEnvInt("guildDataRetentionDays", 30),
 
 		// MQTT
-		MQTTHost:     getEnv("MQTT_Host", "localhost"),
-		MQTTPort:     getEnv("MQTT_Port", "1883"),
-		MQTTUser:     getEnv("MQTT_User", ""),
-		MQTTPassword: getEnv("MQTT_Password", ""),
+		MQTTHost:       getEnv("MQTT_Host", "localhost"),
+		MQTTPort:       getEnv("MQTT_Port", "1883"),
+		MQTTUser:       getEnv("MQTT_User", ""),
+		MQTTPassword:   getEnv("MQTT_Password", ""),
+		MQTTAuthSecret: getEnv("MQTT_AuthSecret", ""),
 
 		// Web Server
-		Port: getEnv("PORT", "3000"),
+		Port:         getEnv("PORT", "3000"),
+		MetricsToken: getEnv("metricsToken", ""),
 
 		// Environment
 		Environment: getEnv("enviroment", "dev"),
@@ -95,6 +122,20 @@ func loadConfig() {
 		LogsWebServerHook: getEnv("logsWebServerWebhook", ""),
 		GuildsWebhook:     getEnv("guildsWebhook", ""),
 
+		// Logging
+		LogFormat:     getEnv("logFormat", "text"),
+		LogLevel:      getEnv("logLevel", "debug"),
+		LogLevels:     getEnv("logLevels", ""),
+		LogMaxSizeMB:  getEnvInt("logMaxSizeMB", 20),
+		LogMaxFiles:   getEnvInt("logMaxFiles", 14),
+		LogMaxAgeDays: getEnvInt("logMaxAgeDays", 14),
+
+		// Tracing
+		TracingExporter:    getEnv("tracingExporter", "none"),
+		TracingEndpoint:    getEnv("tracingEndpoint", ""),
+		TracingHeaders:     getEnv("tracingHeaders", ""),
+		TracingSampleRatio: getEnvFloat("tracingSampleRatio", 1),
+
 		// Lavalink
 		LinkServer:   getEnv("linkserver", "localhost"),
 		LinkPassword: getEnv("linkpassword", ""),
@@ -125,6 +166,22 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvInt gets a numeric environment variable or returns a default value
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvFloat gets a decimal environment variable or returns a default value
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
 // IsProd returns true if the environment is production
 func (c *Config) IsProd() bool {
 	return c.Environment == "prod"
@@ -70,32 +70,20 @@ func (c *BlacklistCache) Refresh() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	collection := GlobalBlacklistDM.collection
-	if collection == nil {
+	if !GlobalBlacklistDM.available() {
 		logger.Warn("BlacklistCache: Collection not available", "BlacklistCache")
 		return nil
 	}
 
-	cursor, err := collection.Find(ctx, bson.M{})
-	if err != nil {
+	var entries []*models.Blacklist
+	if err := GlobalBlacklistDM.repo.Find(ctx, bson.M{}, FindOptions{}, &entries); err != nil {
 		logger.Debug("BlacklistCache: DB offline. Manteniendo cache actual.", "BlacklistCache")
 		return err
 	}
-	defer func() { _ = cursor.Close(ctx) }()
-
-	newEntries := make(map[string]*models.Blacklist)
-	for cursor.Next(ctx) {
-		var entry models.Blacklist
-		if err := cursor.Decode(&entry); err != nil {
-			logger.Warn("BlacklistCache: Error decoding entry: "+err.Error(), "BlacklistCache")
-			continue
-		}
-		newEntries[entry.ID] = &entry
-	}
 
-	if err := cursor.Err(); err != nil {
-		logger.Debug("BlacklistCache: Cursor error (DB offline): "+err.Error(), "BlacklistCache")
-		return err
+	newEntries := make(map[string]*models.Blacklist, len(entries))
+	for _, entry := range entries {
+		newEntries[entry.ID] = entry
 	}
 
 	c.mu.Lock()
@@ -0,0 +1,41 @@
+package database
+
+import (
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// SaveOpenWager records the stake of an open casino game, or updates it when
+// the stake grows
+func SaveOpenWager(w *models.OpenWager) error {
+	if OpenWagersDM == nil {
+		return ErrDatabaseOffline
+	}
+	_, err := OpenWagersDM.Set(bson.M{"_id": w.ID}, w)
+	return err
+}
+
+// CloseOpenWager forgets the stake of a game that was settled
+func CloseOpenWager(id string) error {
+	if OpenWagersDM == nil {
+		return ErrDatabaseOffline
+	}
+	return OpenWagersDM.Delete(bson.M{"_id": id})
+}
+
+// ClaimOpenWager removes the stake of an abandoned game and reports whether
+// this call removed it, so only one instance refunds it
+func ClaimOpenWager(id string) (bool, error) {
+	if OpenWagersDM == nil {
+		return false, ErrDatabaseOffline
+	}
+	return OpenWagersDM.claim(bson.M{"_id": id})
+}
+
+// GetAbandonedWagers returns the stakes of games that timed out before the
+// given time and were never settled
+func GetAbandonedWagers(before time.Time) ([]*models.OpenWager, error) {
+	return findAll(OpenWagersDM, bson.M{"expires_at": bson.M{"$lt": before}}, bson.D{{Key: "expires_at", Value: 1}})
+}
@@ -18,10 +18,20 @@ import (
 
 // QueuedOperation represents a pending database operation
 type QueuedOperation struct {
+	ID             string // Set when queued; a replayed operation is marked done by ID
 	CollectionName string
 	Query          bson.M
-	Operation      string // "set" or "delete"
-	Data           interface{}
+	Operation      string      // "set" or "delete"
+	Data           interface{} // Document of a set, when Update is empty
+	Update         bson.M      // Update document of a set, {"$set": Data} by default
+	Guards         bson.M      // Conditions the stored document must still meet, see applyQueued
+	QueuedAt       time.Time
+}
+
+// Options tunes a Database
+type Options struct {
+	WriteQueuePath string // Journal of the offline writes; empty keeps them only in memory
+	WriteQueueMax  int    // DefaultWriteQueueMax when 0
 }
 
 // Database manages the MongoDB connection and data managers
@@ -29,12 +39,13 @@ type Database struct {
 	client          *mongo.Client
 	db              *mongo.Database
 	IsConnected     bool
-	writeQueue      []QueuedOperation
+	writeQueue      *writeQueue
 	reconnectTicker *time.Ticker
 	stopReconnect   chan struct{}
 	mu              sync.RWMutex
-	queueMu         sync.Mutex
+	syncMu          sync.Mutex // Held while the queue is replayed
 	collections     map[string]*mongo.Collection
+	memory          *memoryStore // Set when the collections live in memory instead of MongoDB
 }
 
 var (
@@ -42,31 +53,73 @@ var (
 	dbOnce   sync.Once
 )
 
-// Init initializes the global database instance
-func Init(mongoURL, dbName string) (*Database, error) {
+// Init initializes the global database instance. The offline writes left in
+// the journal are loaded before connecting, so they are replayed right away.
+func Init(mongoURL, dbName string, opts ...Options) (*Database, error) {
 	var err error
 	dbOnce.Do(func() {
-		database = NewDatabase()
+		var options Options
+		if len(opts) > 0 {
+			options = opts[0]
+		}
+		database = NewDatabase(options)
+		if options.WriteQueuePath != "" {
+			if journalErr := database.writeQueue.openJournal(options.WriteQueuePath); journalErr != nil {
+				logger.Error(fmt.Sprintf("No se pudo abrir la cola offline '%s', las escrituras offline solo se guardarán en memoria: %v", options.WriteQueuePath, journalErr), "DB-Queue")
+			} else if pending := database.writeQueue.len(); pending > 0 {
+				logger.System(fmt.Sprintf("%d escrituras offline recuperadas del disco", pending), "DB-Queue")
+			}
+		}
 		err = database.Connect(mongoURL, dbName)
 	})
 	return database, err
 }
 
+// InitMemory initializes the global database instance without MongoDB, for
+// development: documents live in memory and are lost on restart
+func InitMemory() *Database {
+	dbOnce.Do(func() {
+		database = NewMemoryDatabase()
+		logger.Warn("Base de datos en memoria: los datos se perderán al apagar el bot", "DB")
+	})
+	return database
+}
+
 // Get returns the global database instance
 func Get() *Database {
 	return database
 }
 
 // NewDatabase creates a new Database instance
-func NewDatabase() *Database {
+func NewDatabase(opts ...Options) *Database {
+	var options Options
+	if len(opts) > 0 {
+		options = opts[0]
+	}
 	return &Database{
 		IsConnected:   false,
-		writeQueue:    make([]QueuedOperation, 0),
+		writeQueue:    newWriteQueue(options.WriteQueueMax),
 		stopReconnect: make(chan struct{}),
 		collections:   make(map[string]*mongo.Collection),
 	}
 }
 
+// NewMemoryDatabase creates a Database whose collections live in memory
+func NewMemoryDatabase() *Database {
+	d := NewDatabase()
+	d.memory = newMemoryStore()
+	d.IsConnected = true
+	return d
+}
+
+// repository returns the storage of a collection
+func (d *Database) repository(name string) Repository {
+	if d.memory != nil {
+		return d.memory.collection(name)
+	}
+	return &mongoRepository{db: d, name: name}
+}
+
 // Connect establishes a connection to MongoDB
 func (d *Database) Connect(mongoURL, dbName string) error {
 	d.mu.Lock()
@@ -160,7 +213,7 @@ func (d *Database) Disconnect() error {
 		d.IsConnected = false
 		logger.Warn("La base de datos ha sido desconectada", "DB")
 	}
-	return nil
+	return d.writeQueue.close()
 }
 
 // Ping measures the database response time
@@ -168,6 +221,9 @@ func (d *Database) Ping() (time.Duration, error) {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
 
+	if d.memory != nil {
+		return 0, nil
+	}
 	if !d.IsConnected || d.client == nil {
 		return 0, fmt.Errorf("not connected to database")
 	}
@@ -185,6 +241,9 @@ func (d *Database) GetStatus() (string, bool) {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
 
+	if d.memory != nil {
+		return "🟡 | En memoria", true
+	}
 	if d.client == nil {
 		return "🔴 | Desconectado", false
 	}
@@ -220,63 +279,132 @@ func (d *Database) GetCollection(name string) *mongo.Collection {
 	return col
 }
 
-// AddToWriteQueue adds an operation to the offline write queue
-func (d *Database) AddToWriteQueue(op QueuedOperation) {
-	d.queueMu.Lock()
-	defer d.queueMu.Unlock()
-	d.writeQueue = append(d.writeQueue, op)
+// AddToWriteQueue adds an operation to the offline write queue. It returns
+// ErrWriteQueueFull when the queue is at its limit and the write is lost.
+func (d *Database) AddToWriteQueue(op QueuedOperation) error {
+	return d.writeQueue.add(op)
 }
 
-// syncOfflineWrites syncs queued operations with the database
-func (d *Database) syncOfflineWrites() {
-	d.queueMu.Lock()
-	if len(d.writeQueue) == 0 {
-		d.queueMu.Unlock()
-		return
-	}
+// WriteQueueLength returns how many writes are waiting for the database
+func (d *Database) WriteQueueLength() int {
+	return d.writeQueue.len()
+}
 
-	logger.System(fmt.Sprintf("Sincronizando %d operaciones pendientes con la DB...", len(d.writeQueue)), "DB-Sync")
+// PendingWrites returns the writes waiting for the database, oldest first
+func (d *Database) PendingWrites() []QueuedOperation {
+	return d.writeQueue.pending()
+}
 
-	operations := make([]QueuedOperation, len(d.writeQueue))
-	copy(operations, d.writeQueue)
-	d.writeQueue = make([]QueuedOperation, 0)
-	d.queueMu.Unlock()
+// SyncResult counts the outcome of a replay of the offline queue
+type SyncResult struct {
+	Applied   int
+	Conflicts int // Dropped because the document changed in the meantime
+	Pending   int // Left for the next attempt
+}
 
-	failedOps := make([]QueuedOperation, 0)
+// SyncOfflineWrites replays the offline queue in order. It stops at the first
+// write that fails, so the order is kept for the next attempt. Replays are
+// idempotent: applied writes are marked done in the journal one by one, and a
+// write replayed twice leaves the document as once.
+func (d *Database) SyncOfflineWrites() (SyncResult, error) {
+	d.syncMu.Lock()
+	defer d.syncMu.Unlock()
+
+	var result SyncResult
+	operations := d.writeQueue.pending()
+	if len(operations) == 0 {
+		return result, nil
+	}
+	logger.System(fmt.Sprintf("Sincronizando %d operaciones pendientes con la DB...", len(operations)), "DB-Sync")
 
+	var syncErr error
 	for _, op := range operations {
 		col := d.GetCollection(op.CollectionName)
 		if col == nil {
-			logger.Error(fmt.Sprintf("Colección '%s' no encontrada durante la sincronización.", op.CollectionName), "DB-Sync")
-			continue
+			syncErr = fmt.Errorf("colección '%s' no disponible", op.CollectionName)
+			break
 		}
 
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		conflict, err := applyQueued(ctx, col, op)
+		cancel()
+		if err != nil {
+			writeQueueReplays.Inc("failed")
+			syncErr = fmt.Errorf("%s en '%s': %w", op.Operation, op.CollectionName, err)
+			break
+		}
 
-		var err error
-		if op.Operation == "set" {
-			opts := options.Update().SetUpsert(true)
-			_, err = col.UpdateOne(ctx, op.Query, bson.M{"$set": op.Data}, opts)
-		} else if op.Operation == "delete" {
-			_, err = col.DeleteOne(ctx, op.Query)
+		d.writeQueue.done(op.ID)
+		if conflict {
+			result.Conflicts++
+			writeQueueReplays.Inc("conflict")
+			logger.WithFields(logger.Fields{"collection": op.CollectionName, "query": fmt.Sprint(op.Query)}).
+				Warn("Escritura offline descartada: el documento cambió mientras la DB estaba caída", "DB-Sync")
+		} else {
+			result.Applied++
+			writeQueueReplays.Inc("applied")
 		}
+		// The cache may hold the offline value or a stale one
+		cacheKey := queryCacheKey(op.CollectionName+":", op.Query)
+		globalCacheManager.remove(cacheKey)
+		publishInvalidation(cacheKey)
+	}
 
-		cancel()
+	if err := d.writeQueue.compact(); err != nil {
+		logger.Error(fmt.Sprintf("No se pudo compactar la cola offline: %v", err), "DB-Sync")
+	}
+	result.Pending = d.writeQueue.len()
 
-		if err != nil {
-			logger.Error(fmt.Sprintf("Error al sincronizar operación para '%s'. La operación se volverá a encolar.", op.CollectionName), "DB-Sync")
-			failedOps = append(failedOps, op)
-		}
+	if syncErr != nil {
+		logger.Warn(fmt.Sprintf("Sincronización detenida (%d aplicadas, %d conflictos, %d pendientes): %v", result.Applied, result.Conflicts, result.Pending, syncErr), "DB-Sync")
+		return result, syncErr
 	}
+	logger.Success(fmt.Sprintf("Sincronización completada: %d aplicadas, %d conflictos.", result.Applied, result.Conflicts), "DB-Sync")
+	return result, nil
+}
+
+// syncOfflineWrites replays the queue after a reconnect
+func (d *Database) syncOfflineWrites() {
+	_, _ = d.SyncOfflineWrites()
+}
 
-	if len(failedOps) > 0 {
-		d.queueMu.Lock()
-		d.writeQueue = append(d.writeQueue, failedOps...)
-		d.queueMu.Unlock()
-		logger.Warn(fmt.Sprintf("%d operaciones no pudieron sincronizarse y se reintentarán.", len(failedOps)), "DB-Sync")
-	} else {
-		logger.Success("Sincronización completada exitosamente.", "DB-Sync")
+// applyQueued replays one operation. A set with guards only applies if the
+// stored document still meets them, or was deleted; otherwise it is reported
+// as a conflict and not applied.
+func applyQueued(ctx context.Context, col *mongo.Collection, op QueuedOperation) (conflict bool, err error) {
+	switch op.Operation {
+	case "delete":
+		_, err = col.DeleteOne(ctx, op.Query)
+		return false, err
+	case "set":
+		update := op.Update
+		if update == nil {
+			update = bson.M{"$set": op.Data}
+		}
+		if len(op.Guards) == 0 {
+			_, err = col.UpdateOne(ctx, op.Query, update, options.Update().SetUpsert(true))
+			return false, err
+		}
+
+		filter := bson.M{}
+		for k, v := range op.Guards {
+			filter[k] = v
+		}
+		for k, v := range op.Query {
+			filter[k] = v
+		}
+		res, err := col.UpdateOne(ctx, filter, update)
+		if err != nil || res.MatchedCount > 0 {
+			return false, err
+		}
+		exists, err := col.CountDocuments(ctx, op.Query, options.Count().SetLimit(1))
+		if err != nil || exists > 0 {
+			return exists > 0, err
+		}
+		_, err = col.UpdateOne(ctx, op.Query, update, options.Update().SetUpsert(true))
+		return false, err
 	}
+	return false, fmt.Errorf("operación desconocida %q", op.Operation)
 }
 
 // Client returns the underlying MongoDB client
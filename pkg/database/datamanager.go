@@ -5,6 +5,7 @@ import (
 	"container/list"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
@@ -13,14 +14,23 @@ import (
 
 	"github.com/PancyStudios/PancyBotGo/pkg/logger"
 	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/PancyStudios/PancyBotGo/pkg/tracing"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// ErrDatabaseOffline is returned by operations that cannot be served from the cache
+var ErrDatabaseOffline = errors.New("database offline")
+
 // DataManagerOptions contains configuration for a DataManager
 type DataManagerOptions struct {
 	MaxCacheSize int
+	// TTL bounds how long a cached document is served without reading it
+	// again. Zero keeps documents until they are evicted or invalidated.
+	TTL time.Duration
+	// VersionField names a counter that every Set increments, so writers that
+	// guard on it with Update detect that the document changed under them
+	VersionField string
 }
 
 // CacheManager provides shared caching across DataManagers
@@ -32,8 +42,13 @@ type CacheManager struct {
 
 // cacheEntry holds a cached value with its key
 type cacheEntry struct {
-	key   string
-	value interface{}
+	key       string
+	value     interface{}
+	expiresAt time.Time // Zero for entries without TTL
+}
+
+func (e *cacheEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && now.After(e.expiresAt)
 }
 
 // globalCacheManager is shared across all DataManager instances
@@ -42,6 +57,28 @@ var globalCacheManager = &CacheManager{
 	cacheList: list.New(),
 }
 
+// remove drops a key from the cache
+func (cm *CacheManager) remove(key string) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	if elem, exists := cm.cache[key]; exists {
+		cm.cacheList.Remove(elem)
+		delete(cm.cache, key)
+	}
+}
+
+// removePrefix drops every key that starts with prefix
+func (cm *CacheManager) removePrefix(prefix string) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	for key, elem := range cm.cache {
+		if strings.HasPrefix(key, prefix) {
+			cm.cacheList.Remove(elem)
+			delete(cm.cache, key)
+		}
+	}
+}
+
 // global DataManagers for shared collections
 var (
 	GlobalWarnDM         *DataManager[models.WarnsDocument]
@@ -55,29 +92,66 @@ var (
 	ItemDM               *DataManager[models.Item]
 	GlobalGuildDM        *DataManager[models.GuildDocument]
 	GlobalMusicDM        *DataManager[models.MusicSettings]
+	VerificationDM       *DataManager[models.PendingVerification]
+	InviteStatsDM        *DataManager[models.InviteStats]
+	InviteJoinsDM        *DataManager[models.InviteJoin]
+	TransactionsDM       *DataManager[models.Transaction]
+	MarketListingsDM     *DataManager[models.MarketListing]
+	TimedRolesDM         *DataManager[models.TimedRole]
+	SeasonsDM            *DataManager[models.Season]
+	GuildPurgesDM        *DataManager[models.GuildPurge]
+	OpenWagersDM         *DataManager[models.OpenWager]
 )
 
+// Cache TTLs. Writes of other instances arrive through the invalidation bus;
+// the TTL bounds how stale a document gets when a message is lost or a
+// collection is written without a DataManager.
+const (
+	configCacheTTL  = 10 * time.Minute // Guild, music and premium settings
+	profileCacheTTL = 2 * time.Minute  // Profiles written on every message or command
+)
+
+// withTTL returns the default options with a cache TTL
+func withTTL(ttl time.Duration) DataManagerOptions {
+	opts := DefaultDataManagerOptions()
+	opts.TTL = ttl
+	return opts
+}
+
 // InitGlobalDataManagers initializes shared DataManager instances
 func InitGlobalDataManagers(db *Database) {
-	GlobalWarnDM = NewDataManager[models.WarnsDocument]("warns", db)
-	GlobalUserPremiumDM = NewDataManager[models.UserPremium]("premium", db)
-	GlobalGuildPremiumDM = NewDataManager[models.GuildPremium]("premium_guilds", db)
-	GlobalPremiumCodeDM = NewDataManager[models.PremiumCode]("premium_codes", db)
-	GlobalBlacklistDM = NewDataManager[models.Blacklist]("blacklist", db)
-	GlobalGuildDM = NewDataManager[models.GuildDocument]("guilds", db)
-	GlobalMusicDM = NewDataManager[models.MusicSettings]("music", db)
-	GlobalEconomyDM = NewDataManager[models.GlobalEconomyProfile]("economy_global", db)
-	LocalEconomyDM = NewDataManager[models.LocalEconomyProfile]("economy_local", db)
-	LocalLevelsDM = NewDataManager[models.UserLevelProfile]("levels", db)
-	ItemDM = NewDataManager[models.Item]("economy_items", db)
-}
-
-// DataManager provides cached access to a MongoDB collection
+	guildOptions := withTTL(configCacheTTL)
+	guildOptions.VersionField = "version"
+
+	GlobalWarnDM = NewDataManager[models.WarnsDocument]("warns", db, withTTL(profileCacheTTL))
+	GlobalUserPremiumDM = NewDataManager[models.UserPremium]("premium", db, withTTL(configCacheTTL))
+	GlobalGuildPremiumDM = NewDataManager[models.GuildPremium]("premium_guilds", db, withTTL(configCacheTTL))
+	GlobalPremiumCodeDM = NewDataManager[models.PremiumCode]("premium_codes", db, withTTL(configCacheTTL))
+	GlobalBlacklistDM = NewDataManager[models.Blacklist]("blacklist", db, withTTL(configCacheTTL))
+	GlobalGuildDM = NewDataManager[models.GuildDocument]("guilds", db, guildOptions)
+	GlobalMusicDM = NewDataManager[models.MusicSettings]("music", db, withTTL(configCacheTTL))
+	GlobalEconomyDM = NewDataManager[models.GlobalEconomyProfile]("economy_global", db, withTTL(profileCacheTTL))
+	LocalEconomyDM = NewDataManager[models.LocalEconomyProfile]("economy_local", db, withTTL(profileCacheTTL))
+	LocalLevelsDM = NewDataManager[models.UserLevelProfile]("levels", db, withTTL(profileCacheTTL))
+	ItemDM = NewDataManager[models.Item]("economy_items", db, withTTL(configCacheTTL))
+	VerificationDM = NewDataManager[models.PendingVerification]("verification_pending", db, withTTL(profileCacheTTL))
+	InviteStatsDM = NewDataManager[models.InviteStats]("invites", db, withTTL(profileCacheTTL))
+	InviteJoinsDM = NewDataManager[models.InviteJoin]("invite_joins", db, withTTL(profileCacheTTL))
+	TransactionsDM = NewDataManager[models.Transaction]("economy_transactions", db, withTTL(profileCacheTTL))
+	MarketListingsDM = NewDataManager[models.MarketListing]("market_listings", db, withTTL(profileCacheTTL))
+	TimedRolesDM = NewDataManager[models.TimedRole]("economy_timed_roles", db, withTTL(profileCacheTTL))
+	SeasonsDM = NewDataManager[models.Season]("seasons", db, withTTL(configCacheTTL))
+	GuildPurgesDM = NewDataManager[models.GuildPurge]("guild_purges", db, withTTL(configCacheTTL))
+	OpenWagersDM = NewDataManager[models.OpenWager]("casino_open_wagers", db, withTTL(profileCacheTTL))
+}
+
+// DataManager provides cached access to a collection
 type DataManager[T any] struct {
 	collectionName string
-	collection     *mongo.Collection
+	repo           Repository
 	dbInstance     *Database
 	options        DataManagerOptions
+	ctx            context.Context
 }
 
 // DefaultDataManagerOptions returns default options for DataManager
@@ -96,20 +170,48 @@ func NewDataManager[T any](collectionName string, db *Database, opts ...DataMana
 
 	return &DataManager[T]{
 		collectionName: collectionName,
-		collection:     db.GetCollection(collectionName),
+		repo:           db.repository(collectionName),
 		dbInstance:     db,
 		options:        dmOptions,
 	}
 }
 
+// WithContext returns a DataManager that runs its operations with ctx. When ctx
+// carries a tracing span, as the context of a command does, every operation
+// shows up in the trace. The cache is shared with the original.
+func (dm *DataManager[T]) WithContext(ctx context.Context) *DataManager[T] {
+	bound := *dm
+	bound.ctx = ctx
+	return &bound
+}
+
+// available reports whether the storage can be reached; without it reads are
+// served from the cache and writes are queued
+func (dm *DataManager[T]) available() bool {
+	return dm.repo != nil && dm.repo.Available()
+}
+
+// startSpan opens the tracing span of an operation, when there is a trace
+func (dm *DataManager[T]) startSpan(operation string) (context.Context, *tracing.Span) {
+	ctx := dm.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return tracing.StartChild(ctx, "mongodb "+operation+" "+dm.collectionName, tracing.KindClient,
+		tracing.String("db.system", "mongodb"),
+		tracing.String("db.collection.name", dm.collectionName),
+		tracing.String("db.operation.name", operation),
+	)
+}
+
 // generateCacheKey creates a unique, deterministic key from a query
-// It sorts the keys to ensure consistent ordering regardless of map iteration order
 func (dm *DataManager[T]) generateCacheKey(query bson.M) string {
-	collName := ""
-	if dm.collection != nil {
-		collName = dm.collectionName
-	}
+	return queryCacheKey(dm.cachePrefix(), query)
+}
 
+// queryCacheKey builds the cache key of a query in the collection of prefix.
+// It sorts the keys to ensure consistent ordering regardless of map iteration order
+func queryCacheKey(prefix string, query bson.M) string {
 	// Sort keys for deterministic serialization
 	keys := make([]string, 0, len(query))
 	for k := range query {
@@ -123,98 +225,87 @@ func (dm *DataManager[T]) generateCacheKey(query bson.M) string {
 		parts = append(parts, fmt.Sprintf("%s=%v", k, query[k]))
 	}
 
-	return fmt.Sprintf("%s:{%s}", collName, strings.Join(parts, ","))
+	return fmt.Sprintf("%s{%s}", prefix, strings.Join(parts, ","))
 }
 
 // Get retrieves a document from cache or database
 func (dm *DataManager[T]) Get(query bson.M) (*T, error) {
+	spanCtx, span := dm.startSpan("get")
+	defer span.End()
 	cacheKey := dm.generateCacheKey(query)
 
 	// Check cache first
 	globalCacheManager.mu.RLock()
 	if elem, exists := globalCacheManager.cache[cacheKey]; exists {
-		// Move to front (LRU)
-		globalCacheManager.mu.RUnlock()
-		globalCacheManager.mu.Lock()
-		globalCacheManager.cacheList.MoveToFront(elem)
 		entry := elem.Value.(*cacheEntry)
-		globalCacheManager.mu.Unlock()
-		return entry.value.(*T), nil
+		globalCacheManager.mu.RUnlock()
+		if !entry.expired(time.Now()) {
+			// Move to front (LRU)
+			globalCacheManager.mu.Lock()
+			globalCacheManager.cacheList.MoveToFront(elem)
+			globalCacheManager.mu.Unlock()
+			cacheRequests.Inc(dm.collectionName, "hit")
+			span.SetAttributes(tracing.Bool("cache.hit", true))
+			return entry.value.(*T), nil
+		}
+		globalCacheManager.remove(cacheKey)
+	} else {
+		globalCacheManager.mu.RUnlock()
 	}
-	globalCacheManager.mu.RUnlock()
+	cacheRequests.Inc(dm.collectionName, "miss")
+	span.SetAttributes(tracing.Bool("cache.hit", false))
 
 	// Not in cache, fetch from database
-	if !dm.dbInstance.Connected() || dm.collection == nil {
+	if !dm.available() {
 		// Modo offline: Si no está en caché y la DB está desconectada, devolvemos nil sin error.
 		// Esto le dice a los servicios que el perfil "no existe" y deben inicializar uno.
 		return nil, nil
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	ctx, cancel := context.WithTimeout(spanCtx, 1500*time.Millisecond)
 	defer cancel()
 
 	var result T
-	err := dm.collection.FindOne(ctx, query).Decode(&result)
+	err := dm.repo.FindOne(ctx, query, &result)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, nil
 		}
+		span.RecordError(err)
 		logger.Warn(fmt.Sprintf("Error leyendo de DB (%s), operando en modo fallback: %v", dm.collectionName, err), "DataManager")
 		return nil, nil // En vez de retornar error, simulamos "no existe" para inicializarlo localmente
 	}
 
 	// Add to cache
-	globalCacheManager.mu.Lock()
-	defer globalCacheManager.mu.Unlock()
-
-	entry := &cacheEntry{key: cacheKey, value: &result}
-	elem := globalCacheManager.cacheList.PushFront(entry)
-	globalCacheManager.cache[cacheKey] = elem
-
-	// Evict if over capacity
-	if dm.options.MaxCacheSize > 0 && globalCacheManager.cacheList.Len() > dm.options.MaxCacheSize {
-		oldest := globalCacheManager.cacheList.Back()
-		if oldest != nil {
-			oldEntry := oldest.Value.(*cacheEntry)
-			delete(globalCacheManager.cache, oldEntry.key)
-			globalCacheManager.cacheList.Remove(oldest)
-		}
-	}
+	dm.storeCache(cacheKey, &result)
 
 	return &result, nil
 }
 
 // GetAll retrieves all documents matching a query from the database
 func (dm *DataManager[T]) GetAll(query bson.M) ([]*T, error) {
-	if !dm.dbInstance.Connected() || dm.collection == nil {
+	spanCtx, span := dm.startSpan("find")
+	defer span.End()
+
+	if !dm.available() {
 		logger.Warn(fmt.Sprintf("DB offline. GetAll devolviendo lista vacía para '%s'", dm.collectionName), "DataManager")
 		return []*T{}, nil
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	ctx, cancel := context.WithTimeout(spanCtx, 1500*time.Millisecond)
 	defer cancel()
 
-	cursor, err := dm.collection.Find(ctx, query)
-	if err != nil {
-		return nil, err
-	}
-	defer func() { _ = cursor.Close(ctx) }()
-
 	var results []*T
-	for cursor.Next(ctx) {
-		var doc T
-		if err := cursor.Decode(&doc); err != nil {
-			logger.Warn(fmt.Sprintf("Error decoding document in GetAll for '%s': %v", dm.collectionName, err), "DataManager")
-			continue
-		}
-		results = append(results, &doc)
-	}
-
-	return results, cursor.Err()
+	err := dm.repo.Find(ctx, query, FindOptions{}, &results)
+	span.SetAttributes(tracing.Int("db.response.returned_rows", int64(len(results))))
+	span.RecordError(err)
+	return results, err
 }
 
 // Set updates or inserts a document in the database and cache
 func (dm *DataManager[T]) Set(query bson.M, data interface{}) (*T, error) {
+	spanCtx, span := dm.startSpan("set")
+	defer span.End()
 	cacheKey := dm.generateCacheKey(query)
 
 	// Update cache immediately (Write-Through)
@@ -230,107 +321,290 @@ func (dm *DataManager[T]) Set(query bson.M, data interface{}) (*T, error) {
 		cacheValue = &temp
 	}
 
-	globalCacheManager.mu.Lock()
-	entry := &cacheEntry{key: cacheKey, value: cacheValue}
-	if elem, exists := globalCacheManager.cache[cacheKey]; exists {
-		elem.Value = entry
-		globalCacheManager.cacheList.MoveToFront(elem)
-	} else {
-		elem := globalCacheManager.cacheList.PushFront(entry)
-		globalCacheManager.cache[cacheKey] = elem
-
-		// Evict if over capacity
-		if dm.options.MaxCacheSize > 0 && globalCacheManager.cacheList.Len() > dm.options.MaxCacheSize {
-			oldest := globalCacheManager.cacheList.Back()
-			if oldest != nil {
-				oldEntry := oldest.Value.(*cacheEntry)
-				delete(globalCacheManager.cache, oldEntry.key)
-				globalCacheManager.cacheList.Remove(oldest)
-			}
-		}
-	}
-	globalCacheManager.mu.Unlock()
+	dm.storeCache(cacheKey, cacheValue)
 
-	if !dm.dbInstance.Connected() || dm.collection == nil {
+	if !dm.available() {
+		span.SetAttributes(tracing.Bool("db.queued", true))
 		logger.Warn(fmt.Sprintf("DB offline. Encolando escritura en '%s' y usando caché.", dm.collectionName), "DataManager")
-		dm.dbInstance.AddToWriteQueue(QueuedOperation{
-			CollectionName: dm.collectionName,
-			Query:          query,
-			Operation:      "set",
-			Data:           data,
-		})
-		return cacheValue, nil
+		return cacheValue, dm.queueSet(query, data)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	ctx, cancel := context.WithTimeout(spanCtx, 1500*time.Millisecond)
 	defer cancel()
 
-	opts := options.FindOneAndUpdate().
-		SetUpsert(true).
-		SetReturnDocument(options.After)
+	update, _, err := dm.setUpdate(data)
+	if err != nil {
+		span.RecordError(err)
+		return cacheValue, err
+	}
 
 	var result T
-	err := dm.collection.FindOneAndUpdate(ctx, query, bson.M{"$set": data}, opts).Decode(&result)
+	err = dm.repo.FindOneAndUpdate(ctx, query, update, true, &result)
 	if err != nil {
+		span.RecordError(err)
+		span.SetAttributes(tracing.Bool("db.queued", true))
 		logger.Debug(fmt.Sprintf("DB offline o timeout. Usando cache local para '%s'", dm.collectionName), "DataManager")
-		dm.dbInstance.AddToWriteQueue(QueuedOperation{
-			CollectionName: dm.collectionName,
-			Query:          query,
-			Operation:      "set",
-			Data:           data,
-		})
-		return cacheValue, nil
+		return cacheValue, dm.queueSet(query, data)
 	}
 
+	if dm.options.VersionField != "" {
+		// The cached copy still has the old version
+		dm.storeCache(cacheKey, &result)
+	}
+	publishInvalidation(cacheKey)
+	return &result, nil
+}
+
+// setUpdate builds the update document of Set, incrementing the version field
+// instead of overwriting it when there is one. It also returns the version data
+// was read at.
+func (dm *DataManager[T]) setUpdate(data interface{}) (bson.M, interface{}, error) {
+	if dm.options.VersionField == "" {
+		return bson.M{"$set": data}, nil, nil
+	}
+	raw, err := bson.Marshal(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	var fields bson.M
+	if err := bson.Unmarshal(raw, &fields); err != nil {
+		return nil, nil, err
+	}
+	version := fields[dm.options.VersionField]
+	delete(fields, dm.options.VersionField)
+	return bson.M{"$set": fields, "$inc": bson.M{dm.options.VersionField: 1}}, version, nil
+}
+
+// queueSet keeps a Set for when the database is back. With a version field the
+// write is guarded by the version data was read at, so it is dropped on replay
+// if someone else changed the document meanwhile.
+func (dm *DataManager[T]) queueSet(query bson.M, data interface{}) error {
+	op := QueuedOperation{
+		CollectionName: dm.collectionName,
+		Query:          query,
+		Operation:      "set",
+		Data:           data,
+	}
+	if update, version, err := dm.setUpdate(data); err == nil && dm.options.VersionField != "" {
+		op.Update = update
+		if version == nil || version == int64(0) || version == int32(0) {
+			op.Guards = bson.M{dm.options.VersionField: bson.M{"$in": bson.A{0, nil}}}
+		} else {
+			op.Guards = bson.M{dm.options.VersionField: version}
+		}
+	}
+
+	if err := dm.dbInstance.AddToWriteQueue(op); err != nil {
+		logger.Error(fmt.Sprintf("Cola offline llena, se pierde la escritura en '%s'", dm.collectionName), "DataManager")
+		return err
+	}
+	return nil
+}
+
+// queueUpdate caches the locally applied value and queues the update document
+// itself, so a replay applies the delta to whatever the document holds by then
+// and is dropped as a conflict when the guards no longer hold.
+func (dm *DataManager[T]) queueUpdate(query, guards, update bson.M, value *T) error {
+	dm.storeCache(dm.generateCacheKey(query), value)
+
+	err := dm.dbInstance.AddToWriteQueue(QueuedOperation{
+		CollectionName: dm.collectionName,
+		Query:          query,
+		Operation:      "set",
+		Update:         update,
+		Guards:         guards,
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Cola offline llena, se pierde la escritura en '%s'", dm.collectionName), "DataManager")
+	}
+	return err
+}
+
+// Update atomically applies an update document (e.g. $inc) to the document matching
+// query and guards, and refreshes the cache with the result. It returns
+// mongo.ErrNoDocuments when nothing matches (a guard failed) and ErrDatabaseOffline
+// when the database cannot be reached, so the caller decides how to degrade.
+func (dm *DataManager[T]) Update(query, guards, update bson.M) (*T, error) {
+	return dm.update(query, guards, update, false)
+}
+
+// UpdateOrInsert is Update for counters that may not exist yet: when nothing
+// matches query the document is created from the update ($setOnInsert fills
+// the fields $inc and $set don't).
+func (dm *DataManager[T]) UpdateOrInsert(query, update bson.M) (*T, error) {
+	return dm.update(query, nil, update, true)
+}
+
+func (dm *DataManager[T]) update(query, guards, update bson.M, upsert bool) (*T, error) {
+	spanCtx, span := dm.startSpan("update")
+	defer span.End()
+
+	if !dm.available() {
+		span.RecordError(ErrDatabaseOffline)
+		return nil, ErrDatabaseOffline
+	}
+
+	filter := bson.M{}
+	for k, v := range guards {
+		filter[k] = v
+	}
+	for k, v := range query {
+		filter[k] = v
+	}
+
+	ctx, cancel := context.WithTimeout(spanCtx, 1500*time.Millisecond)
+	defer cancel()
+
+	var result T
+	if err := dm.repo.FindOneAndUpdate(ctx, filter, update, upsert, &result); err != nil {
+		if err == mongo.ErrNoDocuments {
+			span.SetAttributes(tracing.Bool("db.matched", false))
+			return nil, err
+		}
+		span.RecordError(err)
+		logger.Warn(fmt.Sprintf("Error en actualización atómica de '%s': %v", dm.collectionName, err), "DataManager")
+		return nil, ErrDatabaseOffline
+	}
+
+	cacheKey := dm.generateCacheKey(query)
+	dm.storeCache(cacheKey, &result)
+	publishInvalidation(cacheKey)
 	return &result, nil
 }
 
 // Delete removes a document from the database and cache
 func (dm *DataManager[T]) Delete(query bson.M) error {
-	cacheKey := dm.generateCacheKey(query)
+	spanCtx, span := dm.startSpan("delete")
+	defer span.End()
 
 	// Remove from cache first
-	globalCacheManager.mu.Lock()
-	if elem, exists := globalCacheManager.cache[cacheKey]; exists {
-		globalCacheManager.cacheList.Remove(elem)
-		delete(globalCacheManager.cache, cacheKey)
-	}
-	globalCacheManager.mu.Unlock()
+	dm.evict(query)
 
-	if !dm.dbInstance.Connected() || dm.collection == nil {
+	if !dm.available() {
 		logger.Warn(fmt.Sprintf("DB offline. Encolando eliminación para '%s'", dm.collectionName), "DataManager")
-		dm.dbInstance.AddToWriteQueue(QueuedOperation{
+		return dm.dbInstance.AddToWriteQueue(QueuedOperation{
 			CollectionName: dm.collectionName,
 			Query:          query,
 			Operation:      "delete",
 		})
-		return nil
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	ctx, cancel := context.WithTimeout(spanCtx, 1500*time.Millisecond)
 	defer cancel()
 
-	_, err := dm.collection.DeleteOne(ctx, query)
+	_, err := dm.repo.DeleteOne(ctx, query)
 	if err != nil {
+		span.RecordError(err)
 		logger.Debug("Eliminación añadida a la cola offline", "DataManager")
-		dm.dbInstance.AddToWriteQueue(QueuedOperation{
+		if queueErr := dm.dbInstance.AddToWriteQueue(QueuedOperation{
 			CollectionName: dm.collectionName,
 			Query:          query,
 			Operation:      "delete",
-		})
+		}); queueErr != nil {
+			return queueErr
+		}
 		return err
 	}
 
+	publishInvalidation(dm.generateCacheKey(query))
 	return nil
 }
 
-// ClearCache clears the entire cache
-func (dm *DataManager[T]) ClearCache() {
+// deleteWhere removes every document matching filter and drops the cache of
+// the collection. Unlike Delete it is not queued while offline.
+func (dm *DataManager[T]) deleteWhere(filter bson.M) (int64, error) {
+	if !dm.available() {
+		return 0, ErrDatabaseOffline
+	}
+
+	spanCtx, span := dm.startSpan("delete_many")
+	defer span.End()
+	ctx, cancel := context.WithTimeout(spanCtx, 30*time.Second)
+	defer cancel()
+
+	deleted, err := dm.repo.DeleteMany(ctx, filter)
+	span.RecordError(err)
+	if deleted > 0 {
+		dm.ClearCache()
+	}
+	return deleted, err
+}
+
+// claim deletes the document matching query and reports whether this call
+// removed it. Unlike Delete it is not queued offline: with several instances
+// only the one that removed the document may act on it.
+func (dm *DataManager[T]) claim(query bson.M) (bool, error) {
+	if !dm.available() {
+		return false, ErrDatabaseOffline
+	}
+
+	spanCtx, span := dm.startSpan("claim")
+	defer span.End()
+	ctx, cancel := context.WithTimeout(spanCtx, 1500*time.Millisecond)
+	defer cancel()
+
+	deleted, err := dm.repo.DeleteOne(ctx, query)
+	span.RecordError(err)
+	if deleted > 0 {
+		dm.Invalidate(query)
+	}
+	return deleted > 0, err
+}
+
+// evict removes a document from the shared cache, so the next Get reads it
+// again from the database
+func (dm *DataManager[T]) evict(query bson.M) {
+	globalCacheManager.remove(dm.generateCacheKey(query))
+}
+
+// Invalidate evicts a document from the cache of this instance and, through
+// the invalidation bus, of every other instance. Use it after writing to the
+// collection without the DataManager.
+func (dm *DataManager[T]) Invalidate(query bson.M) {
+	cacheKey := dm.generateCacheKey(query)
+	globalCacheManager.remove(cacheKey)
+	publishInvalidation(cacheKey)
+}
+
+// storeCache puts a value in the shared cache, evicting the oldest entry if needed
+func (dm *DataManager[T]) storeCache(cacheKey string, value *T) {
 	globalCacheManager.mu.Lock()
 	defer globalCacheManager.mu.Unlock()
 
-	globalCacheManager.cache = make(map[string]*list.Element)
-	globalCacheManager.cacheList = list.New()
+	entry := &cacheEntry{key: cacheKey, value: value}
+	if dm.options.TTL > 0 {
+		entry.expiresAt = time.Now().Add(dm.options.TTL)
+	}
+	if elem, exists := globalCacheManager.cache[cacheKey]; exists {
+		elem.Value = entry
+		globalCacheManager.cacheList.MoveToFront(elem)
+		return
+	}
+
+	elem := globalCacheManager.cacheList.PushFront(entry)
+	globalCacheManager.cache[cacheKey] = elem
+
+	// Evict if over capacity
+	if dm.options.MaxCacheSize > 0 && globalCacheManager.cacheList.Len() > dm.options.MaxCacheSize {
+		oldest := globalCacheManager.cacheList.Back()
+		if oldest != nil {
+			oldEntry := oldest.Value.(*cacheEntry)
+			delete(globalCacheManager.cache, oldEntry.key)
+			globalCacheManager.cacheList.Remove(oldest)
+		}
+	}
+}
+
+// ClearCache drops every cached document of the collection, here and on the
+// other instances
+func (dm *DataManager[T]) ClearCache() {
+	globalCacheManager.removePrefix(dm.cachePrefix())
+	publishCollectionClear(dm.cachePrefix())
+}
+
+// cachePrefix is the start of every cache key of the collection
+func (dm *DataManager[T]) cachePrefix() string {
+	return dm.collectionName + ":"
 }
 
 // CacheSize returns the current cache size
@@ -342,9 +616,5 @@ func (dm *DataManager[T]) CacheSize() int {
 
 // PrimeCache logs that the cache is ready (caches are filled on demand)
 func (dm *DataManager[T]) PrimeCache() {
-	collName := ""
-	if dm.collection != nil {
-		collName = dm.collectionName
-	}
-	logger.System(fmt.Sprintf("Caché para '%s' preparada (tamaño máx: %d). Se llenará bajo demanda.", collName, dm.options.MaxCacheSize), "DataManager")
+	logger.System(fmt.Sprintf("Caché para '%s' preparada (tamaño máx: %d). Se llenará bajo demanda.", dm.collectionName, dm.options.MaxCacheSize), "DataManager")
 }
@@ -67,14 +67,16 @@ func GetLocalProfile(guildID, userID string) (*models.LocalEconomyProfile, error
 	}
 
 	if profile == nil {
-		// Initialize a new profile
+		// Initialize a new profile with the starting values of the server
+		startingBalance, bankCapacity := localProfileDefaults(guildID)
 		profile = &models.LocalEconomyProfile{
 			ID:           id,
 			GuildID:      guildID,
 			UserID:       userID,
-			Wallet:       0,
+			Wallet:       startingBalance,
 			Bank:         0,
-			BankCapacity: DefaultLocalBankCapacity,
+			NetWorth:     startingBalance,
+			BankCapacity: bankCapacity,
 			Inventory:    make(map[string]int),
 			Cooldowns:    make(map[string]time.Time),
 			CreatedAt:    time.Now(),
@@ -84,198 +86,120 @@ func GetLocalProfile(guildID, userID string) (*models.LocalEconomyProfile, error
 		if err != nil {
 			return nil, err
 		}
+
+		if startingBalance > 0 {
+			recordTransaction(&models.Transaction{
+				Currency:    models.CurrencyLocal,
+				GuildID:     guildID,
+				UserID:      userID,
+				WalletAfter: profile.Wallet,
+			}, BalanceChange{Wallet: startingBalance}, TxInfo{Type: models.TransactionReward, Note: "Saldo inicial"})
+		}
 	}
 
 	return profile, nil
 }
 
-// AddStars adds or removes Stars from a user's global profile
-func AddStars(userID string, amount int64, toBank bool) (*models.GlobalEconomyProfile, error) {
-	profile, err := GetGlobalProfile(userID)
-	if err != nil {
-		return nil, err
+// localProfileDefaults returns the starting balance and bank capacity a server sets
+// for new local profiles
+func localProfileDefaults(guildID string) (int64, int64) {
+	if GlobalGuildDM == nil {
+		return 0, DefaultLocalBankCapacity
 	}
-
-	if toBank {
-		newBank := profile.StarsBank + amount
-		if amount > 0 && newBank > profile.BankCapacity {
-			return nil, ErrBankFull
-		}
-		if newBank < 0 {
-			return nil, ErrInsufficientFunds
-		}
-		profile.StarsBank = newBank
-	} else {
-		newWallet := profile.StarsWallet + amount
-		if newWallet < 0 {
-			return nil, ErrInsufficientFunds
-		}
-		profile.StarsWallet = newWallet
+	doc, err := GlobalGuildDM.Get(bson.M{"id": guildID})
+	if err != nil || doc == nil {
+		return 0, DefaultLocalBankCapacity
 	}
 
-	profile.UpdatedAt = time.Now()
-	_, err = GlobalEconomyDM.Set(bson.M{"_id": userID}, profile)
-	return profile, err
+	bankCapacity := DefaultLocalBankCapacity
+	if doc.Economy.BankCapacity > 0 {
+		bankCapacity = doc.Economy.BankCapacity
+	}
+	return doc.Economy.StartingBalance, bankCapacity
 }
 
-// AddLocalBalance adds or removes local balance from a user
-func AddLocalBalance(guildID, userID string, amount int64, toBank bool) (*models.LocalEconomyProfile, error) {
-	profile, err := GetLocalProfile(guildID, userID)
-	if err != nil {
-		return nil, err
+// AddStars adds or removes Stars from a user's global profile through the ledger
+func AddStars(userID string, amount int64, toBank bool, info TxInfo) (*models.GlobalEconomyProfile, error) {
+	change := BalanceChange{Wallet: amount}
+	if toBank {
+		change = BalanceChange{Bank: amount}
 	}
+	return ApplyStarsChange(userID, change, info)
+}
 
+// AddLocalBalance adds or removes local balance from a user through the ledger
+func AddLocalBalance(guildID, userID string, amount int64, toBank bool, info TxInfo) (*models.LocalEconomyProfile, error) {
+	change := BalanceChange{Wallet: amount}
 	if toBank {
-		newBank := profile.Bank + amount
-		if amount > 0 && newBank > profile.BankCapacity {
-			return nil, ErrBankFull
-		}
-		if newBank < 0 {
-			return nil, ErrInsufficientFunds
-		}
-		profile.Bank = newBank
-	} else {
-		newWallet := profile.Wallet + amount
-		if newWallet < 0 {
-			return nil, ErrInsufficientFunds
-		}
-		profile.Wallet = newWallet
+		change = BalanceChange{Bank: amount}
 	}
-
-	profile.UpdatedAt = time.Now()
-	_, err = LocalEconomyDM.Set(bson.M{"_id": profile.ID}, profile)
-	return profile, err
+	return ApplyLocalChange(guildID, userID, change, info)
 }
 
-// TransferStars transfers Stars between users globally
-func TransferStars(fromUserID, toUserID string, amount int64) error {
+// TransferStars transfers Stars between users globally. The sender is debited first
+// with a guarded update; if crediting the receiver fails the sender is refunded.
+func TransferStars(fromUserID, toUserID string, amount int64, info TxInfo) error {
 	if amount <= 0 {
-		return errors.New("amount must be positive")
-	}
-
-	_, err := AddStars(fromUserID, -amount, false)
-	if err != nil {
-		return err
+		return ErrInvalidAmount
 	}
 
-	_, err = AddStars(toUserID, amount, false)
-	if err != nil {
-		// Rollback on failure
-		_, _ = AddStars(fromUserID, amount, false)
-		return err
-	}
-
-	return nil
+	debit, credit := info, info
+	debit.Counterparty = toUserID
+	credit.Counterparty = fromUserID
+	return ApplyLegs([]LedgerLeg{
+		{Currency: models.CurrencyStars, UserID: fromUserID, Change: BalanceChange{Wallet: -amount}, Info: debit},
+		{Currency: models.CurrencyStars, UserID: toUserID, Change: BalanceChange{Wallet: amount}, Info: credit},
+	})
 }
 
-// TransferLocalBalance transfers local currency between users
-func TransferLocalBalance(guildID, fromUserID, toUserID string, amount int64) error {
+// TransferLocalBalance transfers local currency between users, see TransferStars
+func TransferLocalBalance(guildID, fromUserID, toUserID string, amount int64, info TxInfo) error {
 	if amount <= 0 {
-		return errors.New("amount must be positive")
+		return ErrInvalidAmount
 	}
 
-	_, err := AddLocalBalance(guildID, fromUserID, -amount, false)
-	if err != nil {
-		return err
-	}
-
-	_, err = AddLocalBalance(guildID, toUserID, amount, false)
-	if err != nil {
-		// Rollback on failure
-		_, _ = AddLocalBalance(guildID, fromUserID, amount, false)
-		return err
-	}
-
-	return nil
+	debit, credit := info, info
+	debit.Counterparty = toUserID
+	credit.Counterparty = fromUserID
+	return ApplyLegs([]LedgerLeg{
+		{Currency: models.CurrencyLocal, GuildID: guildID, UserID: fromUserID, Change: BalanceChange{Wallet: -amount}, Info: debit},
+		{Currency: models.CurrencyLocal, GuildID: guildID, UserID: toUserID, Change: BalanceChange{Wallet: amount}, Info: credit},
+	})
 }
 
 // DepositLocal deposits money from Wallet to Bank
 func DepositLocal(guildID, userID string, amount int64) error {
 	if amount <= 0 {
-		return errors.New("amount must be positive")
+		return ErrInvalidAmount
 	}
-	profile, err := GetLocalProfile(guildID, userID)
-	if err != nil {
-		return err
-	}
-
-	if profile.Wallet < amount {
-		return ErrInsufficientFunds
-	}
-	if profile.Bank+amount > profile.BankCapacity {
-		return ErrBankFull
-	}
-
-	profile.Wallet -= amount
-	profile.Bank += amount
-	profile.UpdatedAt = time.Now()
-	_, err = LocalEconomyDM.Set(bson.M{"_id": profile.ID}, profile)
+	_, err := ApplyLocalChange(guildID, userID, BalanceChange{Wallet: -amount, Bank: amount}, TxInfo{Type: models.TransactionDeposit, Command: "deposit"})
 	return err
 }
 
 // WithdrawLocal withdraws money from Bank to Wallet
 func WithdrawLocal(guildID, userID string, amount int64) error {
 	if amount <= 0 {
-		return errors.New("amount must be positive")
-	}
-	profile, err := GetLocalProfile(guildID, userID)
-	if err != nil {
-		return err
+		return ErrInvalidAmount
 	}
-
-	if profile.Bank < amount {
-		return ErrInsufficientFunds
-	}
-
-	profile.Bank -= amount
-	profile.Wallet += amount
-	profile.UpdatedAt = time.Now()
-	_, err = LocalEconomyDM.Set(bson.M{"_id": profile.ID}, profile)
+	_, err := ApplyLocalChange(guildID, userID, BalanceChange{Wallet: amount, Bank: -amount}, TxInfo{Type: models.TransactionWithdraw, Command: "withdraw"})
 	return err
 }
 
 // DepositStars deposits global Stars from Wallet to Bank
 func DepositStars(userID string, amount int64) error {
 	if amount <= 0 {
-		return errors.New("amount must be positive")
-	}
-	profile, err := GetGlobalProfile(userID)
-	if err != nil {
-		return err
-	}
-
-	if profile.StarsWallet < amount {
-		return ErrInsufficientFunds
+		return ErrInvalidAmount
 	}
-	if profile.StarsBank+amount > profile.BankCapacity {
-		return ErrBankFull
-	}
-
-	profile.StarsWallet -= amount
-	profile.StarsBank += amount
-	profile.UpdatedAt = time.Now()
-	_, err = GlobalEconomyDM.Set(bson.M{"_id": userID}, profile)
+	_, err := ApplyStarsChange(userID, BalanceChange{Wallet: -amount, Bank: amount}, TxInfo{Type: models.TransactionDeposit, Command: "deposit"})
 	return err
 }
 
 // WithdrawStars withdraws global Stars from Bank to Wallet
 func WithdrawStars(userID string, amount int64) error {
 	if amount <= 0 {
-		return errors.New("amount must be positive")
-	}
-	profile, err := GetGlobalProfile(userID)
-	if err != nil {
-		return err
-	}
-
-	if profile.StarsBank < amount {
-		return ErrInsufficientFunds
+		return ErrInvalidAmount
 	}
-
-	profile.StarsBank -= amount
-	profile.StarsWallet += amount
-	profile.UpdatedAt = time.Now()
-	_, err = GlobalEconomyDM.Set(bson.M{"_id": userID}, profile)
+	_, err := ApplyStarsChange(userID, BalanceChange{Wallet: amount, Bank: -amount}, TxInfo{Type: models.TransactionWithdraw, Command: "withdraw"})
 	return err
 }
 
@@ -307,12 +231,7 @@ func SetCooldownLocal(guildID, userID, command string) error {
 	if err != nil {
 		return err
 	}
-	if profile.Cooldowns == nil {
-		profile.Cooldowns = make(map[string]time.Time)
-	}
-	profile.Cooldowns[command] = time.Now()
-	_, err = LocalEconomyDM.Set(bson.M{"_id": profile.ID}, profile)
-	return err
+	return setCooldown(LocalEconomyDM, bson.M{"_id": profile.ID}, profile, &profile.Cooldowns, command)
 }
 
 // CooldownStars checks if a global cooldown has expired.
@@ -343,11 +262,23 @@ func SetCooldownStars(userID, command string) error {
 	if err != nil {
 		return err
 	}
-	if profile.Cooldowns == nil {
-		profile.Cooldowns = make(map[string]time.Time)
+	return setCooldown(GlobalEconomyDM, bson.M{"_id": userID}, profile, &profile.Cooldowns, command)
+}
+
+// setCooldown stamps a single cooldown with $set so it never overwrites balances
+// changed concurrently by the ledger. Offline it falls back to saving the profile.
+func setCooldown[T any](dm *DataManager[T], query bson.M, profile *T, cooldowns *map[string]time.Time, command string) error {
+	now := time.Now()
+	_, err := dm.Update(query, nil, bson.M{"$set": bson.M{"cooldowns." + command: now}})
+	if err == nil {
+		return nil
+	}
+
+	if *cooldowns == nil {
+		*cooldowns = make(map[string]time.Time)
 	}
-	profile.Cooldowns[command] = time.Now()
-	_, err = GlobalEconomyDM.Set(bson.M{"_id": userID}, profile)
+	(*cooldowns)[command] = now
+	_, err = dm.Set(query, profile)
 	return err
 }
 
@@ -0,0 +1,51 @@
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+)
+
+func TestLocalEconomyWithMemoryDatabase(t *testing.T) {
+	useMemoryDatabase(t)
+
+	if _, err := AddLocalBalance("g1", "alice", 500, false, TxInfo{Type: models.TransactionReward}); err != nil {
+		t.Fatal(err)
+	}
+	if err := DepositLocal("g1", "alice", 200); err != nil {
+		t.Fatal(err)
+	}
+	if err := DepositLocal("g1", "alice", 400); !errors.Is(err, ErrInsufficientFunds) {
+		t.Errorf("Expected insufficient funds, got %v", err)
+	}
+	if err := TransferLocalBalance("g1", "alice", "bob", 250, TxInfo{Type: models.TransactionTransfer}); err != nil {
+		t.Fatal(err)
+	}
+	if err := TransferLocalBalance("g1", "bob", "alice", 251, TxInfo{Type: models.TransactionTransfer}); !errors.Is(err, ErrInsufficientFunds) {
+		t.Errorf("Expected insufficient funds, got %v", err)
+	}
+
+	alice, err := GetLocalProfile("g1", "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if alice.Wallet != 50 || alice.Bank != 200 || alice.NetWorth != 250 {
+		t.Errorf("alice = wallet %d, bank %d, net worth %d; want 50, 200, 250", alice.Wallet, alice.Bank, alice.NetWorth)
+	}
+	bob, err := GetLocalProfile("g1", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bob.Wallet != 250 {
+		t.Errorf("bob wallet = %d, want 250", bob.Wallet)
+	}
+
+	txs, err := GetTransactions(TransactionFilter{GuildID: "g1", UserID: "alice"}, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 3 {
+		t.Errorf("Expected 3 transactions for alice, got %d", len(txs))
+	}
+}
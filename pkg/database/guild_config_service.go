@@ -0,0 +1,56 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrVersionConflict is returned when a guild document changed since the
+// version the writer read
+var ErrVersionConflict = errors.New("version conflict")
+
+// GetGuildConfig returns the document of a guild, creating it with the defaults
+// when the guild has none yet
+func GetGuildConfig(ctx context.Context, guildID string) (*models.GuildDocument, error) {
+	if GlobalGuildDM == nil {
+		return nil, fmt.Errorf("guild data manager not initialized")
+	}
+	dm := GlobalGuildDM.WithContext(ctx)
+	doc, err := dm.Get(bson.M{"id": guildID})
+	if err != nil {
+		return nil, err
+	}
+	if doc != nil {
+		return doc, nil
+	}
+	return dm.Set(bson.M{"id": guildID}, models.NewDefaultGuildDocument(guildID))
+}
+
+// SaveGuildSection replaces one section of a guild document, like "greetings",
+// as long as the document is still at version expected, and increments the
+// version. It returns ErrVersionConflict when someone else wrote first.
+func SaveGuildSection(ctx context.Context, guildID string, expected int64, section string, value interface{}) (*models.GuildDocument, error) {
+	if GlobalGuildDM == nil {
+		return nil, fmt.Errorf("guild data manager not initialized")
+	}
+
+	// Documents written before versioning have no version field
+	var guard interface{} = expected
+	if expected == 0 {
+		guard = bson.M{"$in": bson.A{0, nil}}
+	}
+	doc, err := GlobalGuildDM.WithContext(ctx).Update(
+		bson.M{"id": guildID},
+		bson.M{"version": guard},
+		bson.M{"$set": bson.M{section: value}, "$inc": bson.M{"version": 1}},
+	)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrVersionConflict
+	}
+	return doc, err
+}
@@ -0,0 +1,176 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/PancyStudios/PancyBotGo/pkg/mqtt"
+	"github.com/google/uuid"
+)
+
+// Invalidation tells the other instances of the bot which cached documents
+// changed. Keys are cache keys; prefixes clear whole collections.
+type Invalidation struct {
+	Origin   string   `json:"origin"` // Instance that wrote, which ignores its own messages
+	Keys     []string `json:"keys,omitempty"`
+	Prefixes []string `json:"prefixes,omitempty"`
+}
+
+// InvalidationBus carries invalidations between the instances that share the
+// database
+type InvalidationBus interface {
+	Publish(inv Invalidation) error
+	Subscribe(handler func(inv Invalidation)) error
+}
+
+// invalidationFlushInterval groups the writes of a burst, like the XP of a busy
+// channel, into one message
+const invalidationFlushInterval = 250 * time.Millisecond
+
+// invalidator batches the invalidations of this instance
+type invalidator struct {
+	bus      InvalidationBus
+	origin   string
+	mu       sync.Mutex
+	keys     map[string]bool
+	prefixes map[string]bool
+	timer    *time.Timer
+}
+
+var (
+	activeInvalidator *invalidator
+	invalidatorMu     sync.RWMutex
+)
+
+// StartInvalidationBus makes every write of this instance evict the document
+// from the caches of the other instances, and applies their invalidations here.
+// Without a bus, caches of other instances only catch up through the TTL.
+func StartInvalidationBus(bus InvalidationBus) error {
+	inv := &invalidator{
+		bus:      bus,
+		origin:   uuid.New().String(),
+		keys:     make(map[string]bool),
+		prefixes: make(map[string]bool),
+	}
+	if err := bus.Subscribe(inv.receive); err != nil {
+		return err
+	}
+
+	invalidatorMu.Lock()
+	activeInvalidator = inv
+	invalidatorMu.Unlock()
+	return nil
+}
+
+// StopInvalidationBus sends the pending invalidations and stops publishing
+func StopInvalidationBus() {
+	invalidatorMu.Lock()
+	inv := activeInvalidator
+	activeInvalidator = nil
+	invalidatorMu.Unlock()
+	if inv != nil {
+		inv.flush()
+	}
+}
+
+func publishInvalidation(key string) {
+	invalidatorMu.RLock()
+	inv := activeInvalidator
+	invalidatorMu.RUnlock()
+	if inv != nil {
+		inv.add(key, false)
+	}
+}
+
+func publishCollectionClear(prefix string) {
+	invalidatorMu.RLock()
+	inv := activeInvalidator
+	invalidatorMu.RUnlock()
+	if inv != nil {
+		inv.add(prefix, true)
+	}
+}
+
+func (inv *invalidator) add(key string, prefix bool) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	if prefix {
+		inv.prefixes[key] = true
+	} else {
+		inv.keys[key] = true
+	}
+	if inv.timer == nil {
+		inv.timer = time.AfterFunc(invalidationFlushInterval, inv.flush)
+	}
+}
+
+// flush publishes the pending invalidations
+func (inv *invalidator) flush() {
+	inv.mu.Lock()
+	msg := Invalidation{Origin: inv.origin}
+	for key := range inv.keys {
+		msg.Keys = append(msg.Keys, key)
+	}
+	for prefix := range inv.prefixes {
+		msg.Prefixes = append(msg.Prefixes, prefix)
+	}
+	inv.keys = make(map[string]bool)
+	inv.prefixes = make(map[string]bool)
+	if inv.timer != nil {
+		inv.timer.Stop()
+		inv.timer = nil
+	}
+	inv.mu.Unlock()
+
+	if len(msg.Keys) == 0 && len(msg.Prefixes) == 0 {
+		return
+	}
+	if err := inv.bus.Publish(msg); err != nil {
+		logger.Warn(fmt.Sprintf("No se pudieron publicar %d invalidaciones de caché: %v", len(msg.Keys)+len(msg.Prefixes), err), "DataManager")
+	}
+}
+
+// receive applies the invalidations of another instance
+func (inv *invalidator) receive(msg Invalidation) {
+	if msg.Origin == inv.origin {
+		return
+	}
+	for _, key := range msg.Keys {
+		globalCacheManager.remove(key)
+	}
+	for _, prefix := range msg.Prefixes {
+		globalCacheManager.removePrefix(prefix)
+	}
+	cacheInvalidations.Add(float64(len(msg.Keys) + len(msg.Prefixes)))
+}
+
+// invalidationTopic is shared by every environment, since canary and
+// production use the same database
+const invalidationTopic = "pancy/cache/invalidate"
+
+type mqttInvalidationBus struct {
+	mc *mqtt.MqttCommunicator
+}
+
+// MQTTInvalidationBus carries invalidations on an MQTT topic
+func MQTTInvalidationBus(mc *mqtt.MqttCommunicator) InvalidationBus {
+	return mqttInvalidationBus{mc: mc}
+}
+
+func (b mqttInvalidationBus) Publish(inv Invalidation) error {
+	return b.mc.Broadcast(invalidationTopic, inv)
+}
+
+func (b mqttInvalidationBus) Subscribe(handler func(inv Invalidation)) error {
+	return b.mc.Listen(invalidationTopic, func(topic string, payload []byte) {
+		var inv Invalidation
+		if err := json.Unmarshal(payload, &inv); err != nil {
+			logger.Warn(fmt.Sprintf("Invalidación de caché inválida: %v", err), "DataManager")
+			return
+		}
+		handler(inv)
+	})
+}
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/mqtt"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type cachedDoc struct {
+	ID string `bson:"_id"`
+}
+
+// offlineManager returns a DataManager that only has the cache
+func offlineManager(ttl time.Duration) *DataManager[cachedDoc] {
+	return &DataManager[cachedDoc]{
+		collectionName: "test",
+		dbInstance:     &Database{},
+		options:        DataManagerOptions{MaxCacheSize: 100, TTL: ttl},
+	}
+}
+
+func cached(dm *DataManager[cachedDoc], id string) bool {
+	doc, _ := dm.Get(bson.M{"_id": id})
+	return doc != nil
+}
+
+func TestCacheTTL(t *testing.T) {
+	dm := offlineManager(20 * time.Millisecond)
+	defer dm.ClearCache()
+	query := bson.M{"_id": "a"}
+	dm.storeCache(dm.generateCacheKey(query), &cachedDoc{ID: "a"})
+
+	if !cached(dm, "a") {
+		t.Fatal("Expected a fresh entry to be served")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if cached(dm, "a") {
+		t.Error("Expected an expired entry to be dropped")
+	}
+}
+
+func TestInvalidationBus(t *testing.T) {
+	t.Setenv("BOT_ENV", "test")
+	broker := mqtt.NewMemoryBroker()
+	mc := mqtt.NewWithBroker(broker, "pancybot_test")
+	if err := StartInvalidationBus(MQTTInvalidationBus(mc)); err != nil {
+		t.Fatal(err)
+	}
+	defer StopInvalidationBus()
+
+	dm := offlineManager(0)
+	defer dm.ClearCache()
+	for _, id := range []string{"a", "b"} {
+		query := bson.M{"_id": id}
+		dm.storeCache(dm.generateCacheKey(query), &cachedDoc{ID: id})
+	}
+
+	// A write of another instance evicts the key here
+	other := Invalidation{Origin: "other", Keys: []string{dm.generateCacheKey(bson.M{"_id": "a"})}}
+	if err := mc.Broadcast(invalidationTopic, other); err != nil {
+		t.Fatal(err)
+	}
+	if cached(dm, "a") || !cached(dm, "b") {
+		t.Error("Expected only the invalidated key to be evicted")
+	}
+
+	// Local invalidations are batched and published for the others, and
+	// ignored when they come back
+	dm.Invalidate(bson.M{"_id": "b"})
+	dm.storeCache(dm.generateCacheKey(bson.M{"_id": "b"}), &cachedDoc{ID: "b"})
+	StopInvalidationBus()
+	msgs := broker.Published(invalidationTopic)
+	if len(msgs) != 2 {
+		t.Fatalf("Expected our invalidation after the other one, got %d messages", len(msgs))
+	}
+	var mine Invalidation
+	if err := json.Unmarshal(msgs[1].Payload, &mine); err != nil || mine.Origin == "other" || len(mine.Keys) != 1 {
+		t.Errorf("Unexpected invalidation %+v, %v", mine, err)
+	}
+	if !cached(dm, "b") {
+		t.Error("Expected our own invalidation to be ignored")
+	}
+}
@@ -0,0 +1,148 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var ErrInvitesManagerNotInitialized = errors.New("invites data manager not initialized")
+
+// GetInviteStats returns the invite counters of a member (zeroed when it never invited anyone)
+func GetInviteStats(guildID, userID string) (*models.InviteStats, error) {
+	if InviteStatsDM == nil {
+		return nil, ErrInvitesManagerNotInitialized
+	}
+
+	id := fmt.Sprintf("%s_%s", guildID, userID)
+	stats, err := InviteStatsDM.Get(bson.M{"_id": id})
+	if err != nil {
+		return nil, err
+	}
+	if stats == nil {
+		stats = &models.InviteStats{ID: id, GuildID: guildID, UserID: userID}
+	}
+	return stats, nil
+}
+
+// GetInviteJoin returns how a member joined the guild, or nil if it was not tracked
+func GetInviteJoin(guildID, memberID string) (*models.InviteJoin, error) {
+	if InviteJoinsDM == nil {
+		return nil, ErrInvitesManagerNotInitialized
+	}
+	return InviteJoinsDM.Get(bson.M{"_id": fmt.Sprintf("%s_%s", guildID, memberID)})
+}
+
+// RecordInviteJoin stores the join and credits the inviter. It returns the updated
+// inviter stats, or nil when the source has no inviter (vanity or unknown).
+func RecordInviteJoin(join *models.InviteJoin) (*models.InviteStats, error) {
+	if InviteStatsDM == nil || InviteJoinsDM == nil {
+		return nil, ErrInvitesManagerNotInitialized
+	}
+
+	join.ID = fmt.Sprintf("%s_%s", join.GuildID, join.MemberID)
+	if _, err := InviteJoinsDM.Set(bson.M{"_id": join.ID}, join); err != nil {
+		return nil, err
+	}
+
+	if join.InviterID == "" {
+		return nil, nil
+	}
+
+	inc := bson.M{"joins": int64(1)}
+	if join.Fake {
+		inc["fakes"] = int64(1)
+	}
+	return incrementInviteStats(join.GuildID, join.InviterID, inc)
+}
+
+// RecordInviteLeave marks the member as gone and charges the leave to its inviter.
+// Fake invites are not charged twice. It returns the join record, or nil if the
+// member was not tracked.
+func RecordInviteLeave(guildID, memberID string) (*models.InviteJoin, error) {
+	join, err := GetInviteJoin(guildID, memberID)
+	if err != nil || join == nil || join.Left {
+		return join, err
+	}
+
+	join.Left = true
+	if _, err := InviteJoinsDM.Set(bson.M{"_id": join.ID}, join); err != nil {
+		return nil, err
+	}
+
+	if join.InviterID == "" || join.Fake {
+		return join, nil
+	}
+
+	_, err = incrementInviteStats(guildID, join.InviterID, bson.M{"leaves": int64(1)})
+	return join, err
+}
+
+// incrementInviteStats atomically adds to the counters of an inviter, creating
+// them on their first invite
+func incrementInviteStats(guildID, userID string, inc bson.M) (*models.InviteStats, error) {
+	id := fmt.Sprintf("%s_%s", guildID, userID)
+	now := time.Now()
+	stats, err := InviteStatsDM.UpdateOrInsert(bson.M{"_id": id}, bson.M{
+		"$inc": inc,
+		"$set": bson.M{"updated_at": now},
+		"$setOnInsert": bson.M{
+			"guild_id": guildID,
+			"user_id":  userID,
+		},
+	})
+	if !errors.Is(err, ErrDatabaseOffline) {
+		return stats, err
+	}
+
+	// Offline: count on the cached stats, the write is queued by Set
+	if stats, err = GetInviteStats(guildID, userID); err != nil {
+		return nil, err
+	}
+	stats.Joins += inviteCounter(inc, "joins")
+	stats.Fakes += inviteCounter(inc, "fakes")
+	stats.Leaves += inviteCounter(inc, "leaves")
+	stats.UpdatedAt = now
+	return InviteStatsDM.Set(bson.M{"_id": id}, stats)
+}
+
+func inviteCounter(inc bson.M, field string) int64 {
+	n, _ := inc[field].(int64)
+	return n
+}
+
+// GetTopInviters retrieves the members with the most valid invites in a guild
+func GetTopInviters(guildID string, limit int64) ([]*models.InviteStats, error) {
+	if InviteStatsDM == nil || !InviteStatsDM.available() {
+		return nil, ErrInvitesManagerNotInitialized
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"guild_id": guildID,
+		"$expr":    bson.M{"$gt": bson.A{bson.M{"$subtract": bson.A{"$joins", bson.M{"$add": bson.A{"$leaves", "$fakes"}}}}, 0}},
+	}
+	var results []*models.InviteStats
+	if err := InviteStatsDM.repo.Find(ctx, filter, FindOptions{}, &results); err != nil {
+		return nil, err
+	}
+
+	// The total is computed, so the ranking is sorted here
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Total() != results[j].Total() {
+			return results[i].Total() > results[j].Total()
+		}
+		return results[i].Joins > results[j].Joins
+	})
+	if int64(len(results)) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestConcurrentInviteJoinsAreCounted(t *testing.T) {
+	useMemoryDatabase(t)
+
+	const joins = 20
+	var wg sync.WaitGroup
+	for n := 0; n < joins; n++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			join := &models.InviteJoin{GuildID: "g1", MemberID: string(rune('a' + n)), InviterID: "alice", Fake: n%5 == 0}
+			if _, err := RecordInviteJoin(join); err != nil {
+				t.Error(err)
+			}
+		}(n)
+	}
+	wg.Wait()
+
+	for _, member := range []string{"b", "c", "f"} {
+		if _, err := RecordInviteLeave("g1", member); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats, err := GetInviteStats("g1", "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Joins != joins || stats.Fakes != 4 || stats.Leaves != 2 {
+		t.Errorf("Expected %d joins, 4 fakes and 2 leaves, got %+v", joins, stats)
+	}
+	if stats.GuildID != "g1" || stats.UserID != "alice" {
+		t.Errorf("Expected the key fields to be set on insert, got %+v", stats)
+	}
+}
+
+func TestTopInvitersInMemory(t *testing.T) {
+	useMemoryDatabase(t)
+
+	for _, stats := range []*models.InviteStats{
+		{ID: "g1_alice", GuildID: "g1", UserID: "alice", Joins: 5, Leaves: 1},
+		{ID: "g1_bob", GuildID: "g1", UserID: "bob", Joins: 3},
+		{ID: "g1_carol", GuildID: "g1", UserID: "carol", Joins: 2, Fakes: 2},
+		{ID: "g1_dave", GuildID: "g1", UserID: "dave", Joins: 4},
+		{ID: "g2_erin", GuildID: "g2", UserID: "erin", Joins: 9},
+	} {
+		if _, err := InviteStatsDM.Set(bson.M{"_id": stats.ID}, stats); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	top, err := GetTopInviters("g1", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(top) != 2 || top[0].UserID != "alice" || top[1].UserID != "dave" {
+		t.Errorf("Expected alice then dave, got %+v", top)
+	}
+}
@@ -0,0 +1,141 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrOutOfStock                     = errors.New("item is out of stock")
+	ErrTimedRoleManagerNotInitialized = errors.New("timed role data manager not initialized")
+)
+
+// PurchaseItem buys qty units of an item and returns the total cost. Limited stock
+// is reserved atomically before charging and released if the buyer can't pay.
+func PurchaseItem(guildID, userID string, item models.Item, qty int) (int64, error) {
+	if ItemDM == nil {
+		return 0, ErrEconomyManagerNotInitialized
+	}
+	if qty <= 0 {
+		return 0, ErrInvalidAmount
+	}
+
+	limited := item.Stock >= 0
+	if limited {
+		_, err := ItemDM.Update(bson.M{"_id": item.ID}, bson.M{"stock": bson.M{"$gte": qty}}, bson.M{"$inc": bson.M{"stock": -qty}})
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return 0, ErrOutOfStock
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	total := item.Price * int64(qty)
+	change := BalanceChange{Wallet: -total, Items: map[string]int{item.ID: qty}}
+	info := TxInfo{Type: models.TransactionPurchase, Command: "buy", Note: item.Name}
+
+	var err error
+	if item.IsGlobal {
+		_, err = ApplyStarsChange(userID, change, info)
+	} else {
+		_, err = ApplyLocalChange(guildID, userID, change, info)
+	}
+	if err != nil {
+		if limited {
+			if _, restockErr := ItemDM.Update(bson.M{"_id": item.ID}, nil, bson.M{"$inc": bson.M{"stock": qty}}); restockErr != nil {
+				logger.Error(fmt.Sprintf("No se pudo devolver el stock de %s: %v", item.ID, restockErr), "Economy")
+			}
+		}
+		return 0, err
+	}
+	return total, nil
+}
+
+// AddLocalBuff activates a timed buff on a local profile. Using an item with the
+// same value while the buff is active extends it.
+func AddLocalBuff(guildID, userID, effect, itemID string, value float64, duration time.Duration) (time.Time, error) {
+	profile, err := GetLocalProfile(guildID, userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return setBuff(LocalEconomyDM, bson.M{"_id": profile.ID}, profile, &profile.Buffs, effect, itemID, value, duration)
+}
+
+// AddStarsBuff activates a timed buff on a global profile, see AddLocalBuff
+func AddStarsBuff(userID, effect, itemID string, value float64, duration time.Duration) (time.Time, error) {
+	profile, err := GetGlobalProfile(userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return setBuff(GlobalEconomyDM, bson.M{"_id": userID}, profile, &profile.Buffs, effect, itemID, value, duration)
+}
+
+// setBuff stores a single buff with $set, like setCooldown
+func setBuff[T any](dm *DataManager[T], query bson.M, profile *T, buffs *models.Buffs, effect, itemID string, value float64, duration time.Duration) (time.Time, error) {
+	start := time.Now()
+	if current, ok := buffs.Active(effect); ok && current.Value == value {
+		start = current.ExpiresAt
+	}
+	buff := models.ActiveBuff{Value: value, ItemID: itemID, ExpiresAt: start.Add(duration)}
+
+	if _, err := dm.Update(query, nil, bson.M{"$set": bson.M{"buffs." + effect: buff}}); err == nil {
+		return buff.ExpiresAt, nil
+	}
+
+	if *buffs == nil {
+		*buffs = make(models.Buffs)
+	}
+	(*buffs)[effect] = buff
+	_, err := dm.Set(query, profile)
+	return buff.ExpiresAt, err
+}
+
+// AddTimedRole schedules the removal of a role granted by an item. Granting the
+// same role again before it expires extends it.
+func AddTimedRole(guildID, userID, roleID, itemID string, duration time.Duration) (time.Time, error) {
+	if TimedRolesDM == nil {
+		return time.Time{}, ErrTimedRoleManagerNotInitialized
+	}
+
+	id := fmt.Sprintf("%s_%s_%s", guildID, userID, roleID)
+	query := bson.M{"_id": id}
+
+	start := time.Now()
+	if current, err := TimedRolesDM.Get(query); err == nil && current != nil && current.ExpiresAt.After(start) {
+		start = current.ExpiresAt
+	}
+
+	timed := &models.TimedRole{
+		ID:        id,
+		GuildID:   guildID,
+		UserID:    userID,
+		RoleID:    roleID,
+		ItemID:    itemID,
+		ExpiresAt: start.Add(duration),
+	}
+	_, err := TimedRolesDM.Set(query, timed)
+	return timed.ExpiresAt, err
+}
+
+// RemoveTimedRole forgets a timed role once it has been taken away
+func RemoveTimedRole(id string) error {
+	if TimedRolesDM == nil {
+		return ErrTimedRoleManagerNotInitialized
+	}
+	return TimedRolesDM.Delete(bson.M{"_id": id})
+}
+
+// GetExpiredTimedRoles returns every timed role whose time has passed
+func GetExpiredTimedRoles(now time.Time) ([]*models.TimedRole, error) {
+	if TimedRolesDM == nil {
+		return nil, ErrTimedRoleManagerNotInitialized
+	}
+	return TimedRolesDM.GetAll(bson.M{"expires_at": bson.M{"$lte": now}})
+}
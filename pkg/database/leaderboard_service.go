@@ -0,0 +1,144 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var ErrLeaderboardUnavailable = errors.New("leaderboard needs the database online")
+
+// LeaderboardEntry is one row of an economy leaderboard
+type LeaderboardEntry struct {
+	UserID   string `bson:"user_id"`
+	Wallet   int64  `bson:"wallet"`
+	Bank     int64  `bson:"bank"`
+	NetWorth int64  `bson:"net_worth"`
+}
+
+// economyBoard returns the storage and the filter of an economy
+func economyBoard(guildID string, global bool) (Repository, bson.M, error) {
+	if global {
+		if GlobalEconomyDM == nil || !GlobalEconomyDM.available() {
+			return nil, nil, ErrLeaderboardUnavailable
+		}
+		return GlobalEconomyDM.repo, bson.M{}, nil
+	}
+	if LocalEconomyDM == nil || !LocalEconomyDM.available() {
+		return nil, nil, ErrLeaderboardUnavailable
+	}
+	return LocalEconomyDM.repo, bson.M{"guild_id": guildID}, nil
+}
+
+// GetEconomyLeaderboard returns a page of the richest profiles of an economy,
+// sorted by net worth (wallet + bank)
+func GetEconomyLeaderboard(guildID string, global bool, limit, skip int64) ([]LeaderboardEntry, error) {
+	repo, match, err := economyBoard(guildID, global)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := FindOptions{
+		Sort:  bson.D{{Key: "net_worth", Value: -1}, {Key: "_id", Value: 1}},
+		Skip:  skip,
+		Limit: limit,
+	}
+	var entries []LeaderboardEntry
+	if global {
+		var profiles []*models.GlobalEconomyProfile
+		if err := repo.Find(ctx, match, opts, &profiles); err != nil {
+			return nil, err
+		}
+		for _, p := range profiles {
+			entries = append(entries, LeaderboardEntry{UserID: p.UserID, Wallet: p.StarsWallet, Bank: p.StarsBank, NetWorth: p.NetWorth})
+		}
+		return entries, nil
+	}
+
+	var profiles []*models.LocalEconomyProfile
+	if err := repo.Find(ctx, match, opts, &profiles); err != nil {
+		return nil, err
+	}
+	for _, p := range profiles {
+		entries = append(entries, LeaderboardEntry{UserID: p.UserID, Wallet: p.Wallet, Bank: p.Bank, NetWorth: p.NetWorth})
+	}
+	return entries, nil
+}
+
+// CountEconomyLeaderboard returns how many profiles an economy leaderboard has
+func CountEconomyLeaderboard(guildID string, global bool) (int64, error) {
+	repo, match, err := economyBoard(guildID, global)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return repo.Count(ctx, match)
+}
+
+// GetEconomyRank returns the position (1-based) of a user in an economy
+// leaderboard and their net worth. Users without a profile get rank 0.
+func GetEconomyRank(guildID, userID string, global bool) (int64, int64, error) {
+	repo, match, err := economyBoard(guildID, global)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	id := userID
+	if !global {
+		id = guildID + "_" + userID
+	}
+	var own struct {
+		NetWorth int64 `bson:"net_worth"`
+	}
+	err = repo.FindOne(ctx, bson.M{"_id": id}, &own)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	ahead := bson.M{"net_worth": bson.M{"$gt": own.NetWorth}}
+	for key, value := range match {
+		ahead[key] = value
+	}
+	count, err := repo.Count(ctx, ahead)
+	if err != nil {
+		return 0, 0, err
+	}
+	return count + 1, own.NetWorth, nil
+}
+
+// CountLevelsLeaderboard returns how many users have XP in a guild
+func CountLevelsLeaderboard(guildID string) (int64, error) {
+	if LocalLevelsDM == nil || !LocalLevelsDM.available() {
+		return 0, ErrLeaderboardUnavailable
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return LocalLevelsDM.repo.Count(ctx, bson.M{"guild_id": guildID})
+}
+
+// CountVoiceLeaderboard returns how many users have voice time in a guild
+func CountVoiceLeaderboard(guildID string) (int64, error) {
+	if LocalLevelsDM == nil || !LocalLevelsDM.available() {
+		return 0, ErrLeaderboardUnavailable
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return LocalLevelsDM.repo.Count(ctx, bson.M{"guild_id": guildID, "voice_seconds": bson.M{"$gt": 0}})
+}
@@ -0,0 +1,453 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrInsufficientItems = errors.New("insufficient items")
+	ErrInvalidAmount     = errors.New("amount must be positive")
+)
+
+// BalanceChange is a set of deltas applied atomically to one economy profile
+type BalanceChange struct {
+	Wallet       int64
+	Bank         int64
+	BankCapacity int64
+	Items        map[string]int // ItemID -> quantity delta
+}
+
+// TxInfo describes why a balance changed; it is stored in the ledger entry
+type TxInfo struct {
+	Type         models.TransactionType
+	Command      string
+	Counterparty string
+	Note         string
+	groupID      string
+}
+
+// LedgerLeg is one balance change of an operation that touches several profiles
+type LedgerLeg struct {
+	Currency string // models.CurrencyLocal or models.CurrencyStars
+	GuildID  string // Only for local legs
+	UserID   string
+	Change   BalanceChange
+	Info     TxInfo
+}
+
+// TransactionFilter selects ledger entries; empty fields are ignored
+type TransactionFilter struct {
+	Currency       string
+	GuildID        string
+	UserID         string
+	CounterpartyID string
+	Type           models.TransactionType
+	Since          time.Time
+}
+
+// ApplyLocalChange atomically applies a change to a local profile and appends it to
+// the ledger. Debits never leave the wallet, bank or inventory below zero and
+// deposits never exceed the bank capacity.
+func ApplyLocalChange(guildID, userID string, change BalanceChange, info TxInfo) (*models.LocalEconomyProfile, error) {
+	profile, err := GetLocalProfile(guildID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	guards, update := change.mongoUpdate("wallet", "bank")
+	seasonal := int64(0)
+	if info.Type.Seasonal() && change.Wallet+change.Bank != 0 {
+		seasonal = change.Wallet + change.Bank
+		update["$inc"].(bson.M)["season_earnings"] = seasonal
+	}
+	updated, err := LocalEconomyDM.Update(bson.M{"_id": profile.ID}, guards, update)
+	switch {
+	case errors.Is(err, ErrDatabaseOffline):
+		// Offline: apply the change to the cached profile and queue the guarded delta
+		if err := change.check(profile.Wallet, profile.Bank, profile.BankCapacity, profile.Inventory); err != nil {
+			return nil, err
+		}
+		profile.Wallet += change.Wallet
+		profile.Bank += change.Bank
+		profile.NetWorth = profile.Wallet + profile.Bank
+		profile.SeasonEarnings += seasonal
+		profile.BankCapacity += change.BankCapacity
+		profile.Inventory = change.applyItems(profile.Inventory)
+		profile.UpdatedAt = time.Now()
+		if err := LocalEconomyDM.queueUpdate(bson.M{"_id": profile.ID}, guards, update, profile); err != nil {
+			return nil, err
+		}
+		updated = profile
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return nil, change.rejected(profile.Wallet, profile.Bank, profile.BankCapacity, profile.Inventory)
+	case err != nil:
+		return nil, err
+	}
+
+	recordTransaction(&models.Transaction{
+		Currency:    models.CurrencyLocal,
+		GuildID:     guildID,
+		UserID:      userID,
+		WalletAfter: updated.Wallet,
+		BankAfter:   updated.Bank,
+	}, change, info)
+	return updated, nil
+}
+
+// ApplyStarsChange is ApplyLocalChange for the global (Stars) profile
+func ApplyStarsChange(userID string, change BalanceChange, info TxInfo) (*models.GlobalEconomyProfile, error) {
+	profile, err := GetGlobalProfile(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	guards, update := change.mongoUpdate("stars_wallet", "stars_bank")
+	updated, err := GlobalEconomyDM.Update(bson.M{"_id": userID}, guards, update)
+	switch {
+	case errors.Is(err, ErrDatabaseOffline):
+		if err := change.check(profile.StarsWallet, profile.StarsBank, profile.BankCapacity, profile.Inventory); err != nil {
+			return nil, err
+		}
+		profile.StarsWallet += change.Wallet
+		profile.StarsBank += change.Bank
+		profile.NetWorth = profile.StarsWallet + profile.StarsBank
+		profile.BankCapacity += change.BankCapacity
+		profile.Inventory = change.applyItems(profile.Inventory)
+		profile.UpdatedAt = time.Now()
+		if err := GlobalEconomyDM.queueUpdate(bson.M{"_id": userID}, guards, update, profile); err != nil {
+			return nil, err
+		}
+		updated = profile
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return nil, change.rejected(profile.StarsWallet, profile.StarsBank, profile.BankCapacity, profile.Inventory)
+	case err != nil:
+		return nil, err
+	}
+
+	recordTransaction(&models.Transaction{
+		Currency:    models.CurrencyStars,
+		UserID:      userID,
+		WalletAfter: updated.StarsWallet,
+		BankAfter:   updated.StarsBank,
+	}, change, info)
+	return updated, nil
+}
+
+// ApplyLegs applies several balance changes as a unit. Legs that take something from
+// a profile go first; if any leg is rejected the legs already applied are reverted
+// with refund entries. All the entries share the same group ID.
+func ApplyLegs(legs []LedgerLeg) error {
+	groupID := newTransferGroup()
+	ordered := make([]LedgerLeg, 0, len(legs))
+	for _, leg := range legs {
+		if leg.Change.debits() {
+			ordered = append(ordered, leg)
+		}
+	}
+	for _, leg := range legs {
+		if !leg.Change.debits() {
+			ordered = append(ordered, leg)
+		}
+	}
+
+	for i, leg := range ordered {
+		leg.Info.groupID = groupID
+		if err := leg.apply(); err != nil {
+			for _, done := range ordered[:i] {
+				done.Info.groupID = groupID
+				done.Info.Type = models.TransactionRefund
+				done.Change = done.Change.inverse()
+				if revertErr := done.apply(); revertErr != nil {
+					logger.Error(fmt.Sprintf("No se pudo revertir la operación %s de %s: %v", groupID, done.UserID, revertErr), "Ledger")
+				}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (l LedgerLeg) apply() error {
+	var err error
+	if l.Currency == models.CurrencyStars {
+		_, err = ApplyStarsChange(l.UserID, l.Change, l.Info)
+	} else {
+		_, err = ApplyLocalChange(l.GuildID, l.UserID, l.Change, l.Info)
+	}
+	return err
+}
+
+// debits reports whether the change takes money or items from the profile
+func (c BalanceChange) debits() bool {
+	if c.Wallet < 0 || c.Bank < 0 || c.BankCapacity < 0 {
+		return true
+	}
+	for _, qty := range c.Items {
+		if qty < 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// IsZero reports whether the change does nothing
+func (c BalanceChange) IsZero() bool {
+	if c.Wallet != 0 || c.Bank != 0 || c.BankCapacity != 0 {
+		return false
+	}
+	for _, qty := range c.Items {
+		if qty != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (c BalanceChange) inverse() BalanceChange {
+	inv := BalanceChange{Wallet: -c.Wallet, Bank: -c.Bank, BankCapacity: -c.BankCapacity}
+	if len(c.Items) > 0 {
+		inv.Items = make(map[string]int, len(c.Items))
+		for itemID, qty := range c.Items {
+			inv.Items[itemID] = -qty
+		}
+	}
+	return inv
+}
+
+// mongoUpdate builds the guarded $inc for the change
+func (c BalanceChange) mongoUpdate(walletField, bankField string) (bson.M, bson.M) {
+	guards := bson.M{}
+	inc := bson.M{}
+
+	if c.Wallet != 0 {
+		inc[walletField] = c.Wallet
+		if c.Wallet < 0 {
+			guards[walletField] = bson.M{"$gte": -c.Wallet}
+		}
+	}
+	if c.Bank != 0 {
+		inc[bankField] = c.Bank
+		if c.Bank < 0 {
+			guards[bankField] = bson.M{"$gte": -c.Bank}
+		} else {
+			guards["$expr"] = bson.M{"$lte": bson.A{
+				bson.M{"$add": bson.A{"$" + bankField, c.Bank}},
+				bson.M{"$add": bson.A{"$bank_capacity", c.BankCapacity}},
+			}}
+		}
+	}
+	if c.Wallet+c.Bank != 0 {
+		inc["net_worth"] = c.Wallet + c.Bank
+	}
+	if c.BankCapacity != 0 {
+		inc["bank_capacity"] = c.BankCapacity
+	}
+	for itemID, qty := range c.Items {
+		if qty == 0 {
+			continue
+		}
+		field := "inventory." + itemID
+		inc[field] = qty
+		if qty < 0 {
+			guards[field] = bson.M{"$gte": -qty}
+		}
+	}
+
+	update := bson.M{"$set": bson.M{"updated_at": time.Now()}}
+	if len(inc) > 0 {
+		update["$inc"] = inc
+	}
+	return guards, update
+}
+
+// check explains why the change cannot be applied to the given balances
+func (c BalanceChange) check(wallet, bank, capacity int64, inventory map[string]int) error {
+	for itemID, qty := range c.Items {
+		if qty < 0 && inventory[itemID] < -qty {
+			return ErrInsufficientItems
+		}
+	}
+	if wallet+c.Wallet < 0 || bank+c.Bank < 0 {
+		return ErrInsufficientFunds
+	}
+	if c.Bank > 0 && bank+c.Bank > capacity+c.BankCapacity {
+		return ErrBankFull
+	}
+	return nil
+}
+
+// rejected explains why a guard failed. The cached balances may be stale, so it
+// falls back to ErrInsufficientFunds when they look fine.
+func (c BalanceChange) rejected(wallet, bank, capacity int64, inventory map[string]int) error {
+	if err := c.check(wallet, bank, capacity, inventory); err != nil {
+		return err
+	}
+	return ErrInsufficientFunds
+}
+
+// applyItems applies the item deltas to an inventory, dropping empty entries
+func (c BalanceChange) applyItems(inventory map[string]int) map[string]int {
+	if inventory == nil {
+		inventory = make(map[string]int)
+	}
+	for itemID, qty := range c.Items {
+		inventory[itemID] += qty
+		if inventory[itemID] <= 0 {
+			delete(inventory, itemID)
+		}
+	}
+	return inventory
+}
+
+// recordTransaction appends a ledger entry. A failure to record never undoes the
+// balance change; the entry is queued until the database is back.
+func recordTransaction(tx *models.Transaction, change BalanceChange, info TxInfo) {
+	if TransactionsDM == nil {
+		return
+	}
+
+	tx.ID = uuid.New().String()
+	tx.GroupID = info.groupID
+	tx.Type = info.Type
+	tx.Amount = change.Wallet + change.Bank
+	tx.WalletDelta = change.Wallet
+	tx.BankDelta = change.Bank
+	tx.CounterpartyID = info.Counterparty
+	tx.Command = info.Command
+	tx.Note = info.Note
+	tx.CreatedAt = time.Now()
+	if len(change.Items) > 0 {
+		tx.Items = change.Items
+	}
+
+	if TransactionsDM.available() {
+		ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+		defer cancel()
+		if err := TransactionsDM.repo.InsertOne(ctx, tx); err == nil {
+			return
+		}
+	}
+
+	logger.Debug(fmt.Sprintf("Transacción %s encolada hasta que vuelva la DB", tx.ID), "Ledger")
+	if err := TransactionsDM.dbInstance.AddToWriteQueue(QueuedOperation{
+		CollectionName: TransactionsDM.collectionName,
+		Query:          bson.M{"_id": tx.ID},
+		Operation:      "set",
+		Data:           tx,
+	}); err != nil {
+		logger.Error(fmt.Sprintf("Transacción %s perdida: %v", tx.ID, err), "Ledger")
+	}
+}
+
+// newTransferGroup returns a group ID shared by the legs of a transfer
+func newTransferGroup() string {
+	return uuid.New().String()
+}
+
+// GetTransactions returns the newest ledger entries matching the filter
+func GetTransactions(filter TransactionFilter, limit, skip int64) ([]*models.Transaction, error) {
+	if TransactionsDM == nil {
+		return nil, ErrEconomyManagerNotInitialized
+	}
+	if !TransactionsDM.available() {
+		return nil, ErrDatabaseOffline
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var results []*models.Transaction
+	err := TransactionsDM.repo.Find(ctx, filter.query(), FindOptions{
+		Sort:  bson.D{{Key: "created_at", Value: -1}},
+		Skip:  skip,
+		Limit: limit,
+	}, &results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// GetTransactionFlows sums, per counterparty, what a user received and sent. It is
+// used by the audit view to spot money being funneled between accounts.
+func GetTransactionFlows(filter TransactionFilter, limit int64) ([]*models.TransactionFlow, error) {
+	if TransactionsDM == nil {
+		return nil, ErrEconomyManagerNotInitialized
+	}
+	if !TransactionsDM.available() {
+		return nil, ErrDatabaseOffline
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	match := filter.query()
+	if _, ok := match["counterparty_id"]; !ok {
+		match["counterparty_id"] = bson.M{"$nin": bson.A{"", nil}}
+	}
+	var entries []struct {
+		CounterpartyID string `bson:"counterparty_id"`
+		Amount         int64  `bson:"amount"`
+	}
+	if err := TransactionsDM.repo.Find(ctx, match, FindOptions{}, &entries); err != nil {
+		return nil, err
+	}
+
+	byCounterparty := make(map[string]*models.TransactionFlow)
+	var results []*models.TransactionFlow
+	for _, e := range entries {
+		flow, ok := byCounterparty[e.CounterpartyID]
+		if !ok {
+			flow = &models.TransactionFlow{CounterpartyID: e.CounterpartyID}
+			byCounterparty[e.CounterpartyID] = flow
+			results = append(results, flow)
+		}
+		if e.Amount > 0 {
+			flow.Received += e.Amount
+		} else {
+			flow.Sent -= e.Amount
+		}
+		flow.Count++
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Received+results[i].Sent > results[j].Received+results[j].Sent
+	})
+	if int64(len(results)) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+func (f TransactionFilter) query() bson.M {
+	query := bson.M{}
+	if f.Currency != "" {
+		query["currency"] = f.Currency
+	}
+	if f.GuildID != "" {
+		query["guild_id"] = f.GuildID
+	}
+	if f.UserID != "" {
+		query["user_id"] = f.UserID
+	}
+	if f.CounterpartyID != "" {
+		query["counterparty_id"] = f.CounterpartyID
+	}
+	if f.Type != "" {
+		query["type"] = f.Type
+	}
+	if !f.Since.IsZero() {
+		query["created_at"] = bson.M{"$gte": f.Since}
+	}
+	return query
+}
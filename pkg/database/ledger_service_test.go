@@ -0,0 +1,127 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestBalanceChangeGuards(t *testing.T) {
+	change := BalanceChange{Wallet: -100, Bank: 100, Items: map[string]int{"sword": -1}}
+	guards, update := change.mongoUpdate("wallet", "bank")
+
+	if got := guards["wallet"]; got == nil || got.(bson.M)["$gte"] != int64(100) {
+		t.Errorf("wallet guard = %v, want $gte 100", got)
+	}
+	if got := guards["inventory.sword"]; got == nil || got.(bson.M)["$gte"] != 1 {
+		t.Errorf("item guard = %v, want $gte 1", got)
+	}
+	if _, ok := guards["$expr"]; !ok {
+		t.Error("deposit without bank capacity guard")
+	}
+	inc := update["$inc"].(bson.M)
+	if inc["wallet"] != int64(-100) || inc["bank"] != int64(100) || inc["inventory.sword"] != -1 {
+		t.Errorf("unexpected $inc %v", inc)
+	}
+	if _, ok := inc["net_worth"]; ok {
+		t.Error("a deposit must not change the net worth")
+	}
+
+	// Credits need no guard
+	guards, update = BalanceChange{Wallet: 50}.mongoUpdate("wallet", "bank")
+	if len(guards) != 0 {
+		t.Errorf("credit guards = %v, want none", guards)
+	}
+	if got := update["$inc"].(bson.M)["net_worth"]; got != int64(50) {
+		t.Errorf("net_worth $inc = %v, want 50", got)
+	}
+}
+
+func TestBalanceChangeCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		change BalanceChange
+		want   error
+	}{
+		{"credit", BalanceChange{Wallet: 500}, nil},
+		{"debit", BalanceChange{Wallet: -100}, nil},
+		{"overdraft", BalanceChange{Wallet: -101}, ErrInsufficientFunds},
+		{"deposit", BalanceChange{Wallet: -100, Bank: 100}, nil},
+		{"bank full", BalanceChange{Bank: 951}, ErrBankFull},
+		{"bank expanded", BalanceChange{Bank: 951, BankCapacity: 1}, nil},
+		{"missing item", BalanceChange{Items: map[string]int{"sword": -2}}, ErrInsufficientItems},
+	}
+
+	inventory := map[string]int{"sword": 1}
+	for _, tt := range tests {
+		if err := tt.change.check(100, 50, 1000, inventory); !errors.Is(err, tt.want) {
+			t.Errorf("%s: check() = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestOfflineChangeQueuesDelta(t *testing.T) {
+	db := NewDatabase()
+	InitGlobalDataManagers(db)
+	t.Cleanup(func() { globalCacheManager.removePrefix("") })
+
+	profile, err := ApplyLocalChange("g-offline", "u1", BalanceChange{Wallet: 25}, TxInfo{Type: models.TransactionEarn})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached, _ := GetLocalProfile("g-offline", "u1"); cached.Wallet != profile.Wallet {
+		t.Errorf("cached wallet = %d, want %d", cached.Wallet, profile.Wallet)
+	}
+
+	var delta *QueuedOperation
+	for _, op := range db.PendingWrites() {
+		if op.CollectionName == LocalEconomyDM.collectionName && op.Update["$inc"] != nil {
+			delta = &op
+		}
+	}
+	if delta == nil {
+		t.Fatalf("no $inc queued, got %+v", db.PendingWrites())
+	}
+	if inc := delta.Update["$inc"].(bson.M); inc["wallet"] != int64(25) {
+		t.Errorf("queued $inc = %v, want wallet 25", inc)
+	}
+	if delta.Data != nil {
+		t.Error("the delta must not carry the whole profile")
+	}
+}
+
+func TestTransactionFlowsInMemory(t *testing.T) {
+	useMemoryDatabase(t)
+
+	ctx := context.Background()
+	for i, tx := range []models.Transaction{
+		{UserID: "u1", CounterpartyID: "bob", Amount: 100},
+		{UserID: "u1", CounterpartyID: "bob", Amount: -30},
+		{UserID: "u1", CounterpartyID: "carol", Amount: 10},
+		{UserID: "u1", Amount: 500},
+		{UserID: "u2", CounterpartyID: "carol", Amount: 900},
+	} {
+		tx.ID = fmt.Sprintf("tx%d", i)
+		if err := TransactionsDM.repo.InsertOne(ctx, &tx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	flows, err := GetTransactionFlows(TransactionFilter{UserID: "u1"}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(flows) != 2 {
+		t.Fatalf("Expected 2 counterparties, got %+v", flows)
+	}
+	if f := flows[0]; f.CounterpartyID != "bob" || f.Received != 100 || f.Sent != 30 || f.Count != 2 {
+		t.Errorf("Unexpected flow with bob: %+v", f)
+	}
+	if f := flows[1]; f.CounterpartyID != "carol" || f.Received != 10 || f.Count != 1 {
+		t.Errorf("Unexpected flow with carol: %+v", f)
+	}
+}
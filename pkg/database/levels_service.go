@@ -7,7 +7,6 @@ import (
 
 	"github.com/PancyStudios/PancyBotGo/pkg/models"
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // GetLocalLevelProfile retrieves the user's level profile or creates a new one
@@ -43,27 +42,169 @@ func GetLocalLevelProfile(guildID, userID string) (*models.UserLevelProfile, err
 	return profile, nil
 }
 
-// GetTopLevels retrieves the top users by XP in a guild
-func GetTopLevels(guildID string, limit int64) ([]*models.UserLevelProfile, error) {
-	query := bson.M{"guild_id": guildID}
+// GetTopLevels returns a page of the users with the most XP in a guild
+func GetTopLevels(guildID string, limit, skip int64) ([]*models.UserLevelProfile, error) {
+	if LocalLevelsDM == nil || !LocalLevelsDM.available() {
+		return nil, fmt.Errorf("levels data manager not initialized")
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	findOptions := options.Find().
-		SetSort(bson.D{{Key: "xp", Value: -1}}).
-		SetLimit(limit)
-
-	cursor, err := LocalLevelsDM.collection.Find(ctx, query, findOptions)
+	var results []*models.UserLevelProfile
+	err := LocalLevelsDM.repo.Find(ctx, bson.M{"guild_id": guildID}, FindOptions{
+		Sort:  bson.D{{Key: "xp", Value: -1}, {Key: "_id", Value: 1}},
+		Skip:  skip,
+		Limit: limit,
+	}, &results)
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(ctx)
+
+	return results, nil
+}
+
+// GetLevelRank returns the position (1-based) of a user with the given XP in the guild ranking
+func GetLevelRank(guildID string, xp int64) (int, error) {
+	if LocalLevelsDM == nil || !LocalLevelsDM.available() {
+		return 0, fmt.Errorf("levels data manager not initialized")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ahead, err := LocalLevelsDM.repo.Count(ctx, bson.M{"guild_id": guildID, "xp": bson.M{"$gt": xp}})
+	if err != nil {
+		return 0, err
+	}
+	return int(ahead) + 1, nil
+}
+
+// GetTopVoice returns a page of the users with the most voice time in a guild
+func GetTopVoice(guildID string, limit, skip int64) ([]*models.UserLevelProfile, error) {
+	if LocalLevelsDM == nil || !LocalLevelsDM.available() {
+		return nil, fmt.Errorf("levels data manager not initialized")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
 	var results []*models.UserLevelProfile
-	if err = cursor.All(ctx, &results); err != nil {
+	err := LocalLevelsDM.repo.Find(ctx, bson.M{"guild_id": guildID, "voice_seconds": bson.M{"$gt": 0}}, FindOptions{
+		Sort:  bson.D{{Key: "voice_seconds", Value: -1}, {Key: "_id", Value: 1}},
+		Skip:  skip,
+		Limit: limit,
+	}, &results)
+	if err != nil {
 		return nil, err
 	}
 
 	return results, nil
 }
+
+// GetVoiceRank returns the position (1-based) of a user with the given voice time in the guild ranking
+func GetVoiceRank(guildID string, seconds int64) (int, error) {
+	if LocalLevelsDM == nil || !LocalLevelsDM.available() {
+		return 0, fmt.Errorf("levels data manager not initialized")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ahead, err := LocalLevelsDM.repo.Count(ctx, bson.M{"guild_id": guildID, "voice_seconds": bson.M{"$gt": seconds}})
+	if err != nil {
+		return 0, err
+	}
+	return int(ahead) + 1, nil
+}
+
+// GetGuildLevelProfiles returns every level profile of a guild, most XP first
+func GetGuildLevelProfiles(guildID string) ([]*models.UserLevelProfile, error) {
+	if LocalLevelsDM == nil || !LocalLevelsDM.available() {
+		return nil, ErrDatabaseOffline
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var results []*models.UserLevelProfile
+	err := LocalLevelsDM.repo.Find(ctx, bson.M{"guild_id": guildID}, FindOptions{
+		Sort: bson.D{{Key: "xp", Value: -1}, {Key: "_id", Value: 1}},
+	}, &results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// DeleteLevelProfile removes the level profile of a user in a guild
+func DeleteLevelProfile(guildID, userID string) error {
+	return LocalLevelsDM.Delete(bson.M{"_id": fmt.Sprintf("%s_%s", guildID, userID)})
+}
+
+// DeleteGuildLevelProfiles removes every level profile of a guild and returns
+// the IDs of the users that had one
+func DeleteGuildLevelProfiles(guildID string) ([]string, error) {
+	profiles, err := GetGuildLevelProfiles(guildID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if _, err := LocalLevelsDM.repo.DeleteMany(ctx, bson.M{"guild_id": guildID}); err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]string, 0, len(profiles))
+	for _, profile := range profiles {
+		LocalLevelsDM.Invalidate(bson.M{"_id": profile.ID})
+		userIDs = append(userIDs, profile.UserID)
+	}
+	return userIDs, nil
+}
+
+// ImportLevelProfiles writes the XP, level and counters of the given profiles,
+// creating the users that had none. Users missing from the import are kept.
+func ImportLevelProfiles(guildID string, profiles []*models.UserLevelProfile) error {
+	if LocalLevelsDM == nil || !LocalLevelsDM.available() {
+		return ErrDatabaseOffline
+	}
+	if len(profiles) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	upserts := make([]Upsert, 0, len(profiles))
+	for _, profile := range profiles {
+		id := fmt.Sprintf("%s_%s", guildID, profile.UserID)
+		upserts = append(upserts, Upsert{
+			Filter: bson.M{"_id": id},
+			Update: bson.M{
+				"$set": bson.M{
+					"xp":             profile.XP,
+					"level":          profile.Level,
+					"total_messages": profile.TotalMessages,
+					"voice_seconds":  profile.VoiceSeconds,
+					"updated_at":     now,
+				},
+				"$setOnInsert": bson.M{
+					"guild_id":   guildID,
+					"user_id":    profile.UserID,
+					"created_at": now,
+				},
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	if err := LocalLevelsDM.repo.UpsertMany(ctx, upserts); err != nil {
+		return err
+	}
+
+	for _, profile := range profiles {
+		LocalLevelsDM.Invalidate(bson.M{"_id": fmt.Sprintf("%s_%s", guildID, profile.UserID)})
+	}
+	return nil
+}
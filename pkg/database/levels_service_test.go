@@ -0,0 +1,53 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+)
+
+func TestLevelRankingWithMemoryDatabase(t *testing.T) {
+	useMemoryDatabase(t)
+
+	err := ImportLevelProfiles("g1", []*models.UserLevelProfile{
+		{UserID: "alice", XP: 300, Level: 3},
+		{UserID: "bob", XP: 900, Level: 6, VoiceSeconds: 60},
+		{UserID: "carol", XP: 300, Level: 3},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ImportLevelProfiles("g2", []*models.UserLevelProfile{{UserID: "dave", XP: 5000}}); err != nil {
+		t.Fatal(err)
+	}
+
+	top, err := GetTopLevels("g1", 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(top) != 2 || top[0].UserID != "bob" || top[1].UserID != "alice" {
+		t.Errorf("Expected bob then alice (ties by ID), got %+v", top)
+	}
+	if rank, err := GetLevelRank("g1", 300); err != nil || rank != 2 {
+		t.Errorf("GetLevelRank() = %d, %v, want 2", rank, err)
+	}
+	if voice, err := GetTopVoice("g1", 10, 0); err != nil || len(voice) != 1 {
+		t.Errorf("GetTopVoice() = %d profiles, %v, want 1", len(voice), err)
+	}
+
+	profile, err := GetLocalLevelProfile("g1", "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.XP != 300 || profile.GuildID != "g1" {
+		t.Errorf("Unexpected imported profile %+v", profile)
+	}
+
+	deleted, err := DeleteGuildLevelProfiles("g1")
+	if err != nil || len(deleted) != 3 {
+		t.Errorf("DeleteGuildLevelProfiles() = %v, %v", deleted, err)
+	}
+	if n, err := CountLevelsLeaderboard("g2"); err != nil || n != 1 {
+		t.Errorf("CountLevelsLeaderboard() = %d, %v, want 1", n, err)
+	}
+}
@@ -0,0 +1,375 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrMarketManagerNotInitialized = errors.New("market data manager not initialized")
+	ErrListingNotFound             = errors.New("listing not found")
+	ErrListingUnavailable          = errors.New("listing is no longer available")
+	ErrInvalidListing              = errors.New("a listing needs a buy now price or a starting bid")
+	ErrOwnListing                  = errors.New("cannot buy or bid on your own listing")
+	ErrBidTooLow                   = errors.New("bid is too low")
+	ErrListingHasBids              = errors.New("listing already has bids")
+)
+
+const (
+	// ListingFeePercent of the highest price of the listing is charged up front
+	ListingFeePercent = 5
+	MinListingFee     = int64(1)
+	// MinBidIncrementPercent over the current bid is required to outbid it
+	MinBidIncrementPercent = 5
+	DefaultListingDuration = 24 * time.Hour
+	MaxListingDuration     = 72 * time.Hour
+)
+
+// ListingFee returns the fee charged to publish a listing
+func ListingFee(buyNow, startingBid int64) int64 {
+	base := buyNow
+	if startingBid > base {
+		base = startingBid
+	}
+	fee := base * ListingFeePercent / 100
+	if fee < MinListingFee {
+		fee = MinListingFee
+	}
+	return fee
+}
+
+// MinNextBid returns the lowest bid accepted by a listing
+func MinNextBid(listing *models.MarketListing) int64 {
+	if listing.BidderID == "" {
+		return listing.StartingBid
+	}
+	increment := listing.CurrentBid * MinBidIncrementPercent / 100
+	if increment < 1 {
+		increment = 1
+	}
+	return listing.CurrentBid + increment
+}
+
+// itemLeg moves items in or out of the inventory that holds them
+func itemLeg(guildID, userID, itemID string, global bool, qty int, info TxInfo) LedgerLeg {
+	leg := LedgerLeg{Currency: models.CurrencyLocal, GuildID: guildID, UserID: userID, Change: BalanceChange{Items: map[string]int{itemID: qty}}, Info: info}
+	if global {
+		leg.Currency = models.CurrencyStars
+		leg.GuildID = ""
+	}
+	return leg
+}
+
+// coinsLeg moves local coins in or out of a wallet
+func coinsLeg(guildID, userID string, amount int64, info TxInfo) LedgerLeg {
+	return LedgerLeg{Currency: models.CurrencyLocal, GuildID: guildID, UserID: userID, Change: BalanceChange{Wallet: amount}, Info: info}
+}
+
+// CreateListing puts items of the seller in escrow, charges the listing fee and
+// publishes the listing
+func CreateListing(guildID, sellerID string, item models.Item, qty int, buyNow, startingBid int64, duration time.Duration) (*models.MarketListing, error) {
+	if MarketListingsDM == nil {
+		return nil, ErrMarketManagerNotInitialized
+	}
+	if qty <= 0 || buyNow < 0 || startingBid < 0 || (buyNow == 0 && startingBid == 0) {
+		return nil, ErrInvalidListing
+	}
+	if buyNow > 0 && startingBid >= buyNow {
+		return nil, ErrInvalidListing
+	}
+	if duration <= 0 {
+		duration = DefaultListingDuration
+	}
+	if duration > MaxListingDuration {
+		duration = MaxListingDuration
+	}
+
+	now := time.Now()
+	listing := &models.MarketListing{
+		ID:          uuid.New().String()[:8],
+		GuildID:     guildID,
+		SellerID:    sellerID,
+		ItemID:      item.ID,
+		ItemName:    item.Name,
+		ItemEmoji:   item.Emoji,
+		ItemGlobal:  item.IsGlobal,
+		Quantity:    qty,
+		BuyNowPrice: buyNow,
+		StartingBid: startingBid,
+		Fee:         ListingFee(buyNow, startingBid),
+		Status:      models.ListingActive,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(duration),
+	}
+
+	note := fmt.Sprintf("Publicación %s", listing.ID)
+	escrow := TxInfo{Type: models.TransactionMarket, Command: "market", Note: note}
+	fee := TxInfo{Type: models.TransactionMarketFee, Command: "market", Note: note}
+	err := ApplyLegs([]LedgerLeg{
+		itemLeg(guildID, sellerID, item.ID, item.IsGlobal, -qty, escrow),
+		coinsLeg(guildID, sellerID, -listing.Fee, fee),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := MarketListingsDM.Set(bson.M{"_id": listing.ID}, listing); err != nil {
+		return nil, err
+	}
+	return listing, nil
+}
+
+// GetListing returns a listing of a guild
+func GetListing(guildID, listingID string) (*models.MarketListing, error) {
+	if MarketListingsDM == nil {
+		return nil, ErrMarketManagerNotInitialized
+	}
+	listing, err := MarketListingsDM.Get(bson.M{"_id": listingID})
+	if err != nil {
+		return nil, err
+	}
+	if listing == nil || listing.GuildID != guildID {
+		return nil, ErrListingNotFound
+	}
+	return listing, nil
+}
+
+// GetActiveListings returns the active listings of a guild, ending soonest first
+func GetActiveListings(guildID string, limit, skip int64) ([]*models.MarketListing, error) {
+	if MarketListingsDM == nil {
+		return nil, ErrMarketManagerNotInitialized
+	}
+	if !MarketListingsDM.available() {
+		return nil, ErrDatabaseOffline
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"guild_id": guildID, "status": models.ListingActive, "expires_at": bson.M{"$gt": time.Now()}}
+	var results []*models.MarketListing
+	err := MarketListingsDM.repo.Find(ctx, filter, FindOptions{
+		Sort:  bson.D{{Key: "expires_at", Value: 1}},
+		Skip:  skip,
+		Limit: limit,
+	}, &results)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// claimListing moves an active listing to settling so only one buyer, bid or sweep
+// can close it
+func claimListing(query, guards bson.M) (*models.MarketListing, error) {
+	guards["status"] = models.ListingActive
+	listing, err := MarketListingsDM.Update(query, guards, bson.M{"$set": bson.M{"status": models.ListingSettling}})
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrListingUnavailable
+	}
+	return listing, err
+}
+
+// closeListing stores the final state of a settled listing
+func closeListing(listingID string, status models.ListingStatus, buyerID string) {
+	set := bson.M{"status": status, "closed_at": time.Now()}
+	if buyerID != "" {
+		set["buyer_id"] = buyerID
+	}
+	if _, err := MarketListingsDM.Update(bson.M{"_id": listingID}, nil, bson.M{"$set": set}); err != nil {
+		logger.Error(fmt.Sprintf("No se pudo cerrar la publicación %s: %v", listingID, err), "Market")
+	}
+}
+
+// reopenListing puts back a claimed listing whose settlement failed
+func reopenListing(listingID string) {
+	if _, err := MarketListingsDM.Update(bson.M{"_id": listingID}, nil, bson.M{"$set": bson.M{"status": models.ListingActive}}); err != nil {
+		logger.Error(fmt.Sprintf("No se pudo reabrir la publicación %s: %v", listingID, err), "Market")
+	}
+}
+
+// BuyListing buys a listing at its buy now price. An escrowed bid is returned to
+// its bidder.
+func BuyListing(guildID, listingID, buyerID string) (*models.MarketListing, error) {
+	if MarketListingsDM == nil {
+		return nil, ErrMarketManagerNotInitialized
+	}
+
+	listing, err := GetListing(guildID, listingID)
+	if err != nil {
+		return nil, err
+	}
+	if listing.SellerID == buyerID {
+		return nil, ErrOwnListing
+	}
+
+	listing, err = claimListing(bson.M{"_id": listingID}, bson.M{
+		"guild_id":      guildID,
+		"seller_id":     bson.M{"$ne": buyerID},
+		"buy_now_price": bson.M{"$gt": 0},
+		"expires_at":    bson.M{"$gt": time.Now()},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	info := TxInfo{Type: models.TransactionMarket, Command: "market", Note: fmt.Sprintf("Publicación %s", listing.ID)}
+	buyer, seller := info, info
+	buyer.Counterparty = listing.SellerID
+	seller.Counterparty = buyerID
+	legs := []LedgerLeg{
+		coinsLeg(guildID, buyerID, -listing.BuyNowPrice, buyer),
+		itemLeg(guildID, buyerID, listing.ItemID, listing.ItemGlobal, listing.Quantity, buyer),
+		coinsLeg(guildID, listing.SellerID, listing.BuyNowPrice, seller),
+	}
+	if listing.BidderID != "" {
+		refund := info
+		refund.Type = models.TransactionRefund
+		legs = append(legs, coinsLeg(guildID, listing.BidderID, listing.CurrentBid, refund))
+	}
+
+	if err := ApplyLegs(legs); err != nil {
+		reopenListing(listing.ID)
+		return nil, err
+	}
+
+	closeListing(listing.ID, models.ListingSold, buyerID)
+	listing.Status = models.ListingSold
+	listing.BuyerID = buyerID
+	return listing, nil
+}
+
+// PlaceBid escrows a bid and returns the coins of the previous bidder
+func PlaceBid(guildID, listingID, bidderID string, amount int64) (*models.MarketListing, error) {
+	listing, err := GetListing(guildID, listingID)
+	if err != nil {
+		return nil, err
+	}
+	if listing.Status != models.ListingActive || !listing.ExpiresAt.After(time.Now()) || !listing.AcceptsBids() {
+		return nil, ErrListingUnavailable
+	}
+	if listing.SellerID == bidderID {
+		return nil, ErrOwnListing
+	}
+	if amount < MinNextBid(listing) || (listing.BuyNowPrice > 0 && amount >= listing.BuyNowPrice) {
+		return nil, ErrBidTooLow
+	}
+
+	info := TxInfo{Type: models.TransactionMarket, Command: "bid", Counterparty: listing.SellerID, Note: fmt.Sprintf("Puja en %s", listing.ID)}
+	if err := ApplyLegs([]LedgerLeg{coinsLeg(guildID, bidderID, -amount, info)}); err != nil {
+		return nil, err
+	}
+
+	// Only succeeds if nobody else bid in the meantime
+	updated, err := MarketListingsDM.Update(bson.M{"_id": listing.ID}, bson.M{
+		"status":      models.ListingActive,
+		"current_bid": listing.CurrentBid,
+		"bidder_id":   listing.BidderID,
+		"expires_at":  bson.M{"$gt": time.Now()},
+	}, bson.M{
+		"$set": bson.M{"current_bid": amount, "bidder_id": bidderID},
+		"$inc": bson.M{"bids": 1},
+	})
+	if err != nil {
+		info.Type = models.TransactionRefund
+		_ = ApplyLegs([]LedgerLeg{coinsLeg(guildID, bidderID, amount, info)})
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrListingUnavailable
+		}
+		return nil, err
+	}
+
+	if listing.BidderID != "" {
+		refund := TxInfo{Type: models.TransactionRefund, Command: "bid", Counterparty: bidderID, Note: fmt.Sprintf("Puja superada en %s", listing.ID)}
+		if err := ApplyLegs([]LedgerLeg{coinsLeg(guildID, listing.BidderID, listing.CurrentBid, refund)}); err != nil {
+			logger.Error(fmt.Sprintf("No se pudo devolver la puja de %s en %s: %v", listing.BidderID, listing.ID, err), "Market")
+		}
+	}
+	return updated, nil
+}
+
+// CancelListing withdraws a listing without bids and returns the items. The
+// listing fee is not refunded.
+func CancelListing(guildID, listingID, sellerID string) (*models.MarketListing, error) {
+	if MarketListingsDM == nil {
+		return nil, ErrMarketManagerNotInitialized
+	}
+
+	listing, err := GetListing(guildID, listingID)
+	if err != nil {
+		return nil, err
+	}
+	if listing.BidderID != "" {
+		return nil, ErrListingHasBids
+	}
+
+	listing, err = claimListing(bson.M{"_id": listingID}, bson.M{"guild_id": guildID, "seller_id": sellerID, "bidder_id": ""})
+	if err != nil {
+		return nil, err
+	}
+
+	info := TxInfo{Type: models.TransactionMarket, Command: "market", Note: fmt.Sprintf("Publicación %s cancelada", listing.ID)}
+	if err := ApplyLegs([]LedgerLeg{itemLeg(guildID, sellerID, listing.ItemID, listing.ItemGlobal, listing.Quantity, info)}); err != nil {
+		reopenListing(listing.ID)
+		return nil, err
+	}
+
+	closeListing(listing.ID, models.ListingCancelled, "")
+	listing.Status = models.ListingCancelled
+	return listing, nil
+}
+
+// SettleExpiredListings closes the listings whose time ran out: the highest bidder
+// gets the items and the seller the bid, or the items go back to the seller.
+// It returns how many listings were settled.
+func SettleExpiredListings() (int, error) {
+	if MarketListingsDM == nil {
+		return 0, ErrMarketManagerNotInitialized
+	}
+
+	expired, err := MarketListingsDM.GetAll(bson.M{"status": models.ListingActive, "expires_at": bson.M{"$lte": time.Now()}})
+	if err != nil {
+		return 0, err
+	}
+
+	settled := 0
+	for _, candidate := range expired {
+		listing, err := claimListing(bson.M{"_id": candidate.ID}, bson.M{})
+		if err != nil {
+			continue
+		}
+
+		info := TxInfo{Type: models.TransactionMarket, Command: "market", Note: fmt.Sprintf("Publicación %s finalizada", listing.ID)}
+		status := models.ListingExpired
+		var legs []LedgerLeg
+		if listing.BidderID != "" {
+			winner, seller := info, info
+			winner.Counterparty = listing.SellerID
+			seller.Counterparty = listing.BidderID
+			legs = []LedgerLeg{
+				itemLeg(listing.GuildID, listing.BidderID, listing.ItemID, listing.ItemGlobal, listing.Quantity, winner),
+				coinsLeg(listing.GuildID, listing.SellerID, listing.CurrentBid, seller),
+			}
+			status = models.ListingSold
+		} else {
+			legs = []LedgerLeg{itemLeg(listing.GuildID, listing.SellerID, listing.ItemID, listing.ItemGlobal, listing.Quantity, info)}
+		}
+
+		if err := ApplyLegs(legs); err != nil {
+			logger.Error(fmt.Sprintf("No se pudo liquidar la publicación %s: %v", listing.ID, err), "Market")
+			reopenListing(listing.ID)
+			continue
+		}
+		closeListing(listing.ID, status, listing.BidderID)
+		settled++
+	}
+	return settled, nil
+}
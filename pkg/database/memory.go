@@ -0,0 +1,788 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// memoryStore holds the collections of a Database without MongoDB
+type memoryStore struct {
+	mu          sync.Mutex
+	collections map[string]*memoryRepository
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{collections: make(map[string]*memoryRepository)}
+}
+
+func (s *memoryStore) collection(name string) *memoryRepository {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	repo, ok := s.collections[name]
+	if !ok {
+		repo = &memoryRepository{name: name}
+		s.collections[name] = repo
+	}
+	return repo
+}
+
+// memoryRepository is a Repository kept in memory. It understands the subset
+// of the MongoDB query language the services use: equality, $gt, $gte, $lt,
+// $lte, $ne, $in, $nin, $exists, $and, $or and $expr with arithmetic and
+// comparisons; and the $set, $unset, $inc and $setOnInsert updates.
+type memoryRepository struct {
+	name string
+	mu   sync.Mutex
+	docs []bson.M
+}
+
+func (r *memoryRepository) Available() bool {
+	return true
+}
+
+func (r *memoryRepository) FindOne(ctx context.Context, filter bson.M, result interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i, err := r.indexLocked(filter)
+	if err != nil {
+		return err
+	}
+	if i < 0 {
+		return mongo.ErrNoDocuments
+	}
+	return decodeDocument(r.docs[i], result)
+}
+
+func (r *memoryRepository) Find(ctx context.Context, filter bson.M, opts FindOptions, results interface{}) error {
+	r.mu.Lock()
+	matches, err := r.matchesLocked(filter)
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if opts.Sort != nil {
+		sort.SliceStable(matches, func(i, j int) bool {
+			for _, key := range opts.Sort {
+				a, _ := lookupPath(matches[i], key.Key)
+				b, _ := lookupPath(matches[j], key.Key)
+				c := compareOrder(a, b)
+				if c == 0 {
+					continue
+				}
+				if direction, _ := toFloat(key.Value); direction < 0 {
+					return c > 0
+				}
+				return c < 0
+			}
+			return false
+		})
+	}
+	if opts.Skip > 0 {
+		if opts.Skip >= int64(len(matches)) {
+			matches = nil
+		} else {
+			matches = matches[opts.Skip:]
+		}
+	}
+	if opts.Limit > 0 && int64(len(matches)) > opts.Limit {
+		matches = matches[:opts.Limit]
+	}
+
+	return decodeEach(results, r.name, func(next func(func(interface{}) error) error) error {
+		for _, doc := range matches {
+			doc := doc
+			if err := next(func(v interface{}) error { return decodeDocument(doc, v) }); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *memoryRepository) Count(ctx context.Context, filter bson.M) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	matches, err := r.matchesLocked(filter)
+	return int64(len(matches)), err
+}
+
+func (r *memoryRepository) FindOneAndUpdate(ctx context.Context, filter, update bson.M, upsert bool, result interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	doc, err := r.updateLocked(filter, update, upsert)
+	if err != nil {
+		return err
+	}
+	return decodeDocument(doc, result)
+}
+
+func (r *memoryRepository) InsertOne(ctx context.Context, doc interface{}) error {
+	stored, err := bsonDocument(doc)
+	if err != nil {
+		return err
+	}
+	if _, ok := stored["_id"]; !ok {
+		stored["_id"] = primitive.NewObjectID()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if i, _ := r.indexLocked(bson.M{"_id": stored["_id"]}); i >= 0 {
+		return mongo.WriteException{WriteErrors: mongo.WriteErrors{{
+			Code:    11000,
+			Message: fmt.Sprintf("duplicate key _id %v in %s", stored["_id"], r.name),
+		}}}
+	}
+	r.docs = append(r.docs, stored)
+	return nil
+}
+
+func (r *memoryRepository) UpdateMany(ctx context.Context, filter, update bson.M) (int64, error) {
+	normalized, err := bsonDocument(filter)
+	if err != nil {
+		return 0, err
+	}
+	normalizedUpdate, err := bsonDocument(update)
+	if err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var matched int64
+	for i, doc := range r.docs {
+		ok, err := matchDocument(doc, normalized)
+		if err != nil {
+			return matched, err
+		}
+		if !ok {
+			continue
+		}
+		updated := copyDocument(doc)
+		if err := applyUpdate(updated, normalizedUpdate, false); err != nil {
+			return matched, err
+		}
+		r.docs[i] = updated
+		matched++
+	}
+	return matched, nil
+}
+
+func (r *memoryRepository) DeleteOne(ctx context.Context, filter bson.M) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i, err := r.indexLocked(filter)
+	if err != nil || i < 0 {
+		return 0, err
+	}
+	r.docs = append(r.docs[:i], r.docs[i+1:]...)
+	return 1, nil
+}
+
+func (r *memoryRepository) DeleteMany(ctx context.Context, filter bson.M) (int64, error) {
+	normalized, err := bsonDocument(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	kept := r.docs[:0]
+	var deleted int64
+	for _, doc := range r.docs {
+		ok, err := matchDocument(doc, normalized)
+		if err != nil {
+			return deleted, err
+		}
+		if ok {
+			deleted++
+		} else {
+			kept = append(kept, doc)
+		}
+	}
+	r.docs = kept
+	return deleted, nil
+}
+
+func (r *memoryRepository) UpsertMany(ctx context.Context, upserts []Upsert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range upserts {
+		if _, err := r.updateLocked(u.Filter, u.Update, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateLocked applies update to the first match, or to a new document built
+// from the equalities of the filter when upserting
+func (r *memoryRepository) updateLocked(filter, update bson.M, upsert bool) (bson.M, error) {
+	normalizedUpdate, err := bsonDocument(update)
+	if err != nil {
+		return nil, err
+	}
+	i, err := r.indexLocked(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if i >= 0 {
+		doc := copyDocument(r.docs[i])
+		if err := applyUpdate(doc, normalizedUpdate, false); err != nil {
+			return nil, err
+		}
+		r.docs[i] = doc
+		return doc, nil
+	}
+	if !upsert {
+		return nil, mongo.ErrNoDocuments
+	}
+
+	normalizedFilter, err := bsonDocument(filter)
+	if err != nil {
+		return nil, err
+	}
+	doc := bson.M{}
+	for key, value := range normalizedFilter {
+		if strings.HasPrefix(key, "$") || isOperatorDocument(value) {
+			continue
+		}
+		setPath(doc, key, value)
+	}
+	if err := applyUpdate(doc, normalizedUpdate, true); err != nil {
+		return nil, err
+	}
+	if _, ok := doc["_id"]; !ok {
+		doc["_id"] = primitive.NewObjectID()
+	}
+	r.docs = append(r.docs, doc)
+	return doc, nil
+}
+
+func (r *memoryRepository) indexLocked(filter bson.M) (int, error) {
+	normalized, err := bsonDocument(filter)
+	if err != nil {
+		return -1, err
+	}
+	for i, doc := range r.docs {
+		ok, err := matchDocument(doc, normalized)
+		if err != nil {
+			return -1, err
+		}
+		if ok {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
+func (r *memoryRepository) matchesLocked(filter bson.M) ([]bson.M, error) {
+	normalized, err := bsonDocument(filter)
+	if err != nil {
+		return nil, err
+	}
+	var matches []bson.M
+	for _, doc := range r.docs {
+		ok, err := matchDocument(doc, normalized)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matches = append(matches, doc)
+		}
+	}
+	return matches, nil
+}
+
+// decodeDocument decodes a stored document into v
+func decodeDocument(doc bson.M, v interface{}) error {
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return bson.Unmarshal(raw, v)
+}
+
+// copyDocument copies doc deeply, so a failed update leaves it untouched
+func copyDocument(doc bson.M) bson.M {
+	copied := make(bson.M, len(doc))
+	for key, value := range doc {
+		copied[key] = copyValue(value)
+	}
+	return copied
+}
+
+func copyValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case bson.M:
+		return copyDocument(v)
+	case bson.A:
+		copied := make(bson.A, len(v))
+		for i := range v {
+			copied[i] = copyValue(v[i])
+		}
+		return copied
+	}
+	return v
+}
+
+// matchDocument reports whether doc matches a normalized filter
+func matchDocument(doc bson.M, filter bson.M) (bool, error) {
+	for key, cond := range filter {
+		var ok bool
+		var err error
+		switch key {
+		case "$and", "$or":
+			ok, err = matchLogical(doc, key, cond)
+		case "$expr":
+			var value interface{}
+			value, err = evalExpr(doc, cond)
+			ok = truthy(value)
+		default:
+			if strings.HasPrefix(key, "$") {
+				return false, fmt.Errorf("operador %s no soportado en memoria", key)
+			}
+			value, exists := lookupPath(doc, key)
+			ok, err = matchField(value, exists, cond)
+		}
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func matchLogical(doc bson.M, op string, cond interface{}) (bool, error) {
+	clauses, ok := cond.(bson.A)
+	if !ok {
+		return false, fmt.Errorf("%s necesita una lista", op)
+	}
+	for _, clause := range clauses {
+		sub, ok := clause.(bson.M)
+		if !ok {
+			return false, fmt.Errorf("%s necesita documentos", op)
+		}
+		matched, err := matchDocument(doc, sub)
+		if err != nil {
+			return false, err
+		}
+		if op == "$or" && matched {
+			return true, nil
+		}
+		if op == "$and" && !matched {
+			return false, nil
+		}
+	}
+	return op == "$and", nil
+}
+
+// matchField reports whether a field meets a condition: a value it must equal,
+// or a document of operators
+func matchField(value interface{}, exists bool, cond interface{}) (bool, error) {
+	ops, ok := cond.(bson.M)
+	if !ok || !isOperatorDocument(ops) {
+		return valueEquals(value, cond), nil
+	}
+
+	for op, arg := range ops {
+		var ok bool
+		switch op {
+		case "$eq":
+			ok = valueEquals(value, arg)
+		case "$ne":
+			ok = !valueEquals(value, arg)
+		case "$gt", "$gte", "$lt", "$lte":
+			ok = exists && compareMatching(value, arg, op)
+		case "$in", "$nin":
+			list, isList := arg.(bson.A)
+			if !isList {
+				return false, fmt.Errorf("%s necesita una lista", op)
+			}
+			for _, candidate := range list {
+				if valueEquals(value, candidate) {
+					ok = true
+					break
+				}
+			}
+			if op == "$nin" {
+				ok = !ok
+			}
+		case "$exists":
+			ok = exists == truthy(arg)
+		default:
+			return false, fmt.Errorf("operador %s no soportado en memoria", op)
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// compareMatching compares like MongoDB: an array matches if an element does,
+// and values of different types never match
+func compareMatching(value, arg interface{}, op string) bool {
+	if list, ok := value.(bson.A); ok {
+		for _, elem := range list {
+			if compareMatching(elem, arg, op) {
+				return true
+			}
+		}
+		return false
+	}
+	c, ok := compareValues(value, arg)
+	if !ok {
+		return false
+	}
+	switch op {
+	case "$gt":
+		return c > 0
+	case "$gte":
+		return c >= 0
+	case "$lt":
+		return c < 0
+	}
+	return c <= 0
+}
+
+// valueEquals is equality as MongoDB matches it: nil matches a missing field
+// and an array matches any of its elements
+func valueEquals(value, want interface{}) bool {
+	if c, ok := compareValues(value, want); ok && c == 0 {
+		return true
+	}
+	if list, ok := value.(bson.A); ok {
+		if _, wantList := want.(bson.A); !wantList {
+			for _, elem := range list {
+				if valueEquals(elem, want) {
+					return true
+				}
+			}
+		}
+	}
+	return reflect.DeepEqual(value, want)
+}
+
+func isOperatorDocument(v interface{}) bool {
+	doc, ok := v.(bson.M)
+	if !ok || len(doc) == 0 {
+		return false
+	}
+	for key := range doc {
+		if !strings.HasPrefix(key, "$") {
+			return false
+		}
+	}
+	return true
+}
+
+// compareValues orders two values of comparable types: numbers, strings,
+// booleans, dates and object IDs
+func compareValues(a, b interface{}) (int, bool) {
+	if a == nil || b == nil {
+		if a == nil && b == nil {
+			return 0, true
+		}
+		return 0, false
+	}
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	}
+	switch x := a.(type) {
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y), true
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			switch {
+			case x == y:
+				return 0, true
+			case !x:
+				return -1, true
+			}
+			return 1, true
+		}
+	case primitive.DateTime:
+		if y, ok := b.(primitive.DateTime); ok {
+			switch {
+			case x < y:
+				return -1, true
+			case x > y:
+				return 1, true
+			}
+			return 0, true
+		}
+	case primitive.ObjectID:
+		if y, ok := b.(primitive.ObjectID); ok {
+			return strings.Compare(x.Hex(), y.Hex()), true
+		}
+	}
+	return 0, false
+}
+
+// compareOrder orders any two values for sorting, missing values first
+func compareOrder(a, b interface{}) int {
+	if c, ok := compareValues(a, b); ok {
+		return c
+	}
+	switch {
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	return strings.Compare(fmt.Sprintf("%T", a), fmt.Sprintf("%T", b))
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func truthy(v interface{}) bool {
+	switch x := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return x
+	}
+	if n, ok := toFloat(v); ok {
+		return n != 0
+	}
+	return true
+}
+
+// evalExpr evaluates an aggregation expression against doc
+func evalExpr(doc bson.M, expr interface{}) (interface{}, error) {
+	switch e := expr.(type) {
+	case string:
+		if strings.HasPrefix(e, "$") {
+			value, _ := lookupPath(doc, e[1:])
+			return value, nil
+		}
+		return e, nil
+	case bson.M:
+		if len(e) != 1 || !isOperatorDocument(e) {
+			return e, nil
+		}
+		for op, arg := range e {
+			args, ok := arg.(bson.A)
+			if !ok {
+				args = bson.A{arg}
+			}
+			values := make([]interface{}, len(args))
+			for i := range args {
+				value, err := evalExpr(doc, args[i])
+				if err != nil {
+					return nil, err
+				}
+				values[i] = value
+			}
+			return applyExprOperator(op, values)
+		}
+	}
+	return expr, nil
+}
+
+func applyExprOperator(op string, values []interface{}) (interface{}, error) {
+	switch op {
+	case "$add", "$multiply":
+		total := 0.0
+		if op == "$multiply" {
+			total = 1
+		}
+		integer := true
+		for _, value := range values {
+			n, ok := toFloat(value)
+			if !ok {
+				return nil, nil
+			}
+			if _, isFloat := value.(float64); isFloat {
+				integer = false
+			}
+			if op == "$add" {
+				total += n
+			} else {
+				total *= n
+			}
+		}
+		if integer {
+			return int64(total), nil
+		}
+		return total, nil
+	case "$subtract":
+		if len(values) != 2 {
+			return nil, fmt.Errorf("$subtract necesita dos valores")
+		}
+		x, okX := toFloat(values[0])
+		y, okY := toFloat(values[1])
+		if !okX || !okY {
+			return nil, nil
+		}
+		return x - y, nil
+	case "$eq", "$ne", "$gt", "$gte", "$lt", "$lte":
+		if len(values) != 2 {
+			return nil, fmt.Errorf("%s necesita dos valores", op)
+		}
+		c := compareOrder(values[0], values[1])
+		switch op {
+		case "$eq":
+			return c == 0, nil
+		case "$ne":
+			return c != 0, nil
+		case "$gt":
+			return c > 0, nil
+		case "$gte":
+			return c >= 0, nil
+		case "$lt":
+			return c < 0, nil
+		}
+		return c <= 0, nil
+	}
+	return nil, fmt.Errorf("operador %s no soportado en memoria", op)
+}
+
+// applyUpdate applies the operators of a normalized update to doc
+func applyUpdate(doc bson.M, update bson.M, inserting bool) error {
+	if len(update) == 0 || !isOperatorDocument(update) {
+		return fmt.Errorf("la actualización necesita operadores como $set")
+	}
+	for op, arg := range update {
+		fields, ok := arg.(bson.M)
+		if !ok {
+			return fmt.Errorf("%s necesita un documento", op)
+		}
+		switch op {
+		case "$set":
+			for path, value := range fields {
+				setPath(doc, path, copyValue(value))
+			}
+		case "$setOnInsert":
+			if inserting {
+				for path, value := range fields {
+					setPath(doc, path, copyValue(value))
+				}
+			}
+		case "$unset":
+			for path := range fields {
+				unsetPath(doc, path)
+			}
+		case "$inc":
+			for path, delta := range fields {
+				current, _ := lookupPath(doc, path)
+				sum, err := addNumbers(current, delta)
+				if err != nil {
+					return fmt.Errorf("$inc de %s: %w", path, err)
+				}
+				setPath(doc, path, sum)
+			}
+		default:
+			return fmt.Errorf("operador %s no soportado en memoria", op)
+		}
+	}
+	return nil
+}
+
+// addNumbers adds like $inc: a missing field counts as zero, and integers stay
+// integers
+func addNumbers(current, delta interface{}) (interface{}, error) {
+	if current == nil {
+		current = int64(0)
+	}
+	x, okX := toFloat(current)
+	y, okY := toFloat(delta)
+	if !okX || !okY {
+		return nil, fmt.Errorf("no es un número")
+	}
+	_, floatX := current.(float64)
+	_, floatY := delta.(float64)
+	if floatX || floatY {
+		return x + y, nil
+	}
+	return int64(x) + int64(y), nil
+}
+
+// lookupPath returns the value at a dotted path, indexing arrays by position
+func lookupPath(doc bson.M, path string) (interface{}, bool) {
+	var current interface{} = doc
+	for _, part := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case bson.M:
+			value, ok := node[part]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case bson.A:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			current = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// setPath sets the value at a dotted path, creating the missing documents
+func setPath(doc bson.M, path string, value interface{}) {
+	parts := strings.Split(path, ".")
+	current := doc
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := current[part].(bson.M)
+		if !ok {
+			next = bson.M{}
+			current[part] = next
+		}
+		current = next
+	}
+	current[parts[len(parts)-1]] = value
+}
+
+func unsetPath(doc bson.M, path string) {
+	parts := strings.Split(path, ".")
+	current := doc
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := current[part].(bson.M)
+		if !ok {
+			return
+		}
+		current = next
+	}
+	delete(current, parts[len(parts)-1])
+}
@@ -0,0 +1,161 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// useMemoryDatabase points the global DataManagers at a fresh in-memory
+// database for the length of the test
+func useMemoryDatabase(t *testing.T) *Database {
+	t.Helper()
+	db := NewMemoryDatabase()
+	InitGlobalDataManagers(db)
+	t.Cleanup(func() { globalCacheManager.removePrefix("") })
+	return db
+}
+
+type memoryTestDoc struct {
+	ID     string         `bson:"_id"`
+	Guild  string         `bson:"guild"`
+	Score  int64          `bson:"score"`
+	Bank   int64          `bson:"bank"`
+	Tags   []string       `bson:"tags,omitempty"`
+	Items  map[string]int `bson:"items,omitempty"`
+	Seen   time.Time      `bson:"seen,omitempty"`
+	Hidden bool           `bson:"hidden,omitempty"`
+}
+
+func TestMemoryRepositoryQueries(t *testing.T) {
+	repo := newMemoryStore().collection("test")
+	ctx := context.Background()
+	now := time.Now()
+	docs := []memoryTestDoc{
+		{ID: "a", Guild: "g1", Score: 10, Bank: 5, Tags: []string{"x"}, Seen: now},
+		{ID: "b", Guild: "g1", Score: 30, Bank: 0, Hidden: true, Seen: now.Add(-time.Hour)},
+		{ID: "c", Guild: "g1", Score: 20, Bank: 50},
+		{ID: "d", Guild: "g2", Score: 40},
+	}
+	for _, doc := range docs {
+		if err := repo.InsertOne(ctx, doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := repo.InsertOne(ctx, docs[0]); !mongo.IsDuplicateKeyError(err) {
+		t.Errorf("Expected a duplicate key error, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		filter bson.M
+		want   int64
+	}{
+		{"equality", bson.M{"guild": "g1"}, 3},
+		{"range", bson.M{"score": bson.M{"$gt": 10, "$lte": 30}}, 2},
+		{"in", bson.M{"_id": bson.M{"$in": bson.A{"a", "d", "z"}}}, 2},
+		{"nin", bson.M{"_id": bson.M{"$nin": []string{"a"}}}, 3},
+		{"ne", bson.M{"guild": bson.M{"$ne": "g1"}}, 1},
+		{"exists", bson.M{"hidden": bson.M{"$exists": false}}, 3},
+		{"array element", bson.M{"tags": "x"}, 1},
+		{"missing is nil", bson.M{"tags": nil}, 3},
+		{"date", bson.M{"seen": bson.M{"$lt": now.Add(-time.Minute)}}, 1},
+		{"or", bson.M{"$or": bson.A{bson.M{"score": 10}, bson.M{"guild": "g2"}}}, 2},
+		{"and", bson.M{"$and": []bson.M{{"guild": "g1"}, {"score": bson.M{"$gte": 20}}}}, 2},
+		{"expr", bson.M{"$expr": bson.M{"$gt": bson.A{bson.M{"$add": bson.A{"$score", "$bank"}}, 60}}}, 1},
+	}
+	for _, tt := range tests {
+		got, err := repo.Count(ctx, tt.filter)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: Count() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	var page []*memoryTestDoc
+	err := repo.Find(ctx, bson.M{"guild": "g1"}, FindOptions{
+		Sort:  bson.D{{Key: "score", Value: -1}},
+		Skip:  1,
+		Limit: 1,
+	}, &page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 1 || page[0].ID != "c" {
+		t.Errorf("Expected the second highest score c, got %+v", page)
+	}
+
+	if _, err := repo.Count(ctx, bson.M{"$where": "true"}); err == nil {
+		t.Error("Expected an error for an unsupported operator")
+	}
+}
+
+func TestMemoryRepositoryUpdates(t *testing.T) {
+	repo := newMemoryStore().collection("test")
+	ctx := context.Background()
+
+	var doc memoryTestDoc
+	err := repo.FindOneAndUpdate(ctx, bson.M{"_id": "a"}, bson.M{
+		"$set":         bson.M{"guild": "g1"},
+		"$inc":         bson.M{"score": int64(5), "items.sword": 1},
+		"$setOnInsert": bson.M{"bank": int64(100)},
+	}, true, &doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.ID != "a" || doc.Score != 5 || doc.Bank != 100 || doc.Items["sword"] != 1 {
+		t.Errorf("Unexpected upserted document %+v", doc)
+	}
+
+	doc = memoryTestDoc{}
+	err = repo.FindOneAndUpdate(ctx, bson.M{"_id": "a"}, bson.M{
+		"$inc":         bson.M{"score": int64(-5)},
+		"$unset":       bson.M{"items": ""},
+		"$setOnInsert": bson.M{"bank": int64(0)},
+	}, true, &doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Score != 0 || doc.Bank != 100 || doc.Items != nil {
+		t.Errorf("Unexpected updated document %+v", doc)
+	}
+
+	// A guard that doesn't hold matches nothing and changes nothing
+	err = repo.FindOneAndUpdate(ctx, bson.M{"_id": "a", "score": bson.M{"$gte": 10}}, bson.M{"$inc": bson.M{"score": -10}}, false, &doc)
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("Expected no documents, got %v", err)
+	}
+	if err := repo.FindOneAndUpdate(ctx, bson.M{"_id": "a"}, bson.M{"$inc": bson.M{"guild": 1}}, false, &doc); err == nil {
+		t.Error("Expected an error incrementing a string")
+	}
+	if err := repo.FindOne(ctx, bson.M{"_id": "a"}, &doc); err != nil || doc.Guild != "g1" || doc.Score != 0 {
+		t.Errorf("Failed update changed the document: %+v, %v", doc, err)
+	}
+
+	if err := repo.UpsertMany(ctx, []Upsert{
+		{Filter: bson.M{"_id": "a"}, Update: bson.M{"$set": bson.M{"score": 7}}},
+		{Filter: bson.M{"_id": "b"}, Update: bson.M{"$set": bson.M{"score": 3, "guild": "g1"}}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if matched, err := repo.UpdateMany(ctx, bson.M{"guild": "g1"}, bson.M{"$inc": bson.M{"score": 1}}); err != nil || matched != 2 {
+		t.Errorf("UpdateMany() = %d, %v, want 2", matched, err)
+	}
+	deleted, err := repo.DeleteMany(ctx, bson.M{"guild": "g1", "score": bson.M{"$lt": 5}})
+	if err != nil || deleted != 1 {
+		t.Errorf("DeleteMany() = %d, %v, want 1", deleted, err)
+	}
+	if deleted, err := repo.DeleteOne(ctx, bson.M{"_id": "a"}); err != nil || deleted != 1 {
+		t.Fatalf("DeleteOne() = %d, %v, want 1", deleted, err)
+	}
+	if n, _ := repo.Count(ctx, bson.M{}); n != 0 {
+		t.Errorf("Expected an empty collection, got %d documents", n)
+	}
+}
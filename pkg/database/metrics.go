@@ -0,0 +1,72 @@
+package database
+
+import (
+	"strings"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/metrics"
+)
+
+var cacheRequests = metrics.NewCounter("pancybot_cache_requests_total",
+	"DataManager cache lookups, by collection and result (hit or miss).", "collection", "result")
+
+var cacheInvalidations = metrics.NewCounter("pancybot_cache_invalidations_received_total",
+	"Cache keys and collections invalidated by writes of other bot instances.")
+
+var writeQueueRejected = metrics.NewCounter("pancybot_db_write_queue_rejected_total",
+	"Offline writes lost because the queue was full.")
+
+var writeQueueCoalesced = metrics.NewCounter("pancybot_db_write_queue_coalesced_total",
+	"Offline writes that replaced a queued write to the same document.")
+
+var writeQueueReplays = metrics.NewCounter("pancybot_db_write_queue_replays_total",
+	"Offline writes replayed when the database came back, by result (applied, conflict or failed).", "result")
+
+func init() {
+	metrics.NewGaugeFunc("pancybot_cache_entries",
+		"Documents held in the shared DataManager cache, by collection.", []string{"collection"}, func(emit func(float64, ...string)) {
+			for collection, size := range cacheSizes() {
+				emit(float64(size), collection)
+			}
+		})
+	metrics.NewGaugeFunc("pancybot_db_connected",
+		"Whether the MongoDB connection is up.", nil, func(emit func(float64, ...string)) {
+			if db := Get(); db != nil {
+				connected := 0.0
+				if db.Connected() {
+					connected = 1
+				}
+				emit(connected)
+			}
+		})
+	metrics.NewGaugeFunc("pancybot_db_write_queue_length",
+		"Writes waiting in the offline queue for the database to come back.", nil, func(emit func(float64, ...string)) {
+			if db := Get(); db != nil {
+				emit(float64(db.WriteQueueLength()))
+			}
+		})
+	metrics.NewGaugeFunc("pancybot_db_write_queue_oldest_seconds",
+		"Age of the oldest write waiting in the offline queue.", nil, func(emit func(float64, ...string)) {
+			if db := Get(); db != nil {
+				if oldest, ok := db.writeQueue.oldest(); ok {
+					emit(time.Since(oldest).Seconds())
+				} else {
+					emit(0)
+				}
+			}
+		})
+}
+
+// cacheSizes counts the cached documents of every collection
+func cacheSizes() map[string]int {
+	globalCacheManager.mu.RLock()
+	defer globalCacheManager.mu.RUnlock()
+
+	sizes := make(map[string]int)
+	for key := range globalCacheManager.cache {
+		if collection, _, ok := strings.Cut(key, ":{"); ok {
+			sizes[collection]++
+		}
+	}
+	return sizes
+}
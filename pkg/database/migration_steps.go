@@ -0,0 +1,143 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// migrations are applied in this order. Never edit or remove an applied one;
+// add a new version instead.
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "Guarda net_worth en los perfiles de economía anteriores a los rankings",
+		Collections: []string{"economy_local", "economy_global"},
+		Up:          migrateNetWorth,
+	},
+	{
+		Version:     2,
+		Description: "Actualiza los servidores al esquema " + models.GuildSchemaVersion + " (antibots como documento, usersWithAccess)",
+		Collections: []string{"guilds"},
+		Up:          migrateGuildSchema2,
+	},
+}
+
+// indexes every collection needs. Missing ones are created after the migrations.
+var indexes = []index{
+	{collection: "guilds", keys: bson.D{{Key: "id", Value: 1}}},
+
+	{collection: "economy_local", keys: bson.D{{Key: "guild_id", Value: 1}}},
+	{collection: "economy_local", keys: bson.D{{Key: "user_id", Value: 1}}},
+	{collection: "economy_local", keys: bson.D{{Key: "guild_id", Value: 1}, {Key: "net_worth", Value: -1}}},
+	{collection: "economy_local", keys: bson.D{{Key: "guild_id", Value: 1}, {Key: "season_earnings", Value: -1}}},
+	{collection: "economy_global", keys: bson.D{{Key: "net_worth", Value: -1}}},
+	{collection: "economy_transactions", keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	{collection: "economy_transactions", keys: bson.D{{Key: "guild_id", Value: 1}, {Key: "created_at", Value: -1}}},
+
+	{collection: "levels", keys: bson.D{{Key: "user_id", Value: 1}}},
+	{collection: "levels", keys: bson.D{{Key: "guild_id", Value: 1}, {Key: "xp", Value: -1}}},
+	{collection: "levels", keys: bson.D{{Key: "guild_id", Value: 1}, {Key: "season", Value: 1}, {Key: "season_xp", Value: -1}}},
+
+	{collection: "warns", keys: bson.D{{Key: "guildId", Value: 1}, {Key: "userId", Value: 1}}},
+	{collection: "tempbans", keys: bson.D{{Key: "guildId", Value: 1}, {Key: "userId", Value: 1}}},
+	// The scheduler lifts expired bans every minute; the TTL only removes those
+	// left behind, so it waits long enough for a bot that was down to catch up
+	{collection: "tempbans", keys: bson.D{{Key: "expiresAt", Value: 1}}, ttl: 7 * 24 * time.Hour},
+
+	{collection: "casino_open_wagers", keys: bson.D{{Key: "expires_at", Value: 1}}},
+}
+
+// migrateNetWorth fills net_worth on profiles written before it was stored
+func migrateNetWorth(ctx context.Context, db *mongo.Database, dryRun bool) (int64, error) {
+	missing := bson.M{"net_worth": bson.M{"$exists": false}}
+	steps := []struct {
+		collection   string
+		wallet, bank string
+	}{
+		{"economy_local", "$wallet", "$bank"},
+		{"economy_global", "$stars_wallet", "$stars_bank"},
+	}
+
+	var total int64
+	for _, step := range steps {
+		col := db.Collection(step.collection)
+		if dryRun {
+			n, err := col.CountDocuments(ctx, missing)
+			if err != nil {
+				return total, err
+			}
+			total += n
+			continue
+		}
+		res, err := col.UpdateMany(ctx, missing, mongo.Pipeline{
+			{{Key: "$set", Value: bson.M{"net_worth": bson.M{"$add": bson.A{step.wallet, step.bank}}}}},
+		})
+		if err != nil {
+			return total, err
+		}
+		total += res.ModifiedCount
+	}
+	return total, nil
+}
+
+// migrateGuildSchema2 upgrades the guilds still in the TypeScript shapes, found
+// by configuration._version. Each step only touches old documents and the
+// version is set last, so an interrupted run is picked up by the next one.
+func migrateGuildSchema2(ctx context.Context, db *mongo.Database, dryRun bool) (int64, error) {
+	col := db.Collection("guilds")
+	legacy := bson.M{"configuration._version": bson.M{"$in": bson.A{nil, "", "1.0.0"}}}
+	if dryRun {
+		return col.CountDocuments(ctx, legacy)
+	}
+
+	with := func(filter bson.M) bson.M {
+		merged := bson.M{}
+		for k, v := range legacy {
+			merged[k] = v
+		}
+		for k, v := range filter {
+			merged[k] = v
+		}
+		return merged
+	}
+
+	// antibots was saved as "enable"/"disable" before it had a type
+	if _, err := col.UpdateMany(ctx, with(bson.M{"protection.antibots": bson.M{"$type": "string"}}), mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{"protection.antibots": bson.M{
+			"enable": bson.M{"$in": bson.A{"$protection.antibots", bson.A{"enable", "true"}}},
+			"_type":  "",
+		}}}},
+	}); err != nil {
+		return 0, err
+	}
+
+	// A guild saved before the migration already has the new key, read from the
+	// old one (see models.PasswordConfig), and it must not be overwritten
+	for _, field := range []string{"configuration.password", "protection.antijoins.password"} {
+		oldKey, newKey := field+".usersWithAcces", field+".usersWithAccess"
+		if _, err := col.UpdateMany(ctx, with(bson.M{oldKey: bson.M{"$exists": true}, newKey: bson.M{"$exists": false}}), bson.M{
+			"$rename": bson.M{oldKey: newKey},
+		}); err != nil {
+			return 0, err
+		}
+		if _, err := col.UpdateMany(ctx, with(bson.M{oldKey: bson.M{"$exists": true}}), bson.M{
+			"$unset": bson.M{oldKey: ""},
+		}); err != nil {
+			return 0, err
+		}
+	}
+
+	// The version bump makes queued offline writes based on the old document conflict
+	res, err := col.UpdateMany(ctx, legacy, bson.M{
+		"$set": bson.M{"configuration._version": models.GuildSchemaVersion},
+		"$inc": bson.M{"version": 1},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
@@ -0,0 +1,278 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var ErrMigrationsUnavailable = errors.New("migrations need the database online")
+
+// migrationsCollection records the applied migrations, one document per version
+const migrationsCollection = "_migrations"
+
+// migrationLockTimeout is how long a migration claimed by an instance may run
+// before another instance takes it over, assuming the first one died
+const migrationLockTimeout = 15 * time.Minute
+
+// Migration is a change to the stored documents. Migrations run once, in
+// version order, and are recorded in the _migrations collection.
+type Migration struct {
+	Version     int
+	Description string
+	Collections []string // Collections it writes, whose caches are cleared afterwards
+	// Up applies the migration and returns how many documents it changed. On a
+	// dry run it changes nothing and returns how many documents it would change.
+	Up func(ctx context.Context, db *mongo.Database, dryRun bool) (int64, error)
+}
+
+// MigrationRecord is a migration in the _migrations collection
+type MigrationRecord struct {
+	Version     int       `bson:"_id" json:"version"`
+	Description string    `bson:"description" json:"description"`
+	State       string    `bson:"state" json:"state"` // "running" or "done"
+	Instance    string    `bson:"instance" json:"instance"`
+	Documents   int64     `bson:"documents" json:"documents"`
+	StartedAt   time.Time `bson:"startedAt" json:"startedAt"`
+	AppliedAt   time.Time `bson:"appliedAt,omitempty" json:"appliedAt,omitempty"`
+}
+
+// MigrateOptions tunes Migrate
+type MigrateOptions struct {
+	DryRun   bool   // Report what would change without writing
+	Instance string // Recorded with the migrations it applies, for the logs
+}
+
+// MigrationResult is a migration applied, or pending on a dry run
+type MigrationResult struct {
+	Version     int
+	Description string
+	Documents   int64
+}
+
+// MigrateReport is the outcome of Migrate
+type MigrateReport struct {
+	Migrations []MigrationResult
+	Indexes    []string // Indexes created, or missing on a dry run, as collection.name
+}
+
+// Migrate applies the pending migrations in order and then creates the missing
+// indexes. When another instance is applying a migration, Migrate stops there
+// and leaves the rest for the next start, so the order is kept.
+func Migrate(ctx context.Context, d *Database, opts MigrateOptions) (*MigrateReport, error) {
+	if d != nil && d.memory != nil {
+		// Documents in memory are all written by this version
+		return &MigrateReport{}, nil
+	}
+	if d == nil || !d.Connected() {
+		return nil, ErrMigrationsUnavailable
+	}
+	db := d.DB()
+	if db == nil {
+		return nil, ErrMigrationsUnavailable
+	}
+
+	applied, err := appliedMigrations(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &MigrateReport{}
+	for _, m := range migrations {
+		if record, ok := applied[m.Version]; ok && record.State == "done" {
+			continue
+		}
+
+		if opts.DryRun {
+			n, err := m.Up(ctx, db, true)
+			if err != nil {
+				return report, fmt.Errorf("migración %d: %w", m.Version, err)
+			}
+			report.Migrations = append(report.Migrations, MigrationResult{m.Version, m.Description, n})
+			continue
+		}
+
+		claimed, err := claimMigration(ctx, db, m, opts.Instance)
+		if err != nil {
+			return report, fmt.Errorf("migración %d: %w", m.Version, err)
+		}
+		if !claimed {
+			logger.Warn(fmt.Sprintf("La migración %d la está aplicando otra instancia, las siguientes quedan para el próximo arranque", m.Version), "DB-Migrate")
+			return report, nil
+		}
+
+		logger.System(fmt.Sprintf("Aplicando migración %d: %s", m.Version, m.Description), "DB-Migrate")
+		n, err := m.Up(ctx, db, false)
+		if err != nil {
+			// Release the claim so the migration is retried
+			_, _ = db.Collection(migrationsCollection).DeleteOne(context.Background(), bson.M{"_id": m.Version, "state": "running"})
+			return report, fmt.Errorf("migración %d: %w", m.Version, err)
+		}
+		if _, err := db.Collection(migrationsCollection).UpdateOne(ctx, bson.M{"_id": m.Version}, bson.M{"$set": bson.M{
+			"state":     "done",
+			"documents": n,
+			"appliedAt": time.Now(),
+		}}); err != nil {
+			return report, fmt.Errorf("migración %d aplicada pero no registrada: %w", m.Version, err)
+		}
+		if n > 0 {
+			for _, collection := range m.Collections {
+				globalCacheManager.removePrefix(collection + ":")
+				publishCollectionClear(collection + ":")
+			}
+		}
+		logger.Success(fmt.Sprintf("Migración %d aplicada (%d documentos)", m.Version, n), "DB-Migrate")
+		report.Migrations = append(report.Migrations, MigrationResult{m.Version, m.Description, n})
+	}
+
+	report.Indexes, err = ensureIndexes(ctx, db, opts.DryRun)
+	return report, err
+}
+
+// Migrations returns the known migrations with their state in the database,
+// "pending" for those not applied yet
+func Migrations(ctx context.Context, d *Database) ([]MigrationRecord, error) {
+	if d == nil || !d.Connected() || d.DB() == nil {
+		return nil, ErrMigrationsUnavailable
+	}
+	applied, err := appliedMigrations(ctx, d.DB())
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]MigrationRecord, len(migrations))
+	for i, m := range migrations {
+		record, ok := applied[m.Version]
+		if !ok {
+			record = MigrationRecord{Version: m.Version, State: "pending"}
+		}
+		record.Description = m.Description
+		records[i] = record
+	}
+	return records, nil
+}
+
+func appliedMigrations(ctx context.Context, db *mongo.Database) (map[int]MigrationRecord, error) {
+	cursor, err := db.Collection(migrationsCollection).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	var records []MigrationRecord
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+	applied := make(map[int]MigrationRecord, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// claimMigration records a migration as running for this instance. It fails to
+// claim it when another instance has it running and has not timed out.
+func claimMigration(ctx context.Context, db *mongo.Database, m Migration, instance string) (bool, error) {
+	col := db.Collection(migrationsCollection)
+	now := time.Now()
+	record := MigrationRecord{
+		Version:     m.Version,
+		Description: m.Description,
+		State:       "running",
+		Instance:    instance,
+		StartedAt:   now,
+	}
+
+	_, err := col.InsertOne(ctx, record)
+	if err == nil {
+		return true, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return false, err
+	}
+
+	// Take over a claim left by an instance that died mid-migration
+	res, err := col.ReplaceOne(ctx, bson.M{
+		"_id":       m.Version,
+		"state":     "running",
+		"startedAt": bson.M{"$lt": now.Add(-migrationLockTimeout)},
+	}, record)
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount > 0, nil
+}
+
+// index is an index a collection needs
+type index struct {
+	collection string
+	keys       bson.D
+	ttl        time.Duration // Documents are deleted this long after the date in the only key
+}
+
+// name is the name MongoDB gives the index by default, so indexes created
+// before they were listed here are recognized
+func (i index) name() string {
+	parts := make([]string, 0, len(i.keys)*2)
+	for _, key := range i.keys {
+		parts = append(parts, key.Key, fmt.Sprint(key.Value))
+	}
+	return strings.Join(parts, "_")
+}
+
+// ensureIndexes creates the indexes that are missing and returns them
+func ensureIndexes(ctx context.Context, db *mongo.Database, dryRun bool) ([]string, error) {
+	existing := make(map[string]map[string]bool)
+	var created []string
+	var errs []error
+	for _, idx := range indexes {
+		names, ok := existing[idx.collection]
+		if !ok {
+			var err error
+			if names, err = indexNames(ctx, db.Collection(idx.collection)); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", idx.collection, err))
+				continue
+			}
+			existing[idx.collection] = names
+		}
+		if names[idx.name()] {
+			continue
+		}
+
+		if !dryRun {
+			opts := options.Index().SetName(idx.name())
+			if idx.ttl > 0 {
+				opts.SetExpireAfterSeconds(int32(idx.ttl.Seconds()))
+			}
+			if _, err := db.Collection(idx.collection).Indexes().CreateOne(ctx, mongo.IndexModel{Keys: idx.keys, Options: opts}); err != nil {
+				errs = append(errs, fmt.Errorf("%s.%s: %w", idx.collection, idx.name(), err))
+				continue
+			}
+		}
+		created = append(created, idx.collection+"."+idx.name())
+	}
+	return created, errors.Join(errs...)
+}
+
+func indexNames(ctx context.Context, col *mongo.Collection) (map[string]bool, error) {
+	cursor, err := col.Indexes().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var specs []struct {
+		Name string `bson:"name"`
+	}
+	if err := cursor.All(ctx, &specs); err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		names[spec.Name] = true
+	}
+	return names, nil
+}
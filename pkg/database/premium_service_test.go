@@ -0,0 +1,41 @@
+package database
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+)
+
+func TestPremiumCodesWithMemoryDatabase(t *testing.T) {
+	useMemoryDatabase(t)
+
+	if ok, _, err := IsUserPremium("alice"); err != nil || ok {
+		t.Fatalf("IsUserPremium() = %v, %v before redeeming", ok, err)
+	}
+	if _, err := CreatePremiumCode("CODE-1", models.PremiumCodeTypeUser, 30, false, "dev"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RedeemPremiumCode("CODE-1", "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RedeemPremiumCode("CODE-1", "bob"); !errors.Is(err, ErrCodeAlreadyClaimed) {
+		t.Errorf("Expected the code to be claimed, got %v", err)
+	}
+
+	ok, record, err := IsUserPremium("alice")
+	if err != nil || !ok {
+		t.Fatalf("IsUserPremium() = %v, %v after redeeming", ok, err)
+	}
+	if remaining := time.Duration(record.ExpiresAt-nowMillis()) * time.Millisecond; remaining < 29*24*time.Hour {
+		t.Errorf("Expected about 30 days of premium, got %v", remaining)
+	}
+
+	if err := RemoveUserPremium("alice"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _, err := IsUserPremium("alice"); err != nil || ok {
+		t.Errorf("IsUserPremium() = %v, %v after removing", ok, err)
+	}
+}
@@ -0,0 +1,249 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// UserDataExport is everything stored about a user, across every server
+type UserDataExport struct {
+	UserID         string                        `json:"user_id"`
+	ExportedAt     time.Time                     `json:"exported_at"`
+	GlobalEconomy  *models.GlobalEconomyProfile  `json:"global_economy,omitempty"`
+	LocalEconomy   []*models.LocalEconomyProfile `json:"local_economy"`
+	Levels         []*models.UserLevelProfile    `json:"levels"`
+	Warns          []*models.WarnsDocument       `json:"warns"`
+	Premium        *models.UserPremium           `json:"premium,omitempty"`
+	PremiumCodes   []*models.PremiumCode         `json:"premium_codes"`
+	Blacklist      *models.Blacklist             `json:"blacklist,omitempty"`
+	Transactions   []*models.Transaction         `json:"transactions"`
+	Invites        []*models.InviteStats         `json:"invites"`
+	InviteJoins    []*models.InviteJoin          `json:"invite_joins"`
+	MarketListings []*models.MarketListing       `json:"market_listings"`
+	TimedRoles     []*models.TimedRole           `json:"timed_roles"`
+	Verifications  []*models.PendingVerification `json:"verifications"`
+}
+
+// DeletionReport is how many documents a deletion removed, by collection
+type DeletionReport map[string]int64
+
+// Total returns how many documents were removed
+func (r DeletionReport) Total() int64 {
+	var total int64
+	for _, n := range r {
+		total += n
+	}
+	return total
+}
+
+// dataScope is the documents of a user or a guild in one collection
+type dataScope struct {
+	collection string
+	filter     bson.M
+	delete     func(filter bson.M) (int64, error)
+}
+
+func scopeOf[T any](dm *DataManager[T], filter bson.M) dataScope {
+	return dataScope{collection: dm.collectionName, filter: filter, delete: dm.deleteWhere}
+}
+
+// findAll returns the documents matching filter straight from the storage
+func findAll[T any](dm *DataManager[T], filter bson.M, sort bson.D) ([]*T, error) {
+	if dm == nil || !dm.available() {
+		return nil, ErrDatabaseOffline
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	results := []*T{}
+	if err := dm.repo.Find(ctx, filter, FindOptions{Sort: sort}, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// ExportUserData gathers the documents of a user in every collection
+func ExportUserData(userID string) (*UserDataExport, error) {
+	if GlobalEconomyDM == nil || !GlobalEconomyDM.available() {
+		return nil, ErrDatabaseOffline
+	}
+
+	export := &UserDataExport{UserID: userID, ExportedAt: time.Now()}
+	var err error
+	var errs []error
+	collect := func(e error) {
+		if e != nil {
+			errs = append(errs, e)
+		}
+	}
+
+	export.GlobalEconomy, err = GlobalEconomyDM.Get(bson.M{"_id": userID})
+	collect(err)
+	export.Premium, err = GlobalUserPremiumDM.Get(bson.M{"user": userID})
+	collect(err)
+	export.Blacklist, err = GlobalBlacklistDM.Get(bson.M{"_id": userID, "type": models.BlacklistTypeUser})
+	collect(err)
+
+	export.LocalEconomy, err = findAll(LocalEconomyDM, bson.M{"user_id": userID}, nil)
+	collect(err)
+	export.Levels, err = findAll(LocalLevelsDM, bson.M{"user_id": userID}, nil)
+	collect(err)
+	export.Warns, err = findAll(GlobalWarnDM, bson.M{"userId": userID}, nil)
+	collect(err)
+	export.PremiumCodes, err = findAll(GlobalPremiumCodeDM, bson.M{"claimed_by": userID}, nil)
+	collect(err)
+	export.Transactions, err = findAll(TransactionsDM, bson.M{"user_id": userID}, bson.D{{Key: "created_at", Value: -1}})
+	collect(err)
+	export.Invites, err = findAll(InviteStatsDM, bson.M{"user_id": userID}, nil)
+	collect(err)
+	export.InviteJoins, err = findAll(InviteJoinsDM, bson.M{"member_id": userID}, nil)
+	collect(err)
+	export.MarketListings, err = findAll(MarketListingsDM, bson.M{"$or": bson.A{
+		bson.M{"seller_id": userID},
+		bson.M{"buyer_id": userID},
+	}}, bson.D{{Key: "created_at", Value: -1}})
+	collect(err)
+	export.TimedRoles, err = findAll(TimedRolesDM, bson.M{"user_id": userID}, nil)
+	collect(err)
+	export.Verifications, err = findAll(VerificationDM, bson.M{"user_id": userID}, nil)
+	collect(err)
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+// userScopes are the documents removed when a user deletes their data. Warns
+// and blacklist entries are moderation records of the servers and the bot and
+// are kept, like the premium codes the user redeemed. Open marketplace
+// listings are kept until they settle, since they hold items and bids.
+func userScopes(userID string) []dataScope {
+	return []dataScope{
+		scopeOf(GlobalEconomyDM, bson.M{"_id": userID}),
+		scopeOf(LocalEconomyDM, bson.M{"user_id": userID}),
+		scopeOf(LocalLevelsDM, bson.M{"user_id": userID}),
+		scopeOf(GlobalUserPremiumDM, bson.M{"user": userID}),
+		scopeOf(TransactionsDM, bson.M{"user_id": userID}),
+		scopeOf(InviteStatsDM, bson.M{"user_id": userID}),
+		scopeOf(InviteJoinsDM, bson.M{"member_id": userID}),
+		scopeOf(MarketListingsDM, bson.M{"seller_id": userID, "status": bson.M{"$nin": bson.A{models.ListingActive, models.ListingSettling}}}),
+		scopeOf(TimedRolesDM, bson.M{"user_id": userID}),
+		scopeOf(VerificationDM, bson.M{"user_id": userID}),
+	}
+}
+
+// guildScopes are the documents removed when a guild's data is deleted. The
+// blacklist entry of the guild, if any, is kept, and its pending purge is
+// removed by purgeGuild once everything else is gone.
+func guildScopes(guildID string) []dataScope {
+	return []dataScope{
+		scopeOf(GlobalGuildDM, bson.M{"id": guildID}),
+		scopeOf(GlobalMusicDM, bson.M{"_id": guildID}),
+		scopeOf(GlobalGuildPremiumDM, bson.M{"guild": guildID}),
+		scopeOf(LocalEconomyDM, bson.M{"guild_id": guildID}),
+		scopeOf(ItemDM, bson.M{"guild_id": guildID}),
+		scopeOf(LocalLevelsDM, bson.M{"guild_id": guildID}),
+		scopeOf(GlobalWarnDM, bson.M{"guildId": guildID}),
+		scopeOf(InviteStatsDM, bson.M{"guild_id": guildID}),
+		scopeOf(InviteJoinsDM, bson.M{"guild_id": guildID}),
+		scopeOf(TransactionsDM, bson.M{"guild_id": guildID}),
+		scopeOf(MarketListingsDM, bson.M{"guild_id": guildID}),
+		scopeOf(TimedRolesDM, bson.M{"guild_id": guildID}),
+		scopeOf(SeasonsDM, bson.M{"guild_id": guildID}),
+		scopeOf(VerificationDM, bson.M{"guild_id": guildID}),
+		{collection: "tempbans", filter: bson.M{"guildId": guildID}, delete: deleteTempBans},
+	}
+}
+
+// deleteTempBans removes tempbans, which have no DataManager
+func deleteTempBans(filter bson.M) (int64, error) {
+	repo, err := tempBans()
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return repo.DeleteMany(ctx, filter)
+}
+
+// deleteScopes removes every scope, going on after a failure so as much as
+// possible is deleted
+func deleteScopes(scopes []dataScope) (DeletionReport, error) {
+	report := make(DeletionReport, len(scopes))
+	var errs []error
+	for _, scope := range scopes {
+		deleted, err := scope.delete(scope.filter)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", scope.collection, err))
+		}
+		if deleted > 0 {
+			report[scope.collection] += deleted
+		}
+	}
+	return report, errors.Join(errs...)
+}
+
+// DeleteUserData removes the documents of a user in every server, see userScopes
+func DeleteUserData(userID string) (DeletionReport, error) {
+	if GlobalEconomyDM == nil || !GlobalEconomyDM.available() {
+		return nil, ErrDatabaseOffline
+	}
+	return deleteScopes(userScopes(userID))
+}
+
+// DeleteGuildData removes the configuration of a guild and every document of
+// its members in it, see guildScopes
+func DeleteGuildData(guildID string) (DeletionReport, error) {
+	if GlobalGuildDM == nil || !GlobalGuildDM.available() {
+		return nil, ErrDatabaseOffline
+	}
+	return purgeGuild(guildID, guildScopes(guildID))
+}
+
+// purgeGuild deletes the scopes of a guild and then its pending purge. The purge
+// is kept when a scope failed, so the scheduler tries the deletion again.
+func purgeGuild(guildID string, scopes []dataScope) (DeletionReport, error) {
+	report, err := deleteScopes(scopes)
+	if err != nil {
+		return report, err
+	}
+	deleted, err := GuildPurgesDM.deleteWhere(bson.M{"_id": guildID})
+	if deleted > 0 {
+		report[GuildPurgesDM.collectionName] += deleted
+	}
+	return report, err
+}
+
+// ScheduleGuildPurge marks the data of a guild the bot left to be deleted
+// after the retention period
+func ScheduleGuildPurge(guildID string, retention time.Duration) error {
+	if GuildPurgesDM == nil {
+		return ErrDatabaseOffline
+	}
+	now := time.Now()
+	_, err := GuildPurgesDM.Set(bson.M{"_id": guildID}, models.GuildPurge{
+		GuildID: guildID,
+		LeftAt:  now,
+		PurgeAt: now.Add(retention),
+	})
+	return err
+}
+
+// CancelGuildPurge keeps the data of a guild the bot was added back to
+func CancelGuildPurge(guildID string) error {
+	if GuildPurgesDM == nil {
+		return ErrDatabaseOffline
+	}
+	return GuildPurgesDM.Delete(bson.M{"_id": guildID})
+}
+
+// GetDueGuildPurges returns the purges whose retention ended
+func GetDueGuildPurges(now time.Time) ([]*models.GuildPurge, error) {
+	return findAll(GuildPurgesDM, bson.M{"purge_at": bson.M{"$lte": now}}, bson.D{{Key: "purge_at", Value: 1}})
+}
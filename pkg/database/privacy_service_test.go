@@ -0,0 +1,151 @@
+package database
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// seedPrivacyData stores alice and bob in two guilds
+func seedPrivacyData(t *testing.T) {
+	t.Helper()
+	for _, user := range []string{"alice", "bob"} {
+		if _, err := GlobalEconomyDM.Set(bson.M{"_id": user}, models.GlobalEconomyProfile{UserID: user, StarsWallet: 10}); err != nil {
+			t.Fatal(err)
+		}
+		for _, guild := range []string{"g1", "g2"} {
+			id := guild + "_" + user
+			if _, err := LocalEconomyDM.Set(bson.M{"_id": id}, models.LocalEconomyProfile{ID: id, GuildID: guild, UserID: user, Wallet: 5}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LocalLevelsDM.Set(bson.M{"_id": id}, models.UserLevelProfile{ID: id, GuildID: guild, UserID: user, XP: 100}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if _, err := GlobalWarnDM.Set(bson.M{"guildId": "g1", "userId": "alice"}, models.WarnsDocument{GuildID: "g1", UserID: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func countDocs[T any](t *testing.T, dm *DataManager[T], filter bson.M) int {
+	t.Helper()
+	docs, err := findAll(dm, filter, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(docs)
+}
+
+func TestExportAndDeleteUserData(t *testing.T) {
+	useMemoryDatabase(t)
+	seedPrivacyData(t)
+
+	export, err := ExportUserData("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if export.GlobalEconomy == nil || len(export.LocalEconomy) != 2 || len(export.Levels) != 2 || len(export.Warns) != 1 {
+		t.Errorf("Unexpected export %+v", export)
+	}
+	for _, profile := range export.LocalEconomy {
+		if profile.UserID != "alice" {
+			t.Errorf("Export of alice has the profile of %s", profile.UserID)
+		}
+	}
+
+	report, err := DeleteUserData("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Total() != 5 {
+		t.Errorf("Expected 5 deleted documents, got %v", report)
+	}
+	if n := countDocs(t, LocalEconomyDM, bson.M{"user_id": "alice"}); n != 0 {
+		t.Errorf("Expected no profiles left for alice, got %d", n)
+	}
+	if n := countDocs(t, GlobalWarnDM, bson.M{"userId": "alice"}); n != 1 {
+		t.Errorf("Expected the warns of alice to be kept, got %d", n)
+	}
+	if n := countDocs(t, LocalLevelsDM, bson.M{"user_id": "bob"}); n != 2 {
+		t.Errorf("Expected the levels of bob to be kept, got %d", n)
+	}
+}
+
+func TestGuildPurge(t *testing.T) {
+	useMemoryDatabase(t)
+	seedPrivacyData(t)
+
+	if err := ScheduleGuildPurge("g1", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if due, err := GetDueGuildPurges(time.Now()); err != nil || len(due) != 0 {
+		t.Fatalf("GetDueGuildPurges() = %v, %v before the retention ends", due, err)
+	}
+	due, err := GetDueGuildPurges(time.Now().Add(2 * time.Hour))
+	if err != nil || len(due) != 1 || due[0].GuildID != "g1" {
+		t.Fatalf("GetDueGuildPurges() = %v, %v after the retention ends", due, err)
+	}
+
+	if err := CancelGuildPurge("g1"); err != nil {
+		t.Fatal(err)
+	}
+	if due, _ := GetDueGuildPurges(time.Now().Add(2 * time.Hour)); len(due) != 0 {
+		t.Errorf("Expected the purge to be cancelled, got %v", due)
+	}
+
+	if err := ScheduleGuildPurge("g1", 0); err != nil {
+		t.Fatal(err)
+	}
+	report, err := DeleteGuildData("g1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report["guild_purges"] != 1 {
+		t.Errorf("Expected the purge to be removed with the data, got %v", report)
+	}
+	if n := countDocs(t, LocalEconomyDM, bson.M{"guild_id": "g1"}); n != 0 {
+		t.Errorf("Expected no profiles left in g1, got %d", n)
+	}
+	if n := countDocs(t, GlobalWarnDM, bson.M{"guildId": "g1"}); n != 0 {
+		t.Errorf("Expected no warns left in g1, got %d", n)
+	}
+	if n := countDocs(t, LocalLevelsDM, bson.M{"guild_id": "g2"}); n != 2 {
+		t.Errorf("Expected the levels of g2 to be kept, got %d", n)
+	}
+}
+
+func TestFailedGuildPurgeIsRetried(t *testing.T) {
+	useMemoryDatabase(t)
+	seedPrivacyData(t)
+
+	if err := ScheduleGuildPurge("g1", 0); err != nil {
+		t.Fatal(err)
+	}
+	failing := dataScope{collection: "broken", filter: bson.M{}, delete: func(bson.M) (int64, error) {
+		return 0, errors.New("timeout")
+	}}
+	scopes := append([]dataScope{failing}, guildScopes("g1")...)
+
+	report, err := purgeGuild("g1", scopes)
+	if err == nil {
+		t.Fatal("Expected the failing scope to be reported")
+	}
+	if report["economy_local"] != 2 {
+		t.Errorf("Expected the other scopes to be deleted anyway, got %v", report)
+	}
+	due, err := GetDueGuildPurges(time.Now())
+	if err != nil || len(due) != 1 {
+		t.Errorf("Expected the purge to be kept for a retry, got %v, %v", due, err)
+	}
+
+	if _, err := DeleteGuildData("g1"); err != nil {
+		t.Fatal(err)
+	}
+	if due, _ := GetDueGuildPurges(time.Now()); len(due) != 0 {
+		t.Errorf("Expected the purge to be removed after a full deletion, got %v", due)
+	}
+}
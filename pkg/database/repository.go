@@ -0,0 +1,232 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Repository stores the documents of one collection. The DataManager caches
+// on top of it; MongoDB backs it in production and memory in tests and in the
+// development mode without a database. Filters and updates are the bson.M
+// documents MongoDB takes.
+type Repository interface {
+	// Available reports whether operations can reach the storage
+	Available() bool
+	// FindOne decodes the first match into result, or returns mongo.ErrNoDocuments
+	FindOne(ctx context.Context, filter bson.M, result interface{}) error
+	// Find decodes the matches into results, a pointer to a slice. Documents that
+	// fail to decode are skipped.
+	Find(ctx context.Context, filter bson.M, opts FindOptions, results interface{}) error
+	Count(ctx context.Context, filter bson.M) (int64, error)
+	// FindOneAndUpdate updates the first match and decodes it, as it is after the
+	// update, into result. With upsert it creates the document when nothing
+	// matches; without it, it returns mongo.ErrNoDocuments.
+	FindOneAndUpdate(ctx context.Context, filter, update bson.M, upsert bool, result interface{}) error
+	InsertOne(ctx context.Context, doc interface{}) error
+	// UpdateMany applies update to every match and returns how many matched
+	UpdateMany(ctx context.Context, filter, update bson.M) (int64, error)
+	// DeleteOne removes the first match and returns how many documents it removed
+	DeleteOne(ctx context.Context, filter bson.M) (int64, error)
+	DeleteMany(ctx context.Context, filter bson.M) (int64, error)
+	// UpsertMany applies the updates, creating the documents that don't exist
+	UpsertMany(ctx context.Context, upserts []Upsert) error
+}
+
+// FindOptions sorts and pages the results of Find
+type FindOptions struct {
+	Sort  bson.D
+	Skip  int64
+	Limit int64 // Zero for no limit
+}
+
+// Upsert is one update of UpsertMany
+type Upsert struct {
+	Filter bson.M
+	Update bson.M
+}
+
+// mongoRepository is a Repository on a MongoDB collection
+type mongoRepository struct {
+	db   *Database
+	name string
+}
+
+// collection returns the collection while the database is connected
+func (r *mongoRepository) collection() *mongo.Collection {
+	if !r.db.Connected() {
+		return nil
+	}
+	return r.db.GetCollection(r.name)
+}
+
+func (r *mongoRepository) Available() bool {
+	return r.collection() != nil
+}
+
+func (r *mongoRepository) FindOne(ctx context.Context, filter bson.M, result interface{}) error {
+	col := r.collection()
+	if col == nil {
+		return ErrDatabaseOffline
+	}
+	return col.FindOne(ctx, filter).Decode(result)
+}
+
+func (r *mongoRepository) Find(ctx context.Context, filter bson.M, opts FindOptions, results interface{}) error {
+	col := r.collection()
+	if col == nil {
+		return ErrDatabaseOffline
+	}
+
+	findOpts := options.Find()
+	if opts.Sort != nil {
+		findOpts.SetSort(opts.Sort)
+	}
+	if opts.Skip > 0 {
+		findOpts.SetSkip(opts.Skip)
+	}
+	if opts.Limit > 0 {
+		findOpts.SetLimit(opts.Limit)
+	}
+	cursor, err := col.Find(ctx, filter, findOpts)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = cursor.Close(ctx) }()
+
+	return decodeEach(results, r.name, func(next func(func(interface{}) error) error) error {
+		for cursor.Next(ctx) {
+			if err := next(cursor.Decode); err != nil {
+				return err
+			}
+		}
+		return cursor.Err()
+	})
+}
+
+func (r *mongoRepository) Count(ctx context.Context, filter bson.M) (int64, error) {
+	col := r.collection()
+	if col == nil {
+		return 0, ErrDatabaseOffline
+	}
+	return col.CountDocuments(ctx, filter)
+}
+
+func (r *mongoRepository) FindOneAndUpdate(ctx context.Context, filter, update bson.M, upsert bool, result interface{}) error {
+	col := r.collection()
+	if col == nil {
+		return ErrDatabaseOffline
+	}
+	opts := options.FindOneAndUpdate().
+		SetUpsert(upsert).
+		SetReturnDocument(options.After)
+	return col.FindOneAndUpdate(ctx, filter, update, opts).Decode(result)
+}
+
+func (r *mongoRepository) InsertOne(ctx context.Context, doc interface{}) error {
+	col := r.collection()
+	if col == nil {
+		return ErrDatabaseOffline
+	}
+	_, err := col.InsertOne(ctx, doc)
+	return err
+}
+
+func (r *mongoRepository) UpdateMany(ctx context.Context, filter, update bson.M) (int64, error) {
+	col := r.collection()
+	if col == nil {
+		return 0, ErrDatabaseOffline
+	}
+	res, err := col.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return res.MatchedCount, nil
+}
+
+func (r *mongoRepository) DeleteOne(ctx context.Context, filter bson.M) (int64, error) {
+	col := r.collection()
+	if col == nil {
+		return 0, ErrDatabaseOffline
+	}
+	res, err := col.DeleteOne(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
+func (r *mongoRepository) DeleteMany(ctx context.Context, filter bson.M) (int64, error) {
+	col := r.collection()
+	if col == nil {
+		return 0, ErrDatabaseOffline
+	}
+	res, err := col.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
+func (r *mongoRepository) UpsertMany(ctx context.Context, upserts []Upsert) error {
+	col := r.collection()
+	if col == nil {
+		return ErrDatabaseOffline
+	}
+	if len(upserts) == 0 {
+		return nil
+	}
+	writes := make([]mongo.WriteModel, len(upserts))
+	for i, u := range upserts {
+		writes[i] = mongo.NewUpdateOneModel().SetFilter(u.Filter).SetUpdate(u.Update).SetUpsert(true)
+	}
+	_, err := col.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+// decodeEach appends to results, a pointer to a slice of T or *T, a document
+// per call of next by each. Documents that fail to decode are logged and skipped.
+func decodeEach(results interface{}, collection string, each func(next func(decode func(interface{}) error) error) error) error {
+	slice := reflect.ValueOf(results)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("results must be a pointer to a slice, got %T", results)
+	}
+	slice = slice.Elem()
+	elemType := slice.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+
+	return each(func(decode func(interface{}) error) error {
+		elem := reflect.New(elemType)
+		if err := decode(elem.Interface()); err != nil {
+			logger.Warn(fmt.Sprintf("Error decoding document of '%s': %v", collection, err), "DataManager")
+			return nil
+		}
+		if !isPtr {
+			elem = elem.Elem()
+		}
+		slice.Set(reflect.Append(slice, elem))
+		return nil
+	})
+}
+
+// bsonDocument converts a filter, update or document to a bson.M with the
+// types MongoDB would store, like primitive.DateTime for a time.Time
+func bsonDocument(v interface{}) (bson.M, error) {
+	raw, err := bson.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc bson.M
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
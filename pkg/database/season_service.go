@@ -0,0 +1,167 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Season boards
+const (
+	SeasonBoardLevels  = "levels"
+	SeasonBoardEconomy = "economy"
+)
+
+// seasonBoard returns the storage, the filter and the sorted field of a season
+// board. Level profiles keep the season their XP belongs to, so stale profiles of
+// older seasons are left out; economy profiles are reset when a season starts.
+func seasonBoard(board, guildID string, season int) (Repository, bson.M, string, error) {
+	if board == SeasonBoardEconomy {
+		if LocalEconomyDM == nil || !LocalEconomyDM.available() {
+			return nil, nil, "", ErrLeaderboardUnavailable
+		}
+		return LocalEconomyDM.repo, bson.M{"guild_id": guildID, "season_earnings": bson.M{"$gt": 0}}, "season_earnings", nil
+	}
+	if LocalLevelsDM == nil || !LocalLevelsDM.available() {
+		return nil, nil, "", ErrLeaderboardUnavailable
+	}
+	return LocalLevelsDM.repo, bson.M{"guild_id": guildID, "season": season, "season_xp": bson.M{"$gt": 0}}, "season_xp", nil
+}
+
+// seasonRow holds the fields of a profile a season board reads
+type seasonRow struct {
+	UserID   string `bson:"user_id"`
+	Earnings int64  `bson:"season_earnings"`
+	XP       int64  `bson:"season_xp"`
+}
+
+// GetSeasonStandings returns a page of a season board, best first
+func GetSeasonStandings(board, guildID string, season int, limit, skip int64) ([]models.SeasonStanding, error) {
+	repo, match, field, err := seasonBoard(board, guildID, season)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var rows []seasonRow
+	opts := FindOptions{Sort: bson.D{{Key: field, Value: -1}, {Key: "_id", Value: 1}}, Skip: skip, Limit: limit}
+	if err := repo.Find(ctx, match, opts, &rows); err != nil {
+		return nil, err
+	}
+
+	standings := make([]models.SeasonStanding, 0, len(rows))
+	for _, row := range rows {
+		value := row.XP
+		if board == SeasonBoardEconomy {
+			value = row.Earnings
+		}
+		standings = append(standings, models.SeasonStanding{UserID: row.UserID, Value: value})
+	}
+	return standings, nil
+}
+
+// CountSeasonStandings returns how many users a season board has
+func CountSeasonStandings(board, guildID string, season int) (int64, error) {
+	repo, match, _, err := seasonBoard(board, guildID, season)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return repo.Count(ctx, match)
+}
+
+// GetSeasonRank returns the position (1-based) of a user with the given value in
+// a season board
+func GetSeasonRank(board, guildID string, season int, value int64) (int64, error) {
+	repo, match, field, err := seasonBoard(board, guildID, season)
+	if err != nil {
+		return 0, err
+	}
+	match[field] = bson.M{"$gt": value}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ahead, err := repo.Count(ctx, match)
+	if err != nil {
+		return 0, err
+	}
+	return ahead + 1, nil
+}
+
+// ResetSeasonEarnings sets the season earnings of every local economy profile of
+// a guild back to zero
+func ResetSeasonEarnings(guildID string) error {
+	if LocalEconomyDM == nil || !LocalEconomyDM.available() {
+		return ErrDatabaseOffline
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	filter := bson.M{"guild_id": guildID, "season_earnings": bson.M{"$nin": bson.A{0, nil}}}
+	var ids []struct {
+		ID string `bson:"_id"`
+	}
+	if err := LocalEconomyDM.repo.Find(ctx, filter, FindOptions{}, &ids); err != nil {
+		return err
+	}
+
+	if _, err := LocalEconomyDM.repo.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"season_earnings": 0}}); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		LocalEconomyDM.Invalidate(bson.M{"_id": id.ID})
+	}
+	return nil
+}
+
+// GetDueSeasons returns the guilds whose running season is over
+func GetDueSeasons(now time.Time) ([]*models.GuildDocument, error) {
+	if GlobalGuildDM == nil {
+		return nil, fmt.Errorf("guild data manager not initialized")
+	}
+	return GlobalGuildDM.GetAll(bson.M{"seasons.active": true, "seasons.endsAt": bson.M{"$lte": now}})
+}
+
+// GetSeason returns a season of a guild, or nil if it does not exist
+func GetSeason(guildID string, number int) (*models.Season, error) {
+	if SeasonsDM == nil {
+		return nil, fmt.Errorf("seasons data manager not initialized")
+	}
+	return SeasonsDM.Get(bson.M{"_id": fmt.Sprintf("%s_%d", guildID, number)})
+}
+
+// SaveSeason writes a season record
+func SaveSeason(season *models.Season) error {
+	if SeasonsDM == nil {
+		return fmt.Errorf("seasons data manager not initialized")
+	}
+	season.ID = fmt.Sprintf("%s_%d", season.GuildID, season.Number)
+	_, err := SeasonsDM.Set(bson.M{"_id": season.ID}, season)
+	return err
+}
+
+// GetPastSeasons returns the last finished seasons of a guild, newest first
+func GetPastSeasons(guildID string, limit int64) ([]*models.Season, error) {
+	if SeasonsDM == nil || !SeasonsDM.available() {
+		return nil, ErrDatabaseOffline
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var results []*models.Season
+	opts := FindOptions{Sort: bson.D{{Key: "number", Value: -1}}, Limit: limit}
+	if err := SeasonsDM.repo.Find(ctx, bson.M{"guild_id": guildID, "ended_at": bson.M{"$exists": true}}, opts, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// tempBans returns the storage of the tempbans, which have no DataManager
+// since they are only read by the scheduler
+func tempBans() (Repository, error) {
+	if GlobalGuildDM == nil {
+		return nil, ErrDatabaseOffline
+	}
+	repo := GlobalGuildDM.dbInstance.repository("tempbans")
+	if !repo.Available() {
+		return nil, ErrDatabaseOffline
+	}
+	return repo, nil
+}
+
+// SaveTempBan records a ban to lift at expiresAt, replacing the previous one
+// of the member
+func SaveTempBan(guildID, userID string, expiresAt time.Time) error {
+	repo, err := tempBans()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ban := models.TempBan{GuildID: guildID, UserID: userID, ExpiresAt: expiresAt}
+	return repo.UpsertMany(ctx, []Upsert{{
+		Filter: bson.M{"guildId": guildID, "userId": userID},
+		Update: bson.M{"$set": ban},
+	}})
+}
+
+// GetExpiredTempBans returns the bans whose time is up
+func GetExpiredTempBans(now time.Time) ([]*models.TempBan, error) {
+	repo, err := tempBans()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var bans []*models.TempBan
+	if err := repo.Find(ctx, bson.M{"expiresAt": bson.M{"$lte": now}}, FindOptions{}, &bans); err != nil {
+		return nil, err
+	}
+	return bans, nil
+}
+
+// DeleteTempBan forgets the ban of a member
+func DeleteTempBan(guildID, userID string) error {
+	repo, err := tempBans()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = repo.DeleteOne(ctx, bson.M{"guildId": guildID, "userId": userID})
+	return err
+}
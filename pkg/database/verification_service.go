@@ -0,0 +1,60 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var ErrVerificationManagerNotInitialized = errors.New("verification data manager not initialized")
+
+// AddPendingVerification registers a member that must verify before the timeout
+// expires. A zero timeout keeps the member pending without kicking it.
+func AddPendingVerification(guildID, userID string, timeout time.Duration) error {
+	if VerificationDM == nil {
+		return ErrVerificationManagerNotInitialized
+	}
+
+	id := fmt.Sprintf("%s_%s", guildID, userID)
+	now := time.Now()
+	pending := &models.PendingVerification{
+		ID:       id,
+		GuildID:  guildID,
+		UserID:   userID,
+		JoinedAt: now,
+	}
+	if timeout > 0 {
+		pending.ExpiresAt = now.Add(timeout)
+	}
+
+	_, err := VerificationDM.Set(bson.M{"_id": id}, pending)
+	return err
+}
+
+// GetPendingVerification returns the pending verification of a member, or nil
+// if the member is not waiting to verify
+func GetPendingVerification(guildID, userID string) (*models.PendingVerification, error) {
+	if VerificationDM == nil {
+		return nil, ErrVerificationManagerNotInitialized
+	}
+	return VerificationDM.Get(bson.M{"_id": fmt.Sprintf("%s_%s", guildID, userID)})
+}
+
+// RemovePendingVerification removes a member from the pending verification list
+func RemovePendingVerification(guildID, userID string) error {
+	if VerificationDM == nil {
+		return ErrVerificationManagerNotInitialized
+	}
+	return VerificationDM.Delete(bson.M{"_id": fmt.Sprintf("%s_%s", guildID, userID)})
+}
+
+// GetExpiredPendingVerifications returns every pending verification whose timeout has passed
+func GetExpiredPendingVerifications(now time.Time) ([]*models.PendingVerification, error) {
+	if VerificationDM == nil {
+		return nil, ErrVerificationManagerNotInitialized
+	}
+	return VerificationDM.GetAll(bson.M{"expires_at": bson.M{"$gt": time.Time{}, "$lte": now}})
+}
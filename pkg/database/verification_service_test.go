@@ -0,0 +1,31 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPendingVerificationWithoutTimeoutNeverExpires(t *testing.T) {
+	useMemoryDatabase(t)
+
+	if err := AddPendingVerification("g1", "alice", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddPendingVerification("g1", "bob", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	expired, err := GetExpiredPendingVerifications(time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expired) != 1 || expired[0].UserID != "bob" {
+		t.Errorf("Expected only bob to expire, got %+v", expired)
+	}
+	if pending, err := GetPendingVerification("g1", "alice"); err != nil || pending == nil {
+		t.Errorf("Expected alice to stay pending, got %v, %v", pending, err)
+	}
+	if pending, err := GetPendingVerification("g1", "carol"); err != nil || pending != nil {
+		t.Errorf("Expected carol not to be pending, got %v, %v", pending, err)
+	}
+}
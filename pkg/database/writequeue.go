@@ -0,0 +1,336 @@
+package database
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ErrWriteQueueFull is returned when the database is unreachable and the
+// offline queue can take no more writes
+var ErrWriteQueueFull = errors.New("offline write queue full")
+
+// DefaultWriteQueueMax bounds the offline queue when no limit is configured
+const DefaultWriteQueueMax = 10000
+
+// writeQueue keeps the writes made while MongoDB is unreachable, in order.
+// Writes to a document already queued replace the queued one. With a journal
+// every change is appended to it, so a restart keeps the pending writes; the
+// journal is rewritten with only the pending writes after every sync.
+type writeQueue struct {
+	mu      sync.Mutex
+	ops     []QueuedOperation
+	max     int
+	path    string
+	journal *os.File
+}
+
+// journalEntry is a line of the journal: a queued operation, or the ID of one
+// that was applied or dropped
+type journalEntry struct {
+	Done       string          `json:"done,omitempty"`
+	ID         string          `json:"id,omitempty"`
+	Collection string          `json:"collection,omitempty"`
+	Operation  string          `json:"operation,omitempty"`
+	Query      json.RawMessage `json:"query,omitempty"` // Canonical extended JSON, which keeps the BSON types
+	Update     json.RawMessage `json:"update,omitempty"`
+	Guards     json.RawMessage `json:"guards,omitempty"`
+	QueuedAt   time.Time       `json:"queuedAt,omitempty"`
+}
+
+func newWriteQueue(max int) *writeQueue {
+	if max <= 0 {
+		max = DefaultWriteQueueMax
+	}
+	return &writeQueue{max: max}
+}
+
+// openJournal loads the writes left in the journal at path and appends the
+// next ones to it
+func (q *writeQueue) openJournal(path string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if file, err := os.Open(path); err == nil {
+		err = q.load(file)
+		file.Close()
+		if err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	q.path = path
+	return q.rewriteLocked()
+}
+
+// load replays the journal into the queue
+func (q *writeQueue) load(file *os.File) error {
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		var entry journalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// A crash can cut the last line short
+			logger.Warn(fmt.Sprintf("Línea %d de la cola offline ilegible, se ignora: %v", line, err), "DB-Queue")
+			continue
+		}
+		if entry.Done != "" {
+			q.removeLocked(entry.Done)
+			continue
+		}
+		op, err := entry.operation()
+		if err != nil {
+			logger.Warn(fmt.Sprintf("Operación %s de la cola offline ilegible, se ignora: %v", entry.ID, err), "DB-Queue")
+			continue
+		}
+		q.putLocked(op)
+	}
+	return scanner.Err()
+}
+
+// add queues an operation, coalescing it with a queued write to the same
+// document. It fails with ErrWriteQueueFull when the queue is at its limit.
+func (q *writeQueue) add(op QueuedOperation) error {
+	if op.ID == "" {
+		op.ID = uuid.New().String()
+	}
+	if op.QueuedAt.IsZero() {
+		op.QueuedAt = time.Now()
+	}
+	if op.Operation == "set" && op.Update == nil {
+		op.Update = bson.M{"$set": op.Data}
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	coalesced := q.indexLocked(op.key()) >= 0
+	if !coalesced && len(q.ops) >= q.max {
+		writeQueueRejected.Inc()
+		return ErrWriteQueueFull
+	}
+	if coalesced {
+		writeQueueCoalesced.Inc()
+	}
+
+	op = q.putLocked(op)
+	if q.journal != nil {
+		if err := q.appendLocked(op.entry()); err != nil {
+			logger.Error(fmt.Sprintf("No se pudo guardar la escritura offline en el disco: %v", err), "DB-Queue")
+		}
+	}
+	return nil
+}
+
+// putLocked queues op at the end, replacing a queued write to the same
+// document. The replaced write keeps its guards, which describe the document
+// the first offline write was based on.
+func (q *writeQueue) putLocked(op QueuedOperation) QueuedOperation {
+	if i := q.indexLocked(op.key()); i >= 0 {
+		if op.Guards == nil && op.Operation == "set" && q.ops[i].Operation == "set" {
+			op.Guards = q.ops[i].Guards
+		}
+		q.ops = append(q.ops[:i], q.ops[i+1:]...)
+	}
+	q.ops = append(q.ops, op)
+	return op
+}
+
+func (q *writeQueue) indexLocked(key string) int {
+	for i := range q.ops {
+		if q.ops[i].key() == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func (q *writeQueue) removeLocked(id string) {
+	for i := range q.ops {
+		if q.ops[i].ID == id {
+			q.ops = append(q.ops[:i], q.ops[i+1:]...)
+			return
+		}
+	}
+}
+
+// done removes an operation that was applied or dropped
+func (q *writeQueue) done(id string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.removeLocked(id)
+	if q.journal != nil {
+		if err := q.appendLocked(journalEntry{Done: id}); err != nil {
+			logger.Error(fmt.Sprintf("No se pudo marcar la escritura %s como aplicada: %v", id, err), "DB-Queue")
+		}
+	}
+}
+
+// pending returns a copy of the queued operations, oldest first
+func (q *writeQueue) pending() []QueuedOperation {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	ops := make([]QueuedOperation, len(q.ops))
+	copy(ops, q.ops)
+	return ops
+}
+
+func (q *writeQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.ops)
+}
+
+// oldest returns when the oldest pending write was queued
+func (q *writeQueue) oldest() (time.Time, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.ops) == 0 {
+		return time.Time{}, false
+	}
+	oldest := q.ops[0].QueuedAt
+	for _, op := range q.ops[1:] {
+		if op.QueuedAt.Before(oldest) {
+			oldest = op.QueuedAt
+		}
+	}
+	return oldest, true
+}
+
+// compact rewrites the journal with only the pending writes
+func (q *writeQueue) compact() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.path == "" {
+		return nil
+	}
+	return q.rewriteLocked()
+}
+
+// rewriteLocked replaces the journal atomically and reopens it for appending
+func (q *writeQueue) rewriteLocked() error {
+	tmp := q.path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, op := range q.ops {
+		if err := encoder.Encode(op.entry()); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	if q.journal != nil {
+		q.journal.Close()
+		q.journal = nil
+	}
+	if err := os.Rename(tmp, q.path); err != nil {
+		return err
+	}
+	q.journal, err = os.OpenFile(q.path, os.O_APPEND|os.O_WRONLY, 0o600)
+	return err
+}
+
+func (q *writeQueue) appendLocked(entry journalEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := q.journal.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return q.journal.Sync()
+}
+
+// close releases the journal
+func (q *writeQueue) close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.journal == nil {
+		return nil
+	}
+	err := q.journal.Close()
+	q.journal = nil
+	return err
+}
+
+// key identifies the document an operation writes, for coalescing
+func (op QueuedOperation) key() string {
+	return generateCacheKey(op.CollectionName, op.Query)
+}
+
+func (op QueuedOperation) entry() journalEntry {
+	return journalEntry{
+		ID:         op.ID,
+		Collection: op.CollectionName,
+		Operation:  op.Operation,
+		Query:      extJSON(op.Query),
+		Update:     extJSON(op.Update),
+		Guards:     extJSON(op.Guards),
+		QueuedAt:   op.QueuedAt,
+	}
+}
+
+func (e journalEntry) operation() (QueuedOperation, error) {
+	op := QueuedOperation{
+		ID:             e.ID,
+		CollectionName: e.Collection,
+		Operation:      e.Operation,
+		QueuedAt:       e.QueuedAt,
+	}
+	for _, field := range []struct {
+		raw    json.RawMessage
+		target *bson.M
+	}{{e.Query, &op.Query}, {e.Update, &op.Update}, {e.Guards, &op.Guards}} {
+		if len(field.raw) == 0 {
+			continue
+		}
+		if err := bson.UnmarshalExtJSON(field.raw, true, field.target); err != nil {
+			return op, err
+		}
+	}
+	return op, nil
+}
+
+// extJSON encodes a document as canonical extended JSON, nil for empty ones
+func extJSON(doc bson.M) json.RawMessage {
+	if doc == nil {
+		return nil
+	}
+	data, err := bson.MarshalExtJSON(doc, true, false)
+	if err != nil {
+		logger.Error(fmt.Sprintf("No se pudo codificar una escritura offline: %v", err), "DB-Queue")
+		return nil
+	}
+	return data
+}
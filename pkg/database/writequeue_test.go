@@ -0,0 +1,72 @@
+package database
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestWriteQueueCoalescesAndBounds(t *testing.T) {
+	q := newWriteQueue(2)
+	guards := bson.M{"version": int64(3)}
+	if err := q.add(QueuedOperation{CollectionName: "c", Query: bson.M{"_id": "a"}, Operation: "set", Data: bson.M{"n": 1}, Guards: guards}); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.add(QueuedOperation{CollectionName: "c", Query: bson.M{"_id": "b"}, Operation: "delete"}); err != nil {
+		t.Fatal(err)
+	}
+	// A later write to a queued document replaces it, even when full
+	if err := q.add(QueuedOperation{CollectionName: "c", Query: bson.M{"_id": "a"}, Operation: "set", Data: bson.M{"n": 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.add(QueuedOperation{CollectionName: "c", Query: bson.M{"_id": "c"}, Operation: "delete"}); !errors.Is(err, ErrWriteQueueFull) {
+		t.Errorf("Expected a full queue, got %v", err)
+	}
+
+	pending := q.pending()
+	if len(pending) != 2 || pending[0].Query["_id"] != "b" || pending[1].Query["_id"] != "a" {
+		t.Fatalf("Expected b then the coalesced a, got %+v", pending)
+	}
+	if pending[1].Update["$set"].(bson.M)["n"] != 2 || pending[1].Guards["version"] != int64(3) {
+		t.Errorf("Expected the last data with the first guards, got %+v", pending[1])
+	}
+}
+
+func TestWriteQueueJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue", "writes.jsonl")
+	q := newWriteQueue(0)
+	if err := q.openJournal(path); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"a", "b", "c"} {
+		if err := q.add(QueuedOperation{CollectionName: "c", Query: bson.M{"_id": id}, Operation: "set", Data: bson.M{"n": int32(1)}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	q.done(q.pending()[1].ID)
+	q.close()
+
+	// A restart loads what was not applied, in order
+	reopened := newWriteQueue(0)
+	if err := reopened.openJournal(path); err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.close()
+	pending := reopened.pending()
+	if len(pending) != 2 || pending[0].Query["_id"] != "a" || pending[1].Query["_id"] != "c" {
+		t.Fatalf("Expected a and c after the reload, got %+v", pending)
+	}
+	if set, ok := pending[0].Update["$set"].(bson.M); !ok || set["n"] != int32(1) {
+		t.Errorf("Expected the update to keep its types, got %#v", pending[0].Update)
+	}
+
+	if err := reopened.compact(); err != nil {
+		t.Fatal(err)
+	}
+	reopened.done(pending[0].ID)
+	if got := reopened.len(); got != 1 {
+		t.Errorf("Expected 1 pending write, got %d", got)
+	}
+}
@@ -3,6 +3,7 @@
 package discord
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"sync"
@@ -12,6 +13,7 @@ import (
 	"github.com/PancyStudios/PancyBotGo/pkg/discord/premium"
 	"github.com/PancyStudios/PancyBotGo/pkg/errors"
 	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/PancyStudios/PancyBotGo/pkg/tracing"
 	"github.com/bwmarrin/discordgo"
 )
 
@@ -110,7 +112,8 @@ func NewClient(token string) (*ExtendedClient, error) {
 	session.Identify.Intents = discordgo.IntentsGuilds |
 		discordgo.IntentsGuildMessages |
 		discordgo.IntentsGuildMembers |
-		discordgo.IntentsGuildVoiceStates
+		discordgo.IntentsGuildVoiceStates |
+		discordgo.IntentsGuildInvites
 
 	// Configure session
 	session.ShardCount = 1 // Auto sharding equivalent
@@ -292,7 +295,20 @@ func (c *ExtendedClient) handleInteraction(s *discordgo.Session, i *discordgo.In
 		}
 	}
 
-	if err := cmd.Run(ctx); err != nil {
+	traceCtx, span := tracing.Start(context.Background(), "command "+commandName, tracing.KindServer,
+		tracing.String("command.name", commandName),
+		tracing.String("command.kind", CommandKindSlash),
+		tracing.String("discord.guild_id", i.GuildID),
+		tracing.String("discord.user_id", ctx.User().ID),
+	)
+	defer span.End()
+	ctx.ctx = traceCtx
+
+	start := time.Now()
+	err := cmd.Run(ctx)
+	ObserveCommand(commandName, CommandKindSlash, start, err)
+	span.RecordError(err)
+	if err != nil {
 		logger.Error("Error executing command "+commandName+": "+err.Error(), "Client")
 	}
 }
@@ -2,6 +2,8 @@
 package discord
 
 import (
+	"context"
+
 	"github.com/PancyStudios/PancyBotGo/pkg/discord/premium"
 	"github.com/bwmarrin/discordgo"
 )
@@ -11,6 +13,21 @@ type CommandContext struct {
 	Session     *discordgo.Session
 	Interaction *discordgo.InteractionCreate
 	Client      *ExtendedClient
+	ctx         context.Context
+}
+
+// Context returns the context of the command run. It carries the tracing span
+// of the command, so pass it to database and HTTP calls to see them in the trace.
+func (ctx *CommandContext) Context() context.Context {
+	if ctx.ctx == nil {
+		return context.Background()
+	}
+	return ctx.ctx
+}
+
+// request returns the discordgo options that tie a REST call to the command
+func (ctx *CommandContext) request() discordgo.RequestOption {
+	return discordgo.WithContext(ctx.Context())
 }
 
 // Command represents a Discord slash command
@@ -149,7 +166,7 @@ func (ctx *CommandContext) ReplyText(content string) error {
 		Data: &discordgo.InteractionResponseData{
 			Content: content,
 		},
-	})
+	}, ctx.request())
 }
 
 // ReplySuccess sends a success embed reply
@@ -179,7 +196,7 @@ func (ctx *CommandContext) ReplyEmbed(embed *discordgo.MessageEmbed) error {
 		Data: &discordgo.InteractionResponseData{
 			Embeds: []*discordgo.MessageEmbed{embed},
 		},
-	})
+	}, ctx.request())
 }
 
 // ReplyEphemeral sends an ephemeral embedded reply visible only to the user
@@ -195,7 +212,7 @@ func (ctx *CommandContext) ReplyEphemeralText(content string) error {
 			Content: content,
 			Flags:   discordgo.MessageFlagsEphemeral,
 		},
-	})
+	}, ctx.request())
 }
 
 // ReplyEphemeralEmbed sends an ephemeral embed reply visible only to the user
@@ -206,14 +223,14 @@ func (ctx *CommandContext) ReplyEphemeralEmbed(embed *discordgo.MessageEmbed) er
 			Embeds: []*discordgo.MessageEmbed{embed},
 			Flags:  discordgo.MessageFlagsEphemeral,
 		},
-	})
+	}, ctx.request())
 }
 
 // Defer defers the interaction response
 func (ctx *CommandContext) Defer() error {
 	return ctx.Session.InteractionRespond(ctx.Interaction.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
-	})
+	}, ctx.request())
 }
 
 // EditReply edits the original interaction response with an embed
@@ -225,7 +242,7 @@ func (ctx *CommandContext) EditReply(content string) error {
 func (ctx *CommandContext) EditReplyText(content string) error {
 	_, err := ctx.Session.InteractionResponseEdit(ctx.Interaction.Interaction, &discordgo.WebhookEdit{
 		Content: &content,
-	})
+	}, ctx.request())
 	return err
 }
 
@@ -233,7 +250,15 @@ func (ctx *CommandContext) EditReplyText(content string) error {
 func (ctx *CommandContext) EditReplyEmbed(embed *discordgo.MessageEmbed) error {
 	_, err := ctx.Session.InteractionResponseEdit(ctx.Interaction.Interaction, &discordgo.WebhookEdit{
 		Embeds: &[]*discordgo.MessageEmbed{embed},
-	})
+	}, ctx.request())
+	return err
+}
+
+// EditReplyFiles edits the original interaction response attaching files
+func (ctx *CommandContext) EditReplyFiles(files ...*discordgo.File) error {
+	_, err := ctx.Session.InteractionResponseEdit(ctx.Interaction.Interaction, &discordgo.WebhookEdit{
+		Files: files,
+	}, ctx.request())
 	return err
 }
 
@@ -281,6 +306,15 @@ func (ctx *CommandContext) GetIntOption(name string) int64 {
 	return opt.IntValue()
 }
 
+// GetFloatOption retrieves a number option value
+func (ctx *CommandContext) GetFloatOption(name string) float64 {
+	opt := ctx.GetOption(name)
+	if opt == nil {
+		return 0
+	}
+	return opt.FloatValue()
+}
+
 // GetBoolOption retrieves a boolean option value
 func (ctx *CommandContext) GetBoolOption(name string) bool {
 	opt := ctx.GetOption(name)
@@ -359,7 +393,7 @@ func (ctx *CommandContext) SendAutoCompleteResults(choices []string) error {
 		Data: &discordgo.InteractionResponseData{
 			Choices: acs,
 		},
-	})
+	}, ctx.request())
 }
 
 // SendAutoCompleteChoices sends custom autocomplete choices
@@ -369,7 +403,7 @@ func (ctx *CommandContext) SendAutoCompleteChoices(choices []*discordgo.Applicat
 		Data: &discordgo.InteractionResponseData{
 			Choices: choices,
 		},
-	})
+	}, ctx.request())
 }
 
 // PremiumRequirement represents the type of premium required for a command
@@ -0,0 +1,69 @@
+package discord
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/metrics"
+)
+
+// Command kinds used in the command metrics
+const (
+	CommandKindSlash  = "slash"
+	CommandKindPrefix = "prefix"
+)
+
+var (
+	commandsTotal = metrics.NewCounter("pancybot_commands_total",
+		"Commands run, by command name, kind and result.", "command", "kind", "result")
+	commandDuration = metrics.NewHistogram("pancybot_command_duration_seconds",
+		"Time spent running commands.", nil, "command", "kind")
+)
+
+// ObserveCommand records a command run that started at start. Both the slash
+// commands and the prefix router report through it.
+func ObserveCommand(command, kind string, start time.Time, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	commandsTotal.Inc(command, kind, result)
+	commandDuration.Observe(time.Since(start).Seconds(), command, kind)
+}
+
+func init() {
+	metrics.NewGaugeFunc("pancybot_gateway_latency_seconds",
+		"Heartbeat latency of the Discord gateway.", nil, func(emit func(float64, ...string)) {
+			if c := Get(); c != nil && c.Session != nil {
+				emit(c.Session.HeartbeatLatency().Seconds())
+			}
+		})
+	metrics.NewGaugeFunc("pancybot_shard_up",
+		"Whether the gateway connection of a shard is ready.", []string{"shard"}, func(emit func(float64, ...string)) {
+			c := Get()
+			if c == nil || c.Session == nil {
+				return
+			}
+			up := 0.0
+			if c.IsReady() && c.Session.DataReady {
+				up = 1
+			}
+			emit(up, strconv.Itoa(c.Session.ShardID))
+		})
+	metrics.NewGaugeFunc("pancybot_shards",
+		"Number of shards the bot runs with.", nil, func(emit func(float64, ...string)) {
+			if c := Get(); c != nil && c.Session != nil {
+				count := c.Session.ShardCount
+				if count == 0 {
+					count = 1
+				}
+				emit(float64(count))
+			}
+		})
+	metrics.NewGaugeFunc("pancybot_guilds",
+		"Guilds the bot is in.", nil, func(emit func(float64, ...string)) {
+			if c := Get(); c != nil {
+				emit(float64(c.GuildCount()))
+			}
+		})
+}
@@ -0,0 +1,152 @@
+package ecoconfig
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+)
+
+// SlotSymbols are the symbols of the slot machine reels, from the most to the
+// least common
+var SlotSymbols = []string{"🍒", "🍋", "🍇", "🔔", "⭐", "💎"}
+
+// slotWeights is how often each symbol of SlotSymbols lands on a reel, out of 100
+var slotWeights = []int{30, 25, 20, 12, 8, 5}
+
+var defaultSlotsPayouts = map[string]float64{
+	"🍒": 5,
+	"🍋": 8,
+	"🍇": 10,
+	"🔔": 15,
+	"⭐": 25,
+	"💎": 50,
+}
+
+const (
+	defaultSlotsPair = 1.2
+	maxSlotsPayout   = 1000
+	maxHouseEdge     = 50
+)
+
+// CasinoRules is the resolved configuration of the games of chance in one economy
+type CasinoRules struct {
+	Enabled      bool
+	MinBet       int64
+	MaxBet       int64
+	HouseEdge    float64 // Between 0 and 1, kept from the profit of every win
+	SlotsPayouts map[string]float64
+	SlotsPair    float64
+	Currency     Currency
+}
+
+var localCasinoDefaults = CasinoRules{MinBet: 10, MaxBet: 50000, HouseEdge: 0.03, SlotsPair: defaultSlotsPair}
+
+var globalCasinoDefaults = CasinoRules{MinBet: 10, MaxBet: 25000, HouseEdge: 0.03, SlotsPair: defaultSlotsPair}
+
+// CasinoFor returns the casino rules of an economy. As with the commands, disabling
+// the casino in a server disables it in both economies and the limits only change
+// the local economy.
+func CasinoFor(guildID string, global bool) CasinoRules {
+	return ResolveCasino(Load(guildID), global)
+}
+
+// ResolveCasino merges a server config over the casino defaults
+func ResolveCasino(cfg models.EconomyConfig, global bool) CasinoRules {
+	custom := cfg.Casino
+	payouts := make(map[string]float64, len(defaultSlotsPayouts))
+	for symbol, multiplier := range defaultSlotsPayouts {
+		payouts[symbol] = multiplier
+	}
+
+	if global {
+		rules := globalCasinoDefaults
+		rules.Enabled = !custom.Disabled
+		rules.SlotsPayouts = payouts
+		rules.Currency = Stars
+		return rules
+	}
+
+	rules := localCasinoDefaults
+	rules.Enabled = !custom.Disabled
+	rules.Currency = LocalCurrency(cfg)
+	if custom.MinBet > 0 {
+		rules.MinBet = custom.MinBet
+	}
+	if custom.MaxBet > 0 {
+		rules.MaxBet = custom.MaxBet
+	}
+	if custom.HouseEdge > 0 {
+		rules.HouseEdge = float64(custom.HouseEdge) / 100
+	}
+	if custom.SlotsPair > 0 {
+		rules.SlotsPair = custom.SlotsPair
+	}
+	for symbol, multiplier := range custom.SlotsPayouts {
+		if multiplier > 0 {
+			payouts[symbol] = multiplier
+		}
+	}
+	rules.SlotsPayouts = payouts
+	return rules
+}
+
+// SlotWeight returns how often a symbol lands on a reel, out of 100
+func SlotWeight(symbol string) int {
+	for i, s := range SlotSymbols {
+		if s == symbol {
+			return slotWeights[i]
+		}
+	}
+	return 0
+}
+
+// validateCasino checks the casino part of a server config
+func validateCasino(cfg models.EconomyConfig) error {
+	custom := cfg.Casino
+	if custom.MinBet < 0 || custom.MaxBet < 0 || custom.SlotsPair < 0 {
+		return fmt.Errorf("%w: los límites del casino no pueden ser negativos", ErrInvalidConfig)
+	}
+	if custom.HouseEdge < 0 || custom.HouseEdge > maxHouseEdge {
+		return fmt.Errorf("%w: la ventaja de la casa debe estar entre 1 y %d", ErrInvalidConfig, maxHouseEdge)
+	}
+	for symbol, multiplier := range custom.SlotsPayouts {
+		if SlotWeight(symbol) == 0 {
+			return fmt.Errorf("%w: %s no es un símbolo de las tragaperras (%s)", ErrInvalidConfig, symbol, strings.Join(SlotSymbols, " "))
+		}
+		if multiplier < 0 || multiplier > maxSlotsPayout {
+			return fmt.Errorf("%w: los premios de las tragaperras deben estar entre 1 y %d", ErrInvalidConfig, maxSlotsPayout)
+		}
+	}
+	if custom.SlotsPair > maxSlotsPayout {
+		return fmt.Errorf("%w: los premios de las tragaperras deben estar entre 1 y %d", ErrInvalidConfig, maxSlotsPayout)
+	}
+
+	rules := ResolveCasino(cfg, false)
+	if rules.MinBet > rules.MaxBet {
+		return fmt.Errorf("%w: en el casino la apuesta mínima no puede superar a la máxima", ErrInvalidConfig)
+	}
+	return nil
+}
+
+// Summary describes the casino rules in one line for the config embeds
+func (r CasinoRules) Summary() string {
+	if !r.Enabled {
+		return "❌ Desactivado"
+	}
+	return fmt.Sprintf("🎰 Apuestas de %s · 🏦 La casa se queda el %.0f%% de las ganancias", span(r.MinBet, r.MaxBet, ""), r.HouseEdge*100)
+}
+
+// SlotsTable describes the payout table of the slot machine
+func (r CasinoRules) SlotsTable() string {
+	symbols := append([]string{}, SlotSymbols...)
+	sort.SliceStable(symbols, func(i, j int) bool { return r.SlotsPayouts[symbols[i]] > r.SlotsPayouts[symbols[j]] })
+
+	var parts []string
+	for _, symbol := range symbols {
+		parts = append(parts, fmt.Sprintf("%s%s%s x%g", symbol, symbol, symbol, r.SlotsPayouts[symbol]))
+	}
+	parts = append(parts, fmt.Sprintf("Pareja x%g", r.SlotsPair))
+	return strings.Join(parts, " · ")
+}
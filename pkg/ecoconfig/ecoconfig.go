@@ -0,0 +1,304 @@
+// Package ecoconfig resolves the rules of the economy commands. The global economy
+// (Stars) always uses the bot defaults; the local economy of a server starts from
+// its own defaults and applies the settings stored in the guild document.
+package ecoconfig
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Commands that can be configured
+const (
+	CommandWork   = "work"
+	CommandCrime  = "crime"
+	CommandSlut   = "slut"
+	CommandRob    = "rob"
+	CommandDaily  = "daily"
+	CommandWeekly = "weekly"
+)
+
+// Commands lists the configurable commands in the order they are shown
+var Commands = []string{CommandWork, CommandCrime, CommandSlut, CommandRob, CommandDaily, CommandWeekly}
+
+const (
+	maxMessages      = 10
+	maxMessageLength = 300
+)
+
+var (
+	ErrUnknownCommand = errors.New("unknown economy command")
+	ErrInvalidConfig  = errors.New("invalid economy config")
+)
+
+// Currency is how an economy names its money
+type Currency struct {
+	Name  string
+	Emoji string
+}
+
+// Stars is the currency of the global economy
+var Stars = Currency{Name: "estrellas", Emoji: "🌟"}
+
+// DefaultLocalCurrency is the currency of servers that don't name their own
+var DefaultLocalCurrency = Currency{Name: "monedas", Emoji: "💵"}
+
+// Format renders an amount, e.g. "💵 150 monedas"
+func (c Currency) Format(amount int64) string {
+	return fmt.Sprintf("%s %d %s", c.Emoji, amount, c.Name)
+}
+
+// Rules is the resolved configuration of a command in one economy
+type Rules struct {
+	Command      string
+	Enabled      bool
+	MinPayout    int64
+	MaxPayout    int64
+	SuccessRate  float64 // Between 0 and 1
+	MinFine      int64
+	MaxFine      int64
+	Cooldown     time.Duration
+	Messages     []string
+	FailMessages []string
+	Currency     Currency
+}
+
+var localDefaults = map[string]Rules{
+	CommandWork:   {MinPayout: 50, MaxPayout: 249, SuccessRate: 1, Cooldown: 5 * time.Minute},
+	CommandCrime:  {MinPayout: 200, MaxPayout: 599, SuccessRate: 0.40, MinFine: 100, MaxFine: 299, Cooldown: 10 * time.Minute},
+	CommandSlut:   {MinPayout: 100, MaxPayout: 599, SuccessRate: 0.60, MinFine: 50, MaxFine: 149, Cooldown: 15 * time.Minute},
+	CommandRob:    {MinPayout: 10, MaxPayout: 40, SuccessRate: 0.40, MinFine: 25, MaxFine: 25, Cooldown: 30 * time.Minute},
+	CommandDaily:  {MinPayout: 1000, MaxPayout: 1000, SuccessRate: 1, Cooldown: 24 * time.Hour},
+	CommandWeekly: {MinPayout: 10000, MaxPayout: 10000, SuccessRate: 1, Cooldown: 7 * 24 * time.Hour},
+}
+
+var globalDefaults = map[string]Rules{
+	CommandWork:   {MinPayout: 50, MaxPayout: 249, SuccessRate: 1, Cooldown: 5 * time.Minute},
+	CommandCrime:  {MinPayout: 150, MaxPayout: 449, SuccessRate: 0.40, MinFine: 50, MaxFine: 199, Cooldown: 10 * time.Minute},
+	CommandSlut:   {MinPayout: 100, MaxPayout: 499, SuccessRate: 0.60, MinFine: 20, MaxFine: 119, Cooldown: 15 * time.Minute},
+	CommandRob:    {MinPayout: 10, MaxPayout: 40, SuccessRate: 0.40, MinFine: 25, MaxFine: 25, Cooldown: 30 * time.Minute},
+	CommandDaily:  {MinPayout: 1000, MaxPayout: 1000, SuccessRate: 1, Cooldown: 24 * time.Hour},
+	CommandWeekly: {MinPayout: 10000, MaxPayout: 10000, SuccessRate: 1, Cooldown: 7 * 24 * time.Hour},
+}
+
+// Known reports whether a command can be configured
+func Known(command string) bool {
+	_, ok := localDefaults[command]
+	return ok
+}
+
+// Load returns the economy settings of a server, empty if it has none
+func Load(guildID string) models.EconomyConfig {
+	if database.GlobalGuildDM == nil || guildID == "" {
+		return models.EconomyConfig{}
+	}
+	doc, err := database.GlobalGuildDM.Get(bson.M{"id": guildID})
+	if err != nil || doc == nil {
+		return models.EconomyConfig{}
+	}
+	return doc.Economy
+}
+
+// For returns the rules of a command. Disabling a command in a server disables it
+// in both economies; the rest of the settings only change the local economy.
+func For(guildID, command string, global bool) Rules {
+	return Resolve(Load(guildID), command, global)
+}
+
+// CurrencyFor returns the currency of an economy
+func CurrencyFor(guildID string, global bool) Currency {
+	if global {
+		return Stars
+	}
+	return LocalCurrency(Load(guildID))
+}
+
+// LocalCurrency returns the currency named by a server config
+func LocalCurrency(cfg models.EconomyConfig) Currency {
+	currency := DefaultLocalCurrency
+	if cfg.CurrencyName != "" {
+		currency.Name = cfg.CurrencyName
+	}
+	if cfg.CurrencyEmoji != "" {
+		currency.Emoji = cfg.CurrencyEmoji
+	}
+	return currency
+}
+
+// Resolve merges a server config over the defaults of a command
+func Resolve(cfg models.EconomyConfig, command string, global bool) Rules {
+	custom := cfg.Commands[command]
+	if global {
+		rules := globalDefaults[command]
+		rules.Command = command
+		rules.Enabled = !custom.Disabled
+		rules.Currency = Stars
+		return rules
+	}
+
+	rules := localDefaults[command]
+	rules.Command = command
+	rules.Enabled = !custom.Disabled
+	rules.Currency = LocalCurrency(cfg)
+	if custom.MinPayout > 0 {
+		rules.MinPayout = custom.MinPayout
+	}
+	if custom.MaxPayout > 0 {
+		rules.MaxPayout = custom.MaxPayout
+	}
+	if custom.SuccessRate > 0 {
+		rules.SuccessRate = float64(custom.SuccessRate) / 100
+	}
+	if custom.MinFine > 0 {
+		rules.MinFine = custom.MinFine
+	}
+	if custom.MaxFine > 0 {
+		rules.MaxFine = custom.MaxFine
+	}
+	if custom.CooldownMinutes > 0 {
+		rules.Cooldown = time.Duration(custom.CooldownMinutes) * time.Minute
+	}
+	rules.Messages = custom.Messages
+	rules.FailMessages = custom.FailMessages
+	return rules
+}
+
+// Validate checks a server config before it is saved
+func Validate(cfg models.EconomyConfig) error {
+	if len([]rune(cfg.CurrencyName)) > 32 || len([]rune(cfg.CurrencyEmoji)) > 64 {
+		return fmt.Errorf("%w: el nombre o el emoji de la moneda es demasiado largo", ErrInvalidConfig)
+	}
+	if cfg.StartingBalance < 0 || cfg.BankCapacity < 0 {
+		return fmt.Errorf("%w: el saldo inicial y la capacidad del banco no pueden ser negativos", ErrInvalidConfig)
+	}
+
+	for command, custom := range cfg.Commands {
+		if !Known(command) {
+			return fmt.Errorf("%w: %s", ErrUnknownCommand, command)
+		}
+		if custom.MinPayout < 0 || custom.MaxPayout < 0 || custom.MinFine < 0 || custom.MaxFine < 0 || custom.CooldownMinutes < 0 {
+			return fmt.Errorf("%w: los valores de %s no pueden ser negativos", ErrInvalidConfig, command)
+		}
+		if custom.SuccessRate < 0 || custom.SuccessRate > 100 {
+			return fmt.Errorf("%w: la probabilidad de %s debe estar entre 1 y 100", ErrInvalidConfig, command)
+		}
+		if len(custom.Messages) > maxMessages || len(custom.FailMessages) > maxMessages {
+			return fmt.Errorf("%w: %s admite como máximo %d mensajes de cada tipo", ErrInvalidConfig, command, maxMessages)
+		}
+		for _, message := range append(append([]string{}, custom.Messages...), custom.FailMessages...) {
+			if strings.TrimSpace(message) == "" || len([]rune(message)) > maxMessageLength {
+				return fmt.Errorf("%w: los mensajes deben tener entre 1 y %d caracteres", ErrInvalidConfig, maxMessageLength)
+			}
+		}
+
+		rules := Resolve(cfg, command, false)
+		if rules.MinPayout > rules.MaxPayout || rules.MinFine > rules.MaxFine {
+			return fmt.Errorf("%w: en %s el mínimo no puede superar al máximo", ErrInvalidConfig, command)
+		}
+		if command == CommandRob && (rules.MaxPayout > 100 || rules.MaxFine > 100) {
+			return fmt.Errorf("%w: el robo y la multa de rob son porcentajes de la cartera (1-100)", ErrInvalidConfig)
+		}
+	}
+	return validateCasino(cfg)
+}
+
+// Explain turns a validation error into a message for the server admin
+func Explain(err error) string {
+	switch {
+	case errors.Is(err, ErrUnknownCommand):
+		return "ese comando no se puede configurar. Usa: " + strings.Join(Commands, ", ") + "."
+	case errors.Is(err, ErrInvalidConfig):
+		return strings.TrimPrefix(err.Error(), ErrInvalidConfig.Error()+": ")
+	default:
+		return err.Error()
+	}
+}
+
+// Payout returns a random payout between the minimum and the maximum
+func (r Rules) Payout() int64 {
+	return between(r.MinPayout, r.MaxPayout)
+}
+
+// Fine returns a random fine between the minimum and the maximum
+func (r Rules) Fine() int64 {
+	return between(r.MinFine, r.MaxFine)
+}
+
+// Succeeds rolls the success rate
+func (r Rules) Succeeds() bool {
+	return rand.Float64() < r.SuccessRate
+}
+
+// Success renders a random custom success message, or the fallback. Messages can
+// use {amount} and {currency}.
+func (r Rules) Success(fallback string, amount int64) string {
+	return r.render(r.Messages, fallback, amount)
+}
+
+// Failure is Success for the failure messages
+func (r Rules) Failure(fallback string, amount int64) string {
+	return r.render(r.FailMessages, fallback, amount)
+}
+
+// DisabledText is the reply of a disabled command
+func (r Rules) DisabledText() string {
+	return fmt.Sprintf("❌ El comando `%s` está desactivado en este servidor.", r.Command)
+}
+
+// Summary describes the rules in one line for the config embeds
+func (r Rules) Summary() string {
+	if !r.Enabled {
+		return "❌ Desactivado"
+	}
+
+	payout := fmt.Sprintf("💰 %s", span(r.MinPayout, r.MaxPayout, ""))
+	if r.Command == CommandRob {
+		payout = fmt.Sprintf("💰 %s de la cartera", span(r.MinPayout, r.MaxPayout, "%"))
+	}
+	parts := []string{payout}
+	if r.SuccessRate < 1 {
+		parts = append(parts, fmt.Sprintf("🎲 %.0f%%", r.SuccessRate*100))
+		if r.Command == CommandRob {
+			parts = append(parts, fmt.Sprintf("🚔 %s", span(r.MinFine, r.MaxFine, "%")))
+		} else {
+			parts = append(parts, fmt.Sprintf("🚔 %s", span(r.MinFine, r.MaxFine, "")))
+		}
+	}
+	parts = append(parts, fmt.Sprintf("⏱️ %s", r.Cooldown))
+	if n := len(r.Messages) + len(r.FailMessages); n > 0 {
+		parts = append(parts, fmt.Sprintf("💬 %d mensajes", n))
+	}
+	return strings.Join(parts, " · ")
+}
+
+func span(min, max int64, unit string) string {
+	if min == max {
+		return fmt.Sprintf("%d%s", min, unit)
+	}
+	return fmt.Sprintf("%d-%d%s", min, max, unit)
+}
+
+func (r Rules) render(messages []string, fallback string, amount int64) string {
+	text := fallback
+	if len(messages) > 0 {
+		text = messages[rand.Intn(len(messages))]
+	}
+	return strings.NewReplacer(
+		"{amount}", r.Currency.Format(amount),
+		"{currency}", r.Currency.Name,
+	).Replace(text)
+}
+
+func between(min, max int64) int64 {
+	if max <= min {
+		return min
+	}
+	return min + rand.Int63n(max-min+1)
+}
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/PancyStudios/PancyBotGo/pkg/metrics"
 )
 
 // ErrorHandler manages error counting and reporting
@@ -39,6 +40,13 @@ var (
 	once    sync.Once
 )
 
+var (
+	errorsTotal = metrics.NewCounter("pancybot_errors_total",
+		"Errors counted by the anti-crash handler, including recovered panics.")
+	panicsTotal = metrics.NewCounter("pancybot_panics_total",
+		"Panics recovered by the anti-crash handler.")
+)
+
 // Init initializes the global error handler
 func Init(webhookURL string, shutdownFunc func()) *ErrorHandler {
 	once.Do(func() {
@@ -126,11 +134,13 @@ func (h *ErrorHandler) Stop() {
 // IncrementError increments the error count
 func (h *ErrorHandler) IncrementError() {
 	count := atomic.AddInt32(&h.errorCount, 1)
+	errorsTotal.Inc()
 	logger.Error(fmt.Sprintf("Error count: %d", count), "AntiCrash")
 }
 
 // HandlePanic handles a recovered panic and generates a crash dump
 func (h *ErrorHandler) HandlePanic(recovered interface{}) {
+	panicsTotal.Inc()
 	h.IncrementError()
 	logger.Debug("Unhandled Panic/Catch", "AntiCrash")
 
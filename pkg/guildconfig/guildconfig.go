@@ -0,0 +1,295 @@
+// Package guildconfig reads and patches the sections of a guild document that
+// the web dashboard can edit. Patches are JSON merge patches (RFC 7386): the
+// dashboard sends only the fields that changed, null resets a field, and arrays
+// are replaced whole. Fields the document doesn't have are rejected. Every
+// patched section is validated against the server before it is saved.
+package guildconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/bwmarrin/discordgo"
+)
+
+// Sections that can be read and patched
+const (
+	SectionGreetings  = "greetings"
+	SectionModeration = "moderation"
+	SectionProtection = "protection"
+	SectionLevels     = "levels"
+	SectionEmbeds     = "embeds"
+	SectionPingOnJoin = "pingOnJoin"
+)
+
+// Sections lists the editable sections in the order the dashboard shows them
+var Sections = []string{
+	SectionGreetings,
+	SectionModeration,
+	SectionProtection,
+	SectionLevels,
+	SectionEmbeds,
+	SectionPingOnJoin,
+}
+
+var (
+	ErrUnknownSection = errors.New("unknown section")
+	ErrInvalidConfig  = errors.New("invalid config")
+)
+
+// FieldError is a problem with one field, named by its JSON path like
+// "greetings.welcome.channel"
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every problem found in a patched section. It matches
+// ErrInvalidConfig with errors.Is.
+type ValidationError struct {
+	Problems []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		parts[i] = p.Field + ": " + p.Message
+	}
+	return ErrInvalidConfig.Error() + ": " + strings.Join(parts, "; ")
+}
+
+// Is makes errors.Is(err, ErrInvalidConfig) true
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidConfig
+}
+
+// Env is what validation knows about the server
+type Env struct {
+	Guild   *discordgo.Guild // Channels and roles must exist in it; nil skips those checks
+	Premium bool
+}
+
+// Known reports whether a section can be edited
+func Known(section string) bool {
+	return field(&models.GuildDocument{}, section) != nil
+}
+
+// Section returns the current value of a section
+func Section(doc *models.GuildDocument, section string) (interface{}, error) {
+	ptr := field(doc, section)
+	if ptr == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSection, section)
+	}
+	return reflect.ValueOf(ptr).Elem().Interface(), nil
+}
+
+// Patch applies a merge patch to a section of doc, validates the result and
+// returns the new value of the section. doc only changes when the patch is
+// valid.
+func Patch(doc *models.GuildDocument, section string, patch json.RawMessage, env Env) (interface{}, error) {
+	ptr := field(doc, section)
+	if ptr == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSection, section)
+	}
+
+	current, err := json.Marshal(ptr)
+	if err != nil {
+		return nil, err
+	}
+	var target, changes interface{}
+	if err := json.Unmarshal(current, &target); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patch, &changes); err != nil {
+		return nil, &ValidationError{Problems: []FieldError{{Field: section, Message: "el parche no es JSON válido"}}}
+	}
+	target = mergePatch(target, changes)
+	sectionType := reflect.TypeOf(ptr).Elem()
+	if path := unknownField(sectionType, target, section); path != "" {
+		return nil, &ValidationError{Problems: []FieldError{{Field: path, Message: "campo desconocido"}}}
+	}
+	merged, err := json.Marshal(target)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode into a fresh value so removed fields end up empty
+	value := reflect.New(sectionType)
+	if err := json.Unmarshal(merged, value.Interface()); err != nil {
+		return nil, &ValidationError{Problems: []FieldError{decodeProblem(section, err)}}
+	}
+
+	next := *doc
+	reflect.ValueOf(field(&next, section)).Elem().Set(value.Elem())
+	if err := validate(&next, doc, section, env); err != nil {
+		return nil, err
+	}
+	*doc = next
+	return value.Elem().Interface(), nil
+}
+
+// field returns a pointer to a section of doc, or nil for unknown sections
+func field(doc *models.GuildDocument, section string) interface{} {
+	switch section {
+	case SectionGreetings:
+		return &doc.Greetings
+	case SectionModeration:
+		return &doc.Moderation
+	case SectionProtection:
+		return &doc.Protection
+	case SectionLevels:
+		return &doc.Levels
+	case SectionEmbeds:
+		return &doc.Embeds
+	case SectionPingOnJoin:
+		return &doc.PingOnJoin
+	}
+	return nil
+}
+
+// mergePatch applies an RFC 7386 merge patch to target
+func mergePatch(target, patch interface{}) interface{} {
+	changes, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	doc, ok := target.(map[string]interface{})
+	if !ok {
+		doc = make(map[string]interface{})
+	}
+	for key, value := range changes {
+		if value == nil {
+			delete(doc, key)
+			continue
+		}
+		doc[key] = mergePatch(doc[key], value)
+	}
+	return doc
+}
+
+// unknownField returns the path of the first field of value that t doesn't
+// have, or "" when there is none
+func unknownField(t reflect.Type, value interface{}, path string) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			var fieldType reflect.Type
+			switch t.Kind() {
+			case reflect.Map:
+				fieldType = t.Elem()
+			case reflect.Struct:
+				fieldType = jsonField(t, key)
+			}
+			if fieldType == nil {
+				// Values of the wrong type are reported when decoding
+				if t.Kind() == reflect.Struct {
+					return path + "." + key
+				}
+				continue
+			}
+			if unknown := unknownField(fieldType, v[key], path+"."+key); unknown != "" {
+				return unknown
+			}
+		}
+	case []interface{}:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for i, item := range v {
+				if unknown := unknownField(t.Elem(), item, fmt.Sprintf("%s.%d", path, i)); unknown != "" {
+					return unknown
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// jsonField returns the type of the field of t named key in JSON
+func jsonField(t reflect.Type, key string) reflect.Type {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" {
+			name = field.Name
+		}
+		if name == key {
+			return field.Type
+		}
+	}
+	return nil
+}
+
+// decodeProblem names the field a decoding error is about
+func decodeProblem(section string, err error) FieldError {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		path := section
+		if typeErr.Field != "" {
+			path += "." + typeErr.Field
+		}
+		return FieldError{Field: path, Message: "debe ser de tipo " + jsonType(typeErr.Type)}
+	}
+	return FieldError{Field: section, Message: err.Error()}
+}
+
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "texto"
+	case reflect.Bool:
+		return "booleano"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "entero"
+	case reflect.Float32, reflect.Float64:
+		return "número"
+	case reflect.Slice, reflect.Array:
+		return "lista"
+	}
+	return "objeto"
+}
+
+// CanManage reports whether a member may change the configuration of the
+// server: its owner, administrators and members with Manage Guild
+func CanManage(guild *discordgo.Guild, userID string, roles []string) bool {
+	if guild == nil {
+		return false
+	}
+	if guild.OwnerID == userID {
+		return true
+	}
+
+	var permissions int64
+	for _, role := range guild.Roles {
+		// The @everyone role has the ID of the server
+		if role.ID == guild.ID || contains(roles, role.ID) {
+			permissions |= role.Permissions
+		}
+	}
+	return permissions&(discordgo.PermissionAdministrator|discordgo.PermissionManageGuild) != 0
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
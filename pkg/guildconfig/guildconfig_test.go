@@ -0,0 +1,113 @@
+package guildconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/bwmarrin/discordgo"
+)
+
+func testGuild() *discordgo.Guild {
+	return &discordgo.Guild{
+		ID:       "g",
+		OwnerID:  "owner",
+		Channels: []*discordgo.Channel{{ID: "welcome"}, {ID: "logs"}},
+		Roles: []*discordgo.Role{
+			{ID: "g"},
+			{ID: "member"},
+			{ID: "mod", Permissions: discordgo.PermissionManageGuild},
+		},
+	}
+}
+
+func problemFields(err error) []string {
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		return nil
+	}
+	fields := make([]string, len(verr.Problems))
+	for i, p := range verr.Problems {
+		fields[i] = p.Field
+	}
+	return fields
+}
+
+func TestPatchMergesIntoTheSection(t *testing.T) {
+	doc := models.NewDefaultGuildDocument("g")
+	doc.Greetings.Welcome.Message = "Hola {user}"
+	env := Env{Guild: testGuild()}
+
+	value, err := Patch(doc, SectionGreetings, json.RawMessage(`{"welcome":{"enable":true,"channel":"welcome"}}`), env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	greetings := value.(models.Greetings)
+	if !greetings.Welcome.Enable || greetings.Welcome.Channel != "welcome" || greetings.Welcome.Message != "Hola {user}" {
+		t.Errorf("Expected the patch merged with the current values, got %+v", greetings.Welcome)
+	}
+	if doc.Greetings.Welcome.Channel != "welcome" {
+		t.Error("Expected the document to be updated")
+	}
+
+	// null resets a field
+	if _, err := Patch(doc, SectionGreetings, json.RawMessage(`{"welcome":{"message":null}}`), env); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Greetings.Welcome.Message != "" || doc.Greetings.Welcome.Channel != "welcome" {
+		t.Errorf("Expected only the message reset, got %+v", doc.Greetings.Welcome)
+	}
+}
+
+func TestPatchRejectsInvalidFields(t *testing.T) {
+	doc := models.NewDefaultGuildDocument("g")
+	env := Env{Guild: testGuild()}
+
+	for name, tc := range map[string]struct {
+		section, patch string
+		field          string
+	}{
+		"missing channel": {SectionGreetings, `{"welcome":{"enable":true,"channel":"nope"}}`, "greetings.welcome.channel"},
+		"wrong type":      {SectionGreetings, `{"welcome":{"enable":"yes"}}`, "greetings.welcome.enable"},
+		"unknown field":   {SectionModeration, `{"logs":{"warns":{"colour":1}}}`, "moderation.logs.warns.colour"},
+		"enum":            {SectionProtection, `{"antiraid":{"action":"mute"}}`, "protection.antiraid.action"},
+		"premium":         {SectionGreetings, `{"card":{"customBackground":"https://x/y.png"}}`, "greetings.card.customBackground"},
+		"duplicated id":   {SectionEmbeds, `[{"id":"a","name":"A"},{"id":"a","name":"B"}]`, "embeds.1.id"},
+		"required role":   {SectionPingOnJoin, `[{"channelId":"welcome"}]`, "pingOnJoin.0.roleId"},
+	} {
+		before := *doc
+		_, err := Patch(doc, tc.section, json.RawMessage(tc.patch), env)
+		if !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("%s: expected an invalid config, got %v", name, err)
+			continue
+		}
+		if fields := problemFields(err); len(fields) != 1 || fields[0] != tc.field {
+			t.Errorf("%s: expected a problem with %s, got %v", name, tc.field, fields)
+		}
+		if doc.Greetings.Welcome.Channel != before.Greetings.Welcome.Channel || len(doc.Embeds) != len(before.Embeds) {
+			t.Errorf("%s: the document changed on a rejected patch", name)
+		}
+	}
+
+	if _, err := Patch(doc, "economy", json.RawMessage(`{}`), env); !errors.Is(err, ErrUnknownSection) {
+		t.Errorf("Expected an unknown section, got %v", err)
+	}
+}
+
+func TestCanManage(t *testing.T) {
+	guild := testGuild()
+	for _, tc := range []struct {
+		user  string
+		roles []string
+		want  bool
+	}{
+		{"owner", nil, true},
+		{"someone", []string{"member"}, false},
+		{"someone", []string{"member", "mod"}, true},
+	} {
+		if got := CanManage(guild, tc.user, tc.roles); got != tc.want {
+			t.Errorf("CanManage(%s, %v) = %v, want %v", tc.user, tc.roles, got, tc.want)
+		}
+	}
+}
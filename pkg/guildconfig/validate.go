@@ -0,0 +1,288 @@
+package guildconfig
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/imaging"
+	"github.com/PancyStudios/PancyBotGo/pkg/leveling"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/PancyStudios/PancyBotGo/pkg/templates"
+	"github.com/PancyStudios/PancyBotGo/pkg/verification"
+)
+
+const (
+	maxEmbeds     = 50
+	maxPingOnJoin = 25
+	maxColor      = 0xFFFFFF
+)
+
+// problems collects the field errors of a section
+type problems []FieldError
+
+func (p *problems) add(field, format string, args ...interface{}) {
+	*p = append(*p, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// channel checks that a channel exists in the server. Empty IDs pass unless the
+// field is required.
+func (p *problems) channel(env Env, field, id string, required bool) {
+	switch {
+	case id == "":
+		if required {
+			p.add(field, "es obligatorio")
+		}
+	case env.Guild != nil && !hasChannel(env, id):
+		p.add(field, "el canal no existe en el servidor")
+	}
+}
+
+// role checks that a role exists in the server
+func (p *problems) role(env Env, field, id string, required bool) {
+	switch {
+	case id == "":
+		if required {
+			p.add(field, "es obligatorio")
+		}
+	case env.Guild != nil && !hasRole(env, id):
+		p.add(field, "el rol no existe en el servidor")
+	}
+}
+
+func (p *problems) template(field, tpl string) {
+	if err := templates.Validate(tpl); err != nil {
+		p.add(field, "%s", err.Error())
+	}
+}
+
+func (p *problems) nonNegative(field string, value int) {
+	if value < 0 {
+		p.add(field, "no puede ser negativo")
+	}
+}
+
+func (p *problems) between(field string, value, min, max int) {
+	if value < min || value > max {
+		p.add(field, "debe estar entre %d y %d", min, max)
+	}
+}
+
+// sortedKeys keeps the problems of map fields in a stable order
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func hasChannel(env Env, id string) bool {
+	for _, channel := range env.Guild.Channels {
+		if channel.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func hasRole(env Env, id string) bool {
+	for _, role := range env.Guild.Roles {
+		if role.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// validate checks the patched section of next. prev is the document before
+// the patch, for the rules that depend on what changed.
+func validate(next, prev *models.GuildDocument, section string, env Env) error {
+	var p problems
+	switch section {
+	case SectionGreetings:
+		p.greetings(next, prev, env)
+	case SectionModeration:
+		p.moderation(next.Moderation, env)
+	case SectionProtection:
+		p.protection(next.Protection, env)
+	case SectionLevels:
+		p.levels(next.Levels, env)
+	case SectionEmbeds:
+		p.embeds(next.Embeds)
+	case SectionPingOnJoin:
+		p.pingOnJoin(next.PingOnJoin, env)
+	}
+	if len(p) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: p}
+}
+
+func (p *problems) greetings(next, prev *models.GuildDocument, env Env) {
+	g := next.Greetings
+	hasEmbed := func(id string) bool {
+		for _, embed := range next.Embeds {
+			if embed.ID == id {
+				return true
+			}
+		}
+		return false
+	}
+
+	p.channel(env, "greetings.welcome.channel", g.Welcome.Channel, g.Welcome.Enable && !g.Welcome.IsDM)
+	p.template("greetings.welcome.message", g.Welcome.Message)
+	if g.Welcome.EmbedID != "" && !hasEmbed(g.Welcome.EmbedID) {
+		p.add("greetings.welcome.embedId", "el embed no existe")
+	}
+
+	p.channel(env, "greetings.farewell.channel", g.Farewell.Channel, g.Farewell.Enable)
+	p.template("greetings.farewell.message", g.Farewell.Message)
+	if g.Farewell.EmbedID != "" && !hasEmbed(g.Farewell.EmbedID) {
+		p.add("greetings.farewell.embedId", "el embed no existe")
+	}
+
+	if g.Autorole.Enable && len(g.Autorole.Roles) == 0 {
+		p.add("greetings.autorole.roles", "es obligatorio con el autorol activado")
+	}
+	for i, role := range g.Autorole.Roles {
+		p.role(env, fmt.Sprintf("greetings.autorole.roles.%d", i), role, true)
+	}
+	p.nonNegative("greetings.autorole.delay", g.Autorole.Delay)
+
+	card := g.Card
+	if card.Background != "" && !imaging.IsValidBackground(card.Background) {
+		p.add("greetings.card.background", "fondo desconocido, usa %s", strings.Join(imaging.Backgrounds(), ", "))
+	}
+	if card.Font != "" && !imaging.IsValidFont(card.Font) {
+		p.add("greetings.card.font", "fuente desconocida, usa %s", strings.Join(imaging.Fonts(), ", "))
+	}
+	p.between("greetings.card.accentColor", card.AccentColor, 0, maxColor)
+	if card.CustomBackground != "" && card.CustomBackground != prev.Greetings.Card.CustomBackground {
+		switch {
+		case !strings.HasPrefix(card.CustomBackground, "https://"):
+			p.add("greetings.card.customBackground", "la URL debe empezar por https://")
+		case !env.Premium:
+			p.add("greetings.card.customBackground", "los fondos personalizados son exclusivos de servidores Premium")
+		}
+	}
+}
+
+func (p *problems) moderation(m models.ModeratorData, env Env) {
+	for _, log := range []struct {
+		name   string
+		config models.LogChannelConfig
+	}{{"warns", m.Logs.Warns}, {"mutes", m.Logs.Mutes}, {"kicks", m.Logs.Kicks}, {"bans", m.Logs.Bans}} {
+		p.channel(env, "moderation.logs."+log.name+".channel", log.config.Channel, log.config.Enable)
+	}
+	p.role(env, "moderation.dataModeration.muterole", m.DataModeration.MuteRole, false)
+
+	actions := m.Automoderator.Actions
+	for i, warns := range actions.Warns {
+		p.nonNegative(fmt.Sprintf("moderation.automoderator.actions.warns.%d", i), warns)
+	}
+	for i, minutes := range actions.MuteTime {
+		p.nonNegative(fmt.Sprintf("moderation.automoderator.actions.muteTime.%d", i), minutes)
+	}
+	p.nonNegative("moderation.automoderator.actions.floodDetect", actions.FloodDetect)
+	p.nonNegative("moderation.automoderator.actions.manyEmojis", actions.ManyEmojis)
+	p.nonNegative("moderation.automoderator.actions.manyPings", actions.ManyPings)
+	p.nonNegative("moderation.automoderator.actions.manyWords", actions.ManyWords)
+}
+
+func (p *problems) protection(pr models.ProtectionConfig, env Env) {
+	switch pr.Antibots.Type {
+	case "", "all", "only_nv", "only_v", "disabled":
+	default:
+		p.add("protection.antibots._type", "debe ser all, only_nv, only_v o disabled")
+	}
+
+	raid := pr.AntiRaid
+	if raid.Action != "" && raid.Action != "kick" && raid.Action != "ban" {
+		p.add("protection.antiraid.action", "debe ser kick o ban")
+	}
+	p.nonNegative("protection.antiraid.amount", raid.Amount)
+	p.between("protection.antiraid.minAccountAgeDays", raid.MinAccountAgeDays, 0, 365)
+	p.nonNegative("protection.antiraid.joinLimit", raid.JoinLimit)
+	p.nonNegative("protection.antiraid.timeWindow", raid.TimeWindow)
+	p.nonNegative("protection.antitokens.entritiesCount", pr.AntiTokens.EntritiesCount)
+	p.nonNegative("protection.purgeWebhooksAttacks.amount", pr.PurgeWebhooksAttacks.Amount)
+
+	v := pr.Verification
+	if v.Type != "" && !verification.IsValidType(v.Type) {
+		p.add("protection.verification._type", "debe ser button, captcha, math o web")
+	}
+	p.channel(env, "protection.verification.channel", v.Channel, v.Enable)
+	p.role(env, "protection.verification.role", v.Role, v.Enable)
+	p.between("protection.verification.minAccountAgeDays", v.MinAccountAgeDays, 0, 365)
+	p.between("protection.verification.maxAttempts", v.MaxAttempts, 0, 10)
+	p.between("protection.verification.timeoutMinutes", v.TimeoutMinutes, 0, 1440)
+}
+
+func (p *problems) levels(l models.LevelsConfig, env Env) {
+	if err := leveling.Validate(l); err != nil {
+		p.add("levels", "%s", leveling.Explain(err))
+	}
+	p.channel(env, "levels.levelUpChannel", l.LevelUpChannel, false)
+	p.template("levels.levelUpMessage", l.LevelUpMessage)
+
+	for i, reward := range l.Rewards {
+		if reward.Level < 1 {
+			p.add(fmt.Sprintf("levels.rewards.%d.level", i), "debe ser al menos 1")
+		}
+		p.role(env, fmt.Sprintf("levels.rewards.%d.roleId", i), reward.RoleID, true)
+	}
+	for _, id := range sortedKeys(l.RoleMultipliers) {
+		p.role(env, "levels.roleMultipliers."+id, id, true)
+	}
+	for _, id := range sortedKeys(l.ChannelMultipliers) {
+		p.channel(env, "levels.channelMultipliers."+id, id, true)
+	}
+	for i, id := range l.NoXPChannels {
+		p.channel(env, fmt.Sprintf("levels.noXpChannels.%d", i), id, true)
+	}
+	for i, id := range l.NoXPRoles {
+		p.role(env, fmt.Sprintf("levels.noXpRoles.%d", i), id, true)
+	}
+}
+
+func (p *problems) embeds(embeds []models.CustomEmbed) {
+	if len(embeds) > maxEmbeds {
+		p.add("embeds", "no puede haber más de %d embeds", maxEmbeds)
+	}
+	seen := make(map[string]bool, len(embeds))
+	for i, embed := range embeds {
+		prefix := fmt.Sprintf("embeds.%d", i)
+		switch {
+		case embed.ID == "":
+			p.add(prefix+".id", "es obligatorio")
+		case seen[embed.ID]:
+			p.add(prefix+".id", "ya hay otro embed con el ID %s", embed.ID)
+		}
+		seen[embed.ID] = true
+		if strings.TrimSpace(embed.Name) == "" {
+			p.add(prefix+".name", "es obligatorio")
+		}
+		p.between(prefix+".color", embed.Color, 0, maxColor)
+		if err := templates.ValidateEmbed(embed); err != nil {
+			p.add(prefix, "%s", err.Error())
+		}
+	}
+}
+
+func (p *problems) pingOnJoin(pings []models.PingOnJoinConfig, env Env) {
+	if len(pings) > maxPingOnJoin {
+		p.add("pingOnJoin", "no puede haber más de %d avisos", maxPingOnJoin)
+	}
+	seen := make(map[models.PingOnJoinConfig]bool, len(pings))
+	for i, ping := range pings {
+		prefix := fmt.Sprintf("pingOnJoin.%d", i)
+		p.channel(env, prefix+".channelId", ping.ChannelID, true)
+		p.role(env, prefix+".roleId", ping.RoleID, true)
+		if seen[ping] {
+			p.add(prefix, "el aviso está repetido")
+		}
+		seen[ping] = true
+	}
+}
@@ -0,0 +1,126 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"math/rand"
+	"strings"
+)
+
+// Captcha canvas size in pixels
+const (
+	CaptchaWidth  = 260
+	CaptchaHeight = 100
+)
+
+// captchaAlphabet excludes characters that are easy to confuse (0/O, 1/I/L, 5/S, 2/Z, 8/B)
+const captchaAlphabet = "ACDEFGHJKMNPQRTUVWXY34679"
+
+// NewCaptchaCode returns a random code of the given length using unambiguous characters
+func NewCaptchaCode(length int) string {
+	var sb strings.Builder
+	for i := 0; i < length; i++ {
+		sb.WriteByte(captchaAlphabet[rand.Intn(len(captchaAlphabet))])
+	}
+	return sb.String()
+}
+
+// RenderCaptcha renders the code as a distorted PNG with noise lines and dots
+func RenderCaptcha(code string) ([]byte, error) {
+	bounds := image.Rect(0, 0, CaptchaWidth, CaptchaHeight)
+
+	// Background with a soft vertical gradient
+	canvas := image.NewRGBA(bounds)
+	for y := 0; y < CaptchaHeight; y++ {
+		shade := uint8(235 - y*30/CaptchaHeight)
+		draw.Draw(canvas, image.Rect(0, y, CaptchaWidth, y+1), image.NewUniform(color.RGBA{shade, shade, 245, 255}), image.Point{}, draw.Src)
+	}
+
+	// Background noise
+	for i := 0; i < 700; i++ {
+		canvas.Set(rand.Intn(CaptchaWidth), rand.Intn(CaptchaHeight), randomColor(120, 220))
+	}
+
+	// Render every character on its own layer with random offset and colour
+	text := image.NewRGBA(bounds)
+	scale := 6
+	step := (GlyphWidth + 2) * scale
+	startX := (CaptchaWidth - step*len(code)) / 2
+	for i, r := range code {
+		x := startX + i*step + rand.Intn(7) - 3
+		y := (CaptchaHeight-TextHeight(scale))/2 + rand.Intn(17) - 8
+		DrawGlyph(text, x, y, r, scale, randomColor(20, 110))
+	}
+
+	// Wave + shear distortion of the text layer
+	ampX, ampY := 3+rand.Float64()*3, 4+rand.Float64()*4
+	periodX, periodY := 14+rand.Float64()*10, 30+rand.Float64()*20
+	phaseX, phaseY := rand.Float64()*math.Pi*2, rand.Float64()*math.Pi*2
+	shear := (rand.Float64() - 0.5) * 0.4
+	for y := 0; y < CaptchaHeight; y++ {
+		for x := 0; x < CaptchaWidth; x++ {
+			sx := float64(x) + ampX*math.Sin(float64(y)/periodX+phaseX) + shear*float64(y-CaptchaHeight/2)
+			sy := float64(y) + ampY*math.Sin(float64(x)/periodY+phaseY)
+			ix, iy := int(sx), int(sy)
+			if ix < 0 || iy < 0 || ix >= CaptchaWidth || iy >= CaptchaHeight {
+				continue
+			}
+			if c := text.RGBAAt(ix, iy); c.A > 0 {
+				canvas.SetRGBA(x, y, c)
+			}
+		}
+	}
+
+	// Crossing lines drawn over the text
+	for i := 0; i < 6; i++ {
+		drawLine(canvas,
+			rand.Intn(CaptchaWidth/4), rand.Intn(CaptchaHeight),
+			CaptchaWidth-rand.Intn(CaptchaWidth/4), rand.Intn(CaptchaHeight),
+			randomColor(40, 160))
+	}
+
+	// Foreground speckles
+	for i := 0; i < 250; i++ {
+		canvas.Set(rand.Intn(CaptchaWidth), rand.Intn(CaptchaHeight), randomColor(30, 140))
+	}
+
+	return EncodePNG(canvas)
+}
+
+// randomColor returns an opaque colour whose channels are in [min, max)
+func randomColor(min, max int) color.RGBA {
+	ch := func() uint8 { return uint8(min + rand.Intn(max-min)) }
+	return color.RGBA{ch(), ch(), ch(), 255}
+}
+
+// drawLine draws a 2px line using Bresenham's algorithm
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, col color.RGBA) {
+	dx := int(math.Abs(float64(x1 - x0)))
+	dy := -int(math.Abs(float64(y1 - y0)))
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	e := dx + dy
+	for {
+		img.SetRGBA(x0, y0, col)
+		img.SetRGBA(x0, y0+1, col)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
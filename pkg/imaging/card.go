@@ -0,0 +1,397 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Card canvas size in pixels (welcome, farewell and rank cards share it)
+const (
+	CardWidth  = 900
+	CardHeight = 300
+)
+
+// Card layout
+const (
+	cardPadding     = 20
+	avatarCenterX   = 150
+	avatarCenterY   = CardHeight / 2
+	avatarRadius    = 100
+	avatarRing      = 6
+	cardTextX       = 290
+	cardTextMaxX    = CardWidth - cardPadding - 20
+	cardTextMaxSize = cardTextMaxX - cardTextX
+)
+
+// DefaultAccentColor is used when the guild has not picked one
+const DefaultAccentColor = 0x5865F2
+
+// DefaultBackground and DefaultFont are the preset names used when none is configured
+const (
+	DefaultBackground = "default"
+	DefaultFont       = "classic"
+)
+
+// backgrounds are the preset vertical gradients available to every guild
+var backgrounds = map[string][2]color.RGBA{
+	"default": {{0x23, 0x27, 0x2A, 0xFF}, {0x2C, 0x2F, 0x33, 0xFF}},
+	"night":   {{0x0F, 0x20, 0x27, 0xFF}, {0x2C, 0x53, 0x64, 0xFF}},
+	"sunset":  {{0xFF, 0x5F, 0x6D, 0xFF}, {0xFF, 0xC3, 0x71, 0xFF}},
+	"ocean":   {{0x21, 0x93, 0xB0, 0xFF}, {0x6D, 0xD5, 0xED, 0xFF}},
+	"forest":  {{0x13, 0x4E, 0x5E, 0xFF}, {0x71, 0xB2, 0x80, 0xFF}},
+	"candy":   {{0xD5, 0x33, 0x69, 0xFF}, {0xDA, 0xAE, 0x51, 0xFF}},
+}
+
+// fonts are the available text styles for the built-in bitmap font
+var fonts = map[string]bool{
+	"classic": true,
+	"bold":    true,
+	"shadow":  true,
+}
+
+// Backgrounds returns the names of the preset backgrounds
+func Backgrounds() []string {
+	names := make([]string, 0, len(backgrounds))
+	for name := range backgrounds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Fonts returns the names of the available font styles
+func Fonts() []string {
+	names := make([]string, 0, len(fonts))
+	for name := range fonts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsValidBackground reports whether name is a preset background
+func IsValidBackground(name string) bool {
+	_, ok := backgrounds[name]
+	return ok
+}
+
+// IsValidFont reports whether name is an available font style
+func IsValidFont(name string) bool {
+	return fonts[name]
+}
+
+// ParseHexColor parses colours like "#5865F2", "5865F2" or "0x5865F2"
+func ParseHexColor(s string) (int, error) {
+	s = strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(strings.TrimSpace(s)), "#"), "0x")
+	if len(s) != 6 {
+		return 0, fmt.Errorf("color inválido: %q", s)
+	}
+	var value int
+	if _, err := fmt.Sscanf(s, "%06x", &value); err != nil {
+		return 0, fmt.Errorf("color inválido: %q", s)
+	}
+	return value, nil
+}
+
+// CardStyle describes how a card is painted
+type CardStyle struct {
+	Background string
+	Font       string
+	Accent     int
+	// CustomBackground replaces the preset background when set
+	CustomBackground image.Image
+}
+
+// WelcomeCard holds the content of a welcome or farewell card
+type WelcomeCard struct {
+	Title    string
+	Username string
+	Subtitle string
+	Avatar   image.Image
+}
+
+// RankCard holds the content of a level card
+type RankCard struct {
+	Username string
+	Avatar   image.Image
+	Level    int64
+	XP       int64
+	// LevelXP and NextLevelXP are the total XP at the start of the current and next level
+	LevelXP       int64
+	NextLevelXP   int64
+	Rank          int
+	TotalMessages int64
+	VoiceTime     string // Empty when the user was never in voice
+}
+
+// RenderWelcomeCard renders a welcome/farewell card as PNG
+func RenderWelcomeCard(card WelcomeCard, style CardStyle) ([]byte, error) {
+	canvas := newCardCanvas(style)
+	accent := intToRGBA(style.Accent)
+	white := color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}
+	grey := color.RGBA{0xDD, 0xDD, 0xDD, 0xFF}
+
+	drawAvatar(canvas, card.Avatar, accent)
+
+	title := FitText(card.Title, 6, cardTextMaxSize)
+	drawStyledText(canvas, cardTextX, 60, title, 6, white, style.Font)
+
+	username := FitText(card.Username, 5, cardTextMaxSize)
+	drawStyledText(canvas, cardTextX, 135, username, 5, accent, style.Font)
+
+	subtitle := FitText(card.Subtitle, 3, cardTextMaxSize)
+	drawStyledText(canvas, cardTextX, 210, subtitle, 3, grey, style.Font)
+
+	return EncodePNG(canvas)
+}
+
+// RenderRankCard renders a level card with a progress bar as PNG
+func RenderRankCard(card RankCard, style CardStyle) ([]byte, error) {
+	canvas := newCardCanvas(style)
+	accent := intToRGBA(style.Accent)
+	white := color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}
+	grey := color.RGBA{0xBB, 0xBB, 0xBB, 0xFF}
+
+	drawAvatar(canvas, card.Avatar, accent)
+
+	username := FitText(card.Username, 5, cardTextMaxSize)
+	drawStyledText(canvas, cardTextX, 45, username, 5, white, style.Font)
+
+	levelText := fmt.Sprintf("NIVEL %d", card.Level)
+	drawStyledText(canvas, cardTextX, 110, levelText, 4, accent, style.Font)
+	if card.Rank > 0 {
+		rankText := fmt.Sprintf("#%d", card.Rank)
+		drawStyledText(canvas, cardTextMaxX-TextWidth(rankText, 4), 110, rankText, 4, white, style.Font)
+	}
+
+	xpText := fmt.Sprintf("%d / %d XP", card.XP, card.NextLevelXP)
+	drawStyledText(canvas, cardTextMaxX-TextWidth(xpText, 2), 160, xpText, 2, grey, style.Font)
+
+	// Progress bar inside the current level
+	progress := 0.0
+	if span := card.NextLevelXP - card.LevelXP; span > 0 {
+		progress = float64(card.XP-card.LevelXP) / float64(span)
+	}
+	progress = math.Max(0, math.Min(1, progress))
+
+	bar := image.Rect(cardTextX, 180, cardTextMaxX, 214)
+	fillRoundedRect(canvas, bar, bar.Dy()/2, color.NRGBA{0x00, 0x00, 0x00, 0xA0})
+	if filled := int(float64(bar.Dx()) * progress); filled > 0 {
+		if filled < bar.Dy() {
+			filled = bar.Dy()
+		}
+		fillRoundedRect(canvas, image.Rect(bar.Min.X, bar.Min.Y, bar.Min.X+filled, bar.Max.Y), bar.Dy()/2, accent)
+	}
+
+	percentText := fmt.Sprintf("%d%%", int(progress*100))
+	drawStyledText(canvas, cardTextX, 230, percentText, 2, grey, style.Font)
+	messagesText := fmt.Sprintf("MENSAJES: %d", card.TotalMessages)
+	if card.VoiceTime != "" {
+		messagesText += "  VOZ: " + card.VoiceTime
+	}
+	drawStyledText(canvas, cardTextMaxX-TextWidth(messagesText, 2), 230, messagesText, 2, grey, style.Font)
+
+	return EncodePNG(canvas)
+}
+
+// newCardCanvas paints the background and the translucent content panel
+func newCardCanvas(style CardStyle) *image.RGBA {
+	bounds := image.Rect(0, 0, CardWidth, CardHeight)
+	canvas := image.NewRGBA(bounds)
+
+	if style.CustomBackground != nil {
+		drawCover(canvas, style.CustomBackground)
+	} else {
+		gradient, ok := backgrounds[style.Background]
+		if !ok {
+			gradient = backgrounds[DefaultBackground]
+		}
+		top, bottom := gradient[0], gradient[1]
+		for y := 0; y < CardHeight; y++ {
+			t := float64(y) / float64(CardHeight-1)
+			row := color.RGBA{
+				lerp(top.R, bottom.R, t),
+				lerp(top.G, bottom.G, t),
+				lerp(top.B, bottom.B, t),
+				0xFF,
+			}
+			draw.Draw(canvas, image.Rect(0, y, CardWidth, y+1), image.NewUniform(row), image.Point{}, draw.Src)
+		}
+	}
+
+	panel := image.Rect(cardPadding, cardPadding, CardWidth-cardPadding, CardHeight-cardPadding)
+	fillRoundedRect(canvas, panel, 24, color.NRGBA{0x00, 0x00, 0x00, 0x78})
+	return canvas
+}
+
+// FitBackground scales and crops an image to the card size, so a custom
+// background can be kept in memory and drawn without scaling it again
+func FitBackground(src image.Image) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, CardWidth, CardHeight))
+	drawCover(dst, src)
+	return dst
+}
+
+// drawCover scales src to cover the whole canvas, cropping the overflow
+func drawCover(dst *image.RGBA, src image.Image) {
+	sb := src.Bounds()
+	if sb.Dx() == 0 || sb.Dy() == 0 {
+		return
+	}
+	scale := math.Max(float64(CardWidth)/float64(sb.Dx()), float64(CardHeight)/float64(sb.Dy()))
+	offX := (float64(sb.Dx())*scale - CardWidth) / 2
+	offY := (float64(sb.Dy())*scale - CardHeight) / 2
+
+	for y := 0; y < CardHeight; y++ {
+		sy := sb.Min.Y + int((float64(y)+offY)/scale)
+		for x := 0; x < CardWidth; x++ {
+			sx := sb.Min.X + int((float64(x)+offX)/scale)
+			dst.Set(x, y, src.At(sx, sy))
+		}
+	}
+}
+
+// drawAvatar draws the avatar clipped to a circle with an accent ring
+func drawAvatar(dst *image.RGBA, avatar image.Image, accent color.RGBA) {
+	fillCircle(dst, avatarCenterX, avatarCenterY, float64(avatarRadius+avatarRing), accent)
+
+	if avatar == nil {
+		fillCircle(dst, avatarCenterX, avatarCenterY, avatarRadius, color.RGBA{0x40, 0x44, 0x4B, 0xFF})
+		return
+	}
+
+	size := avatarRadius * 2
+	scaled := resizeBox(avatar, size, size)
+	r := float64(avatarRadius)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dx, dy := float64(x)-r+0.5, float64(y)-r+0.5
+			coverage := r - math.Sqrt(dx*dx+dy*dy) + 0.5
+			if coverage <= 0 {
+				continue
+			}
+			c := scaled.RGBAAt(x, y)
+			px, py := avatarCenterX-avatarRadius+x, avatarCenterY-avatarRadius+y
+			blend(dst, px, py, color.NRGBA{c.R, c.G, c.B, uint8(float64(c.A) * math.Min(1, coverage))})
+		}
+	}
+}
+
+// resizeBox scales src to w×h averaging the source pixels under each destination pixel
+func resizeBox(src image.Image, w, h int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	sb := src.Bounds()
+	sx, sy := float64(sb.Dx())/float64(w), float64(sb.Dy())/float64(h)
+
+	for y := 0; y < h; y++ {
+		y0 := sb.Min.Y + int(float64(y)*sy)
+		y1 := sb.Min.Y + int(math.Max(float64(y+1)*sy, float64(y)*sy+1))
+		for x := 0; x < w; x++ {
+			x0 := sb.Min.X + int(float64(x)*sx)
+			x1 := sb.Min.X + int(math.Max(float64(x+1)*sx, float64(x)*sx+1))
+
+			var r, g, b, a, n uint32
+			for yy := y0; yy < y1 && yy < sb.Max.Y; yy++ {
+				for xx := x0; xx < x1 && xx < sb.Max.X; xx++ {
+					cr, cg, cb, ca := src.At(xx, yy).RGBA()
+					r, g, b, a, n = r+cr, g+cg, b+cb, a+ca, n+1
+				}
+			}
+			if n == 0 {
+				continue
+			}
+			dst.SetRGBA(x, y, color.RGBA{uint8(r / n >> 8), uint8(g / n >> 8), uint8(b / n >> 8), uint8(a / n >> 8)})
+		}
+	}
+	return dst
+}
+
+// drawStyledText draws text using one of the font styles
+func drawStyledText(dst *image.RGBA, x, y int, text string, scale int, col color.RGBA, font string) {
+	text = strings.ToUpper(text)
+	switch font {
+	case "bold":
+		thickness := scale / 3
+		if thickness < 1 {
+			thickness = 1
+		}
+		for i := 0; i <= thickness; i++ {
+			DrawText(dst, x+i, y, text, scale, col)
+		}
+	case "shadow":
+		offset := scale / 2
+		if offset < 1 {
+			offset = 1
+		}
+		DrawText(dst, x+offset, y+offset, text, scale, color.NRGBA{0x00, 0x00, 0x00, 0xB0})
+		DrawText(dst, x, y, text, scale, col)
+	default:
+		DrawText(dst, x, y, text, scale, col)
+	}
+}
+
+// fillCircle draws an anti-aliased filled circle
+func fillCircle(dst *image.RGBA, cx, cy int, radius float64, col color.RGBA) {
+	r := int(math.Ceil(radius))
+	for y := -r; y <= r; y++ {
+		for x := -r; x <= r; x++ {
+			coverage := radius - math.Sqrt(float64(x*x+y*y)) + 0.5
+			if coverage <= 0 {
+				continue
+			}
+			blend(dst, cx+x, cy+y, color.NRGBA{col.R, col.G, col.B, uint8(float64(col.A) * math.Min(1, coverage))})
+		}
+	}
+}
+
+// fillRoundedRect draws a filled rectangle with rounded corners
+func fillRoundedRect(dst *image.RGBA, rect image.Rectangle, radius int, col color.Color) {
+	nc := color.NRGBAModel.Convert(col).(color.NRGBA)
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			// Distance from the nearest corner centre when inside a corner square
+			cx := clampInt(x, rect.Min.X+radius, rect.Max.X-radius-1)
+			cy := clampInt(y, rect.Min.Y+radius, rect.Max.Y-radius-1)
+			dx, dy := float64(x-cx), float64(y-cy)
+			coverage := float64(radius) - math.Sqrt(dx*dx+dy*dy) + 0.5
+			if coverage <= 0 {
+				continue
+			}
+			blend(dst, x, y, color.NRGBA{nc.R, nc.G, nc.B, uint8(float64(nc.A) * math.Min(1, coverage))})
+		}
+	}
+}
+
+// blend paints a non-premultiplied colour over the pixel at (x, y)
+func blend(dst *image.RGBA, x, y int, c color.NRGBA) {
+	if !(image.Point{X: x, Y: y}.In(dst.Bounds())) || c.A == 0 {
+		return
+	}
+	draw.Draw(dst, image.Rect(x, y, x+1, y+1), image.NewUniform(c), image.Point{}, draw.Over)
+}
+
+func intToRGBA(v int) color.RGBA {
+	return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 0xFF}
+}
+
+func lerp(a, b uint8, t float64) uint8 {
+	return uint8(float64(a) + (float64(b)-float64(a))*t)
+}
+
+func clampInt(v, min, max int) int {
+	if max < min {
+		return (min + max) / 2
+	}
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
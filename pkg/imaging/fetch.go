@@ -0,0 +1,53 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif"  // Register GIF decoder (animated avatars)
+	_ "image/jpeg" // Register JPEG decoder (custom backgrounds)
+	"io"
+	"net/http"
+	"time"
+)
+
+// maxImageBytes bounds remote downloads so a huge background cannot exhaust memory
+const maxImageBytes = 8 << 20
+
+// maxImageSide bounds the decoded size, since a small file can declare a huge image
+const maxImageSide = 4096
+
+var httpClient = &http.Client{Timeout: 5 * time.Second}
+
+// FetchImage downloads and decodes a PNG, JPEG or GIF image (first frame)
+func FetchImage(url string) (image.Image, error) {
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("estado HTTP %d al descargar %s", resp.StatusCode, url)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the declared size before allocating the pixels
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo decodificar la imagen: %w", err)
+	}
+	if cfg.Width > maxImageSide || cfg.Height > maxImageSide {
+		return nil, fmt.Errorf("imagen demasiado grande (%dx%d, máximo %dx%d)", cfg.Width, cfg.Height, maxImageSide, maxImageSide)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo decodificar la imagen: %w", err)
+	}
+	return img, nil
+}
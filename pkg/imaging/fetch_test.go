@@ -0,0 +1,47 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// pngWithSize encodes a 1x1 PNG and rewrites its header to declare another size
+func pngWithSize(t *testing.T, width, height uint32) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	// Signature (8), chunk length (4), "IHDR" (4), width (4), height (4), ...
+	binary.BigEndian.PutUint32(data[16:], width)
+	binary.BigEndian.PutUint32(data[20:], height)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+	return data
+}
+
+func TestFetchImageRejectsHugeImages(t *testing.T) {
+	images := map[string][]byte{
+		"/small.png": pngWithSize(t, 1, 1),
+		"/huge.png":  pngWithSize(t, 50000, 50000),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(images[r.URL.Path])
+	}))
+	defer server.Close()
+
+	if _, err := FetchImage(server.URL + "/small.png"); err != nil {
+		t.Errorf("FetchImage() of a small image: %v", err)
+	}
+	_, err := FetchImage(server.URL + "/huge.png")
+	if err == nil || !strings.Contains(err.Error(), "demasiado grande") {
+		t.Errorf("Expected the huge image to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+// Package imaging provides PNG rendering helpers (captchas, cards) built only on
+// the standard library, so no external font or graphics dependencies are needed.
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+	"unicode"
+)
+
+// Glyph dimensions of the built-in bitmap font (in font pixels)
+const (
+	GlyphWidth   = 5
+	GlyphHeight  = 7
+	GlyphSpacing = 1
+)
+
+// glyphs is a classic 5x7 bitmap font. Each row is a 5 character mask where '1' is ink.
+var glyphs = map[rune][GlyphHeight]string{
+	'A':  {"01110", "10001", "10001", "11111", "10001", "10001", "10001"},
+	'B':  {"11110", "10001", "10001", "11110", "10001", "10001", "11110"},
+	'C':  {"01110", "10001", "10000", "10000", "10000", "10001", "01110"},
+	'D':  {"11100", "10010", "10001", "10001", "10001", "10010", "11100"},
+	'E':  {"11111", "10000", "10000", "11110", "10000", "10000", "11111"},
+	'F':  {"11111", "10000", "10000", "11110", "10000", "10000", "10000"},
+	'G':  {"01110", "10001", "10000", "10111", "10001", "10001", "01111"},
+	'H':  {"10001", "10001", "10001", "11111", "10001", "10001", "10001"},
+	'I':  {"01110", "00100", "00100", "00100", "00100", "00100", "01110"},
+	'J':  {"00111", "00010", "00010", "00010", "00010", "10010", "01100"},
+	'K':  {"10001", "10010", "10100", "11000", "10100", "10010", "10001"},
+	'L':  {"10000", "10000", "10000", "10000", "10000", "10000", "11111"},
+	'M':  {"10001", "11011", "10101", "10101", "10001", "10001", "10001"},
+	'N':  {"10001", "10001", "11001", "10101", "10011", "10001", "10001"},
+	'O':  {"01110", "10001", "10001", "10001", "10001", "10001", "01110"},
+	'P':  {"11110", "10001", "10001", "11110", "10000", "10000", "10000"},
+	'Q':  {"01110", "10001", "10001", "10001", "10101", "10010", "01101"},
+	'R':  {"11110", "10001", "10001", "11110", "10100", "10010", "10001"},
+	'S':  {"01111", "10000", "10000", "01110", "00001", "00001", "11110"},
+	'T':  {"11111", "00100", "00100", "00100", "00100", "00100", "00100"},
+	'U':  {"10001", "10001", "10001", "10001", "10001", "10001", "01110"},
+	'V':  {"10001", "10001", "10001", "10001", "10001", "01010", "00100"},
+	'W':  {"10001", "10001", "10001", "10101", "10101", "10101", "01010"},
+	'X':  {"10001", "10001", "01010", "00100", "01010", "10001", "10001"},
+	'Y':  {"10001", "10001", "10001", "01010", "00100", "00100", "00100"},
+	'Z':  {"11111", "00001", "00010", "00100", "01000", "10000", "11111"},
+	'0':  {"01110", "10001", "10011", "10101", "11001", "10001", "01110"},
+	'1':  {"00100", "01100", "00100", "00100", "00100", "00100", "01110"},
+	'2':  {"01110", "10001", "00001", "00010", "00100", "01000", "11111"},
+	'3':  {"11111", "00010", "00100", "00010", "00001", "10001", "01110"},
+	'4':  {"00010", "00110", "01010", "10010", "11111", "00010", "00010"},
+	'5':  {"11111", "10000", "11110", "00001", "00001", "10001", "01110"},
+	'6':  {"00110", "01000", "10000", "11110", "10001", "10001", "01110"},
+	'7':  {"11111", "00001", "00010", "00100", "01000", "01000", "01000"},
+	'8':  {"01110", "10001", "10001", "01110", "10001", "10001", "01110"},
+	'9':  {"01110", "10001", "10001", "01111", "00001", "00010", "01100"},
+	' ':  {"00000", "00000", "00000", "00000", "00000", "00000", "00000"},
+	'!':  {"00100", "00100", "00100", "00100", "00100", "00000", "00100"},
+	'?':  {"01110", "10001", "00001", "00010", "00100", "00000", "00100"},
+	'.':  {"00000", "00000", "00000", "00000", "00000", "01100", "01100"},
+	',':  {"00000", "00000", "00000", "00000", "01100", "00100", "01000"},
+	'-':  {"00000", "00000", "00000", "11111", "00000", "00000", "00000"},
+	'_':  {"00000", "00000", "00000", "00000", "00000", "00000", "11111"},
+	':':  {"00000", "01100", "01100", "00000", "01100", "01100", "00000"},
+	'/':  {"00000", "00001", "00010", "00100", "01000", "10000", "00000"},
+	'#':  {"01010", "01010", "11111", "01010", "11111", "01010", "01010"},
+	'%':  {"11000", "11001", "00010", "00100", "01000", "10011", "00011"},
+	'+':  {"00000", "00100", "00100", "11111", "00100", "00100", "00000"},
+	'=':  {"00000", "00000", "11111", "00000", "11111", "00000", "00000"},
+	'@':  {"01110", "10001", "00001", "01101", "10101", "10101", "01110"},
+	'\'': {"01100", "00100", "01000", "00000", "00000", "00000", "00000"},
+	'(':  {"00010", "00100", "01000", "01000", "01000", "00100", "00010"},
+	')':  {"01000", "00100", "00010", "00010", "00010", "00100", "01000"},
+}
+
+// accentFold maps accented characters to the base glyph that represents them
+var accentFold = map[rune]rune{
+	'Á': 'A', 'À': 'A', 'Â': 'A', 'Ä': 'A', 'Ã': 'A',
+	'É': 'E', 'È': 'E', 'Ê': 'E', 'Ë': 'E',
+	'Í': 'I', 'Ì': 'I', 'Î': 'I', 'Ï': 'I',
+	'Ó': 'O', 'Ò': 'O', 'Ô': 'O', 'Ö': 'O', 'Õ': 'O',
+	'Ú': 'U', 'Ù': 'U', 'Û': 'U', 'Ü': 'U',
+	'Ñ': 'N', 'Ç': 'C', '¡': '!', '¿': '?',
+}
+
+// glyphFor returns the bitmap for a rune, folding case and accents and falling back to '?'
+func glyphFor(r rune) [GlyphHeight]string {
+	r = unicode.ToUpper(r)
+	if folded, ok := accentFold[r]; ok {
+		r = folded
+	}
+	if g, ok := glyphs[r]; ok {
+		return g
+	}
+	return glyphs['?']
+}
+
+// TextWidth returns the width in pixels of text rendered at the given scale
+func TextWidth(text string, scale int) int {
+	n := len([]rune(text))
+	if n == 0 {
+		return 0
+	}
+	return (n*(GlyphWidth+GlyphSpacing) - GlyphSpacing) * scale
+}
+
+// TextHeight returns the height in pixels of a line rendered at the given scale
+func TextHeight(scale int) int {
+	return GlyphHeight * scale
+}
+
+// DrawGlyph draws a single rune with its top-left corner at (x, y)
+func DrawGlyph(dst draw.Image, x, y int, r rune, scale int, col color.Color) {
+	g := glyphFor(r)
+	for row := 0; row < GlyphHeight; row++ {
+		for c := 0; c < GlyphWidth; c++ {
+			if g[row][c] != '1' {
+				continue
+			}
+			rect := image.Rect(x+c*scale, y+row*scale, x+(c+1)*scale, y+(row+1)*scale)
+			draw.Draw(dst, rect, image.NewUniform(col), image.Point{}, draw.Over)
+		}
+	}
+}
+
+// DrawText draws text with its top-left corner at (x, y)
+func DrawText(dst draw.Image, x, y int, text string, scale int, col color.Color) {
+	for _, r := range text {
+		DrawGlyph(dst, x, y, r, scale, col)
+		x += (GlyphWidth + GlyphSpacing) * scale
+	}
+}
+
+// DrawTextCentered draws text horizontally centered around centerX
+func DrawTextCentered(dst draw.Image, centerX, y int, text string, scale int, col color.Color) {
+	DrawText(dst, centerX-TextWidth(text, scale)/2, y, text, scale, col)
+}
+
+// FitText truncates text with "..." so that it fits in maxWidth at the given scale
+func FitText(text string, scale, maxWidth int) string {
+	if TextWidth(text, scale) <= maxWidth {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		candidate := strings.TrimSpace(string(runes)) + "..."
+		if TextWidth(candidate, scale) <= maxWidth {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// EncodePNG encodes an image as PNG bytes
+func EncodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,161 @@
+package invites
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/bwmarrin/discordgo"
+)
+
+// Session is the subset of *discordgo.Session used to hand out rewards and log joins
+type Session interface {
+	GuildMemberRoleAdd(guildID, userID, roleID string, options ...discordgo.RequestOption) error
+	ChannelMessageSendEmbed(channelID string, embed *discordgo.MessageEmbed, options ...discordgo.RequestOption) (*discordgo.Message, error)
+}
+
+// DefaultFakeAccountDays is used when the guild leaves FakeAccountDays empty
+const DefaultFakeAccountDays = 7
+
+// IsFake reports whether a join should not count for the inviter: self invites
+// and accounts younger than the configured age
+func IsFake(cfg models.InvitesConfig, user *discordgo.User, inviterID string) bool {
+	if inviterID == "" {
+		return false
+	}
+	if inviterID == user.ID {
+		return true
+	}
+
+	days := cfg.FakeAccountDays
+	if days <= 0 {
+		days = DefaultFakeAccountDays
+	}
+	createdAt, err := discordgo.SnowflakeTimestamp(user.ID)
+	if err != nil {
+		return false
+	}
+	return time.Since(createdAt) < time.Duration(days)*24*time.Hour
+}
+
+// InviterText is the value of the {inviter} placeholder for an attribution
+func InviterText(att Attribution) string {
+	inviterID := ""
+	if att.Inviter != nil {
+		inviterID = att.Inviter.ID
+	}
+	return inviterText(att.Source, inviterID)
+}
+
+// JoinInviterText is the value of the {inviter} placeholder for a stored join
+func JoinInviterText(join *models.InviteJoin) string {
+	if join == nil {
+		return ""
+	}
+	return inviterText(join.Source, join.InviterID)
+}
+
+func inviterText(source, inviterID string) string {
+	switch source {
+	case models.InviteSourceInvite:
+		if inviterID != "" {
+			return fmt.Sprintf("<@%s>", inviterID)
+		}
+	case models.InviteSourceVanity:
+		return "la URL personalizada"
+	}
+	return ""
+}
+
+// RecordJoin stores the attribution, credits the inviter, hands out milestone
+// rewards and logs the join in the invites log channel
+func RecordJoin(s Session, guildDoc *models.GuildDocument, user *discordgo.User, att Attribution) {
+	cfg := guildDoc.Invites
+	join := &models.InviteJoin{
+		GuildID:  guildDoc.ID,
+		MemberID: user.ID,
+		Code:     att.Code,
+		Source:   att.Source,
+		JoinedAt: time.Now(),
+	}
+	if att.Inviter != nil {
+		join.InviterID = att.Inviter.ID
+	}
+	join.Fake = IsFake(cfg, user, join.InviterID)
+
+	stats, err := database.RecordInviteJoin(join)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("No se pudo registrar la invitación de %s en %s: %v", user.ID, guildDoc.ID, err), "Invites")
+	}
+
+	if stats != nil && !join.Fake {
+		giveRewards(s, guildDoc, stats)
+	}
+
+	logJoin(s, guildDoc, user, att, join, stats)
+}
+
+// RecordLeave charges the leave to the inviter of the member and returns how it
+// had joined (nil when the join was not tracked)
+func RecordLeave(guildDoc *models.GuildDocument, user *discordgo.User) *models.InviteJoin {
+	join, err := database.RecordInviteLeave(guildDoc.ID, user.ID)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("No se pudo registrar la salida de %s en %s: %v", user.ID, guildDoc.ID, err), "Invites")
+	}
+	return join
+}
+
+// giveRewards hands out the roles of the milestone the inviter just reached
+func giveRewards(s Session, guildDoc *models.GuildDocument, stats *models.InviteStats) {
+	total := stats.Total()
+	for _, reward := range guildDoc.Invites.Rewards {
+		if reward.Invites != total || reward.RoleID == "" {
+			continue
+		}
+		if err := s.GuildMemberRoleAdd(guildDoc.ID, stats.UserID, reward.RoleID); err != nil {
+			logger.Error(fmt.Sprintf("No se pudo asignar el rol de invitaciones %s a %s: %v", reward.RoleID, stats.UserID, err), "Invites")
+		} else {
+			logger.Info(fmt.Sprintf("Rol %s asignado a %s por llegar a %d invitaciones", reward.RoleID, stats.UserID, total), "Invites")
+		}
+	}
+}
+
+func logJoin(s Session, guildDoc *models.GuildDocument, user *discordgo.User, att Attribution, join *models.InviteJoin, stats *models.InviteStats) {
+	channelID := guildDoc.Invites.LogChannel
+	if channelID == "" {
+		return
+	}
+
+	source := "❓ Desconocido"
+	switch att.Source {
+	case models.InviteSourceInvite:
+		source = fmt.Sprintf("🔗 `%s` (%d usos)", att.Code, att.Uses)
+	case models.InviteSourceVanity:
+		source = fmt.Sprintf("✨ URL personalizada `%s` (%d usos)", att.Code, att.Uses)
+	}
+
+	embed := discord.NewEmbed().
+		SetTitle("📨 Nuevo miembro").
+		SetColor(discord.ColorInfo).
+		SetThumbnail(user.AvatarURL("128")).
+		AddField("Miembro", fmt.Sprintf("<@%s> (`%s`)", user.ID, user.ID), true).
+		AddField("Origen", source, true)
+
+	if join.InviterID != "" {
+		inviter := fmt.Sprintf("<@%s>", join.InviterID)
+		if stats != nil {
+			inviter += fmt.Sprintf("\n**%d** invitaciones", stats.Total())
+		}
+		embed.AddField("Invitado por", inviter, true)
+	}
+	if join.Fake {
+		embed.SetColor(discord.ColorWarning).AddField("⚠️ Invitación falsa", "La cuenta es demasiado reciente o se invitó a sí misma.", false)
+	}
+
+	if _, err := s.ChannelMessageSendEmbed(channelID, embed.Build()); err != nil {
+		logger.Debug(fmt.Sprintf("No se pudo enviar el log de invitaciones a %s: %v", channelID, err), "Invites")
+	}
+}
@@ -0,0 +1,200 @@
+// Package invites keeps a cache of the guild invites and works out which invite a
+// new member used by comparing the use counters before and after the join.
+package invites
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/bwmarrin/discordgo"
+)
+
+// Fetcher is the subset of *discordgo.Session needed to read invites
+type Fetcher interface {
+	GuildInvites(guildID string, options ...discordgo.RequestOption) ([]*discordgo.Invite, error)
+	RequestWithBucketID(method, urlStr string, data interface{}, bucketID string, options ...discordgo.RequestOption) ([]byte, error)
+}
+
+// deletedTTL is how long a deleted invite is remembered. Discord deletes an invite
+// as soon as it reaches its max uses, sometimes before the member join arrives.
+const deletedTTL = 30 * time.Second
+
+// Attribution is the result of matching a join with an invite
+type Attribution struct {
+	Source  string // models.InviteSource*
+	Code    string
+	Inviter *discordgo.User
+	Uses    int
+}
+
+type cachedInvite struct {
+	inviter   *discordgo.User
+	uses      int
+	maxUses   int
+	deletedAt time.Time
+}
+
+type guildInvites struct {
+	mu         sync.Mutex
+	loaded     bool
+	invites    map[string]*cachedInvite
+	vanityCode string
+	vanityUses int
+	noVanity   bool // the vanity endpoint failed once (no vanity URL or no access), skip it
+}
+
+var (
+	guilds   = make(map[string]*guildInvites)
+	guildsMu sync.Mutex
+)
+
+// nowFn is replaced in tests
+var nowFn = time.Now
+
+func guildCache(guildID string) *guildInvites {
+	guildsMu.Lock()
+	defer guildsMu.Unlock()
+	g, ok := guilds[guildID]
+	if !ok {
+		g = &guildInvites{invites: make(map[string]*cachedInvite)}
+		guilds[guildID] = g
+	}
+	return g
+}
+
+// Load fetches and caches the current invites of a guild
+func Load(f Fetcher, guildID string) error {
+	g := guildCache(guildID)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.noVanity = false
+	return g.refresh(f, guildID)
+}
+
+// Forget drops the cached invites of a guild (e.g. when the bot leaves it)
+func Forget(guildID string) {
+	guildsMu.Lock()
+	defer guildsMu.Unlock()
+	delete(guilds, guildID)
+}
+
+// Created adds a new invite to the cache
+func Created(inv *discordgo.InviteCreate) {
+	if inv == nil || inv.Invite == nil {
+		return
+	}
+	g := guildCache(inv.GuildID)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.invites[inv.Code] = &cachedInvite{inviter: inv.Inviter, uses: inv.Uses, maxUses: inv.MaxUses}
+}
+
+// Deleted marks an invite as deleted; it is kept for a while in case a join used it last
+func Deleted(guildID, code string) {
+	g := guildCache(guildID)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if cached, ok := g.invites[code]; ok {
+		cached.deletedAt = nowFn()
+	}
+}
+
+// Attribute works out which invite was used by the member that just joined and
+// refreshes the cache. Joins of the same guild are attributed one at a time.
+func Attribute(f Fetcher, guildID string) (Attribution, error) {
+	g := guildCache(guildID)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	unknown := Attribution{Source: models.InviteSourceUnknown}
+	if !g.loaded {
+		// Without a previous snapshot there is nothing to compare with
+		return unknown, g.refresh(f, guildID)
+	}
+
+	previous := g.invites
+	previousVanity := g.vanityUses
+	if err := g.refresh(f, guildID); err != nil {
+		return unknown, err
+	}
+
+	var candidates []Attribution
+	for code, current := range g.invites {
+		before, ok := previous[code]
+		beforeUses := 0
+		if ok {
+			beforeUses = before.uses
+		}
+		if current.uses > beforeUses {
+			candidates = append(candidates, Attribution{Source: models.InviteSourceInvite, Code: code, Inviter: current.inviter, Uses: current.uses})
+		}
+	}
+
+	// Invites that disappeared right after reaching their last use
+	now := nowFn()
+	for code, before := range previous {
+		if _, still := g.invites[code]; still {
+			continue
+		}
+		usedUp := before.maxUses > 0 && before.uses+1 == before.maxUses
+		stale := !before.deletedAt.IsZero() && now.Sub(before.deletedAt) > deletedTTL
+		if usedUp && !stale {
+			candidates = append(candidates, Attribution{Source: models.InviteSourceInvite, Code: code, Inviter: before.inviter, Uses: before.maxUses})
+		}
+	}
+
+	if len(candidates) == 1 {
+		return candidates[0], nil
+	}
+	if len(candidates) == 0 && g.vanityCode != "" && g.vanityUses > previousVanity {
+		return Attribution{Source: models.InviteSourceVanity, Code: g.vanityCode, Uses: g.vanityUses}, nil
+	}
+	// Several invites moved at the same time (or none did): we cannot tell
+	return unknown, nil
+}
+
+// refresh replaces the snapshot with the current invites; g.mu must be held
+func (g *guildInvites) refresh(f Fetcher, guildID string) error {
+	list, err := f.GuildInvites(guildID)
+	if err != nil {
+		return err
+	}
+
+	invites := make(map[string]*cachedInvite, len(list))
+	for _, inv := range list {
+		invites[inv.Code] = &cachedInvite{inviter: inv.Inviter, uses: inv.Uses, maxUses: inv.MaxUses}
+	}
+	g.invites = invites
+	g.loaded = true
+
+	if !g.noVanity {
+		code, uses, err := vanity(f, guildID)
+		if err != nil || code == "" {
+			g.noVanity = true
+		} else {
+			g.vanityCode, g.vanityUses = code, uses
+		}
+	}
+	return nil
+}
+
+// vanity reads the vanity URL of a guild. discordgo has no helper for this endpoint.
+func vanity(f Fetcher, guildID string) (string, int, error) {
+	endpoint := discordgo.EndpointGuild(guildID) + "/vanity-url"
+	body, err := f.RequestWithBucketID("GET", endpoint, nil, endpoint)
+	if err != nil {
+		return "", 0, err
+	}
+
+	var data struct {
+		Code string `json:"code"`
+		Uses int    `json:"uses"`
+	}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return "", 0, fmt.Errorf("decoding vanity url: %w", err)
+	}
+	return data.Code, data.Uses, nil
+}
@@ -0,0 +1,179 @@
+// Package items holds the registry of item effects. Every effect declares the item
+// type it belongs to and the parameter it reads from the item, so the shop admin can
+// validate new items and /use, /work, /crime, /rob and the level system agree on
+// what an item does.
+package items
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+)
+
+// Effect IDs stored in models.Item.Effect
+const (
+	EffectNone        = "NONE"
+	EffectExpandBank  = "EXPAND_BANK"
+	EffectStarTicket  = "STAR_TICKET"
+	EffectLocalTicket = "LOCAL_TICKET"
+	EffectGiveRole    = "GIVE_ROLE"
+	EffectWorkBoost   = "BUFF_WORK"
+	EffectRobShield   = "BUFF_SHIELD"
+	EffectXPBoost     = "BUFF_XP"
+	EffectWorkTool    = "TOOL_WORK"
+	EffectCrimeTool   = "TOOL_CRIME"
+	EffectBadge       = "BADGE"
+)
+
+// Param is the kind of value an effect reads from models.Item.EffectValue
+type Param int
+
+const (
+	ParamNone       Param = iota
+	ParamAmount           // Whole amount of coins, stars or bank capacity
+	ParamMultiplier       // Factor above 1, e.g. 1.5
+	ParamPercent          // Percentage between 1 and 100
+)
+
+// Scope restricts the economy an effect can be used in
+type Scope int
+
+const (
+	ScopeAny Scope = iota
+	ScopeLocal
+	ScopeGlobal
+)
+
+var (
+	ErrUnknownEffect   = errors.New("unknown item effect")
+	ErrInvalidParam    = errors.New("invalid effect value")
+	ErrMissingDuration = errors.New("effect needs a duration")
+	ErrMissingRole     = errors.New("effect needs a role")
+	ErrWrongScope      = errors.New("effect not available in this economy")
+)
+
+// Effect describes what an item does
+type Effect struct {
+	ID    string
+	Name  string // Shown in the shop admin
+	Type  models.ItemType
+	Param Param
+	Scope Scope
+	// Timed effects read models.Item.Duration; buffs need it, roles use it optionally
+	Timed            bool
+	DurationRequired bool
+	// Passive effects are never consumed: they work while the item is owned
+	Passive bool
+
+	use useFunc
+}
+
+var registry = make(map[string]*Effect)
+
+// Register adds an effect to the registry
+func Register(e *Effect) {
+	registry[e.ID] = e
+}
+
+// Get returns a registered effect
+func Get(id string) (*Effect, bool) {
+	e, ok := registry[id]
+	return e, ok
+}
+
+// All returns the registered effects sorted by ID
+func All() []*Effect {
+	effects := make([]*Effect, 0, len(registry))
+	for _, e := range registry {
+		effects = append(effects, e)
+	}
+	sort.Slice(effects, func(i, j int) bool { return effects[i].ID < effects[j].ID })
+	return effects
+}
+
+// Of returns the effect of an item. Items created before the registry only carry
+// their type, so role and badge items map to their effect by type.
+func Of(item models.Item) *Effect {
+	if e, ok := registry[item.Effect]; ok && e.ID != EffectNone {
+		return e
+	}
+	switch {
+	case item.Type == models.ItemTypeRole && item.RoleID != "":
+		return registry[EffectGiveRole]
+	case item.Type == models.ItemTypeBadge:
+		return registry[EffectBadge]
+	}
+	return registry[EffectNone]
+}
+
+// Validate checks that an item carries the parameters its effect needs
+func Validate(item models.Item) error {
+	e, ok := Get(item.Effect)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownEffect, item.Effect)
+	}
+
+	switch e.Param {
+	case ParamAmount:
+		if item.EffectValue < 1 || item.EffectValue != float64(int64(item.EffectValue)) {
+			return fmt.Errorf("%w: debe ser un número entero positivo", ErrInvalidParam)
+		}
+	case ParamMultiplier:
+		if item.EffectValue <= 1 || item.EffectValue > 10 {
+			return fmt.Errorf("%w: debe ser un multiplicador entre 1 y 10 (ej. 1.5)", ErrInvalidParam)
+		}
+	case ParamPercent:
+		if item.EffectValue < 1 || item.EffectValue > 100 {
+			return fmt.Errorf("%w: debe ser un porcentaje entre 1 y 100", ErrInvalidParam)
+		}
+	}
+
+	if item.Duration < 0 || (!e.Timed && item.Duration > 0) {
+		return fmt.Errorf("%w: este efecto no usa duración", ErrInvalidParam)
+	}
+	if e.DurationRequired && item.Duration == 0 {
+		return ErrMissingDuration
+	}
+	if e.ID == EffectGiveRole && item.RoleID == "" {
+		return ErrMissingRole
+	}
+	if (e.Scope == ScopeLocal && item.IsGlobal) || (e.Scope == ScopeGlobal && !item.IsGlobal) {
+		return ErrWrongScope
+	}
+	return nil
+}
+
+func init() {
+	Register(&Effect{ID: EffectNone, Name: "Ninguno", Type: models.ItemTypeCollectible, use: useNothing})
+	Register(&Effect{ID: EffectExpandBank, Name: "Expandir Banco", Type: models.ItemTypeConsumable, Param: ParamAmount, use: useInstant})
+	Register(&Effect{ID: EffectStarTicket, Name: "Ticket de Estrellas", Type: models.ItemTypeConsumable, Param: ParamAmount, Scope: ScopeGlobal, use: useInstant})
+	Register(&Effect{ID: EffectLocalTicket, Name: "Ticket de Monedas", Type: models.ItemTypeConsumable, Param: ParamAmount, Scope: ScopeLocal, use: useInstant})
+	Register(&Effect{ID: EffectGiveRole, Name: "Otorgar Rol", Type: models.ItemTypeRole, Scope: ScopeLocal, Timed: true, use: useRole})
+	Register(&Effect{ID: EffectWorkBoost, Name: "Multiplicador de Trabajo", Type: models.ItemTypeConsumable, Param: ParamMultiplier, Timed: true, DurationRequired: true, use: useBuff})
+	Register(&Effect{ID: EffectRobShield, Name: "Escudo Anti-Robo", Type: models.ItemTypeConsumable, Timed: true, DurationRequired: true, use: useBuff})
+	Register(&Effect{ID: EffectXPBoost, Name: "Multiplicador de XP", Type: models.ItemTypeConsumable, Param: ParamMultiplier, Timed: true, DurationRequired: true, use: useBuff})
+	Register(&Effect{ID: EffectWorkTool, Name: "Herramienta de Trabajo", Type: models.ItemTypeTool, Param: ParamPercent, Passive: true})
+	Register(&Effect{ID: EffectCrimeTool, Name: "Herramienta de Crimen", Type: models.ItemTypeTool, Param: ParamPercent, Passive: true})
+	Register(&Effect{ID: EffectBadge, Name: "Emblema", Type: models.ItemTypeBadge, Passive: true})
+}
+
+// Explain turns a validation error into a message for the shop admin
+func Explain(err error) string {
+	switch {
+	case errors.Is(err, ErrUnknownEffect):
+		return "ese efecto no existe."
+	case errors.Is(err, ErrMissingDuration):
+		return "este efecto necesita una duración en minutos."
+	case errors.Is(err, ErrMissingRole):
+		return "indica el rol que otorga el objeto."
+	case errors.Is(err, ErrWrongScope):
+		return "este efecto no está disponible en esta tienda."
+	case errors.Is(err, ErrInvalidParam):
+		return strings.TrimPrefix(err.Error(), ErrInvalidParam.Error()+": ")
+	default:
+		return err.Error()
+	}
+}
@@ -0,0 +1,71 @@
+package items
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		item models.Item
+		want error
+	}{
+		{"plain collectible", models.Item{Effect: EffectNone}, nil},
+		{"bank amount", models.Item{Effect: EffectExpandBank, EffectValue: 500}, nil},
+		{"fractional amount", models.Item{Effect: EffectExpandBank, EffectValue: 2.5}, ErrInvalidParam},
+		{"buff without duration", models.Item{Effect: EffectWorkBoost, EffectValue: 1.5}, ErrMissingDuration},
+		{"buff", models.Item{Effect: EffectWorkBoost, EffectValue: 1.5, Duration: 60}, nil},
+		{"multiplier too low", models.Item{Effect: EffectXPBoost, EffectValue: 1, Duration: 60}, ErrInvalidParam},
+		{"tool percent", models.Item{Effect: EffectCrimeTool, EffectValue: 15}, nil},
+		{"tool with duration", models.Item{Effect: EffectCrimeTool, EffectValue: 15, Duration: 5}, ErrInvalidParam},
+		{"role without role", models.Item{Effect: EffectGiveRole}, ErrMissingRole},
+		{"global role", models.Item{Effect: EffectGiveRole, RoleID: "1", IsGlobal: true}, ErrWrongScope},
+		{"timed role", models.Item{Effect: EffectGiveRole, RoleID: "1", Duration: 1440}, nil},
+		{"unknown", models.Item{Effect: "FLY"}, ErrUnknownEffect},
+	}
+
+	for _, tt := range tests {
+		if err := Validate(tt.item); !errors.Is(err, tt.want) {
+			t.Errorf("%s: Validate() = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestOfLegacyItems(t *testing.T) {
+	if got := Of(models.Item{Type: models.ItemTypeRole, RoleID: "1"}).ID; got != EffectGiveRole {
+		t.Errorf("role item effect = %s, want %s", got, EffectGiveRole)
+	}
+	if got := Of(models.Item{Type: models.ItemTypeBadge, Effect: EffectNone}).ID; got != EffectBadge {
+		t.Errorf("badge item effect = %s, want %s", got, EffectBadge)
+	}
+	if got := Of(models.Item{}).ID; got != EffectNone {
+		t.Errorf("empty item effect = %s, want %s", got, EffectNone)
+	}
+}
+
+func TestGear(t *testing.T) {
+	gear := Gear{
+		ToolCheck: ToolCheck{Bonus: 20},
+		Buffs:     models.Buffs{EffectWorkBoost: {Value: 1.5, ExpiresAt: time.Now().Add(time.Hour)}},
+	}
+	if got := gear.Boost(100, EffectWorkBoost); got != 180 {
+		t.Errorf("Boost() = %d, want 180", got)
+	}
+
+	gear.Buffs[EffectWorkBoost] = models.ActiveBuff{Value: 1.5, ExpiresAt: time.Now().Add(-time.Minute)}
+	if got := gear.Boost(100, EffectWorkBoost); got != 120 {
+		t.Errorf("Boost() with expired buff = %d, want 120", got)
+	}
+
+	if got := gear.Chance(0.40, 0.90); got < 0.599 || got > 0.601 {
+		t.Errorf("Chance() = %v, want 0.60", got)
+	}
+	gear.Bonus = 80
+	if got := gear.Chance(0.40, 0.90); got != 0.90 {
+		t.Errorf("Chance() = %v, want capped 0.90", got)
+	}
+}
@@ -0,0 +1,195 @@
+package items
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Multiplier returns the value of an active multiplier buff, or 1
+func Multiplier(buffs models.Buffs, effect string) float64 {
+	if buff, ok := buffs.Active(effect); ok && buff.Value > 1 {
+		return buff.Value
+	}
+	return 1
+}
+
+// BestMultiplier returns the strongest active multiplier of a user across the local
+// and the global economy profiles. It never creates profiles, so it is cheap to call
+// for users that don't use the economy.
+func BestMultiplier(guildID, userID, effect string) float64 {
+	best := 1.0
+	if database.LocalEconomyDM == nil || database.GlobalEconomyDM == nil {
+		return best
+	}
+	if local, err := database.LocalEconomyDM.Get(bson.M{"_id": fmt.Sprintf("%s_%s", guildID, userID)}); err == nil && local != nil {
+		best = Multiplier(local.Buffs, effect)
+	}
+	if global, err := database.GlobalEconomyDM.Get(bson.M{"_id": userID}); err == nil && global != nil {
+		if m := Multiplier(global.Buffs, effect); m > best {
+			best = m
+		}
+	}
+	return best
+}
+
+// Shielded reports whether a profile is protected from robberies
+func Shielded(buffs models.Buffs) bool {
+	_, ok := buffs.Active(EffectRobShield)
+	return ok
+}
+
+// DescribeBuff renders an active buff
+func DescribeBuff(effect string, value float64) string {
+	switch effect {
+	case EffectWorkBoost:
+		return fmt.Sprintf("💼 Trabajo x%g", value)
+	case EffectXPBoost:
+		return fmt.Sprintf("📈 XP x%g", value)
+	case EffectRobShield:
+		return "🛡️ Escudo anti-robo"
+	}
+	return effect
+}
+
+// DescribeBuffs lists the active buffs of a profile, one per line
+func DescribeBuffs(buffs models.Buffs) string {
+	effects := make([]string, 0, len(buffs))
+	for effect := range buffs {
+		effects = append(effects, effect)
+	}
+	sort.Strings(effects)
+
+	var lines []string
+	for _, effect := range effects {
+		if buff, ok := buffs.Active(effect); ok {
+			lines = append(lines, fmt.Sprintf("%s · termina <t:%d:R>", DescribeBuff(effect, buff.Value), buff.ExpiresAt.Unix()))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ToolCheck is what the tools of a user mean for a command
+type ToolCheck struct {
+	Bonus   float64      // Percent of the best owned tool
+	Tool    *models.Item // Best owned tool, nil if none
+	Missing *models.Item // Set when the shop sells a required tool and the user owns none
+}
+
+// CheckTools looks for the tools of an effect in the shop of an economy and in the
+// inventory of the user. Owning any tool of the effect unlocks a gated command.
+func CheckTools(guildID string, global bool, inventory map[string]int, effect string) (ToolCheck, error) {
+	catalog, err := database.GetItems(guildID)
+	if err != nil {
+		return ToolCheck{}, err
+	}
+
+	var check ToolCheck
+	var required *models.Item
+	for i := range catalog {
+		item := catalog[i]
+		if item.Effect != effect || item.IsGlobal != global {
+			continue
+		}
+		if item.Required && required == nil {
+			required = &item
+		}
+		if inventory[item.ID] > 0 && item.EffectValue >= check.Bonus {
+			check.Bonus = item.EffectValue
+			check.Tool = &item
+		}
+	}
+	if check.Tool == nil {
+		check.Missing = required
+	}
+	return check, nil
+}
+
+// Badges returns the badge items owned in an inventory, sorted by name
+func Badges(catalog []models.Item, inventory map[string]int) []models.Item {
+	var badges []models.Item
+	for _, item := range catalog {
+		if inventory[item.ID] > 0 && Of(item).ID == EffectBadge {
+			badges = append(badges, item)
+		}
+	}
+	sort.Slice(badges, func(i, j int) bool { return badges[i].Name < badges[j].Name })
+	return badges
+}
+
+// Gear is what a user brings to an economy command: the tools for it and the buffs
+// of the profile of that economy
+type Gear struct {
+	ToolCheck
+	Buffs models.Buffs
+}
+
+// LoadGear reads the profile of an economy and checks the tools of an effect
+func LoadGear(guildID, userID string, global bool, toolEffect string) (Gear, error) {
+	var gear Gear
+	var inventory map[string]int
+	if global {
+		profile, err := database.GetGlobalProfile(userID)
+		if err != nil {
+			return gear, err
+		}
+		inventory, gear.Buffs = profile.Inventory, profile.Buffs
+	} else {
+		profile, err := database.GetLocalProfile(guildID, userID)
+		if err != nil {
+			return gear, err
+		}
+		inventory, gear.Buffs = profile.Inventory, profile.Buffs
+	}
+
+	check, err := CheckTools(guildID, global, inventory, toolEffect)
+	if err != nil {
+		return gear, err
+	}
+	gear.ToolCheck = check
+	return gear, nil
+}
+
+// Boost applies the tool bonus and a multiplier buff to an amount
+func (g Gear) Boost(amount int64, buffEffect string) int64 {
+	return int64(float64(amount) * (1 + g.Bonus/100) * Multiplier(g.Buffs, buffEffect))
+}
+
+// Note describes the tool and buff that improved a command, empty if none did
+func (g Gear) Note(buffEffect string) string {
+	var parts []string
+	if g.Tool != nil {
+		parts = append(parts, fmt.Sprintf("%s %s +%g%%", g.Tool.Emoji, g.Tool.Name, g.Bonus))
+	}
+	if buffEffect != "" {
+		if m := Multiplier(g.Buffs, buffEffect); m > 1 {
+			parts = append(parts, DescribeBuff(buffEffect, m))
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, " · ") + ")"
+}
+
+// MissingText explains which tool a gated command needs
+func (g Gear) MissingText(action string) string {
+	return fmt.Sprintf("❌ Necesitas %s **%s** para %s. Cómpralo en la tienda con el ID `%s`.", g.Missing.Emoji, g.Missing.Name, action, g.Missing.ID)
+}
+
+// Chance adds the tool bonus, in percentage points, to a success chance. The bonus
+// never pushes the chance above max, but a base already above it is kept.
+func (g Gear) Chance(base, max float64) float64 {
+	if base > max {
+		max = base
+	}
+	chance := base + g.Bonus/100
+	if chance > max {
+		chance = max
+	}
+	return chance
+}
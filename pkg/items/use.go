@@ -0,0 +1,139 @@
+package items
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/bwmarrin/discordgo"
+)
+
+var (
+	ErrPassive   = errors.New("item works while it is in the inventory")
+	ErrRoleGrant = errors.New("could not grant the item role")
+)
+
+// Use is the context of an item being used
+type Use struct {
+	Session *discordgo.Session
+	GuildID string
+	UserID  string
+	Item    models.Item
+}
+
+type useFunc func(u *Use, e *Effect) (string, error)
+
+// Apply consumes one unit of the item and applies its effect. It returns the
+// message shown to the user. Passive items return ErrPassive and are kept.
+func (u *Use) Apply() (string, error) {
+	e := Of(u.Item)
+	if e.Passive || e.use == nil {
+		return "", ErrPassive
+	}
+	return e.use(u, e)
+}
+
+// consume removes one unit of the item together with any balance change
+func (u *Use) consume(change database.BalanceChange) error {
+	change.Items = map[string]int{u.Item.ID: -1}
+	info := database.TxInfo{Type: models.TransactionItemUse, Command: "use", Note: u.Item.Name}
+	if u.Item.IsGlobal {
+		_, err := database.ApplyStarsChange(u.UserID, change, info)
+		return err
+	}
+	_, err := database.ApplyLocalChange(u.GuildID, u.UserID, change, info)
+	return err
+}
+
+// refund gives the item back when its effect could not be applied
+func (u *Use) refund() {
+	change := database.BalanceChange{Items: map[string]int{u.Item.ID: 1}}
+	info := database.TxInfo{Type: models.TransactionRefund, Command: "use", Note: u.Item.Name}
+	if u.Item.IsGlobal {
+		_, _ = database.ApplyStarsChange(u.UserID, change, info)
+		return
+	}
+	_, _ = database.ApplyLocalChange(u.GuildID, u.UserID, change, info)
+}
+
+func (u *Use) duration() time.Duration {
+	return time.Duration(u.Item.Duration) * time.Minute
+}
+
+func useNothing(u *Use, _ *Effect) (string, error) {
+	if err := u.consume(database.BalanceChange{}); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("✨ Has usado **%s** pero no pareció tener ningún efecto especial.", u.Item.Name), nil
+}
+
+func useInstant(u *Use, e *Effect) (string, error) {
+	if (e.Scope == ScopeLocal && u.Item.IsGlobal) || (e.Scope == ScopeGlobal && !u.Item.IsGlobal) {
+		return "", ErrWrongScope
+	}
+
+	amount := int64(u.Item.EffectValue)
+	change := database.BalanceChange{}
+	var text string
+	switch e.ID {
+	case EffectExpandBank:
+		change.BankCapacity = amount
+		text = fmt.Sprintf("🏦 La capacidad de tu banco aumentó en **%d**.", amount)
+	default:
+		change.Wallet = amount
+		text = fmt.Sprintf("💰 Recibiste **%d** en tu cartera.", amount)
+	}
+
+	if err := u.consume(change); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("✨ Has usado **%s**. %s", u.Item.Name, text), nil
+}
+
+func useBuff(u *Use, e *Effect) (string, error) {
+	if err := u.consume(database.BalanceChange{}); err != nil {
+		return "", err
+	}
+
+	var expires time.Time
+	var err error
+	if u.Item.IsGlobal {
+		expires, err = database.AddStarsBuff(u.UserID, e.ID, u.Item.ID, u.Item.EffectValue, u.duration())
+	} else {
+		expires, err = database.AddLocalBuff(u.GuildID, u.UserID, e.ID, u.Item.ID, u.Item.EffectValue, u.duration())
+	}
+	if err != nil {
+		u.refund()
+		return "", err
+	}
+
+	return fmt.Sprintf("✨ Has usado **%s**. %s activo hasta <t:%d:R>.", u.Item.Name, DescribeBuff(e.ID, u.Item.EffectValue), expires.Unix()), nil
+}
+
+func useRole(u *Use, _ *Effect) (string, error) {
+	if u.Item.IsGlobal || u.Item.RoleID == "" || u.Session == nil {
+		return "", ErrWrongScope
+	}
+	if err := u.consume(database.BalanceChange{}); err != nil {
+		return "", err
+	}
+
+	if err := u.Session.GuildMemberRoleAdd(u.GuildID, u.UserID, u.Item.RoleID); err != nil {
+		u.refund()
+		return "", fmt.Errorf("%w: %v", ErrRoleGrant, err)
+	}
+
+	if u.Item.Duration > 0 {
+		expires, err := database.AddTimedRole(u.GuildID, u.UserID, u.Item.RoleID, u.Item.ID, u.duration())
+		if err != nil {
+			// Without a schedule the role would stay forever; take it back
+			_ = u.Session.GuildMemberRoleRemove(u.GuildID, u.UserID, u.Item.RoleID)
+			u.refund()
+			return "", err
+		}
+		return fmt.Sprintf("✅ Has usado **%s**. Tienes el rol <@&%s> hasta <t:%d:R>.", u.Item.Name, u.Item.RoleID, expires.Unix()), nil
+	}
+	return fmt.Sprintf("✅ Has usado **%s**. ¡Ahora tienes el rol <@&%s>!", u.Item.Name, u.Item.RoleID), nil
+}
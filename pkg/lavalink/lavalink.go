@@ -4,6 +4,7 @@ package lavalink
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -15,6 +16,7 @@ import (
 
 	"github.com/PancyStudios/PancyBotGo/pkg/logger"
 	"github.com/PancyStudios/PancyBotGo/pkg/mqtt"
+	"github.com/PancyStudios/PancyBotGo/pkg/tracing"
 	"github.com/bwmarrin/discordgo"
 	"github.com/gorilla/websocket"
 )
@@ -439,7 +441,7 @@ func (c *LavalinkClient) DestroyPlayer(guildID string) {
 		node.mu.RUnlock()
 
 		if isConnected {
-			if err := node.destroyPlayer(guildID); err != nil {
+			if err := node.destroyPlayer(context.Background(), guildID); err != nil {
 				logger.Error(fmt.Sprintf("Error destroying player: %v", err), "Lavalink")
 			}
 			break
@@ -449,6 +451,17 @@ func (c *LavalinkClient) DestroyPlayer(guildID string) {
 
 // Search searches for tracks
 func (c *LavalinkClient) Search(query string) (*SearchResult, error) {
+	return c.SearchContext(context.Background(), query)
+}
+
+// SearchContext searches for tracks as part of the trace carried by ctx
+func (c *LavalinkClient) SearchContext(ctx context.Context, query string) (result *SearchResult, err error) {
+	ctx, span := tracing.Start(ctx, "lavalink search", tracing.KindClient, tracing.String("lavalink.query", query))
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
 	for _, node := range c.nodes {
 		node.mu.RLock()
 		isConnected := node.connected
@@ -462,6 +475,7 @@ func (c *LavalinkClient) Search(query string) (*SearchResult, error) {
 		}
 
 		logger.Debug(fmt.Sprintf("Usando node %s para búsqueda: %s", nodeName, query), "Lavalink")
+		span.SetAttributes(tracing.String("lavalink.node", nodeName))
 
 		scheme := "http"
 		if config.Secure {
@@ -481,7 +495,7 @@ func (c *LavalinkClient) Search(query string) (*SearchResult, error) {
 
 		logger.Debug(fmt.Sprintf("URL de búsqueda: %s", url), "Lavalink")
 
-		req, err := http.NewRequest("GET", url, nil)
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 		if err != nil {
 			logger.Error(fmt.Sprintf("Error creando request HTTP: %v", err), "Lavalink")
 			continue
@@ -508,15 +522,16 @@ func (c *LavalinkClient) Search(query string) (*SearchResult, error) {
 			continue
 		}
 
-		var result SearchResult
-		if err := json.Unmarshal(bodyBytes, &result); err != nil {
+		var found SearchResult
+		if err := json.Unmarshal(bodyBytes, &found); err != nil {
 			logger.Error(fmt.Sprintf("Error decodificando respuesta JSON: %v", err), "Lavalink")
 			continue
 		}
 
-		tracks := result.GetTracks()
-		logger.Debug(fmt.Sprintf("LoadType: %s, Tracks encontrados: %d", result.LoadType, len(tracks)), "Lavalink")
-		return &result, nil
+		tracks := found.GetTracks()
+		logger.Debug(fmt.Sprintf("LoadType: %s, Tracks encontrados: %d", found.LoadType, len(tracks)), "Lavalink")
+		span.SetAttributes(tracing.String("lavalink.load_type", found.LoadType), tracing.Int("lavalink.tracks", int64(len(tracks))))
+		return &found, nil
 	}
 
 	logger.Error(fmt.Sprintf("No hay nodos Lavalink disponibles. Total de nodos: %d", len(c.nodes)), "Lavalink")
@@ -525,6 +540,11 @@ func (c *LavalinkClient) Search(query string) (*SearchResult, error) {
 
 // Play starts playing a track
 func (c *LavalinkClient) Play(guildID, voiceChannelID, textChannelID string, track *Track) error {
+	return c.PlayContext(context.Background(), guildID, voiceChannelID, textChannelID, track)
+}
+
+// PlayContext starts playing a track as part of the trace carried by ctx
+func (c *LavalinkClient) PlayContext(ctx context.Context, guildID, voiceChannelID, textChannelID string, track *Track) error {
 	player := c.GetPlayer(guildID)
 	player.Mu.Lock()
 	player.VoiceChannel = voiceChannelID
@@ -560,7 +580,7 @@ func (c *LavalinkClient) Play(guildID, voiceChannelID, textChannelID string, tra
 					"encoded": track.Encoded,
 				},
 			}
-			if err := node.updatePlayer(guildID, payload); err != nil {
+			if err := node.updatePlayer(ctx, guildID, payload); err != nil {
 				logger.Error(fmt.Sprintf("Error sending play command: %v", err), "Lavalink")
 				continue
 			}
@@ -588,7 +608,7 @@ func (c *LavalinkClient) Pause(guildID string, pause bool) error {
 			payload := map[string]interface{}{
 				"paused": pause,
 			}
-			if err := node.updatePlayer(guildID, payload); err != nil {
+			if err := node.updatePlayer(context.Background(), guildID, payload); err != nil {
 				logger.Error(fmt.Sprintf("Error sending pause command: %v", err), "Lavalink")
 				continue
 			}
@@ -621,7 +641,7 @@ func (c *LavalinkClient) Stop(guildID string) error {
 					"encoded": nil,
 				},
 			}
-			if err := node.updatePlayer(guildID, payload); err != nil {
+			if err := node.updatePlayer(context.Background(), guildID, payload); err != nil {
 				logger.Error(fmt.Sprintf("Error sending stop command: %v", err), "Lavalink")
 				continue
 			}
@@ -657,7 +677,7 @@ func (c *LavalinkClient) Skip(guildID string) error {
 					"encoded": nextTrack.Encoded,
 				},
 			}
-			if err := node.updatePlayer(guildID, payload); err != nil {
+			if err := node.updatePlayer(context.Background(), guildID, payload); err != nil {
 				logger.Error(fmt.Sprintf("Error sending skip command: %v", err), "Lavalink")
 				continue
 			}
@@ -690,7 +710,7 @@ func (c *LavalinkClient) SetVolume(guildID string, volume int) error {
 			payload := map[string]interface{}{
 				"volume": volume,
 			}
-			if err := node.updatePlayer(guildID, payload); err != nil {
+			if err := node.updatePlayer(context.Background(), guildID, payload); err != nil {
 				logger.Error(fmt.Sprintf("Error sending volume command: %v", err), "Lavalink")
 				continue
 			}
@@ -708,7 +728,16 @@ func (n *Node) sendOp(data map[string]interface{}) error {
 }
 
 // updatePlayer sends a PATCH request to update player state via REST API
-func (n *Node) updatePlayer(guildID string, payload map[string]interface{}) error {
+func (n *Node) updatePlayer(ctx context.Context, guildID string, payload map[string]interface{}) (err error) {
+	ctx, span := tracing.Start(ctx, "lavalink update player", tracing.KindClient,
+		tracing.String("lavalink.node", n.config.Name),
+		tracing.String("discord.guild_id", guildID),
+	)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
 	n.mu.RLock()
 	sessionId := n.sessionId
 	config := n.config
@@ -732,7 +761,7 @@ func (n *Node) updatePlayer(guildID string, payload map[string]interface{}) erro
 		return fmt.Errorf("error marshaling payload: %w", err)
 	}
 
-	req, err := http.NewRequest("PATCH", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "PATCH", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return fmt.Errorf("error creating request: %w", err)
 	}
@@ -756,7 +785,16 @@ func (n *Node) updatePlayer(guildID string, payload map[string]interface{}) erro
 }
 
 // destroyPlayer sends a DELETE request to destroy a player
-func (n *Node) destroyPlayer(guildID string) error {
+func (n *Node) destroyPlayer(ctx context.Context, guildID string) (err error) {
+	ctx, span := tracing.Start(ctx, "lavalink destroy player", tracing.KindClient,
+		tracing.String("lavalink.node", n.config.Name),
+		tracing.String("discord.guild_id", guildID),
+	)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
 	n.mu.RLock()
 	sessionId := n.sessionId
 	config := n.config
@@ -775,7 +813,7 @@ func (n *Node) destroyPlayer(guildID string) error {
 	url := fmt.Sprintf("%s://%s:%d/v4/sessions/%s/players/%s",
 		scheme, config.Host, config.Port, sessionId, guildID)
 
-	req, err := http.NewRequest("DELETE", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
 	if err != nil {
 		return fmt.Errorf("error creating request: %w", err)
 	}
@@ -868,7 +906,7 @@ func (c *LavalinkClient) handleTrackEnd(guildID string, payload map[string]inter
 						"encoded": nextTrack.Encoded,
 					},
 				}
-				if err := node.updatePlayer(guildID, payload); err != nil {
+				if err := node.updatePlayer(context.Background(), guildID, payload); err != nil {
 					logger.Error(fmt.Sprintf("Error playing next track: %v", err), "Lavalink")
 					continue
 				}
@@ -1006,7 +1044,7 @@ func (c *LavalinkClient) voiceServerUpdate(s *discordgo.Session, v *discordgo.Vo
 					"endpoint":  v.Endpoint,
 				},
 			}
-			if err := node.updatePlayer(v.GuildID, payload); err != nil {
+			if err := node.updatePlayer(context.Background(), v.GuildID, payload); err != nil {
 				logger.Error(fmt.Sprintf("Error sending voice update: %v", err), "Lavalink")
 				continue
 			}
@@ -0,0 +1,52 @@
+package lavalink
+
+import "github.com/PancyStudios/PancyBotGo/pkg/metrics"
+
+func init() {
+	metrics.NewGaugeFunc("pancybot_lavalink_players",
+		"Music players, by state (playing, paused or idle).", []string{"state"}, func(emit func(float64, ...string)) {
+			c := Get()
+			if c == nil {
+				return
+			}
+			counts := map[string]int{"playing": 0, "paused": 0, "idle": 0}
+			c.mu.RLock()
+			players := make([]*Player, 0, len(c.players))
+			for _, player := range c.players {
+				players = append(players, player)
+			}
+			c.mu.RUnlock()
+
+			for _, player := range players {
+				player.Mu.RLock()
+				switch {
+				case player.IsPaused:
+					counts["paused"]++
+				case player.IsPlaying:
+					counts["playing"]++
+				default:
+					counts["idle"]++
+				}
+				player.Mu.RUnlock()
+			}
+			for state, count := range counts {
+				emit(float64(count), state)
+			}
+		})
+	metrics.NewGaugeFunc("pancybot_lavalink_node_up",
+		"Whether a Lavalink node is connected.", []string{"node"}, func(emit func(float64, ...string)) {
+			c := Get()
+			if c == nil {
+				return
+			}
+			for _, node := range c.nodes {
+				node.mu.RLock()
+				up := 0.0
+				if node.connected {
+					up = 1
+				}
+				node.mu.RUnlock()
+				emit(up, node.config.Name)
+			}
+		})
+}
@@ -2,22 +2,40 @@ package lavalink
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/PancyStudios/PancyBotGo/pkg/mqtt"
 )
 
-// RegisterMusicHandlers registers all MQTT endpoints for music control
+// musicResponse is the answer of the music control topics
+type musicResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Track   string `json:"track,omitempty"`
+}
+
+type skipRequest struct {
+	Direction string `json:"direction"` // next (default) or previous
+}
+
+func (r skipRequest) Validate() error {
+	switch r.Direction {
+	case "", "next", "previous":
+		return nil
+	}
+	return fmt.Errorf("direction must be next or previous")
+}
+
+type skipToRequest struct {
+	Index int `json:"index"`
+}
+
+// RegisterMusicHandlers registers all MQTT endpoints for music control. The
+// server ID is the first wildcard of every topic.
 func RegisterMusicHandlers(mc *mqtt.MqttCommunicator, llClient *LavalinkClient) {
 
 	// PLAY / RESUME
-	mc.On("music/+/play", func(payload map[string]interface{}) (interface{}, error) {
-		actualTopic := payload["_topic"].(string)
-		parts := strings.Split(actualTopic, "/")
-		if len(parts) < 3 {
-			return nil, fmt.Errorf("invalid topic structure")
-		}
-		guildID := parts[1]
+	mqtt.On(mc, "music/+/play", func(call *mqtt.Call, req mqtt.Empty) (*musicResponse, error) {
+		guildID := call.Param(0)
 
 		player := llClient.GetPlayer(guildID)
 		player.Mu.RLock()
@@ -30,89 +48,60 @@ func RegisterMusicHandlers(mc *mqtt.MqttCommunicator, llClient *LavalinkClient)
 			}
 		}
 
-		response := map[string]interface{}{
-			"success": true,
-			"message": "Reproducción reanudada",
+		response := &musicResponse{
+			Success: true,
+			Message: "Reproducción reanudada",
 		}
 
 		player.Mu.RLock()
 		if player.CurrentTrack != nil {
-			response["track"] = player.CurrentTrack.Info.Title
+			response.Track = player.CurrentTrack.Info.Title
 		}
 		player.Mu.RUnlock()
 
 		return response, nil
-	})
+	}, mqtt.Describe("Reanuda la reproducción de un servidor"))
 
 	// PAUSE
-	mc.On("music/+/pause", func(payload map[string]interface{}) (interface{}, error) {
-		actualTopic := payload["_topic"].(string)
-		parts := strings.Split(actualTopic, "/")
-		if len(parts) < 3 {
-			return nil, fmt.Errorf("invalid topic structure")
-		}
-		guildID := parts[1]
-
-		if err := llClient.Pause(guildID, true); err != nil {
+	mqtt.On(mc, "music/+/pause", func(call *mqtt.Call, req mqtt.Empty) (*musicResponse, error) {
+		if err := llClient.Pause(call.Param(0), true); err != nil {
 			return nil, err
 		}
 
-		return map[string]interface{}{
-			"success": true,
-			"message": "Reproducción pausada",
+		return &musicResponse{
+			Success: true,
+			Message: "Reproducción pausada",
 		}, nil
-	})
+	}, mqtt.Describe("Pausa la reproducción de un servidor"))
 
 	// SKIP (Next or Previous)
-	mc.On("music/+/skip", func(payload map[string]interface{}) (interface{}, error) {
-		actualTopic := payload["_topic"].(string)
-		parts := strings.Split(actualTopic, "/")
-		if len(parts) < 3 {
-			return nil, fmt.Errorf("invalid topic structure")
+	mqtt.On(mc, "music/+/skip", func(call *mqtt.Call, req skipRequest) (*musicResponse, error) {
+		if req.Direction == "previous" {
+			return nil, fmt.Errorf("skip to previous not fully implemented yet")
 		}
-		guildID := parts[1]
 
-		direction := "next"
-		if d, ok := payload["direction"].(string); ok {
-			direction = d
-		}
-
-		if direction == "next" {
-			if err := llClient.Skip(guildID); err != nil {
-				return nil, err
-			}
-			return map[string]interface{}{
-				"success": true,
-				"message": "Saltado a la siguiente canción",
-			}, nil
+		if err := llClient.Skip(call.Param(0)); err != nil {
+			return nil, err
 		}
-
-		return nil, fmt.Errorf("skip to previous not fully implemented yet")
-	})
+		return &musicResponse{
+			Success: true,
+			Message: "Saltado a la siguiente canción",
+		}, nil
+	}, mqtt.Describe("Salta a la siguiente canción"))
 
 	// SKIP TO INDEX
-	mc.On("music/+/skip/+", func(payload map[string]interface{}) (interface{}, error) {
-		actualTopic := payload["_topic"].(string)
-		parts := strings.Split(actualTopic, "/")
-		if len(parts) < 4 {
-			return nil, fmt.Errorf("invalid topic structure")
-		}
-		guildID := parts[1]
-
-		var index float64 // json decodes numbers as float64
-		if idx, ok := payload["index"].(float64); ok {
-			index = idx
-		}
+	mqtt.On(mc, "music/+/skip/+", func(call *mqtt.Call, req skipToRequest) (*musicResponse, error) {
+		guildID := call.Param(0)
 
 		player := llClient.GetPlayer(guildID)
 		player.Mu.Lock()
 		defer player.Mu.Unlock()
 
-		if int(index) < 0 || int(index) >= len(player.Queue) {
+		if req.Index < 0 || req.Index >= len(player.Queue) {
 			return nil, fmt.Errorf("index out of bounds")
 		}
 
-		player.Queue = player.Queue[int(index):]
+		player.Queue = player.Queue[req.Index:]
 
 		player.Mu.Unlock()
 		err := llClient.Skip(guildID)
@@ -122,9 +111,9 @@ func RegisterMusicHandlers(mc *mqtt.MqttCommunicator, llClient *LavalinkClient)
 			return nil, err
 		}
 
-		return map[string]interface{}{
-			"success": true,
-			"message": fmt.Sprintf("Saltado al índice %d", int(index)),
+		return &musicResponse{
+			Success: true,
+			Message: fmt.Sprintf("Saltado al índice %d", req.Index),
 		}, nil
-	})
+	}, mqtt.Describe("Salta a una canción de la cola"))
 }
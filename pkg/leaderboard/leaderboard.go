@@ -0,0 +1,284 @@
+// Package leaderboard renders the paginated economy and levels leaderboards. The
+// pages are computed by the database, so only one page is loaded at a time, and
+// every page shows the rank of the user looking at it.
+package leaderboard
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/ecoconfig"
+	"github.com/PancyStudios/PancyBotGo/pkg/leveling"
+	"github.com/PancyStudios/PancyBotGo/pkg/seasons"
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Boards that can be rendered
+const (
+	BoardLocal  = "local"
+	BoardGlobal = "global"
+	BoardLevels = "levels"
+	BoardVoice  = "voice"
+
+	BoardSeasonLevels  = "slevels"  // XP of the running season
+	BoardSeasonEconomy = "seconomy" // Local earnings of the running season
+)
+
+// ButtonPrefix starts the custom ID of the navigation buttons: lb_nav_{board}_{page}
+const ButtonPrefix = "lb_nav_"
+
+// PageSize is the number of entries per page
+const PageSize = 10
+
+// ParseButton reads the board and the page of a navigation button
+func ParseButton(customID string) (string, int, bool) {
+	parts := strings.Split(strings.TrimPrefix(customID, ButtonPrefix), "_")
+	if len(parts) != 2 {
+		return "", 0, false
+	}
+	page, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return "", 0, false
+	}
+	return parts[0], page, true
+}
+
+// Render builds a page (0-based) of a board for a viewer. Pages out of range are
+// clamped to the first or the last page.
+func Render(board, guildID, viewerID string, page int) (*discordgo.MessageEmbed, []discordgo.MessageComponent, error) {
+	switch board {
+	case BoardLocal, BoardGlobal:
+		return renderEconomy(board, guildID, viewerID, page)
+	case BoardLevels:
+		return renderLevels(guildID, viewerID, page)
+	case BoardVoice:
+		return renderVoice(guildID, viewerID, page)
+	case BoardSeasonLevels, BoardSeasonEconomy:
+		return renderSeason(board, guildID, viewerID, page)
+	}
+	return nil, nil, fmt.Errorf("unknown leaderboard %q", board)
+}
+
+func renderEconomy(board, guildID, viewerID string, page int) (*discordgo.MessageEmbed, []discordgo.MessageComponent, error) {
+	global := board == BoardGlobal
+
+	total, err := database.CountEconomyLeaderboard(guildID, global)
+	if err != nil {
+		return nil, nil, err
+	}
+	page, pages := clamp(page, total)
+
+	entries, err := database.GetEconomyLeaderboard(guildID, global, PageSize, int64(page*PageSize))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	currency := ecoconfig.CurrencyFor(guildID, global)
+	var lines []string
+	for i, entry := range entries {
+		position := page*PageSize + i + 1
+		lines = append(lines, fmt.Sprintf("%s **%d.** <@%s> - %s %d (Cartera: %d, Banco: %d)", medal(position), position, entry.UserID, currency.Emoji, entry.NetWorth, entry.Wallet, entry.Bank))
+	}
+	description := strings.Join(lines, "\n")
+	if description == "" {
+		description = "No hay datos para mostrar en la tabla de clasificación."
+	}
+
+	title, color := "🏆 Tabla de Clasificación Local", 0x2ECC71
+	if global {
+		title, color = "🏆 Tabla de Clasificación Global", 0xF1C40F
+	}
+
+	footer := "Aún no tienes perfil en esta economía"
+	if rank, netWorth, err := database.GetEconomyRank(guildID, viewerID, global); err == nil && rank > 0 {
+		footer = fmt.Sprintf("Tu posición: #%d de %d · %d %s", rank, total, netWorth, currency.Name)
+	}
+
+	embed := discord.NewEmbed().
+		SetTitle(fmt.Sprintf("%s (Página %d/%d)", title, page+1, pages)).
+		SetColor(color).
+		SetDescription(description).
+		SetFooter(footer, "").
+		Build()
+	return embed, buttons(board, page, pages), nil
+}
+
+func renderLevels(guildID, viewerID string, page int) (*discordgo.MessageEmbed, []discordgo.MessageComponent, error) {
+	total, err := database.CountLevelsLeaderboard(guildID)
+	if err != nil {
+		return nil, nil, err
+	}
+	page, pages := clamp(page, total)
+
+	profiles, err := database.GetTopLevels(guildID, PageSize, int64(page*PageSize))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	description := "¡Estos son los usuarios más activos del servidor!\n\n"
+	for i, profile := range profiles {
+		position := page*PageSize + i + 1
+		description += fmt.Sprintf("%s **#%d** <@%s> - Nivel %d (%d XP)\n", medal(position), position, profile.UserID, profile.Level, profile.XP)
+	}
+
+	// Read the viewer without creating a profile for them
+	footer := "Aún no tienes experiencia en este servidor"
+	if own, err := database.LocalLevelsDM.Get(bson.M{"_id": fmt.Sprintf("%s_%s", guildID, viewerID)}); err == nil && own != nil {
+		if rank, err := database.GetLevelRank(guildID, own.XP); err == nil {
+			footer = fmt.Sprintf("Tu posición: #%d de %d · Nivel %d (%d XP)", rank, total, own.Level, own.XP)
+		}
+	}
+
+	embed := discord.NewEmbed().
+		SetTitle(fmt.Sprintf("🏆 Tabla de Clasificación de Niveles (Página %d/%d)", page+1, pages)).
+		SetColor(0xFFD700).
+		SetDescription(description).
+		SetFooter(footer, "").
+		Build()
+	return embed, buttons(BoardLevels, page, pages), nil
+}
+
+func renderVoice(guildID, viewerID string, page int) (*discordgo.MessageEmbed, []discordgo.MessageComponent, error) {
+	total, err := database.CountVoiceLeaderboard(guildID)
+	if err != nil {
+		return nil, nil, err
+	}
+	page, pages := clamp(page, total)
+
+	profiles, err := database.GetTopVoice(guildID, PageSize, int64(page*PageSize))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	description := "¡Estos son los usuarios que más tiempo pasan en voz!\n\n"
+	for i, profile := range profiles {
+		position := page*PageSize + i + 1
+		description += fmt.Sprintf("%s **#%d** <@%s> - %s\n", medal(position), position, profile.UserID, leveling.FormatVoice(profile.VoiceSeconds))
+	}
+
+	footer := "Aún no tienes tiempo en voz en este servidor"
+	if own, err := database.LocalLevelsDM.Get(bson.M{"_id": fmt.Sprintf("%s_%s", guildID, viewerID)}); err == nil && own != nil && own.VoiceSeconds > 0 {
+		if rank, err := database.GetVoiceRank(guildID, own.VoiceSeconds); err == nil {
+			footer = fmt.Sprintf("Tu posición: #%d de %d · %s en voz", rank, total, leveling.FormatVoice(own.VoiceSeconds))
+		}
+	}
+
+	embed := discord.NewEmbed().
+		SetTitle(fmt.Sprintf("🎤 Tabla de Clasificación de Voz (Página %d/%d)", page+1, pages)).
+		SetColor(0x9B59B6).
+		SetDescription(description).
+		SetFooter(footer, "").
+		Build()
+	return embed, buttons(BoardVoice, page, pages), nil
+}
+
+func renderSeason(board, guildID, viewerID string, page int) (*discordgo.MessageEmbed, []discordgo.MessageComponent, error) {
+	guildData, err := database.GlobalGuildDM.Get(bson.M{"id": guildID})
+	if err != nil || guildData == nil || !guildData.Seasons.Active {
+		return nil, nil, seasons.ErrNotRunning
+	}
+	season := guildData.Seasons
+
+	kind, title, format := database.SeasonBoardLevels, "🌟 Experiencia", func(v int64) string { return fmt.Sprintf("%d XP", v) }
+	if board == BoardSeasonEconomy {
+		kind, title, format = database.SeasonBoardEconomy, "💰 Ganancias", ecoconfig.LocalCurrency(guildData.Economy).Format
+	}
+
+	total, err := database.CountSeasonStandings(kind, guildID, season.Number)
+	if err != nil {
+		return nil, nil, err
+	}
+	page, pages := clamp(page, total)
+
+	standings, err := database.GetSeasonStandings(kind, guildID, season.Number, PageSize, int64(page*PageSize))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var lines []string
+	for i, standing := range standings {
+		position := page*PageSize + i + 1
+		lines = append(lines, fmt.Sprintf("%s **#%d** <@%s> - %s", medal(position), position, standing.UserID, format(standing.Value)))
+	}
+	description := fmt.Sprintf("La temporada termina <t:%d:R>.\n\n", season.EndsAt.Unix()) + strings.Join(lines, "\n")
+	if len(lines) == 0 {
+		description += "Aún nadie ha puntuado en esta temporada."
+	}
+
+	// Read the viewer without creating a profile for them
+	var own int64
+	id := fmt.Sprintf("%s_%s", guildID, viewerID)
+	if kind == database.SeasonBoardEconomy {
+		if profile, err := database.LocalEconomyDM.Get(bson.M{"_id": id}); err == nil && profile != nil {
+			own = profile.SeasonEarnings
+		}
+	} else if profile, err := database.LocalLevelsDM.Get(bson.M{"_id": id}); err == nil && profile != nil {
+		own = seasons.SeasonXP(profile, season)
+	}
+	footer := "Aún no has puntuado en esta temporada"
+	if own > 0 {
+		if rank, err := database.GetSeasonRank(kind, guildID, season.Number, own); err == nil {
+			footer = fmt.Sprintf("Tu posición: #%d de %d · %s", rank, total, format(own))
+		}
+	}
+
+	embed := discord.NewEmbed().
+		SetTitle(fmt.Sprintf("🏁 Temporada %d · %s (Página %d/%d)", season.Number, title, page+1, pages)).
+		SetColor(0xE67E22).
+		SetDescription(description).
+		SetFooter(footer, "").
+		Build()
+	return embed, buttons(board, page, pages), nil
+}
+
+// clamp keeps a page inside the board and returns it with the number of pages
+func clamp(page int, total int64) (int, int) {
+	pages := int((total + PageSize - 1) / PageSize)
+	if pages < 1 {
+		pages = 1
+	}
+	if page >= pages {
+		page = pages - 1
+	}
+	if page < 0 {
+		page = 0
+	}
+	return page, pages
+}
+
+func medal(position int) string {
+	switch position {
+	case 1:
+		return "🥇"
+	case 2:
+		return "🥈"
+	case 3:
+		return "🥉"
+	}
+	return "🏅"
+}
+
+func buttons(board string, page, pages int) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "◀️ Anterior",
+					Style:    discordgo.PrimaryButton,
+					CustomID: fmt.Sprintf("%s%s_%d", ButtonPrefix, board, page-1),
+					Disabled: page <= 0,
+				},
+				discordgo.Button{
+					Label:    "Siguiente ▶️",
+					Style:    discordgo.PrimaryButton,
+					CustomID: fmt.Sprintf("%s%s_%d", ButtonPrefix, board, page+1),
+					Disabled: page >= pages-1,
+				},
+			},
+		},
+	}
+}
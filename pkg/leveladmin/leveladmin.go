@@ -0,0 +1,221 @@
+// Package leveladmin lets server admins change the XP of their members, reset
+// it, and move it in and out of the bot as CSV or JSON. Every change waits for
+// the admin to confirm it, and the reward roles are synced with the new levels.
+package leveladmin
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/leveling"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/bwmarrin/discordgo"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Actions an admin can confirm
+const (
+	ActionSetXP    = "set-xp"
+	ActionAddXP    = "add-xp"
+	ActionSetLevel = "set-level"
+	ActionReset    = "reset"
+	ActionResetAll = "reset-all"
+	ActionImport   = "import"
+)
+
+// ConfirmTimeout is how long an action waits to be confirmed
+const ConfirmTimeout = 2 * time.Minute
+
+var (
+	ErrNotFound     = errors.New("action not found or expired")
+	ErrNotAdmin     = errors.New("user did not request this action")
+	ErrInvalidValue = errors.New("invalid value")
+)
+
+// Action is a change waiting for confirmation
+type Action struct {
+	ID      string
+	Kind    string
+	GuildID string
+	AdminID string
+	UserID  string   // Target of set-xp, add-xp, set-level and reset
+	Value   int64    // XP or level
+	Records []Record // Users of an import
+}
+
+var (
+	pending = make(map[string]Action)
+	mu      sync.Mutex
+)
+
+// Prepare checks an action and keeps it until it is confirmed or expires
+func Prepare(a Action) (Action, error) {
+	switch a.Kind {
+	case ActionSetXP:
+		if a.Value < 0 {
+			return a, ErrInvalidValue
+		}
+	case ActionSetLevel:
+		if a.Value < 0 || a.Value > leveling.MaxLevel {
+			return a, ErrInvalidValue
+		}
+	case ActionImport:
+		if len(a.Records) == 0 {
+			return a, ErrInvalidValue
+		}
+	}
+
+	a.ID = uuid.New().String()[:8]
+	mu.Lock()
+	pending[a.ID] = a
+	mu.Unlock()
+
+	time.AfterFunc(ConfirmTimeout, func() {
+		mu.Lock()
+		delete(pending, a.ID)
+		mu.Unlock()
+	})
+	return a, nil
+}
+
+// Cancel drops a pending action
+func Cancel(id, adminID string) error {
+	_, err := claim(id, adminID)
+	return err
+}
+
+// claim removes a pending action so it can only run once
+func claim(id, adminID string) (Action, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	a, ok := pending[id]
+	if !ok {
+		return Action{}, ErrNotFound
+	}
+	if a.AdminID != adminID {
+		return Action{}, ErrNotAdmin
+	}
+	delete(pending, id)
+	return a, nil
+}
+
+// Confirm runs a pending action and returns what was done
+func Confirm(s *discordgo.Session, id, adminID string) (string, error) {
+	a, err := claim(id, adminID)
+	if err != nil {
+		return "", err
+	}
+
+	guildData, err := database.GlobalGuildDM.Get(bson.M{"id": a.GuildID})
+	if err != nil || guildData == nil {
+		return "", fmt.Errorf("error getting guild config: %v", err)
+	}
+	rules := leveling.Resolve(guildData.Levels)
+	rewards := guildData.Levels.Rewards
+
+	switch a.Kind {
+	case ActionSetXP, ActionAddXP, ActionSetLevel:
+		profile, err := database.GetLocalLevelProfile(a.GuildID, a.UserID)
+		if err != nil {
+			return "", err
+		}
+		profile.XP, profile.Level = Target(a, rules, profile.XP)
+		profile.UpdatedAt = time.Now()
+		if _, err := database.LocalLevelsDM.Set(bson.M{"_id": profile.ID}, profile); err != nil {
+			return "", err
+		}
+		leveling.SyncRewards(s, a.GuildID, a.UserID, rules, rewards, profile.Level)
+		return fmt.Sprintf("✅ <@%s> ahora está en el **Nivel %d** con **%d XP**.", a.UserID, profile.Level, profile.XP), nil
+
+	case ActionReset:
+		if err := database.DeleteLevelProfile(a.GuildID, a.UserID); err != nil {
+			return "", err
+		}
+		leveling.SyncRewards(s, a.GuildID, a.UserID, rules, rewards, 0)
+		return fmt.Sprintf("✅ La experiencia de <@%s> fue reiniciada.", a.UserID), nil
+
+	case ActionResetAll:
+		userIDs, err := database.DeleteGuildLevelProfiles(a.GuildID)
+		if err != nil {
+			return "", err
+		}
+		go syncAll(s, a.GuildID, rules, rewards, userIDs, func(string) int64 { return 0 })
+		return fmt.Sprintf("✅ Se reinició la experiencia de **%d** usuarios. Las recompensas se retirarán en segundo plano.", len(userIDs)), nil
+
+	case ActionImport:
+		profiles := Profiles(a.Records, rules)
+		if err := database.ImportLevelProfiles(a.GuildID, profiles); err != nil {
+			return "", err
+		}
+		levels := make(map[string]int64, len(profiles))
+		userIDs := make([]string, 0, len(profiles))
+		for _, p := range profiles {
+			levels[p.UserID] = p.Level
+			userIDs = append(userIDs, p.UserID)
+		}
+		go syncAll(s, a.GuildID, rules, rewards, userIDs, func(userID string) int64 { return levels[userID] })
+		return fmt.Sprintf("✅ Se importaron **%d** usuarios. Las recompensas se sincronizarán en segundo plano.", len(profiles)), nil
+	}
+	return "", ErrNotFound
+}
+
+// Target returns the XP and level a member ends with after a set-xp, add-xp or
+// set-level action
+func Target(a Action, rules leveling.Rules, currentXP int64) (int64, int64) {
+	xp := currentXP
+	switch a.Kind {
+	case ActionSetXP:
+		xp = a.Value
+	case ActionAddXP:
+		xp += a.Value
+	case ActionSetLevel:
+		return rules.XPForLevel(a.Value), a.Value
+	}
+	if xp < 0 {
+		xp = 0
+	}
+	return xp, rules.LevelFor(xp)
+}
+
+// Preview describes what an action will change, for the confirmation message
+func Preview(a Action) string {
+	switch a.Kind {
+	case ActionResetAll:
+		total, _ := database.CountLevelsLeaderboard(a.GuildID)
+		return fmt.Sprintf("⚠️ Se borrará la experiencia de **%d** usuarios del servidor y se les retirarán las recompensas. Esta acción no se puede deshacer.", total)
+	case ActionImport:
+		return fmt.Sprintf("📥 Se importarán **%d** usuarios. Su experiencia se reemplazará por la del archivo y los niveles se recalcularán con la curva del servidor. El resto de usuarios no cambia.", len(a.Records))
+	}
+
+	// Read the member without creating a profile for them
+	current := &models.UserLevelProfile{}
+	if profile, err := database.LocalLevelsDM.Get(bson.M{"_id": fmt.Sprintf("%s_%s", a.GuildID, a.UserID)}); err == nil && profile != nil {
+		current = profile
+	}
+	if a.Kind == ActionReset {
+		return fmt.Sprintf("⚠️ Se borrará la experiencia de <@%s> (**Nivel %d**, %d XP) y se le retirarán las recompensas.", a.UserID, current.Level, current.XP)
+	}
+
+	var levels models.LevelsConfig
+	if guildData, err := database.GlobalGuildDM.Get(bson.M{"id": a.GuildID}); err == nil && guildData != nil {
+		levels = guildData.Levels
+	}
+	xp, level := Target(a, leveling.Resolve(levels), current.XP)
+	return fmt.Sprintf("✏️ <@%s>: **Nivel %d** (%d XP) → **Nivel %d** (%d XP)", a.UserID, current.Level, current.XP, level, xp)
+}
+
+// syncAll puts the reward roles of many members in line with their levels
+func syncAll(s *discordgo.Session, guildID string, rules leveling.Rules, rewards []models.LevelReward, userIDs []string, level func(string) int64) {
+	if len(rewards) == 0 {
+		return
+	}
+	for _, userID := range userIDs {
+		leveling.SyncRewards(s, guildID, userID, rules, rewards, level(userID))
+	}
+	logger.Info(fmt.Sprintf("Recompensas de nivel sincronizadas para %d usuarios en %s", len(userIDs), guildID), "Levels")
+}
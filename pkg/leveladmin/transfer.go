@@ -0,0 +1,278 @@
+package leveladmin
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/leveling"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+)
+
+// Export formats
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// MaxImportSize bounds the files accepted by an import
+const MaxImportSize = 5 << 20
+
+var (
+	ErrInvalidFile = errors.New("invalid import file")
+	ErrFileTooBig  = errors.New("import file too big")
+)
+
+// Record is a user in an export or import file
+type Record struct {
+	UserID       string `json:"userId"`
+	XP           int64  `json:"xp"`
+	Level        int64  `json:"level"`
+	Messages     int64  `json:"messages"`
+	VoiceSeconds int64  `json:"voiceSeconds"`
+}
+
+// Column names accepted on import, so files from other leveling bots can be
+// loaded as they are. The first name is the one used on export.
+var columns = map[string][]string{
+	"userId":       {"userid", "user_id", "user id", "id", "user", "discord_id"},
+	"xp":           {"xp", "exp", "experience", "total_xp", "totalxp"},
+	"level":        {"level", "lvl", "nivel"},
+	"messages":     {"messages", "message_count", "messagecount", "total_messages", "msgs"},
+	"voiceSeconds": {"voiceseconds", "voice_seconds", "voice"},
+}
+
+// Records turns the level profiles of a guild into export records
+func Records(profiles []*models.UserLevelProfile) []Record {
+	records := make([]Record, 0, len(profiles))
+	for _, p := range profiles {
+		records = append(records, Record{UserID: p.UserID, XP: p.XP, Level: p.Level, Messages: p.TotalMessages, VoiceSeconds: p.VoiceSeconds})
+	}
+	return records
+}
+
+// Encode writes records as CSV or JSON
+func Encode(records []Record, format string) ([]byte, error) {
+	if format == FormatJSON {
+		return json.MarshalIndent(records, "", "  ")
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"user_id", "xp", "level", "messages", "voice_seconds"})
+	for _, r := range records {
+		_ = w.Write([]string{r.UserID, itoa(r.XP), itoa(r.Level), itoa(r.Messages), itoa(r.VoiceSeconds)})
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// Decode reads records from a CSV or JSON file. JSON may be a list of users or
+// an object holding the list (e.g. {"players": [...]}).
+func Decode(data []byte) ([]Record, error) {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("%w: el archivo está vacío", ErrInvalidFile)
+	}
+
+	var records []Record
+	var err error
+	if trimmed[0] == '[' || trimmed[0] == '{' {
+		records, err = decodeJSON(trimmed)
+	} else {
+		records, err = decodeCSV(trimmed)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(records))
+	for i, r := range records {
+		if _, err := strconv.ParseUint(r.UserID, 10, 64); err != nil {
+			return nil, fmt.Errorf("%w: la fila %d no tiene un ID de usuario válido", ErrInvalidFile, i+1)
+		}
+		if r.XP < 0 || r.Level < 0 || r.Messages < 0 || r.VoiceSeconds < 0 {
+			return nil, fmt.Errorf("%w: la fila %d tiene valores negativos", ErrInvalidFile, i+1)
+		}
+		if r.Level > leveling.MaxLevel {
+			return nil, fmt.Errorf("%w: la fila %d supera el nivel %d", ErrInvalidFile, i+1, leveling.MaxLevel)
+		}
+		if seen[r.UserID] {
+			return nil, fmt.Errorf("%w: el usuario %s aparece dos veces", ErrInvalidFile, r.UserID)
+		}
+		seen[r.UserID] = true
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: no hay usuarios", ErrInvalidFile)
+	}
+	return records, nil
+}
+
+func decodeCSV(data []byte) ([]Record, error) {
+	rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+
+	index := make(map[string]int)
+	for i, name := range rows[0] {
+		if field := fieldFor(name); field != "" {
+			index[field] = i
+		}
+	}
+	if _, ok := index["userId"]; !ok {
+		return nil, fmt.Errorf("%w: falta la columna user_id", ErrInvalidFile)
+	}
+	if _, ok := index["xp"]; !ok {
+		if _, ok := index["level"]; !ok {
+			return nil, fmt.Errorf("%w: hace falta una columna xp o level", ErrInvalidFile)
+		}
+	}
+
+	records := make([]Record, 0, len(rows)-1)
+	for n, row := range rows[1:] {
+		value := func(field string) (int64, error) {
+			i, ok := index[field]
+			if !ok || i >= len(row) || strings.TrimSpace(row[i]) == "" {
+				return 0, nil
+			}
+			v, err := strconv.ParseFloat(strings.TrimSpace(row[i]), 64)
+			if err != nil {
+				return 0, fmt.Errorf("%w: la fila %d tiene un %s inválido", ErrInvalidFile, n+1, field)
+			}
+			return int64(v), nil
+		}
+
+		r := Record{UserID: strings.TrimSpace(row[index["userId"]])}
+		for field, target := range map[string]*int64{"xp": &r.XP, "level": &r.Level, "messages": &r.Messages, "voiceSeconds": &r.VoiceSeconds} {
+			if *target, err = value(field); err != nil {
+				return nil, err
+			}
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+func decodeJSON(data []byte) ([]Record, error) {
+	var list []map[string]interface{}
+	if data[0] == '{' {
+		var wrapper map[string]json.RawMessage
+		if err := json.Unmarshal(data, &wrapper); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+		}
+		for _, key := range []string{"players", "users", "levels", "members", "data"} {
+			if raw, ok := wrapper[key]; ok {
+				data = raw
+				break
+			}
+		}
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("%w: se esperaba una lista de usuarios", ErrInvalidFile)
+	}
+
+	records := make([]Record, 0, len(list))
+	for n, entry := range list {
+		var r Record
+		for key, raw := range entry {
+			field := fieldFor(key)
+			if field == "" {
+				continue
+			}
+			if field == "userId" {
+				switch v := raw.(type) {
+				case string:
+					r.UserID = v
+				case float64:
+					// IDs do not fit a float64; they must come as strings
+					return nil, fmt.Errorf("%w: el ID de la fila %d debe ser un texto", ErrInvalidFile, n+1)
+				}
+				continue
+			}
+			number, ok := raw.(float64)
+			if !ok {
+				return nil, fmt.Errorf("%w: la fila %d tiene un %s inválido", ErrInvalidFile, n+1, field)
+			}
+			switch field {
+			case "xp":
+				r.XP = int64(number)
+			case "level":
+				r.Level = int64(number)
+			case "messages":
+				r.Messages = int64(number)
+			case "voiceSeconds":
+				r.VoiceSeconds = int64(number)
+			}
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+// fieldFor maps a column or key name to a Record field, or ""
+func fieldFor(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for field, names := range columns {
+		for _, n := range names {
+			if n == name {
+				return field
+			}
+		}
+	}
+	return ""
+}
+
+// Profiles turns import records into level profiles under the rules of the
+// guild. Levels are recomputed from XP so they follow the guild curve; records
+// with a level and no XP get the XP of the start of that level.
+func Profiles(records []Record, rules leveling.Rules) []*models.UserLevelProfile {
+	profiles := make([]*models.UserLevelProfile, 0, len(records))
+	for _, r := range records {
+		xp := r.XP
+		if xp == 0 && r.Level > 0 {
+			xp = rules.XPForLevel(r.Level)
+		}
+		profiles = append(profiles, &models.UserLevelProfile{
+			UserID:        r.UserID,
+			XP:            xp,
+			Level:         rules.LevelFor(xp),
+			TotalMessages: r.Messages,
+			VoiceSeconds:  r.VoiceSeconds,
+		})
+	}
+	return profiles
+}
+
+// Download fetches an import file attached to a message
+func Download(url string) ([]byte, error) {
+	client := &http.Client{Timeout: 15 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxImportSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxImportSize {
+		return nil, ErrFileTooBig
+	}
+	return data, nil
+}
+
+func itoa(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
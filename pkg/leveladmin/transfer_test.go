@@ -0,0 +1,61 @@
+package leveladmin
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/leveling"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+)
+
+func TestCSVRoundTrip(t *testing.T) {
+	records := []Record{{UserID: "123456789012345678", XP: 2500, Level: 5, Messages: 40, VoiceSeconds: 600}}
+	data, err := Encode(records, FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := Decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != 1 || decoded[0] != records[0] {
+		t.Errorf("Decode(Encode(%v)) = %v", records, decoded)
+	}
+}
+
+func TestDecodeOtherBots(t *testing.T) {
+	mee6 := `{"players": [{"id": "111111111111111111", "xp": 1500, "level": 3, "message_count": 90, "username": "a"}]}`
+	records, err := Decode([]byte(mee6))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if records[0].UserID != "111111111111111111" || records[0].XP != 1500 || records[0].Messages != 90 {
+		t.Errorf("unexpected record %+v", records[0])
+	}
+
+	csv := "User ID,Level\n222222222222222222,4\n"
+	records, err = Decode([]byte(csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	profiles := Profiles(records, leveling.Resolve(models.LevelsConfig{}))
+	if profiles[0].Level != 4 || profiles[0].XP != 1600 {
+		t.Errorf("a level without XP should start that level, got %+v", profiles[0])
+	}
+}
+
+func TestDecodeRejectsBadFiles(t *testing.T) {
+	bad := []string{
+		"",
+		"name,xp\nfoo,10\n",
+		"user_id,xp\nnot-an-id,10\n",
+		"user_id,xp\n111111111111111111,-5\n",
+		"user_id,xp\n111111111111111111,5\n111111111111111111,6\n",
+		`[{"id": 111111111111111111, "xp": 5}]`,
+	}
+	for _, data := range bad {
+		if _, err := Decode([]byte(data)); !errors.Is(err, ErrInvalidFile) {
+			t.Errorf("Decode(%q) = %v, want ErrInvalidFile", data, err)
+		}
+	}
+}
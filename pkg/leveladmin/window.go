@@ -0,0 +1,84 @@
+package leveladmin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/bwmarrin/discordgo"
+)
+
+// Custom ID prefixes of the confirmation buttons, followed by the action ID
+const (
+	ConfirmButtonPrefix = "lvladm_ok_"
+	CancelButtonPrefix  = "lvladm_no_"
+)
+
+// ConfirmEmbed renders the confirmation of a pending action
+func ConfirmEmbed(a Action) *discordgo.MessageEmbed {
+	return discord.NewEmbed().
+		SetTitle("🛠️ Confirmar cambio de experiencia").
+		SetDescription(fmt.Sprintf("%s\n\nConfirma antes de <t:%d:R>.", Preview(a), time.Now().Add(ConfirmTimeout).Unix())).
+		SetColor(discord.ColorWarning).
+		SetFooter("ID: "+a.ID, "").
+		Build()
+}
+
+// ConfirmComponents returns the buttons of a pending action
+func ConfirmComponents(a Action) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{Label: "Confirmar", Emoji: &discordgo.ComponentEmoji{Name: "✅"}, Style: discordgo.DangerButton, CustomID: ConfirmButtonPrefix + a.ID},
+			discordgo.Button{Label: "Cancelar", Emoji: &discordgo.ComponentEmoji{Name: "✖️"}, Style: discordgo.SecondaryButton, CustomID: CancelButtonPrefix + a.ID},
+		}},
+	}
+}
+
+// ExportFile builds the export of the levels of a guild
+func ExportFile(guildID, format string) (*discordgo.File, int, error) {
+	profiles, err := database.GetGuildLevelProfiles(guildID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if format != FormatJSON {
+		format = FormatCSV
+	}
+	data, err := Encode(Records(profiles), format)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	contentType := "text/csv"
+	if format == FormatJSON {
+		contentType = "application/json"
+	}
+	return &discordgo.File{
+		Name:        fmt.Sprintf("niveles-%s.%s", guildID, format),
+		ContentType: contentType,
+		Reader:      bytes.NewReader(data),
+	}, len(profiles), nil
+}
+
+// ErrorText explains an admin action error
+func ErrorText(err error) string {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return "⌛ Esta acción ya no está pendiente. Vuelve a usar el comando."
+	case errors.Is(err, ErrNotAdmin):
+		return "❌ Solo quien pidió el cambio puede confirmarlo."
+	case errors.Is(err, ErrInvalidValue):
+		return "❌ El valor no es válido."
+	case errors.Is(err, ErrFileTooBig):
+		return fmt.Sprintf("❌ El archivo supera el máximo de %d MB.", MaxImportSize>>20)
+	case errors.Is(err, ErrInvalidFile):
+		return "❌ " + strings.TrimPrefix(err.Error(), ErrInvalidFile.Error()+": ")
+	case errors.Is(err, database.ErrDatabaseOffline):
+		return "❌ La base de datos no está disponible ahora mismo."
+	default:
+		return "❌ Ocurrió un error al aplicar el cambio."
+	}
+}
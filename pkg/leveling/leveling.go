@@ -0,0 +1,366 @@
+// Package leveling resolves the XP rules of a server: how much XP a message gives,
+// how often, with which multipliers, and how much XP each level needs.
+package leveling
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+)
+
+// Level curves
+const (
+	CurveQuadratic   = "quadratic"   // base × level²
+	CurveLinear      = "linear"      // base × level
+	CurveExponential = "exponential" // each level needs 20% more than the previous one
+)
+
+// Curves lists the level curves in the order they are shown
+var Curves = []string{CurveQuadratic, CurveLinear, CurveExponential}
+
+// Reward modes
+const (
+	RewardsStack   = "stack"   // Members keep every reward role they reached
+	RewardsReplace = "replace" // Members only keep the reward of their highest level
+)
+
+// MaxLevel bounds the level computed from XP
+const MaxLevel = 1000
+
+const (
+	maxXPPerMessage = 10000
+	maxMultiplier   = 10
+	maxCooldown     = 3600
+	maxCurveBase    = 1000000
+)
+
+var ErrInvalidConfig = errors.New("invalid levels config")
+
+// Rules is the resolved XP configuration of a server
+type Rules struct {
+	MinXP              int64
+	MaxXP              int64
+	Cooldown           time.Duration
+	SpamMessages       int // Messages inside SpamWindow that trigger SpamCooldown
+	SpamWindow         time.Duration
+	SpamCooldown       time.Duration
+	Curve              string
+	CurveBase          int64
+	RoleMultipliers    map[string]float64
+	ChannelMultipliers map[string]float64
+	NoXPChannels       map[string]bool
+	NoXPRoles          map[string]bool
+	PremiumBoost       float64
+	RewardMode         string
+	VoiceXP            int64 // Per minute in voice channels, 0 when disabled
+}
+
+var defaults = Rules{
+	MinXP:        1,
+	MaxXP:        15,
+	SpamMessages: 4,
+	SpamWindow:   3 * time.Second,
+	SpamCooldown: 5 * time.Second,
+	Curve:        CurveQuadratic,
+	CurveBase:    100,
+	PremiumBoost: 1,
+	RewardMode:   RewardsStack,
+	VoiceXP:      5,
+}
+
+// Resolve merges a server config over the defaults
+func Resolve(cfg models.LevelsConfig) Rules {
+	rules := defaults
+	if cfg.MinXP > 0 {
+		rules.MinXP = cfg.MinXP
+	}
+	if cfg.MaxXP > 0 {
+		rules.MaxXP = cfg.MaxXP
+	}
+	if cfg.CooldownSeconds > 0 {
+		rules.Cooldown = time.Duration(cfg.CooldownSeconds) * time.Second
+	}
+	if cfg.Curve != "" {
+		rules.Curve = cfg.Curve
+	}
+	if cfg.CurveBase > 0 {
+		rules.CurveBase = cfg.CurveBase
+	}
+	if cfg.PremiumBoost > 0 {
+		rules.PremiumBoost = cfg.PremiumBoost
+	}
+	if cfg.RewardMode != "" {
+		rules.RewardMode = cfg.RewardMode
+	}
+	if cfg.VoiceXP > 0 {
+		rules.VoiceXP = cfg.VoiceXP
+	}
+	if cfg.VoiceDisabled {
+		rules.VoiceXP = 0
+	}
+	rules.RoleMultipliers = cfg.RoleMultipliers
+	rules.ChannelMultipliers = cfg.ChannelMultipliers
+	rules.NoXPChannels = set(cfg.NoXPChannels)
+	rules.NoXPRoles = set(cfg.NoXPRoles)
+	return rules
+}
+
+// Validate checks a server config before it is saved
+func Validate(cfg models.LevelsConfig) error {
+	if cfg.MinXP < 0 || cfg.MaxXP < 0 || cfg.CurveBase < 0 || cfg.CooldownSeconds < 0 || cfg.PremiumBoost < 0 || cfg.VoiceXP < 0 {
+		return fmt.Errorf("%w: los valores no pueden ser negativos", ErrInvalidConfig)
+	}
+	if cfg.MaxXP > maxXPPerMessage {
+		return fmt.Errorf("%w: un mensaje da como máximo %d XP", ErrInvalidConfig, maxXPPerMessage)
+	}
+	if cfg.VoiceXP > maxXPPerMessage {
+		return fmt.Errorf("%w: un minuto en voz da como máximo %d XP", ErrInvalidConfig, maxXPPerMessage)
+	}
+	if cfg.CurveBase > maxCurveBase {
+		return fmt.Errorf("%w: la base de la curva no puede superar %d", ErrInvalidConfig, maxCurveBase)
+	}
+	if cfg.CooldownSeconds > maxCooldown {
+		return fmt.Errorf("%w: el cooldown no puede superar %d segundos", ErrInvalidConfig, maxCooldown)
+	}
+	if cfg.Curve != "" && !known(Curves, cfg.Curve) {
+		return fmt.Errorf("%w: curva desconocida, usa %s", ErrInvalidConfig, strings.Join(Curves, ", "))
+	}
+	if cfg.RewardMode != "" && cfg.RewardMode != RewardsStack && cfg.RewardMode != RewardsReplace {
+		return fmt.Errorf("%w: el modo de recompensas debe ser %s o %s", ErrInvalidConfig, RewardsStack, RewardsReplace)
+	}
+	if cfg.PremiumBoost > maxMultiplier {
+		return fmt.Errorf("%w: los multiplicadores deben estar entre 0 y %d", ErrInvalidConfig, maxMultiplier)
+	}
+	for _, multipliers := range []map[string]float64{cfg.RoleMultipliers, cfg.ChannelMultipliers} {
+		for _, multiplier := range multipliers {
+			if multiplier < 0 || multiplier > maxMultiplier {
+				return fmt.Errorf("%w: los multiplicadores deben estar entre 0 y %d", ErrInvalidConfig, maxMultiplier)
+			}
+		}
+	}
+
+	rules := Resolve(cfg)
+	if rules.MinXP > rules.MaxXP {
+		return fmt.Errorf("%w: el XP mínimo no puede superar al máximo", ErrInvalidConfig)
+	}
+	return nil
+}
+
+// Explain turns a validation error into a message for the server admin
+func Explain(err error) string {
+	return strings.TrimPrefix(err.Error(), ErrInvalidConfig.Error()+": ")
+}
+
+// Excluded reports whether messages in a channel or from a member with one of
+// the roles give no XP
+func (r Rules) Excluded(channelID string, roles []string) bool {
+	if r.NoXPChannels[channelID] {
+		return true
+	}
+	for _, role := range roles {
+		if r.NoXPRoles[role] {
+			return true
+		}
+	}
+	return false
+}
+
+// Multiplier returns the XP multiplier of a message: the best role multiplier,
+// the channel multiplier and the premium boost
+func (r Rules) Multiplier(channelID string, roles []string, premium bool) float64 {
+	best := 1.0
+	for _, role := range roles {
+		if m, ok := r.RoleMultipliers[role]; ok && m > best {
+			best = m
+		}
+	}
+	if m, ok := r.ChannelMultipliers[channelID]; ok {
+		best *= m
+	}
+	if premium {
+		best *= r.PremiumBoost
+	}
+	return best
+}
+
+// Roll returns the base XP of a message
+func (r Rules) Roll() int64 {
+	if r.MaxXP <= r.MinXP {
+		return r.MinXP
+	}
+	return r.MinXP + rand.Int63n(r.MaxXP-r.MinXP+1)
+}
+
+// XPForLevel returns the total XP needed to reach a level
+func (r Rules) XPForLevel(level int64) int64 {
+	if level <= 0 {
+		return 0
+	}
+	switch r.Curve {
+	case CurveLinear:
+		return r.CurveBase * level
+	case CurveExponential:
+		xp := float64(r.CurveBase) * (math.Pow(1.2, float64(level)) - 1) / 0.2
+		if xp >= math.MaxInt64 {
+			return math.MaxInt64
+		}
+		return int64(xp)
+	default:
+		return r.CurveBase * level * level
+	}
+}
+
+// LevelFor returns the level reached with an amount of XP
+func (r Rules) LevelFor(xp int64) int64 {
+	level := int64(0)
+	for level < MaxLevel && xp >= r.XPForLevel(level+1) {
+		level++
+	}
+	return level
+}
+
+// RewardChanges returns the reward roles a member at a level should get and, in
+// replace mode, the reward roles of lower levels they should lose
+func (r Rules) RewardChanges(rewards []models.LevelReward, level int64) (add, remove []string) {
+	sorted := append([]models.LevelReward{}, rewards...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Level < sorted[j].Level })
+
+	var reached []string
+	for _, reward := range sorted {
+		if reward.Level <= level {
+			reached = append(reached, reward.RoleID)
+		}
+	}
+	if r.RewardMode != RewardsReplace || len(reached) == 0 {
+		return reached, nil
+	}
+
+	top := reached[len(reached)-1]
+	for _, reward := range sorted {
+		if reward.RoleID != top {
+			remove = append(remove, reward.RoleID)
+		}
+	}
+	return []string{top}, remove
+}
+
+// RewardSync returns the reward roles a member at a level should have and the
+// ones they should not, including the rewards of higher levels. It is used when
+// an admin moves a member to another level.
+func (r Rules) RewardSync(rewards []models.LevelReward, level int64) (add, remove []string) {
+	add, remove = r.RewardChanges(rewards, level)
+	keep := set(add)
+	for _, id := range remove {
+		keep[id] = true
+	}
+	for _, reward := range rewards {
+		if reward.Level > level && !keep[reward.RoleID] {
+			keep[reward.RoleID] = true
+			remove = append(remove, reward.RoleID)
+		}
+	}
+	return add, remove
+}
+
+// Summary describes the rules for the config embeds
+func (r Rules) Summary() string {
+	parts := []string{
+		fmt.Sprintf("✨ %d-%d XP por mensaje", r.MinXP, r.MaxXP),
+		fmt.Sprintf("⏱️ %s entre ganancias", r.Cooldown),
+		fmt.Sprintf("📈 Curva %s (base %d)", r.Curve, r.CurveBase),
+		fmt.Sprintf("💎 Premium x%g", r.PremiumBoost),
+		fmt.Sprintf("🎁 Recompensas: %s", r.RewardMode),
+	}
+	if r.VoiceXP > 0 {
+		parts = append(parts, fmt.Sprintf("🎤 %d XP por minuto en voz", r.VoiceXP))
+	} else {
+		parts = append(parts, "🎤 Sin XP por voz")
+	}
+	return strings.Join(parts, "\n")
+}
+
+// Lists renders the multipliers and the no-XP channels and roles of a config
+func Lists(cfg models.LevelsConfig) (multipliers, excluded string) {
+	var lines []string
+	for id, m := range cfg.ChannelMultipliers {
+		lines = append(lines, fmt.Sprintf("<#%s> x%g", id, m))
+	}
+	for id, m := range cfg.RoleMultipliers {
+		lines = append(lines, fmt.Sprintf("<@&%s> x%g", id, m))
+	}
+	sort.Strings(lines)
+	multipliers = orNone(lines)
+
+	lines = nil
+	for _, id := range cfg.NoXPChannels {
+		lines = append(lines, fmt.Sprintf("<#%s>", id))
+	}
+	for _, id := range cfg.NoXPRoles {
+		lines = append(lines, fmt.Sprintf("<@&%s>", id))
+	}
+	return multipliers, orNone(lines)
+}
+
+// SetMultiplier returns a copy of the multipliers with the one of a channel or
+// role set; 1 removes it
+func SetMultiplier(multipliers map[string]float64, id string, multiplier float64) map[string]float64 {
+	updated := make(map[string]float64, len(multipliers)+1)
+	for k, v := range multipliers {
+		updated[k] = v
+	}
+	if multiplier == 1 {
+		delete(updated, id)
+	} else {
+		updated[id] = multiplier
+	}
+	return updated
+}
+
+// ToggleID adds an ID to a list, or removes it if it was already there
+func ToggleID(ids []string, id string) []string {
+	for i, existing := range ids {
+		if existing == id {
+			return append(ids[:i:i], ids[i+1:]...)
+		}
+	}
+	return append(ids[:len(ids):len(ids)], id)
+}
+
+// FormatVoice renders a voice time, e.g. "3h 20m"
+func FormatVoice(seconds int64) string {
+	hours, minutes := seconds/3600, seconds%3600/60
+	if hours == 0 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%dh %dm", hours, minutes)
+}
+
+func orNone(lines []string) string {
+	if len(lines) == 0 {
+		return "Ninguno"
+	}
+	return strings.Join(lines, "\n")
+}
+
+func set(ids []string) map[string]bool {
+	m := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		m[id] = true
+	}
+	return m
+}
+
+func known(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package leveling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+)
+
+func TestDefaultCurveMatchesLegacyFormula(t *testing.T) {
+	rules := Resolve(models.LevelsConfig{})
+	for level := int64(1); level <= 50; level++ {
+		if got, want := rules.XPForLevel(level), level*level*100; got != want {
+			t.Fatalf("XPForLevel(%d) = %d, want %d", level, got, want)
+		}
+	}
+}
+
+func TestLevelForJumpsSeveralLevels(t *testing.T) {
+	rules := Resolve(models.LevelsConfig{Curve: CurveLinear, CurveBase: 50})
+	if got := rules.LevelFor(49); got != 0 {
+		t.Errorf("LevelFor(49) = %d, want 0", got)
+	}
+	if got := rules.LevelFor(260); got != 5 {
+		t.Errorf("LevelFor(260) = %d, want 5", got)
+	}
+	if got := Resolve(models.LevelsConfig{Curve: CurveExponential, CurveBase: maxCurveBase}).LevelFor(1 << 62); got >= MaxLevel {
+		t.Errorf("the exponential curve must not overflow, got level %d", got)
+	}
+}
+
+func TestMultiplierUsesBestRole(t *testing.T) {
+	rules := Resolve(models.LevelsConfig{
+		RoleMultipliers:    map[string]float64{"a": 1.5, "b": 2},
+		ChannelMultipliers: map[string]float64{"c": 0.5},
+		PremiumBoost:       3,
+	})
+	if got := rules.Multiplier("c", []string{"a", "b"}, true); got != 3 {
+		t.Errorf("Multiplier = %v, want 2 × 0.5 × 3", got)
+	}
+	if got := rules.Multiplier("x", nil, false); got != 1 {
+		t.Errorf("Multiplier without bonuses = %v, want 1", got)
+	}
+}
+
+func TestRewardChanges(t *testing.T) {
+	rewards := []models.LevelReward{{Level: 10, RoleID: "r10"}, {Level: 5, RoleID: "r5"}, {Level: 20, RoleID: "r20"}}
+
+	add, remove := Resolve(models.LevelsConfig{}).RewardChanges(rewards, 12)
+	if len(add) != 2 || len(remove) != 0 {
+		t.Errorf("stack mode: add %v remove %v, want r5 and r10 kept", add, remove)
+	}
+
+	add, remove = Resolve(models.LevelsConfig{RewardMode: RewardsReplace}).RewardChanges(rewards, 12)
+	if len(add) != 1 || add[0] != "r10" || len(remove) != 2 {
+		t.Errorf("replace mode: add %v remove %v, want only r10", add, remove)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	invalid := []models.LevelsConfig{
+		{MinXP: 20},
+		{MaxXP: maxXPPerMessage + 1},
+		{Curve: "cubic"},
+		{RewardMode: "all"},
+		{RoleMultipliers: map[string]float64{"a": maxMultiplier + 1}},
+		{CooldownSeconds: -1},
+	}
+	for _, cfg := range invalid {
+		if err := Validate(cfg); err == nil {
+			t.Errorf("Validate(%+v) should fail", cfg)
+		}
+	}
+	if err := Validate(models.LevelsConfig{MinXP: 5, MaxXP: 5, Curve: CurveLinear}); err != nil {
+		t.Errorf("a valid config was rejected: %v", err)
+	}
+}
+
+func TestVoiceTracker(t *testing.T) {
+	tracker := NewVoiceTracker()
+	start := time.Unix(0, 0)
+
+	if ended := tracker.Sync("g", map[string]string{"a": "c1", "b": "c1"}, start); len(ended) != 0 {
+		t.Fatalf("new sessions ended: %v", ended)
+	}
+
+	// b moves to another channel, a keeps talking
+	ended := tracker.Sync("g", map[string]string{"a": "c1", "b": "c2"}, start.Add(2*time.Minute))
+	if len(ended) != 1 || ended[0].UserID != "b" || ended[0].ChannelID != "c1" || ended[0].Duration != 2*time.Minute {
+		t.Fatalf("moving must end the old session, got %v", ended)
+	}
+
+	counted := tracker.Flush(start.Add(3 * time.Minute))
+	total := time.Duration(0)
+	for _, vt := range counted {
+		total += vt.Duration
+	}
+	if len(counted) != 2 || total != 4*time.Minute {
+		t.Errorf("Flush counted %v, want 3m for a and 1m for b", counted)
+	}
+
+	// Everybody leaves: only the time since the flush is left
+	ended = tracker.Sync("g", nil, start.Add(4*time.Minute))
+	if len(ended) != 2 || ended[0].Duration != time.Minute || ended[1].Duration != time.Minute {
+		t.Errorf("leaving must count the time since the flush, got %v", ended)
+	}
+}
+
+func TestVoiceMinutesCarryOver(t *testing.T) {
+	if got := VoiceMinutes(50, 20); got != 1 {
+		t.Errorf("VoiceMinutes(50, 20) = %d, want 1", got)
+	}
+	if got := VoiceMinutes(0, 59); got != 0 {
+		t.Errorf("VoiceMinutes(0, 59) = %d, want 0", got)
+	}
+}
+
+func TestRewardSyncRemovesHigherLevels(t *testing.T) {
+	rewards := []models.LevelReward{{Level: 5, RoleID: "r5"}, {Level: 10, RoleID: "r10"}, {Level: 20, RoleID: "r20"}}
+	add, remove := Resolve(models.LevelsConfig{}).RewardSync(rewards, 7)
+	if len(add) != 1 || add[0] != "r5" {
+		t.Errorf("add = %v, want r5", add)
+	}
+	if len(remove) != 2 {
+		t.Errorf("remove = %v, want r10 and r20", remove)
+	}
+}
@@ -0,0 +1,51 @@
+package leveling
+
+import (
+	"fmt"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/bwmarrin/discordgo"
+)
+
+// ApplyRewards gives the reward roles a member is missing and takes away the
+// ones they should not have. current are the roles the member has now.
+func ApplyRewards(s *discordgo.Session, guildID, userID string, current, add, remove []string) {
+	has := set(current)
+
+	for _, roleID := range add {
+		if has[roleID] {
+			continue
+		}
+		if err := s.GuildMemberRoleAdd(guildID, userID, roleID); err != nil {
+			logger.Error(fmt.Sprintf("No se pudo asignar el rol de nivel %s a %s: %v", roleID, userID, err), "Levels")
+		} else {
+			logger.Info(fmt.Sprintf("Rol %s asignado a %s por subir de nivel", roleID, userID), "Levels")
+		}
+	}
+	for _, roleID := range remove {
+		if !has[roleID] {
+			continue
+		}
+		if err := s.GuildMemberRoleRemove(guildID, userID, roleID); err != nil {
+			logger.Error(fmt.Sprintf("No se pudo quitar el rol de nivel %s a %s: %v", roleID, userID, err), "Levels")
+		}
+	}
+}
+
+// SyncRewards puts the reward roles of a member in line with their level. Members
+// that left the server are skipped.
+func SyncRewards(s *discordgo.Session, guildID, userID string, rules Rules, rewards []models.LevelReward, level int64) {
+	if len(rewards) == 0 {
+		return
+	}
+	member, err := s.State.Member(guildID, userID)
+	if err != nil {
+		if member, err = s.GuildMember(guildID, userID); err != nil {
+			logger.Debug(fmt.Sprintf("No se pudieron sincronizar las recompensas de %s: %v", userID, err), "Levels")
+			return
+		}
+	}
+	add, remove := rules.RewardSync(rewards, level)
+	ApplyRewards(s, guildID, userID, member.Roles, add, remove)
+}
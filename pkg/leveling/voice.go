@@ -0,0 +1,87 @@
+package leveling
+
+import (
+	"sync"
+	"time"
+)
+
+// VoiceTime is a stretch of time a member spent earning XP in a voice channel
+type VoiceTime struct {
+	GuildID   string
+	UserID    string
+	ChannelID string
+	Duration  time.Duration
+}
+
+type voiceSession struct {
+	channelID string
+	since     time.Time
+}
+
+// VoiceTracker keeps the open voice sessions of every guild. A session only
+// exists while the member is earning: in a channel that is not the AFK one, not
+// deafened and with somebody else to talk to.
+type VoiceTracker struct {
+	mu       sync.Mutex
+	sessions map[string]map[string]voiceSession // GuildID -> UserID -> session
+}
+
+// NewVoiceTracker creates an empty tracker
+func NewVoiceTracker() *VoiceTracker {
+	return &VoiceTracker{sessions: make(map[string]map[string]voiceSession)}
+}
+
+// Sync replaces the sessions of a guild with the members earning now (UserID ->
+// ChannelID) and returns the time of the sessions that ended, including those of
+// members that moved to another channel.
+func (t *VoiceTracker) Sync(guildID string, earning map[string]string, now time.Time) []VoiceTime {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	current := t.sessions[guildID]
+	var ended []VoiceTime
+	for userID, session := range current {
+		if channelID, ok := earning[userID]; ok && channelID == session.channelID {
+			continue
+		}
+		ended = append(ended, VoiceTime{GuildID: guildID, UserID: userID, ChannelID: session.channelID, Duration: now.Sub(session.since)})
+		delete(current, userID)
+	}
+
+	if len(earning) == 0 {
+		delete(t.sessions, guildID)
+		return ended
+	}
+	if current == nil {
+		current = make(map[string]voiceSession, len(earning))
+		t.sessions[guildID] = current
+	}
+	for userID, channelID := range earning {
+		if _, ok := current[userID]; !ok {
+			current[userID] = voiceSession{channelID: channelID, since: now}
+		}
+	}
+	return ended
+}
+
+// Flush returns the time counted so far by every open session and restarts them,
+// so long sessions are credited while they last
+func (t *VoiceTracker) Flush(now time.Time) []VoiceTime {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var counted []VoiceTime
+	for guildID, sessions := range t.sessions {
+		for userID, session := range sessions {
+			counted = append(counted, VoiceTime{GuildID: guildID, UserID: userID, ChannelID: session.channelID, Duration: now.Sub(session.since)})
+			sessions[userID] = voiceSession{channelID: session.channelID, since: now}
+		}
+	}
+	return counted
+}
+
+// VoiceMinutes returns how many whole minutes a member completes when seconds
+// are added to their voice time, so partial minutes carry over between sessions
+func VoiceMinutes(before, added int64) int64 {
+	return (before+added)/60 - before/60
+}
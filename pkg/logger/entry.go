@@ -0,0 +1,100 @@
+package logger
+
+import "time"
+
+// Entry is a log line with fields, waiting for its level and message
+type Entry struct {
+	logger *Logger
+	fields Fields
+}
+
+// WithFields starts a line with the given fields
+func (l *Logger) WithFields(fields Fields) *Entry {
+	return (&Entry{logger: l}).WithFields(fields)
+}
+
+// WithField starts a line with a single field
+func (l *Logger) WithField(key string, value interface{}) *Entry {
+	return l.WithFields(Fields{key: value})
+}
+
+// WithError starts a line with the error in the "error" field
+func (l *Logger) WithError(err error) *Entry {
+	return l.WithField("error", err)
+}
+
+// WithFields returns a copy of the entry with more fields
+func (e *Entry) WithFields(fields Fields) *Entry {
+	merged := make(Fields, len(e.fields)+len(fields))
+	for key, value := range e.fields {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+	return &Entry{logger: e.logger, fields: merged}
+}
+
+// WithField returns a copy of the entry with one more field
+func (e *Entry) WithField(key string, value interface{}) *Entry {
+	return e.WithFields(Fields{key: value})
+}
+
+// WithError returns a copy of the entry with the error in the "error" field
+func (e *Entry) WithError(err error) *Entry {
+	return e.WithField("error", err)
+}
+
+func (e *Entry) log(level LogLevel, message, prefix string) {
+	e.logger.write(Record{Time: time.Now(), Level: level, Prefix: prefix, Message: message, Fields: e.fields})
+}
+
+// Critical logs a critical message with the entry fields
+func (e *Entry) Critical(message string, prefix string) {
+	e.log(LevelCritical, message, prefix)
+}
+
+// Error logs an error message with the entry fields
+func (e *Entry) Error(message string, prefix string) {
+	e.log(LevelError, message, prefix)
+}
+
+// Warn logs a warning message with the entry fields
+func (e *Entry) Warn(message string, prefix string) {
+	e.log(LevelWarn, message, prefix)
+}
+
+// Success logs a success message with the entry fields
+func (e *Entry) Success(message string, prefix string) {
+	e.log(LevelSuccess, message, prefix)
+}
+
+// Info logs an info message with the entry fields
+func (e *Entry) Info(message string, prefix string) {
+	e.log(LevelInfo, message, prefix)
+}
+
+// Debug logs a debug message with the entry fields
+func (e *Entry) Debug(message string, prefix string) {
+	e.log(LevelDebug, message, prefix)
+}
+
+// System logs a system message with the entry fields
+func (e *Entry) System(message string, prefix string) {
+	e.log(LevelSystem, message, prefix)
+}
+
+// WithFields starts a line with fields using the global logger
+func WithFields(fields Fields) *Entry {
+	return Get().WithFields(fields)
+}
+
+// WithField starts a line with a single field using the global logger
+func WithField(key string, value interface{}) *Entry {
+	return Get().WithField(key, value)
+}
+
+// WithError starts a line with an error field using the global logger
+func WithError(err error) *Entry {
+	return Get().WithError(err)
+}
@@ -0,0 +1,188 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// filter decides which lines are written. Every prefix can have its own level,
+// the rest use the global one.
+type filter struct {
+	mu       sync.RWMutex
+	level    LogLevel
+	prefixes map[string]LogLevel
+}
+
+func newFilter() filter {
+	return filter{level: LevelDebug, prefixes: make(map[string]LogLevel)}
+}
+
+// verbosity ranks levels from the most to the least important. Success lines
+// are as verbose as Info ones. System lines answer the operator on the console,
+// so they are never filtered.
+func verbosity(level LogLevel) int {
+	switch level {
+	case LevelCritical, LevelSystem:
+		return 0
+	case LevelError:
+		return 1
+	case LevelWarn:
+		return 2
+	case LevelSuccess, LevelInfo:
+		return 3
+	default:
+		return 4
+	}
+}
+
+func (f *filter) enabled(level LogLevel, prefix string) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	max, ok := f.prefixes[strings.ToLower(prefix)]
+	if !ok {
+		max = f.level
+	}
+	return verbosity(level) <= verbosity(max)
+}
+
+// ParseLevel reads a level name such as "debug", "warn" or "error"
+func ParseLevel(name string) (LogLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "critical", "crit", "fatal":
+		return LevelCritical, nil
+	case "error", "err":
+		return LevelError, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "success":
+		return LevelSuccess, nil
+	case "info":
+		return LevelInfo, nil
+	case "debug", "all", "":
+		return LevelDebug, nil
+	}
+	return LevelDebug, fmt.Errorf("unknown log level %q", name)
+}
+
+// ParsePrefixLevels reads per-prefix levels written as "Levels=debug,Scheduler=warn"
+func ParsePrefixLevels(spec string) (map[string]LogLevel, error) {
+	levels := make(map[string]LogLevel)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		prefix, name, ok := strings.Cut(part, "=")
+		if !ok || strings.TrimSpace(prefix) == "" {
+			return nil, fmt.Errorf("invalid prefix level %q", part)
+		}
+		level, err := ParseLevel(name)
+		if err != nil {
+			return nil, err
+		}
+		levels[strings.TrimSpace(prefix)] = level
+	}
+	return levels, nil
+}
+
+// SetLevel changes the level of the prefixes without their own
+func (l *Logger) SetLevel(level LogLevel) {
+	l.filter.mu.Lock()
+	l.filter.level = level
+	l.filter.mu.Unlock()
+}
+
+// Level returns the level of the prefixes without their own
+func (l *Logger) Level() LogLevel {
+	l.filter.mu.RLock()
+	defer l.filter.mu.RUnlock()
+	return l.filter.level
+}
+
+// SetPrefixLevel changes the level of a single prefix. Prefixes are matched
+// without case.
+func (l *Logger) SetPrefixLevel(prefix string, level LogLevel) {
+	l.filter.mu.Lock()
+	l.filter.prefixes[strings.ToLower(prefix)] = level
+	l.filter.mu.Unlock()
+}
+
+// ResetPrefixLevel makes a prefix use the global level again
+func (l *Logger) ResetPrefixLevel(prefix string) {
+	l.filter.mu.Lock()
+	delete(l.filter.prefixes, strings.ToLower(prefix))
+	l.filter.mu.Unlock()
+}
+
+// PrefixLevels returns the prefixes with their own level, sorted by name
+func (l *Logger) PrefixLevels() []string {
+	l.filter.mu.RLock()
+	defer l.filter.mu.RUnlock()
+	out := make([]string, 0, len(l.filter.prefixes))
+	for prefix, level := range l.filter.prefixes {
+		out = append(out, prefix+"="+strings.ToLower(level.String()))
+	}
+	sort.Strings(out)
+	return out
+}
+
+// SetFormat changes how lines are written to the console and the files
+func (l *Logger) SetFormat(format Format) {
+	l.format.Store(format)
+}
+
+// Format returns how lines are written to the console and the files
+func (l *Logger) Format() Format {
+	return l.format.Load().(Format)
+}
+
+// SetLevel changes the global level of the global logger
+func SetLevel(level LogLevel) {
+	Get().SetLevel(level)
+}
+
+// SetPrefixLevel changes the level of a prefix in the global logger
+func SetPrefixLevel(prefix string, level LogLevel) {
+	Get().SetPrefixLevel(prefix, level)
+}
+
+// SetFormat changes the format of the global logger
+func SetFormat(format Format) {
+	Get().SetFormat(format)
+}
+
+// Settings are the logging options read from the environment
+type Settings struct {
+	Format       string
+	Level        string
+	PrefixLevels string
+	Rotation     Rotation
+}
+
+// Apply changes the format, the levels and the rotation at once. Invalid values
+// are reported and the rest is still applied.
+func (l *Logger) Apply(settings Settings) error {
+	var errs []error
+	if format, ok := ParseFormat(settings.Format); ok {
+		l.SetFormat(format)
+	} else {
+		errs = append(errs, fmt.Errorf("unknown log format %q", settings.Format))
+	}
+	if level, err := ParseLevel(settings.Level); err == nil {
+		l.SetLevel(level)
+	} else {
+		errs = append(errs, err)
+	}
+	if levels, err := ParsePrefixLevels(settings.PrefixLevels); err == nil {
+		for prefix, level := range levels {
+			l.SetPrefixLevel(prefix, level)
+		}
+	} else {
+		errs = append(errs, err)
+	}
+	l.SetRotation(settings.Rotation)
+	return errors.Join(errs...)
+}
@@ -0,0 +1,149 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format is how lines are written to the console and the log files
+type Format string
+
+const (
+	FormatText Format = "text"
+	FormatJSON Format = "json"
+)
+
+const timestampFormat = "2006-01-02 15:04:05"
+
+// ParseFormat reads a format name, falling back to text
+func ParseFormat(name string) (Format, bool) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "text", "texto":
+		return FormatText, true
+	case "json":
+		return FormatJSON, true
+	}
+	return FormatText, false
+}
+
+// Fields are key/value pairs attached to a log line
+type Fields map[string]interface{}
+
+// Record is a single log line before it is formatted
+type Record struct {
+	Time    time.Time
+	Level   LogLevel
+	Prefix  string
+	Message string
+	Fields  Fields
+}
+
+// console formats the record for the terminal and the live subscribers
+func (r Record) console(format Format) string {
+	if format == FormatJSON {
+		return r.json()
+	}
+	return fmt.Sprintf("[%s] [%s%s%s] [%s]: %s%s\n",
+		r.Time.Format(timestampFormat),
+		r.Level.Color(),
+		r.Level.String(),
+		colorReset,
+		r.Prefix,
+		r.Message,
+		r.fieldsText(),
+	)
+}
+
+// file formats the record for the log files, without colors
+func (r Record) file(format Format) string {
+	if format == FormatJSON {
+		return r.json()
+	}
+	return r.text() + "\n"
+}
+
+// text is the plain line without the trailing newline
+func (r Record) text() string {
+	return fmt.Sprintf("[%s] [%s] [%s]: %s%s",
+		r.Time.Format(timestampFormat),
+		r.Level.String(),
+		r.Prefix,
+		r.Message,
+		r.fieldsText(),
+	)
+}
+
+// fieldsText renders the fields as sorted key=value pairs
+func (r Record) fieldsText() string {
+	if len(r.Fields) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for _, key := range r.Fields.keys() {
+		value := fmt.Sprint(fieldValue(r.Fields[key]))
+		if value == "" || strings.ContainsAny(value, " =\"\t\n") {
+			value = strconv.Quote(value)
+		}
+		b.WriteString(" ")
+		b.WriteString(key)
+		b.WriteString("=")
+		b.WriteString(value)
+	}
+	return b.String()
+}
+
+// json renders the record as a single JSON object. Fields that clash with the
+// standard keys are written as "fields.<key>".
+func (r Record) json() string {
+	out := make(map[string]interface{}, len(r.Fields)+4)
+	for key, value := range r.Fields {
+		out[key] = fieldValue(value)
+	}
+	for _, key := range []string{"time", "level", "prefix", "msg"} {
+		if value, ok := out[key]; ok {
+			out["fields."+key] = value
+		}
+	}
+	out["time"] = r.Time.Format(time.RFC3339Nano)
+	out["level"] = r.Level.String()
+	out["prefix"] = r.Prefix
+	out["msg"] = r.Message
+
+	data, err := json.Marshal(out)
+	if err != nil {
+		data, _ = json.Marshal(map[string]interface{}{
+			"time":   out["time"],
+			"level":  out["level"],
+			"prefix": r.Prefix,
+			"msg":    r.Message,
+			"error":  "fields: " + err.Error(),
+		})
+	}
+	return string(data) + "\n"
+}
+
+func (f Fields) keys() []string {
+	keys := make([]string, 0, len(f))
+	for key := range f {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// fieldValue turns errors and stringers into text so they are readable in JSON
+func fieldValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case error:
+		return v.Error()
+	case time.Duration:
+		return v.String()
+	case fmt.Stringer:
+		return v.String()
+	}
+	return value
+}
@@ -1,16 +1,16 @@
 // Package logger provides a comprehensive logging system with multiple outputs.
-// It supports console logging with colors, file logging, and Discord webhook logging.
+// It supports console logging with colors, rotated file logging, and batched
+// Discord webhook logging. Lines can carry key/value fields, be written as JSON,
+// and be filtered by level for every prefix at runtime.
 package logger
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -100,9 +100,13 @@ type Logger struct {
 	logrus          *logrus.Logger
 	errorWebhookURL string
 	logsWebhookURL  string
-	logFile         *os.File
-	errorFile       *os.File
+	logFile         *rotatingFile
+	errorFile       *rotatingFile
+	errorWebhook    *webhookSink
+	logsWebhook     *webhookSink
 	mu              sync.Mutex
+	filter          filter
+	format          atomic.Value // Format of the console and the files
 	subscribers     []chan string
 	subMu           sync.RWMutex
 	history         []string
@@ -140,7 +144,9 @@ func NewLogger(errorWebhook, logsWebhook string) *Logger {
 		errorWebhookURL: errorWebhook,
 		logsWebhookURL:  logsWebhook,
 		maxHistory:      200,
+		filter:          newFilter(),
 	}
+	l.format.Store(FormatText)
 
 	// Setup logrus
 	l.logrus.SetFormatter(&logrus.TextFormatter{
@@ -158,36 +164,53 @@ func NewLogger(errorWebhook, logsWebhook string) *Logger {
 
 	// Open log files
 	var err error
-	l.logFile, err = os.OpenFile(filepath.Join(logsDir, "combined.log"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	l.logFile, err = openRotatingFile(filepath.Join(logsDir, "combined.log"), DefaultRotation)
 	if err != nil {
 		fmt.Printf("Error opening combined log file: %v\n", err)
 	}
 
-	l.errorFile, err = os.OpenFile(filepath.Join(logsDir, "error.log"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	l.errorFile, err = openRotatingFile(filepath.Join(logsDir, "error.log"), DefaultRotation)
 	if err != nil {
 		fmt.Printf("Error opening error log file: %v\n", err)
 	}
 
+	// Webhook lines are batched so incidents don't get the bot rate limited
+	if errorWebhook != "" {
+		l.errorWebhook = newWebhookSink(errorWebhook, webhookFlushInterval)
+	}
+	if logsWebhook != "" {
+		l.logsWebhook = newWebhookSink(logsWebhook, webhookFlushInterval)
+	}
+
 	return l
 }
 
 // log is the internal logging function
 func (l *Logger) log(level LogLevel, message string, prefix string) {
+	l.write(Record{Time: time.Now(), Level: level, Prefix: prefix, Message: message})
+}
+
+// write filters, formats and sends a record to every output
+func (l *Logger) write(r Record) {
+	if !l.filter.enabled(r.Level, r.Prefix) {
+		return
+	}
+
+	format := l.Format()
+	consoleMsg := r.console(format)
+	fileMsg := r.file(format)
+
+	// Lines of different goroutines must not interleave
 	l.mu.Lock()
-	defer l.mu.Unlock()
-
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
-
-	// Console output with colors
-	consoleMsg := fmt.Sprintf("[%s] [%s%s%s] [%s]: %s\n",
-		timestamp,
-		level.Color(),
-		level.String(),
-		colorReset,
-		prefix,
-		message,
-	)
 	fmt.Print(consoleMsg)
+	if l.logFile != nil {
+		l.logFile.WriteString(fileMsg)
+	}
+	// Write to error log if it's an error level
+	if r.Level <= LevelError && l.errorFile != nil {
+		l.errorFile.WriteString(fileMsg)
+	}
+	l.mu.Unlock()
 
 	// Broadcast to SSE subscribers
 	l.subMu.RLock()
@@ -209,77 +232,18 @@ func (l *Logger) log(level LogLevel, message string, prefix string) {
 	}
 	l.historyMu.Unlock()
 
-	// File output without colors
-	fileMsg := fmt.Sprintf("[%s] [%s] [%s]: %s\n",
-		timestamp,
-		level.String(),
-		prefix,
-		message,
-	)
-
-	// Write to combined log
-	if l.logFile != nil {
-		l.logFile.WriteString(fileMsg)
-	}
-
-	// Write to error log if it's an error level
-	if level <= LevelError && l.errorFile != nil {
-		l.errorFile.WriteString(fileMsg)
-	}
-
 	// Send to Discord webhook
-	go l.sendToWebhook(level, message, prefix)
-}
-
-// sendToWebhook sends the log message to the appropriate Discord webhook
-func (l *Logger) sendToWebhook(level LogLevel, message, prefix string) {
-	var webhookURL string
-
-	if level <= LevelError && l.errorWebhookURL != "" {
-		webhookURL = l.errorWebhookURL
-	} else if l.logsWebhookURL != "" && level > LevelError {
-		webhookURL = l.logsWebhookURL
-	}
-
-	if webhookURL == "" {
-		return
-	}
-
-	embed := map[string]interface{}{
-		"title":       fmt.Sprintf("[%s] %s", level.String(), prefix),
-		"description": fmt.Sprintf("```%s```", message),
-		"color":       level.DiscordColor(),
-		"timestamp":   time.Now().Format(time.RFC3339),
-		"footer": map[string]string{
-			"text": "💫 Developed by PancyStudio | PancyBot Go",
-		},
-	}
-
-	payload := map[string]interface{}{
-		"embeds": []interface{}{embed},
-	}
-
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		return
-	}
-
-	req, err := http.NewRequest("POST", webhookURL, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{Timeout: 5 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return
+	if r.Level <= LevelError {
+		l.errorWebhook.send(r)
+	} else {
+		l.logsWebhook.send(r)
 	}
-	defer resp.Body.Close()
 }
 
-// Close closes the log files
+// Close flushes the webhooks and closes the log files
 func (l *Logger) Close() {
+	l.errorWebhook.close()
+	l.logsWebhook.close()
 	if l.logFile != nil {
 		l.logFile.Close()
 	}
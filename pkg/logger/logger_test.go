@@ -1,10 +1,17 @@
 package logger
 
 import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 )
 
 func TestNewLogger(t *testing.T) {
@@ -141,3 +148,170 @@ func TestGlobalLoggerInit(t *testing.T) {
 
 	l.Close()
 }
+
+func TestRecordFormats(t *testing.T) {
+	r := Record{
+		Time:    time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Level:   LevelWarn,
+		Prefix:  "Levels",
+		Message: "hello",
+		Fields:  Fields{"guild": "123", "error": errors.New("boom now"), "msg": "clash"},
+	}
+
+	text := r.file(FormatText)
+	want := `[2024-05-01 12:00:00] [WARN] [Levels]: hello error="boom now" guild=123 msg=clash` + "\n"
+	if text != want {
+		t.Errorf("text format = %q, want %q", text, want)
+	}
+
+	var out map[string]interface{}
+	if err := json.Unmarshal([]byte(r.file(FormatJSON)), &out); err != nil {
+		t.Fatalf("json format is not valid JSON: %v", err)
+	}
+	if out["msg"] != "hello" || out["fields.msg"] != "clash" || out["level"] != "WARN" || out["error"] != "boom now" {
+		t.Errorf("unexpected json line: %v", out)
+	}
+}
+
+func TestPrefixLevels(t *testing.T) {
+	l := NewLogger("", "")
+	defer l.Close()
+
+	if err := l.Apply(Settings{Level: "warn", PrefixLevels: "Levels=debug, Scheduler=error"}); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	tests := []struct {
+		level  LogLevel
+		prefix string
+		want   bool
+	}{
+		{LevelInfo, "Main", false},
+		{LevelWarn, "Main", true},
+		{LevelDebug, "levels", true},
+		{LevelWarn, "Scheduler", false},
+		{LevelError, "Scheduler", true},
+		{LevelSystem, "Scheduler", true},
+	}
+	for _, tt := range tests {
+		if got := l.filter.enabled(tt.level, tt.prefix); got != tt.want {
+			t.Errorf("enabled(%s, %s) = %v, want %v", tt.level, tt.prefix, got, tt.want)
+		}
+	}
+
+	l.ResetPrefixLevel("LEVELS")
+	if l.filter.enabled(LevelDebug, "Levels") {
+		t.Error("Expected Levels to use the global level after a reset")
+	}
+	if err := l.Apply(Settings{Level: "loud", PrefixLevels: "broken"}); err == nil {
+		t.Error("Expected invalid settings to return an error")
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "combined.log")
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	f, err := openRotatingFile(path, Rotation{MaxSize: 10, Interval: time.Hour, MaxBackups: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	f.now = func() time.Time { return now }
+	f.opened = now
+
+	// Every write after the first goes over the size and rotates
+	for i := 0; i < 4; i++ {
+		now = now.Add(time.Second)
+		f.WriteString("0123456789\n")
+	}
+	if backups := f.backups(); len(backups) != 2 {
+		t.Errorf("Expected 2 backups to be kept, got %v", backups)
+	}
+
+	// The interval rotates a small file too
+	f.SetRotation(Rotation{Interval: time.Hour})
+	now = now.Add(2 * time.Hour)
+	f.WriteString("x\n")
+	if backups := f.backups(); len(backups) != 3 {
+		t.Errorf("Expected a time based rotation, got %v", backups)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "x\n" {
+		t.Errorf("Expected the current file to restart, got %q", data)
+	}
+}
+
+func TestWebhookSinkBatchesAndRetries(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"retry_after": 0.05, "global": false}`))
+			return
+		}
+		bodies = append(bodies, string(body))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sink := newWebhookSink(server.URL, 50*time.Millisecond)
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		sink.send(Record{Time: now, Level: LevelInfo, Prefix: "Test", Message: "same line"})
+	}
+	sink.send(Record{Time: now, Level: LevelError, Prefix: "Test", Message: "other line"})
+	sink.close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if calls != 2 || len(bodies) != 1 {
+		t.Fatalf("Expected one retried batch, got %d calls and %d deliveries", calls, len(bodies))
+	}
+	var payload struct {
+		Embeds []struct {
+			Title       string `json:"title"`
+			Description string `json:"description"`
+		} `json:"embeds"`
+	}
+	if err := json.Unmarshal([]byte(bodies[0]), &payload); err != nil {
+		t.Fatal(err)
+	}
+	if len(payload.Embeds) != 2 {
+		t.Fatalf("Expected an embed per level, got %d", len(payload.Embeds))
+	}
+	if d := payload.Embeds[0].Description; strings.Count(d, "same line") != 1 || !strings.Contains(d, "(x3)") {
+		t.Errorf("Expected the repeated line once with a counter, got %q", d)
+	}
+}
+
+func TestWebhookPayloadLimits(t *testing.T) {
+	var batch []Record
+	for i := 0; i < 40; i++ {
+		batch = append(batch, Record{Time: time.Now(), Level: LogLevel(i % 2), Prefix: "Test", Message: strings.Repeat("a", 500) + string(rune('A'+i%26)) + strings.Repeat("b", i)})
+	}
+	payloads := buildWebhookPayloads(batch, 5)
+	if len(payloads) < 2 {
+		t.Fatalf("Expected the batch to be split, got %d payloads", len(payloads))
+	}
+	for _, payload := range payloads {
+		embeds := payload["embeds"].([]interface{})
+		total := 0
+		for _, embed := range embeds {
+			total += len(embed.(map[string]interface{})["description"].(string))
+		}
+		if len(embeds) > webhookMaxEmbeds || total > 6000 {
+			t.Errorf("Payload over the Discord limits: %d embeds, %d chars", len(embeds), total)
+		}
+	}
+	last := payloads[len(payloads)-1]["embeds"].([]interface{})
+	if d := last[len(last)-1].(map[string]interface{})["description"].(string); !strings.Contains(d, "5 líneas") {
+		t.Errorf("Expected the dropped lines to be reported, got %q", d)
+	}
+}
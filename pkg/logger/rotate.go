@@ -0,0 +1,183 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rotation controls when a log file is rotated and how many old files are kept
+type Rotation struct {
+	MaxSize    int64         // Bytes before the file is rotated, 0 disables it
+	Interval   time.Duration // Age before the file is rotated, 0 disables it
+	MaxBackups int           // Rotated files kept, 0 keeps all of them
+	MaxAge     time.Duration // Rotated files older than this are deleted, 0 keeps them
+}
+
+// DefaultRotation rotates every day or every 20 MB and keeps two weeks of logs
+var DefaultRotation = Rotation{
+	MaxSize:    20 << 20,
+	Interval:   24 * time.Hour,
+	MaxBackups: 14,
+	MaxAge:     14 * 24 * time.Hour,
+}
+
+const backupTimeFormat = "20060102-150405"
+
+// rotatingFile is a log file that moves itself aside when it gets too big or too
+// old. Rotated files are named like combined-20060102-150405.log.
+type rotatingFile struct {
+	mu       sync.Mutex
+	path     string
+	rotation Rotation
+	file     *os.File
+	size     int64
+	opened   time.Time
+	now      func() time.Time
+}
+
+func openRotatingFile(path string, rotation Rotation) (*rotatingFile, error) {
+	f := &rotatingFile{path: path, rotation: rotation, now: time.Now}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	f.file = file
+	f.size = 0
+	f.opened = f.now()
+	if info, err := file.Stat(); err == nil && info.Size() > 0 {
+		// A file left by a previous run counts from its last write
+		f.size = info.Size()
+		f.opened = info.ModTime()
+	}
+	return nil
+}
+
+// SetRotation changes the rotation of the file
+func (f *rotatingFile) SetRotation(rotation Rotation) {
+	f.mu.Lock()
+	f.rotation = rotation
+	f.mu.Unlock()
+}
+
+// WriteString writes a line, rotating the file first if it is due
+func (f *rotatingFile) WriteString(s string) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	if f.due(int64(len(s))) {
+		if err := f.rotate(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error rotating %s: %v\n", f.path, err)
+		}
+	}
+	n, err := f.file.WriteString(s)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *rotatingFile) due(next int64) bool {
+	if f.size == 0 {
+		return false
+	}
+	if f.rotation.MaxSize > 0 && f.size+next > f.rotation.MaxSize {
+		return true
+	}
+	return f.rotation.Interval > 0 && f.now().Sub(f.opened) >= f.rotation.Interval
+}
+
+// rotate moves the current file aside, opens a new one and prunes old backups
+func (f *rotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+
+	backup := f.backupName(f.now())
+	for i := 1; fileExists(backup); i++ {
+		backup = f.backupName(f.now()) + fmt.Sprintf(".%d", i)
+	}
+	renameErr := os.Rename(f.path, backup)
+
+	if err := f.open(); err != nil {
+		return err
+	}
+	if renameErr != nil {
+		return renameErr
+	}
+	f.prune()
+	return nil
+}
+
+func (f *rotatingFile) backupName(t time.Time) string {
+	ext := filepath.Ext(f.path)
+	return strings.TrimSuffix(f.path, ext) + "-" + t.Format(backupTimeFormat) + ext
+}
+
+// backups returns the rotated files of this log, oldest first
+func (f *rotatingFile) backups() []string {
+	ext := filepath.Ext(f.path)
+	matches, err := filepath.Glob(strings.TrimSuffix(f.path, ext) + "-*" + ext + "*")
+	if err != nil {
+		return nil
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+// prune deletes the backups over the limits
+func (f *rotatingFile) prune() {
+	backups := f.backups()
+	now := f.now()
+	for i, backup := range backups {
+		remove := f.rotation.MaxBackups > 0 && len(backups)-i > f.rotation.MaxBackups
+		if !remove && f.rotation.MaxAge > 0 {
+			if info, err := os.Stat(backup); err == nil && now.Sub(info.ModTime()) > f.rotation.MaxAge {
+				remove = true
+			}
+		}
+		if remove {
+			os.Remove(backup)
+		}
+	}
+}
+
+// Close closes the file
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// SetRotation changes the rotation of both log files
+func (l *Logger) SetRotation(rotation Rotation) {
+	if l.logFile != nil {
+		l.logFile.SetRotation(rotation)
+	}
+	if l.errorFile != nil {
+		l.errorFile.SetRotation(rotation)
+	}
+}
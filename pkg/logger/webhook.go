@@ -0,0 +1,332 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	webhookFlushInterval = 2 * time.Second
+	webhookQueueSize     = 1000
+	webhookMaxBatch      = 100  // Lines read before a flush
+	webhookMaxEmbeds     = 10   // Discord limit per message
+	webhookMaxDesc       = 4000 // Under the 4096 limit of a description
+	webhookMaxTotal      = 5500 // Under the 6000 limit of a message
+	webhookMaxLine       = 1000
+	webhookMaxRetries    = 5
+	webhookMaxWait       = time.Minute
+)
+
+// webhookSink delivers log lines to a Discord webhook. Lines are queued and sent
+// in batches, repeated lines are sent once with a counter and rate limits are
+// waited out instead of losing the lines. When the queue is full lines are
+// dropped and the next batch says how many.
+type webhookSink struct {
+	url        string
+	client     *http.Client
+	queue      chan Record
+	flushEvery time.Duration
+	dropped    atomic.Int64
+	done       chan struct{}
+	stopped    chan struct{}
+	closeOnce  sync.Once
+}
+
+func newWebhookSink(url string, flushEvery time.Duration) *webhookSink {
+	w := &webhookSink{
+		url:        url,
+		client:     &http.Client{Timeout: 5 * time.Second},
+		queue:      make(chan Record, webhookQueueSize),
+		flushEvery: flushEvery,
+		done:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// send queues a line without blocking the caller
+func (w *webhookSink) send(r Record) {
+	if w == nil {
+		return
+	}
+	select {
+	case <-w.done:
+		return
+	default:
+	}
+	select {
+	case w.queue <- r:
+	default:
+		w.dropped.Add(1)
+	}
+}
+
+// close sends what is left in the queue and stops the sink
+func (w *webhookSink) close() {
+	if w == nil {
+		return
+	}
+	w.closeOnce.Do(func() { close(w.done) })
+	select {
+	case <-w.stopped:
+	case <-time.After(10 * time.Second):
+	}
+}
+
+func (w *webhookSink) run() {
+	defer close(w.stopped)
+	for {
+		var batch []Record
+		select {
+		case r := <-w.queue:
+			batch = append(batch, r)
+		case <-w.done:
+			w.flush(w.drain(nil))
+			return
+		}
+
+		// Wait a little so the lines of a burst go together
+		timer := time.NewTimer(w.flushEvery)
+	collect:
+		for len(batch) < webhookMaxBatch {
+			select {
+			case r := <-w.queue:
+				batch = append(batch, r)
+			case <-timer.C:
+				break collect
+			case <-w.done:
+				batch = w.drain(batch)
+				break collect
+			}
+		}
+		timer.Stop()
+		w.flush(batch)
+	}
+}
+
+// drain takes every queued line without waiting
+func (w *webhookSink) drain(batch []Record) []Record {
+	for {
+		select {
+		case r := <-w.queue:
+			batch = append(batch, r)
+		default:
+			return batch
+		}
+	}
+}
+
+func (w *webhookSink) flush(batch []Record) {
+	dropped := w.dropped.Swap(0)
+	if len(batch) == 0 && dropped == 0 {
+		return
+	}
+	for _, payload := range buildWebhookPayloads(batch, dropped) {
+		w.post(payload)
+	}
+}
+
+// post sends a payload, waiting out rate limits and retrying failures
+func (w *webhookSink) post(payload map[string]interface{}) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+
+	backoff := time.Second
+	for attempt := 0; attempt < webhookMaxRetries; attempt++ {
+		resp, err := w.client.Post(w.url, "application/json", bytes.NewReader(data))
+		if err != nil {
+			w.wait(backoff)
+			backoff *= 2
+			continue
+		}
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+
+		switch {
+		case resp.StatusCode == http.StatusTooManyRequests:
+			w.wait(retryAfter(resp.Header, body))
+			continue
+		case resp.StatusCode >= 500:
+			w.wait(backoff)
+			backoff *= 2
+			continue
+		case resp.StatusCode >= 400:
+			// The payload is wrong, retrying won't help
+			fmt.Fprintf(os.Stderr, "Log webhook rejected a batch: %s %s\n", resp.Status, strings.TrimSpace(string(body)))
+			return
+		}
+
+		// Don't hit the limit when the bucket is already empty
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			w.wait(secondsHeader(resp.Header.Get("X-RateLimit-Reset-After")))
+		}
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Log webhook gave up on a batch after %d attempts\n", webhookMaxRetries)
+}
+
+func (w *webhookSink) wait(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	if d > webhookMaxWait {
+		d = webhookMaxWait
+	}
+	time.Sleep(d)
+}
+
+// retryAfter reads how long Discord wants us to wait after a 429
+func retryAfter(header http.Header, body []byte) time.Duration {
+	var limited struct {
+		RetryAfter float64 `json:"retry_after"`
+	}
+	if json.Unmarshal(body, &limited) == nil && limited.RetryAfter > 0 {
+		return time.Duration(limited.RetryAfter * float64(time.Second))
+	}
+	if d := secondsHeader(header.Get("Retry-After")); d > 0 {
+		return d
+	}
+	if d := secondsHeader(header.Get("X-RateLimit-Reset-After")); d > 0 {
+		return d
+	}
+	return time.Second
+}
+
+func secondsHeader(value string) time.Duration {
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// webhookLine is a distinct line of a batch with how many times it was logged
+type webhookLine struct {
+	record Record
+	count  int
+}
+
+// buildWebhookPayloads groups a batch into as few messages as the Discord limits
+// allow. Repeated lines are sent once with a counter and consecutive lines of
+// the same level share an embed.
+func buildWebhookPayloads(batch []Record, dropped int64) []map[string]interface{} {
+	var lines []*webhookLine
+	seen := make(map[string]*webhookLine)
+	for _, r := range batch {
+		key := r.Level.String() + "|" + r.Prefix + "|" + r.Message + r.fieldsText()
+		if line, ok := seen[key]; ok {
+			line.count++
+			continue
+		}
+		line := &webhookLine{record: r, count: 1}
+		seen[key] = line
+		lines = append(lines, line)
+	}
+
+	var embeds []map[string]interface{}
+	var current map[string]interface{}
+	var desc strings.Builder
+	var level LogLevel
+
+	closeEmbed := func() {
+		if current == nil {
+			return
+		}
+		current["description"] = desc.String()
+		embeds = append(embeds, current)
+		current = nil
+		desc.Reset()
+	}
+	for _, line := range lines {
+		text := webhookText(line)
+		if current != nil && (line.record.Level != level || desc.Len()+len(text)+1 > webhookMaxDesc) {
+			closeEmbed()
+		}
+		if current == nil {
+			level = line.record.Level
+			current = map[string]interface{}{
+				"title":     fmt.Sprintf("[%s] %s", level.String(), line.record.Prefix),
+				"color":     level.DiscordColor(),
+				"timestamp": line.record.Time.Format(time.RFC3339),
+				"footer": map[string]string{
+					"text": "💫 Developed by PancyStudio | PancyBot Go",
+				},
+			}
+		} else if current["title"] != fmt.Sprintf("[%s] %s", level.String(), line.record.Prefix) {
+			current["title"] = fmt.Sprintf("[%s] Varios", level.String())
+		}
+		if desc.Len() > 0 {
+			desc.WriteString("\n")
+		}
+		desc.WriteString(text)
+	}
+	closeEmbed()
+
+	if dropped > 0 {
+		embeds = append(embeds, map[string]interface{}{
+			"title":       "[WARN] Logger",
+			"description": fmt.Sprintf("Se descartaron %d líneas porque la cola del webhook estaba llena.", dropped),
+			"color":       LevelWarn.DiscordColor(),
+			"timestamp":   time.Now().Format(time.RFC3339),
+		})
+	}
+
+	// Split the embeds in messages under the count and size limits
+	var payloads []map[string]interface{}
+	var group []interface{}
+	total := 0
+	for _, embed := range embeds {
+		size := len(embed["description"].(string)) + len(embed["title"].(string))
+		if len(group) > 0 && (len(group) >= webhookMaxEmbeds || total+size > webhookMaxTotal) {
+			payloads = append(payloads, map[string]interface{}{"embeds": group})
+			group, total = nil, 0
+		}
+		group = append(group, embed)
+		total += size
+	}
+	if len(group) > 0 {
+		payloads = append(payloads, map[string]interface{}{"embeds": group})
+	}
+	return payloads
+}
+
+// webhookText formats a line for an embed description
+func webhookText(line *webhookLine) string {
+	r := line.record
+	text := r.Message + r.fieldsText()
+	if len(text) > webhookMaxLine {
+		text = truncateUTF8(text, webhookMaxLine) + "…"
+	}
+	text = strings.ReplaceAll(text, "```", "'''")
+	out := fmt.Sprintf("`%s` **[%s]** ```%s```", r.Time.Format("15:04:05"), r.Prefix, text)
+	if line.count > 1 {
+		out += fmt.Sprintf(" (x%d)", line.count)
+	}
+	return out
+}
+
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !isRuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
@@ -0,0 +1,421 @@
+// Package metrics exposes counters, gauges and histograms of the bot in the
+// Prometheus text format. Packages declare their metrics next to the code that
+// updates them; values that already live elsewhere (cache sizes, players,
+// latency) are read with gauge functions when the endpoint is scraped.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets suit command and request latencies, in seconds
+var DefaultBuckets = []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// collector is a metric family that can write itself
+type collector interface {
+	name() string
+	write(w *bufio.Writer)
+}
+
+// Registry holds the metric families served by an endpoint
+type Registry struct {
+	mu         sync.RWMutex
+	collectors map[string]collector
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{collectors: make(map[string]collector)}
+}
+
+// Default is the registry the package functions and the /metrics endpoint use
+var Default = NewRegistry()
+
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.collectors[c.name()]; exists {
+		panic("metrics: duplicate metric " + c.name())
+	}
+	r.collectors[c.name()] = c
+}
+
+// WriteTo writes every family in the Prometheus text format, sorted by name
+func (r *Registry) WriteTo(out io.Writer) (int64, error) {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.collectors))
+	for name := range r.collectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	collectors := make([]collector, len(names))
+	for i, name := range names {
+		collectors[i] = r.collectors[name]
+	}
+	r.mu.RUnlock()
+
+	cw := &countingWriter{w: out}
+	w := bufio.NewWriter(cw)
+	for _, c := range collectors {
+		c.write(w)
+	}
+	err := w.Flush()
+	return cw.n, err
+}
+
+// Handler serves the registry
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteTo(w)
+	})
+}
+
+// Handler serves the default registry
+func Handler() http.Handler {
+	return Default.Handler()
+}
+
+// family has the fields shared by every metric type
+type family struct {
+	metricName string
+	help       string
+	labels     []string
+}
+
+func (f *family) name() string { return f.metricName }
+
+func (f *family) header(w *bufio.Writer, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", f.metricName, escapeHelp(f.help), f.metricName, kind)
+}
+
+func (f *family) key(values []string) string {
+	if len(values) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", f.metricName, len(f.labels), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+// Counter is a value that only goes up, split by label values
+type Counter struct {
+	family
+	mu     sync.Mutex
+	values map[string]*sample
+}
+
+type sample struct {
+	labels []string
+	value  float64
+}
+
+// NewCounter registers a counter in the default registry
+func NewCounter(name, help string, labels ...string) *Counter {
+	return Default.NewCounter(name, help, labels...)
+}
+
+// NewCounter registers a counter in the registry
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{family: family{name, help, labels}, values: make(map[string]*sample)}
+	r.register(c)
+	return c
+}
+
+// Inc adds one to the series of the label values
+func (c *Counter) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add adds a non-negative amount to the series of the label values
+func (c *Counter) Add(v float64, values ...string) {
+	if v < 0 {
+		return
+	}
+	key := c.key(values)
+	c.mu.Lock()
+	s, ok := c.values[key]
+	if !ok {
+		s = &sample{labels: append([]string(nil), values...)}
+		c.values[key] = s
+	}
+	s.value += v
+	c.mu.Unlock()
+}
+
+// Value returns the current value of a series
+func (c *Counter) Value(values ...string) float64 {
+	key := c.key(values)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s, ok := c.values[key]; ok {
+		return s.value
+	}
+	return 0
+}
+
+func (c *Counter) write(w *bufio.Writer) {
+	c.header(w, "counter")
+	c.mu.Lock()
+	samples := sortedSamples(c.values)
+	c.mu.Unlock()
+	for _, s := range samples {
+		writeSample(w, c.metricName, c.labels, s.labels, s.value)
+	}
+}
+
+// Gauge is a value that goes up and down, split by label values
+type Gauge struct {
+	Counter
+}
+
+// NewGauge registers a gauge in the default registry
+func NewGauge(name, help string, labels ...string) *Gauge {
+	return Default.NewGauge(name, help, labels...)
+}
+
+// NewGauge registers a gauge in the registry
+func (r *Registry) NewGauge(name, help string, labels ...string) *Gauge {
+	g := &Gauge{Counter{family: family{name, help, labels}, values: make(map[string]*sample)}}
+	r.register(g)
+	return g
+}
+
+// Set changes the value of the series of the label values
+func (g *Gauge) Set(v float64, values ...string) {
+	key := g.key(values)
+	g.mu.Lock()
+	s, ok := g.values[key]
+	if !ok {
+		s = &sample{labels: append([]string(nil), values...)}
+		g.values[key] = s
+	}
+	s.value = v
+	g.mu.Unlock()
+}
+
+// Add moves the series of the label values by v, which may be negative
+func (g *Gauge) Add(v float64, values ...string) {
+	key := g.key(values)
+	g.mu.Lock()
+	s, ok := g.values[key]
+	if !ok {
+		s = &sample{labels: append([]string(nil), values...)}
+		g.values[key] = s
+	}
+	s.value += v
+	g.mu.Unlock()
+}
+
+func (g *Gauge) write(w *bufio.Writer) {
+	g.header(w, "gauge")
+	g.mu.Lock()
+	samples := sortedSamples(g.values)
+	g.mu.Unlock()
+	for _, s := range samples {
+		writeSample(w, g.metricName, g.labels, s.labels, s.value)
+	}
+}
+
+// GaugeFunc reads its values when the registry is scraped
+type GaugeFunc struct {
+	family
+	collect func(emit func(value float64, values ...string))
+}
+
+// NewGaugeFunc registers a gauge function in the default registry. collect
+// calls emit once for every series.
+func NewGaugeFunc(name, help string, labels []string, collect func(emit func(value float64, values ...string))) *GaugeFunc {
+	return Default.NewGaugeFunc(name, help, labels, collect)
+}
+
+// NewGaugeFunc registers a gauge function in the registry
+func (r *Registry) NewGaugeFunc(name, help string, labels []string, collect func(emit func(value float64, values ...string))) *GaugeFunc {
+	g := &GaugeFunc{family: family{name, help, labels}, collect: collect}
+	r.register(g)
+	return g
+}
+
+func (g *GaugeFunc) write(w *bufio.Writer) {
+	g.header(w, "gauge")
+	var samples []*sample
+	g.collect(func(value float64, values ...string) {
+		if len(values) != len(g.labels) {
+			return
+		}
+		samples = append(samples, &sample{labels: values, value: value})
+	})
+	sort.Slice(samples, func(i, j int) bool {
+		return strings.Join(samples[i].labels, "\xff") < strings.Join(samples[j].labels, "\xff")
+	})
+	for _, s := range samples {
+		writeSample(w, g.metricName, g.labels, s.labels, s.value)
+	}
+}
+
+// Histogram counts observations in buckets, split by label values
+type Histogram struct {
+	family
+	buckets []float64
+	mu      sync.Mutex
+	series  map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	labels []string
+	counts []uint64 // One per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// NewHistogram registers a histogram in the default registry. Nil buckets use
+// DefaultBuckets.
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	return Default.NewHistogram(name, help, buckets, labels...)
+}
+
+// NewHistogram registers a histogram in the registry
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+	h := &Histogram{family: family{name, help, labels}, buckets: sorted, series: make(map[string]*histogramSeries)}
+	r.register(h)
+	return h
+}
+
+// Observe records a value in the series of the label values
+func (h *Histogram) Observe(v float64, values ...string) {
+	key := h.key(values)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{labels: append([]string(nil), values...), counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		s.counts[i]++
+	}
+	s.count++
+	s.sum += v
+}
+
+func (h *Histogram) write(w *bufio.Writer) {
+	h.header(w, "histogram")
+
+	h.mu.Lock()
+	keys := make([]string, 0, len(h.series))
+	for key := range h.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	series := make([]histogramSeries, len(keys))
+	for i, key := range keys {
+		s := h.series[key]
+		series[i] = histogramSeries{labels: s.labels, counts: append([]uint64(nil), s.counts...), count: s.count, sum: s.sum}
+	}
+	h.mu.Unlock()
+
+	labels := append(append([]string(nil), h.labels...), "le")
+	for _, s := range series {
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += s.counts[i]
+			writeSample(w, h.metricName+"_bucket", labels, append(append([]string(nil), s.labels...), formatFloat(bound)), float64(cumulative))
+		}
+		writeSample(w, h.metricName+"_bucket", labels, append(append([]string(nil), s.labels...), "+Inf"), float64(s.count))
+		writeSample(w, h.metricName+"_sum", h.labels, s.labels, s.sum)
+		writeSample(w, h.metricName+"_count", h.labels, s.labels, float64(s.count))
+	}
+}
+
+func sortedSamples(values map[string]*sample) []sample {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	out := make([]sample, len(keys))
+	for i, key := range keys {
+		out[i] = *values[key]
+	}
+	return out
+}
+
+func writeSample(w *bufio.Writer, name string, labels, values []string, value float64) {
+	w.WriteString(name)
+	if len(labels) > 0 {
+		w.WriteByte('{')
+		for i, label := range labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(label)
+			w.WriteString(`="`)
+			w.WriteString(escapeLabel(values[i]))
+			w.WriteByte('"')
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(value))
+	w.WriteByte('\n')
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// Runtime metrics of the process, the same numbers /admin/api/stats shows
+func init() {
+	NewGaugeFunc("go_goroutines", "Number of goroutines that currently exist.", nil, func(emit func(float64, ...string)) {
+		emit(float64(runtime.NumGoroutine()))
+	})
+	NewGaugeFunc("go_memstats_alloc_bytes", "Number of bytes allocated and still in use.", nil, func(emit func(float64, ...string)) {
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+		emit(float64(m.Alloc))
+	})
+	NewGaugeFunc("go_memstats_sys_bytes", "Number of bytes obtained from the system.", nil, func(emit func(float64, ...string)) {
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+		emit(float64(m.Sys))
+	})
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistryTextFormat(t *testing.T) {
+	r := NewRegistry()
+	commands := r.NewCounter("test_commands_total", "Commands run.", "command", "result")
+	latency := r.NewHistogram("test_duration_seconds", "Duration.", []float64{0.1, 1}, "command")
+	r.NewGaugeFunc("test_players", "Players.", []string{"state"}, func(emit func(float64, ...string)) {
+		emit(2, "playing")
+		emit(1, `we"ird`)
+	})
+
+	commands.Inc("ping", "ok")
+	commands.Inc("ping", "ok")
+	commands.Inc("ban", "error")
+	latency.Observe(0.05, "ping")
+	latency.Observe(0.1, "ping")
+	latency.Observe(3, "ping")
+
+	var b strings.Builder
+	if _, err := r.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+
+	for _, want := range []string{
+		"# TYPE test_commands_total counter\n",
+		`test_commands_total{command="ban",result="error"} 1` + "\n",
+		`test_commands_total{command="ping",result="ok"} 2` + "\n",
+		"# TYPE test_duration_seconds histogram\n",
+		`test_duration_seconds_bucket{command="ping",le="0.1"} 2` + "\n",
+		`test_duration_seconds_bucket{command="ping",le="1"} 2` + "\n",
+		`test_duration_seconds_bucket{command="ping",le="+Inf"} 3` + "\n",
+		`test_duration_seconds_sum{command="ping"} 3.15` + "\n",
+		`test_duration_seconds_count{command="ping"} 3` + "\n",
+		`test_players{state="playing"} 2` + "\n",
+		`test_players{state="we\"ird"} 1` + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	// Families are written in name order
+	if strings.Index(out, "test_commands_total") > strings.Index(out, "test_players") {
+		t.Error("Expected families sorted by name")
+	}
+}
+
+func TestRegistryRejectsDuplicates(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("dup_total", "First.")
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a duplicate metric to panic")
+		}
+	}()
+	r.NewGauge("dup_total", "Second.")
+}
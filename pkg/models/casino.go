@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// OpenWager is a casino stake taken from a wallet whose game is still open. It
+// is removed when the game settles, so one left behind by a restart is refunded.
+type OpenWager struct {
+	ID        string    `bson:"_id" json:"id"` // Blackjack table or duel ID
+	GuildID   string    `bson:"guild_id" json:"guild_id"`
+	UserID    string    `bson:"user_id" json:"user_id"`
+	Global    bool      `bson:"global" json:"global"`
+	Game      string    `bson:"game" json:"game"`
+	Amount    int64     `bson:"amount" json:"amount"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	ExpiresAt time.Time `bson:"expires_at" json:"expires_at"` // When the game times out
+}
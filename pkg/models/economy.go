@@ -30,6 +30,37 @@ type Item struct {
 	RoleID      string   `bson:"role_id,omitempty" json:"role_id,omitempty"` // If type is role
 	Effect      string   `bson:"effect,omitempty" json:"effect,omitempty"`   // EFFECT_BANK_CAPACITY, etc.
 	EffectValue float64  `bson:"effect_value,omitempty" json:"effect_value,omitempty"`
+	Duration    int      `bson:"duration_minutes,omitempty" json:"duration_minutes,omitempty"` // Buffs and timed roles
+	Required    bool     `bson:"required,omitempty" json:"required,omitempty"`                 // Tools: the command can't be used without one
+}
+
+// ActiveBuff is a timed effect applied to a profile by using an item
+type ActiveBuff struct {
+	Value     float64   `bson:"value" json:"value"`
+	ItemID    string    `bson:"item_id" json:"item_id"`
+	ExpiresAt time.Time `bson:"expires_at" json:"expires_at"`
+}
+
+// Buffs maps an effect ID to its active buff
+type Buffs map[string]ActiveBuff
+
+// Active returns the buff for an effect if it has not expired
+func (b Buffs) Active(effect string) (ActiveBuff, bool) {
+	buff, ok := b[effect]
+	if !ok || !time.Now().Before(buff.ExpiresAt) {
+		return ActiveBuff{}, false
+	}
+	return buff, true
+}
+
+// TimedRole is a role granted by an item that must be removed when it expires
+type TimedRole struct {
+	ID        string    `bson:"_id" json:"id"` // Format: GuildID_UserID_RoleID
+	GuildID   string    `bson:"guild_id" json:"guild_id"`
+	UserID    string    `bson:"user_id" json:"user_id"`
+	RoleID    string    `bson:"role_id" json:"role_id"`
+	ItemID    string    `bson:"item_id" json:"item_id"`
+	ExpiresAt time.Time `bson:"expires_at" json:"expires_at"`
 }
 
 // GlobalEconomyProfile represents a user's global economy (Stars)
@@ -37,23 +68,28 @@ type GlobalEconomyProfile struct {
 	UserID       string               `bson:"_id" json:"user_id"`
 	StarsWallet  int64                `bson:"stars_wallet" json:"stars_wallet"`
 	StarsBank    int64                `bson:"stars_bank" json:"stars_bank"`
+	NetWorth     int64                `bson:"net_worth" json:"net_worth"` // Wallet + bank, kept by the ledger for the leaderboards
 	BankCapacity int64                `bson:"bank_capacity" json:"bank_capacity"`
 	Inventory    map[string]int       `bson:"inventory" json:"inventory"` // ItemID -> Quantity
 	Cooldowns    map[string]time.Time `bson:"cooldowns" json:"cooldowns"`
+	Buffs        Buffs                `bson:"buffs,omitempty" json:"buffs,omitempty"`
 	CreatedAt    time.Time            `bson:"created_at" json:"created_at"`
 	UpdatedAt    time.Time            `bson:"updated_at" json:"updated_at"`
 }
 
 // LocalEconomyProfile represents a user's local economy in a specific server
 type LocalEconomyProfile struct {
-	ID           string               `bson:"_id" json:"id"` // Format: GuildID_UserID
-	GuildID      string               `bson:"guild_id" json:"guild_id"`
-	UserID       string               `bson:"user_id" json:"user_id"`
-	Wallet       int64                `bson:"wallet" json:"wallet"`
-	Bank         int64                `bson:"bank" json:"bank"`
-	BankCapacity int64                `bson:"bank_capacity" json:"bank_capacity"`
-	Inventory    map[string]int       `bson:"inventory" json:"inventory"` // ItemID -> Quantity
-	Cooldowns    map[string]time.Time `bson:"cooldowns" json:"cooldowns"`
-	CreatedAt    time.Time            `bson:"created_at" json:"created_at"`
-	UpdatedAt    time.Time            `bson:"updated_at" json:"updated_at"`
+	ID             string               `bson:"_id" json:"id"` // Format: GuildID_UserID
+	GuildID        string               `bson:"guild_id" json:"guild_id"`
+	UserID         string               `bson:"user_id" json:"user_id"`
+	Wallet         int64                `bson:"wallet" json:"wallet"`
+	Bank           int64                `bson:"bank" json:"bank"`
+	NetWorth       int64                `bson:"net_worth" json:"net_worth"`             // Wallet + bank, kept by the ledger for the leaderboards
+	SeasonEarnings int64                `bson:"season_earnings" json:"season_earnings"` // Net earnings of the current season, see pkg/seasons
+	BankCapacity   int64                `bson:"bank_capacity" json:"bank_capacity"`
+	Inventory      map[string]int       `bson:"inventory" json:"inventory"` // ItemID -> Quantity
+	Cooldowns      map[string]time.Time `bson:"cooldowns" json:"cooldowns"`
+	Buffs          Buffs                `bson:"buffs,omitempty" json:"buffs,omitempty"`
+	CreatedAt      time.Time            `bson:"created_at" json:"created_at"`
+	UpdatedAt      time.Time            `bson:"updated_at" json:"updated_at"`
 }
@@ -6,15 +6,23 @@ import (
 	"go.mongodb.org/mongo-driver/bson/bsontype"
 )
 
+// GuildSchemaVersion is the configuration._version of the guilds written by
+// this code. Older documents are upgraded by the database migrations.
+const GuildSchemaVersion = "2.0.0"
+
 // GuildDocument represents the main document for a guild in MongoDB
 type GuildDocument struct {
 	ID            string             `bson:"id" json:"id"`
 	ObjectID      interface{}        `bson:"_id,omitempty" json:"-"`
+	Version       int64              `bson:"version" json:"version"` // Incremented on every write, see database.DataManagerOptions
 	Configuration GuildConfiguration `bson:"configuration" json:"configuration"`
 	Greetings     Greetings          `bson:"greetings" json:"greetings"`
 	Moderation    ModeratorData      `bson:"moderation" json:"moderation"`
 	Protection    ProtectionConfig   `bson:"protection" json:"protection"`
 	Levels        LevelsConfig       `bson:"levels" json:"levels"`
+	Invites       InvitesConfig      `bson:"invites" json:"invites"`
+	Economy       EconomyConfig      `bson:"economy" json:"economy"`
+	Seasons       SeasonsConfig      `bson:"seasons" json:"seasons"`
 	Embeds        []CustomEmbed      `bson:"embeds" json:"embeds"`
 	PingOnJoin    []PingOnJoinConfig `bson:"pingOnJoin" json:"pingOnJoin"`
 }
@@ -40,12 +48,76 @@ type LevelReward struct {
 	RoleID string `bson:"roleId" json:"roleId"`
 }
 
-// LevelsConfig holds user level system settings
+// LevelsConfig holds user level system settings. Zero values of the XP settings
+// keep the bot defaults, see pkg/leveling.
 type LevelsConfig struct {
-	Enable         bool          `bson:"enable" json:"enable"`
-	LevelUpChannel string        `bson:"levelUpChannel" json:"levelUpChannel"` // Empty for same channel
-	LevelUpMessage string        `bson:"levelUpMessage" json:"levelUpMessage"` // Available placeholders: {user}, {level}
-	Rewards        []LevelReward `bson:"rewards" json:"rewards"`
+	Enable             bool               `bson:"enable" json:"enable"`
+	LevelUpChannel     string             `bson:"levelUpChannel" json:"levelUpChannel"` // Empty for same channel
+	LevelUpMessage     string             `bson:"levelUpMessage" json:"levelUpMessage"` // Template, see pkg/templates
+	Rewards            []LevelReward      `bson:"rewards" json:"rewards"`
+	RewardMode         string             `bson:"rewardMode" json:"rewardMode"` // "stack" (default) or "replace"
+	MinXP              int64              `bson:"minXp" json:"minXp"`           // Per message
+	MaxXP              int64              `bson:"maxXp" json:"maxXp"`
+	CooldownSeconds    int                `bson:"cooldownSeconds" json:"cooldownSeconds"` // Between two XP gains
+	Curve              string             `bson:"curve" json:"curve"`                     // "quadratic" (default), "linear" or "exponential"
+	CurveBase          int64              `bson:"curveBase" json:"curveBase"`
+	RoleMultipliers    map[string]float64 `bson:"roleMultipliers" json:"roleMultipliers"`       // RoleID -> multiplier, the best one applies
+	ChannelMultipliers map[string]float64 `bson:"channelMultipliers" json:"channelMultipliers"` // ChannelID -> multiplier
+	NoXPChannels       []string           `bson:"noXpChannels" json:"noXpChannels"`
+	NoXPRoles          []string           `bson:"noXpRoles" json:"noXpRoles"`
+	PremiumBoost       float64            `bson:"premiumBoost" json:"premiumBoost"`   // Multiplier for premium users and guilds
+	VoiceXP            int64              `bson:"voiceXp" json:"voiceXp"`             // Per minute in voice channels
+	VoiceDisabled      bool               `bson:"voiceDisabled" json:"voiceDisabled"` // No XP for voice time
+}
+
+// InviteReward represents a role given when a member reaches a number of invites
+type InviteReward struct {
+	Invites int64  `bson:"invites" json:"invites"`
+	RoleID  string `bson:"roleId" json:"roleId"`
+}
+
+// InvitesConfig holds the invite tracker settings
+type InvitesConfig struct {
+	Enable          bool           `bson:"enable" json:"enable"`
+	LogChannel      string         `bson:"logChannel" json:"logChannel"`
+	FakeAccountDays int            `bson:"fakeAccountDays" json:"fakeAccountDays"` // Accounts younger than this count as fake invites
+	Rewards         []InviteReward `bson:"rewards" json:"rewards"`
+}
+
+// EconomyCommandConfig tunes one economy command of the local economy. Zero values
+// keep the bot defaults. For rob the payout and the fine are percentages of the
+// victim and the robber wallets.
+type EconomyCommandConfig struct {
+	Disabled        bool     `bson:"disabled" json:"disabled"`
+	MinPayout       int64    `bson:"minPayout" json:"minPayout"`
+	MaxPayout       int64    `bson:"maxPayout" json:"maxPayout"`
+	SuccessRate     int      `bson:"successRate" json:"successRate"` // Percent
+	MinFine         int64    `bson:"minFine" json:"minFine"`
+	MaxFine         int64    `bson:"maxFine" json:"maxFine"`
+	CooldownMinutes int      `bson:"cooldownMinutes" json:"cooldownMinutes"`
+	Messages        []string `bson:"messages" json:"messages"`         // Success replies, see pkg/ecoconfig
+	FailMessages    []string `bson:"failMessages" json:"failMessages"` // Failure replies
+}
+
+// EconomyConfig holds the local economy settings of a server
+type EconomyConfig struct {
+	CurrencyName    string                          `bson:"currencyName" json:"currencyName"`
+	CurrencyEmoji   string                          `bson:"currencyEmoji" json:"currencyEmoji"`
+	StartingBalance int64                           `bson:"startingBalance" json:"startingBalance"`
+	BankCapacity    int64                           `bson:"bankCapacity" json:"bankCapacity"` // Of new profiles
+	Commands        map[string]EconomyCommandConfig `bson:"commands" json:"commands"`
+	Casino          CasinoConfig                    `bson:"casino" json:"casino"`
+}
+
+// CasinoConfig tunes the games of chance of the local economy. Zero values keep the
+// bot defaults.
+type CasinoConfig struct {
+	Disabled     bool               `bson:"disabled" json:"disabled"`
+	MinBet       int64              `bson:"minBet" json:"minBet"`
+	MaxBet       int64              `bson:"maxBet" json:"maxBet"`
+	HouseEdge    int                `bson:"houseEdge" json:"houseEdge"`       // Percent kept from every win
+	SlotsPayouts map[string]float64 `bson:"slotsPayouts" json:"slotsPayouts"` // Symbol -> multiplier of three of a kind
+	SlotsPair    float64            `bson:"slotsPair" json:"slotsPair"`       // Multiplier of two of a kind
 }
 
 // ProtectionConfig holds security settings like antibots and antiraid
@@ -74,7 +146,8 @@ type AntibotsConfig struct {
 	Type   string `bson:"_type" json:"_type"`
 }
 
-// UnmarshalBSONValue handles decoding when antibots is a string in legacy data
+// UnmarshalBSONValue handles decoding when antibots is a string in legacy data,
+// written before the guild was migrated to schema 2.0.0
 func (a *AntibotsConfig) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
 	if t == bsontype.String {
 		var s string
@@ -150,12 +223,16 @@ type OwnSystemConfig struct {
 	} `bson:"events" json:"events"`
 }
 
+// VerificationConfig holds the member verification settings.
+// Type is one of "button", "captcha", "math" or "web".
 type VerificationConfig struct {
 	Enable            bool   `bson:"enable" json:"enable"`
 	Type              string `bson:"_type" json:"_type"`
 	Channel           string `bson:"channel" json:"channel"`
 	Role              string `bson:"role" json:"role"`
 	MinAccountAgeDays int    `bson:"minAccountAgeDays" json:"minAccountAgeDays"`
+	MaxAttempts       int    `bson:"maxAttempts" json:"maxAttempts"`       // Wrong answers allowed before kicking
+	TimeoutMinutes    int    `bson:"timeoutMinutes" json:"timeoutMinutes"` // 0 disables kicking unverified members
 }
 
 type CannotEnterTwiceConf struct {
@@ -228,7 +305,26 @@ type GuildConfiguration struct {
 type PasswordConfig struct {
 	Enable          bool     `bson:"enable" json:"enable"`
 	Password        string   `bson:"_password" json:"_password"`
-	UsersWithAccess []string `bson:"usersWithAcces" json:"usersWithAcces"` // Typo from TS kept for compatibility
+	UsersWithAccess []string `bson:"usersWithAccess" json:"usersWithAcces"` // The dashboard still sends the TS typo
+}
+
+// UnmarshalBSON reads the access list from the misspelled key of the TypeScript
+// bot when the guild wasn't migrated to schema 2.0.0 yet, so it isn't lost by a
+// write made before the migration runs
+func (p *PasswordConfig) UnmarshalBSON(data []byte) error {
+	type Alias PasswordConfig
+	var doc struct {
+		Alias  `bson:",inline"`
+		Legacy []string `bson:"usersWithAcces"`
+	}
+	if err := bson.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	*p = PasswordConfig(doc.Alias)
+	if p.UsersWithAccess == nil {
+		p.UsersWithAccess = doc.Legacy
+	}
+	return nil
 }
 
 // SubDataConfig holds miscellaneous guild settings
@@ -247,6 +343,15 @@ type Greetings struct {
 	Welcome  WelcomeConfig  `bson:"welcome" json:"welcome"`
 	Farewell FarewellConfig `bson:"farewell" json:"farewell"`
 	Autorole AutoroleConfig `bson:"autorole" json:"autorole"`
+	Card     CardConfig     `bson:"card" json:"card"`
+}
+
+// CardConfig holds the style of the generated image cards (welcome, farewell and rank)
+type CardConfig struct {
+	Background       string `bson:"background" json:"background"`             // Preset background name
+	Font             string `bson:"font" json:"font"`                         // Preset font style
+	AccentColor      int    `bson:"accentColor" json:"accentColor"`           // 0 uses the default accent
+	CustomBackground string `bson:"customBackground" json:"customBackground"` // Image URL, premium only
 }
 
 // WelcomeConfig holds welcome message settings
@@ -256,6 +361,7 @@ type WelcomeConfig struct {
 	Message string `bson:"message" json:"message"`
 	EmbedID string `bson:"embedId" json:"embedId"`
 	IsDM    bool   `bson:"isDM" json:"isDM"`
+	UseCard bool   `bson:"useCard" json:"useCard"` // Attach a generated image card
 }
 
 // FarewellConfig holds farewell message settings
@@ -264,6 +370,7 @@ type FarewellConfig struct {
 	Channel string `bson:"channel" json:"channel"`
 	Message string `bson:"message" json:"message"`
 	EmbedID string `bson:"embedId" json:"embedId"`
+	UseCard bool   `bson:"useCard" json:"useCard"` // Attach a generated image card
 }
 
 // AutoroleConfig holds autorole settings
@@ -338,7 +445,7 @@ func NewDefaultGuildDocument(guildID string) *GuildDocument {
 	return &GuildDocument{
 		ID: guildID,
 		Configuration: GuildConfiguration{
-			Version:        "1.0.0",
+			Version:        GuildSchemaVersion,
 			Prefix:         "pan!",
 			Language:       "es",
 			Whitelist:      []string{},
@@ -373,6 +480,11 @@ func NewDefaultGuildDocument(guildID string) *GuildDocument {
 				Roles:  []string{},
 				Delay:  0,
 			},
+			Card: CardConfig{
+				Background:  "default",
+				Font:        "classic",
+				AccentColor: 0x5865F2,
+			},
 		},
 		Moderation: ModeratorData{
 			Logs: ModLogsConfig{
@@ -474,7 +586,7 @@ func NewDefaultGuildDocument(guildID string) *GuildDocument {
 					[]string{}, []string{}, []string{}, []string{}, []string{}, []string{}, []string{}, []string{}, []string{}, []string{}, []string{}, []string{}, []string{}, []string{}, []string{}, []string{}, []string{}, []string{}, []string{}, []string{}, []string{}, []string{}, []string{}, []string{},
 				},
 			},
-			Verification:         VerificationConfig{Enable: false, Type: "button", Channel: "", Role: "", MinAccountAgeDays: 0},
+			Verification:         VerificationConfig{Enable: false, Type: "button", Channel: "", Role: "", MinAccountAgeDays: 0, MaxAttempts: 3, TimeoutMinutes: 0},
 			CannotEnterTwice:     CannotEnterTwiceConf{Enable: false, Users: []string{}},
 			PurgeWebhooksAttacks: PurgeWebhooksConfig{Enable: false, Amount: 0, RememberOwners: "Nadie"},
 			IntelligentSOS:       IntelligentSOSConfig{Enable: false, Cooldown: false},
@@ -489,6 +601,14 @@ func NewDefaultGuildDocument(guildID string) *GuildDocument {
 			LevelUpChannel: "",
 			LevelUpMessage: "",
 		},
+		Invites: InvitesConfig{
+			Enable:          false,
+			FakeAccountDays: 7,
+			Rewards:         make([]InviteReward, 0),
+		},
+		Economy: EconomyConfig{
+			Commands: make(map[string]EconomyCommandConfig),
+		},
 		PingOnJoin: make([]PingOnJoinConfig, 0),
 	}
 }
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestPasswordConfigReadsLegacyAccessList(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  bson.M
+		want []string
+	}{
+		{"legacy", bson.M{"enable": true, "usersWithAcces": bson.A{"a", "b"}}, []string{"a", "b"}},
+		{"migrated", bson.M{"enable": true, "usersWithAccess": bson.A{"c"}}, []string{"c"}},
+		{"both", bson.M{"usersWithAcces": bson.A{"a"}, "usersWithAccess": bson.A{}}, []string{}},
+		{"none", bson.M{"enable": true}, nil},
+	}
+	for _, tt := range tests {
+		raw, err := bson.Marshal(bson.M{"password": tt.doc})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var cfg GuildConfiguration
+		if err := bson.Unmarshal(raw, &cfg); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		got := cfg.Password.UsersWithAccess
+		if len(got) != len(tt.want) || (got == nil) != (tt.want == nil) {
+			t.Errorf("%s: UsersWithAccess = %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: UsersWithAccess = %v, want %v", tt.name, got, tt.want)
+			}
+		}
+		if !cfg.Password.Enable && tt.doc["enable"] == true {
+			t.Errorf("%s: enable was not decoded", tt.name)
+		}
+	}
+}
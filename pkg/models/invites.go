@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Invite sources stored in InviteJoin.Source
+const (
+	InviteSourceInvite  = "invite"
+	InviteSourceVanity  = "vanity"
+	InviteSourceUnknown = "unknown"
+)
+
+// InviteStats holds the invite counters of a member in a specific guild
+type InviteStats struct {
+	ID        string    `bson:"_id" json:"id"` // Format: GuildID_UserID
+	GuildID   string    `bson:"guild_id" json:"guild_id"`
+	UserID    string    `bson:"user_id" json:"user_id"`
+	Joins     int64     `bson:"joins" json:"joins"`
+	Leaves    int64     `bson:"leaves" json:"leaves"`
+	Fakes     int64     `bson:"fakes" json:"fakes"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// Total returns the invites that still count (joins minus leaves and fakes)
+func (s *InviteStats) Total() int64 {
+	return s.Joins - s.Leaves - s.Fakes
+}
+
+// InviteJoin records who invited a member, so the inviter can be charged when the member leaves
+type InviteJoin struct {
+	ID        string    `bson:"_id" json:"id"` // Format: GuildID_MemberID
+	GuildID   string    `bson:"guild_id" json:"guild_id"`
+	MemberID  string    `bson:"member_id" json:"member_id"`
+	InviterID string    `bson:"inviter_id" json:"inviter_id"` // Empty for vanity and unknown sources
+	Code      string    `bson:"code" json:"code"`
+	Source    string    `bson:"source" json:"source"`
+	Fake      bool      `bson:"fake" json:"fake"`
+	Left      bool      `bson:"left" json:"left"`
+	JoinedAt  time.Time `bson:"joined_at" json:"joined_at"`
+}
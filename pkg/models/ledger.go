@@ -0,0 +1,109 @@
+package models
+
+import "time"
+
+// Currencies of the economy ledger
+const (
+	CurrencyStars = "stars" // global economy
+	CurrencyLocal = "local" // per-guild economy
+)
+
+// TransactionType classifies a balance change in the ledger
+type TransactionType string
+
+const (
+	TransactionEarn      TransactionType = "earn"     // work, crime, slut...
+	TransactionReward    TransactionType = "reward"   // daily, weekly
+	TransactionPenalty   TransactionType = "penalty"  // fines paid to nobody
+	TransactionTransfer  TransactionType = "transfer" // pay
+	TransactionRob       TransactionType = "rob"
+	TransactionFine      TransactionType = "fine" // fine paid to another user
+	TransactionDeposit   TransactionType = "deposit"
+	TransactionWithdraw  TransactionType = "withdraw"
+	TransactionPurchase  TransactionType = "purchase"
+	TransactionItemUse   TransactionType = "item_use"
+	TransactionRefund    TransactionType = "refund"
+	TransactionAdmin     TransactionType = "admin"
+	TransactionTrade     TransactionType = "trade"      // player to player trade
+	TransactionMarket    TransactionType = "market"     // marketplace escrow, bids and sales
+	TransactionMarketFee TransactionType = "market_fee" // marketplace listing fee
+	TransactionBet       TransactionType = "bet"        // stake of a casino game or duel
+	TransactionPrize     TransactionType = "prize"      // casino winnings and returned stakes
+)
+
+// Transaction is an immutable ledger entry describing one balance change of one user.
+// Both legs of a transfer share the same GroupID.
+type Transaction struct {
+	ID             string          `bson:"_id" json:"id"`
+	GroupID        string          `bson:"group_id,omitempty" json:"group_id,omitempty"`
+	Currency       string          `bson:"currency" json:"currency"`
+	GuildID        string          `bson:"guild_id,omitempty" json:"guild_id,omitempty"` // Empty for Stars
+	UserID         string          `bson:"user_id" json:"user_id"`
+	Type           TransactionType `bson:"type" json:"type"`
+	Amount         int64           `bson:"amount" json:"amount"` // Net change of wallet + bank
+	WalletDelta    int64           `bson:"wallet_delta" json:"wallet_delta"`
+	BankDelta      int64           `bson:"bank_delta" json:"bank_delta"`
+	Items          map[string]int  `bson:"items,omitempty" json:"items,omitempty"` // ItemID -> quantity delta
+	WalletAfter    int64           `bson:"wallet_after" json:"wallet_after"`
+	BankAfter      int64           `bson:"bank_after" json:"bank_after"`
+	CounterpartyID string          `bson:"counterparty_id,omitempty" json:"counterparty_id,omitempty"`
+	Command        string          `bson:"command,omitempty" json:"command,omitempty"`
+	Note           string          `bson:"note,omitempty" json:"note,omitempty"`
+	CreatedAt      time.Time       `bson:"created_at" json:"created_at"`
+}
+
+// TransactionFlow summarizes the money moved between a user and one counterparty
+type TransactionFlow struct {
+	CounterpartyID string `bson:"_id" json:"counterparty_id"`
+	Received       int64  `bson:"received" json:"received"`
+	Sent           int64  `bson:"sent" json:"sent"`
+	Count          int64  `bson:"count" json:"count"`
+}
+
+// Seasonal reports whether the type counts towards the season earnings: money won
+// or lost playing, not money moved between users or spent in shops
+func (t TransactionType) Seasonal() bool {
+	switch t {
+	case TransactionEarn, TransactionReward, TransactionPenalty, TransactionRob, TransactionFine, TransactionBet, TransactionPrize:
+		return true
+	}
+	return false
+}
+
+// Label returns the human readable name of the transaction type
+func (t TransactionType) Label() string {
+	switch t {
+	case TransactionEarn:
+		return "Ganancia"
+	case TransactionReward:
+		return "Recompensa"
+	case TransactionPenalty:
+		return "Multa"
+	case TransactionTransfer:
+		return "Transferencia"
+	case TransactionRob:
+		return "Robo"
+	case TransactionFine:
+		return "Fianza"
+	case TransactionDeposit:
+		return "Depósito"
+	case TransactionWithdraw:
+		return "Retiro"
+	case TransactionPurchase:
+		return "Compra"
+	case TransactionItemUse:
+		return "Uso de objeto"
+	case TransactionRefund:
+		return "Reembolso"
+	case TransactionAdmin:
+		return "Ajuste admin"
+	case TransactionTrade:
+		return "Intercambio"
+	case TransactionMarket:
+		return "Mercado"
+	case TransactionMarketFee:
+		return "Comisión del mercado"
+	default:
+		return string(t)
+	}
+}
@@ -10,6 +10,9 @@ type UserLevelProfile struct {
 	XP              int64     `bson:"xp" json:"xp"`
 	Level           int64     `bson:"level" json:"level"`
 	TotalMessages   int64     `bson:"total_messages" json:"total_messages"`
+	VoiceSeconds    int64     `bson:"voice_seconds" json:"voice_seconds"`         // Time counted in voice channels
+	Season          int       `bson:"season" json:"season"`                       // Season SeasonXP belongs to
+	SeasonXP        int64     `bson:"season_xp" json:"season_xp"`                 // XP earned during that season
 	LastMessageTime time.Time `bson:"last_message_time" json:"last_message_time"` // For cooldowns
 	SpamWindowStart time.Time `bson:"spam_window_start" json:"spam_window_start"`
 	SpamCount       int       `bson:"spam_count" json:"spam_count"`
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// ListingStatus is the lifecycle state of a marketplace listing
+type ListingStatus string
+
+const (
+	ListingActive    ListingStatus = "active"
+	ListingSettling  ListingStatus = "settling" // claimed by a buyer or the expiry sweep
+	ListingSold      ListingStatus = "sold"
+	ListingExpired   ListingStatus = "expired"
+	ListingCancelled ListingStatus = "cancelled"
+)
+
+// MarketListing is an item put on sale in a guild marketplace. Items are held in
+// escrow while the listing is active and prices are in local coins. Bids are
+// escrowed too, so the current bidder's coins are already out of their wallet.
+type MarketListing struct {
+	ID          string        `bson:"_id" json:"id"`
+	GuildID     string        `bson:"guild_id" json:"guild_id"`
+	SellerID    string        `bson:"seller_id" json:"seller_id"`
+	ItemID      string        `bson:"item_id" json:"item_id"`
+	ItemName    string        `bson:"item_name" json:"item_name"`
+	ItemEmoji   string        `bson:"item_emoji" json:"item_emoji"`
+	ItemGlobal  bool          `bson:"item_global" json:"item_global"` // The item lives in the Stars inventory
+	Quantity    int           `bson:"quantity" json:"quantity"`
+	BuyNowPrice int64         `bson:"buy_now_price" json:"buy_now_price"` // 0 = auction only
+	StartingBid int64         `bson:"starting_bid" json:"starting_bid"`   // 0 = no bids allowed
+	CurrentBid  int64         `bson:"current_bid" json:"current_bid"`
+	BidderID    string        `bson:"bidder_id" json:"bidder_id"`
+	Bids        int           `bson:"bids" json:"bids"`
+	Fee         int64         `bson:"fee" json:"fee"`
+	Status      ListingStatus `bson:"status" json:"status"`
+	BuyerID     string        `bson:"buyer_id,omitempty" json:"buyer_id,omitempty"`
+	CreatedAt   time.Time     `bson:"created_at" json:"created_at"`
+	ExpiresAt   time.Time     `bson:"expires_at" json:"expires_at"`
+	ClosedAt    time.Time     `bson:"closed_at,omitempty" json:"closed_at,omitempty"`
+}
+
+// AcceptsBids reports whether the listing is an auction
+func (l *MarketListing) AcceptsBids() bool {
+	return l.StartingBid > 0
+}
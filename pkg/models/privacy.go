@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// GuildPurge is a guild the bot left. Its data is deleted at PurgeAt unless
+// the bot is added back before.
+type GuildPurge struct {
+	GuildID string    `bson:"_id" json:"guild_id"`
+	LeftAt  time.Time `bson:"left_at" json:"left_at"`
+	PurgeAt time.Time `bson:"purge_at" json:"purge_at"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// SeasonsConfig holds the seasons of the levels and local economy of a guild.
+// Lifetime XP and balances are never touched; only the season counters reset.
+type SeasonsConfig struct {
+	Active          bool      `bson:"active" json:"active"` // A season is running
+	Number          int       `bson:"number" json:"number"` // Current season, or the last one if none is running
+	StartedAt       time.Time `bson:"startedAt" json:"startedAt"`
+	EndsAt          time.Time `bson:"endsAt" json:"endsAt"`
+	LengthDays      int       `bson:"lengthDays" json:"lengthDays"`           // 0 keeps the default, see pkg/seasons
+	AutoRenew       bool      `bson:"autoRenew" json:"autoRenew"`             // Start the next season when one ends
+	AnnounceChannel string    `bson:"announceChannel" json:"announceChannel"` // Results announcement
+	RewardTop       int       `bson:"rewardTop" json:"rewardTop"`             // How many users of each board get the role
+	LevelsRole      string    `bson:"levelsRole" json:"levelsRole"`           // Role for the top of the season XP
+	EconomyRole     string    `bson:"economyRole" json:"economyRole"`         // Role for the top of the season earnings
+}
+
+// SeasonStanding is one position of an archived season board
+type SeasonStanding struct {
+	UserID string `bson:"user_id" json:"user_id"`
+	Value  int64  `bson:"value" json:"value"` // Season XP or earnings
+}
+
+// SeasonRoleGrant is a season role given to a winner, removed when the next
+// season hands out its own
+type SeasonRoleGrant struct {
+	UserID string `bson:"user_id" json:"user_id"`
+	RoleID string `bson:"role_id" json:"role_id"`
+}
+
+// Season is the record of a season of a guild. It is created when the season
+// starts and gets its final standings when it ends.
+type Season struct {
+	ID        string            `bson:"_id" json:"id"` // Format: GuildID_Number
+	GuildID   string            `bson:"guild_id" json:"guild_id"`
+	Number    int               `bson:"number" json:"number"`
+	StartedAt time.Time         `bson:"started_at" json:"started_at"`
+	EndsAt    time.Time         `bson:"ends_at" json:"ends_at"`
+	EndedAt   time.Time         `bson:"ended_at,omitempty" json:"ended_at,omitempty"`
+	Levels    []SeasonStanding  `bson:"levels" json:"levels"`
+	Economy   []SeasonStanding  `bson:"economy" json:"economy"`
+	Rewarded  []SeasonRoleGrant `bson:"rewarded,omitempty" json:"rewarded,omitempty"`
+}
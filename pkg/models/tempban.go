@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// TempBan is a ban the scheduler lifts at ExpiresAt
+type TempBan struct {
+	GuildID   string    `bson:"guildId" json:"guild_id"`
+	UserID    string    `bson:"userId" json:"user_id"`
+	ExpiresAt time.Time `bson:"expiresAt" json:"expires_at"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// PendingVerification tracks a member that joined and still has to pass verification
+type PendingVerification struct {
+	ID        string    `bson:"_id" json:"id"` // Format: GuildID_UserID
+	GuildID   string    `bson:"guild_id" json:"guild_id"`
+	UserID    string    `bson:"user_id" json:"user_id"`
+	JoinedAt  time.Time `bson:"joined_at" json:"joined_at"`
+	ExpiresAt time.Time `bson:"expires_at" json:"expires_at"` // Zero when the guild has no timeout
+}
@@ -0,0 +1,152 @@
+package mqtt
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Authorizer decides whether the credentials sent with a request may call a
+// topic. It returns the caller, when the credentials name one.
+type Authorizer interface {
+	Authorize(topic, token string) (caller string, err error)
+	// Scheme names the credentials in the schema listing
+	Scheme() string
+}
+
+var (
+	errNoSecret     = errors.New("no secret configured")
+	errMissingToken = errors.New("missing token")
+	errInvalidToken = errors.New("invalid token")
+)
+
+type sharedSecret struct {
+	secret []byte
+}
+
+// SharedSecret accepts requests whose token is secret. An empty secret rejects
+// every request, so an unconfigured bot never leaves a topic open.
+func SharedSecret(secret string) Authorizer {
+	return sharedSecret{secret: []byte(secret)}
+}
+
+func (a sharedSecret) Authorize(topic, token string) (string, error) {
+	switch {
+	case len(a.secret) == 0:
+		return "", errNoSecret
+	case token == "":
+		return "", errMissingToken
+	case subtle.ConstantTimeCompare([]byte(token), a.secret) != 1:
+		return "", errInvalidToken
+	}
+	return "", nil
+}
+
+func (a sharedSecret) Scheme() string { return "shared-secret" }
+
+// Claims are the contents of a signed token
+type Claims struct {
+	Subject   string   `json:"sub"`    // Who the token was issued to, like "dashboard"
+	Topics    []string `json:"topics"` // Topics it may call, MQTT wildcards allowed
+	ExpiresAt int64    `json:"exp"`    // Unix seconds
+}
+
+// SignToken creates a token for claims signed with secret. Tokens are the
+// base64 claims and their HMAC-SHA256, separated by a dot.
+func SignToken(secret string, claims Claims) (string, error) {
+	if secret == "" {
+		return "", errNoSecret
+	}
+	body, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(body)
+	return payload + "." + sign(secret, payload), nil
+}
+
+func sign(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+type signedTokens struct {
+	secret string
+	now    func() time.Time
+}
+
+// SignedTokens accepts tokens made by SignToken with secret that have not
+// expired and list the topic. The caller is the subject of the token.
+func SignedTokens(secret string) Authorizer {
+	return signedTokens{secret: secret, now: time.Now}
+}
+
+func (a signedTokens) Authorize(topic, token string) (string, error) {
+	if a.secret == "" {
+		return "", errNoSecret
+	}
+	if token == "" {
+		return "", errMissingToken
+	}
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(sign(a.secret, payload))) {
+		return "", errInvalidToken
+	}
+
+	body, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", errInvalidToken
+	}
+	var claims Claims
+	if err := json.Unmarshal(body, &claims); err != nil {
+		return "", errInvalidToken
+	}
+	if claims.ExpiresAt == 0 || a.now().Unix() >= claims.ExpiresAt {
+		return "", fmt.Errorf("token expired")
+	}
+	for _, allowed := range claims.Topics {
+		if topicMatch(allowed, topic) {
+			return claims.Subject, nil
+		}
+	}
+	return "", fmt.Errorf("token of %q does not allow %s", claims.Subject, topic)
+}
+
+func (a signedTokens) Scheme() string { return "signed-token" }
+
+type anyOf []Authorizer
+
+// AnyOf accepts the requests accepted by any of auths
+func AnyOf(auths ...Authorizer) Authorizer {
+	return anyOf(auths)
+}
+
+func (a anyOf) Authorize(topic, token string) (string, error) {
+	err := errInvalidToken
+	for _, auth := range a {
+		caller, authErr := auth.Authorize(topic, token)
+		if authErr == nil {
+			return caller, nil
+		}
+		// Keep the most useful reason: anything beats a plain "invalid token"
+		if errors.Is(err, errInvalidToken) {
+			err = authErr
+		}
+	}
+	return "", err
+}
+
+func (a anyOf) Scheme() string {
+	schemes := make([]string, len(a))
+	for i, auth := range a {
+		schemes[i] = auth.Scheme()
+	}
+	return strings.Join(schemes, "|")
+}
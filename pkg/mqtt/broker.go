@@ -0,0 +1,57 @@
+package mqtt
+
+import (
+	"fmt"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// Broker is the connection the communicator talks through. The paho client is
+// the real one; tests use a MemoryBroker.
+type Broker interface {
+	Publish(topic string, qos byte, retained bool, payload []byte) error
+	Subscribe(topic string, qos byte, handler func(topic string, payload []byte)) error
+	Unsubscribe(topic string) error
+	IsConnected() bool
+	Disconnect()
+}
+
+// publishTimeout bounds how long a QoS 1 publish waits for the broker, so a
+// lost connection never blocks a handler forever
+const publishTimeout = 10 * time.Second
+
+// pahoBroker adapts a paho client to Broker
+type pahoBroker struct {
+	client mqtt.Client
+}
+
+func (b *pahoBroker) Publish(topic string, qos byte, retained bool, payload []byte) error {
+	token := b.client.Publish(topic, qos, retained, payload)
+	if !token.WaitTimeout(publishTimeout) {
+		return fmt.Errorf("publish to %s timed out", topic)
+	}
+	return token.Error()
+}
+
+func (b *pahoBroker) Subscribe(topic string, qos byte, handler func(topic string, payload []byte)) error {
+	token := b.client.Subscribe(topic, qos, func(c mqtt.Client, msg mqtt.Message) {
+		handler(msg.Topic(), msg.Payload())
+	})
+	token.Wait()
+	return token.Error()
+}
+
+func (b *pahoBroker) Unsubscribe(topic string) error {
+	token := b.client.Unsubscribe(topic)
+	token.Wait()
+	return token.Error()
+}
+
+func (b *pahoBroker) IsConnected() bool {
+	return b.client != nil && b.client.IsConnected()
+}
+
+func (b *pahoBroker) Disconnect() {
+	b.client.Disconnect(250)
+}
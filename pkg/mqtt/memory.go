@@ -0,0 +1,114 @@
+package mqtt
+
+import (
+	"sync"
+)
+
+// Message is a message seen by a MemoryBroker
+type Message struct {
+	Topic    string
+	QoS      byte
+	Retained bool
+	Payload  []byte
+}
+
+// MemoryBroker is an in-process stand-in for the MQTT broker. Messages are
+// delivered synchronously to every matching subscription, wildcards and
+// retained messages included, so tests can run the full request/response flow
+// without a network.
+type MemoryBroker struct {
+	mu        sync.Mutex
+	subs      map[string]func(topic string, payload []byte)
+	retained  map[string][]byte
+	published []Message
+}
+
+// NewMemoryBroker creates an empty broker
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		subs:     make(map[string]func(topic string, payload []byte)),
+		retained: make(map[string][]byte),
+	}
+}
+
+// Publish delivers payload to the matching subscriptions
+func (b *MemoryBroker) Publish(topic string, qos byte, retained bool, payload []byte) error {
+	b.mu.Lock()
+	b.published = append(b.published, Message{Topic: topic, QoS: qos, Retained: retained, Payload: payload})
+	if retained {
+		b.retained[topic] = payload
+	}
+	var handlers []func(string, []byte)
+	for pattern, handler := range b.subs {
+		if topicMatch(pattern, topic) {
+			handlers = append(handlers, handler)
+		}
+	}
+	b.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(topic, payload)
+	}
+	return nil
+}
+
+// Subscribe registers handler for topic, replacing an earlier subscription to
+// the same filter, and delivers the retained messages that match it
+func (b *MemoryBroker) Subscribe(topic string, qos byte, handler func(topic string, payload []byte)) error {
+	b.mu.Lock()
+	b.subs[topic] = handler
+	var retained []Message
+	for t, payload := range b.retained {
+		if topicMatch(topic, t) {
+			retained = append(retained, Message{Topic: t, Retained: true, Payload: payload})
+		}
+	}
+	b.mu.Unlock()
+
+	for _, msg := range retained {
+		handler(msg.Topic, msg.Payload)
+	}
+	return nil
+}
+
+// Unsubscribe removes the subscription to topic
+func (b *MemoryBroker) Unsubscribe(topic string) error {
+	b.mu.Lock()
+	delete(b.subs, topic)
+	b.mu.Unlock()
+	return nil
+}
+
+// IsConnected is always true
+func (b *MemoryBroker) IsConnected() bool { return true }
+
+// Disconnect does nothing
+func (b *MemoryBroker) Disconnect() {}
+
+// Published returns the messages published so far on topics matching filter
+func (b *MemoryBroker) Published(filter string) []Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var out []Message
+	for _, msg := range b.published {
+		if topicMatch(filter, msg.Topic) {
+			out = append(out, msg)
+		}
+	}
+	return out
+}
+
+// Retained returns the retained message of topic, or nil
+func (b *MemoryBroker) Retained(topic string) []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.retained[topic]
+}
+
+// Subscribed reports whether there is a subscription to the filter
+func (b *MemoryBroker) Subscribed(topic string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, ok := b.subs[topic]
+	return ok
+}
@@ -0,0 +1,16 @@
+package mqtt
+
+import "github.com/PancyStudios/PancyBotGo/pkg/metrics"
+
+var requestsTotal = metrics.NewCounter("pancybot_mqtt_requests_total",
+	"MQTT requests, by direction (in or out), topic and result.", "direction", "topic", "result")
+
+// observeRequest counts a request. Incoming requests are counted by the topic
+// the handler was registered with, so wildcards don't create a series per guild.
+func observeRequest(direction, topic string, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	requestsTotal.Inc(direction, topic, result)
+}
@@ -20,6 +20,9 @@ import (
 type MqttRequest struct {
 	CorrelationID string      `json:"correlationId"`
 	Payload       interface{} `json:"payload,omitempty"`
+	Version       int         `json:"version,omitempty"`     // Version of the topic the caller speaks
+	Token         string      `json:"token,omitempty"`       // Shared secret or signed token of protected topics
+	Traceparent   string      `json:"traceparent,omitempty"` // W3C trace context of the caller
 }
 
 // MqttResponse represents an MQTT response message
@@ -27,14 +30,31 @@ type MqttResponse struct {
 	CorrelationID string      `json:"correlationId"`
 	Data          interface{} `json:"data"`
 	Error         string      `json:"error,omitempty"`
+	Code          string      `json:"code,omitempty"`    // Machine-readable error, see errorCode
+	Details       interface{} `json:"details,omitempty"` // Structured information about the error, see DetailedError
+	Version       int         `json:"version,omitempty"`
+}
+
+// rawResponse is a response whose data is decoded later, into the type the
+// caller expects
+type rawResponse struct {
+	CorrelationID string          `json:"correlationId"`
+	Data          json.RawMessage `json:"data"`
+	Error         string          `json:"error,omitempty"`
+	Code          string          `json:"code,omitempty"`
+	Details       json.RawMessage `json:"details,omitempty"`
 }
 
 // MqttCommunicator handles MQTT communication
 type MqttCommunicator struct {
-	client           mqtt.Client
-	responseHandlers map[string]func(MqttResponse)
+	broker           Broker
+	responseHandlers map[string]func(rawResponse)
+	routes           map[string]*route
+	subscriptions    map[string]subscription
+	schemaPublished  bool
 	mu               sync.RWMutex
 	clientID         string
+	env              string
 }
 
 var (
@@ -46,10 +66,6 @@ var (
 func Init(host, port, username, password, clientID string) *MqttCommunicator {
 	once.Do(func() {
 		communicator = NewMqttCommunicator(host, port, username, password, clientID)
-		
-		communicator.On("ping", func(payload map[string]interface{}) (interface{}, error) {
-			return map[string]interface{}{"pong": true}, nil
-		})
 	})
 	return communicator
 }
@@ -61,10 +77,7 @@ func Get() *MqttCommunicator {
 
 // NewMqttCommunicator creates a new MQTT communicator
 func NewMqttCommunicator(host, port, username, password, clientID string) *MqttCommunicator {
-	mc := &MqttCommunicator{
-		responseHandlers: make(map[string]func(MqttResponse)),
-		clientID:         clientID,
-	}
+	mc := newCommunicator(clientID)
 
 	uniqueID := fmt.Sprintf("%s_%s", clientID, uuid.New().String())
 
@@ -78,25 +91,53 @@ func NewMqttCommunicator(host, port, username, password, clientID string) *MqttC
 		SetConnectRetryInterval(5 * time.Second).
 		SetOnConnectHandler(func(c mqtt.Client) {
 			logger.Success(fmt.Sprintf("Conectado al broker MQTT como %s", clientID), "MQTT")
+			// Clean sessions forget the subscriptions, so a reconnect
+			// registers the topics again
+			mc.resubscribe()
 		}).
 		SetConnectionLostHandler(func(c mqtt.Client, err error) {
 			logger.Error(fmt.Sprintf("Conexión MQTT perdida: %v", err), "MQTT")
 		})
 
-	mc.client = mqtt.NewClient(opts)
+	client := mqtt.NewClient(opts)
+	mc.broker = &pahoBroker{client: client}
 
-	token := mc.client.Connect()
+	token := client.Connect()
 	if token.Wait() && token.Error() != nil {
 		logger.Error(fmt.Sprintf("Error de conexión MQTT: %v", token.Error()), "MQTT")
 	}
 
+	mc.registerBuiltins()
 	return mc
 }
 
+// NewWithBroker creates a communicator that talks through broker instead of a
+// network connection. Tests use it with a MemoryBroker.
+func NewWithBroker(broker Broker, clientID string) *MqttCommunicator {
+	mc := newCommunicator(clientID)
+	mc.broker = broker
+	mc.registerBuiltins()
+	return mc
+}
+
+func newCommunicator(clientID string) *MqttCommunicator {
+	env := os.Getenv("BOT_ENV")
+	if env == "" {
+		env = "canary"
+	}
+	return &MqttCommunicator{
+		responseHandlers: make(map[string]func(rawResponse)),
+		routes:           make(map[string]*route),
+		subscriptions:    make(map[string]subscription),
+		clientID:         clientID,
+		env:              env,
+	}
+}
+
 // Destroy closes the MQTT connection
 func (mc *MqttCommunicator) Destroy() {
-	if mc.client != nil && mc.client.IsConnected() {
-		mc.client.Disconnect(250)
+	if mc.IsConnected() {
+		mc.broker.Disconnect()
 		logger.System("Conexión MQTT cerrada exitosamente.", "MQTT")
 	} else {
 		logger.Warn("El cliente MQTT no estaba conectado, no se necesita cerrar.", "MQTT")
@@ -105,54 +146,101 @@ func (mc *MqttCommunicator) Destroy() {
 
 // IsConnected returns true if connected to the broker
 func (mc *MqttCommunicator) IsConnected() bool {
-	return mc.client != nil && mc.client.IsConnected()
+	return mc.broker != nil && mc.broker.IsConnected()
 }
 
 // Publish sends a message to a topic
 func (mc *MqttCommunicator) Publish(topic string, payload interface{}) error {
+	return mc.publish(topic, 0, false, payload)
+}
+
+func (mc *MqttCommunicator) publish(topic string, qos byte, retained bool, payload interface{}) error {
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("failed to marshal payload: %w", err)
 	}
+	return mc.broker.Publish(topic, qos, retained, jsonData)
+}
+
+// Broadcast publishes with QoS 1 on a topic outside the environment prefixes,
+// for messages every instance of the bot must see
+func (mc *MqttCommunicator) Broadcast(topic string, payload interface{}) error {
+	return mc.publish(topic, 1, false, payload)
+}
+
+// Emit publishes an event for the dashboard on pancy/events/<env>/<event>, with
+// QoS 1 so subscribers don't miss changes
+func (mc *MqttCommunicator) Emit(event string, payload interface{}) error {
+	return mc.publish(mc.eventTopic(event), 1, false, payload)
+}
+
+// eventTopic returns the topic an event is published on
+func (mc *MqttCommunicator) eventTopic(event string) string {
+	return fmt.Sprintf("pancy/events/%s/%s", mc.env, event)
+}
+
+// requestTopic returns the full topic requests to topic are published on
+func (mc *MqttCommunicator) requestTopic(topic string) string {
+	return fmt.Sprintf("pancy/request/%s/%s", mc.env, topic)
+}
 
-	token := mc.client.Publish(topic, 0, false, jsonData)
-	token.Wait()
-	return token.Error()
+// responseTopic returns the topic the response to a request is published on
+func (mc *MqttCommunicator) responseTopic(topic, correlationID string) string {
+	return fmt.Sprintf("pancy/response/%s/%s/%s", mc.env, topic, correlationID)
 }
 
 // Request sends a request and waits for a response
 func (mc *MqttCommunicator) Request(topic string, payload interface{}, timeout time.Duration) (interface{}, error) {
-	env := os.Getenv("BOT_ENV")
-	if env == "" {
-		env = "canary"
+	raw, err := mc.roundTrip(topic, MqttRequest{Payload: payload}, timeout)
+	if err != nil {
+		return nil, err
+	}
+	var data interface{}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return nil, err
+		}
 	}
-	correlationID := uuid.New().String()
-	requestTopic := fmt.Sprintf("pancy/request/%s/%s", env, topic)
-	responseTopic := fmt.Sprintf("pancy/response/%s/%s/%s", env, topic, correlationID)
+	return data, nil
+}
 
-	responseChan := make(chan MqttResponse, 1)
+// roundTrip publishes request with QoS 1 and waits for its response, returning
+// the undecoded data
+func (mc *MqttCommunicator) roundTrip(topic string, request MqttRequest, timeout time.Duration) (data json.RawMessage, err error) {
+	defer func() { observeRequest("out", topic, err) }()
+
+	request.CorrelationID = uuid.New().String()
+	responseTopic := mc.responseTopic(topic, request.CorrelationID)
+
+	responseChan := make(chan rawResponse, 1)
 	errChan := make(chan error, 1)
 
 	// Set up response handler
 	mc.mu.Lock()
-	mc.responseHandlers[correlationID] = func(response MqttResponse) {
-		responseChan <- response
+	mc.responseHandlers[request.CorrelationID] = func(response rawResponse) {
+		select {
+		case responseChan <- response:
+		default:
+		}
 	}
 	mc.mu.Unlock()
 
 	// Clean up handler when done
 	defer func() {
 		mc.mu.Lock()
-		delete(mc.responseHandlers, correlationID)
+		delete(mc.responseHandlers, request.CorrelationID)
 		mc.mu.Unlock()
-		mc.client.Unsubscribe(responseTopic)
+		mc.broker.Unsubscribe(responseTopic)
 	}()
 
 	// Subscribe to response topic
-	token := mc.client.Subscribe(responseTopic, 0, func(c mqtt.Client, msg mqtt.Message) {
-		var response MqttResponse
-		if err := json.Unmarshal(msg.Payload(), &response); err != nil {
-			errChan <- err
+	err = mc.broker.Subscribe(responseTopic, 1, func(topic string, payload []byte) {
+		var response rawResponse
+		if err := json.Unmarshal(payload, &response); err != nil {
+			select {
+			case errChan <- err:
+			default:
+			}
 			return
 		}
 
@@ -164,18 +252,12 @@ func (mc *MqttCommunicator) Request(topic string, payload interface{}, timeout t
 			handler(response)
 		}
 	})
-
-	if token.Wait() && token.Error() != nil {
-		return nil, token.Error()
+	if err != nil {
+		return nil, err
 	}
 
 	// Send request
-	request := MqttRequest{
-		CorrelationID: correlationID,
-		Payload:       payload,
-	}
-
-	if err := mc.Publish(requestTopic, request); err != nil {
+	if err := mc.publish(mc.requestTopic(topic), 1, false, request); err != nil {
 		return nil, err
 	}
 
@@ -183,7 +265,7 @@ func (mc *MqttCommunicator) Request(topic string, payload interface{}, timeout t
 	select {
 	case response := <-responseChan:
 		if response.Error != "" {
-			return nil, fmt.Errorf("%s", response.Error)
+			return nil, &RemoteError{Code: response.Code, Message: response.Error, Details: response.Details}
 		}
 		return response.Data, nil
 	case err := <-errChan:
@@ -196,74 +278,60 @@ func (mc *MqttCommunicator) Request(topic string, payload interface{}, timeout t
 // RequestHandler is a function type for handling MQTT requests
 type RequestHandler func(payload map[string]interface{}) (interface{}, error)
 
-// On registers a handler for a request topic
+// On registers a handler for a request topic. The payload arrives as a map with
+// the received topic under "_topic".
+//
+// Deprecated: use the typed On function, which decodes and validates the
+// payload and lists the topic in the schema.
 func (mc *MqttCommunicator) On(requestTopic string, callback RequestHandler) {
-	env := os.Getenv("BOT_ENV")
-	if env == "" {
-		env = "canary"
-	}
-	topic := fmt.Sprintf("pancy/request/%s/%s", env, requestTopic)
-
-	token := mc.client.Subscribe(topic, 0, func(c mqtt.Client, msg mqtt.Message) {
-		var request MqttRequest
-		if err := json.Unmarshal(msg.Payload(), &request); err != nil {
-			logger.Error(fmt.Sprintf("Error parsing MQTT request: %v", err), "MQTT")
-			return
-		}
-		// Extract actual topic from received topic
-		receivedTopic := msg.Topic()
-		actualTopic := strings.TrimPrefix(receivedTopic, fmt.Sprintf("pancy/request/%s/", env))
-		responseTopic := fmt.Sprintf("pancy/response/%s/%s/%s", env, actualTopic, request.CorrelationID)
-
-		var response MqttResponse
-
-		// Convert payload to map
-		payloadMap := make(map[string]interface{})
-		if request.Payload != nil {
-			if pm, ok := request.Payload.(map[string]interface{}); ok {
-				payloadMap = pm
+	mc.register(&route{
+		pattern: requestTopic,
+		version: 1,
+		serve: func(call *Call, payload json.RawMessage) (interface{}, error) {
+			// Convert payload to map
+			payloadMap := make(map[string]interface{})
+			if len(payload) > 0 {
+				json.Unmarshal(payload, &payloadMap)
+				if payloadMap == nil {
+					payloadMap = make(map[string]interface{})
+				}
 			}
-		}
-		payloadMap["_topic"] = actualTopic
-
-		// Execute callback
-		data, err := callback(payloadMap)
-		if err != nil {
-			response = MqttResponse{
-				CorrelationID: request.CorrelationID,
-				Data:          nil,
-				Error:         err.Error(),
-			}
-		} else {
-			response = MqttResponse{
-				CorrelationID: request.CorrelationID,
-				Data:          data,
-			}
-		}
-
-		// Send response
-		mc.Publish(responseTopic, response)
+			payloadMap["_topic"] = call.Topic
+			return callback(payloadMap)
+		},
 	})
+}
 
-	if token.Wait() && token.Error() != nil {
-		logger.Error(fmt.Sprintf("Error subscribing to topic %s: %v", topic, token.Error()), "MQTT")
-	}
+// subscription is a raw subscription, kept to subscribe again after a reconnect
+type subscription struct {
+	qos     byte
+	handler func(topic string, payload []byte)
 }
 
 // Subscribe subscribes to a topic with a message handler
 func (mc *MqttCommunicator) Subscribe(topic string, handler func(topic string, payload []byte)) error {
-	token := mc.client.Subscribe(topic, 0, func(c mqtt.Client, msg mqtt.Message) {
-		handler(msg.Topic(), msg.Payload())
-	})
-	token.Wait()
-	return token.Error()
+	return mc.subscribe(topic, 0, handler)
+}
+
+// Listen subscribes to a topic with QoS 1, for messages that must not be lost
+// while connected
+func (mc *MqttCommunicator) Listen(topic string, handler func(topic string, payload []byte)) error {
+	return mc.subscribe(topic, 1, handler)
+}
+
+func (mc *MqttCommunicator) subscribe(topic string, qos byte, handler func(topic string, payload []byte)) error {
+	mc.mu.Lock()
+	mc.subscriptions[topic] = subscription{qos: qos, handler: handler}
+	mc.mu.Unlock()
+	return mc.broker.Subscribe(topic, qos, handler)
 }
 
 // Unsubscribe unsubscribes from a topic
 func (mc *MqttCommunicator) Unsubscribe(topic string) error {
-	token := mc.client.Unsubscribe(topic)
-	token.Wait()
-	return token.Error()
+	mc.mu.Lock()
+	delete(mc.subscriptions, topic)
+	mc.mu.Unlock()
+	return mc.broker.Unsubscribe(topic)
 }
 
 // topicMatch checks if a received topic matches a pattern (with wildcards)
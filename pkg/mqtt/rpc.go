@@ -0,0 +1,405 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/PancyStudios/PancyBotGo/pkg/tracing"
+)
+
+// Errors returned to callers, with a code in the response so the dashboard can
+// tell them apart from the errors of the handlers
+var (
+	ErrBadRequest         = errors.New("bad request")
+	ErrUnauthorized       = errors.New("unauthorized")
+	ErrForbidden          = errors.New("forbidden")
+	ErrNotFound           = errors.New("not found")
+	ErrConflict           = errors.New("conflict")
+	ErrUnsupportedVersion = errors.New("unsupported version")
+	ErrInternal           = errors.New("internal error")
+)
+
+var errorCodes = map[error]string{
+	ErrBadRequest:         "bad_request",
+	ErrUnauthorized:       "unauthorized",
+	ErrForbidden:          "forbidden",
+	ErrNotFound:           "not_found",
+	ErrConflict:           "conflict",
+	ErrUnsupportedVersion: "unsupported_version",
+	ErrInternal:           "internal",
+}
+
+// errorCode returns the code sent along with err, empty for handler errors
+func errorCode(err error) string {
+	for target, code := range errorCodes {
+		if errors.Is(err, target) {
+			return code
+		}
+	}
+	return ""
+}
+
+// RemoteError is an error response received from the other side. It matches
+// the Err* values with errors.Is.
+type RemoteError struct {
+	Code    string
+	Message string
+	Details json.RawMessage // Set when the handler returned a DetailedError
+}
+
+func (e *RemoteError) Error() string { return e.Message }
+
+// Is reports whether the remote error carries the code of target
+func (e *RemoteError) Is(target error) bool {
+	code, ok := errorCodes[target]
+	return ok && code == e.Code
+}
+
+// DetailedError is implemented by handler errors that carry structured
+// information for the caller, like the fields that failed validation
+type DetailedError interface {
+	error
+	Details() interface{}
+}
+
+// Empty is the request or response of topics that carry no data
+type Empty struct{}
+
+// Validator is implemented by requests that check more than required fields
+type Validator interface {
+	Validate() error
+}
+
+// Call describes the request a handler is serving
+type Call struct {
+	Topic   string   // Received topic, without the pancy/request/<env>/ prefix
+	Params  []string // Values of the wildcards of the registered topic, in order
+	Version int      // Version the caller speaks, 0 when it didn't say
+	Caller  string   // Subject of the signed token, empty otherwise
+	ctx     context.Context
+}
+
+// Param returns the value of the i-th wildcard, or "" when there is none
+func (c *Call) Param(i int) string {
+	if i < 0 || i >= len(c.Params) {
+		return ""
+	}
+	return c.Params[i]
+}
+
+// Context returns the context of the request, which carries its trace
+func (c *Call) Context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+// route is a registered request topic
+type route struct {
+	pattern     string // May contain + and # wildcards
+	description string
+	version     int
+	auth        Authorizer
+	request     reflect.Type // nil for untyped handlers
+	response    reflect.Type
+	serve       func(call *Call, payload json.RawMessage) (interface{}, error)
+}
+
+// RouteOption configures a topic registered with On
+type RouteOption func(*route)
+
+// Describe sets the description shown in the schema listing
+func Describe(text string) RouteOption {
+	return func(r *route) { r.description = text }
+}
+
+// Version sets the current version of the topic, 1 by default. Requests that
+// ask for a newer version are rejected; older ones are served, so changes must
+// stay backwards compatible.
+func Version(v int) RouteOption {
+	return func(r *route) { r.version = v }
+}
+
+// WithAuth makes the topic require credentials accepted by auth
+func WithAuth(auth Authorizer) RouteOption {
+	return func(r *route) { r.auth = auth }
+}
+
+// On registers a typed handler for a request topic. The payload is decoded into
+// Req and validated (`validate:"required"` tags and the Validator interface)
+// before the handler runs; whatever the handler returns is sent as the data of
+// the response. The topic is listed in the schema with both types.
+func On[Req, Resp any](mc *MqttCommunicator, topic string, handler func(call *Call, req Req) (Resp, error), opts ...RouteOption) {
+	r := &route{
+		pattern:  topic,
+		version:  1,
+		request:  reflect.TypeOf((*Req)(nil)).Elem(),
+		response: reflect.TypeOf((*Resp)(nil)).Elem(),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	r.serve = func(call *Call, payload json.RawMessage) (interface{}, error) {
+		var req Req
+		if len(payload) > 0 && string(payload) != "null" {
+			if err := json.Unmarshal(payload, &req); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrBadRequest, err)
+			}
+		}
+		if err := checkRequired(reflect.ValueOf(req)); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrBadRequest, err)
+		}
+		// Validate may be declared on the value or on the pointer
+		if v, ok := any(&req).(Validator); ok {
+			if err := v.Validate(); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrBadRequest, err)
+			}
+		}
+		return handler(call, req)
+	}
+	mc.register(r)
+}
+
+// CallOption adds credentials or a version to a request sent with Invoke
+type CallOption func(*MqttRequest)
+
+// WithToken sends a shared secret or signed token
+func WithToken(token string) CallOption {
+	return func(r *MqttRequest) { r.Token = token }
+}
+
+// WithVersion asks for a version of the topic
+func WithVersion(v int) CallOption {
+	return func(r *MqttRequest) { r.Version = v }
+}
+
+// Invoke sends a typed request and decodes the data of the response into Resp
+func Invoke[Req, Resp any](mc *MqttCommunicator, topic string, req Req, timeout time.Duration, opts ...CallOption) (Resp, error) {
+	var resp Resp
+	request := MqttRequest{Payload: req}
+	for _, opt := range opts {
+		opt(&request)
+	}
+	raw, err := mc.roundTrip(topic, request, timeout)
+	if err != nil {
+		return resp, err
+	}
+	if len(raw) > 0 && string(raw) != "null" {
+		if err := json.Unmarshal(raw, &resp); err != nil {
+			return resp, fmt.Errorf("invalid response from %s: %w", topic, err)
+		}
+	}
+	return resp, nil
+}
+
+// incomingRequest is a request with the payload left undecoded
+type incomingRequest struct {
+	CorrelationID string          `json:"correlationId"`
+	Payload       json.RawMessage `json:"payload"`
+	Version       int             `json:"version"`
+	Token         string          `json:"token"`
+	Traceparent   string          `json:"traceparent"`
+}
+
+// register stores the route and subscribes to its topic
+func (mc *MqttCommunicator) register(r *route) {
+	mc.mu.Lock()
+	if _, exists := mc.routes[r.pattern]; exists {
+		logger.Warn(fmt.Sprintf("El tópico %s ya tenía un handler, se reemplaza", r.pattern), "MQTT")
+	}
+	mc.routes[r.pattern] = r
+	mc.mu.Unlock()
+
+	if err := mc.subscribeRoute(r); err != nil {
+		logger.Error(fmt.Sprintf("Error subscribing to topic %s: %v", mc.requestTopic(r.pattern), err), "MQTT")
+	}
+}
+
+func (mc *MqttCommunicator) subscribeRoute(r *route) error {
+	return mc.broker.Subscribe(mc.requestTopic(r.pattern), 1, func(topic string, payload []byte) {
+		mc.serve(r, topic, payload)
+	})
+}
+
+// resubscribe registers every route and raw subscription again after a
+// reconnect and publishes the schema again, in case the broker lost its
+// retained messages
+func (mc *MqttCommunicator) resubscribe() {
+	mc.mu.RLock()
+	routes := make([]*route, 0, len(mc.routes))
+	for _, r := range mc.routes {
+		routes = append(routes, r)
+	}
+	subscriptions := make(map[string]subscription, len(mc.subscriptions))
+	for topic, sub := range mc.subscriptions {
+		subscriptions[topic] = sub
+	}
+	publishSchema := mc.schemaPublished
+	mc.mu.RUnlock()
+
+	for _, r := range routes {
+		if err := mc.subscribeRoute(r); err != nil {
+			logger.Error(fmt.Sprintf("Error subscribing to topic %s: %v", mc.requestTopic(r.pattern), err), "MQTT")
+		}
+	}
+	for topic, sub := range subscriptions {
+		if err := mc.broker.Subscribe(topic, sub.qos, sub.handler); err != nil {
+			logger.Error(fmt.Sprintf("Error subscribing to topic %s: %v", topic, err), "MQTT")
+		}
+	}
+	if publishSchema {
+		if err := mc.PublishSchema(); err != nil {
+			logger.Warn(fmt.Sprintf("Error publicando el esquema MQTT: %v", err), "MQTT")
+		}
+	}
+}
+
+// serve answers a request received on a route
+func (mc *MqttCommunicator) serve(r *route, topic string, payload []byte) {
+	var request incomingRequest
+	if err := json.Unmarshal(payload, &request); err != nil {
+		logger.Error(fmt.Sprintf("Error parsing MQTT request: %v", err), "MQTT")
+		return
+	}
+	actualTopic := strings.TrimPrefix(topic, mc.requestTopic(""))
+
+	data, err := mc.handle(r, actualTopic, request)
+	observeRequest("in", r.pattern, err)
+
+	response := MqttResponse{
+		CorrelationID: request.CorrelationID,
+		Version:       r.version,
+	}
+	if err != nil {
+		response.Error = err.Error()
+		response.Code = errorCode(err)
+		var detailed DetailedError
+		if errors.As(err, &detailed) {
+			response.Details = detailed.Details()
+		}
+	} else {
+		response.Data = data
+	}
+
+	if err := mc.publish(mc.responseTopic(actualTopic, request.CorrelationID), 1, false, response); err != nil {
+		logger.Error(fmt.Sprintf("Error publicando la respuesta de %s: %v", actualTopic, err), "MQTT")
+	}
+}
+
+// handle checks the version and credentials of a request and runs the handler
+func (mc *MqttCommunicator) handle(r *route, topic string, request incomingRequest) (data interface{}, err error) {
+	if request.Version > r.version {
+		return nil, fmt.Errorf("%w: %d, this topic speaks up to %d", ErrUnsupportedVersion, request.Version, r.version)
+	}
+
+	call := &Call{
+		Topic:   topic,
+		Params:  topicParams(r.pattern, topic),
+		Version: request.Version,
+	}
+	if r.auth != nil {
+		caller, err := r.auth.Authorize(topic, request.Token)
+		if err != nil {
+			logger.WithFields(logger.Fields{"topic": topic}).WithError(err).Warn("Petición MQTT rechazada", "MQTT")
+			return nil, fmt.Errorf("%w: %v", ErrUnauthorized, err)
+		}
+		call.Caller = caller
+	}
+
+	ctx := tracing.ContextWithTraceparent(context.Background(), request.Traceparent)
+	ctx, span := tracing.Start(ctx, "mqtt "+r.pattern, tracing.KindServer,
+		tracing.String("messaging.system", "mqtt"),
+		tracing.String("messaging.destination", topic),
+	)
+	call.ctx = ctx
+	defer func() {
+		if rec := recover(); rec != nil {
+			logger.Error(fmt.Sprintf("Panic en el handler MQTT %s: %v", topic, rec), "MQTT")
+			data, err = nil, fmt.Errorf("%w: %v", ErrInternal, rec)
+		}
+		span.RecordError(err)
+		span.End()
+	}()
+
+	return r.serve(call, request.Payload)
+}
+
+// topicParams returns the parts of topic matched by the wildcards of pattern.
+// A # wildcard yields the rest of the topic as one value.
+func topicParams(pattern, topic string) []string {
+	patternParts := strings.Split(pattern, "/")
+	topicParts := strings.Split(topic, "/")
+
+	var params []string
+	for i, part := range patternParts {
+		switch {
+		case part == "#":
+			if i < len(topicParts) {
+				params = append(params, strings.Join(topicParts[i:], "/"))
+			}
+			return params
+		case part == "+" && i < len(topicParts):
+			params = append(params, topicParts[i])
+		}
+	}
+	return params
+}
+
+// checkRequired reports the first field tagged `validate:"required"` that has
+// its zero value, looking into nested structs
+func checkRequired(v reflect.Value) error {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		value := v.Field(i)
+		if hasRule(field.Tag.Get("validate"), "required") && value.IsZero() {
+			return fmt.Errorf("missing %s", jsonName(field))
+		}
+		if err := checkRequired(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func hasRule(tag, rule string) bool {
+	for _, r := range strings.Split(tag, ",") {
+		if strings.TrimSpace(r) == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonName returns the name a struct field has in JSON, or "" when the field
+// is skipped
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
@@ -0,0 +1,304 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type greetRequest struct {
+	GuildID string `json:"guildId" validate:"required"`
+	Name    string `json:"name"`
+	Times   int    `json:"times"`
+}
+
+func (r *greetRequest) Validate() error {
+	if r.Times < 0 {
+		return fmt.Errorf("times must be positive")
+	}
+	if r.Times == 0 {
+		r.Times = 1
+	}
+	return nil
+}
+
+type greetResponse struct {
+	Message string `json:"message"`
+	Section string `json:"section"`
+	Caller  string `json:"caller,omitempty"`
+}
+
+func newTestCommunicator(t *testing.T) (*MqttCommunicator, *MemoryBroker) {
+	t.Helper()
+	t.Setenv("BOT_ENV", "test")
+	broker := NewMemoryBroker()
+	return NewWithBroker(broker, "pancybot_test"), broker
+}
+
+func registerGreet(mc *MqttCommunicator, opts ...RouteOption) {
+	On(mc, "greet/+", func(call *Call, req greetRequest) (greetResponse, error) {
+		msg := ""
+		for i := 0; i < req.Times; i++ {
+			msg += "hola " + req.Name + " "
+		}
+		return greetResponse{Message: msg, Section: call.Param(0), Caller: call.Caller}, nil
+	}, opts...)
+}
+
+func TestTypedRoundTrip(t *testing.T) {
+	mc, broker := newTestCommunicator(t)
+	registerGreet(mc, Describe("Saluda"))
+
+	resp, err := Invoke[greetRequest, greetResponse](mc, "greet/lobby", greetRequest{GuildID: "1", Name: "pancy", Times: 2}, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Message != "hola pancy hola pancy " || resp.Section != "lobby" {
+		t.Errorf("Unexpected response %+v", resp)
+	}
+
+	// Validate runs on the decoded request and may fill defaults
+	resp, err = Invoke[greetRequest, greetResponse](mc, "greet/lobby", greetRequest{GuildID: "1", Name: "bot"}, time.Second)
+	if err != nil || resp.Message != "hola bot " {
+		t.Errorf("Expected the default of Validate, got %+v, %v", resp, err)
+	}
+
+	// Requests and responses go with QoS 1
+	for _, msg := range broker.Published("pancy/+/test/greet/#") {
+		if msg.QoS != 1 {
+			t.Errorf("Expected QoS 1 on %s, got %d", msg.Topic, msg.QoS)
+		}
+	}
+	if len(broker.Published("pancy/request/test/greet/lobby")) != 2 {
+		t.Error("Expected both requests on the full request topic")
+	}
+	// The response subscription is dropped once answered
+	if broker.Subscribed("pancy/response/test/greet/lobby/#") {
+		t.Error("Unexpected leftover subscription")
+	}
+}
+
+func TestValidation(t *testing.T) {
+	mc, _ := newTestCommunicator(t)
+	registerGreet(mc)
+
+	for name, payload := range map[string]interface{}{
+		"missing field": map[string]interface{}{"name": "pancy"},
+		"wrong type":    map[string]interface{}{"guildId": 10},
+		"validator":     map[string]interface{}{"guildId": "1", "times": -1},
+		"no payload":    nil,
+	} {
+		_, err := mc.Request("greet/lobby", payload, time.Second)
+		if !errors.Is(err, ErrBadRequest) {
+			t.Errorf("%s: expected a bad request, got %v", name, err)
+		}
+	}
+
+	_, err := mc.Request("greet/lobby", map[string]interface{}{"name": "pancy"}, time.Second)
+	if err == nil || err.Error() != "bad request: missing guildId" {
+		t.Errorf("Expected the missing field to be named, got %v", err)
+	}
+}
+
+func TestHandlerErrorsAndPanics(t *testing.T) {
+	mc, _ := newTestCommunicator(t)
+	On(mc, "fail", func(call *Call, req Empty) (Empty, error) {
+		return Empty{}, errors.New("guild not found")
+	})
+	On(mc, "boom", func(call *Call, req Empty) (Empty, error) {
+		var m map[string]int
+		m["x"] = 1
+		return Empty{}, nil
+	})
+
+	_, err := mc.Request("fail", nil, time.Second)
+	var remote *RemoteError
+	if !errors.As(err, &remote) || remote.Message != "guild not found" || remote.Code != "" {
+		t.Errorf("Expected the handler error as is, got %#v", err)
+	}
+
+	_, err = mc.Request("boom", nil, time.Second)
+	if !errors.Is(err, ErrInternal) {
+		t.Errorf("Expected a panic to become an internal error, got %v", err)
+	}
+}
+
+type fieldsError struct{ fields []string }
+
+func (e fieldsError) Error() string        { return "bad request: invalid fields" }
+func (e fieldsError) Unwrap() error        { return ErrBadRequest }
+func (e fieldsError) Details() interface{} { return e.fields }
+
+func TestErrorDetails(t *testing.T) {
+	mc, _ := newTestCommunicator(t)
+	On(mc, "check", func(call *Call, req Empty) (Empty, error) {
+		return Empty{}, fieldsError{fields: []string{"name", "color"}}
+	})
+
+	_, err := mc.Request("check", nil, time.Second)
+	var remote *RemoteError
+	if !errors.As(err, &remote) || !errors.Is(err, ErrBadRequest) {
+		t.Fatalf("Expected a bad request, got %#v", err)
+	}
+	var fields []string
+	if err := json.Unmarshal(remote.Details, &fields); err != nil || !reflect.DeepEqual(fields, []string{"name", "color"}) {
+		t.Errorf("Expected the details of the error, got %s", remote.Details)
+	}
+}
+
+func TestEmit(t *testing.T) {
+	mc, broker := newTestCommunicator(t)
+	if err := mc.Emit("guild-config/1", map[string]int{"version": 2}); err != nil {
+		t.Fatal(err)
+	}
+	msgs := broker.Published("pancy/events/test/guild-config/1")
+	if len(msgs) != 1 || msgs[0].QoS != 1 || string(msgs[0].Payload) != `{"version":2}` {
+		t.Errorf("Unexpected events %+v", msgs)
+	}
+}
+
+func TestVersioning(t *testing.T) {
+	mc, _ := newTestCommunicator(t)
+	registerGreet(mc, Version(2))
+	req := greetRequest{GuildID: "1", Name: "pancy"}
+
+	if _, err := Invoke[greetRequest, greetResponse](mc, "greet/a", req, time.Second, WithVersion(2)); err != nil {
+		t.Errorf("Expected version 2 to be served, got %v", err)
+	}
+	if _, err := Invoke[greetRequest, greetResponse](mc, "greet/a", req, time.Second, WithVersion(1)); err != nil {
+		t.Errorf("Expected older versions to be served, got %v", err)
+	}
+	_, err := Invoke[greetRequest, greetResponse](mc, "greet/a", req, time.Second, WithVersion(3))
+	if !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("Expected version 3 to be rejected, got %v", err)
+	}
+}
+
+func TestAuthorization(t *testing.T) {
+	mc, _ := newTestCommunicator(t)
+	const secret = "s3cret"
+	registerGreet(mc, WithAuth(AnyOf(SharedSecret(secret), SignedTokens(secret))))
+	req := greetRequest{GuildID: "1", Name: "pancy"}
+	call := func(token string) (greetResponse, error) {
+		return Invoke[greetRequest, greetResponse](mc, "greet/dev", req, time.Second, WithToken(token))
+	}
+
+	if _, err := call(""); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Expected no token to be rejected, got %v", err)
+	}
+	if _, err := call("guess"); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Expected a wrong secret to be rejected, got %v", err)
+	}
+	if resp, err := call(secret); err != nil || resp.Caller != "" {
+		t.Errorf("Expected the shared secret to be accepted, got %+v, %v", resp, err)
+	}
+
+	token, err := SignToken(secret, Claims{Subject: "dashboard", Topics: []string{"greet/+"}, ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp, err := call(token); err != nil || resp.Caller != "dashboard" {
+		t.Errorf("Expected the signed token to be accepted, got %+v, %v", resp, err)
+	}
+
+	for name, claims := range map[string]Claims{
+		"expired":     {Subject: "dashboard", Topics: []string{"#"}, ExpiresAt: time.Now().Add(-time.Minute).Unix()},
+		"other topic": {Subject: "dashboard", Topics: []string{"ping"}, ExpiresAt: time.Now().Add(time.Hour).Unix()},
+	} {
+		token, _ := SignToken(secret, claims)
+		if _, err := call(token); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("%s: expected the token to be rejected, got %v", name, err)
+		}
+	}
+
+	forged, _ := SignToken("other", Claims{Subject: "x", Topics: []string{"#"}, ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	if _, err := call(forged); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Expected a token signed with another secret to be rejected, got %v", err)
+	}
+
+	// Without a secret nothing gets through, not even an empty token
+	open := AnyOf(SharedSecret(""), SignedTokens(""))
+	if _, err := open.Authorize("greet/dev", ""); err == nil {
+		t.Error("Expected an empty secret to reject every request")
+	}
+}
+
+func TestLegacyHandler(t *testing.T) {
+	mc, _ := newTestCommunicator(t)
+	var got map[string]interface{}
+	mc.On("music/+/pause", func(payload map[string]interface{}) (interface{}, error) {
+		got = payload
+		return map[string]interface{}{"success": true}, nil
+	})
+
+	data, err := mc.Request("music/123/pause", map[string]interface{}{"a": "b"}, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["_topic"] != "music/123/pause" || got["a"] != "b" {
+		t.Errorf("Unexpected payload %v", got)
+	}
+	if !reflect.DeepEqual(data, map[string]interface{}{"success": true}) {
+		t.Errorf("Unexpected data %v", data)
+	}
+}
+
+func TestSchema(t *testing.T) {
+	mc, broker := newTestCommunicator(t)
+	registerGreet(mc, Describe("Saluda"), Version(3), WithAuth(SharedSecret("x")))
+
+	if err := mc.PublishSchema(); err != nil {
+		t.Fatal(err)
+	}
+	var listing SchemaListing
+	if err := json.Unmarshal(broker.Retained("pancy/schema/test"), &listing); err != nil {
+		t.Fatalf("Expected a retained listing: %v", err)
+	}
+	if listing.Environment != "test" {
+		t.Errorf("Unexpected environment %q", listing.Environment)
+	}
+
+	var greet *TopicSchema
+	for i, topic := range listing.Topics {
+		if topic.Topic == "greet/+" {
+			greet = &listing.Topics[i]
+		}
+	}
+	if greet == nil {
+		t.Fatalf("Expected greet/+ in %+v", listing.Topics)
+	}
+	if greet.Description != "Saluda" || greet.Version != 3 || greet.Auth != "shared-secret" {
+		t.Errorf("Unexpected topic %+v", greet)
+	}
+	if greet.Request.Properties["times"].Type != "integer" || !reflect.DeepEqual(greet.Request.Required, []string{"guildId"}) {
+		t.Errorf("Unexpected request schema %+v", greet.Request)
+	}
+	if greet.Response.Properties["message"].Type != "string" {
+		t.Errorf("Unexpected response schema %+v", greet.Response)
+	}
+
+	// The listing can also be requested
+	remote, err := Invoke[Empty, SchemaListing](mc, "rpc-schema", Empty{}, time.Second)
+	if err != nil || len(remote.Topics) != len(listing.Topics) {
+		t.Errorf("Expected the same listing over RPC, got %d topics, %v", len(remote.Topics), err)
+	}
+}
+
+func TestTopicParams(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, topic string
+		want           []string
+	}{
+		{"ping", "ping", nil},
+		{"music/+/play", "music/123/play", []string{"123"}},
+		{"music/+/skip/+", "music/123/skip/4", []string{"123", "4"}},
+		{"guild/#", "guild/1/levels/config", []string{"1/levels/config"}},
+	} {
+		if got := topicParams(tc.pattern, tc.topic); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("topicParams(%q, %q) = %v, want %v", tc.pattern, tc.topic, got, tc.want)
+		}
+	}
+}
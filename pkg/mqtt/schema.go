@@ -0,0 +1,177 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Schema describes a JSON value, with a subset of JSON Schema
+type Schema struct {
+	Type                 string             `json:"type,omitempty"` // Empty for any value
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// TopicSchema describes a request topic
+type TopicSchema struct {
+	Topic       string  `json:"topic"`
+	Description string  `json:"description,omitempty"`
+	Version     int     `json:"version"`
+	Auth        string  `json:"auth"`               // "none" or the accepted credentials
+	Request     *Schema `json:"request,omitempty"`  // Missing for untyped topics
+	Response    *Schema `json:"response,omitempty"` // Missing for untyped topics
+}
+
+// SchemaListing lists every request topic of the bot
+type SchemaListing struct {
+	Environment string        `json:"environment"`
+	ClientID    string        `json:"clientId"`
+	GeneratedAt time.Time     `json:"generatedAt"`
+	Topics      []TopicSchema `json:"topics"`
+}
+
+// schemaTopic is where the listing is published, retained
+func (mc *MqttCommunicator) schemaTopic() string {
+	return fmt.Sprintf("pancy/schema/%s", mc.env)
+}
+
+// Schema returns the listing of the registered topics, sorted by topic
+func (mc *MqttCommunicator) Schema() SchemaListing {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+
+	listing := SchemaListing{
+		Environment: mc.env,
+		ClientID:    mc.clientID,
+		GeneratedAt: time.Now().UTC(),
+		Topics:      make([]TopicSchema, 0, len(mc.routes)),
+	}
+	for _, r := range mc.routes {
+		topic := TopicSchema{
+			Topic:       r.pattern,
+			Description: r.description,
+			Version:     r.version,
+			Auth:        "none",
+		}
+		if r.auth != nil {
+			topic.Auth = r.auth.Scheme()
+		}
+		if r.request != nil {
+			topic.Request = schemaOf(r.request, map[reflect.Type]bool{})
+			topic.Response = schemaOf(r.response, map[reflect.Type]bool{})
+		}
+		listing.Topics = append(listing.Topics, topic)
+	}
+	sort.Slice(listing.Topics, func(i, j int) bool {
+		return listing.Topics[i].Topic < listing.Topics[j].Topic
+	})
+	return listing
+}
+
+// PublishSchema publishes the listing as a retained message on
+// pancy/schema/<env>, so the dashboard finds it as soon as it subscribes. It is
+// published again after every reconnect.
+func (mc *MqttCommunicator) PublishSchema() error {
+	mc.mu.Lock()
+	mc.schemaPublished = true
+	mc.mu.Unlock()
+	return mc.publish(mc.schemaTopic(), 1, true, mc.Schema())
+}
+
+// registerBuiltins registers the topics every communicator answers
+func (mc *MqttCommunicator) registerBuiltins() {
+	On(mc, "ping", func(call *Call, req Empty) (map[string]bool, error) {
+		return map[string]bool{"pong": true}, nil
+	}, Describe("Comprueba que el bot responde"))
+
+	On(mc, "rpc-schema", func(call *Call, req Empty) (SchemaListing, error) {
+		return mc.Schema(), nil
+	}, Describe("Lista los tópicos de petición con sus esquemas"))
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	rawType      = reflect.TypeOf(json.RawMessage(nil))
+)
+
+// schemaOf describes the JSON encoding of t. seen stops recursive types.
+func schemaOf(t reflect.Type, seen map[reflect.Type]bool) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case durationType:
+		return &Schema{Type: "integer", Format: "nanoseconds"}
+	case rawType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: schemaOf(t.Elem(), seen)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaOf(t.Elem(), seen)}
+	case reflect.Struct:
+		if seen[t] {
+			return &Schema{Type: "object"}
+		}
+		seen[t] = true
+		defer delete(seen, t)
+
+		s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+		addFields(s, t, seen)
+		return s
+	}
+	return &Schema{}
+}
+
+// addFields adds the exported fields of t to s, flattening embedded structs
+// like encoding/json does
+func addFields(s *Schema, t reflect.Type, seen map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := jsonName(field)
+		if name == "" {
+			continue
+		}
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				addFields(s, embedded, seen)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		s.Properties[name] = schemaOf(field.Type, seen)
+		if hasRule(field.Tag.Get("validate"), "required") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
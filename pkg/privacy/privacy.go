@@ -0,0 +1,208 @@
+// Package privacy lets users download everything the bot stores about them and
+// delete it, and server owners delete the data of their server. Deletions wait
+// for the requester to confirm them.
+package privacy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/bwmarrin/discordgo"
+	"github.com/google/uuid"
+)
+
+// Deletions a user can confirm
+const (
+	ActionDeleteUser  = "delete"
+	ActionDeleteGuild = "delete-server"
+)
+
+// ConfirmTimeout is how long a deletion waits to be confirmed
+const ConfirmTimeout = 2 * time.Minute
+
+// ExportCooldown is how often a user can download their data
+const ExportCooldown = 10 * time.Minute
+
+// MaxExportSize is the largest file the bot can send
+const MaxExportSize = 10 << 20
+
+var (
+	ErrNotFound     = errors.New("action not found or expired")
+	ErrNotRequester = errors.New("user did not request this action")
+	ErrNotOwner     = errors.New("user is not the owner of the server")
+	ErrTooSoon      = errors.New("export requested too soon")
+	ErrTooBig       = errors.New("export too big to send")
+	ErrDMClosed     = errors.New("cannot send direct messages to the user")
+)
+
+// Action is a deletion waiting for confirmation
+type Action struct {
+	ID      string
+	Kind    string
+	UserID  string // Requester, and whose data is deleted by ActionDeleteUser
+	GuildID string // Server deleted by ActionDeleteGuild
+}
+
+var (
+	pending     = make(map[string]Action)
+	lastExports = make(map[string]time.Time)
+	mu          sync.Mutex
+)
+
+// Prepare checks a deletion and keeps it until it is confirmed or expires.
+// Server deletions can only be requested by the owner of the server.
+func Prepare(s *discordgo.Session, a Action) (Action, error) {
+	if a.Kind == ActionDeleteGuild && !IsGuildOwner(s, a.GuildID, a.UserID) {
+		return a, ErrNotOwner
+	}
+
+	a.ID = uuid.New().String()[:8]
+	mu.Lock()
+	pending[a.ID] = a
+	mu.Unlock()
+
+	time.AfterFunc(ConfirmTimeout, func() {
+		mu.Lock()
+		delete(pending, a.ID)
+		mu.Unlock()
+	})
+	return a, nil
+}
+
+// IsGuildOwner reports whether a user owns a server
+func IsGuildOwner(s *discordgo.Session, guildID, userID string) bool {
+	if guildID == "" {
+		return false
+	}
+	guild, err := s.State.Guild(guildID)
+	if err != nil {
+		if guild, err = s.Guild(guildID); err != nil {
+			return false
+		}
+	}
+	return guild.OwnerID == userID
+}
+
+// Cancel drops a pending deletion
+func Cancel(id, userID string) error {
+	_, err := claim(id, userID)
+	return err
+}
+
+// claim removes a pending deletion so it can only run once
+func claim(id, userID string) (Action, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	a, ok := pending[id]
+	if !ok {
+		return Action{}, ErrNotFound
+	}
+	if a.UserID != userID {
+		return Action{}, ErrNotRequester
+	}
+	delete(pending, id)
+	return a, nil
+}
+
+// Confirm runs a pending deletion and returns what was done
+func Confirm(id, userID string) (string, error) {
+	a, err := claim(id, userID)
+	if err != nil {
+		return "", err
+	}
+
+	switch a.Kind {
+	case ActionDeleteUser:
+		report, err := database.DeleteUserData(a.UserID)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("✅ Se borraron **%d** registros con tus datos.", report.Total()), nil
+
+	case ActionDeleteGuild:
+		report, err := database.DeleteGuildData(a.GuildID)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("✅ Se borraron **%d** registros del servidor. La configuración vuelve a los valores por defecto.", report.Total()), nil
+	}
+	return "", ErrNotFound
+}
+
+// Preview describes what a deletion removes, for the confirmation message
+func Preview(a Action) string {
+	if a.Kind == ActionDeleteGuild {
+		return "⚠️ Se borrará **toda** la información del servidor: configuración, economía, niveles, advertencias, invitaciones, mercado, temporadas, premium del servidor y baneos temporales pendientes (que ya no se levantarán solos). Esta acción no se puede deshacer."
+	}
+	return "⚠️ Se borrarán tus perfiles de economía y niveles en todos los servidores, tu premium, tu historial de transacciones, invitaciones, roles temporales y publicaciones cerradas del mercado. Esta acción no se puede deshacer.\n\n" +
+		"Se conservan las advertencias y la blacklist, que son registros de moderación, y las publicaciones abiertas del mercado hasta que terminen."
+}
+
+// ExportFile builds the JSON archive with everything stored about a user.
+// A user can only download it once every ExportCooldown.
+func ExportFile(userID string) (*discordgo.File, error) {
+	mu.Lock()
+	if last, ok := lastExports[userID]; ok && time.Since(last) < ExportCooldown {
+		mu.Unlock()
+		return nil, ErrTooSoon
+	}
+	// The cooldown is checked on lookup; drop the ones that are over
+	for id, last := range lastExports {
+		if time.Since(last) >= ExportCooldown {
+			delete(lastExports, id)
+		}
+	}
+	lastExports[userID] = time.Now()
+	mu.Unlock()
+
+	export, err := database.ExportUserData(userID)
+	if err != nil {
+		// Let the user retry when the database is back
+		forgetExport(userID)
+		return nil, err
+	}
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxExportSize {
+		return nil, ErrTooBig
+	}
+	return &discordgo.File{
+		Name:        fmt.Sprintf("datos-%s.json", userID),
+		ContentType: "application/json",
+		Reader:      bytes.NewReader(data),
+	}, nil
+}
+
+// SendExport builds the archive of a user and sends it to them by DM
+func SendExport(s *discordgo.Session, userID string) error {
+	file, err := ExportFile(userID)
+	if err != nil {
+		return err
+	}
+	channel, err := s.UserChannelCreate(userID)
+	if err == nil {
+		_, err = s.ChannelMessageSendComplex(channel.ID, &discordgo.MessageSend{
+			Content: "📦 Estos son todos los datos que PancyBot guarda sobre ti. Las playlists no se guardan: se cargan al momento desde la plataforma de música.",
+			Files:   []*discordgo.File{file},
+		})
+	}
+	if err != nil {
+		forgetExport(userID)
+		return ErrDMClosed
+	}
+	return nil
+}
+
+// forgetExport lets a user whose export failed ask for it again
+func forgetExport(userID string) {
+	mu.Lock()
+	delete(lastExports, userID)
+	mu.Unlock()
+}
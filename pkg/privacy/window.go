@@ -0,0 +1,59 @@
+package privacy
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/bwmarrin/discordgo"
+)
+
+// Custom ID prefixes of the confirmation buttons, followed by the action ID
+const (
+	ConfirmButtonPrefix = "privacy_ok_"
+	CancelButtonPrefix  = "privacy_no_"
+)
+
+// ConfirmEmbed renders the confirmation of a pending deletion
+func ConfirmEmbed(a Action) *discordgo.MessageEmbed {
+	return discord.NewEmbed().
+		SetTitle("🔒 Confirmar borrado de datos").
+		SetDescription(fmt.Sprintf("%s\n\nConfirma antes de <t:%d:R>.", Preview(a), time.Now().Add(ConfirmTimeout).Unix())).
+		SetColor(discord.ColorWarning).
+		SetFooter("ID: "+a.ID, "").
+		Build()
+}
+
+// ConfirmComponents returns the buttons of a pending deletion
+func ConfirmComponents(a Action) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{Label: "Borrar", Emoji: &discordgo.ComponentEmoji{Name: "🗑️"}, Style: discordgo.DangerButton, CustomID: ConfirmButtonPrefix + a.ID},
+			discordgo.Button{Label: "Cancelar", Emoji: &discordgo.ComponentEmoji{Name: "✖️"}, Style: discordgo.SecondaryButton, CustomID: CancelButtonPrefix + a.ID},
+		}},
+	}
+}
+
+// ErrorText explains a privacy error
+func ErrorText(err error) string {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return "⌛ Esta acción ya no está pendiente. Vuelve a usar el comando."
+	case errors.Is(err, ErrNotRequester):
+		return "❌ Solo quien pidió el borrado puede confirmarlo."
+	case errors.Is(err, ErrNotOwner):
+		return "❌ Solo el dueño del servidor puede borrar sus datos."
+	case errors.Is(err, ErrTooSoon):
+		return fmt.Sprintf("⌛ Ya pediste tus datos hace poco. Puedes volver a pedirlos cada %d minutos.", int(ExportCooldown.Minutes()))
+	case errors.Is(err, ErrTooBig):
+		return fmt.Sprintf("❌ Tus datos superan los %d MB que se pueden enviar. Contacta con el soporte para recibirlos.", MaxExportSize>>20)
+	case errors.Is(err, ErrDMClosed):
+		return "❌ No puedo enviarte mensajes directos. Actívalos e inténtalo de nuevo."
+	case errors.Is(err, database.ErrDatabaseOffline):
+		return "❌ La base de datos no está disponible ahora mismo."
+	default:
+		return "❌ Ocurrió un error al procesar tus datos."
+	}
+}
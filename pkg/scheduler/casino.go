@@ -0,0 +1,39 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/casino"
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+)
+
+// StartCasinoScheduler refunds the stakes of the casino games that were open
+// when the bot stopped
+func StartCasinoScheduler(c *discord.ExtendedClient) {
+	client = c
+	go func() {
+		for {
+			refundAbandonedWagers()
+			time.Sleep(5 * time.Minute)
+		}
+	}()
+}
+
+func refundAbandonedWagers() {
+	db := database.Get()
+	if db == nil || !db.Connected() {
+		return
+	}
+
+	refunded, err := casino.RefundAbandoned()
+	if err != nil {
+		logger.Debug("Scheduler: Error devolviendo apuestas abandonadas: "+err.Error(), "Scheduler")
+		return
+	}
+	if refunded > 0 {
+		logger.Info(fmt.Sprintf("Scheduler: %d apuestas del casino devueltas tras un reinicio", refunded), "Scheduler")
+	}
+}
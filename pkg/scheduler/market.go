@@ -0,0 +1,37 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+)
+
+// StartMarketScheduler settles marketplace listings whose time ran out
+func StartMarketScheduler(c *discord.ExtendedClient) {
+	client = c
+	go func() {
+		for {
+			settleExpiredListings()
+			time.Sleep(1 * time.Minute)
+		}
+	}()
+}
+
+func settleExpiredListings() {
+	db := database.Get()
+	if db == nil || !db.Connected() {
+		return
+	}
+
+	settled, err := database.SettleExpiredListings()
+	if err != nil {
+		logger.Debug("Scheduler: Error liquidando publicaciones del mercado: "+err.Error(), "Scheduler")
+		return
+	}
+	if settled > 0 {
+		logger.Info(fmt.Sprintf("Scheduler: %d publicaciones del mercado liquidadas", settled), "Scheduler")
+	}
+}
@@ -0,0 +1,68 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+)
+
+// StartGuildPurgeScheduler deletes the data of the servers the bot left once
+// their retention ends
+func StartGuildPurgeScheduler(c *discord.ExtendedClient) {
+	client = c
+	go func() {
+		for {
+			// Wait first, so the guilds of the session are known before checking them
+			time.Sleep(1 * time.Hour)
+			purgeLeftGuilds()
+		}
+	}()
+}
+
+func purgeLeftGuilds() {
+	db := database.Get()
+	if db == nil || !db.Connected() {
+		return
+	}
+
+	due, err := database.GetDueGuildPurges(time.Now())
+	if err != nil {
+		logger.Debug("Scheduler: Error obteniendo servidores a borrar: "+err.Error(), "Scheduler")
+		return
+	}
+
+	for _, purge := range due {
+		if !onThisShard(purge.GuildID) {
+			continue
+		}
+		if _, err := client.Session.State.Guild(purge.GuildID); err == nil {
+			// Added back while the event was missed
+			_ = database.CancelGuildPurge(purge.GuildID)
+			continue
+		}
+
+		report, err := database.DeleteGuildData(purge.GuildID)
+		if err != nil {
+			logger.Warn(fmt.Sprintf("Error borrando los datos del servidor %s: %v", purge.GuildID, err), "Scheduler")
+			continue
+		}
+		logger.Info(fmt.Sprintf("Scheduler: datos del servidor %s borrados (%d registros), se fue el %s", purge.GuildID, report.Total(), purge.LeftAt.Format("2006-01-02")), "Scheduler")
+	}
+}
+
+// onThisShard reports whether a guild belongs to the shard of the session, so
+// only the shard that knows whether the bot is in it decides on its data
+func onThisShard(guildID string) bool {
+	if client.Session.ShardCount <= 1 {
+		return true
+	}
+	id, err := strconv.ParseUint(guildID, 10, 64)
+	if err != nil {
+		return false
+	}
+	return int((id>>22)%uint64(client.Session.ShardCount)) == client.Session.ShardID
+}
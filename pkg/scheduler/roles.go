@@ -0,0 +1,43 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+)
+
+// StartTimedRoleScheduler removes the roles granted by items once they expire
+func StartTimedRoleScheduler(c *discord.ExtendedClient) {
+	client = c
+	go func() {
+		for {
+			removeExpiredRoles()
+			time.Sleep(1 * time.Minute)
+		}
+	}()
+}
+
+func removeExpiredRoles() {
+	db := database.Get()
+	if db == nil || !db.Connected() {
+		return
+	}
+
+	expired, err := database.GetExpiredTimedRoles(time.Now())
+	if err != nil {
+		logger.Debug("Scheduler: Error obteniendo roles temporales: "+err.Error(), "Scheduler")
+		return
+	}
+
+	for _, timed := range expired {
+		err := client.Session.GuildMemberRoleRemove(timed.GuildID, timed.UserID, timed.RoleID)
+		if err != nil {
+			// Members that left or roles that were deleted are simply forgotten
+			logger.Debug(fmt.Sprintf("No se pudo quitar el rol temporal %s a %s: %v", timed.RoleID, timed.UserID, err), "Scheduler")
+		}
+		_ = database.RemoveTimedRole(timed.ID)
+	}
+}
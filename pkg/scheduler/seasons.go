@@ -0,0 +1,52 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/PancyStudios/PancyBotGo/pkg/seasons"
+)
+
+// StartSeasonScheduler ends the seasons whose time ran out and starts the next
+// ones of the guilds that renew them automatically
+func StartSeasonScheduler(c *discord.ExtendedClient) {
+	client = c
+	go func() {
+		for {
+			rollOverSeasons()
+			time.Sleep(1 * time.Minute)
+		}
+	}()
+}
+
+func rollOverSeasons() {
+	db := database.Get()
+	if db == nil || !db.Connected() {
+		return
+	}
+
+	now := time.Now()
+	due, err := database.GetDueSeasons(now)
+	if err != nil {
+		logger.Debug("Scheduler: Error obteniendo temporadas terminadas: "+err.Error(), "Scheduler")
+		return
+	}
+
+	for _, guild := range due {
+		// Every instance sees every guild; only the one serving it rolls it over
+		if !onThisShard(guild.ID) {
+			continue
+		}
+		if _, err := client.Session.State.Guild(guild.ID); err != nil {
+			continue
+		}
+		// Failures are retried on the next tick, the season stays open until then
+		if _, _, err := seasons.End(client.Session, guild.ID, now); err != nil {
+			logger.WithFields(logger.Fields{"guild": guild.ID, "season": guild.Seasons.Number}).
+				WithError(err).
+				Warn("No se pudo cerrar la temporada", "Scheduler")
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/PancyStudios/PancyBotGo/pkg/verification"
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// StartVerificationScheduler kicks members that did not verify before their timeout
+func StartVerificationScheduler(c *discord.ExtendedClient) {
+	client = c
+	go func() {
+		for {
+			checkExpiredVerifications()
+			time.Sleep(1 * time.Minute)
+		}
+	}()
+}
+
+func checkExpiredVerifications() {
+	db := database.Get()
+	if db == nil || !db.Connected() {
+		return
+	}
+
+	expired, err := database.GetExpiredPendingVerifications(time.Now())
+	if err != nil {
+		logger.Debug("Scheduler: Error obteniendo verificaciones pendientes: "+err.Error(), "Scheduler")
+		return
+	}
+
+	for _, pending := range expired {
+		guildDoc, err := database.GlobalGuildDM.Get(bson.M{"id": pending.GuildID})
+		if err != nil {
+			// Retried on the next tick
+			continue
+		}
+		if guildDoc == nil || !guildDoc.Protection.Verification.Enable || guildDoc.Protection.Verification.Role == "" {
+			_ = database.RemovePendingVerification(pending.GuildID, pending.UserID)
+			continue
+		}
+
+		// Members that already left or got the role some other way are simply forgotten
+		member, err := client.Session.GuildMember(pending.GuildID, pending.UserID)
+		if err != nil {
+			if isUnknownMember(err) {
+				_ = database.RemovePendingVerification(pending.GuildID, pending.UserID)
+			} else {
+				logger.Debug(fmt.Sprintf("Scheduler: No se pudo comprobar al miembro %s de %s: %v", pending.UserID, pending.GuildID, err), "Scheduler")
+			}
+			continue
+		}
+		if hasRole(member.Roles, guildDoc.Protection.Verification.Role) {
+			_ = database.RemovePendingVerification(pending.GuildID, pending.UserID)
+			continue
+		}
+
+		verification.Kick(client.Session, guildDoc, pending.UserID, verification.OutcomeTimeout,
+			fmt.Sprintf("No completó la verificación en %d minutos", guildDoc.Protection.Verification.TimeoutMinutes))
+	}
+}
+
+// isUnknownMember reports whether Discord answered that the member is not in the
+// guild, as opposed to a failure that may work on the next try
+func isUnknownMember(err error) bool {
+	var restErr *discordgo.RESTError
+	return errors.As(err, &restErr) &&
+		restErr.Response != nil && restErr.Response.StatusCode == http.StatusNotFound &&
+		restErr.Message != nil && restErr.Message.Code == discordgo.ErrCodeUnknownMember
+}
+
+func hasRole(roles []string, roleID string) bool {
+	for _, r := range roles {
+		if r == roleID {
+			return true
+		}
+	}
+	return false
+}
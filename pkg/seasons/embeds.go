@@ -0,0 +1,150 @@
+package seasons
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/ecoconfig"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/bwmarrin/discordgo"
+)
+
+// ResultsEmbed announces the final standings of a season
+func ResultsEmbed(guildData *models.GuildDocument, season, next *models.Season) *discordgo.MessageEmbed {
+	currency := ecoconfig.LocalCurrency(guildData.Economy)
+	embed := discord.NewEmbed().
+		SetTitle(fmt.Sprintf("🏁 ¡Terminó la Temporada %d!", season.Number)).
+		SetDescription(fmt.Sprintf("Del <t:%d:D> al <t:%d:D>. ¡Gracias a todos por participar!", season.StartedAt.Unix(), season.EndedAt.Unix())).
+		SetColor(0xE67E22).
+		AddField("🌟 Experiencia", standings(season.Levels, func(v int64) string { return fmt.Sprintf("%d XP", v) }), true).
+		AddField("💰 Ganancias", standings(season.Economy, currency.Format), true)
+
+	if len(season.Rewarded) > 0 {
+		embed.AddField("🎖️ Premios", fmt.Sprintf("Los %d primeros de cada tabla reciben el rol de la temporada.", guildData.Seasons.RewardTop), false)
+	}
+	if next != nil {
+		embed.AddField("▶️ Siguiente temporada", fmt.Sprintf("La **Temporada %d** ya empezó y termina <t:%d:R>.", next.Number, next.EndsAt.Unix()), false)
+	}
+	return embed.Build()
+}
+
+// StatusEmbed shows the running season of a guild with the top of each board
+func StatusEmbed(guildData *models.GuildDocument) *discordgo.MessageEmbed {
+	cfg := guildData.Seasons
+	if !cfg.Active {
+		description := "No hay ninguna temporada en curso."
+		if cfg.Number > 0 {
+			description += fmt.Sprintf(" La última fue la **Temporada %d**, mira sus resultados con el historial.", cfg.Number)
+		}
+		return discord.NewEmbed().
+			SetTitle("🏁 Temporadas").
+			SetDescription(description).
+			SetColor(discord.ColorInfo).
+			Build()
+	}
+
+	currency := ecoconfig.LocalCurrency(guildData.Economy)
+	levels, _ := database.GetSeasonStandings(database.SeasonBoardLevels, guildData.ID, cfg.Number, 3, 0)
+	economy, _ := database.GetSeasonStandings(database.SeasonBoardEconomy, guildData.ID, cfg.Number, 3, 0)
+
+	return discord.NewEmbed().
+		SetTitle(fmt.Sprintf("🏁 Temporada %d", cfg.Number)).
+		SetDescription(fmt.Sprintf("Empezó <t:%d:R> y termina <t:%d:R>.\nLa experiencia y el dinero de siempre no se pierden: solo se reinician los contadores de la temporada.", cfg.StartedAt.Unix(), cfg.EndsAt.Unix())).
+		SetColor(0xE67E22).
+		AddField("🌟 Experiencia", standings(levels, func(v int64) string { return fmt.Sprintf("%d XP", v) }), true).
+		AddField("💰 Ganancias", standings(economy, currency.Format), true).
+		AddField("⚙️ Configuración", Summary(cfg), false).
+		Build()
+}
+
+// HistoryEmbed lists past seasons with their winners
+func HistoryEmbed(past []*models.Season) *discordgo.MessageEmbed {
+	var lines []string
+	for _, season := range past {
+		winner := "sin participantes"
+		if len(season.Levels) > 0 {
+			winner = fmt.Sprintf("🌟 <@%s>", season.Levels[0].UserID)
+		}
+		if len(season.Economy) > 0 {
+			winner += fmt.Sprintf(" · 💰 <@%s>", season.Economy[0].UserID)
+		}
+		lines = append(lines, fmt.Sprintf("**Temporada %d** (<t:%d:d> - <t:%d:d>): %s", season.Number, season.StartedAt.Unix(), season.EndedAt.Unix(), winner))
+	}
+	description := strings.Join(lines, "\n")
+	if description == "" {
+		description = "Aún no ha terminado ninguna temporada."
+	}
+	return discord.NewEmbed().
+		SetTitle("📜 Historial de Temporadas").
+		SetDescription(description).
+		SetColor(discord.ColorInfo).
+		SetFooter("Indica un número de temporada para ver su clasificación", "").
+		Build()
+}
+
+// Summary describes the season settings of a guild
+func Summary(cfg models.SeasonsConfig) string {
+	renew := "No"
+	if cfg.AutoRenew {
+		renew = "Sí"
+	}
+	channel := "Ninguno"
+	if cfg.AnnounceChannel != "" {
+		channel = "<#" + cfg.AnnounceChannel + ">"
+	}
+	rewards := "Ninguno"
+	if cfg.RewardTop > 0 && (cfg.LevelsRole != "" || cfg.EconomyRole != "") {
+		var roles []string
+		if cfg.LevelsRole != "" {
+			roles = append(roles, "🌟 <@&"+cfg.LevelsRole+">")
+		}
+		if cfg.EconomyRole != "" {
+			roles = append(roles, "💰 <@&"+cfg.EconomyRole+">")
+		}
+		rewards = fmt.Sprintf("Top %d: %s", cfg.RewardTop, strings.Join(roles, ", "))
+	}
+	return fmt.Sprintf("**Duración:** %d días\n**Renovación automática:** %s\n**Anuncios:** %s\n**Roles de premio:** %s",
+		int(Length(cfg).Hours()/24), renew, channel, rewards)
+}
+
+// ErrorText explains a season error
+func ErrorText(err error) string {
+	switch {
+	case errors.Is(err, ErrRunning):
+		return "❌ Ya hay una temporada en curso. Termínala antes de empezar otra."
+	case errors.Is(err, ErrNotRunning):
+		return "❌ No hay ninguna temporada en curso."
+	case errors.Is(err, ErrInvalidConfig):
+		return "❌ " + strings.TrimPrefix(err.Error(), ErrInvalidConfig.Error()+": ")
+	case errors.Is(err, database.ErrDatabaseOffline), errors.Is(err, database.ErrLeaderboardUnavailable):
+		return "❌ La base de datos no está disponible ahora mismo."
+	default:
+		return "❌ Ocurrió un error con la temporada."
+	}
+}
+
+func standings(list []models.SeasonStanding, format func(int64) string) string {
+	if len(list) == 0 {
+		return "Sin participantes"
+	}
+	var lines []string
+	for i, standing := range list {
+		lines = append(lines, fmt.Sprintf("%s <@%s> - %s", position(i+1), standing.UserID, format(standing.Value)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func position(n int) string {
+	switch n {
+	case 1:
+		return "🥇"
+	case 2:
+		return "🥈"
+	case 3:
+		return "🥉"
+	}
+	return fmt.Sprintf("**%d.**", n)
+}
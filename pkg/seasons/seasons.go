@@ -0,0 +1,315 @@
+// Package seasons runs resettable competitions on top of the levels and the local
+// economy of a guild. A season counts the XP and the earnings made while it runs,
+// next to the lifetime totals that never reset. When it ends its standings are
+// archived, the top of each board can get a role and the next season may start.
+package seasons
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	DefaultLengthDays = 30
+	MaxLengthDays     = 365
+	ArchiveSize       = 10 // Standings kept of each board
+	MaxRewardTop      = ArchiveSize
+)
+
+var (
+	ErrRunning       = errors.New("a season is already running")
+	ErrNotRunning    = errors.New("no season is running")
+	ErrInvalidConfig = errors.New("invalid season config")
+	ErrNoGuild       = errors.New("guild config not found")
+)
+
+// mu serializes the start and end of seasons, which can come at the same time
+// from a command and from the scheduler. Other instances sharing the database
+// are kept out by claim.
+var mu sync.Mutex
+
+// Length returns how long the seasons of a guild last
+func Length(cfg models.SeasonsConfig) time.Duration {
+	days := cfg.LengthDays
+	if days <= 0 {
+		days = DefaultLengthDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// Validate checks the season settings an admin can change
+func Validate(cfg models.SeasonsConfig) error {
+	if cfg.LengthDays < 0 || cfg.LengthDays > MaxLengthDays {
+		return fmt.Errorf("%w: la duración debe estar entre 1 y %d días", ErrInvalidConfig, MaxLengthDays)
+	}
+	if cfg.RewardTop < 0 || cfg.RewardTop > MaxRewardTop {
+		return fmt.Errorf("%w: el top premiado debe estar entre 0 y %d", ErrInvalidConfig, MaxRewardTop)
+	}
+	return nil
+}
+
+// AddXP counts XP towards the running season. Profiles that still hold the XP of
+// an older season start over.
+func AddXP(profile *models.UserLevelProfile, cfg models.SeasonsConfig, xp int64) {
+	if !cfg.Active || xp <= 0 {
+		return
+	}
+	if profile.Season != cfg.Number {
+		profile.Season = cfg.Number
+		profile.SeasonXP = 0
+	}
+	profile.SeasonXP += xp
+}
+
+// SeasonXP returns the XP a profile has in the running season
+func SeasonXP(profile *models.UserLevelProfile, cfg models.SeasonsConfig) int64 {
+	if !cfg.Active || profile.Season != cfg.Number {
+		return 0
+	}
+	return profile.SeasonXP
+}
+
+// Start begins the next season of a guild
+func Start(guildID string, now time.Time) (*models.Season, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	guildData, err := loadGuild(guildID)
+	if err != nil {
+		return nil, err
+	}
+	if guildData.Seasons.Active {
+		return nil, ErrRunning
+	}
+	return start(guildData, now)
+}
+
+func start(guildData *models.GuildDocument, now time.Time) (*models.Season, error) {
+	if err := database.ResetSeasonEarnings(guildData.ID); err != nil {
+		return nil, err
+	}
+
+	cfg := &guildData.Seasons
+	season := &models.Season{
+		GuildID:   guildData.ID,
+		Number:    cfg.Number + 1,
+		StartedAt: now,
+		EndsAt:    now.Add(Length(*cfg)),
+		Levels:    []models.SeasonStanding{},
+		Economy:   []models.SeasonStanding{},
+	}
+	if err := database.SaveSeason(season); err != nil {
+		return nil, err
+	}
+
+	cfg.Active = true
+	cfg.Number = season.Number
+	cfg.StartedAt = season.StartedAt
+	cfg.EndsAt = season.EndsAt
+	if _, err := database.GlobalGuildDM.Set(bson.M{"id": guildData.ID}, guildData); err != nil {
+		return nil, err
+	}
+
+	logger.Info(fmt.Sprintf("Temporada %d iniciada en %s", season.Number, guildData.ID), "Seasons")
+	return season, nil
+}
+
+// Configure changes the season settings of a guild. A new length also moves the
+// end of the running season.
+func Configure(guildID string, change func(cfg *models.SeasonsConfig)) (models.SeasonsConfig, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	guildData, err := database.GlobalGuildDM.Get(bson.M{"id": guildID})
+	if err != nil {
+		return models.SeasonsConfig{}, err
+	}
+	if guildData == nil {
+		guildData = models.NewDefaultGuildDocument(guildID)
+	}
+
+	cfg := guildData.Seasons
+	change(&cfg)
+	if err := Validate(cfg); err != nil {
+		return cfg, err
+	}
+	if cfg.Active {
+		cfg.EndsAt = cfg.StartedAt.Add(Length(cfg))
+	}
+
+	guildData.Seasons = cfg
+	if _, err := database.GlobalGuildDM.Set(bson.M{"id": guildID}, guildData); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// End closes the running season of a guild: it archives the standings, hands out
+// the season roles, announces the results and starts the next season when the
+// guild renews them automatically. It returns the finished season and the new
+// one, if any.
+func End(s *discordgo.Session, guildID string, now time.Time) (*models.Season, *models.Season, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	guildData, err := loadGuild(guildID)
+	if err != nil {
+		return nil, nil, err
+	}
+	running := guildData.Seasons
+	if !running.Active {
+		return nil, nil, ErrNotRunning
+	}
+	if guildData, err = claim(guildID, running, now); err != nil {
+		return nil, nil, err
+	}
+	cfg := &guildData.Seasons
+
+	season, err := archive(guildID, running, now)
+	if err != nil {
+		// Reopen the season so the next tick retries it
+		release(guildID, running)
+		return nil, nil, err
+	}
+	logger.Info(fmt.Sprintf("Temporada %d terminada en %s", season.Number, guildID), "Seasons")
+
+	if s != nil {
+		reward(s, guildID, season)
+	}
+
+	var next *models.Season
+	if cfg.AutoRenew {
+		if next, err = start(guildData, now); err != nil {
+			logger.Error(fmt.Sprintf("No se pudo iniciar la siguiente temporada de %s: %v", guildID, err), "Seasons")
+		}
+	}
+
+	if s != nil && cfg.AnnounceChannel != "" {
+		if _, err := s.ChannelMessageSendEmbed(cfg.AnnounceChannel, ResultsEmbed(guildData, season, next)); err != nil {
+			logger.Warn(fmt.Sprintf("No se pudieron anunciar los resultados de la temporada en %s: %v", guildID, err), "Seasons")
+		}
+	}
+	return season, next, nil
+}
+
+// claim closes the running season in the database only if it is still open, so
+// a single instance archives it. It returns the guild as it was saved.
+func claim(guildID string, running models.SeasonsConfig, now time.Time) (*models.GuildDocument, error) {
+	guildData, err := database.GlobalGuildDM.Update(
+		bson.M{"id": guildID},
+		bson.M{"seasons.active": true, "seasons.number": running.Number},
+		bson.M{
+			"$set": bson.M{"seasons.active": false, "seasons.endsAt": now},
+			"$inc": bson.M{"version": 1},
+		},
+	)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		// Another instance or a command ended it first
+		return nil, ErrNotRunning
+	}
+	return guildData, err
+}
+
+// release reopens a claimed season whose archive failed
+func release(guildID string, running models.SeasonsConfig) {
+	_, err := database.GlobalGuildDM.Update(
+		bson.M{"id": guildID},
+		bson.M{"seasons.active": false, "seasons.number": running.Number},
+		bson.M{
+			"$set": bson.M{"seasons.active": true, "seasons.endsAt": running.EndsAt},
+			"$inc": bson.M{"version": 1},
+		},
+	)
+	if err != nil {
+		logger.Error(fmt.Sprintf("No se pudo reabrir la temporada %d de %s: %v", running.Number, guildID, err), "Seasons")
+	}
+}
+
+// archive stores the final standings of a season and the roles its winners get
+func archive(guildID string, running models.SeasonsConfig, now time.Time) (*models.Season, error) {
+	season, err := database.GetSeason(guildID, running.Number)
+	if err != nil {
+		return nil, err
+	}
+	if season == nil {
+		season = &models.Season{GuildID: guildID, Number: running.Number, StartedAt: running.StartedAt, EndsAt: running.EndsAt}
+	}
+
+	if season.Levels, err = database.GetSeasonStandings(database.SeasonBoardLevels, guildID, running.Number, ArchiveSize, 0); err != nil {
+		return nil, err
+	}
+	if season.Economy, err = database.GetSeasonStandings(database.SeasonBoardEconomy, guildID, running.Number, ArchiveSize, 0); err != nil {
+		return nil, err
+	}
+	season.EndedAt = now
+	season.Rewarded = grants(running, season)
+	if err := database.SaveSeason(season); err != nil {
+		return nil, err
+	}
+	return season, nil
+}
+
+// grants returns the season roles the winners of a season get
+func grants(cfg models.SeasonsConfig, season *models.Season) []models.SeasonRoleGrant {
+	var out []models.SeasonRoleGrant
+	add := func(roleID string, standings []models.SeasonStanding) {
+		if roleID == "" {
+			return
+		}
+		for i, standing := range standings {
+			if i >= cfg.RewardTop {
+				break
+			}
+			out = append(out, models.SeasonRoleGrant{UserID: standing.UserID, RoleID: roleID})
+		}
+	}
+	add(cfg.LevelsRole, season.Levels)
+	add(cfg.EconomyRole, season.Economy)
+	return out
+}
+
+// reward takes the season roles from the winners of the previous season and
+// gives them to the new ones
+func reward(s *discordgo.Session, guildID string, season *models.Season) {
+	keep := make(map[models.SeasonRoleGrant]bool, len(season.Rewarded))
+	for _, grant := range season.Rewarded {
+		keep[grant] = true
+	}
+
+	if previous, err := database.GetSeason(guildID, season.Number-1); err == nil && previous != nil {
+		for _, grant := range previous.Rewarded {
+			if keep[grant] {
+				continue
+			}
+			if err := s.GuildMemberRoleRemove(guildID, grant.UserID, grant.RoleID); err != nil {
+				logger.Debug(fmt.Sprintf("No se pudo quitar el rol de temporada %s a %s: %v", grant.RoleID, grant.UserID, err), "Seasons")
+			}
+		}
+	}
+
+	for _, grant := range season.Rewarded {
+		if err := s.GuildMemberRoleAdd(guildID, grant.UserID, grant.RoleID); err != nil {
+			logger.Debug(fmt.Sprintf("No se pudo dar el rol de temporada %s a %s: %v", grant.RoleID, grant.UserID, err), "Seasons")
+		}
+	}
+}
+
+func loadGuild(guildID string) (*models.GuildDocument, error) {
+	guildData, err := database.GlobalGuildDM.Get(bson.M{"id": guildID})
+	if err != nil {
+		return nil, err
+	}
+	if guildData == nil {
+		return nil, ErrNoGuild
+	}
+	return guildData, nil
+}
@@ -0,0 +1,111 @@
+package seasons
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestAddXPStartsOverInANewSeason(t *testing.T) {
+	profile := &models.UserLevelProfile{XP: 500, Season: 1, SeasonXP: 300}
+
+	AddXP(profile, models.SeasonsConfig{Active: true, Number: 2}, 20)
+	if profile.Season != 2 || profile.SeasonXP != 20 {
+		t.Errorf("season %d with %d XP, want season 2 with 20 XP", profile.Season, profile.SeasonXP)
+	}
+
+	AddXP(profile, models.SeasonsConfig{Number: 2}, 20)
+	if profile.SeasonXP != 20 {
+		t.Errorf("XP was counted with no season running: %d", profile.SeasonXP)
+	}
+	if got := SeasonXP(profile, models.SeasonsConfig{Active: true, Number: 3}); got != 0 {
+		t.Errorf("SeasonXP of a stale profile = %d, want 0", got)
+	}
+}
+
+func TestGrantsRewardTheTopOfEachBoard(t *testing.T) {
+	season := &models.Season{
+		Levels:  []models.SeasonStanding{{UserID: "a"}, {UserID: "b"}, {UserID: "c"}},
+		Economy: []models.SeasonStanding{{UserID: "c"}},
+	}
+	cfg := models.SeasonsConfig{RewardTop: 2, LevelsRole: "xp", EconomyRole: "eco"}
+
+	got := grants(cfg, season)
+	want := []models.SeasonRoleGrant{{UserID: "a", RoleID: "xp"}, {UserID: "b", RoleID: "xp"}, {UserID: "c", RoleID: "eco"}}
+	if len(got) != len(want) {
+		t.Fatalf("grants = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("grants[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	cfg.LevelsRole, cfg.EconomyRole = "", ""
+	if got := grants(cfg, season); len(got) != 0 {
+		t.Errorf("no roles configured but got %v", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, cfg := range []models.SeasonsConfig{{LengthDays: -1}, {LengthDays: MaxLengthDays + 1}, {RewardTop: MaxRewardTop + 1}} {
+		if err := Validate(cfg); err == nil {
+			t.Errorf("Validate(%+v) should fail", cfg)
+		}
+	}
+	if err := Validate(models.SeasonsConfig{LengthDays: 7, RewardTop: 3}); err != nil {
+		t.Errorf("a valid config was rejected: %v", err)
+	}
+}
+
+func TestClaimEndsASeasonOnce(t *testing.T) {
+	database.InitGlobalDataManagers(database.NewMemoryDatabase())
+	guild := models.NewDefaultGuildDocument("seasons-claim")
+	guild.Seasons = models.SeasonsConfig{Active: true, Number: 3, EndsAt: time.Now()}
+	if _, err := database.GlobalGuildDM.Set(bson.M{"id": guild.ID}, guild); err != nil {
+		t.Fatal(err)
+	}
+
+	// Both instances read the season as running
+	running := guild.Seasons
+	claimed, err := claim(guild.ID, running, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claimed.Seasons.Active {
+		t.Error("Expected the claimed season to be closed")
+	}
+	if _, err := claim(guild.ID, running, time.Now()); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Expected the second claim to fail, got %v", err)
+	}
+
+	// A failed archive reopens the season for the next tick
+	release(guild.ID, running)
+	seasonsDM := database.SeasonsDM
+	database.SeasonsDM = nil
+	_, _, err = End(nil, guild.ID, time.Now())
+	database.SeasonsDM = seasonsDM
+	if err == nil {
+		t.Fatal("Expected the archive to fail without the seasons collection")
+	}
+	stored, err := database.GlobalGuildDM.Get(bson.M{"id": guild.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stored.Seasons.Active || stored.Seasons.Number != 3 {
+		t.Errorf("Expected season 3 to be running again, got %+v", stored.Seasons)
+	}
+
+	// The retry archives it
+	season, _, err := End(nil, guild.ID, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if season.Number != 3 || season.EndedAt.IsZero() {
+		t.Errorf("Expected season 3 to be archived, got %+v", season)
+	}
+}
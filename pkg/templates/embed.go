@@ -0,0 +1,62 @@
+package templates
+
+import (
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/bwmarrin/discordgo"
+)
+
+// RenderEmbed converts a stored CustomEmbed into a discordgo embed, rendering every text field
+func RenderEmbed(customEmbed models.CustomEmbed, vars Vars) *discordgo.MessageEmbed {
+	render := func(s string) string {
+		return Render(s, vars)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       render(customEmbed.Title),
+		Description: render(customEmbed.Description),
+		Color:       customEmbed.Color,
+	}
+	if customEmbed.Thumbnail != "" {
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: render(customEmbed.Thumbnail)}
+	}
+	if customEmbed.Image != "" {
+		embed.Image = &discordgo.MessageEmbedImage{URL: render(customEmbed.Image)}
+	}
+	if customEmbed.AuthorName != "" || customEmbed.AuthorIcon != "" {
+		embed.Author = &discordgo.MessageEmbedAuthor{Name: render(customEmbed.AuthorName), IconURL: render(customEmbed.AuthorIcon)}
+	}
+	if customEmbed.FooterText != "" || customEmbed.FooterIcon != "" {
+		embed.Footer = &discordgo.MessageEmbedFooter{Text: render(customEmbed.FooterText), IconURL: render(customEmbed.FooterIcon)}
+	}
+	return embed
+}
+
+// FindEmbed returns the custom embed with the given ID, or nil
+func FindEmbed(guildDoc *models.GuildDocument, embedID string) *models.CustomEmbed {
+	if guildDoc == nil || embedID == "" {
+		return nil
+	}
+	for i := range guildDoc.Embeds {
+		if guildDoc.Embeds[i].ID == embedID {
+			return &guildDoc.Embeds[i]
+		}
+	}
+	return nil
+}
+
+// ValidateEmbed checks every text field of a custom embed
+func ValidateEmbed(customEmbed models.CustomEmbed) error {
+	var problems []string
+	for _, field := range []string{
+		customEmbed.Title, customEmbed.Description, customEmbed.Thumbnail, customEmbed.Image,
+		customEmbed.AuthorName, customEmbed.AuthorIcon, customEmbed.FooterText, customEmbed.FooterIcon,
+	} {
+		if err := Validate(field); err != nil {
+			problems = append(problems, err.(*ValidationError).Problems...)
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: problems}
+}
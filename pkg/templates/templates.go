@@ -0,0 +1,467 @@
+// Package templates renders the user-configurable messages of the bot (welcome,
+// farewell, level-up and custom embeds).
+//
+// Syntax:
+//
+//	{user.name}                      variable
+//	{if:level>=10}...{else}...{/if}  conditional block ({else} is optional)
+//	{choose:Hola|Hey|Buenas}         random choice, options may contain variables
+//
+// Conditions accept a single variable (true when it is not empty or "0"), a
+// negated variable ({if:!inviter}) or a comparison using ==, !=, >, >=, < or <=.
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Variable describes a placeholder that can be used inside a template
+type Variable struct {
+	Name        string
+	Description string
+	Legacy      bool // kept for old configurations, hidden from listings
+}
+
+// Variables is the list of every placeholder known by the engine
+var Variables = []Variable{
+	{Name: "user", Description: "Mención del usuario"},
+	{Name: "user.mention", Description: "Mención del usuario"},
+	{Name: "user.id", Description: "ID del usuario"},
+	{Name: "user.name", Description: "Nombre de usuario"},
+	{Name: "user.avatar", Description: "URL del avatar del usuario"},
+	{Name: "user.created", Description: "Fecha de creación de la cuenta"},
+	{Name: "server", Description: "Nombre del servidor"},
+	{Name: "server.name", Description: "Nombre del servidor"},
+	{Name: "server.id", Description: "ID del servidor"},
+	{Name: "server.icon", Description: "URL del icono del servidor"},
+	{Name: "server.members", Description: "Número de miembros del servidor"},
+	{Name: "level", Description: "Nivel del usuario"},
+	{Name: "xp", Description: "Experiencia del usuario"},
+	{Name: "inviter", Description: "Usuario que invitó al miembro"},
+
+	// Retro-compatibilidad
+	{Name: "username", Legacy: true},
+	{Name: "guild.id", Legacy: true},
+	{Name: "guild.name", Legacy: true},
+}
+
+var knownVariables = func() map[string]bool {
+	known := make(map[string]bool, len(Variables))
+	for _, v := range Variables {
+		known[v.Name] = true
+	}
+	return known
+}()
+
+// IsKnownVariable reports whether name is a placeholder supported by the engine
+func IsKnownVariable(name string) bool {
+	return knownVariables[name]
+}
+
+// Help lists the non-legacy variables and the block syntax, one per line
+func Help() string {
+	var sb strings.Builder
+	for _, v := range Variables {
+		if v.Legacy {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("`{%s}` %s\n", v.Name, v.Description))
+	}
+	sb.WriteString("`{if:level>=10}...{else}...{/if}` Bloque condicional\n")
+	sb.WriteString("`{choose:Hola|Hey}` Elige una opción al azar")
+	return sb.String()
+}
+
+// Vars holds the values of the placeholders for a single render
+type Vars map[string]string
+
+// NewVars builds the user and server placeholders. Both arguments may be nil.
+func NewVars(user *discordgo.User, guild *discordgo.Guild) Vars {
+	vars := Vars{}
+	if user != nil {
+		mention := fmt.Sprintf("<@%s>", user.ID)
+		vars["user"] = mention
+		vars["user.mention"] = mention
+		vars["user.id"] = user.ID
+		vars["user.name"] = user.Username
+		vars["user.avatar"] = user.AvatarURL("256")
+		if created, err := discordgo.SnowflakeTimestamp(user.ID); err == nil {
+			vars["user.created"] = fmt.Sprintf("<t:%d:D>", created.Unix())
+		}
+		vars["username"] = user.Username
+	}
+	if guild != nil {
+		vars["server"] = guild.Name
+		vars["server.name"] = guild.Name
+		vars["server.id"] = guild.ID
+		vars["server.icon"] = guild.IconURL("256")
+		vars["server.members"] = strconv.Itoa(guild.MemberCount)
+		vars["guild.id"] = guild.ID
+		vars["guild.name"] = guild.Name
+	}
+	return vars
+}
+
+// WithLevel sets the {level} and {xp} placeholders
+func (v Vars) WithLevel(level, xp int64) Vars {
+	v["level"] = strconv.FormatInt(level, 10)
+	v["xp"] = strconv.FormatInt(xp, 10)
+	return v
+}
+
+// WithInviter sets the {inviter} placeholder
+func (v Vars) WithInviter(inviter string) Vars {
+	v["inviter"] = inviter
+	return v
+}
+
+// Set overrides a single placeholder
+func (v Vars) Set(name, value string) Vars {
+	v[name] = value
+	return v
+}
+
+// Render executes the template. It never fails: malformed blocks are written
+// as plain text and unknown variables are left untouched, so configurations
+// saved before the engine existed keep working.
+func Render(tpl string, vars Vars) string {
+	if !strings.Contains(tpl, "{") {
+		return tpl
+	}
+	nodes, _ := parse(tpl)
+	var sb strings.Builder
+	renderNodes(&sb, nodes, vars)
+	return sb.String()
+}
+
+// ValidationError lists every problem found in a template
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "plantilla inválida: " + strings.Join(e.Problems, "; ")
+}
+
+// Validate checks the syntax of the template and reports unknown variables
+func Validate(tpl string) error {
+	_, problems := parse(tpl)
+	if len(problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: problems}
+}
+
+// Explain formats the problems of a Validate error as a list for Discord messages
+func Explain(err error) string {
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		return err.Error()
+	}
+	lines := make([]string, len(verr.Problems))
+	for i, problem := range verr.Problems {
+		lines[i] = "• " + problem
+	}
+	return strings.Join(lines, "\n")
+}
+
+// randIntn picks the option of a {choose:} block
+var randIntn = rand.Intn
+
+// --- Parsing ---
+
+type node interface {
+	render(sb *strings.Builder, vars Vars)
+}
+
+type textNode string
+
+type varNode struct {
+	name string
+	raw  string
+}
+
+type ifNode struct {
+	cond condition
+	then []node
+	els  []node
+}
+
+type chooseNode struct {
+	options [][]node
+}
+
+type condition struct {
+	name   string
+	negate bool
+	op     string
+	value  string
+}
+
+var (
+	identifierRe = regexp.MustCompile(`^[a-z][a-z0-9_]*(\.[a-z0-9_]+)*$`)
+	conditionRe  = regexp.MustCompile(`^(!?)\s*([a-z][a-z0-9_.]*)\s*(?:(==|!=|>=|<=|>|<)\s*(.*?))?\s*$`)
+)
+
+type parser struct {
+	src      string
+	pos      int
+	problems []string
+}
+
+func parse(src string) ([]node, []string) {
+	p := &parser{src: src}
+	nodes, _ := p.parseUntil(false)
+	return nodes, p.problems
+}
+
+func (p *parser) problem(format string, args ...interface{}) {
+	p.problems = append(p.problems, fmt.Sprintf(format, args...))
+}
+
+// parseUntil reads nodes until the end of the input or, inside a conditional,
+// until an {else} or {/if} tag, which is returned as terminator
+func (p *parser) parseUntil(inIf bool) (nodes []node, terminator string) {
+	for p.pos < len(p.src) {
+		open := strings.IndexByte(p.src[p.pos:], '{')
+		if open < 0 {
+			nodes = append(nodes, textNode(p.src[p.pos:]))
+			p.pos = len(p.src)
+			break
+		}
+		if open > 0 {
+			nodes = append(nodes, textNode(p.src[p.pos:p.pos+open]))
+			p.pos += open
+		}
+
+		start := p.pos
+		end := p.closingBrace(start)
+		if end < 0 {
+			if strings.HasPrefix(p.src[start:], "{if:") || strings.HasPrefix(p.src[start:], "{choose:") {
+				p.problem("bloque sin cerrar en la posición %d", start+1)
+			}
+			// A lone brace in regular text
+			nodes = append(nodes, textNode("{"))
+			p.pos++
+			continue
+		}
+		raw := p.src[start : end+1]
+		tag := p.src[start+1 : end]
+		p.pos = end + 1
+
+		switch {
+		case tag == "else" || tag == "/if":
+			if inIf {
+				return nodes, tag
+			}
+			p.problem("`{%s}` sin `{if:...}`", tag)
+			nodes = append(nodes, textNode(raw))
+
+		case strings.HasPrefix(tag, "if:"):
+			nodes = append(nodes, p.parseIf(tag[len("if:"):], raw))
+
+		case strings.HasPrefix(tag, "choose:"):
+			nodes = append(nodes, p.parseChoose(tag[len("choose:"):]))
+
+		case identifierRe.MatchString(tag):
+			if !IsKnownVariable(tag) {
+				p.problem("variable desconocida `{%s}`", tag)
+			}
+			nodes = append(nodes, varNode{name: tag, raw: raw})
+
+		default:
+			nodes = append(nodes, textNode(raw))
+		}
+	}
+
+	if inIf {
+		p.problem("falta `{/if}`")
+	}
+	return nodes, ""
+}
+
+func (p *parser) parseIf(expr, raw string) node {
+	cond, ok := parseCondition(expr)
+	if !ok {
+		p.problem("condición inválida `%s`", raw)
+	} else if !IsKnownVariable(cond.name) {
+		p.problem("variable desconocida `%s` en la condición", cond.name)
+	}
+
+	n := &ifNode{cond: cond}
+	var terminator string
+	n.then, terminator = p.parseUntil(true)
+	if terminator == "else" {
+		n.els, terminator = p.parseUntil(true)
+		if terminator == "else" {
+			p.problem("`{else}` repetido en el mismo bloque")
+			// Treat everything up to the closing tag as part of the else branch
+			rest, _ := p.parseUntil(true)
+			n.els = append(append(n.els, textNode("{else}")), rest...)
+		}
+	}
+	return n
+}
+
+func (p *parser) parseChoose(body string) node {
+	n := &chooseNode{}
+	for _, option := range splitOptions(body) {
+		sub := &parser{src: option}
+		nodes, _ := sub.parseUntil(false)
+		p.problems = append(p.problems, sub.problems...)
+		n.options = append(n.options, nodes)
+	}
+	if len(n.options) < 2 {
+		p.problem("`{choose:...}` necesita al menos dos opciones separadas por `|`")
+	}
+	return n
+}
+
+// closingBrace returns the index of the brace closing the tag at start, or -1.
+// Only {choose:} may contain nested braces; any other tag ends at the first '}'
+// and is not a tag at all if another '{' shows up before it.
+func (p *parser) closingBrace(start int) int {
+	if !strings.HasPrefix(p.src[start:], "{choose:") {
+		for i := start + 1; i < len(p.src); i++ {
+			switch p.src[i] {
+			case '{':
+				return -1
+			case '}':
+				return i
+			}
+		}
+		return -1
+	}
+
+	depth := 0
+	for i := start; i < len(p.src); i++ {
+		switch p.src[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitOptions splits a choose body on the '|' characters that are not inside braces
+func splitOptions(body string) []string {
+	var options []string
+	depth, last := 0, 0
+	for i := 0; i < len(body); i++ {
+		switch body[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case '|':
+			if depth == 0 {
+				options = append(options, body[last:i])
+				last = i + 1
+			}
+		}
+	}
+	return append(options, body[last:])
+}
+
+func parseCondition(expr string) (condition, bool) {
+	m := conditionRe.FindStringSubmatch(strings.TrimSpace(expr))
+	if m == nil {
+		return condition{}, false
+	}
+	cond := condition{negate: m[1] == "!", name: m[2], op: m[3], value: m[4]}
+	if cond.negate && cond.op != "" {
+		return condition{}, false
+	}
+	return cond, true
+}
+
+// --- Rendering ---
+
+func renderNodes(sb *strings.Builder, nodes []node, vars Vars) {
+	for _, n := range nodes {
+		n.render(sb, vars)
+	}
+}
+
+func (n textNode) render(sb *strings.Builder, _ Vars) {
+	sb.WriteString(string(n))
+}
+
+func (n varNode) render(sb *strings.Builder, vars Vars) {
+	if value, ok := vars[n.name]; ok {
+		sb.WriteString(value)
+		return
+	}
+	if IsKnownVariable(n.name) {
+		// Known but not available in this context (e.g. {level} on a welcome)
+		return
+	}
+	sb.WriteString(n.raw)
+}
+
+func (n *ifNode) render(sb *strings.Builder, vars Vars) {
+	if n.cond.eval(vars) {
+		renderNodes(sb, n.then, vars)
+	} else {
+		renderNodes(sb, n.els, vars)
+	}
+}
+
+func (n *chooseNode) render(sb *strings.Builder, vars Vars) {
+	if len(n.options) == 0 {
+		return
+	}
+	renderNodes(sb, n.options[randIntn(len(n.options))], vars)
+}
+
+func (c condition) eval(vars Vars) bool {
+	value := vars[c.name]
+	if c.op == "" {
+		truthy := value != "" && value != "0"
+		return truthy != c.negate
+	}
+
+	left, lerr := strconv.ParseFloat(value, 64)
+	right, rerr := strconv.ParseFloat(c.value, 64)
+	numeric := lerr == nil && rerr == nil
+
+	switch c.op {
+	case "==":
+		if numeric {
+			return left == right
+		}
+		return strings.EqualFold(value, c.value)
+	case "!=":
+		if numeric {
+			return left != right
+		}
+		return !strings.EqualFold(value, c.value)
+	}
+
+	if !numeric {
+		return false
+	}
+	switch c.op {
+	case ">":
+		return left > right
+	case ">=":
+		return left >= right
+	case "<":
+		return left < right
+	case "<=":
+		return left <= right
+	}
+	return false
+}
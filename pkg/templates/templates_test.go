@@ -0,0 +1,81 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+)
+
+func testVars() Vars {
+	return Vars{
+		"user":      "<@1>",
+		"user.name": "pancy",
+		"server":    "Test Guild",
+		"level":     "12",
+		"xp":        "1500",
+	}
+}
+
+func TestRender(t *testing.T) {
+	original := randIntn
+	randIntn = func(n int) int { return n - 1 }
+	defer func() { randIntn = original }()
+
+	tests := []struct {
+		tpl      string
+		expected string
+	}{
+		{"Hola {user}, bienvenido a {server}", "Hola <@1>, bienvenido a Test Guild"},
+		{"{user.name} llegó al nivel {level}", "pancy llegó al nivel 12"},
+		{"{if:level>=10}veterano{else}novato{/if}", "veterano"},
+		{"{if:level<10}novato{/if}!", "!"},
+		{"{if:inviter}por {inviter}{else}sin invitación{/if}", "sin invitación"},
+		{"{if:!inviter}solo{/if}", "solo"},
+		{"{if:user.name==PANCY}yo{/if}", "yo"},
+		{"{choose:Hola|Hey {user.name}}", "Hey pancy"},
+		{"{if:level>5}{choose:a|{if:xp>1000}b{/if}}{/if}", "b"},
+		{"{desconocida} y {level}", "{desconocida} y 12"},
+		{"{inviter}", ""},
+		{"llaves { sueltas } {user.name}", "llaves { sueltas } pancy"},
+		{"{/if} suelto", "{/if} suelto"},
+	}
+
+	for _, tt := range tests {
+		if got := Render(tt.tpl, testVars()); got != tt.expected {
+			t.Errorf("Render(%q) = %q, expected %q", tt.tpl, got, tt.expected)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := []string{
+		"Hola {user}",
+		"{if:level>=5}{choose:a|b}{else}c{/if}",
+		"{username} en {guild.name}",
+		"sin variables",
+	}
+	for _, tpl := range valid {
+		if err := Validate(tpl); err != nil {
+			t.Errorf("Validate(%q) returned %v", tpl, err)
+		}
+	}
+
+	invalid := map[string]string{
+		"{nivel}":              "variable desconocida",
+		"{if:level>5}a":        "falta",
+		"a{else}b":             "sin",
+		"{if:level ~ 3}a{/if}": "condición inválida",
+		"{choose:solo}":        "dos opciones",
+		"{if:foo}a{/if}":       "variable desconocida",
+		"{choose:a|b":          "sin cerrar",
+	}
+	for tpl, want := range invalid {
+		err := Validate(tpl)
+		if err == nil {
+			t.Errorf("Validate(%q) expected an error", tpl)
+			continue
+		}
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate(%q) = %v, expected it to mention %q", tpl, err, want)
+		}
+	}
+}
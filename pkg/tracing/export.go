@@ -0,0 +1,200 @@
+package tracing
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+)
+
+// Exporter sends finished spans somewhere
+type Exporter interface {
+	Export(spans []*SpanData) error
+	Shutdown()
+}
+
+// processor decides when finished spans reach the exporter
+type processor interface {
+	onEnd(span *SpanData)
+	shutdown()
+}
+
+// syncProcessor exports every span as soon as it ends
+type syncProcessor struct {
+	exporter Exporter
+}
+
+func (p syncProcessor) onEnd(span *SpanData) {
+	p.exporter.Export([]*SpanData{span})
+}
+
+func (p syncProcessor) shutdown() {}
+
+const (
+	batchQueueSize = 2048
+	batchMaxSize   = 512
+	batchInterval  = 5 * time.Second
+)
+
+// batchProcessor queues spans and exports them in batches from its own
+// goroutine, so a slow collector never slows down a command. Spans are dropped
+// when the queue is full.
+type batchProcessor struct {
+	exporter Exporter
+	queue    chan *SpanData
+	done     chan struct{}
+	stopped  chan struct{}
+	once     sync.Once
+}
+
+func newBatchProcessor(exporter Exporter) *batchProcessor {
+	p := &batchProcessor{
+		exporter: exporter,
+		queue:    make(chan *SpanData, batchQueueSize),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+func (p *batchProcessor) onEnd(span *SpanData) {
+	select {
+	case p.queue <- span:
+	default:
+	}
+}
+
+func (p *batchProcessor) run() {
+	defer close(p.stopped)
+	ticker := time.NewTicker(batchInterval)
+	defer ticker.Stop()
+
+	var batch []*SpanData
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := p.exporter.Export(batch); err != nil {
+			fmt.Fprintf(os.Stderr, "Tracing: error exporting %d spans: %v\n", len(batch), err)
+		}
+		batch = nil
+	}
+	for {
+		select {
+		case span := <-p.queue:
+			batch = append(batch, span)
+			if len(batch) >= batchMaxSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-p.done:
+			for {
+				select {
+				case span := <-p.queue:
+					batch = append(batch, span)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (p *batchProcessor) shutdown() {
+	p.once.Do(func() { close(p.done) })
+	select {
+	case <-p.stopped:
+	case <-time.After(10 * time.Second):
+	}
+}
+
+// StdoutExporter writes every span as a debug line of the logger, with the IDs,
+// the duration and the attributes as fields
+type StdoutExporter struct{}
+
+// Export logs the spans
+func (StdoutExporter) Export(spans []*SpanData) error {
+	for _, span := range spans {
+		fields := logger.Fields{
+			"trace_id":    span.TraceID.String(),
+			"span_id":     span.SpanID.String(),
+			"kind":        span.Kind.String(),
+			"duration_ms": float64(span.Duration().Microseconds()) / 1000,
+		}
+		if span.ParentID.IsValid() {
+			fields["parent_id"] = span.ParentID.String()
+		}
+		for _, attr := range span.Attributes {
+			fields[attr.Key] = attr.Value
+		}
+		if span.Error != "" {
+			fields["error"] = span.Error
+		}
+		logger.WithFields(fields).Debug(span.Name, "Tracing")
+	}
+	return nil
+}
+
+// Shutdown does nothing
+func (StdoutExporter) Shutdown() {}
+
+// MemoryExporter keeps the spans in memory. Tests use it to check what was
+// traced.
+type MemoryExporter struct {
+	mu    sync.Mutex
+	spans []*SpanData
+}
+
+// NewMemoryExporter creates an empty memory exporter
+func NewMemoryExporter() *MemoryExporter {
+	return &MemoryExporter{}
+}
+
+// Export stores the spans
+func (e *MemoryExporter) Export(spans []*SpanData) error {
+	e.mu.Lock()
+	e.spans = append(e.spans, spans...)
+	e.mu.Unlock()
+	return nil
+}
+
+// Spans returns the spans exported so far, in the order they ended
+func (e *MemoryExporter) Spans() []*SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]*SpanData(nil), e.spans...)
+}
+
+// Find returns the first exported span with the given name, or nil
+func (e *MemoryExporter) Find(name string) *SpanData {
+	for _, span := range e.Spans() {
+		if span.Name == name {
+			return span
+		}
+	}
+	return nil
+}
+
+// Reset forgets the stored spans
+func (e *MemoryExporter) Reset() {
+	e.mu.Lock()
+	e.spans = nil
+	e.mu.Unlock()
+}
+
+// Shutdown does nothing
+func (e *MemoryExporter) Shutdown() {}
+
+// UseMemory installs a tracer that records every span synchronously in a new
+// memory exporter and returns the exporter and a function that restores the
+// previous tracer
+func UseMemory() (*MemoryExporter, func()) {
+	exporter := NewMemoryExporter()
+	previous := SetTracer(NewTracer(exporter, Options{SampleRatio: 1, Sync: true}))
+	return exporter, func() { SetTracer(previous) }
+}
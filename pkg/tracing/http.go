@@ -0,0 +1,76 @@
+package tracing
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// Transport traces the requests made with the context of a traced operation and
+// sends the W3C traceparent header along, so services that also trace join the
+// same trace
+type Transport struct {
+	Base http.RoundTripper
+}
+
+// NewTransport wraps base, or http.DefaultTransport when nil
+func NewTransport(base http.RoundTripper) *Transport {
+	return &Transport{Base: base}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	// Don't trace twice when the default transport is already instrumented
+	if inner, ok := base.(*Transport); ok && inner != t {
+		base = inner.Base
+	}
+	if base == nil || base == t {
+		base = defaultTransport
+	}
+
+	_, span := StartChild(req.Context(), "HTTP "+req.Method+" "+req.URL.Host, KindClient,
+		String("http.method", req.Method),
+		String("http.host", req.URL.Host),
+		String("http.path", req.URL.Path),
+	)
+	if span == nil {
+		return base.RoundTrip(req)
+	}
+	defer span.End()
+
+	// RoundTrippers must not change the caller's request
+	req = req.Clone(req.Context())
+	req.Header.Set("traceparent", traceparent(span))
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	span.SetAttributes(Int("http.status_code", int64(resp.StatusCode)))
+	if resp.StatusCode >= 500 {
+		span.RecordError(httpError(resp.StatusCode))
+	}
+	return resp, nil
+}
+
+type httpError int
+
+func (e httpError) Error() string {
+	return "HTTP " + strconv.Itoa(int(e)) + " " + http.StatusText(int(e))
+}
+
+// defaultTransport is http.DefaultTransport before it was instrumented
+var defaultTransport = http.DefaultTransport
+
+// InstrumentDefaultTransport makes every client that uses http.DefaultTransport,
+// discordgo's included, trace its requests
+func InstrumentDefaultTransport() {
+	if _, done := http.DefaultTransport.(*Transport); done {
+		return
+	}
+	http.DefaultTransport = NewTransport(http.DefaultTransport)
+}
@@ -0,0 +1,57 @@
+package tracing
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Exporter names accepted by Init
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Config selects the exporter of the global tracer
+type Config struct {
+	Exporter    string // none, stdout or otlp
+	Endpoint    string // Collector URL for otlp
+	Headers     string // Extra otlp headers written as "key=value,key2=value2"
+	ServiceName string
+	SampleRatio float64 // Share of commands traced, from 0 to 1
+}
+
+// Init installs the global tracer described by cfg. With the none exporter
+// tracing stays off and nothing is recorded.
+func Init(cfg Config) error {
+	var exporter Exporter
+	switch strings.ToLower(strings.TrimSpace(cfg.Exporter)) {
+	case "", ExporterNone:
+		SetTracer(nil)
+		return nil
+	case ExporterStdout:
+		exporter = StdoutExporter{}
+	case ExporterOTLP:
+		if cfg.Endpoint == "" {
+			return fmt.Errorf("otlp exporter needs an endpoint")
+		}
+		exporter = NewOTLPExporter(cfg.Endpoint, cfg.ServiceName, parseHeaders(cfg.Headers))
+	default:
+		return fmt.Errorf("unknown tracing exporter %q", cfg.Exporter)
+	}
+
+	InstrumentDefaultTransport()
+	SetTracer(NewTracer(exporter, Options{SampleRatio: cfg.SampleRatio}))
+	return nil
+}
+
+func parseHeaders(spec string) map[string]string {
+	headers := make(map[string]string)
+	for _, part := range strings.Split(spec, ",") {
+		key, value, ok := strings.Cut(part, "=")
+		if ok && strings.TrimSpace(key) != "" {
+			headers[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return headers
+}
@@ -0,0 +1,358 @@
+// Package tracing records spans of the work done for a command: the command
+// itself, the database operations, the Discord REST calls and every other
+// outgoing HTTP request. Spans follow the OpenTelemetry model (trace and span
+// IDs, parent links, attributes and status) so they can be sent to any OTLP
+// collector, printed to the logs or kept in memory for tests.
+//
+// The current span travels in a context.Context. Start opens a span that may be
+// the root of a trace; StartChild only records something when the context
+// already carries a span, so background work like XP gains does not flood the
+// exporter with single-span traces.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TraceID identifies a whole trace
+type TraceID [16]byte
+
+// SpanID identifies a span inside a trace
+type SpanID [8]byte
+
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+func (s SpanID) String() string  { return hex.EncodeToString(s[:]) }
+
+// IsValid reports whether the ID is set
+func (t TraceID) IsValid() bool { return t != TraceID{} }
+
+// IsValid reports whether the ID is set
+func (s SpanID) IsValid() bool { return s != SpanID{} }
+
+// Kind says which side of a call a span is on
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindServer
+	KindClient
+)
+
+// String returns the name of the kind
+func (k Kind) String() string {
+	switch k {
+	case KindServer:
+		return "server"
+	case KindClient:
+		return "client"
+	default:
+		return "internal"
+	}
+}
+
+// Attr is a key/value pair attached to a span
+type Attr struct {
+	Key   string
+	Value interface{}
+}
+
+// String creates a text attribute
+func String(key, value string) Attr { return Attr{key, value} }
+
+// Int creates a numeric attribute
+func Int(key string, value int64) Attr { return Attr{key, value} }
+
+// Bool creates a boolean attribute
+func Bool(key string, value bool) Attr { return Attr{key, value} }
+
+// SpanData is a finished span as exporters receive it
+type SpanData struct {
+	Name       string
+	TraceID    TraceID
+	SpanID     SpanID
+	ParentID   SpanID
+	Kind       Kind
+	Start      time.Time
+	End        time.Time
+	Attributes []Attr
+	Error      string // Empty when the span succeeded
+}
+
+// Duration returns how long the span took
+func (d *SpanData) Duration() time.Duration {
+	return d.End.Sub(d.Start)
+}
+
+// Span is an operation being timed. A nil *Span is valid and does nothing, so
+// callers never need to check whether tracing is enabled.
+type Span struct {
+	mu     sync.Mutex
+	data   SpanData
+	ended  bool
+	tracer *Tracer
+}
+
+// SetAttributes adds attributes to the span
+func (s *Span) SetAttributes(attrs ...Attr) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.data.Attributes = append(s.data.Attributes, attrs...)
+	s.mu.Unlock()
+}
+
+// RecordError marks the span as failed. A nil error does nothing.
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	s.data.Error = err.Error()
+	s.mu.Unlock()
+}
+
+// End finishes the span and hands it to the exporter. Only the first call counts.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	data.Attributes = append([]Attr(nil), s.data.Attributes...)
+	s.mu.Unlock()
+
+	s.tracer.export(&data)
+}
+
+// TraceID returns the trace of the span, or a zero ID for a nil span
+func (s *Span) TraceID() TraceID {
+	if s == nil {
+		return TraceID{}
+	}
+	return s.data.TraceID
+}
+
+// SpanID returns the ID of the span, or a zero ID for a nil span
+func (s *Span) SpanID() SpanID {
+	if s == nil {
+		return SpanID{}
+	}
+	return s.data.SpanID
+}
+
+type spanKey struct{}
+
+// SpanFromContext returns the span carried by ctx, or nil
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// ContextWithSpan returns a copy of ctx carrying span
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// Start opens a span as a child of the span in ctx, or as the root of a new
+// trace when there is none. The returned context carries the new span.
+func Start(ctx context.Context, name string, kind Kind, attrs ...Attr) (context.Context, *Span) {
+	return current().start(ctx, name, kind, attrs, false)
+}
+
+// StartChild opens a span only when ctx already carries one. Otherwise it
+// returns ctx unchanged and a nil span.
+func StartChild(ctx context.Context, name string, kind Kind, attrs ...Attr) (context.Context, *Span) {
+	return current().start(ctx, name, kind, attrs, true)
+}
+
+// Tracer creates spans and sends the finished ones to an exporter
+type Tracer struct {
+	exporter    Exporter
+	processor   processor
+	sampleRatio float64
+}
+
+// Options tune a tracer
+type Options struct {
+	// SampleRatio is the share of new traces recorded, from 0 to 1. Children
+	// follow the decision of their root.
+	SampleRatio float64
+	// Sync exports every span when it ends instead of in batches. Tests use it
+	// with the memory exporter.
+	Sync bool
+}
+
+// NewTracer creates a tracer that sends spans to exporter
+func NewTracer(exporter Exporter, opts Options) *Tracer {
+	t := &Tracer{exporter: exporter, sampleRatio: opts.SampleRatio}
+	if opts.Sync {
+		t.processor = syncProcessor{exporter}
+	} else {
+		t.processor = newBatchProcessor(exporter)
+	}
+	return t
+}
+
+func (t *Tracer) start(ctx context.Context, name string, kind Kind, attrs []Attr, childOnly bool) (context.Context, *Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if t == nil {
+		return ctx, nil
+	}
+
+	parent := SpanFromContext(ctx)
+	remote, hasRemote := remoteParent(ctx)
+	if parent == nil && !hasRemote && (childOnly || !t.sample()) {
+		return ctx, nil
+	}
+
+	span := &Span{tracer: t, data: SpanData{
+		Name:       name,
+		SpanID:     newSpanID(),
+		Kind:       kind,
+		Start:      time.Now(),
+		Attributes: append([]Attr(nil), attrs...),
+	}}
+	switch {
+	case parent != nil:
+		span.data.TraceID = parent.data.TraceID
+		span.data.ParentID = parent.data.SpanID
+	case hasRemote:
+		span.data.TraceID = remote.traceID
+		span.data.ParentID = remote.spanID
+	default:
+		span.data.TraceID = newTraceID()
+	}
+	return ContextWithSpan(ctx, span), span
+}
+
+func (t *Tracer) sample() bool {
+	switch {
+	case t.sampleRatio >= 1:
+		return true
+	case t.sampleRatio <= 0:
+		return false
+	}
+	var b [8]byte
+	rand.Read(b[:])
+	return float64(binary.BigEndian.Uint64(b[:])>>11)/float64(1<<53) < t.sampleRatio
+}
+
+func (t *Tracer) export(data *SpanData) {
+	if t != nil && t.processor != nil {
+		t.processor.onEnd(data)
+	}
+}
+
+// Shutdown exports the spans still queued and closes the exporter
+func (t *Tracer) Shutdown() {
+	if t == nil || t.processor == nil {
+		return
+	}
+	t.processor.shutdown()
+	t.exporter.Shutdown()
+}
+
+var (
+	global   *Tracer
+	globalMu sync.RWMutex
+)
+
+func current() *Tracer {
+	globalMu.RLock()
+	defer globalMu.RUnlock()
+	return global
+}
+
+// SetTracer replaces the global tracer and returns the previous one. A nil
+// tracer turns tracing off.
+func SetTracer(t *Tracer) *Tracer {
+	globalMu.Lock()
+	defer globalMu.Unlock()
+	previous := global
+	global = t
+	return previous
+}
+
+// Enabled reports whether spans are being recorded
+func Enabled() bool {
+	return current() != nil
+}
+
+// Shutdown flushes and stops the global tracer
+func Shutdown() {
+	SetTracer(nil).Shutdown()
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+	return id
+}
+
+// remote is a parent received from another service in a traceparent header
+type remote struct {
+	traceID TraceID
+	spanID  SpanID
+}
+
+type remoteKey struct{}
+
+func remoteParent(ctx context.Context) (remote, bool) {
+	r, ok := ctx.Value(remoteKey{}).(remote)
+	return r, ok
+}
+
+// traceparent formats the W3C trace context header of a span
+func traceparent(span *Span) string {
+	return fmt.Sprintf("00-%s-%s-01", span.data.TraceID, span.data.SpanID)
+}
+
+// ContextWithTraceparent returns a context whose next span continues the trace
+// of a W3C traceparent header. Invalid headers return ctx unchanged.
+func ContextWithTraceparent(ctx context.Context, header string) context.Context {
+	var r remote
+	if len(header) != 55 || header[2] != '-' || header[35] != '-' || header[52] != '-' {
+		return ctx
+	}
+	if _, err := hex.Decode(r.traceID[:], []byte(header[3:35])); err != nil {
+		return ctx
+	}
+	if _, err := hex.Decode(r.spanID[:], []byte(header[36:52])); err != nil {
+		return ctx
+	}
+	if !r.traceID.IsValid() || !r.spanID.IsValid() {
+		return ctx
+	}
+	return context.WithValue(ctx, remoteKey{}, r)
+}
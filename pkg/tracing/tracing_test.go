@@ -0,0 +1,195 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSpansShareTheTrace(t *testing.T) {
+	exporter, restore := UseMemory()
+	defer restore()
+
+	ctx, root := Start(context.Background(), "command ping", KindServer, String("command.name", "ping"))
+	_, child := StartChild(ctx, "mongodb find guilds", KindClient)
+	child.RecordError(errors.New("timeout"))
+	child.End()
+	root.End()
+	root.End() // Only the first End counts
+
+	spans := exporter.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+	db, cmd := spans[0], spans[1]
+	if db.TraceID != cmd.TraceID {
+		t.Error("Expected the child to share the trace of the root")
+	}
+	if db.ParentID != cmd.SpanID || cmd.ParentID.IsValid() {
+		t.Errorf("Unexpected parents: child %s, root %s", db.ParentID, cmd.ParentID)
+	}
+	if db.Error != "timeout" || cmd.Error != "" {
+		t.Errorf("Unexpected errors: child %q, root %q", db.Error, cmd.Error)
+	}
+	if len(cmd.Attributes) != 1 || cmd.Attributes[0] != String("command.name", "ping") {
+		t.Errorf("Unexpected attributes %v", cmd.Attributes)
+	}
+}
+
+func TestStartChildNeedsAParent(t *testing.T) {
+	exporter, restore := UseMemory()
+	defer restore()
+
+	ctx, span := StartChild(context.Background(), "mongodb find users", KindClient)
+	if span != nil || SpanFromContext(ctx) != nil {
+		t.Fatal("Expected no span without a parent")
+	}
+	// Nil spans are safe to use
+	span.SetAttributes(Bool("cache.hit", true))
+	span.RecordError(errors.New("ignored"))
+	span.End()
+
+	if n := len(exporter.Spans()); n != 0 {
+		t.Errorf("Expected nothing exported, got %d spans", n)
+	}
+}
+
+func TestDisabledTracer(t *testing.T) {
+	previous := SetTracer(nil)
+	defer SetTracer(previous)
+
+	if _, span := Start(context.Background(), "command ping", KindServer); span != nil {
+		t.Error("Expected no span with tracing off")
+	}
+}
+
+func TestTraceparent(t *testing.T) {
+	exporter, restore := UseMemory()
+	defer restore()
+
+	ctx := ContextWithTraceparent(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, span := Start(ctx, "mqtt request", KindServer)
+	span.End()
+
+	got := exporter.Find("mqtt request")
+	if got == nil {
+		t.Fatal("Expected the span to be exported")
+	}
+	if got.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || got.ParentID.String() != "00f067aa0ba902b7" {
+		t.Errorf("Expected the remote parent, got trace %s parent %s", got.TraceID, got.ParentID)
+	}
+
+	if ContextWithTraceparent(ctx, "not-a-header") != ctx {
+		t.Error("Expected invalid headers to be ignored")
+	}
+}
+
+func TestTransport(t *testing.T) {
+	exporter, restore := UseMemory()
+	defer restore()
+
+	var header string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: NewTransport(server.Client().Transport)}
+
+	// Without a parent the request goes out untouched
+	resp, err := client.Get(server.URL + "/plain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if header != "" || len(exporter.Spans()) != 0 {
+		t.Fatalf("Expected no tracing without a parent, got header %q", header)
+	}
+
+	ctx, root := Start(context.Background(), "command screenshot", KindServer)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/shot", nil)
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	root.End()
+
+	if req.Header.Get("traceparent") != "" {
+		t.Error("Expected the caller's request to stay unchanged")
+	}
+	spans := exporter.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+	httpSpan := spans[0]
+	if want := "00-" + httpSpan.TraceID.String() + "-" + httpSpan.SpanID.String() + "-01"; header != want {
+		t.Errorf("Expected traceparent %q, got %q", want, header)
+	}
+	if httpSpan.ParentID != root.SpanID() || httpSpan.Kind != KindClient {
+		t.Error("Expected a client span under the command")
+	}
+	if httpSpan.Error == "" {
+		t.Error("Expected a 502 to mark the span as failed")
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var body map[string]interface{}
+	var path, apiKey string
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, apiKey = r.URL.Path, r.Header.Get("api-key")
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &body)
+	}))
+	defer collector.Close()
+
+	exporter := NewOTLPExporter(collector.URL+"/", "pancybot-test", parseHeaders("api-key = abc"))
+	tracer := NewTracer(exporter, Options{SampleRatio: 1})
+	previous := SetTracer(tracer)
+	defer SetTracer(previous)
+
+	ctx, root := Start(context.Background(), "command play", KindServer, Int("tracks", 3))
+	_, child := StartChild(ctx, "lavalink search", KindClient)
+	child.RecordError(errors.New("no matches"))
+	child.End()
+	root.End()
+	tracer.Shutdown()
+
+	if path != "/v1/traces" || apiKey != "abc" {
+		t.Fatalf("Unexpected request to %q with key %q", path, apiKey)
+	}
+	encoded, _ := json.Marshal(body)
+	for _, want := range []string{
+		`"stringValue":"pancybot-test"`,
+		`"name":"command play"`,
+		`"kind":2`,
+		`"intValue":"3"`,
+		`"status":{"code":2,"message":"no matches"}`,
+		`"parentSpanId":"` + root.SpanID().String() + `"`,
+	} {
+		if !strings.Contains(string(encoded), want) {
+			t.Errorf("Expected the payload to contain %s, got %s", want, encoded)
+		}
+	}
+}
+
+func TestInit(t *testing.T) {
+	previous := current()
+	defer SetTracer(previous)
+
+	if err := Init(Config{Exporter: "otlp"}); err == nil {
+		t.Error("Expected otlp without an endpoint to fail")
+	}
+	if err := Init(Config{Exporter: "jaeger"}); err == nil {
+		t.Error("Expected an unknown exporter to fail")
+	}
+	if err := Init(Config{Exporter: "none"}); err != nil || Enabled() {
+		t.Errorf("Expected tracing off, got %v", err)
+	}
+}
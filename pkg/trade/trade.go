@@ -0,0 +1,302 @@
+// Package trade keeps the pending player to player trades. A trade lives in memory
+// for a few minutes: both users build their offer, confirm it, and the exchange is
+// executed as a single group of ledger legs.
+package trade
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/bwmarrin/discordgo"
+	"github.com/google/uuid"
+)
+
+// Timeout is how long a trade window stays open
+const Timeout = 5 * time.Minute
+
+var (
+	ErrNotFound       = errors.New("trade not found or expired")
+	ErrNotParticipant = errors.New("user is not part of this trade")
+	ErrAlreadyTrading = errors.New("user already has an open trade")
+	ErrEmptyTrade     = errors.New("trade has nothing to exchange")
+	ErrUnknownItem    = errors.New("unknown item")
+	ErrInvalidOffer   = errors.New("invalid offer")
+)
+
+// Offer is what one side gives
+type Offer struct {
+	Items map[string]int // ItemID -> quantity
+	Coins int64          // Local currency
+	Stars int64
+}
+
+// IsEmpty reports whether the offer gives nothing
+func (o Offer) IsEmpty() bool {
+	return len(o.Items) == 0 && o.Coins == 0 && o.Stars == 0
+}
+
+// Trade is a pending exchange between two users of a guild
+type Trade struct {
+	ID        string
+	GuildID   string
+	ChannelID string
+	MessageID string
+	Users     [2]string // Initiator, partner
+	Names     [2]string
+	Offers    [2]Offer
+	Confirmed [2]bool
+	ExpiresAt time.Time
+}
+
+// side returns the index of a user in the trade, or -1
+func (t *Trade) side(userID string) int {
+	for i, u := range t.Users {
+		if u == userID {
+			return i
+		}
+	}
+	return -1
+}
+
+var (
+	trades = make(map[string]*Trade)
+	mu     sync.Mutex
+)
+
+// Start opens a trade between two users. A user can only have one open trade.
+func Start(guildID, channelID string, initiator, partner *discordgo.User) (*Trade, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	now := time.Now()
+	for id, t := range trades {
+		if now.After(t.ExpiresAt) {
+			delete(trades, id)
+			continue
+		}
+		if t.side(initiator.ID) >= 0 || t.side(partner.ID) >= 0 {
+			return nil, ErrAlreadyTrading
+		}
+	}
+
+	t := &Trade{
+		ID:        uuid.New().String()[:8],
+		GuildID:   guildID,
+		ChannelID: channelID,
+		Users:     [2]string{initiator.ID, partner.ID},
+		Names:     [2]string{initiator.Username, partner.Username},
+		ExpiresAt: now.Add(Timeout),
+	}
+	trades[t.ID] = t
+	return t, nil
+}
+
+// Get returns a snapshot of an open trade
+func Get(tradeID string) (Trade, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	t, err := open(tradeID)
+	if err != nil {
+		return Trade{}, err
+	}
+	return *t, nil
+}
+
+// SetMessage remembers the message that shows the trade window
+func SetMessage(tradeID, messageID string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if t, ok := trades[tradeID]; ok {
+		t.MessageID = messageID
+	}
+}
+
+// SetOffer replaces the offer of a user. Any change clears both confirmations.
+func SetOffer(tradeID, userID string, offer Offer) (Trade, error) {
+	if offer.Coins < 0 || offer.Stars < 0 {
+		return Trade{}, ErrInvalidOffer
+	}
+	for _, qty := range offer.Items {
+		if qty <= 0 {
+			return Trade{}, ErrInvalidOffer
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	t, err := open(tradeID)
+	if err != nil {
+		return Trade{}, err
+	}
+	i := t.side(userID)
+	if i < 0 {
+		return Trade{}, ErrNotParticipant
+	}
+	t.Offers[i] = offer
+	t.Confirmed = [2]bool{}
+	return *t, nil
+}
+
+// Confirm marks the offer of a user as accepted. When both sides confirmed the
+// trade is removed from the pending list and returned with ready set; the caller
+// must then Execute it.
+func Confirm(tradeID, userID string) (Trade, bool, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	t, err := open(tradeID)
+	if err != nil {
+		return Trade{}, false, err
+	}
+	i := t.side(userID)
+	if i < 0 {
+		return Trade{}, false, ErrNotParticipant
+	}
+	if t.Offers[0].IsEmpty() && t.Offers[1].IsEmpty() {
+		return Trade{}, false, ErrEmptyTrade
+	}
+	t.Confirmed[i] = true
+	if t.Confirmed[0] && t.Confirmed[1] {
+		delete(trades, tradeID)
+		return *t, true, nil
+	}
+	return *t, false, nil
+}
+
+// Cancel closes a trade; only its participants can cancel it
+func Cancel(tradeID, userID string) (Trade, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	t, err := open(tradeID)
+	if err != nil {
+		return Trade{}, err
+	}
+	if t.side(userID) < 0 {
+		return Trade{}, ErrNotParticipant
+	}
+	delete(trades, tradeID)
+	return *t, nil
+}
+
+// Expire removes a trade whose window timed out. It reports false if the trade was
+// already closed (completed, cancelled or expired).
+func Expire(tradeID string) (Trade, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	t, ok := trades[tradeID]
+	if !ok {
+		return Trade{}, false
+	}
+	delete(trades, tradeID)
+	return *t, true
+}
+
+// open returns a pending trade; mu must be held
+func open(tradeID string) (*Trade, error) {
+	t, ok := trades[tradeID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if time.Now().After(t.ExpiresAt) {
+		delete(trades, tradeID)
+		return nil, ErrNotFound
+	}
+	return t, nil
+}
+
+// ParseItems reads an item list like "sword x2, potion" into quantities
+func ParseItems(text string, catalog map[string]models.Item) (map[string]int, error) {
+	items := make(map[string]int)
+	for _, part := range strings.Split(text, ",") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+
+		qty := 1
+		if len(fields) > 1 {
+			n, err := strconv.Atoi(strings.TrimPrefix(strings.ToLower(fields[1]), "x"))
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidOffer, strings.TrimSpace(part))
+			}
+			qty = n
+		}
+
+		if _, ok := catalog[fields[0]]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownItem, fields[0])
+		}
+		items[fields[0]] += qty
+	}
+	return items, nil
+}
+
+// Execute exchanges both offers. Each profile is updated with a guarded atomic
+// change and the whole exchange is reverted if one side no longer has what it
+// offered.
+func Execute(t Trade, catalog map[string]models.Item) error {
+	var legs []database.LedgerLeg
+	for i, userID := range t.Users {
+		other := t.Users[1-i]
+		info := database.TxInfo{Type: models.TransactionTrade, Command: "trade", Counterparty: other, Note: fmt.Sprintf("Intercambio %s", t.ID)}
+
+		local := database.BalanceChange{Wallet: t.Offers[1-i].Coins - t.Offers[i].Coins, Items: map[string]int{}}
+		stars := database.BalanceChange{Wallet: t.Offers[1-i].Stars - t.Offers[i].Stars, Items: map[string]int{}}
+		for side, sign := range map[int]int{i: -1, 1 - i: 1} {
+			for itemID, qty := range t.Offers[side].Items {
+				item, ok := catalog[itemID]
+				if !ok {
+					return fmt.Errorf("%w: %s", ErrUnknownItem, itemID)
+				}
+				if item.IsGlobal {
+					stars.Items[itemID] += sign * qty
+				} else {
+					local.Items[itemID] += sign * qty
+				}
+			}
+		}
+
+		if !local.IsZero() {
+			legs = append(legs, database.LedgerLeg{Currency: models.CurrencyLocal, GuildID: t.GuildID, UserID: userID, Change: local, Info: info})
+		}
+		if !stars.IsZero() {
+			legs = append(legs, database.LedgerLeg{Currency: models.CurrencyStars, UserID: userID, Change: stars, Info: info})
+		}
+	}
+
+	if len(legs) == 0 {
+		return ErrEmptyTrade
+	}
+	return database.ApplyLegs(legs)
+}
+
+// DescribeOffer renders an offer for the trade window
+func DescribeOffer(o Offer, catalog map[string]models.Item) string {
+	if o.IsEmpty() {
+		return "*Nada todavía*"
+	}
+
+	var lines []string
+	if o.Coins > 0 {
+		lines = append(lines, fmt.Sprintf("💵 %d monedas", o.Coins))
+	}
+	if o.Stars > 0 {
+		lines = append(lines, fmt.Sprintf("🌟 %d estrellas", o.Stars))
+	}
+
+	ids := make([]string, 0, len(o.Items))
+	for id := range o.Items {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		item := catalog[id]
+		lines = append(lines, fmt.Sprintf("%s **%s** x%d", item.Emoji, item.Name, o.Items[id]))
+	}
+	return strings.Join(lines, "\n")
+}
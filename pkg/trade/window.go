@@ -0,0 +1,157 @@
+package trade
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/bwmarrin/discordgo"
+)
+
+// Custom ID prefixes of the trade window components, followed by the trade ID
+const (
+	OfferButtonPrefix   = "trade_offer_"
+	ConfirmButtonPrefix = "trade_confirm_"
+	CancelButtonPrefix  = "trade_cancel_"
+	OfferModalPrefix    = "trade_modal_"
+
+	ItemsInputID = "trade_items"
+	CoinsInputID = "trade_coins"
+	StarsInputID = "trade_stars"
+)
+
+// Catalog returns the items that can be traded in a guild, by ID
+func Catalog(guildID string) (map[string]models.Item, error) {
+	items, err := database.GetItems(guildID)
+	if err != nil {
+		return nil, err
+	}
+	catalog := make(map[string]models.Item, len(items))
+	for _, item := range items {
+		catalog[item.ID] = item
+	}
+	return catalog, nil
+}
+
+// Open starts a trade and posts its window in the channel. The window is closed
+// automatically when the trade times out.
+func Open(s *discordgo.Session, guildID, channelID string, initiator, partner *discordgo.User) (Trade, error) {
+	t, err := Start(guildID, channelID, initiator, partner)
+	if err != nil {
+		return Trade{}, err
+	}
+
+	catalog, err := Catalog(guildID)
+	if err != nil {
+		_, _ = Cancel(t.ID, initiator.ID)
+		return Trade{}, err
+	}
+
+	msg, err := s.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+		Content:    fmt.Sprintf("<@%s> <@%s>", initiator.ID, partner.ID),
+		Embeds:     []*discordgo.MessageEmbed{Embed(*t, catalog)},
+		Components: Components(t.ID, false),
+	})
+	if err != nil {
+		_, _ = Cancel(t.ID, initiator.ID)
+		return Trade{}, err
+	}
+	SetMessage(t.ID, msg.ID)
+
+	time.AfterFunc(Timeout, func() {
+		expired, ok := Expire(t.ID)
+		if !ok {
+			return
+		}
+		Close(s, expired, "⌛ El intercambio expiró sin completarse.", discord.ColorWarning)
+	})
+
+	return *t, nil
+}
+
+// Close replaces the trade window with a final message and removes its buttons
+func Close(s *discordgo.Session, t Trade, description string, color int) {
+	if t.MessageID == "" {
+		return
+	}
+	embed := discord.NewEmbed().
+		SetTitle("🤝 Intercambio").
+		SetDescription(fmt.Sprintf("<@%s> ⇄ <@%s>\n\n%s", t.Users[0], t.Users[1], description)).
+		SetColor(color).
+		Build()
+	embeds := []*discordgo.MessageEmbed{embed}
+	components := []discordgo.MessageComponent{}
+	_, err := s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		ID:         t.MessageID,
+		Channel:    t.ChannelID,
+		Embeds:     &embeds,
+		Components: &components,
+	})
+	if err != nil {
+		logger.Debug(fmt.Sprintf("No se pudo cerrar la ventana del intercambio %s: %v", t.ID, err), "Trade")
+	}
+}
+
+// Embed renders the trade window
+func Embed(t Trade, catalog map[string]models.Item) *discordgo.MessageEmbed {
+	status := func(i int) string {
+		if t.Confirmed[i] {
+			return "✅"
+		}
+		return "⏳"
+	}
+
+	return discord.NewEmbed().
+		SetTitle("🤝 Intercambio").
+		SetDescription(fmt.Sprintf("Usad **Editar oferta** para poner objetos, monedas o estrellas y **Confirmar** cuando estéis de acuerdo. Cualquier cambio anula las confirmaciones.\nExpira <t:%d:R>.", t.ExpiresAt.Unix())).
+		SetColor(discord.ColorInfo).
+		AddField(fmt.Sprintf("%s Oferta de %s", status(0), t.Names[0]), DescribeOffer(t.Offers[0], catalog), true).
+		AddField(fmt.Sprintf("%s Oferta de %s", status(1), t.Names[1]), DescribeOffer(t.Offers[1], catalog), true).
+		SetFooter("ID: "+t.ID, "").
+		Build()
+}
+
+// Components returns the buttons of the trade window
+func Components(tradeID string, disabled bool) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{Label: "Editar oferta", Emoji: &discordgo.ComponentEmoji{Name: "✏️"}, Style: discordgo.PrimaryButton, CustomID: OfferButtonPrefix + tradeID, Disabled: disabled},
+			discordgo.Button{Label: "Confirmar", Emoji: &discordgo.ComponentEmoji{Name: "✅"}, Style: discordgo.SuccessButton, CustomID: ConfirmButtonPrefix + tradeID, Disabled: disabled},
+			discordgo.Button{Label: "Cancelar", Emoji: &discordgo.ComponentEmoji{Name: "✖️"}, Style: discordgo.DangerButton, CustomID: CancelButtonPrefix + tradeID, Disabled: disabled},
+		}},
+	}
+}
+
+// OfferModal is the form used to edit an offer, prefilled with the current one
+func OfferModal(tradeID string, current Offer) *discordgo.InteractionResponseData {
+	var items []string
+	for id, qty := range current.Items {
+		items = append(items, fmt.Sprintf("%s x%d", id, qty))
+	}
+	value := func(n int64) string {
+		if n == 0 {
+			return ""
+		}
+		return fmt.Sprint(n)
+	}
+
+	return &discordgo.InteractionResponseData{
+		CustomID: OfferModalPrefix + tradeID,
+		Title:    "Tu oferta",
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+				discordgo.TextInput{CustomID: ItemsInputID, Label: "Objetos (id x cantidad, separados por comas)", Style: discordgo.TextInputParagraph, Required: false, Value: strings.Join(items, ", "), Placeholder: "espada x1, pocion x3"},
+			}},
+			discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+				discordgo.TextInput{CustomID: CoinsInputID, Label: "Monedas locales", Style: discordgo.TextInputShort, Required: false, Value: value(current.Coins)},
+			}},
+			discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+				discordgo.TextInput{CustomID: StarsInputID, Label: "Estrellas", Style: discordgo.TextInputShort, Required: false, Value: value(current.Stars)},
+			}},
+		},
+	}
+}
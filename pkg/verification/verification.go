@@ -0,0 +1,283 @@
+// Package verification implements the member verification challenges
+// (button, image captcha, math question and web) and their outcome logging.
+package verification
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PancyStudios/PancyBotGo/pkg/database"
+	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/imaging"
+	"github.com/PancyStudios/PancyBotGo/pkg/logger"
+	"github.com/PancyStudios/PancyBotGo/pkg/models"
+	"github.com/bwmarrin/discordgo"
+)
+
+// Verification types stored in VerificationConfig.Type
+const (
+	TypeButton  = "button"
+	TypeCaptcha = "captcha"
+	TypeMath    = "math"
+	TypeWeb     = "web"
+)
+
+// ButtonCustomID is the custom ID of the button shown in the verification panel
+const ButtonCustomID = "btn_verify_user"
+
+// Defaults used when the guild configuration leaves a value empty
+const (
+	DefaultMaxAttempts = 3
+	captchaLength      = 6
+	challengeTTL       = 5 * time.Minute
+)
+
+// Result is the outcome of checking an answer against the active challenge
+type Result int
+
+const (
+	ResultPassed Result = iota
+	ResultWrong
+	ResultExhausted
+	ResultNoChallenge
+)
+
+// Outcome describes what happened to a member during verification (used for logging)
+type Outcome string
+
+const (
+	OutcomePassed    Outcome = "passed"
+	OutcomeFailed    Outcome = "failed"
+	OutcomeExhausted Outcome = "attempts_exhausted"
+	OutcomeTooYoung  Outcome = "account_too_young"
+	OutcomeTimeout   Outcome = "timeout"
+)
+
+// challenge holds the expected answer for a member; attempts survive new challenges
+type challenge struct {
+	answer    string
+	attempts  int
+	expiresAt time.Time
+}
+
+var (
+	challenges   = make(map[string]*challenge)
+	challengesMu sync.Mutex
+)
+
+func challengeKey(guildID, userID string) string {
+	return guildID + "_" + userID
+}
+
+// IsValidType reports whether t is a supported verification type
+func IsValidType(t string) bool {
+	switch t {
+	case TypeButton, TypeCaptcha, TypeMath, TypeWeb:
+		return true
+	}
+	return false
+}
+
+// MaxAttempts returns the configured attempt limit or the default one
+func MaxAttempts(cfg models.VerificationConfig) int {
+	if cfg.MaxAttempts > 0 {
+		return cfg.MaxAttempts
+	}
+	return DefaultMaxAttempts
+}
+
+// setChallenge stores a new expected answer, keeping the attempts already used
+func setChallenge(guildID, userID, answer string) {
+	challengesMu.Lock()
+	defer challengesMu.Unlock()
+
+	key := challengeKey(guildID, userID)
+	c, exists := challenges[key]
+	if !exists || time.Now().After(c.expiresAt.Add(challengeTTL)) {
+		c = &challenge{}
+		challenges[key] = c
+	}
+	c.answer = answer
+	c.expiresAt = time.Now().Add(challengeTTL)
+}
+
+// clearChallenge forgets any challenge and attempt count for a member
+func clearChallenge(guildID, userID string) {
+	challengesMu.Lock()
+	defer challengesMu.Unlock()
+	delete(challenges, challengeKey(guildID, userID))
+}
+
+// NewCaptchaChallenge creates an image captcha for the member and returns the PNG
+func NewCaptchaChallenge(guildID, userID string) ([]byte, error) {
+	code := imaging.NewCaptchaCode(captchaLength)
+	png, err := imaging.RenderCaptcha(code)
+	if err != nil {
+		return nil, err
+	}
+	setChallenge(guildID, userID, code)
+	return png, nil
+}
+
+// NewMathChallenge creates a math question for the member and returns its text
+func NewMathChallenge(guildID, userID string) string {
+	a, b := rand.Intn(20)+1, rand.Intn(20)+1
+	var question string
+	var answer int
+
+	switch rand.Intn(3) {
+	case 0:
+		question, answer = fmt.Sprintf("¿Cuánto es %d + %d?", a, b), a+b
+	case 1:
+		if a < b {
+			a, b = b, a
+		}
+		question, answer = fmt.Sprintf("¿Cuánto es %d - %d?", a, b), a-b
+	default:
+		a, b = a%10+1, b%10+1
+		question, answer = fmt.Sprintf("¿Cuánto es %d × %d?", a, b), a*b
+	}
+
+	setChallenge(guildID, userID, strconv.Itoa(answer))
+	return question
+}
+
+// CheckAnswer validates an answer against the active challenge of the member.
+// remaining is the number of attempts left after this one.
+func CheckAnswer(guildID, userID, answer string, maxAttempts int) (result Result, remaining int) {
+	challengesMu.Lock()
+	defer challengesMu.Unlock()
+
+	key := challengeKey(guildID, userID)
+	c, exists := challenges[key]
+	if !exists || c.answer == "" || time.Now().After(c.expiresAt) {
+		remaining = maxAttempts
+		if exists {
+			remaining -= c.attempts
+		}
+		return ResultNoChallenge, remaining
+	}
+
+	if strings.EqualFold(strings.TrimSpace(answer), c.answer) {
+		delete(challenges, key)
+		return ResultPassed, maxAttempts - c.attempts
+	}
+
+	c.attempts++
+	// Each wrong answer invalidates the current challenge so it cannot be brute forced
+	c.answer = ""
+	remaining = maxAttempts - c.attempts
+	if remaining <= 0 {
+		delete(challenges, key)
+		return ResultExhausted, 0
+	}
+	return ResultWrong, remaining
+}
+
+// PanelDescription returns the panel text explaining the challenge of the given type
+func PanelDescription(verificationType string) string {
+	intro := "Para acceder al resto del servidor y canales, debes verificarte.\n\n"
+	switch verificationType {
+	case TypeCaptcha:
+		return intro + "Haz clic en el botón de abajo y escribe el código que aparece en la imagen del captcha."
+	case TypeMath:
+		return intro + "Haz clic en el botón de abajo y responde a una sencilla pregunta matemática."
+	case TypeWeb:
+		return intro + "Haz clic en el botón de abajo para completar la verificación desde nuestra web."
+	default:
+		return intro + "Haz clic en el botón de abajo para confirmar que no eres un bot y aceptar las reglas del servidor."
+	}
+}
+
+// AccountAge returns how old a Discord account is based on its snowflake ID
+func AccountAge(userID string) time.Duration {
+	createdAt, err := discordgo.SnowflakeTimestamp(userID)
+	if err != nil {
+		return 0
+	}
+	return time.Since(createdAt)
+}
+
+// MeetsMinimumAge reports whether the account satisfies MinAccountAgeDays
+func MeetsMinimumAge(cfg models.VerificationConfig, userID string) bool {
+	if cfg.MinAccountAgeDays <= 0 {
+		return true
+	}
+	return AccountAge(userID) >= time.Duration(cfg.MinAccountAgeDays)*24*time.Hour
+}
+
+// RegisterJoin marks a new member as pending when the guild kicks unverified
+// members or verifies them on the web, which only accepts pending members
+func RegisterJoin(guildDoc *models.GuildDocument, userID string) {
+	cfg := guildDoc.Protection.Verification
+	if !cfg.Enable || cfg.Role == "" || (cfg.TimeoutMinutes <= 0 && cfg.Type != TypeWeb) {
+		return
+	}
+	timeout := time.Duration(max(cfg.TimeoutMinutes, 0)) * time.Minute
+	if err := database.AddPendingVerification(guildDoc.ID, userID, timeout); err != nil {
+		logger.Warn(fmt.Sprintf("No se pudo registrar la verificación pendiente de %s: %v", userID, err), "Verification")
+	}
+}
+
+// Grant gives the verification role to the member and logs the success
+func Grant(s *discordgo.Session, guildDoc *models.GuildDocument, userID, method string) error {
+	if err := s.GuildMemberRoleAdd(guildDoc.ID, userID, guildDoc.Protection.Verification.Role); err != nil {
+		return err
+	}
+
+	clearChallenge(guildDoc.ID, userID)
+	_ = database.RemovePendingVerification(guildDoc.ID, userID)
+	LogOutcome(s, guildDoc, userID, OutcomePassed, fmt.Sprintf("Método: %s", method))
+	return nil
+}
+
+// Kick removes a member that failed verification and logs the reason
+func Kick(s *discordgo.Session, guildDoc *models.GuildDocument, userID string, outcome Outcome, reason string) {
+	clearChallenge(guildDoc.ID, userID)
+	_ = database.RemovePendingVerification(guildDoc.ID, userID)
+
+	if err := s.GuildMemberDeleteWithReason(guildDoc.ID, userID, "Verificación: "+reason); err != nil {
+		logger.Warn(fmt.Sprintf("No se pudo expulsar a %s en %s: %v", userID, guildDoc.ID, err), "Verification")
+	}
+	LogOutcome(s, guildDoc, userID, outcome, reason)
+}
+
+// LogOutcome records a verification outcome in the bot logs and the guild logs channel
+func LogOutcome(s *discordgo.Session, guildDoc *models.GuildDocument, userID string, outcome Outcome, detail string) {
+	logger.Info(fmt.Sprintf("🔐 Verificación [%s] de %s en %s: %s", outcome, userID, guildDoc.ID, detail), "Verification")
+
+	channelID := guildDoc.Configuration.LogsChannel
+	if channelID == "" {
+		return
+	}
+
+	title, color := "🔐 Verificación", discord.ColorInfo
+	switch outcome {
+	case OutcomePassed:
+		title, color = "✅ Miembro verificado", discord.ColorSuccess
+	case OutcomeFailed:
+		title, color = "⚠️ Respuesta de verificación incorrecta", discord.ColorWarning
+	case OutcomeExhausted:
+		title, color = "⛔ Intentos de verificación agotados", discord.ColorError
+	case OutcomeTooYoung:
+		title, color = "🕒 Cuenta demasiado reciente", discord.ColorWarning
+	case OutcomeTimeout:
+		title, color = "⌛ Verificación no completada a tiempo", discord.ColorError
+	}
+
+	embed := discord.NewEmbed().
+		SetTitle(title).
+		SetColor(color).
+		AddField("Usuario", fmt.Sprintf("<@%s> (`%s`)", userID, userID), true).
+		AddField("Tipo", guildDoc.Protection.Verification.Type, true).
+		AddField("Detalle", detail, false).
+		Build()
+
+	if _, err := s.ChannelMessageSendEmbed(channelID, embed); err != nil {
+		logger.Debug(fmt.Sprintf("No se pudo enviar el log de verificación a %s: %v", channelID, err), "Verification")
+	}
+}
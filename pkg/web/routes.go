@@ -2,10 +2,13 @@
 package web
 
 import (
+	"crypto/subtle"
 	"net/http"
 
+	"github.com/PancyStudios/PancyBotGo/pkg/config"
 	"github.com/PancyStudios/PancyBotGo/pkg/database"
 	"github.com/PancyStudios/PancyBotGo/pkg/discord"
+	"github.com/PancyStudios/PancyBotGo/pkg/metrics"
 	"github.com/gin-gonic/gin"
 )
 
@@ -17,6 +20,8 @@ func SetupAPIRoutes(s *Server) {
 		api.GET("/health", healthHandler)
 		api.GET("/bot", botInfoHandler)
 	}
+
+	s.GET("/metrics", metricsHandler)
 }
 
 // statusHandler returns the bot and database status
@@ -43,6 +48,19 @@ func statusHandler(c *gin.Context) {
 	})
 }
 
+// metricsHandler serves the bot metrics in the Prometheus text format. When a
+// metrics token is configured scrapers must send it as a bearer token.
+func metricsHandler(c *gin.Context) {
+	if token := config.Get().MetricsToken; token != "" {
+		auth := c.GetHeader("Authorization")
+		if subtle.ConstantTimeCompare([]byte(auth), []byte("Bearer "+token)) != 1 {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+	}
+	metrics.Handler().ServeHTTP(c.Writer, c.Request)
+}
+
 // healthHandler returns a simple health check response
 func healthHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
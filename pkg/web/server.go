@@ -77,6 +77,12 @@ func (s *Server) logsMiddleware() gin.HandlerFunc {
 		host := c.Request.Host
 
 		if isAllowedHost(host) {
+			// Scrapes come every few seconds, logging them would bury everything else
+			if c.Request.URL.Path == "/metrics" {
+				c.Next()
+				return
+			}
+
 			logger.Info(fmt.Sprintf("[LOG] Nueva solicitud: %s %s", c.Request.Method, c.Request.URL.Path), "WebServer")
 
 			// Send to webhook